@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/anonymize"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// runAnonymize scrubs personal data out of a restored production snapshot before it is
+// used in staging, see internal/anonymize. It rewrites data for good, so it only runs
+// when -confirm names the database it connects to; -dry-run verifies the plan and
+// rolls back instead. The verification report is printed, and written as JSON with -report.
+func runAnonymize(args []string) error {
+	flags := flag.NewFlagSet("anonymize", flag.ContinueOnError)
+	dsn := flags.String("dsn", os.Getenv("DATABASE_URL"), "connection string of the database to anonymize, DATABASE_URL by default")
+	planFile := flags.String("plan", "", "anonymization plan file, the plan built into the binary by default")
+	confirm := flags.String("confirm", "", "name of the database to anonymize, required unless -dry-run")
+	dryRun := flags.Bool("dry-run", false, "apply and verify the plan, then roll back")
+	reportFile := flags.String("report", "", "file the verification report is written to as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *dsn == "" {
+		return errors.New("no database to anonymize, set -dsn or DATABASE_URL")
+	}
+
+	plan, err := anonymize.DefaultPlan()
+	if *planFile != "" {
+		plan, err = anonymize.LoadPlan(*planFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, *dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	if !*dryRun && conn.Config().Database != *confirm {
+		return fmt.Errorf("anonymizing rewrites %s for good, pass -confirm %s if it is a restored snapshot", conn.Config().Database, conn.Config().Database)
+	}
+
+	// The plan follows the latest schema, an older snapshot must be migrated first
+	current, latest, err := database.MigrationStatus(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if current != latest {
+		return fmt.Errorf("database is at migration %d, not the latest %d, migrate it first", current, latest)
+	}
+
+	report, runErr := anonymize.Run(ctx, conn, plan, anonymize.Options{DryRun: *dryRun})
+	if report != nil {
+		fmt.Print(report.Text())
+
+		if *reportFile != "" {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode report: %w", err)
+			}
+			if err := os.WriteFile(*reportFile, data, 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", *reportFile, err)
+			}
+		}
+	}
+
+	return runErr
+}
@@ -0,0 +1,209 @@
+//go:build dev
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	testenv "github.com/Barry-dE/go-backend-boilerplate/internal/testing"
+	"github.com/rs/zerolog"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// devDatabase is the name, user and password of the local database, it is only reachable
+// through a random port on the loopback interface.
+const devDatabase = "boilerplate"
+
+// runDev handles "dev <command>" subcommands, which set up a local development environment.
+func runDev(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: go-boilerplate dev up [flags]")
+	}
+
+	switch args[0] {
+	case "up":
+		return runDevUp(args[1:])
+	default:
+		return fmt.Errorf("unknown dev command %q, available: up", args[0])
+	}
+}
+
+// runDevUp starts Postgres and Redis in containers, migrates and seeds the database,
+// writes the environment pointing at them to an env file and runs the server with it
+// until interrupted. The containers are removed on exit unless -keep is set.
+func runDevUp(args []string) error {
+	flags := flag.NewFlagSet("dev up", flag.ContinueOnError)
+	envFile := flags.String("env-file", ".env.dev", "file the generated environment is written to")
+	port := flags.String("port", "8080", "port the server listens on")
+	seed := flags.Bool("seed", true, "seed the database after migrating it")
+	keep := flags.Bool("keep", false, "keep the containers running after the server exits")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger := zerolog.New(zerolog.NewConsoleWriter()).With().Timestamp().Logger()
+
+	var containers []testcontainers.Container
+	defer func() {
+		for _, container := range containers {
+			if *keep {
+				logger.Info().Str("container", container.GetContainerID()).Msg("keeping container")
+				continue
+			}
+			// The signal context is done by now.
+			if err := container.Terminate(context.Background()); err != nil {
+				logger.Warn().Err(err).Str("container", container.GetContainerID()).Msg("failed to remove container")
+			}
+		}
+	}()
+
+	logger.Info().Msg("starting postgres")
+	postgres, err := testenv.StartPostgres(ctx, devDatabase, devDatabase, devDatabase)
+	if err != nil {
+		return err
+	}
+	containers = append(containers, postgres.Container)
+
+	logger.Info().Msg("starting redis")
+	redis, err := testenv.StartRedis(ctx)
+	if err != nil {
+		return err
+	}
+	containers = append(containers, redis.Container)
+
+	variables := devEnvironment(postgres, redis, *port)
+	if err := writeEnvFile(*envFile, variables); err != nil {
+		return err
+	}
+	logger.Info().Str("file", *envFile).Msg("wrote environment")
+
+	// The server process inherits the environment, LoadConfig reads it below.
+	for _, variable := range variables {
+		key, value, _ := strings.Cut(variable, "=")
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// The server doesn't migrate in development, see main.
+	if err := database.Migrate(ctx, &logger, cfg, nil); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	if *seed {
+		if err := database.Seed(ctx, &logger, cfg); err != nil {
+			return fmt.Errorf("failed to seed database: %w", err)
+		}
+	}
+
+	return runDevServer(ctx, &logger)
+}
+
+// devEnvironment returns the environment of the server, pointing at the containers. The
+// API keys of external services are taken from the environment (or .env) if set, the
+// placeholders let the server start without them.
+func devEnvironment(postgres *testenv.PostgresContainer, redis *testenv.RedisContainer, port string) []string {
+	return []string{
+		"BOILERPLATE_PRIMARY.ENV=" + environment,
+		"BOILERPLATE_SERVER.PORT=" + port,
+		"BOILERPLATE_SERVER.READ_TIMEOUT=30",
+		"BOILERPLATE_SERVER.WRITE_TIMEOUT=30",
+		"BOILERPLATE_SERVER.IDLE_TIMEOUT=60",
+		"BOILERPLATE_SERVER.CORS_ALLOWED_ORIGINS=http://localhost:3000",
+		"BOILERPLATE_DATABASE.HOST=" + postgres.Host,
+		fmt.Sprintf("BOILERPLATE_DATABASE.PORT=%d", postgres.Port),
+		"BOILERPLATE_DATABASE.NAME=" + postgres.Database,
+		"BOILERPLATE_DATABASE.USER=" + postgres.User,
+		"BOILERPLATE_DATABASE.PASSWORD=" + postgres.Password,
+		"BOILERPLATE_DATABASE.SSL_MODE=disable",
+		"BOILERPLATE_DATABASE.MAX_OPEN_CONNECTIONS=25",
+		"BOILERPLATE_DATABASE.MAX_IDLE_CONNECTIONS=25",
+		"BOILERPLATE_DATABASE.CONNECTION_MAX_IDLE_TIME=300",
+		"BOILERPLATE_DATABASE.CONNECTION_MAX_LIFE_TIME=300",
+		"BOILERPLATE_REDIS.ADDRESS=" + redis.Address,
+		"BOILERPLATE_AUTH.SECRET_KEY=" + envOr("BOILERPLATE_AUTH.SECRET_KEY", "sk_test_dev"),
+		"BOILERPLATE_INTEGRATION.RESEND_API_KEY=" + envOr("BOILERPLATE_INTEGRATION.RESEND_API_KEY", "re_dev"),
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// writeEnvFile writes variables to path, so tools and a server started by hand can use
+// the same environment. It holds credentials and is only readable by the user.
+func writeEnvFile(path string, variables []string) error {
+	content := "# Generated by go-boilerplate dev up, overwritten on every run.\n" + strings.Join(variables, "\n") + "\n"
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// runDevServer runs this binary as the server until it exits or ctx is done, in which
+// case the server is interrupted and given the time of a graceful shutdown.
+func runDevServer(ctx context.Context, logger *zerolog.Logger) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the server binary: %w", err)
+	}
+
+	cmd := exec.Command(executable)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-exited:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info().Msg("stopping server")
+	_ = cmd.Process.Signal(os.Interrupt)
+
+	select {
+	case err := <-exited:
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// Interrupted, not failed.
+			return nil
+		}
+		return err
+	case <-time.After(shutdownContextTimeout + 5*time.Second):
+		_ = cmd.Process.Kill()
+		return errors.New("server did not stop in time, killed it")
+	}
+}
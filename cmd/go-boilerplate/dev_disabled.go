@@ -0,0 +1,11 @@
+//go:build !dev
+
+package main
+
+import "errors"
+
+// runDev explains how to get the dev subcommands. They are only built with the dev tag,
+// so the server binary doesn't carry the container tooling they depend on.
+func runDev(args []string) error {
+	return errors.New("dev commands are built with the dev tag, run: go run -tags dev ./cmd/go-boilerplate dev up")
+}
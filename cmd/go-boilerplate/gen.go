@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/dbdocs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/enumgen"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/sdkgen"
+	"github.com/jackc/pgx/v5"
+)
+
+// runGen handles "gen <target>" subcommands, which generate code and exit without starting the server.
+func runGen(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: go-boilerplate gen <sdk|enums|dbdocs|errors> [flags]")
+	}
+
+	switch args[0] {
+	case "sdk":
+		return runGenSDK(args[1:])
+	case "enums":
+		return runGenEnums(args[1:])
+	case "dbdocs":
+		return runGenDBDocs(args[1:])
+	case "errors":
+		return runGenErrors(args[1:])
+	default:
+		return fmt.Errorf("unknown gen target %q, available: sdk, enums, dbdocs, errors", args[0])
+	}
+}
+
+// runGenSDK generates the typed API client under pkg/client from the OpenAPI spec.
+// With -check it fails instead of writing when the generated client is out of date, for CI.
+func runGenSDK(args []string) error {
+	flags := flag.NewFlagSet("gen sdk", flag.ContinueOnError)
+	spec := flags.String("spec", "static/openapi.json", "OpenAPI spec file or URL, e.g. http://localhost:8080/openapi.json")
+	out := flags.String("out", "pkg/client", "output directory of the client package")
+	check := flags.Bool("check", false, "fail if the generated client is out of date instead of writing it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	document, err := sdkgen.LoadSpec(*spec)
+	if err != nil {
+		return err
+	}
+
+	source, err := sdkgen.Generate(document, filepath.Base(*out))
+	if err != nil {
+		return fmt.Errorf("failed to generate client: %w", err)
+	}
+
+	target := filepath.Join(*out, "client.gen.go")
+
+	if *check {
+		current, err := os.ReadFile(target)
+		if err != nil || !bytes.Equal(current, source) {
+			return fmt.Errorf("%s is out of date with %s, run: go run ./cmd/go-boilerplate gen sdk", target, *spec)
+		}
+		fmt.Printf("%s is up to date\n", target)
+		return nil
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", *out, err)
+	}
+
+	if err := os.WriteFile(target, source, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+
+	fmt.Printf("generated %s from %s\n", target, *spec)
+	return nil
+}
+
+// runGenEnums generates the enum types declared in a JSON file next to the output.
+// With -check it fails instead of writing when the generated file is out of date, for CI.
+func runGenEnums(args []string) error {
+	flags := flag.NewFlagSet("gen enums", flag.ContinueOnError)
+	in := flags.String("in", "internal/model/enums.json", "enum declaration file")
+	out := flags.String("out", "internal/model/enums.gen.go", "generated Go file")
+	check := flags.Bool("check", false, "fail if the generated enums are out of date instead of writing them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	decl, err := enumgen.LoadDeclarations(*in)
+	if err != nil {
+		return err
+	}
+
+	source, err := enumgen.Generate(decl, filepath.Base(filepath.Dir(*out)))
+	if err != nil {
+		return fmt.Errorf("failed to generate enums: %w", err)
+	}
+
+	if *check {
+		current, err := os.ReadFile(*out)
+		if err != nil || !bytes.Equal(current, source) {
+			return fmt.Errorf("%s is out of date with %s, run: go run ./cmd/go-boilerplate gen enums", *out, *in)
+		}
+		fmt.Printf("%s is up to date\n", *out)
+		return nil
+	}
+
+	if err := os.WriteFile(*out, source, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *out, err)
+	}
+
+	fmt.Printf("generated %s from %s\n", *out, *in)
+	return nil
+}
+
+// runGenDBDocs documents the schema of a migrated database as Markdown and HTML with an
+// ER diagram. The database must be at the latest migration, -migrate brings a scratch
+// database there first. With -check it fails instead of writing when the docs are out of
+// date, for CI.
+func runGenDBDocs(args []string) error {
+	flags := flag.NewFlagSet("gen dbdocs", flag.ContinueOnError)
+	dsn := flags.String("dsn", os.Getenv("DATABASE_URL"), "connection string of the database to document, DATABASE_URL by default")
+	schema := flags.String("schema", "public", "database schema to document")
+	out := flags.String("out", "docs/database", "output directory of schema.md and schema.html")
+	migrate := flags.Bool("migrate", false, "apply pending migrations first, only use on a scratch database")
+	check := flags.Bool("check", false, "fail if the docs are out of date instead of writing them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *dsn == "" {
+		return errors.New("no database to document, set -dsn or DATABASE_URL")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, *dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	if *migrate {
+		if err := database.MigrateConn(ctx, conn); err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	// Docs of a database behind or ahead of migrations/ would document another schema
+	current, latest, err := database.MigrationStatus(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if current != latest {
+		return fmt.Errorf("database is at migration %d, not the latest %d, migrate it first (or pass -migrate on a scratch database)", current, latest)
+	}
+
+	loaded, err := dbdocs.Load(ctx, conn, *schema)
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	page, err := dbdocs.HTML(loaded)
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		filepath.Join(*out, "schema.md"):   dbdocs.Markdown(loaded),
+		filepath.Join(*out, "schema.html"): page,
+	}
+
+	if *check {
+		for target, content := range files {
+			current, err := os.ReadFile(target)
+			if err != nil || !bytes.Equal(current, content) {
+				return fmt.Errorf("%s is out of date with the migrations, run: go run ./cmd/go-boilerplate gen dbdocs", target)
+			}
+		}
+		fmt.Printf("%s is up to date\n", *out)
+		return nil
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", *out, err)
+	}
+
+	for target, content := range files {
+		if err := os.WriteFile(target, content, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+	}
+
+	fmt.Printf("generated %s from schema %s at migration %d\n", *out, *schema, current)
+	return nil
+}
+
+// runGenErrors exports the error code catalog as JSON and Markdown for frontend teams.
+// Codes register themselves in the packages emitting them, which this binary links, so
+// the catalog is complete. With -check it fails instead of writing when the exports are
+// out of date, for CI.
+func runGenErrors(args []string) error {
+	flags := flag.NewFlagSet("gen errors", flag.ContinueOnError)
+	out := flags.String("out", "docs/errors", "output directory of errors.json and errors.md")
+	check := flags.Bool("check", false, "fail if the exports are out of date instead of writing them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := errs.Codes.JSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode error catalog: %w", err)
+	}
+
+	files := map[string][]byte{
+		filepath.Join(*out, "errors.json"): data,
+		filepath.Join(*out, "errors.md"):   errs.Codes.Markdown(),
+	}
+
+	if *check {
+		for target, content := range files {
+			current, err := os.ReadFile(target)
+			if err != nil || !bytes.Equal(current, content) {
+				return fmt.Errorf("%s is out of date with the error catalog, run: go run ./cmd/go-boilerplate gen errors", target)
+			}
+		}
+		fmt.Printf("%s is up to date\n", *out)
+		return nil
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", *out, err)
+	}
+
+	for target, content := range files {
+		if err := os.WriteFile(target, content, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+	}
+
+	fmt.Printf("generated %s with %d error codes\n", *out, len(errs.Codes.Entries()))
+	return nil
+}
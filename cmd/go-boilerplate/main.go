@@ -28,26 +28,71 @@ const (
 
 func main() {
 
+	// Code generation subcommands don't need config or a running server.
+	if len(os.Args) > 1 && os.Args[1] == "gen" {
+		if err := runGen(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Scrubbing a restored snapshot only needs a connection to it.
+	if len(os.Args) > 1 && os.Args[1] == "anonymize" {
+		if err := runAnonymize(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Local environment setup, it runs the server itself once the dependencies are up.
+	if len(os.Args) > 1 && os.Args[1] == "dev" {
+		if err := runDev(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		panic(fmt.Errorf("failed to load config: %w", err))
+		// The validation report lists every problem, one per line
+		fmt.Fprintln(os.Stderr, fmt.Errorf("failed to load config: %w", err))
+		os.Exit(1)
 	}
 
 	loggerService := logger.NewLoggerService(cfg.Observability)
 	defer loggerService.Shutdown()
 	log := logger.NewLoggerWithService(cfg.Observability, loggerService)
 
+	// While migrating, the port already answers probes with "starting" and the progress.
+	migrations := database.NewMigrationProgress()
 	if cfg.Primary.Env != environment {
-		err := database.Migrate(context.Background(), &log, cfg)
+		probe := server.StartStartupProbe(cfg, &log, migrations)
+
+		err := database.Migrate(context.Background(), &log, cfg, migrations)
 		if err != nil {
 			log.Fatal().Err(err).Msg("failed to migrate DB")
 		}
+
+		probe.Stop(context.Background())
+	} else {
+		migrations.Skip()
 	}
 
 	server, err := server.New(cfg, &log, loggerService)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to initialize server")
 	}
+	server.Migrations = migrations
+
+	// Report on config and dependencies, in strict mode refuse to start when a critical check fails.
+	report := server.SelfCheck(context.Background())
+	report.Log(&log)
+	if failures := report.CriticalFailures(); len(failures) > 0 && cfg.SelfCheck.Strict {
+		log.Fatal().Int("critical_failures", len(failures)).Msg("startup self-check failed in strict mode")
+	}
 
 	repos := repository.NewRepositories(server)
 
@@ -58,10 +103,25 @@ func main() {
 
 	handlers := handler.NewHandlers(server, services)
 
-	routes := router.NewRouter(server, handlers, services)
+	routes, err := router.NewRouter(server, handlers, services)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to initialize router")
+	}
 
 	server.ConfigureHTTPServer(routes)
 
+	// Prime caches in the background, readiness waits for the critical ones if gated.
+	server.Warmer.Start()
+
+	// Reload the config on SIGHUP or when the config file changes, components subscribed
+	// with config.OnChange apply the new values without a restart.
+	config.OnChange(func(next *config.Config) {
+		logger.SetLevel(next.Observability)
+	})
+	watcher := config.NewWatcher(cfg, &log)
+	watcher.Start()
+	defer watcher.Stop()
+
 	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
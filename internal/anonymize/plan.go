@@ -0,0 +1,124 @@
+// Package anonymize scrubs personal data out of a restored production snapshot before
+// it is used in staging or development. A Plan lists the columns to transform and how;
+// Run applies it in one transaction, checks no original value is left and reports every
+// column it touched, together with columns that look personal but no rule covers.
+package anonymize
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Strategy is how a column is anonymized.
+type Strategy string
+
+const (
+	// StrategyMaskEmail replaces addresses with user_<hash>@anonymized.invalid. The same
+	// address becomes the same mask in every column, so joins and unique keys hold.
+	StrategyMaskEmail Strategy = "mask_email"
+	// StrategyScrambleName replaces names with "Anon <letters>" derived from the name.
+	StrategyScrambleName Strategy = "scramble_name"
+	// StrategyHash replaces values with anon_<hash>, for identifiers that must stay unique
+	// or consistent across tables, e.g. device tokens or provider customer IDs.
+	StrategyHash Strategy = "hash"
+	// StrategyNull sets the column to NULL, e.g. tokens and payloads. It must be nullable.
+	StrategyNull Strategy = "null"
+	// StrategyFixed sets every non-NULL value to the rule's Value.
+	StrategyFixed Strategy = "fixed"
+)
+
+// defaultPlan covers the personal data of the schema in internal/database/migrations,
+// add a rule next to any migration adding such a column.
+//
+//go:embed plan.json
+var defaultPlan []byte
+
+// Rule anonymizes one column.
+type Rule struct {
+	Table    string   `json:"table"`
+	Column   string   `json:"column"`
+	Strategy Strategy `json:"strategy"`
+	// Value is the value set by StrategyFixed.
+	Value string `json:"value,omitempty"`
+}
+
+// Plan is the list of columns to anonymize in a schema.
+type Plan struct {
+	// Schema defaults to public.
+	Schema string `json:"schema"`
+	Rules  []Rule `json:"rules"`
+	// Keep lists table.column names reviewed and left as they are, so the report doesn't
+	// flag them as uncovered, e.g. backfill_progress.name is a job's name.
+	Keep []string `json:"keep"`
+}
+
+// DefaultPlan returns the plan shipped with the binary.
+func DefaultPlan() (*Plan, error) {
+	return parsePlan(defaultPlan)
+}
+
+// LoadPlan reads a plan from a JSON file.
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anonymization plan: %w", err)
+	}
+	return parsePlan(data)
+}
+
+func parsePlan(data []byte) (*Plan, error) {
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse anonymization plan: %w", err)
+	}
+
+	if plan.Schema == "" {
+		plan.Schema = "public"
+	}
+
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Validate checks the rules on their own, Run checks them against the database.
+func (p *Plan) Validate() error {
+	var problems []error
+	seen := make(map[string]bool, len(p.Rules))
+
+	for i, rule := range p.Rules {
+		if rule.Table == "" || rule.Column == "" {
+			problems = append(problems, fmt.Errorf("rule %d: table and column are required", i))
+			continue
+		}
+
+		name := rule.Table + "." + rule.Column
+		if seen[name] {
+			problems = append(problems, fmt.Errorf("%s has more than one rule", name))
+		}
+		seen[name] = true
+
+		switch rule.Strategy {
+		case StrategyMaskEmail, StrategyScrambleName, StrategyHash, StrategyNull:
+			if rule.Value != "" {
+				problems = append(problems, fmt.Errorf("%s: value is only used by the fixed strategy", name))
+			}
+		case StrategyFixed:
+		default:
+			problems = append(problems, fmt.Errorf("%s: unknown strategy %q", name, rule.Strategy))
+		}
+	}
+
+	if len(p.Rules) == 0 {
+		problems = append(problems, errors.New("the plan has no rules"))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid anonymization plan: %w", errors.Join(problems...))
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package anonymize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// maskDomain is the domain of masked addresses, .invalid can never be delivered to.
+const maskDomain = "anonymized.invalid"
+
+// sensitiveColumnPattern matches column names that likely hold personal data, columns
+// matching it without a rule are listed in the report.
+const sensitiveColumnPattern = `(^|_)(email|name|phone|token|recipient|address|ip|secret|password)(_|$)`
+
+// textTypes are the column types the masking strategies apply to.
+var textTypes = map[string]bool{
+	"text":              true,
+	"character varying": true,
+	"character":         true,
+}
+
+// ColumnReport is what a rule did to its column.
+type ColumnReport struct {
+	Table    string   `json:"table"`
+	Column   string   `json:"column"`
+	Strategy Strategy `json:"strategy"`
+	// Rows is the number of rows updated.
+	Rows int64 `json:"rows"`
+	// Remaining is the number of rows still holding a value the strategy doesn't produce,
+	// the run fails unless it is zero.
+	Remaining int64 `json:"remaining"`
+}
+
+// Report is the verification report of a run.
+type Report struct {
+	Database  string    `json:"database"`
+	Schema    string    `json:"schema"`
+	StartedAt time.Time `json:"started_at"`
+	Duration  string    `json:"duration"`
+	// DryRun reports are of a rolled back transaction, nothing was changed.
+	DryRun  bool           `json:"dry_run"`
+	Columns []ColumnReport `json:"columns"`
+	// Uncovered are table.column names looking personal that no rule covers.
+	Uncovered []string `json:"uncovered"`
+}
+
+// Verified reports whether every anonymized column is free of original values.
+func (r *Report) Verified() bool {
+	for _, column := range r.Columns {
+		if column.Remaining > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Options of Run.
+type Options struct {
+	// DryRun applies the plan and verifies it, then rolls back.
+	DryRun bool
+}
+
+// Run applies plan to the database of conn in one transaction. The rules are checked
+// against the schema before any row is touched, and the transaction only commits if
+// verification finds no original value left, so a failed run changes nothing.
+func Run(ctx context.Context, conn *pgx.Conn, plan *Plan, opts Options) (*Report, error) {
+	report := &Report{
+		Schema:    plan.Schema,
+		StartedAt: time.Now().UTC(),
+		DryRun:    opts.DryRun,
+		Columns:   make([]ColumnReport, 0, len(plan.Rules)),
+	}
+
+	if err := conn.QueryRow(ctx, `SELECT current_database()`).Scan(&report.Database); err != nil {
+		return nil, fmt.Errorf("failed to read database name: %w", err)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkColumns(ctx, tx, plan); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range plan.Rules {
+		column, err := apply(ctx, tx, plan.Schema, rule)
+		if err != nil {
+			return nil, err
+		}
+		report.Columns = append(report.Columns, column)
+	}
+
+	report.Uncovered, err = uncoveredColumns(ctx, tx, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Duration = time.Since(report.StartedAt).Round(time.Millisecond).String()
+
+	if !report.Verified() {
+		return report, errors.New("verification failed, original values are left, nothing was changed")
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit anonymization: %w", err)
+	}
+
+	return report, nil
+}
+
+// checkColumns makes sure every rule's column exists and suits its strategy.
+func checkColumns(ctx context.Context, tx pgx.Tx, plan *Plan) error {
+	var problems []error
+
+	for _, rule := range plan.Rules {
+		name := rule.Table + "." + rule.Column
+
+		var dataType, nullable string
+		err := tx.QueryRow(ctx, `
+			SELECT data_type, is_nullable
+			FROM information_schema.columns
+			WHERE table_schema = $1 AND table_name = $2 AND column_name = $3`,
+			plan.Schema, rule.Table, rule.Column).Scan(&dataType, &nullable)
+		if errors.Is(err, pgx.ErrNoRows) {
+			problems = append(problems, fmt.Errorf("%s doesn't exist", name))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to look up %s: %w", name, err)
+		}
+
+		switch rule.Strategy {
+		case StrategyMaskEmail, StrategyScrambleName, StrategyHash:
+			if !textTypes[dataType] {
+				problems = append(problems, fmt.Errorf("%s is %s, %s needs a text column", name, dataType, rule.Strategy))
+			}
+		case StrategyNull:
+			if nullable != "YES" {
+				problems = append(problems, fmt.Errorf("%s is NOT NULL, use hash or fixed instead of null", name))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("the plan doesn't match the database: %w", errors.Join(problems...))
+	}
+	return nil
+}
+
+// apply runs one rule and counts the values it left behind.
+func apply(ctx context.Context, tx pgx.Tx, schema string, rule Rule) (ColumnReport, error) {
+	table := pgx.Identifier{schema, rule.Table}.Sanitize()
+	column := pgx.Identifier{rule.Column}.Sanitize()
+
+	var set, remaining string
+	var args []any
+
+	switch rule.Strategy {
+	case StrategyMaskEmail:
+		set = `'user_' || left(md5(` + column + `), 16) || '@` + maskDomain + `'`
+		remaining = column + ` NOT LIKE '%@` + maskDomain + `'`
+	case StrategyScrambleName:
+		// Letters only, so the result still reads as a name
+		set = `'Anon ' || initcap(translate(left(md5(` + column + `), 8), '0123456789', 'ghijklmnop'))`
+		remaining = column + ` NOT LIKE 'Anon %'`
+	case StrategyHash:
+		set = `'anon_' || md5(` + column + `)`
+		remaining = column + ` NOT LIKE 'anon\_%'`
+	case StrategyNull:
+		set = `NULL`
+		remaining = column + ` IS NOT NULL`
+	case StrategyFixed:
+		set = `$1`
+		remaining = column + ` <> $1`
+		args = append(args, rule.Value)
+	}
+
+	report := ColumnReport{Table: rule.Table, Column: rule.Column, Strategy: rule.Strategy}
+	name := rule.Table + "." + rule.Column
+
+	// Values already anonymized by an earlier run are left alone, so runs are idempotent
+	tag, err := tx.Exec(ctx, `UPDATE `+table+` SET `+column+` = `+set+` WHERE `+column+` IS NOT NULL AND `+remaining, args...)
+	if err != nil {
+		return report, fmt.Errorf("failed to anonymize %s: %w", name, err)
+	}
+	report.Rows = tag.RowsAffected()
+
+	err = tx.QueryRow(ctx, `SELECT count(*) FROM `+table+` WHERE `+column+` IS NOT NULL AND `+remaining, args...).Scan(&report.Remaining)
+	if err != nil {
+		return report, fmt.Errorf("failed to verify %s: %w", name, err)
+	}
+
+	return report, nil
+}
+
+// uncoveredColumns lists the columns matching sensitiveColumnPattern that no rule covers
+// and the plan doesn't keep.
+func uncoveredColumns(ctx context.Context, tx pgx.Tx, plan *Plan) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.table_name || '.' || c.column_name
+		FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = $1 AND t.table_type = 'BASE TABLE' AND c.column_name ~ $2
+		ORDER BY 1`, plan.Schema, sensitiveColumnPattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list columns: %w", err)
+	}
+
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list columns: %w", err)
+	}
+
+	covered := make(map[string]bool, len(plan.Rules)+len(plan.Keep))
+	for _, rule := range plan.Rules {
+		covered[rule.Table+"."+rule.Column] = true
+	}
+	for _, name := range plan.Keep {
+		covered[name] = true
+	}
+
+	uncovered := make([]string, 0)
+	for _, name := range names {
+		if !covered[name] {
+			uncovered = append(uncovered, name)
+		}
+	}
+	return uncovered, nil
+}
+
+// Text renders the report as a table for the terminal.
+func (r *Report) Text() string {
+	var b strings.Builder
+
+	mode := "committed"
+	if r.DryRun {
+		mode = "dry run, rolled back"
+	}
+	fmt.Fprintf(&b, "anonymized %s (schema %s) in %s, %s\n\n", r.Database, r.Schema, r.Duration, mode)
+
+	fmt.Fprintf(&b, "%-45s %-14s %10s %10s\n", "COLUMN", "STRATEGY", "ROWS", "REMAINING")
+	for _, column := range r.Columns {
+		fmt.Fprintf(&b, "%-45s %-14s %10d %10d\n", column.Table+"."+column.Column, column.Strategy, column.Rows, column.Remaining)
+	}
+
+	if len(r.Uncovered) > 0 {
+		b.WriteString("\ncolumns that look personal but no rule covers, review them:\n")
+		for _, name := range r.Uncovered {
+			fmt.Fprintf(&b, "  %s\n", name)
+		}
+	}
+
+	return b.String()
+}
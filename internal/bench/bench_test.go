@@ -0,0 +1,302 @@
+package bench_test
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/handler"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/router"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	testenv "github.com/Barry-dE/go-backend-boilerplate/internal/testing"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	// benchDatabase is the name, user and password of the database in the container.
+	benchDatabase = "boilerplate"
+	// stubPath is the route of the stub handler, registered behind the global chain.
+	stubPath = "/api/v1/bench/stub"
+	// clientIPs is the number of client IPs requests are spread over, so the global
+	// per-IP rate limit is measured without rejecting the benchmark's requests.
+	clientIPs = 1 << 16
+	// auditLogs are inserted before the repository benchmarks list them.
+	auditLogs = 1000
+)
+
+// The suite shares one server, set up by TestMain.
+var (
+	routes *echo.Echo
+	repos  *repository.Repositories
+	ips    []string
+)
+
+// TestMain starts the containers and the server the benchmarks run against. They are
+// only started when benchmarks were asked for, so go test ./... doesn't need Docker.
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if flag.Lookup("test.bench").Value.String() == "" {
+		os.Exit(m.Run())
+	}
+
+	os.Exit(run(m))
+}
+
+// run sets the suite up, runs it and tears it down, so deferred cleanups run before
+// TestMain exits.
+func run(m *testing.M) int {
+	ctx := context.Background()
+	logger := zerolog.New(zerolog.NewConsoleWriter()).With().Timestamp().Logger()
+
+	var containers []testcontainers.Container
+	defer func() {
+		for _, container := range containers {
+			if err := container.Terminate(ctx); err != nil {
+				logger.Warn().Err(err).Str("container", container.GetContainerID()).Msg("failed to remove container")
+			}
+		}
+	}()
+
+	postgres, err := testenv.StartPostgres(ctx, benchDatabase, benchDatabase, benchDatabase)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to start postgres")
+		return 1
+	}
+	containers = append(containers, postgres.Container)
+
+	redis, err := testenv.StartRedis(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to start redis")
+		return 1
+	}
+	containers = append(containers, redis.Container)
+
+	srv, err := setup(ctx, &logger, postgres, redis)
+	if srv != nil {
+		defer srv.Shutdown(ctx)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to set up the benchmarks")
+		return 1
+	}
+
+	ips = clientAddresses()
+
+	return m.Run()
+}
+
+// setup configures the server against the containers, migrates and seeds the database
+// and builds the router the benchmarks serve requests with.
+func setup(ctx context.Context, logger *zerolog.Logger, postgres *testenv.PostgresContainer, redis *testenv.RedisContainer) (*server.Server, error) {
+	environment := map[string]string{
+		"BOILERPLATE_PRIMARY.ENV":                       "development",
+		"BOILERPLATE_SERVER.PORT":                       "0",
+		"BOILERPLATE_SERVER.READ_TIMEOUT":               "30",
+		"BOILERPLATE_SERVER.WRITE_TIMEOUT":              "30",
+		"BOILERPLATE_SERVER.IDLE_TIMEOUT":               "60",
+		"BOILERPLATE_SERVER.CORS_ALLOWED_ORIGINS":       "http://localhost:3000",
+		"BOILERPLATE_DATABASE.HOST":                     postgres.Host,
+		"BOILERPLATE_DATABASE.PORT":                     strconv.Itoa(postgres.Port),
+		"BOILERPLATE_DATABASE.NAME":                     postgres.Database,
+		"BOILERPLATE_DATABASE.USER":                     postgres.User,
+		"BOILERPLATE_DATABASE.PASSWORD":                 postgres.Password,
+		"BOILERPLATE_DATABASE.SSL_MODE":                 "disable",
+		"BOILERPLATE_DATABASE.MAX_OPEN_CONNECTIONS":     "25",
+		"BOILERPLATE_DATABASE.MAX_IDLE_CONNECTIONS":     "25",
+		"BOILERPLATE_DATABASE.CONNECTION_MAX_IDLE_TIME": "300",
+		"BOILERPLATE_DATABASE.CONNECTION_MAX_LIFE_TIME": "300",
+		"BOILERPLATE_REDIS.ADDRESS":                     redis.Address,
+		"BOILERPLATE_AUTH.SECRET_KEY":                   "sk_test_bench",
+		"BOILERPLATE_INTEGRATION.RESEND_API_KEY":        "re_bench",
+	}
+	for key, value := range environment {
+		if err := os.Setenv(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := database.Migrate(ctx, logger, cfg, nil); err != nil {
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	// Requests are logged as in production, to a sink, so the terminal isn't measured
+	serverLogger := zerolog.New(io.Discard).Level(zerolog.InfoLevel).With().Timestamp().Logger()
+
+	srv, err := server.New(cfg, &serverLogger, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize server: %w", err)
+	}
+
+	repos = repository.NewRepositories(srv)
+	services, err := service.NewService(srv, repos)
+	if err != nil {
+		return srv, fmt.Errorf("failed to initialize services: %w", err)
+	}
+
+	routes, err = router.NewRouter(srv, handler.NewHandlers(srv, services), services)
+	if err != nil {
+		return srv, fmt.Errorf("failed to initialize router: %w", err)
+	}
+	// Requests are served by the router directly, Shutdown still expects the HTTP server
+	srv.ConfigureHTTPServer(routes)
+
+	// Global middlewares run on every route, routes added after the router too
+	routes.GET(stubPath, stub)
+	routes.POST(stubPath, stub)
+
+	return srv, seed(ctx)
+}
+
+// seed inserts the rows the repository benchmarks read.
+func seed(ctx context.Context) error {
+	if _, err := repos.Setting.UpsertSetting(ctx, "bench", json.RawMessage(`0`), "bench"); err != nil {
+		return fmt.Errorf("failed to seed settings: %w", err)
+	}
+
+	for i := range auditLogs {
+		err := repos.Audit.Create(ctx, &model.AuditLog{
+			ActorID:      fmt.Sprintf("user_%d", i%50),
+			Action:       "bench.action",
+			ResourceType: "bench",
+			Metadata:     map[string]any{"i": i},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to seed audit logs: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func BenchmarkChainGet(b *testing.B) {
+	serve(b, func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, stubPath, nil)
+	})
+}
+
+func BenchmarkChainPostJSON(b *testing.B) {
+	body := `{"name":"bench","tags":["a","b","c"],"count":3}`
+	serve(b, func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, stubPath, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		return req
+	})
+}
+
+func BenchmarkChainNotFound(b *testing.B) {
+	serve(b, func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, "/api/v1/bench/missing", nil)
+	})
+}
+
+func BenchmarkChainGetParallel(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			req := httptest.NewRequest(http.MethodGet, stubPath, nil)
+			req.Header.Set(echo.HeaderXRealIP, ips[i%len(ips)])
+			routes.ServeHTTP(httptest.NewRecorder(), req)
+			i++
+		}
+	})
+}
+
+func BenchmarkStatus(b *testing.B) {
+	serve(b, func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, "/status", nil)
+	})
+}
+
+func BenchmarkSettingGet(b *testing.B) {
+	ctx := b.Context()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := repos.Setting.GetSetting(ctx, "bench"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSettingUpsert(b *testing.B) {
+	ctx := b.Context()
+	b.ReportAllocs()
+	for i := range b.N {
+		if _, err := repos.Setting.UpsertSetting(ctx, "bench", json.RawMessage(strconv.Itoa(i)), "bench"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDeviceUpsert(b *testing.B) {
+	ctx := b.Context()
+	b.ReportAllocs()
+	for i := range b.N {
+		payload := &model.RegisterDevicePayload{Token: fmt.Sprintf("bench-%d", i%1000), Provider: "fcm", Platform: "android"}
+		if _, err := repos.Notification.UpsertDevice(ctx, "user_bench", payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAuditList(b *testing.B) {
+	ctx := b.Context()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, _, err := repos.Audit.List(ctx, model.ListParams{Limit: 50}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// serve serves a request made by newRequest through the router b.N times, each from
+// the next of ips.
+func serve(b *testing.B, newRequest func() *http.Request) {
+	b.Helper()
+	b.ReportAllocs()
+
+	for i := range b.N {
+		req := newRequest()
+		req.Header.Set(echo.HeaderXRealIP, ips[i%len(ips)])
+		routes.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func clientAddresses() []string {
+	addresses := make([]string, clientIPs)
+	for i := range addresses {
+		addresses[i] = fmt.Sprintf("10.%d.%d.%d", i>>16&0xff, i>>8&0xff, i&0xff)
+	}
+	return addresses
+}
+
+// stub answers like a small handler would, binding the body of POST requests.
+func stub(c echo.Context) error {
+	payload := map[string]any{}
+	if c.Request().Method == http.MethodPost {
+		if err := c.Bind(&payload); err != nil {
+			return err
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]any{"ok": true, "received": len(payload)})
+}
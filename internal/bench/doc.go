@@ -0,0 +1,5 @@
+// Package bench measures the boilerplate itself: requests through the full global
+// middleware chain to a stub handler and common repository operations, against
+// Postgres and Redis in containers. It only holds benchmarks, run them with
+// "task bench" or go test -run '^$' -bench . ./internal/bench.
+package bench
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type ArchiveConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Schedule is the cron expression (UTC) the archival job runs on, nightly by default.
+	Schedule string `koanf:"schedule"`
+	// RetentionDays is how many days of audit logs stay in the database, older days are archived.
+	RetentionDays int `koanf:"retention_days"`
+	// MaxDaysPerRun bounds the work of a single run, a large backlog is worked off over several runs.
+	MaxDaysPerRun int                 `koanf:"max_days_per_run"`
+	Storage       ObjectStorageConfig `koanf:"storage"`
+}
+
+// ObjectStorageConfig points at an S3-compatible bucket (AWS S3, MinIO, R2, ...).
+type ObjectStorageConfig struct {
+	Endpoint        string `koanf:"endpoint"`
+	Region          string `koanf:"region"`
+	Bucket          string `koanf:"bucket"`
+	AccessKeyID     string `koanf:"access_key_id"`
+	SecretAccessKey string `koanf:"secret_access_key"`
+	// Prefix is prepended to every object key, e.g. "archive/".
+	Prefix string `koanf:"prefix"`
+	// UsePathStyle addresses the bucket as endpoint/bucket instead of bucket.endpoint, MinIO needs it.
+	UsePathStyle bool `koanf:"use_path_style"`
+}
+
+func (a *ArchiveConfig) Validate() error {
+	if a.RetentionDays < 0 || a.MaxDaysPerRun < 0 {
+		return fmt.Errorf("archive retention_days and max_days_per_run must be non-negative")
+	}
+
+	if !a.Enabled {
+		return nil
+	}
+
+	s := a.Storage
+	if s.Endpoint == "" || s.Bucket == "" || s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return fmt.Errorf("archive storage endpoint, bucket, access_key_id and secret_access_key are required when archiving is enabled")
+	}
+
+	return nil
+}
+
+func (a *ArchiveConfig) applyDefaults() {
+	if a.Schedule == "" {
+		a.Schedule = "0 3 * * *"
+	}
+
+	if a.RetentionDays == 0 {
+		a.RetentionDays = 90
+	}
+
+	if a.MaxDaysPerRun == 0 {
+		a.MaxDaysPerRun = 30
+	}
+
+	if a.Storage.Region == "" {
+		a.Storage.Region = "us-east-1"
+	}
+}
+
+// Retention returns RetentionDays as a duration.
+func (a *ArchiveConfig) Retention() time.Duration {
+	return time.Duration(a.RetentionDays) * 24 * time.Hour
+}
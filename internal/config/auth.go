@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	AuthProviderClerk = "clerk"
+	AuthProviderOIDC  = "oidc"
+)
+
+// OIDCConfig configures the oidc auth provider. Tokens must be signed by the issuer and
+// meant for one of the audiences, the user is their sub claim. Users are only known by
+// their claims, features looking users up in Clerk (e.g. emails sent by jobs) need the
+// clerk provider.
+type OIDCConfig struct {
+	// Issuer is the issuer URL of the provider, e.g. "https://auth.example.com/realms/app".
+	// Its discovery document (/.well-known/openid-configuration) names the JWKS endpoint.
+	Issuer string `koanf:"issuer"`
+	// JWKSURL skips discovery, for providers without a discovery document.
+	JWKSURL string `koanf:"jwks_url"`
+	// Audiences are the accepted aud values, usually the client ID or API identifier.
+	Audiences []string `koanf:"audiences"`
+	// Algorithms are the accepted signing algorithms, e.g. RS256 or ES256.
+	Algorithms []string `koanf:"algorithms"`
+	// RefreshInterval is how often the provider's keys are refetched, keys it rotated
+	// in are fetched as soon as a token signed with them comes in.
+	RefreshInterval time.Duration `koanf:"refresh_interval"`
+	// RefreshCooldown is the least time between fetches triggered by unknown keys.
+	RefreshCooldown time.Duration `koanf:"refresh_cooldown"`
+	// Leeway is the clock drift between the provider and the API tolerated when checking
+	// token lifetimes.
+	Leeway time.Duration `koanf:"leeway"`
+	// RoleClaim, PermissionsClaim and OrganizationClaim name the claims the user's role,
+	// permissions and organization are read from. Nested claims are separated by dots,
+	// e.g. "realm_access.roles", the first entry of a list is the role.
+	RoleClaim         string `koanf:"role_claim"`
+	PermissionsClaim  string `koanf:"permissions_claim"`
+	OrganizationClaim string `koanf:"organization_claim"`
+}
+
+func (a *AuthConfig) Validate() error {
+	if a.Provider != AuthProviderOIDC {
+		return nil
+	}
+
+	if a.OIDC.Issuer == "" {
+		return fmt.Errorf("auth oidc issuer is required with the oidc provider")
+	}
+
+	if len(a.OIDC.Audiences) == 0 {
+		return fmt.Errorf("auth oidc audiences are required with the oidc provider")
+	}
+
+	if a.OIDC.RefreshInterval < 0 || a.OIDC.RefreshCooldown < 0 || a.OIDC.Leeway < 0 {
+		return fmt.Errorf("auth oidc refresh_interval, refresh_cooldown and leeway must be non-negative")
+	}
+
+	return nil
+}
+
+func (a *AuthConfig) applyDefaults() {
+	if a.Provider == "" {
+		a.Provider = AuthProviderClerk
+	}
+
+	if a.OperatorClaim == "" {
+		a.OperatorClaim = "metadata.operator"
+	}
+
+	if len(a.OIDC.Algorithms) == 0 {
+		a.OIDC.Algorithms = []string{"RS256"}
+	}
+
+	if a.OIDC.RefreshInterval == 0 {
+		a.OIDC.RefreshInterval = time.Hour
+	}
+
+	if a.OIDC.RefreshCooldown == 0 {
+		a.OIDC.RefreshCooldown = time.Minute
+	}
+
+	if a.OIDC.Leeway == 0 {
+		a.OIDC.Leeway = 30 * time.Second
+	}
+
+	if a.OIDC.RoleClaim == "" {
+		a.OIDC.RoleClaim = "role"
+	}
+
+	if a.OIDC.PermissionsClaim == "" {
+		a.OIDC.PermissionsClaim = "permissions"
+	}
+
+	if a.OIDC.OrganizationClaim == "" {
+		a.OIDC.OrganizationClaim = "org_id"
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuthzConfig configures the policy engine authorizing actions beyond role checks. Policies
+// live in Postgres and are managed through the admin API.
+type AuthzConfig struct {
+	// ReloadInterval is how often policies are reloaded, changes made through another
+	// instance apply within it.
+	ReloadInterval time.Duration `koanf:"reload_interval"`
+	// LogDecisions is which decisions are written to the audit log: "denied" (the
+	// default), "all" or "none".
+	LogDecisions string `koanf:"log_decisions" validate:"omitempty,oneof=denied all none"`
+}
+
+func (a *AuthzConfig) Validate() error {
+	if a.ReloadInterval < 0 {
+		return fmt.Errorf("authz reload_interval must be non-negative")
+	}
+
+	return nil
+}
+
+func (a *AuthzConfig) applyDefaults() {
+	if a.ReloadInterval == 0 {
+		a.ReloadInterval = 30 * time.Second
+	}
+	if a.LogDecisions == "" {
+		a.LogDecisions = "denied"
+	}
+}
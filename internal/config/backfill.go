@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type BackfillConfig struct {
+	// Schedule is the cron expression (UTC) the backfill job runs on, it resumes every
+	// backfill in database.Backfills that hasn't completed yet.
+	Schedule string `koanf:"schedule"`
+	// BatchSize is the number of rows updated per transaction.
+	BatchSize int `koanf:"batch_size"`
+	// Pause is the time waited between batches, leaving room for regular traffic.
+	Pause time.Duration `koanf:"pause"`
+	// Timeout bounds a single run, the next scheduled run picks up where it stopped.
+	Timeout time.Duration `koanf:"timeout"`
+}
+
+func (b *BackfillConfig) Validate() error {
+	if b.BatchSize < 0 || b.Pause < 0 || b.Timeout < 0 {
+		return fmt.Errorf("backfill batch size, pause and timeout must be non-negative")
+	}
+
+	return nil
+}
+
+func (b *BackfillConfig) applyDefaults() {
+	if b.Schedule == "" {
+		b.Schedule = "*/15 * * * *"
+	}
+
+	if b.BatchSize == 0 {
+		b.BatchSize = 1000
+	}
+
+	if b.Pause == 0 {
+		b.Pause = 100 * time.Millisecond
+	}
+
+	if b.Timeout == 0 {
+		b.Timeout = 10 * time.Minute
+	}
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// BackupConfig schedules logical backups of the database: pg_dump, encrypted, uploaded to
+// object storage. Backups can be verified by restoring them into a scratch database.
+type BackupConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Schedule is the cron expression (UTC) backups run on, nightly by default.
+	Schedule string `koanf:"schedule"`
+	// EncryptionKey is the base64-encoded 32-byte AES-256 key backups are encrypted with.
+	// Keep a copy outside the cluster, backups can't be restored without it.
+	EncryptionKey string `koanf:"encryption_key"`
+	// RetentionDays is how long backups are kept, KeepLast backups are kept regardless.
+	RetentionDays int `koanf:"retention_days"`
+	KeepLast      int `koanf:"keep_last"`
+	// VerifyDatabaseURL is a scratch database every backup is restored into to confirm it
+	// is usable. Its contents are replaced, never point it at a database in use, the
+	// application database is rejected (see CheckVerifyDatabase). Empty skips verification.
+	VerifyDatabaseURL string `koanf:"verify_database_url"`
+	// PgDumpPath and PgRestorePath are the client binaries, looked up in PATH by default.
+	// They should match the server's major version.
+	PgDumpPath    string `koanf:"pg_dump_path"`
+	PgRestorePath string `koanf:"pg_restore_path"`
+	// Timeout bounds a whole run: dump, upload, verification and retention.
+	Timeout time.Duration `koanf:"timeout"`
+	// MaxAge is how old the last successful backup may get before the health check reports it stale.
+	MaxAge  time.Duration       `koanf:"max_age"`
+	Storage ObjectStorageConfig `koanf:"storage"`
+}
+
+func (b *BackupConfig) Validate() error {
+	if b.RetentionDays < 0 || b.KeepLast < 0 || b.Timeout < 0 || b.MaxAge < 0 {
+		return fmt.Errorf("backup retention_days, keep_last, timeout and max_age must be non-negative")
+	}
+
+	if !b.Enabled {
+		return nil
+	}
+
+	if _, err := b.Key(); err != nil {
+		return err
+	}
+
+	s := b.Storage
+	if s.Endpoint == "" || s.Bucket == "" || s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return fmt.Errorf("backup storage endpoint, bucket, access_key_id and secret_access_key are required when backups are enabled")
+	}
+
+	return nil
+}
+
+// CheckVerifyDatabase fails if VerifyDatabaseURL points at the database of db, which
+// verification would overwrite with the backup. Databases are the same when their host,
+// port and name are.
+func (b *BackupConfig) CheckVerifyDatabase(db *DatabaseConfig) error {
+	if b.VerifyDatabaseURL == "" {
+		return nil
+	}
+
+	verify, err := databaseAddress(b.VerifyDatabaseURL)
+	if err != nil {
+		return fmt.Errorf("backup verify_database_url is not a valid postgres:// URL")
+	}
+
+	// An invalid database config is reported by the database section
+	live, err := db.DSN()
+	if err == nil {
+		if target, parseErr := databaseAddress(live); parseErr == nil && target == verify {
+			return fmt.Errorf("backup verify_database_url points at the application database, verification would replace its contents")
+		}
+	}
+
+	return nil
+}
+
+// databaseAddress returns the host, port and database name of the postgres:// URL dsn,
+// with the defaults libpq applies filled in.
+func databaseAddress(dsn string) (string, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil || (parsed.Scheme != "postgres" && parsed.Scheme != "postgresql") {
+		return "", fmt.Errorf("not a postgres:// URL")
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" || host == "127.0.0.1" || host == "::1" {
+		host = "localhost"
+	}
+
+	port := parsed.Port()
+	if port == "" {
+		port = "5432"
+	}
+
+	name := strings.TrimPrefix(parsed.Path, "/")
+	if name == "" {
+		// libpq connects to the database named after the user
+		name = parsed.User.Username()
+	}
+
+	return net.JoinHostPort(host, port) + "/" + name, nil
+}
+
+// Key decodes EncryptionKey.
+func (b *BackupConfig) Key() ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(b.EncryptionKey)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("backup encryption_key must be a base64-encoded 32-byte key")
+	}
+	return key, nil
+}
+
+func (b *BackupConfig) applyDefaults() {
+	if b.Schedule == "" {
+		b.Schedule = "0 2 * * *"
+	}
+	if b.RetentionDays == 0 {
+		b.RetentionDays = 30
+	}
+	if b.KeepLast == 0 {
+		b.KeepLast = 3
+	}
+	if b.PgDumpPath == "" {
+		b.PgDumpPath = "pg_dump"
+	}
+	if b.PgRestorePath == "" {
+		b.PgRestorePath = "pg_restore"
+	}
+	if b.Timeout == 0 {
+		b.Timeout = 2 * time.Hour
+	}
+	if b.MaxAge == 0 {
+		b.MaxAge = 26 * time.Hour
+	}
+	if b.Storage.Region == "" {
+		b.Storage.Region = "us-east-1"
+	}
+	if b.Storage.Prefix == "" {
+		b.Storage.Prefix = "backups/"
+	}
+}
+
+// Retention returns RetentionDays as a duration.
+func (b *BackupConfig) Retention() time.Duration {
+	return time.Duration(b.RetentionDays) * 24 * time.Hour
+}
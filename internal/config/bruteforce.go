@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// BruteForceConfig protects authentication from guessing. Failed attempts are counted
+// per IP (and per user where known) in Redis; past DelayAfter failures the client must
+// wait a growing delay between attempts, past LockoutThreshold it is locked out.
+type BruteForceConfig struct {
+	// Disabled turns the protection off, it is on by default.
+	Disabled bool `koanf:"disabled"`
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration `koanf:"window"`
+	// DelayAfter is the number of failures allowed before delays start.
+	DelayAfter int `koanf:"delay_after"`
+	// BaseDelay doubles with every further failure, up to MaxDelay.
+	BaseDelay time.Duration `koanf:"base_delay"`
+	MaxDelay  time.Duration `koanf:"max_delay"`
+	// LockoutThreshold is the number of failures that locks the IP or user out for LockoutDuration.
+	LockoutThreshold int           `koanf:"lockout_threshold"`
+	LockoutDuration  time.Duration `koanf:"lockout_duration"`
+	// MinResponseTime is the fastest credential endpoints answer, slower answers are
+	// rounded up to a multiple of it, so timing doesn't tell whether a user exists.
+	MinResponseTime time.Duration `koanf:"min_response_time"`
+}
+
+func (b *BruteForceConfig) Validate() error {
+	if b.Window < 0 || b.BaseDelay < 0 || b.MaxDelay < 0 || b.LockoutDuration < 0 || b.MinResponseTime < 0 {
+		return fmt.Errorf("brute_force window, delays, lockout_duration and min_response_time must be non-negative")
+	}
+
+	if b.DelayAfter < 0 || b.LockoutThreshold < 0 {
+		return fmt.Errorf("brute_force delay_after and lockout_threshold must be non-negative")
+	}
+
+	if b.LockoutThreshold > 0 && b.DelayAfter > b.LockoutThreshold {
+		return fmt.Errorf("brute_force delay_after must not exceed lockout_threshold")
+	}
+
+	return nil
+}
+
+func (b *BruteForceConfig) applyDefaults() {
+	if b.Window == 0 {
+		b.Window = 15 * time.Minute
+	}
+	if b.DelayAfter == 0 {
+		b.DelayAfter = 3
+	}
+	if b.BaseDelay == 0 {
+		b.BaseDelay = time.Second
+	}
+	if b.MaxDelay == 0 {
+		b.MaxDelay = 30 * time.Second
+	}
+	if b.LockoutThreshold == 0 {
+		b.LockoutThreshold = 10
+	}
+	if b.LockoutDuration == 0 {
+		b.LockoutDuration = 15 * time.Minute
+	}
+	if b.MinResponseTime == 0 {
+		b.MinResponseTime = 500 * time.Millisecond
+	}
+}
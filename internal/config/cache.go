@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type CacheConfig struct {
+	// LocalSize is the number of hot entries each instance keeps in memory in front of
+	// Redis, a negative value turns the in-process tier off.
+	LocalSize int `koanf:"local_size"`
+	// LocalTTL bounds how long an entry is served from memory, in case an invalidation
+	// published by another instance gets lost.
+	LocalTTL time.Duration `koanf:"local_ttl"`
+	// Warmup primes caches at startup, see warmup.Warmer.
+	Warmup CacheWarmupConfig `koanf:"warmup"`
+}
+
+type CacheWarmupConfig struct {
+	// Timeout bounds each primer without a timeout of its own, defaults to 10s.
+	Timeout time.Duration `koanf:"timeout"`
+	// GateReadiness keeps the instance out of rotation until the critical caches are warm.
+	GateReadiness bool `koanf:"gate_readiness"`
+}
+
+func (c *CacheConfig) Validate() error {
+	if c.LocalTTL < 0 {
+		return fmt.Errorf("cache local_ttl must be non-negative")
+	}
+
+	if c.Warmup.Timeout < 0 {
+		return fmt.Errorf("cache warmup.timeout must be non-negative")
+	}
+
+	return nil
+}
+
+func (c *CacheConfig) applyDefaults() {
+	if c.LocalSize == 0 {
+		c.LocalSize = 10000
+	}
+
+	if c.LocalTTL == 0 {
+		c.LocalTTL = 30 * time.Second
+	}
+
+	if c.Warmup.Timeout == 0 {
+		c.Warmup.Timeout = 10 * time.Second
+	}
+}
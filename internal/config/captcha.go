@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type CaptchaConfig struct {
+	// Provider is "turnstile", "hcaptcha" or "recaptcha". When unset, RequireCaptcha lets
+	// requests through in local and development and rejects them everywhere else.
+	Provider string `koanf:"provider" validate:"omitempty,oneof=turnstile hcaptcha recaptcha"`
+	Secret   string `koanf:"secret"`
+	// MinScore rejects tokens scored below it (reCAPTCHA v3, hCaptcha Enterprise), zero accepts any.
+	MinScore float64 `koanf:"min_score"`
+	// TokenHeader is the request header the frontend sends the token in.
+	TokenHeader string        `koanf:"token_header"`
+	Timeout     time.Duration `koanf:"timeout"`
+}
+
+func (c *CaptchaConfig) Enabled() bool {
+	return c.Provider != ""
+}
+
+func (c *CaptchaConfig) Validate() error {
+	if c.Enabled() && c.Secret == "" {
+		return fmt.Errorf("captcha secret is required for provider %s", c.Provider)
+	}
+
+	if c.MinScore < 0 || c.MinScore > 1 {
+		return fmt.Errorf("captcha min_score must be between 0 and 1")
+	}
+
+	if c.Timeout < 0 {
+		return fmt.Errorf("captcha timeout must be non-negative")
+	}
+
+	return nil
+}
+
+func (c *CaptchaConfig) applyDefaults() {
+	if c.TokenHeader == "" {
+		c.TokenHeader = "X-Captcha-Token"
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 5 * time.Second
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import "time"
+
+type ComplianceConfig struct {
+	// DeletionGracePeriod is how long an account deletion can still be cancelled.
+	DeletionGracePeriod time.Duration `koanf:"deletion_grace_period"`
+	// ExportLinkTTL is how long a signed data export download link stays valid.
+	ExportLinkTTL time.Duration `koanf:"export_link_ttl"`
+	// ExportRetention is how long a generated export archive is kept.
+	ExportRetention time.Duration `koanf:"export_retention"`
+}
+
+func DefaultComplianceConfig() ComplianceConfig {
+	return ComplianceConfig{
+		DeletionGracePeriod: 30 * 24 * time.Hour,
+		ExportLinkTTL:       24 * time.Hour,
+		ExportRetention:     7 * 24 * time.Hour,
+	}
+}
+
+func (c *ComplianceConfig) applyDefaults() {
+	defaults := DefaultComplianceConfig()
+
+	if c.DeletionGracePeriod <= 0 {
+		c.DeletionGracePeriod = defaults.DeletionGracePeriod
+	}
+	if c.ExportLinkTTL <= 0 {
+		c.ExportLinkTTL = defaults.ExportLinkTTL
+	}
+	if c.ExportRetention <= 0 {
+		c.ExportRetention = defaults.ExportRetention
+	}
+}
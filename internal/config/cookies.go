@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type CookiesConfig struct {
+	// Keys is a comma-separated list of base64-encoded 32 byte keys. The first one
+	// encrypts new cookies, the others still decrypt, so keys are rotated by prepending
+	// the new key and removing the old one once its cookies expired.
+	// Falls back to a key derived from the auth secret key when not set.
+	Keys   string `koanf:"keys"`
+	Domain string `koanf:"domain"`
+	// Secure overrides the environment default, which is off in local and development only.
+	Secure *bool `koanf:"secure"`
+	// SameSite is "lax" (the default), "strict" or "none".
+	SameSite string        `koanf:"same_site" validate:"omitempty,oneof=lax strict none"`
+	MaxAge   time.Duration `koanf:"max_age"`
+}
+
+func (c *CookiesConfig) Validate() error {
+	if c.MaxAge < 0 {
+		return fmt.Errorf("cookies max_age must be non-negative")
+	}
+
+	if c.SameSite == "none" && c.Secure != nil && !*c.Secure {
+		return fmt.Errorf("cookies with same_site none must be secure")
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Validate checks the connection URL, if any, and the runtime parameters.
+func (d *DatabaseConfig) Validate() error {
+	if d.URL != "" {
+		if _, err := d.parseURL(); err != nil {
+			return err
+		}
+	}
+
+	if d.MinConnections < 0 || d.MinConnections > d.MaxOpenConnections {
+		return fmt.Errorf("database min_connections must be between 0 and max_open_connections")
+	}
+
+	if d.MaxIdleConnections < 0 || d.MaxIdleConnections > d.MaxOpenConnections {
+		return fmt.Errorf("database max_idle_connections must be between 0 and max_open_connections")
+	}
+
+	if d.ConnectionMaxIdleTime < 0 || d.ConnectionMaxLifeTime < 0 || d.HealthCheckPeriod < 0 {
+		return fmt.Errorf("database connection_max_idle_time, connection_max_life_time and health_check_period must be non-negative")
+	}
+
+	if d.WarmupConnections > d.MaxOpenConnections {
+		return fmt.Errorf("database warmup_connections must not exceed max_open_connections")
+	}
+
+	if d.RequestStatementTimeout < 0 {
+		return fmt.Errorf("database request_statement_timeout must not be negative")
+	}
+
+	for name := range d.RuntimeParams {
+		if name == "" {
+			return fmt.Errorf("database runtime_params contains an empty parameter name")
+		}
+	}
+
+	return nil
+}
+
+func (d *DatabaseConfig) applyDefaults() {
+	if d.StatementTimeout == 0 {
+		d.StatementTimeout = 30 * time.Second
+	}
+
+	if d.WarmupConnections == 0 {
+		d.WarmupConnections = d.MinConnections
+	}
+
+	if d.HealthCheckPeriod == 0 {
+		d.HealthCheckPeriod = time.Minute
+	}
+}
+
+// DSN returns the connection string for pgx.
+//
+// Precedence: URL wins over the individual host/port/user/name/ssl_mode fields, which are
+// then ignored. Runtime parameters from config (statement_timeout, application_name,
+// runtime_params) are added on top and override parameters of the same name in the URL.
+func (d *DatabaseConfig) DSN() (string, error) {
+	var dsn *url.URL
+
+	if d.URL != "" {
+		parsed, err := d.parseURL()
+		if err != nil {
+			return "", err
+		}
+		dsn = parsed
+	} else {
+		dsn = &url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(d.User, d.Password),
+			Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+			Path:     "/" + d.Name,
+			RawQuery: url.Values{"sslmode": {d.SSLMode}}.Encode(),
+		}
+	}
+
+	query := dsn.Query()
+	for name, value := range d.RuntimeParams {
+		query.Set(name, value)
+	}
+	if d.StatementTimeout > 0 {
+		// Postgres reads a bare number as milliseconds.
+		query.Set("statement_timeout", strconv.FormatInt(d.StatementTimeout.Milliseconds(), 10))
+	}
+	if d.ApplicationName != "" {
+		query.Set("application_name", d.ApplicationName)
+	}
+	dsn.RawQuery = query.Encode()
+
+	return dsn.String(), nil
+}
+
+func (d *DatabaseConfig) parseURL() (*url.URL, error) {
+	parsed, err := url.Parse(d.URL)
+	if err != nil {
+		// The parse error echoes the URL, which may contain the password.
+		return nil, fmt.Errorf("database url is not a valid URL")
+	}
+
+	if parsed.Scheme != "postgres" && parsed.Scheme != "postgresql" {
+		return nil, fmt.Errorf("database url must use the postgres:// or postgresql:// scheme, got %q", parsed.Scheme)
+	}
+
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("database url is missing a host")
+	}
+
+	if parsed.Path == "" || parsed.Path == "/" {
+		return nil, fmt.Errorf("database url is missing a database name")
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import "fmt"
+
+// DocsConfig controls the API docs. With CaptureExamples, requests served in local and
+// development are recorded, anonymized, as examples of their route and embedded into
+// /openapi.json. Commit the examples directory to show them in every environment.
+type DocsConfig struct {
+	CaptureExamples bool `koanf:"capture_examples"`
+	// ExamplesDir holds the captured examples, one JSON file per route.
+	ExamplesDir string `koanf:"examples_dir"`
+	// MaxExampleSize is the largest request or response body, in bytes, captured.
+	MaxExampleSize int `koanf:"max_example_size"`
+}
+
+func (d *DocsConfig) Validate() error {
+	if d.MaxExampleSize < 0 {
+		return fmt.Errorf("docs max_example_size must be non-negative")
+	}
+
+	return nil
+}
+
+func (d *DocsConfig) applyDefaults() {
+	if d.ExamplesDir == "" {
+		d.ExamplesDir = "static/examples"
+	}
+	if d.MaxExampleSize == 0 {
+		d.MaxExampleSize = 16 << 10
+	}
+}
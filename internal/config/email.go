@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type EmailConfig struct {
+	// UnsubscribeLinkTTL is how long the unsubscribe link of a bulk email stays valid.
+	// Mailbox providers expect it to keep working long after delivery.
+	UnsubscribeLinkTTL time.Duration `koanf:"unsubscribe_link_ttl"`
+	// SendRates are the sending limits of the email providers, keyed by provider (e.g.
+	// resend). Every instance draws from one token bucket per provider, Resend defaults
+	// to its API limit of 2 requests per second.
+	SendRates map[string]SendRateConfig `koanf:"send_rates"`
+	// ThrottleMaxWait is how long a job waits for its turn to send before it is retried
+	// later instead, defaults to 10s.
+	ThrottleMaxWait time.Duration `koanf:"throttle_max_wait"`
+
+	Verification EmailVerificationConfig `koanf:"verification"`
+}
+
+type EmailVerificationConfig struct {
+	// TokenTTL is how long a verification link stays valid, defaults to 24h.
+	TokenTTL time.Duration `koanf:"token_ttl"`
+	// SendLimit is how many verification emails a user can be sent per hour, defaults to 5.
+	SendLimit int `koanf:"send_limit"`
+	// Required makes the routes requiring a verified email reject unverified users. Until
+	// it is set they only log them, so clients can roll out the verification flow first.
+	Required bool `koanf:"required"`
+}
+
+type SendRateConfig struct {
+	PerSecond float64 `koanf:"per_second"`
+	// Burst is the number of emails sent at once after a quiet period, defaults to PerSecond.
+	Burst int `koanf:"burst"`
+}
+
+func (e *EmailConfig) Validate() error {
+	if e.UnsubscribeLinkTTL < 0 {
+		return fmt.Errorf("email unsubscribe_link_ttl must be non-negative")
+	}
+
+	for provider, rate := range e.SendRates {
+		if rate.PerSecond <= 0 {
+			return fmt.Errorf("email send_rates.%s.per_second must be positive", provider)
+		}
+		if rate.Burst < 0 {
+			return fmt.Errorf("email send_rates.%s.burst must be non-negative", provider)
+		}
+	}
+
+	if e.ThrottleMaxWait < 0 {
+		return fmt.Errorf("email throttle_max_wait must be non-negative")
+	}
+
+	if e.Verification.TokenTTL < 0 {
+		return fmt.Errorf("email verification.token_ttl must be non-negative")
+	}
+	if e.Verification.SendLimit < 0 {
+		return fmt.Errorf("email verification.send_limit must be non-negative")
+	}
+
+	return nil
+}
+
+func (e *EmailConfig) applyDefaults() {
+	if e.UnsubscribeLinkTTL == 0 {
+		e.UnsubscribeLinkTTL = 180 * 24 * time.Hour
+	}
+
+	if e.SendRates == nil {
+		e.SendRates = make(map[string]SendRateConfig)
+	}
+	if _, ok := e.SendRates["resend"]; !ok {
+		e.SendRates["resend"] = SendRateConfig{PerSecond: 2}
+	}
+	for provider, rate := range e.SendRates {
+		if rate.Burst == 0 {
+			rate.Burst = max(int(rate.PerSecond), 1)
+			e.SendRates[provider] = rate
+		}
+	}
+
+	if e.ThrottleMaxWait == 0 {
+		e.ThrottleMaxWait = 10 * time.Second
+	}
+
+	if e.Verification.TokenTTL == 0 {
+		e.Verification.TokenTTL = 24 * time.Hour
+	}
+	if e.Verification.SendLimit == 0 {
+		e.Verification.SendLimit = 5
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExportConfig controls CSV/XLSX exports of datasets.
+type ExportConfig struct {
+	// MaxSyncRows is the most rows an export streamed in the request may have, larger
+	// exports must be requested as async exports built by a job.
+	MaxSyncRows int64 `koanf:"max_sync_rows"`
+	// FlushRows is how many rows are buffered before they are flushed to the client.
+	FlushRows int `koanf:"flush_rows"`
+	// LinkTTL is how long a signed download link of an async export stays valid.
+	LinkTTL time.Duration `koanf:"link_ttl"`
+	// Retention is how long the file of an async export is kept.
+	Retention time.Duration `koanf:"retention"`
+}
+
+func (e *ExportConfig) Validate() error {
+	if e.MaxSyncRows < 0 {
+		return fmt.Errorf("export max_sync_rows must be non-negative")
+	}
+	if e.FlushRows < 0 {
+		return fmt.Errorf("export flush_rows must be non-negative")
+	}
+
+	return nil
+}
+
+func (e *ExportConfig) applyDefaults() {
+	if e.MaxSyncRows == 0 {
+		e.MaxSyncRows = 100_000
+	}
+	if e.FlushRows == 0 {
+		e.FlushRows = 500
+	}
+	if e.LinkTTL <= 0 {
+		e.LinkTTL = 24 * time.Hour
+	}
+	if e.Retention <= 0 {
+		e.Retention = 7 * 24 * time.Hour
+	}
+}
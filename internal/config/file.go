@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/toml/v2"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+// ConfigFileEnv names the env var holding the path of an optional config file. Its keys
+// are the koanf keys of the env vars, nested (server.port is server: {port: ...} in
+// YAML), and env vars override them.
+const ConfigFileEnv = "BOILERPLATE_CONFIG_FILE"
+
+// loadFile loads the config file at path into k, parsed by its extension: .yaml, .yml,
+// .toml or .json.
+func loadFile(k *koanf.Koanf, path string) error {
+	var parser koanf.Parser
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		parser = yaml.Parser()
+	case ".toml":
+		parser = toml.Parser()
+	case ".json":
+		parser = json.Parser()
+	default:
+		return fmt.Errorf("config file %s must be .yaml, .yml, .toml or .json", path)
+	}
+
+	if err := k.Load(file.Provider(path), parser); err != nil {
+		return fmt.Errorf("failed to load config file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// GraphQLConfig configures the optional GraphQL endpoint at /graphql. It resolves through
+// the same services as the REST API, behind the same authentication and tracing.
+type GraphQLConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// MaxDepth is how deeply fields may be nested in a query.
+	MaxDepth int `koanf:"max_depth"`
+	// MaxQueryLength is the longest query accepted, in bytes.
+	MaxQueryLength int `koanf:"max_query_length"`
+	// MaxParallelism is how many resolvers of a request run in parallel.
+	MaxParallelism int `koanf:"max_parallelism"`
+	// Introspection overrides the environment default of serving the schema to clients,
+	// e.g. for GraphiQL or codegen, which is on in local and development only.
+	Introspection *bool `koanf:"introspection"`
+	// BatchWait is how long a dataloader collects keys before it loads them in one
+	// query, BatchSize the most keys it loads at once.
+	BatchWait time.Duration `koanf:"batch_wait"`
+	BatchSize int           `koanf:"batch_size"`
+}
+
+func (g *GraphQLConfig) Validate() error {
+	if g.MaxDepth < 0 || g.MaxQueryLength < 0 || g.MaxParallelism < 0 || g.BatchWait < 0 || g.BatchSize < 0 {
+		return fmt.Errorf("graphql max_depth, max_query_length, max_parallelism, batch_wait and batch_size must be non-negative")
+	}
+
+	return nil
+}
+
+func (g *GraphQLConfig) applyDefaults() {
+	if g.MaxDepth == 0 {
+		g.MaxDepth = 8
+	}
+	if g.MaxQueryLength == 0 {
+		g.MaxQueryLength = 10_000
+	}
+	if g.MaxParallelism == 0 {
+		g.MaxParallelism = 10
+	}
+	if g.BatchWait == 0 {
+		g.BatchWait = 2 * time.Millisecond
+	}
+	if g.BatchSize == 0 {
+		g.BatchSize = 100
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DeepHealthConfig controls the end-to-end checks of /health/deep.
+type DeepHealthConfig struct {
+	// JobTimeout is how long the check waits for its probe job to be processed.
+	JobTimeout time.Duration `koanf:"job_timeout"`
+	// Email receives the probe email, Resend's sandbox address by default so nothing is delivered.
+	Email string `koanf:"email" validate:"omitempty,email"`
+	// EmailDisabled skips the email step, e.g. where no email provider is configured.
+	EmailDisabled bool `koanf:"email_disabled"`
+}
+
+func (d *DeepHealthConfig) Validate() error {
+	if d.JobTimeout < 0 {
+		return fmt.Errorf("deep_health job_timeout must be non-negative")
+	}
+
+	return nil
+}
+
+func (d *DeepHealthConfig) applyDefaults() {
+	if d.JobTimeout == 0 {
+		d.JobTimeout = 10 * time.Second
+	}
+
+	if d.Email == "" {
+		d.Email = "delivered@resend.dev"
+	}
+}
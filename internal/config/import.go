@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+)
+
+// ImportConfig controls bulk CSV imports.
+type ImportConfig struct {
+	// MaxFileSize is the largest accepted upload, e.g. "50M". Uploads bypass the server's BodyLimit.
+	MaxFileSize string `koanf:"max_file_size"`
+	// BatchSize is how many staged rows the import job commits per transaction.
+	BatchSize int `koanf:"batch_size"`
+	// MaxReportedErrors is how many row errors an import reports, all are counted.
+	MaxReportedErrors int `koanf:"max_reported_errors"`
+}
+
+func (i *ImportConfig) Validate() error {
+	if i.BatchSize < 0 {
+		return fmt.Errorf("import batch_size must be non-negative")
+	}
+	if i.MaxReportedErrors < 0 {
+		return fmt.Errorf("import max_reported_errors must be non-negative")
+	}
+
+	return nil
+}
+
+func (i *ImportConfig) applyDefaults() {
+	if i.MaxFileSize == "" {
+		i.MaxFileSize = "50M"
+	}
+	if i.BatchSize == 0 {
+		i.BatchSize = 1000
+	}
+	if i.MaxReportedErrors == 0 {
+		i.MaxReportedErrors = 100
+	}
+}
@@ -0,0 +1,298 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type JobsConfig struct {
+	// Backend is where tasks are queued: "redis" (asynq, the default) or "postgres", a
+	// table polled by an in-process worker pool, for minimal deployments without Redis.
+	Backend  string              `koanf:"backend" validate:"omitempty,oneof=redis postgres"`
+	Postgres PostgresQueueConfig `koanf:"postgres"`
+	// RetryPolicies overrides the retry policy of individual task types, keyed by task type (e.g. "email:welcome").
+	RetryPolicies map[string]RetryPolicyConfig `koanf:"retry_policies" validate:"omitempty,dive"`
+	Digest        DigestConfig                 `koanf:"digest"`
+	Backpressure  BackpressureConfig           `koanf:"backpressure"`
+	BatchEmail    BatchEmailConfig             `koanf:"batch_email"`
+	Streams       StreamsConfig                `koanf:"streams"`
+	Workers       WorkersConfig                `koanf:"workers"`
+	DeadTasks     DeadTasksConfig              `koanf:"dead_tasks"`
+}
+
+// DeadTasksConfig controls the alerts raised when a task runs out of retries and is
+// archived (dead-lettered for stream tasks), so dead tasks don't pile up unnoticed.
+// They are always logged with a summary of their payload and recorded as a
+// JobRetriesExhausted event, on-call can also be emailed or called on a webhook.
+type DeadTasksConfig struct {
+	// Emails are the on-call addresses alerted, none by default.
+	Emails []string `koanf:"emails" validate:"omitempty,dive,email"`
+	// WebhookURL receives every alert as a JSON POST, e.g. an incident tool's intake.
+	WebhookURL string `koanf:"webhook_url" validate:"omitempty,url"`
+	// Cooldown is how long further dead tasks of the same type are only logged and
+	// recorded after an email or webhook alert, so a broken handler doesn't page once
+	// per task.
+	Cooldown time.Duration `koanf:"cooldown"`
+	// PayloadKeys is how many top-level payload keys the summary lists, values are left
+	// out as they may carry personal data.
+	PayloadKeys int `koanf:"payload_keys"`
+}
+
+// WorkersConfig tunes the asynq worker server of the redis backend.
+type WorkersConfig struct {
+	// Concurrency is the number of tasks processed at once per instance.
+	Concurrency int `koanf:"concurrency"`
+	// Queues is the weight per queue name: with weights 6, 3 and 1, workers pick from the
+	// first queue 60% of the time. Queues left out keep their default weight
+	// (critical 6, default 3, low 1).
+	Queues map[string]int `koanf:"queues"`
+	// StrictPriority drains higher weighted queues first, lower ones are only processed
+	// while the higher ones are empty.
+	StrictPriority bool `koanf:"strict_priority"`
+	// ShutdownTimeout is how long Stop waits for running tasks before cancelling them,
+	// cancelled tasks are retried later.
+	ShutdownTimeout time.Duration `koanf:"shutdown_timeout"`
+	// HealthCheckInterval is how often the connection to Redis is checked, failures are logged.
+	HealthCheckInterval time.Duration `koanf:"health_check_interval"`
+}
+
+// defaultQueueWeights are the weights of the queues tasks are enqueued on.
+var defaultQueueWeights = map[string]int{
+	"critical": 6, // more capacity for important tasks
+	"default":  3, // normal tasks
+	"low":      1, // non-urgent tasks
+}
+
+// PostgresQueueConfig controls the worker pool of the postgres backend.
+type PostgresQueueConfig struct {
+	// Concurrency is the number of tasks processed at once per instance.
+	Concurrency int `koanf:"concurrency"`
+	// PollInterval is how often idle workers look for due tasks.
+	PollInterval time.Duration `koanf:"poll_interval"`
+	// ArchiveRetention is how long tasks that ran out of retries are kept for inspection.
+	ArchiveRetention time.Duration `koanf:"archive_retention"`
+	// ShutdownTimeout is how long Stop waits for running tasks before cancelling them,
+	// cancelled tasks are put back in their queue.
+	ShutdownTimeout time.Duration `koanf:"shutdown_timeout"`
+}
+
+// StreamsConfig controls the Redis Streams workers of task types registered with
+// RegisterStream, which are only available with the redis backend.
+type StreamsConfig struct {
+	// LeaseTTL is how long a partition stays owned by an instance that stopped renewing
+	// its lease, i.e. how soon the partitions of a lost instance are taken over.
+	LeaseTTL time.Duration `koanf:"lease_ttl"`
+	// BlockTimeout is how long a partition's worker waits for new entries at once.
+	BlockTimeout time.Duration `koanf:"block_timeout"`
+	// IDRetention is how long task IDs are remembered to reject duplicates, and how long
+	// results are kept for task types without a retention.
+	IDRetention time.Duration `koanf:"id_retention"`
+	// ShutdownTimeout is how long Stop waits for running tasks before cancelling them,
+	// cancelled tasks stay pending and run again on the next owner of their partition.
+	ShutdownTimeout time.Duration `koanf:"shutdown_timeout"`
+}
+
+// BatchEmailConfig controls how batch email tasks send.
+type BatchEmailConfig struct {
+	// ChunkSize is the number of recipients checked against the suppression list and
+	// sent at once, progress is saved after every chunk.
+	ChunkSize int `koanf:"chunk_size"`
+	// RatePerSecond caps the sends per second of each worker, below the email provider's
+	// rate limit (Resend allows 2 per second by default).
+	RatePerSecond float64 `koanf:"rate_per_second"`
+	// MaxAttempts is how often sending to one recipient is tried before it counts as failed.
+	MaxAttempts int `koanf:"max_attempts"`
+}
+
+// BackpressureConfig bounds how far the queues may grow before enqueueing applies
+// the task type's overflow policy instead of adding to Redis.
+type BackpressureConfig struct {
+	// Ceilings caps the backlog (pending, scheduled and retrying tasks) per queue name,
+	// e.g. "low". Queues without a ceiling are not checked.
+	Ceilings map[string]int `koanf:"ceilings"`
+	// OverflowPolicies is the overflow policy per task type: "reject" (the default),
+	// "drop", "shed" (move to the low queue) or "outbox" (persist, relayed later).
+	OverflowPolicies map[string]string `koanf:"overflow_policies" validate:"omitempty,dive,oneof=reject drop shed outbox"`
+	// SizeCacheTTL is how long a queue's backlog size is reused before asking Redis again.
+	SizeCacheTTL time.Duration `koanf:"size_cache_ttl"`
+	// RelaySchedule is the cron expression (UTC) outboxed tasks are moved back to their queue on.
+	RelaySchedule string `koanf:"relay_schedule"`
+	// RelayBatchSize is the maximum number of outboxed tasks relayed per run.
+	RelayBatchSize int `koanf:"relay_batch_size"`
+}
+
+type DigestConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Schedule is the cron expression (UTC) digests are sent on, weekly on Monday morning by default.
+	Schedule string `koanf:"schedule"`
+	// Period is the span of activity a digest summarizes, it should match Schedule.
+	Period time.Duration `koanf:"period"`
+	// BatchSize is the number of users fetched from the auth provider per page.
+	BatchSize int `koanf:"batch_size"`
+}
+
+type RetryPolicyConfig struct {
+	MaxRetries *int          `koanf:"max_retries" validate:"omitempty,min=0"`
+	Backoff    string        `koanf:"backoff" validate:"omitempty,oneof=constant linear exponential"`
+	BaseDelay  time.Duration `koanf:"base_delay"`
+	MaxDelay   time.Duration `koanf:"max_delay"`
+	Retention  time.Duration `koanf:"retention"`
+}
+
+func (j *JobsConfig) Validate() error {
+	for taskType, policy := range j.RetryPolicies {
+		if policy.BaseDelay < 0 || policy.MaxDelay < 0 || policy.Retention < 0 {
+			return fmt.Errorf("retry policy for %s: delays and retention must be non-negative", taskType)
+		}
+
+		if policy.MaxDelay > 0 && policy.BaseDelay > policy.MaxDelay {
+			return fmt.Errorf("retry policy for %s: base_delay must not exceed max_delay", taskType)
+		}
+	}
+
+	if j.Digest.Period < 0 || j.Digest.BatchSize < 0 {
+		return fmt.Errorf("digest period and batch_size must be non-negative")
+	}
+
+	for queue, ceiling := range j.Backpressure.Ceilings {
+		if ceiling < 1 {
+			return fmt.Errorf("backpressure ceiling for queue %s must be positive", queue)
+		}
+	}
+
+	if j.Backpressure.SizeCacheTTL < 0 || j.Backpressure.RelayBatchSize < 0 {
+		return fmt.Errorf("backpressure size_cache_ttl and relay_batch_size must be non-negative")
+	}
+
+	if j.Postgres.Concurrency < 0 || j.Postgres.PollInterval < 0 || j.Postgres.ArchiveRetention < 0 || j.Postgres.ShutdownTimeout < 0 {
+		return fmt.Errorf("postgres concurrency, poll_interval, archive_retention and shutdown_timeout must be non-negative")
+	}
+
+	if j.Streams.LeaseTTL < 0 || j.Streams.BlockTimeout < 0 || j.Streams.IDRetention < 0 || j.Streams.ShutdownTimeout < 0 {
+		return fmt.Errorf("streams lease_ttl, block_timeout, id_retention and shutdown_timeout must be non-negative")
+	}
+
+	if j.BatchEmail.ChunkSize < 0 || j.BatchEmail.RatePerSecond < 0 || j.BatchEmail.MaxAttempts < 0 {
+		return fmt.Errorf("batch_email chunk_size, rate_per_second and max_attempts must be non-negative")
+	}
+
+	if j.Workers.Concurrency < 0 || j.Workers.ShutdownTimeout < 0 || j.Workers.HealthCheckInterval < 0 {
+		return fmt.Errorf("workers concurrency, shutdown_timeout and health_check_interval must be non-negative")
+	}
+
+	if j.DeadTasks.Cooldown < 0 || j.DeadTasks.PayloadKeys < 0 {
+		return fmt.Errorf("dead_tasks cooldown and payload_keys must be non-negative")
+	}
+
+	// asynq silently ignores queues with a weight below 1, tasks enqueued on them would never run
+	for queue, weight := range j.Workers.Queues {
+		if weight < 1 {
+			return fmt.Errorf("workers queue %s: weight must be positive", queue)
+		}
+	}
+
+	return nil
+}
+
+func (j *JobsConfig) applyDefaults() {
+	if j.Backend == "" {
+		j.Backend = "redis"
+	}
+
+	if j.Postgres.Concurrency == 0 {
+		j.Postgres.Concurrency = 10
+	}
+
+	if j.Postgres.PollInterval == 0 {
+		j.Postgres.PollInterval = time.Second
+	}
+
+	if j.Postgres.ArchiveRetention == 0 {
+		j.Postgres.ArchiveRetention = 7 * 24 * time.Hour
+	}
+
+	if j.Postgres.ShutdownTimeout == 0 {
+		j.Postgres.ShutdownTimeout = 8 * time.Second
+	}
+
+	if j.Digest.Schedule == "" {
+		j.Digest.Schedule = "0 8 * * 1"
+	}
+
+	if j.Digest.Period == 0 {
+		j.Digest.Period = 7 * 24 * time.Hour
+	}
+
+	if j.Digest.BatchSize == 0 {
+		j.Digest.BatchSize = 100
+	}
+
+	if j.Backpressure.SizeCacheTTL == 0 {
+		j.Backpressure.SizeCacheTTL = 5 * time.Second
+	}
+
+	if j.Backpressure.RelaySchedule == "" {
+		j.Backpressure.RelaySchedule = "* * * * *"
+	}
+
+	if j.Backpressure.RelayBatchSize == 0 {
+		j.Backpressure.RelayBatchSize = 500
+	}
+
+	if j.Streams.LeaseTTL == 0 {
+		j.Streams.LeaseTTL = 30 * time.Second
+	}
+
+	if j.Streams.BlockTimeout == 0 {
+		j.Streams.BlockTimeout = 5 * time.Second
+	}
+
+	if j.Streams.IDRetention == 0 {
+		j.Streams.IDRetention = 24 * time.Hour
+	}
+
+	if j.Streams.ShutdownTimeout == 0 {
+		j.Streams.ShutdownTimeout = 8 * time.Second
+	}
+
+	if j.BatchEmail.ChunkSize == 0 {
+		j.BatchEmail.ChunkSize = 100
+	}
+
+	if j.BatchEmail.RatePerSecond == 0 {
+		j.BatchEmail.RatePerSecond = 2
+	}
+
+	if j.BatchEmail.MaxAttempts == 0 {
+		j.BatchEmail.MaxAttempts = 3
+	}
+
+	if j.Workers.Concurrency == 0 {
+		j.Workers.Concurrency = 10
+	}
+
+	if j.Workers.Queues == nil {
+		j.Workers.Queues = make(map[string]int, len(defaultQueueWeights))
+	}
+	for queue, weight := range defaultQueueWeights {
+		if _, ok := j.Workers.Queues[queue]; !ok {
+			j.Workers.Queues[queue] = weight
+		}
+	}
+
+	if j.Workers.ShutdownTimeout == 0 {
+		j.Workers.ShutdownTimeout = 8 * time.Second
+	}
+
+	if j.Workers.HealthCheckInterval == 0 {
+		j.Workers.HealthCheckInterval = 15 * time.Second
+	}
+
+	if j.DeadTasks.Cooldown == 0 {
+		j.DeadTasks.Cooldown = 15 * time.Minute
+	}
+
+	if j.DeadTasks.PayloadKeys == 0 {
+		j.DeadTasks.PayloadKeys = 10
+	}
+}
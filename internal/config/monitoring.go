@@ -11,6 +11,8 @@ type MonitoringConfig struct {
 	NewRelic    NewRelicConfig    `koanf:"new_relic" validate:"required"`
 	Logging     LoggingConfig     `koanf:"logging" validate:"required"`
 	HealthCheck HealthCheckConfig `koanf:"health_check" validate:"required"`
+	Metrics     MetricsConfig     `koanf:"metrics"`
+	Events      EventsConfig      `koanf:"events"`
 }
 
 type NewRelicConfig struct {
@@ -21,16 +23,90 @@ type NewRelicConfig struct {
 }
 
 type LoggingConfig struct {
-	Level              string        `koanf:"level" validate:"required"`
-	SlowQueryThreshold time.Duration `koanf:"slow_query_threshold" `
-	Format             string        `koanf:"format" validate:"required"`
+	Level                string        `koanf:"level" validate:"required"`
+	SlowQueryThreshold   time.Duration `koanf:"slow_query_threshold" `
+	SlowRequestThreshold time.Duration `koanf:"slow_request_threshold"`
+	Format               string        `koanf:"format" validate:"required"`
+	// NPlusOneThreshold is how often a request may run the same query shape before a
+	// possible N+1 is reported. Only checked in local development, zero disables it.
+	NPlusOneThreshold int `koanf:"n_plus_one_threshold"`
+	// MiddlewareTimingSampleRate is the fraction of requests, between 0 and 1, logged with the
+	// time each middleware and the handler took. Local development logs every request, zero
+	// disables it elsewhere.
+	MiddlewareTimingSampleRate float64 `koanf:"middleware_timing_sample_rate"`
 }
 
+// HealthCheckConfig controls the background prober publishing the health of the
+// dependencies as metrics, see metrics.HealthProber.
 type HealthCheckConfig struct {
 	Enabled  bool          `koanf:"enabled"`
 	Interval time.Duration `koanf:"interval" validate:"min=1s"`
 	Timeout  time.Duration `koanf:"timeout" validate:"min=1s"`
-	Checks   []string      `koanf:"checks"`
+	// Checks are the dependencies probed: database, redis and server (startup readiness).
+	Checks []string `koanf:"checks"`
+}
+
+type MetricsConfig struct {
+	PoolCollectionInterval   time.Duration `koanf:"pool_collection_interval"`
+	PoolAcquireWaitThreshold time.Duration `koanf:"pool_acquire_wait_threshold"`
+}
+
+// EventsConfig routes the structured events of observe.Event to a backend.
+type EventsConfig struct {
+	// Exporter is "newrelic" (the default, custom events), "otlp" (log records) or "none".
+	Exporter string `koanf:"exporter" validate:"omitempty,oneof=newrelic otlp none"`
+	// OTLPEndpoint is the base URL of the OTLP/HTTP receiver, e.g. http://otel-collector:4318.
+	OTLPEndpoint string `koanf:"otlp_endpoint"`
+	// OTLPHeaders are added to every export, e.g. an API key of a hosted backend.
+	OTLPHeaders   map[string]string `koanf:"otlp_headers"`
+	BufferSize    int               `koanf:"buffer_size"`
+	BatchSize     int               `koanf:"batch_size"`
+	FlushInterval time.Duration     `koanf:"flush_interval"`
+	// MaxPerSecond is how many events of one name are kept each second before they are
+	// sampled at SampleRate, which also applies while the buffer is more than half full.
+	MaxPerSecond int     `koanf:"max_per_second"`
+	SampleRate   float64 `koanf:"sample_rate"`
+}
+
+func (e *EventsConfig) Validate() error {
+	if e.Exporter == "otlp" && e.OTLPEndpoint == "" {
+		return fmt.Errorf("events otlp_endpoint is required for the otlp exporter")
+	}
+
+	if e.BufferSize < 0 || e.BatchSize < 0 || e.MaxPerSecond < 0 {
+		return fmt.Errorf("events buffer_size, batch_size and max_per_second must be non-negative")
+	}
+
+	if e.FlushInterval < 0 {
+		return fmt.Errorf("events flush_interval must be non-negative")
+	}
+
+	if e.SampleRate < 0 || e.SampleRate > 1 {
+		return fmt.Errorf("events sample_rate must be between 0 and 1")
+	}
+
+	return nil
+}
+
+func (e *EventsConfig) applyDefaults() {
+	if e.Exporter == "" {
+		e.Exporter = "newrelic"
+	}
+	if e.BufferSize == 0 {
+		e.BufferSize = 10000
+	}
+	if e.BatchSize == 0 {
+		e.BatchSize = 500
+	}
+	if e.FlushInterval == 0 {
+		e.FlushInterval = 5 * time.Second
+	}
+	if e.MaxPerSecond == 0 {
+		e.MaxPerSecond = 100
+	}
+	if e.SampleRate == 0 {
+		e.SampleRate = 0.1
+	}
 }
 
 func DefaultMonitoringConfig() *MonitoringConfig {
@@ -44,9 +120,12 @@ func DefaultMonitoringConfig() *MonitoringConfig {
 			AppLogForwardingEnabled:   true,
 		},
 		Logging: LoggingConfig{
-			Level:              "info",
-			SlowQueryThreshold: 200 * time.Millisecond,
-			Format:             "json",
+			Level:                      "info",
+			SlowQueryThreshold:         200 * time.Millisecond,
+			SlowRequestThreshold:       10 * time.Second,
+			Format:                     "json",
+			NPlusOneThreshold:          5,
+			MiddlewareTimingSampleRate: 0.01,
 		},
 		HealthCheck: HealthCheckConfig{
 			Enabled:  true,
@@ -54,6 +133,10 @@ func DefaultMonitoringConfig() *MonitoringConfig {
 			Timeout:  5 * time.Second,
 			Checks:   []string{"database", "redis", "server"},
 		},
+		Metrics: MetricsConfig{
+			PoolCollectionInterval:   15 * time.Second,
+			PoolAcquireWaitThreshold: 100 * time.Millisecond,
+		},
 	}
 }
 
@@ -76,6 +159,42 @@ func (m *MonitoringConfig) Validate() error {
 		return fmt.Errorf("slow_query_threshold must be non-negative")
 	}
 
+	// Validate slow request threshold, zero disables in-flight request detection
+	if m.Logging.SlowRequestThreshold < 0 {
+		return fmt.Errorf("slow_request_threshold must be non-negative")
+	}
+
+	if m.Logging.NPlusOneThreshold < 0 {
+		return fmt.Errorf("n_plus_one_threshold must be non-negative")
+	}
+
+	if m.Logging.MiddlewareTimingSampleRate < 0 || m.Logging.MiddlewareTimingSampleRate > 1 {
+		return fmt.Errorf("middleware_timing_sample_rate must be between 0 and 1")
+	}
+
+	// Validate pool metrics settings, a zero interval disables collection
+	if m.Metrics.PoolCollectionInterval < 0 {
+		return fmt.Errorf("pool_collection_interval must be non-negative")
+	}
+
+	if m.Metrics.PoolAcquireWaitThreshold < 0 {
+		return fmt.Errorf("pool_acquire_wait_threshold must be non-negative")
+	}
+
+	validHealthChecks := map[string]bool{
+		"database": true, "redis": true, "server": true,
+	}
+
+	for _, check := range m.HealthCheck.Checks {
+		if !validHealthChecks[check] {
+			return fmt.Errorf("invalid health check: %s (valid checks are database, redis, server)", check)
+		}
+	}
+
+	if err := m.Events.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
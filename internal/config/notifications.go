@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotificationsConfig configures the SMS and push channels next to email. Each channel is
+// disabled until its provider is set. Twilio status callbacks are verified with the webhook
+// source "twilio" (scheme twilio, the secret is the auth token).
+type NotificationsConfig struct {
+	SMS  SMSConfig  `koanf:"sms"`
+	Push PushConfig `koanf:"push"`
+	// Timeout bounds every call to a provider.
+	Timeout time.Duration `koanf:"timeout"`
+}
+
+type SMSConfig struct {
+	// Provider is "twilio", SMS are disabled when unset.
+	Provider   string `koanf:"provider" validate:"omitempty,oneof=twilio"`
+	AccountSID string `koanf:"account_sid"`
+	AuthToken  string `koanf:"auth_token"`
+	// From is the sending number, MessagingServiceSID sends through a messaging service instead.
+	From                string `koanf:"from"`
+	MessagingServiceSID string `koanf:"messaging_service_sid"`
+	// APIBaseURL overrides the provider's API endpoint, e.g. for a local mock.
+	APIBaseURL string `koanf:"api_base_url"`
+	// RateLimit is how many SMS a user may be sent per hour.
+	RateLimit int `koanf:"rate_limit"`
+}
+
+func (s *SMSConfig) Enabled() bool {
+	return s.Provider != ""
+}
+
+type PushConfig struct {
+	FCM  FCMConfig  `koanf:"fcm"`
+	APNs APNsConfig `koanf:"apns"`
+	// RateLimit is how many push notifications a user may be sent per hour.
+	RateLimit int `koanf:"rate_limit"`
+}
+
+// Enabled reports whether any push provider is configured.
+func (p *PushConfig) Enabled() bool {
+	return p.FCM.Enabled() || p.APNs.Enabled()
+}
+
+// FCMConfig holds a Firebase service account key, inline or as a file.
+type FCMConfig struct {
+	CredentialsJSON string `koanf:"credentials_json"`
+	CredentialsFile string `koanf:"credentials_file"`
+	// APIBaseURL overrides the FCM endpoint, e.g. for a local mock.
+	APIBaseURL string `koanf:"api_base_url"`
+}
+
+func (f *FCMConfig) Enabled() bool {
+	return f.CredentialsJSON != "" || f.CredentialsFile != ""
+}
+
+// APNsConfig holds an APNs token signing key (.p8), inline or as a file.
+type APNsConfig struct {
+	TeamID     string `koanf:"team_id"`
+	KeyID      string `koanf:"key_id"`
+	PrivateKey string `koanf:"private_key"`
+	KeyFile    string `koanf:"key_file"`
+	// Topic is the app's bundle ID.
+	Topic string `koanf:"topic"`
+	// Sandbox sends to development builds of the app.
+	Sandbox bool `koanf:"sandbox"`
+	// APIBaseURL overrides the APNs endpoint, e.g. for a local mock.
+	APIBaseURL string `koanf:"api_base_url"`
+}
+
+func (a *APNsConfig) Enabled() bool {
+	return a.KeyID != ""
+}
+
+func (n *NotificationsConfig) Validate() error {
+	if n.SMS.Enabled() {
+		if n.SMS.AccountSID == "" || n.SMS.AuthToken == "" {
+			return fmt.Errorf("notifications sms account_sid and auth_token are required for provider %s", n.SMS.Provider)
+		}
+		if n.SMS.From == "" && n.SMS.MessagingServiceSID == "" {
+			return fmt.Errorf("notifications sms from or messaging_service_sid is required for provider %s", n.SMS.Provider)
+		}
+	}
+
+	if n.Push.APNs.Enabled() {
+		if n.Push.APNs.TeamID == "" || n.Push.APNs.Topic == "" {
+			return fmt.Errorf("notifications push apns team_id and topic are required")
+		}
+		if n.Push.APNs.PrivateKey == "" && n.Push.APNs.KeyFile == "" {
+			return fmt.Errorf("notifications push apns private_key or key_file is required")
+		}
+	}
+
+	if n.SMS.RateLimit < 0 || n.Push.RateLimit < 0 {
+		return fmt.Errorf("notifications rate_limit must be non-negative")
+	}
+
+	if n.Timeout < 0 {
+		return fmt.Errorf("notifications timeout must be non-negative")
+	}
+
+	return nil
+}
+
+func (n *NotificationsConfig) applyDefaults() {
+	if n.SMS.RateLimit == 0 {
+		n.SMS.RateLimit = 10
+	}
+	if n.Push.RateLimit == 0 {
+		n.Push.RateLimit = 60
+	}
+	if n.Timeout == 0 {
+		n.Timeout = 10 * time.Second
+	}
+}
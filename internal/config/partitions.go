@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type PartitionsConfig struct {
+	// Schedule is the cron expression (UTC) the partition maintenance job runs on, daily by default.
+	Schedule string `koanf:"schedule"`
+	// Premake is the number of future partitions kept ready ahead of time.
+	Premake int `koanf:"premake"`
+	// Retention drops partitions that ended longer ago, zero keeps them forever.
+	// While archiving is enabled only partitions emptied by the archive job are dropped.
+	Retention time.Duration `koanf:"retention"`
+}
+
+func (p *PartitionsConfig) Validate() error {
+	if p.Premake < 0 || p.Retention < 0 {
+		return fmt.Errorf("partitions premake and retention must be non-negative")
+	}
+
+	return nil
+}
+
+func (p *PartitionsConfig) applyDefaults() {
+	if p.Schedule == "" {
+		p.Schedule = "0 1 * * *"
+	}
+
+	if p.Premake == 0 {
+		p.Premake = 3
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// PaymentsConfig configures billing through a payments provider. Webhooks of the provider
+// are verified with the webhook source of the same name, e.g. BOILERPLATE_WEBHOOKS.STRIPE.SECRET.
+type PaymentsConfig struct {
+	// Provider is "stripe", payments are disabled when unset.
+	Provider string `koanf:"provider" validate:"omitempty,oneof=stripe"`
+	// SecretKey is the provider's API key, e.g. Stripe's sk_live_... key.
+	SecretKey string `koanf:"secret_key"`
+	// APIBaseURL overrides the provider's API endpoint, e.g. for a local mock.
+	APIBaseURL string `koanf:"api_base_url"`
+	// Prices are the price IDs customers may check out, every price when empty.
+	Prices []string `koanf:"prices"`
+	// SuccessURL and CancelURL are where checkout returns to; the provider may expand
+	// placeholders in them, e.g. Stripe's {CHECKOUT_SESSION_ID}.
+	SuccessURL string        `koanf:"success_url"`
+	CancelURL  string        `koanf:"cancel_url"`
+	Timeout    time.Duration `koanf:"timeout"`
+}
+
+func (p *PaymentsConfig) Enabled() bool {
+	return p.Provider != ""
+}
+
+func (p *PaymentsConfig) Validate() error {
+	if !p.Enabled() {
+		return nil
+	}
+
+	if p.SecretKey == "" {
+		return fmt.Errorf("payments secret_key is required for provider %s", p.Provider)
+	}
+
+	if p.SuccessURL == "" || p.CancelURL == "" {
+		return fmt.Errorf("payments success_url and cancel_url are required for provider %s", p.Provider)
+	}
+
+	if p.Timeout < 0 {
+		return fmt.Errorf("payments timeout must be non-negative")
+	}
+
+	return nil
+}
+
+func (p *PaymentsConfig) applyDefaults() {
+	if p.Timeout == 0 {
+		p.Timeout = 10 * time.Second
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type QuotaConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// DailyLimit and MonthlyLimit are the number of requests allowed per consumer, zero means unlimited.
+	DailyLimit   int64 `koanf:"daily_limit"`
+	MonthlyLimit int64 `koanf:"monthly_limit"`
+	// SnapshotInterval is how often usage counters are persisted to Postgres.
+	SnapshotInterval time.Duration `koanf:"snapshot_interval"`
+}
+
+func (q *QuotaConfig) Validate() error {
+	if q.DailyLimit < 0 || q.MonthlyLimit < 0 {
+		return fmt.Errorf("quota limits must be non-negative")
+	}
+
+	if q.SnapshotInterval < 0 {
+		return fmt.Errorf("quota snapshot_interval must be non-negative")
+	}
+
+	return nil
+}
+
+func (q *QuotaConfig) applyDefaults() {
+	if q.SnapshotInterval == 0 {
+		q.SnapshotInterval = 5 * time.Minute
+	}
+}
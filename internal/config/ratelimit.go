@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type RateLimitConfig struct {
+	// TenantRequestsPerMinute is the default rate limit of authenticated requests per
+	// organization (per user outside of one), zero means unlimited. Organizations can
+	// be given their own limit, e.g. by plan, in the tenant_limits table.
+	TenantRequestsPerMinute int `koanf:"tenant_requests_per_minute"`
+	// OverrideCacheTTL is how long per-tenant overrides are cached.
+	OverrideCacheTTL time.Duration `koanf:"override_cache_ttl"`
+}
+
+func (r *RateLimitConfig) Validate() error {
+	if r.TenantRequestsPerMinute < 0 || r.OverrideCacheTTL < 0 {
+		return fmt.Errorf("rate_limit tenant_requests_per_minute and override_cache_ttl must be non-negative")
+	}
+
+	return nil
+}
+
+func (r *RateLimitConfig) applyDefaults() {
+	if r.OverrideCacheTTL == 0 {
+		r.OverrideCacheTTL = 5 * time.Minute
+	}
+}
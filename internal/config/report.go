@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// envPrefix is the prefix of every environment variable of the config.
+const envPrefix = "BOILERPLATE_"
+
+// Problem is one invalid or missing config value.
+type Problem struct {
+	// Key is the config path, e.g. "database.host", or the section for problems the
+	// section reports as a whole, e.g. "server".
+	Key string
+	// EnvVar sets the value, e.g. BOILERPLATE_DATABASE.HOST, or BOILERPLATE_SERVER.* for a section.
+	EnvVar string
+	// Message says what is wrong and what is expected.
+	Message string
+}
+
+// ValidationReport lists every problem found in the config, so all of them can be fixed
+// in one go instead of one per restart.
+type ValidationReport struct {
+	Problems []Problem
+}
+
+func (r *ValidationReport) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid config, %d problem(s):", len(r.Problems))
+	for _, problem := range r.Problems {
+		fmt.Fprintf(&b, "\n  %s (%s): %s", problem.Key, problem.EnvVar, problem.Message)
+	}
+	return b.String()
+}
+
+// err returns the report, or nil if there is no problem.
+func (r *ValidationReport) err() error {
+	if len(r.Problems) == 0 {
+		return nil
+	}
+	return r
+}
+
+// addStructErrors adds the failed struct tag validations of err.
+func (r *ValidationReport) addStructErrors(err error) {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		r.Problems = append(r.Problems, Problem{Key: "config", EnvVar: envPrefix + "*", Message: err.Error()})
+		return
+	}
+
+	for _, fe := range fieldErrs {
+		key := configKey(fe.Namespace())
+		r.Problems = append(r.Problems, Problem{
+			Key:     key,
+			EnvVar:  envVar(key),
+			Message: describe(fe, key),
+		})
+	}
+}
+
+// addSection adds the error a section's Validate returned, if any.
+func (r *ValidationReport) addSection(section string, err error) {
+	if err == nil {
+		return
+	}
+	r.Problems = append(r.Problems, Problem{
+		Key:     section,
+		EnvVar:  envVar(section) + ".*",
+		Message: err.Error(),
+	})
+}
+
+// newValidator returns a validator naming fields by their koanf keys, so failures
+// point at the config path rather than the Go field.
+func newValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("koanf"), ",")
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+	return validate
+}
+
+// configKey turns a validator namespace, e.g. "Config.webhooks[stripe].secret", into a
+// config path, e.g. "webhooks.stripe.secret".
+func configKey(namespace string) string {
+	_, key, _ := strings.Cut(namespace, ".")
+	key = strings.ReplaceAll(key, "[", ".")
+	return strings.ReplaceAll(key, "]", "")
+}
+
+// envVar returns the environment variable setting the config path key.
+func envVar(key string) string {
+	return envPrefix + strings.ToUpper(key)
+}
+
+// describe says what a failed struct tag validation of the value at key expected.
+func describe(fe validator.FieldError, key string) string {
+	switch fe.Tag() {
+	case "required":
+		return "missing, a value is required"
+	case "required_without":
+		// The parameter is the Go name of a sibling field
+		sibling := strings.ToLower(fe.Param())
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			sibling = key[:i+1] + sibling
+		}
+		return fmt.Sprintf("missing, a value is required unless %s is set", sibling)
+	case "oneof":
+		return fmt.Sprintf("got %q, expected one of: %s", fmt.Sprint(fe.Value()), strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "min":
+		return fmt.Sprintf("got %v, expected at least %s", fe.Value(), fe.Param())
+	case "max":
+		return fmt.Sprintf("got %v, expected at most %s", fe.Value(), fe.Param())
+	case "email":
+		return fmt.Sprintf("got %q, expected an email address", fmt.Sprint(fe.Value()))
+	default:
+		return fmt.Sprintf("failed the %q check", fe.Tag())
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// RequestIDConfig controls which X-Request-ID headers are kept. Requests from untrusted
+// callers always get a fresh ID, so clients can't forge or collide IDs in the logs.
+type RequestIDConfig struct {
+	// Trust is whose request IDs are kept: "internal" (the default) keeps them from
+	// callers with a valid service token or from TrustedNetworks, "all" from anyone and
+	// "none" from no one.
+	Trust string `koanf:"trust" validate:"omitempty,oneof=internal all none"`
+	// TrustedNetworks are CIDRs of internal callers, e.g. other services or the load
+	// balancer, matched against the connection's address, never a forwarded one.
+	TrustedNetworks []string `koanf:"trusted_networks"`
+	// MaxLength is the longest ID kept, longer ones are replaced.
+	MaxLength int `koanf:"max_length"`
+	// Prefix prepends a server-generated component to every kept ID, so IDs reused by a
+	// caller still tell requests apart while keeping the caller's ID searchable.
+	Prefix bool `koanf:"prefix"`
+}
+
+func (r *RequestIDConfig) Validate() error {
+	if r.MaxLength < 0 {
+		return fmt.Errorf("request_id max_length must be non-negative")
+	}
+
+	for _, network := range r.TrustedNetworks {
+		if _, err := netip.ParsePrefix(network); err != nil {
+			return fmt.Errorf("request_id trusted_networks: invalid CIDR %q: %w", network, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *RequestIDConfig) applyDefaults() {
+	if r.Trust == "" {
+		r.Trust = "internal"
+	}
+	if r.MaxLength == 0 {
+		r.MaxLength = 128
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type SelfCheckConfig struct {
+	// Strict refuses to start the server when a critical startup check fails.
+	Strict bool `koanf:"strict"`
+	// Timeout bounds each individual check.
+	Timeout time.Duration `koanf:"timeout"`
+}
+
+func (s *SelfCheckConfig) Validate() error {
+	if s.Timeout < 0 {
+		return fmt.Errorf("self_check timeout must be non-negative")
+	}
+
+	return nil
+}
+
+func (s *SelfCheckConfig) applyDefaults() {
+	if s.Timeout == 0 {
+		s.Timeout = 5 * time.Second
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type SLOConfig struct {
+	// DefaultLatencyBudget applies to routes that don't declare a budget of their own,
+	// zero only tracks routes with a declared budget.
+	DefaultLatencyBudget time.Duration `koanf:"default_latency_budget"`
+	// Objective is the share of requests expected to finish within their budget, e.g. 0.99.
+	Objective float64 `koanf:"objective"`
+	// ReportInterval is how often per-route burn rates are computed and exported.
+	ReportInterval time.Duration `koanf:"report_interval"`
+}
+
+func (s *SLOConfig) Validate() error {
+	if s.DefaultLatencyBudget < 0 || s.ReportInterval < 0 {
+		return fmt.Errorf("slo default_latency_budget and report_interval must be non-negative")
+	}
+
+	if s.Objective < 0 || s.Objective >= 1 {
+		return fmt.Errorf("slo objective must be between 0 and 1 (exclusive)")
+	}
+
+	return nil
+}
+
+func (s *SLOConfig) applyDefaults() {
+	if s.Objective == 0 {
+		s.Objective = 0.99
+	}
+
+	if s.ReportInterval == 0 {
+		s.ReportInterval = time.Minute
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type ServiceAuthConfig struct {
+	// Name identifies this service: the issuer of the tokens it mints and the audience of
+	// the tokens it accepts. Internal endpoints reject every call while it is unset.
+	Name string `koanf:"name"`
+	// Secret (an HMAC secret shared with the services called) or SigningKey (an Ed25519
+	// private key) signs outgoing tokens, base64 encoded. Set at most one.
+	Secret     string `koanf:"secret"`
+	SigningKey string `koanf:"signing_key"`
+	// Callers are the services allowed to call this one, keyed by service name, set with
+	// BOILERPLATE_SERVICE_AUTH.CALLERS.BILLING.PUBLIC_KEY etc.
+	Callers  map[string]ServiceCallerConfig `koanf:"callers"`
+	TokenTTL time.Duration                  `koanf:"token_ttl"`
+	// Leeway is the clock drift between hosts tolerated when checking token lifetimes.
+	Leeway time.Duration `koanf:"leeway"`
+}
+
+type ServiceCallerConfig struct {
+	// Secret is the HMAC secret shared with the caller, PublicKey its Ed25519 public key,
+	// base64 encoded. Exactly one is required.
+	Secret    string `koanf:"secret"`
+	PublicKey string `koanf:"public_key"`
+}
+
+func (s *ServiceAuthConfig) Validate() error {
+	if s.Name == "" && (len(s.Callers) > 0 || s.Secret != "" || s.SigningKey != "") {
+		return fmt.Errorf("service_auth name is required when keys or callers are configured")
+	}
+
+	if s.Secret != "" && s.SigningKey != "" {
+		return fmt.Errorf("service_auth secret and signing_key are mutually exclusive")
+	}
+
+	for name, caller := range s.Callers {
+		if (caller.Secret == "") == (caller.PublicKey == "") {
+			return fmt.Errorf("service_auth caller %s: exactly one of secret and public_key is required", name)
+		}
+	}
+
+	if s.TokenTTL < 0 || s.Leeway < 0 {
+		return fmt.Errorf("service_auth token_ttl and leeway must be non-negative")
+	}
+
+	return nil
+}
+
+func (s *ServiceAuthConfig) applyDefaults() {
+	if s.TokenTTL == 0 {
+		s.TokenTTL = time.Minute
+	}
+	if s.Leeway == 0 {
+		s.Leeway = 30 * time.Second
+	}
+}
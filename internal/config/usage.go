@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// UsageConfig configures API usage analytics. Every instance counts requests per
+// client and route in memory, and flushes the counts to Postgres through the job queue.
+type UsageConfig struct {
+	Disabled bool `koanf:"disabled"`
+	// FlushInterval is how often counts are flushed, the counts of an instance killed
+	// without a graceful shutdown since the last flush are lost.
+	FlushInterval time.Duration `koanf:"flush_interval"`
+	// DeprecationNotices emails the users still calling deprecated routes.
+	DeprecationNotices DeprecationNoticesConfig `koanf:"deprecation_notices"`
+}
+
+type DeprecationNoticesConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Schedule is the cron expression (UTC) notices are sent on, weekly on Monday morning by default.
+	Schedule string `koanf:"schedule"`
+	// Lookback is how far back calls to deprecated routes are looked at, it should match Schedule.
+	Lookback time.Duration `koanf:"lookback"`
+}
+
+func (u *UsageConfig) Validate() error {
+	if u.FlushInterval < 0 || u.DeprecationNotices.Lookback < 0 {
+		return fmt.Errorf("usage flush_interval and deprecation_notices.lookback must be non-negative")
+	}
+
+	return nil
+}
+
+func (u *UsageConfig) applyDefaults() {
+	if u.FlushInterval == 0 {
+		u.FlushInterval = time.Minute
+	}
+	if u.DeprecationNotices.Schedule == "" {
+		u.DeprecationNotices.Schedule = "0 9 * * 1"
+	}
+	if u.DeprecationNotices.Lookback == 0 {
+		u.DeprecationNotices.Lookback = 7 * 24 * time.Hour
+	}
+}
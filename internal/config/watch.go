@@ -0,0 +1,170 @@
+package config
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/knadh/koanf/providers/file"
+	"github.com/rs/zerolog"
+)
+
+// reloadDebounce coalesces the events of one change of the config file, editors and
+// config map updates fire several.
+const reloadDebounce = 500 * time.Millisecond
+
+var (
+	callbacksMu sync.RWMutex
+	callbacks   []func(*Config)
+)
+
+// OnChange registers fn to be called with the new config after every reload that changed
+// it, see Watcher. Components able to apply a change without a restart subscribe when
+// they are built, e.g. the log level, CORS origins and tenant rate limit; everything
+// else keeps the config the server started with. fn runs on the watcher's goroutine and
+// must not block.
+func OnChange(fn func(*Config)) {
+	callbacksMu.Lock()
+	defer callbacksMu.Unlock()
+	callbacks = append(callbacks, fn)
+}
+
+func notifyChange(cfg *Config) {
+	callbacksMu.RLock()
+	subscribed := slices.Clone(callbacks)
+	callbacksMu.RUnlock()
+
+	for _, fn := range subscribed {
+		fn(cfg)
+	}
+}
+
+// Watcher reloads the config on SIGHUP and, when one is used, whenever the config file
+// changes. Env vars are fixed for the life of the process, so only the file can change
+// what a reload sees. A config that fails to load or validate is logged and ignored, the
+// running one stays in effect.
+type Watcher struct {
+	logger *zerolog.Logger
+
+	mu       sync.Mutex
+	current  *Config
+	debounce *time.Timer
+
+	signals chan os.Signal
+	done    chan struct{}
+	file    *file.File
+}
+
+// NewWatcher returns a Watcher of current, the config the server started with.
+func NewWatcher(current *Config, logger *zerolog.Logger) *Watcher {
+	return &Watcher{
+		logger:  logger,
+		current: current,
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start listens for SIGHUP and watches the config file.
+func (w *Watcher) Start() {
+	signal.Notify(w.signals, syscall.SIGHUP)
+	go w.run()
+
+	path := os.Getenv(ConfigFileEnv)
+	if path == "" {
+		return
+	}
+
+	w.file = file.Provider(path)
+	if err := w.file.Watch(w.onFileEvent); err != nil {
+		w.logger.Warn().Err(err).Str("path", path).Msg("config file is not watched, reload it with SIGHUP")
+		w.file = nil
+	}
+}
+
+// Stop stops listening for SIGHUP and watching the config file.
+func (w *Watcher) Stop() {
+	signal.Stop(w.signals)
+	close(w.done)
+
+	if w.file != nil {
+		_ = w.file.Unwatch()
+	}
+
+	w.mu.Lock()
+	if w.debounce != nil {
+		w.debounce.Stop()
+	}
+	w.mu.Unlock()
+}
+
+func (w *Watcher) run() {
+	for {
+		select {
+		case <-w.signals:
+			w.Reload("sighup")
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *Watcher) onFileEvent(_ any, err error) {
+	if err != nil {
+		// The watch ends on errors, e.g. when the file is removed
+		w.logger.Warn().Err(err).Msg("config file is no longer watched, reload it with SIGHUP")
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.debounce != nil {
+		w.debounce.Stop()
+	}
+	w.debounce = time.AfterFunc(reloadDebounce, func() { w.Reload("file") })
+}
+
+// Reload loads the config again and hands it to the OnChange callbacks if it changed.
+// trigger says what asked for the reload in the logs, e.g. "sighup".
+func (w *Watcher) Reload(trigger string) {
+	// One reload at a time, so callbacks see the configs in the order they were loaded
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		w.logger.Error().Err(err).Str("trigger", trigger).Msg("config reload failed, keeping the running config")
+		return
+	}
+
+	changed := changedSections(w.current, cfg)
+	if len(changed) == 0 {
+		w.logger.Info().Str("trigger", trigger).Msg("config reloaded, nothing changed")
+		return
+	}
+
+	// Section names only, their values may be secrets
+	w.logger.Info().Str("trigger", trigger).Str("changed", strings.Join(changed, ", ")).Msg("config reloaded")
+	w.current = cfg
+	notifyChange(cfg)
+}
+
+// changedSections returns the koanf keys of the top-level sections that differ.
+func changedSections(previous, next *Config) []string {
+	var changed []string
+
+	before, after := reflect.ValueOf(previous).Elem(), reflect.ValueOf(next).Elem()
+	for i := range before.NumField() {
+		if !reflect.DeepEqual(before.Field(i).Interface(), after.Field(i).Interface()) {
+			changed = append(changed, before.Type().Field(i).Tag.Get("koanf"))
+		}
+	}
+
+	return changed
+}
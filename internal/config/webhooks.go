@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// WebhooksConfig holds the signing settings of every inbound webhook source, keyed by
+// source name (e.g. "stripe", "clerk", "resend"), set with BOILERPLATE_WEBHOOKS.CLERK.SECRET etc.
+type WebhooksConfig map[string]WebhookConfig
+
+type WebhookConfig struct {
+	Secret string `koanf:"secret" validate:"required"`
+	// Scheme is the signature format: "stripe", "svix" (used by Clerk and Resend), "twilio"
+	// (the secret is the account's auth token, no timestamp is signed) or "hmac".
+	Scheme string `koanf:"scheme" validate:"required,oneof=stripe svix twilio hmac"`
+	// SignatureHeader and TimestampHeader override the scheme's default headers.
+	// For the generic "hmac" scheme SignatureHeader is required, TimestampHeader is optional.
+	SignatureHeader string `koanf:"signature_header"`
+	TimestampHeader string `koanf:"timestamp_header"`
+	// Tolerance is how far the signed timestamp may be from now, 5 minutes by default.
+	Tolerance time.Duration `koanf:"tolerance"`
+}
+
+func (w WebhooksConfig) Validate() error {
+	for source, webhook := range w {
+		if webhook.Scheme == "hmac" && webhook.SignatureHeader == "" {
+			return fmt.Errorf("webhook %s: signature_header is required for the hmac scheme", source)
+		}
+
+		if webhook.Tolerance < 0 {
+			return fmt.Errorf("webhook %s: tolerance must be non-negative", source)
+		}
+	}
+
+	return nil
+}
+
+func (w WebhooksConfig) applyDefaults() {
+	for source, webhook := range w {
+		if webhook.Tolerance == 0 {
+			webhook.Tolerance = 5 * time.Minute
+		}
+		w[source] = webhook
+	}
+}
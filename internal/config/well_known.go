@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// WellKnownConfig controls the documents served under /.well-known/. Files under
+// static/well-known/ are served there too, e.g. assetlinks.json for Android app links.
+type WellKnownConfig struct {
+	SecurityTxt SecurityTxtConfig `koanf:"security_txt"`
+	// ChangePasswordURL is where /.well-known/change-password redirects password managers
+	// to, e.g. the auth provider's account page. Not served while unset.
+	ChangePasswordURL string `koanf:"change_password_url" validate:"omitempty,url"`
+	// JWKS publishes the public key of the service tokens this service mints at
+	// /.well-known/jwks.json, so callees can fetch it instead of configuring it. Only
+	// Ed25519 signing keys can be published, an HMAC secret never is.
+	JWKS bool `koanf:"jwks"`
+}
+
+// SecurityTxtConfig builds /.well-known/security.txt (RFC 9116), telling security
+// researchers how to report vulnerabilities. Without contacts, static/well-known/security.txt
+// is served if it exists.
+type SecurityTxtConfig struct {
+	// Contacts are mailto: or https: URIs, at least one is required by the RFC.
+	Contacts []string `koanf:"contacts" validate:"omitempty,dive,uri"`
+	// Expires is the date the document goes stale, in RFC 3339. Defaults to a year
+	// ahead of each response, set it to have the document reviewed on a schedule.
+	Expires            string `koanf:"expires"`
+	Policy             string `koanf:"policy" validate:"omitempty,url"`
+	Acknowledgments    string `koanf:"acknowledgments" validate:"omitempty,url"`
+	Encryption         string `koanf:"encryption" validate:"omitempty,uri"`
+	Hiring             string `koanf:"hiring" validate:"omitempty,url"`
+	PreferredLanguages string `koanf:"preferred_languages"`
+}
+
+func (w *WellKnownConfig) Validate() error {
+	if w.SecurityTxt.Expires != "" {
+		if _, err := time.Parse(time.RFC3339, w.SecurityTxt.Expires); err != nil {
+			return fmt.Errorf("well_known security_txt expires %q is not an RFC 3339 date", w.SecurityTxt.Expires)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Backfill fills the column added by an expand migration for existing rows, in batches
+// ordered by Key so no batch locks more than a handful of rows or runs into the
+// statement timeout. New and updated rows are kept in sync by the shared/dual_write.sql
+// trigger meanwhile; once the backfill completed the contract migration can gate on it
+// with shared/contract_gate.sql.
+type Backfill struct {
+	// Name identifies the backfill in backfill_progress, keep it stable once deployed.
+	Name string
+	// Table is the table to update.
+	Table string
+	// Key is a unique, sortable column the batches walk through, "id" by default.
+	Key string
+	// Set is the SET clause applied to each row, e.g. "email_normalized = lower(email)".
+	Set string
+	// Where matches the rows still to fill, e.g. "email_normalized IS NULL AND email IS NOT NULL".
+	Where string
+}
+
+// BackfillProgress is the state of a backfill, saved after every batch.
+type BackfillProgress struct {
+	Name        string     `json:"name" db:"name"`
+	LastKey     *string    `json:"last_key" db:"last_key"`
+	RowsDone    int64      `json:"rows_done" db:"rows_done"`
+	Batches     int64      `json:"batches" db:"batches"`
+	StartedAt   time.Time  `json:"started_at" db:"started_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+}
+
+// Done reports whether the backfill found no rows left to fill.
+func (p *BackfillProgress) Done() bool {
+	return p.CompletedAt != nil
+}
+
+// Backfills lists every backfill the backfill job runs until completed.
+// Register a backfill here together with the expand migration adding its column.
+var Backfills = []Backfill{}
+
+func (b Backfill) key() string {
+	if b.Key == "" {
+		return "id"
+	}
+	return b.Key
+}
+
+// Validate checks the backfill is fully described, Where is required because it's
+// what makes batches idempotent and the backfill end.
+func (b Backfill) Validate() error {
+	if b.Name == "" || b.Table == "" || b.Set == "" || b.Where == "" {
+		return fmt.Errorf("backfill %q needs a name, table, set and where clause", b.Name)
+	}
+
+	return nil
+}
+
+const backfillProgressColumns = "name, last_key, rows_done, batches, started_at, updated_at, completed_at"
+
+// GetBackfillProgress returns the progress of the named backfill, nil if it never ran.
+func (db *Database) GetBackfillProgress(ctx context.Context, name string) (*BackfillProgress, error) {
+	rows, err := db.Pool.Query(ctx, `SELECT `+backfillProgressColumns+` FROM backfill_progress WHERE name = $1`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backfill progress of %s: %w", name, err)
+	}
+
+	progress, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[BackfillProgress])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:backfill_progress: %w", err)
+	}
+
+	return &progress, nil
+}
+
+// BackfillBatch fills the next batch of up to size rows after the saved cursor and
+// saves the progress in the same transaction, so a crash never skips or repeats a
+// committed batch. The progress row stays locked meanwhile, concurrent runners of the
+// same backfill wait for each other. The backfill completes with the first empty batch.
+func (db *Database) BackfillBatch(ctx context.Context, b Backfill, size int) (*BackfillProgress, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `INSERT INTO backfill_progress (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`, b.Name); err != nil {
+		return nil, fmt.Errorf("failed to start backfill %s: %w", b.Name, err)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT `+backfillProgressColumns+` FROM backfill_progress WHERE name = $1 FOR UPDATE`, b.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock backfill progress of %s: %w", b.Name, err)
+	}
+
+	progress, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[BackfillProgress])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:backfill_progress: %w", err)
+	}
+
+	if progress.Done() {
+		return &progress, nil
+	}
+
+	// The cursor is saved as text, it's cast back to the key's type so it compares
+	// in the key's order rather than lexically.
+	var keyType string
+	err = tx.QueryRow(ctx, `
+		SELECT format_type(atttypid, atttypmod)
+		FROM pg_attribute
+		WHERE attrelid = $1::regclass AND attname = $2 AND NOT attisdropped
+	`, b.Table, b.key()).Scan(&keyType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up key %s of %s: %w", b.key(), b.Table, err)
+	}
+
+	table := pgx.Identifier{b.Table}.Sanitize()
+	key := pgx.Identifier{b.key()}.Sanitize()
+
+	// Set and Where come from code, not from input, so inlining them is safe.
+	batch := fmt.Sprintf(`
+		WITH batch AS (
+			SELECT %[2]s AS backfill_key FROM %[1]s
+			WHERE (%[4]s) AND ($1::text IS NULL OR %[2]s > CAST($1::text AS %[5]s))
+			ORDER BY %[2]s
+			LIMIT $2
+			FOR UPDATE
+		), updated AS (
+			UPDATE %[1]s t SET %[3]s FROM batch WHERE t.%[2]s = batch.backfill_key RETURNING t.%[2]s AS backfill_key
+		)
+		SELECT count(*), (SELECT backfill_key::text FROM updated ORDER BY backfill_key DESC LIMIT 1) FROM updated
+	`, table, key, b.Set, b.Where, keyType)
+
+	var count int64
+	var lastKey *string
+	if err := tx.QueryRow(ctx, batch, progress.LastKey, size).Scan(&count, &lastKey); err != nil {
+		return nil, fmt.Errorf("failed to backfill %s: %w", b.Name, err)
+	}
+
+	if lastKey == nil {
+		lastKey = progress.LastKey
+	}
+
+	rows, err = tx.Query(ctx, `
+		UPDATE backfill_progress SET
+			last_key = $2,
+			rows_done = rows_done + $3,
+			batches = batches + 1,
+			updated_at = now(),
+			completed_at = CASE WHEN $3 = 0 THEN now() END
+		WHERE name = $1
+		RETURNING `+backfillProgressColumns, b.Name, lastKey, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save backfill progress of %s: %w", b.Name, err)
+	}
+
+	progress, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[BackfillProgress])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:backfill_progress: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit backfill batch of %s: %w", b.Name, err)
+	}
+
+	return &progress, nil
+}
@@ -6,13 +6,13 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net"
-	"net/url"
-	"strconv"
+	"sync"
 	"time"
 
 	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/latency"
 	loggerConfig "github.com/Barry-dE/go-backend-boilerplate/internal/logger"
 	pgxZeroLog "github.com/jackc/pgx-zerolog"
 	"github.com/jackc/pgx/v5"
@@ -23,8 +23,9 @@ import (
 )
 
 type Database struct {
-	Pool *pgxpool.Pool
-	log  *zerolog.Logger
+	Pool            *pgxpool.Pool
+	log             *zerolog.Logger
+	applicationName string
 }
 
 const DatabasePingTimeout = 10
@@ -63,12 +64,54 @@ func (met *multiEnvironmentTracer) TraceQueryEnd(ctx context.Context, connection
 
 }
 
-func NewDatabaseConnectionPool(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerConfig.LoggerService) (*Database, error) {
-	hostPort := net.JoinHostPort(cfg.Database.Host, strconv.Itoa(cfg.Database.Port))
+type queryStartKey struct{}
+
+// queryTimer measures queries for the latency breakdown of the request, see latency.Breakdown.
+type queryTimer struct{}
+
+func (qt *queryTimer) TraceQueryStart(ctx context.Context, connection *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if latency.FromContext(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, queryStartKey{}, time.Now())
+}
+
+func (qt *queryTimer) TraceQueryEnd(ctx context.Context, connection *pgx.Conn, data pgx.TraceQueryEndData) {
+	if start, ok := ctx.Value(queryStartKey{}).(time.Time); ok {
+		latency.FromContext(ctx).AddDB(time.Since(start))
+	}
+}
+
+// sqlArgsLogger wraps a pgx tracelog.Logger and rewrites the query arguments
+// of every log entry according to mode before handing it to the next logger.
+// In raw mode the fully interpolated query is added as well, to make
+// copy-pasting queries into psql easy while developing.
+type sqlArgsLogger struct {
+	next tracelog.Logger
+	mode loggerConfig.SQLArgsMode
+}
+
+func (l *sqlArgsLogger) Log(ctx context.Context, level tracelog.LogLevel, msg string, data map[string]any) {
+	if args, ok := data["args"].([]any); ok {
+		switch l.mode {
+		case loggerConfig.SQLArgsSanitized:
+			data["args"] = loggerConfig.SanitizeSQLArgs(args)
+		case loggerConfig.SQLArgsRaw:
+			if sql, ok := data["sql"].(string); ok {
+				data["query"] = loggerConfig.FormatSQLWithArgs(sql, args)
+			}
+		}
+	}
 
-	// URL-encode the database password
-	encodePassword := url.QueryEscape(cfg.Database.Password)
-	dsn := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s", cfg.Database.User, encodePassword, hostPort, cfg.Database.Name, cfg.Database.SSLMode)
+	l.next.Log(ctx, level, msg, data)
+}
+
+func NewDatabaseConnectionPool(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerConfig.LoggerService) (*Database, error) {
+	// Build the DSN from the database URL or the individual connection fields
+	dsn, err := cfg.Database.DSN()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build database dsn: %w", err)
+	}
 
 	// parse dsn to create a pool of connections
 	pgxPoolConfig, err := pgxpool.ParseConfig(dsn)
@@ -76,33 +119,65 @@ func NewDatabaseConnectionPool(cfg *config.Config, logger *zerolog.Logger, logge
 		return nil, fmt.Errorf("failed to parse pgx pool config: %w", err)
 	}
 
+	// Name every connection after the service, environment and version so it can be told apart in pg_stat_activity
+	appName := applicationName(cfg)
+	pgxPoolConfig.AfterConnect = setApplicationName(appName)
+
+	// The pool keeps MinConns open, topping them up on its health checks. pgx has no cap
+	// on idle connections, those above MinConns are closed once idle for MaxConnIdleTime.
+	pgxPoolConfig.MaxConns = int32(cfg.Database.MaxOpenConnections)
+	pgxPoolConfig.MinConns = int32(cfg.Database.MinConnections)
+	if cfg.Database.MaxIdleConnections > 0 {
+		pgxPoolConfig.MinConns = int32(min(cfg.Database.MinConnections, cfg.Database.MaxIdleConnections))
+	}
+
+	// Unset values keep pgx's defaults, or the pool_* parameters of the database URL
+	if cfg.Database.ConnectionMaxIdleTime > 0 {
+		pgxPoolConfig.MaxConnIdleTime = time.Duration(cfg.Database.ConnectionMaxIdleTime) * time.Second
+	}
+	if cfg.Database.ConnectionMaxLifeTime > 0 {
+		pgxPoolConfig.MaxConnLifetime = time.Duration(cfg.Database.ConnectionMaxLifeTime) * time.Second
+		// Connections opened together (e.g. by the warmup) would otherwise all expire at once
+		pgxPoolConfig.MaxConnLifetimeJitter = pgxPoolConfig.MaxConnLifetime / 10
+	}
+	if cfg.Database.HealthCheckPeriod > 0 {
+		pgxPoolConfig.HealthCheckPeriod = cfg.Database.HealthCheckPeriod
+	}
+
+	// Every query adds its duration to the latency breakdown of the request it runs for.
+	tracers := []any{&queryTimer{}}
+
 	// Instrument database with new relic
 	if loggerService != nil && loggerService.GetNewRelicApp() != nil {
-		pgxPoolConfig.ConnConfig.Tracer = nrpgx5.NewTracer()
+		tracers = append(tracers, nrpgx5.NewTracer())
 	}
 
-	if cfg.Primary.Env == "local" {
-		globalLogLevel := logger.GetLevel()
+	// Queries are always logged in local development, elsewhere when log_queries is set
+	if cfg.Primary.Env == "local" || cfg.Database.LogQueries {
+		globalLogLevel := zerolog.GlobalLevel()
 		pgxLogger := loggerConfig.DatabaseLogger(globalLogLevel)
 
-		// chain traces, new relic first,then local logging
-		if pgxPoolConfig.ConnConfig.Tracer != nil {
-			// if new relic tracer exist, create a multi tracer
-			devTracer := &tracelog.TraceLog{
-				Logger:   pgxZeroLog.NewLogger(pgxLogger),
-				LogLevel: tracelog.LogLevel(loggerConfig.GetDBTraceLogLevel(globalLogLevel)),
-			}
-
-			pgxPoolConfig.ConnConfig.Tracer = &multiEnvironmentTracer{
-				tracers: []any{pgxPoolConfig.ConnConfig.Tracer, devTracer},
-			}
-		} else {
-			pgxPoolConfig.ConnConfig.Tracer = &tracelog.TraceLog{
-				Logger:   pgxZeroLog.NewLogger(pgxLogger),
-				LogLevel: tracelog.LogLevel(loggerConfig.GetDBTraceLogLevel(globalLogLevel)),
-			}
+		// Query arguments are rendered raw or sanitized depending on the environment,
+		// so enabling this tracer outside local development never leaks user data.
+		queryTracer := &tracelog.TraceLog{
+			Logger: &sqlArgsLogger{
+				next: pgxZeroLog.NewLogger(pgxLogger),
+				mode: loggerConfig.SQLArgsModeForEnv(cfg.Primary.Env),
+			},
+			LogLevel: tracelog.LogLevel(loggerConfig.GetDBTraceLogLevel(globalLogLevel)),
 		}
 
+		// chain traces, new relic first,then logging
+		tracers = append(tracers, queryTracer)
+	}
+
+	if cfg.Primary.Env == "local" {
+		// Count query shapes per request so repeated queries (N+1) can be reported.
+		tracers = append(tracers, &queryShapeTracer{})
+	}
+
+	pgxPoolConfig.ConnConfig.Tracer = &multiEnvironmentTracer{
+		tracers: tracers,
 	}
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), pgxPoolConfig)
@@ -111,8 +186,9 @@ func NewDatabaseConnectionPool(cfg *config.Config, logger *zerolog.Logger, logge
 	}
 
 	database := &Database{
-		Pool: pool,
-		log:  logger,
+		Pool:            pool,
+		log:             logger,
+		applicationName: appName,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), DatabasePingTimeout*time.Second)
@@ -123,9 +199,63 @@ func NewDatabaseConnectionPool(cfg *config.Config, logger *zerolog.Logger, logge
 
 	logger.Info().Msg("Database connected successfully")
 
+	if cfg.Database.WarmupConnections > 0 {
+		database.Warmup(cfg.Database.WarmupConnections)
+	}
+
 	return database, nil
 }
 
+// Warmup opens n connections at once and runs a trivial query on each, so they are
+// established and idle in the pool before traffic arrives. A failed warmup is only
+// logged, the pool opens connections on demand as usual.
+func (db *Database) Warmup(n int) {
+	ctx, cancel := context.WithTimeout(context.Background(), DatabasePingTimeout*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	conns := make([]*pgxpool.Conn, n)
+	errs := make([]error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			conn, err := db.Pool.Acquire(ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			conns[i] = conn
+
+			_, errs[i] = conn.Exec(ctx, "SELECT 1")
+		}()
+	}
+	wg.Wait()
+
+	// Release only once every connection is open, otherwise the pool would hand
+	// released ones to the remaining goroutines instead of opening new ones.
+	warmed := 0
+	for i, conn := range conns {
+		if conn == nil {
+			continue
+		}
+		if errs[i] == nil {
+			warmed++
+		}
+		conn.Release()
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		db.log.Warn().Err(err).Int("requested", n).Int("warmed", warmed).Msg("Database pool warmup incomplete")
+		return
+	}
+
+	db.log.Info().Int("connections", warmed).Dur("duration", time.Since(start)).Msg("Database pool warmed up")
+}
+
 // Close gracefully shuts down the database connection pool.
 func (db *Database) Close() error {
 	db.log.Info().Msg("Closing database connection pool")
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"sync"
+	"time"
+)
+
+type MigrationPhase string
+
+const (
+	MigrationPending   MigrationPhase = "pending"
+	MigrationRunning   MigrationPhase = "running"
+	MigrationCompleted MigrationPhase = "completed"
+	MigrationFailed    MigrationPhase = "failed"
+	// MigrationSkipped means this instance doesn't migrate at startup (development).
+	MigrationSkipped MigrationPhase = "skipped"
+)
+
+// MigrationState is a snapshot of the startup migration, as reported by the readiness endpoint.
+type MigrationState struct {
+	Phase MigrationPhase `json:"phase"`
+	// Current is the schema version, Target the latest version known to this binary.
+	Current int32 `json:"current_version"`
+	Target  int32 `json:"target_version"`
+	// Applying is the migration being applied while running, e.g. "007_tenant_limits.sql".
+	Applying   string     `json:"applying,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// MigrationProgress tracks the startup migration, so the instance reports itself as
+// starting instead of ready while the schema is only partly migrated.
+type MigrationProgress struct {
+	mu    sync.RWMutex
+	state MigrationState
+}
+
+func NewMigrationProgress() *MigrationProgress {
+	return &MigrationProgress{state: MigrationState{Phase: MigrationPending}}
+}
+
+// State returns a snapshot of the migration.
+func (p *MigrationProgress) State() MigrationState {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.state
+}
+
+// Done reports whether the schema is ready for traffic: migrated, or not migrated by this instance.
+func (p *MigrationProgress) Done() bool {
+	phase := p.State().Phase
+	return phase == MigrationCompleted || phase == MigrationSkipped
+}
+
+// Skip records that this instance doesn't run migrations.
+func (p *MigrationProgress) Skip() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state.Phase = MigrationSkipped
+}
+
+func (p *MigrationProgress) start(current, target int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now().UTC()
+	p.state = MigrationState{Phase: MigrationRunning, Current: current, Target: target, StartedAt: &now}
+}
+
+// applying records the start of a migration, the one before it has been applied.
+func (p *MigrationProgress) applying(sequence int32, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.state.Current = sequence - 1
+	p.state.Applying = name
+}
+
+func (p *MigrationProgress) finish(current int32, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now().UTC()
+	p.state.FinishedAt = &now
+	p.state.Current = current
+	p.state.Applying = ""
+
+	if err != nil {
+		p.state.Phase = MigrationFailed
+		p.state.Error = err.Error()
+		return
+	}
+
+	p.state.Phase = MigrationCompleted
+}
@@ -0,0 +1,15 @@
+-- Periodic snapshots of the request quota counters kept in Redis
+CREATE TABLE quota_usage (
+    consumer_id TEXT NOT NULL,
+    period TEXT NOT NULL CHECK (period IN ('daily', 'monthly')),
+    period_start DATE NOT NULL,
+    used BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (consumer_id, period, period_start)
+);
+
+CREATE INDEX idx_quota_usage_period_start ON quota_usage (period, period_start);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS quota_usage;
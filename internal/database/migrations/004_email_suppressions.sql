@@ -0,0 +1,10 @@
+-- Addresses that must not receive non-essential emails (bounces, complaints, opt-outs)
+CREATE TABLE email_suppressions (
+    email TEXT PRIMARY KEY,
+    reason TEXT NOT NULL CHECK (reason IN ('bounce', 'complaint', 'unsubscribe')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS email_suppressions;
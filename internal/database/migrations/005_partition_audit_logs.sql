@@ -0,0 +1,56 @@
+-- Range partition audit_logs by month so old months can be archived and dropped
+-- without a long DELETE. The partition key has to be part of the primary key.
+ALTER TABLE audit_logs RENAME TO audit_logs_unpartitioned;
+ALTER TABLE audit_logs_unpartitioned RENAME CONSTRAINT audit_logs_pkey TO audit_logs_unpartitioned_pkey;
+ALTER INDEX idx_audit_logs_actor_id RENAME TO idx_audit_logs_unpartitioned_actor_id;
+ALTER INDEX idx_audit_logs_created_at RENAME TO idx_audit_logs_unpartitioned_created_at;
+
+CREATE TABLE audit_logs (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    actor_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    resource_type TEXT NOT NULL,
+    resource_id TEXT,
+    request_id TEXT,
+    metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
+
+{{ template "shared/time_partitions.sql" (dict "table" "audit_logs" "interval" "month" "from" "COALESCE((SELECT MIN(created_at) FROM audit_logs_unpartitioned), now())" "ahead" 3) }}
+
+INSERT INTO audit_logs (id, actor_id, action, resource_type, resource_id, request_id, metadata, created_at)
+SELECT id, actor_id, action, resource_type, resource_id, request_id, metadata, created_at
+FROM audit_logs_unpartitioned;
+
+DROP TABLE audit_logs_unpartitioned;
+
+---- create above / drop below ----
+
+ALTER TABLE audit_logs RENAME TO audit_logs_partitioned;
+ALTER TABLE audit_logs_partitioned RENAME CONSTRAINT audit_logs_pkey TO audit_logs_partitioned_pkey;
+ALTER INDEX idx_audit_logs_actor_id RENAME TO idx_audit_logs_partitioned_actor_id;
+ALTER INDEX idx_audit_logs_created_at RENAME TO idx_audit_logs_partitioned_created_at;
+
+CREATE TABLE audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    resource_type TEXT NOT NULL,
+    resource_id TEXT,
+    request_id TEXT,
+    metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
+
+INSERT INTO audit_logs (id, actor_id, action, resource_type, resource_id, request_id, metadata, created_at)
+SELECT id, actor_id, action, resource_type, resource_id, request_id, metadata, created_at
+FROM audit_logs_partitioned;
+
+DROP TABLE audit_logs_partitioned;
@@ -0,0 +1,14 @@
+-- Tasks that didn't fit in their queue, relayed back once the queue has room
+CREATE TABLE job_outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_type TEXT NOT NULL,
+    payload BYTEA,
+    queue TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_job_outbox_created_at ON job_outbox (created_at);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS job_outbox;
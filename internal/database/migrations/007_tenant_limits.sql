@@ -0,0 +1,15 @@
+-- Per-organization overrides of the rate limit and request quotas, e.g. granted by a paid plan.
+-- NULL columns keep the configured default, zero means unlimited.
+CREATE TABLE tenant_limits (
+    organization_id TEXT PRIMARY KEY,
+    plan TEXT,
+    requests_per_minute INTEGER CHECK (requests_per_minute >= 0),
+    daily_quota BIGINT CHECK (daily_quota >= 0),
+    monthly_quota BIGINT CHECK (monthly_quota >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS tenant_limits;
@@ -0,0 +1,15 @@
+-- Progress of the batched backfills in database.Backfills, so runs resume after the last
+-- committed batch and contract migrations can gate on completed_at.
+CREATE TABLE backfill_progress (
+    name TEXT PRIMARY KEY,
+    last_key TEXT,
+    rows_done BIGINT NOT NULL DEFAULT 0,
+    batches BIGINT NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS backfill_progress;
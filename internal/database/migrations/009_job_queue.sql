@@ -0,0 +1,31 @@
+-- Tasks of the postgres job backend (jobs.backend), used instead of Redis in minimal deployments
+CREATE TABLE job_queue (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    payload BYTEA,
+    queue TEXT NOT NULL,
+    state TEXT NOT NULL DEFAULT 'pending' CHECK (state IN ('pending', 'active', 'completed', 'archived')),
+    retried INTEGER NOT NULL DEFAULT 0,
+    max_retry INTEGER NOT NULL,
+    timeout_ms BIGINT NOT NULL,
+    deadline TIMESTAMPTZ,
+    retention_ms BIGINT NOT NULL DEFAULT 0,
+    unique_key TEXT,
+    unique_until TIMESTAMPTZ,
+    process_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    lease_until TIMESTAMPTZ,
+    last_error TEXT,
+    result BYTEA,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Due tasks are claimed by queue and process_at, only pending rows are looked at
+CREATE INDEX idx_job_queue_pending ON job_queue (queue, process_at) WHERE state = 'pending';
+CREATE INDEX idx_job_queue_active_lease ON job_queue (lease_until) WHERE state = 'active';
+CREATE INDEX idx_job_queue_unique_key ON job_queue (unique_key) WHERE unique_key IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS job_queue;
@@ -0,0 +1,10 @@
+-- Probe rows of the deep health check, written, processed by a probe job and deleted per check
+CREATE TABLE health_probes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    processed_at TIMESTAMPTZ
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS health_probes;
@@ -0,0 +1,26 @@
+-- Async CSV/XLSX exports of datasets, built by a job and downloaded through a signed link
+CREATE TABLE exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    requested_by TEXT NOT NULL,
+    dataset TEXT NOT NULL,
+    format TEXT NOT NULL CHECK (format IN ('csv', 'xlsx')),
+    columns TEXT[] NOT NULL DEFAULT '{}',
+    language TEXT NOT NULL DEFAULT '',
+    range_from TIMESTAMPTZ,
+    range_until TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    content BYTEA,
+    row_count BIGINT,
+    size_bytes BIGINT,
+    error TEXT,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_exports_requested_by ON exports (requested_by);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS exports;
@@ -0,0 +1,33 @@
+-- Bulk CSV imports: validated on upload, staged in import_rows and committed in batches by a job
+CREATE TABLE imports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    requested_by TEXT NOT NULL,
+    dataset TEXT NOT NULL,
+    filename TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'validating' CHECK (status IN ('validating', 'invalid', 'pending', 'processing', 'completed', 'failed')),
+    total_rows BIGINT NOT NULL DEFAULT 0,
+    valid_rows BIGINT NOT NULL DEFAULT 0,
+    processed_rows BIGINT NOT NULL DEFAULT 0,
+    error_count BIGINT NOT NULL DEFAULT 0,
+    errors JSONB NOT NULL DEFAULT '[]'::jsonb,
+    error TEXT,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_imports_requested_by ON imports (requested_by);
+
+-- Staged rows are transient, so the table skips the write-ahead log. Postgres empties it
+-- after a crash, the import job then fails the affected imports so they are uploaded again
+CREATE UNLOGGED TABLE import_rows (
+    import_id UUID NOT NULL REFERENCES imports (id) ON DELETE CASCADE,
+    line INTEGER NOT NULL,
+    data JSONB NOT NULL,
+    PRIMARY KEY (import_id, line)
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS import_rows;
+DROP TABLE IF EXISTS imports;
@@ -0,0 +1,47 @@
+-- The payments provider customer of every owner, the organization or user billed
+-- (see middleware.GetQuotaConsumer), e.g. "org:org_123"
+CREATE TABLE payment_customers (
+    owner_id TEXT PRIMARY KEY,
+    provider TEXT NOT NULL,
+    customer_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (provider, customer_id)
+);
+
+-- Subscriptions as last reported by the provider's webhooks
+CREATE TABLE subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider TEXT NOT NULL,
+    provider_subscription_id TEXT NOT NULL,
+    customer_id TEXT NOT NULL,
+    owner_id TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('trialing', 'active', 'past_due', 'unpaid', 'paused', 'incomplete', 'incomplete_expired', 'canceled')),
+    price_id TEXT NOT NULL DEFAULT '',
+    current_period_end TIMESTAMPTZ,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT false,
+    canceled_at TIMESTAMPTZ,
+    -- When the provider created the event last applied. Events can arrive out of
+    -- order, older ones are ignored so they don't roll the state back.
+    synced_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (provider, provider_subscription_id)
+);
+
+CREATE INDEX idx_subscriptions_owner_id ON subscriptions (owner_id);
+
+-- Webhook events already processed, providers deliver events at least once
+CREATE TABLE payment_events (
+    provider TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (provider, event_id)
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS payment_events;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS payment_customers;
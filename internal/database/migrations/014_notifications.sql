@@ -0,0 +1,39 @@
+-- Push device tokens of every user. A token belongs to one app install, so it moves
+-- to whoever registers it last.
+CREATE TABLE devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id TEXT NOT NULL,
+    provider TEXT NOT NULL CHECK (provider IN ('fcm', 'apns')),
+    platform TEXT NOT NULL CHECK (platform IN ('ios', 'android', 'web')),
+    token TEXT NOT NULL UNIQUE,
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_devices_user_id ON devices (user_id);
+
+-- Every SMS and push notification sent, with its delivery status as last reported
+CREATE TABLE notification_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id TEXT NOT NULL,
+    channel TEXT NOT NULL CHECK (channel IN ('sms', 'push')),
+    provider TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    provider_message_id TEXT,
+    status TEXT NOT NULL CHECK (status IN ('queued', 'sent', 'delivered', 'undelivered', 'failed')),
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_notification_deliveries_user_id ON notification_deliveries (user_id);
+
+-- Status callbacks look deliveries up by the provider's message ID
+CREATE UNIQUE INDEX idx_notification_deliveries_provider_message_id ON notification_deliveries (provider, provider_message_id)
+WHERE provider_message_id IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS notification_deliveries;
+DROP TABLE IF EXISTS devices;
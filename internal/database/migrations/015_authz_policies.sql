@@ -0,0 +1,17 @@
+-- Authorization policies evaluated by the policy engine, see internal/lib/authz. A deny
+-- matching a request wins over any allow, nothing is allowed without a policy.
+CREATE TABLE authz_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subject TEXT NOT NULL,
+    action TEXT NOT NULL,
+    resource TEXT NOT NULL,
+    effect TEXT NOT NULL CHECK (effect IN ('allow', 'deny')),
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (subject, action, resource, effect)
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS authz_policies;
@@ -0,0 +1,24 @@
+-- API usage rolled up per day, client and route. Clients are "user:<id>", "service:<name>"
+-- or "anonymous". Instances count requests in memory and flush them through the job queue.
+CREATE TABLE api_usage (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    day DATE NOT NULL,
+    client TEXT NOT NULL,
+    method TEXT NOT NULL,
+    route TEXT NOT NULL,
+    version TEXT NOT NULL DEFAULT '',
+    deprecated BOOLEAN NOT NULL DEFAULT false,
+    requests BIGINT NOT NULL DEFAULT 0,
+    errors BIGINT NOT NULL DEFAULT 0,
+    last_seen_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (day, client, method, route)
+);
+
+-- Finds the clients still calling deprecated routes
+CREATE INDEX idx_api_usage_deprecated ON api_usage (day, client) WHERE deprecated;
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS api_usage;
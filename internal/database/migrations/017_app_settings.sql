@@ -0,0 +1,13 @@
+-- Runtime settings changed by operators through the admin API, see internal/lib/settings.
+-- Settings are declared in code with their type and default, only changed values are stored.
+CREATE TABLE app_settings (
+    key TEXT PRIMARY KEY,
+    value JSONB NOT NULL,
+    updated_by TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS app_settings;
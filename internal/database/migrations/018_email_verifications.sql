@@ -0,0 +1,16 @@
+-- Email verification of every user who asked for one. Only the SHA-256 of the pending
+-- token is stored, a new token replaces the previous one and verifying clears it.
+CREATE TABLE email_verifications (
+    user_id TEXT PRIMARY KEY,
+    email TEXT NOT NULL,
+    token_hash TEXT UNIQUE,
+    token_expires_at TIMESTAMPTZ,
+    email_verified_at TIMESTAMPTZ,
+    last_sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS email_verifications;
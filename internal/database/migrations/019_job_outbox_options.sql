@@ -0,0 +1,6 @@
+-- Options of outboxed tasks (process_at, task_id, timeout, ...), restored when they are relayed
+ALTER TABLE job_outbox ADD COLUMN options JSONB NOT NULL DEFAULT '{}';
+
+---- create above / drop below ----
+
+ALTER TABLE job_outbox DROP COLUMN IF EXISTS options;
@@ -0,0 +1,21 @@
+{{- /*
+Refuses to run the destructive step of a contract migration until `check`, a SQL
+boolean expression, holds. Tern runs every migration in a transaction, so the raised
+exception rolls the whole migration back and the deploy stops before anything is
+dropped; it passes once the data is ready, e.g. after the backfill completed.
+
+Usage, at the top of the contract migration:
+    {{ template "shared/contract_gate.sql" (dict
+        "check" "EXISTS (SELECT 1 FROM backfill_progress WHERE name = 'users_email_normalized' AND completed_at IS NOT NULL)"
+        "message" "users.email_normalized backfill has not completed") }}
+    {{ template "shared/contract_gate.sql" (dict
+        "check" "NOT EXISTS (SELECT 1 FROM users WHERE email_normalized IS NULL AND email IS NOT NULL)"
+        "message" "users.email_normalized still has unfilled rows") }}
+*/ -}}
+DO $$
+BEGIN
+    IF NOT COALESCE(({{ .check }}), false) THEN
+        RAISE EXCEPTION 'contract gate failed: %', $gate${{ .message }}$gate$
+            USING HINT = 'Complete the expand phase (dual writes and backfill) before running this migration.';
+    END IF;
+END $$;
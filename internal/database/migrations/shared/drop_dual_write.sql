@@ -0,0 +1,9 @@
+{{- /*
+Drops the trigger created by shared/dual_write.sql, in the contract migration once
+every writer sets the new column, or in the down section of the expand migration.
+
+Usage:
+    {{ template "shared/drop_dual_write.sql" (dict "table" "users" "to" "email_normalized") }}
+*/ -}}
+DROP TRIGGER IF EXISTS {{ .table }}_{{ .to }}_dual_write ON {{ .table }};
+DROP FUNCTION IF EXISTS {{ .table }}_{{ .to }}_dual_write();
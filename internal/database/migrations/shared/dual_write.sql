@@ -0,0 +1,27 @@
+{{- /*
+Keeps a column added by an expand migration in sync with the column it replaces while
+old and new code run side by side. Writes changing `from` without setting `to` get
+`to` filled from `expression` (NEW.<from> by default), writes setting `to` themselves
+are left alone, so new code writing both columns wins. Existing rows are filled by a
+database.Backfill, the contract migration drops the trigger with shared/drop_dual_write.sql.
+
+Usage, after ALTER TABLE users ADD COLUMN email_normalized TEXT:
+    {{ template "shared/dual_write.sql" (dict "table" "users" "from" "email" "to" "email_normalized" "expression" "lower(NEW.email)") }}
+*/ -}}
+CREATE FUNCTION {{ .table }}_{{ .to }}_dual_write() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.{{ .to }} IS NULL THEN
+            NEW.{{ .to }} := {{ if .expression }}{{ .expression }}{{ else }}NEW.{{ .from }}{{ end }};
+        END IF;
+    ELSIF NEW.{{ .from }} IS DISTINCT FROM OLD.{{ .from }} AND NEW.{{ .to }} IS NOT DISTINCT FROM OLD.{{ .to }} THEN
+        NEW.{{ .to }} := {{ if .expression }}{{ .expression }}{{ else }}NEW.{{ .from }}{{ end }};
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER {{ .table }}_{{ .to }}_dual_write
+    BEFORE INSERT OR UPDATE ON {{ .table }}
+    FOR EACH ROW EXECUTE FUNCTION {{ .table }}_{{ .to }}_dual_write();
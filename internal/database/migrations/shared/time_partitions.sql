@@ -0,0 +1,31 @@
+{{- /*
+Creates the default partition of a table partitioned by RANGE on a timestamptz column,
+plus one partition per interval ("day" or "month", UTC) from the `from` SQL expression
+until `ahead` intervals after now. Names and bounds match database.PartitionedTable,
+which keeps creating partitions ahead of time once the table exists.
+
+Usage, after CREATE TABLE ... PARTITION BY RANGE (created_at):
+    {{ template "shared/time_partitions.sql" (dict "table" "events" "interval" "month" "from" "now()" "ahead" 3) }}
+*/ -}}
+-- Rows outside every partition land here instead of failing the insert.
+CREATE TABLE {{ .table }}_default PARTITION OF {{ .table }} DEFAULT;
+
+DO $$
+DECLARE
+    bound timestamp;
+BEGIN
+    FOR bound IN
+        SELECT generate_series(
+            date_trunc('{{ .interval }}', ({{ .from }}) AT TIME ZONE 'UTC'),
+            date_trunc('{{ .interval }}', now() AT TIME ZONE 'UTC') + interval '{{ .ahead }} {{ .interval }}',
+            interval '1 {{ .interval }}'
+        )
+    LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF {{ .table }} FOR VALUES FROM (%L) TO (%L)',
+            '{{ .table }}_p' || to_char(bound, '{{ if eq .interval "day" }}YYYY_MM_DD{{ else }}YYYY_MM{{ end }}'),
+            bound AT TIME ZONE 'UTC',
+            (bound + interval '1 {{ .interval }}') AT TIME ZONE 'UTC'
+        );
+    END LOOP;
+END $$;
@@ -5,9 +5,6 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
-	"net"
-	"net/url"
-	"strconv"
 
 	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
 	"github.com/jackc/pgx/v5"
@@ -15,18 +12,26 @@ import (
 	"github.com/rs/zerolog"
 )
 
-//go:embed migrations/*.sql
+//go:embed migrations/*.sql migrations/shared/*.sql
 var migrationFS embed.FS
 
-func Migrate(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) error {
-	hostPort := net.JoinHostPort(cfg.Database.Host, strconv.Itoa(cfg.Database.Port))
+// Migrate applies every pending migration, reporting its progress to progress if not nil.
+func Migrate(ctx context.Context, logger *zerolog.Logger, cfg *config.Config, progress *MigrationProgress) error {
+	dsn, err := cfg.Database.DSN()
+	if err != nil {
+		return fmt.Errorf("failed to build database dsn: %w", err)
+	}
 
-	// URL-encode the database password
-	password := url.QueryEscape(cfg.Database.Password)
-	dsn := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s", cfg.Database.User, password, hostPort, cfg.Database.Name, cfg.Database.SSLMode)
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return fmt.Errorf("failed to parse database dsn: %w", err)
+	}
+
+	// Migrations may legitimately run longer than the statement timeout meant for requests.
+	delete(connConfig.RuntimeParams, "statement_timeout")
 
 	// Use a single database connection for migrations.
-	dbConn, err := pgx.Connect(ctx, dsn)
+	dbConn, err := pgx.ConnectConfig(ctx, connConfig)
 	if err != nil {
 		return err
 	}
@@ -34,21 +39,9 @@ func Migrate(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) er
 	// Close DB connection when migration is finish.
 	defer dbConn.Close(ctx)
 
-	// Create a new migrator instance with the database connection and the schema version table name.
-	migrator, err := tern.NewMigrator(ctx, dbConn, "schema_version")
+	migrator, err := newMigrator(ctx, dbConn)
 	if err != nil {
-		return fmt.Errorf("failed to create migrator: %w", err)
-	}
-
-	// Access the "migrations" subdirectory from the embedded filesystem
-	fsImplementation, err := fs.Sub(migrationFS, "migrations")
-	if err != nil {
-		return fmt.Errorf("failed to get sub filesystem: %w", err)
-	}
-
-	// Load all SQL migration files into the migrator.
-	if err := migrator.LoadMigrations(fsImplementation); err != nil {
-		return fmt.Errorf("failed to load migrations: %w", err)
+		return err
 	}
 
 	// Get the current migration version before applying new migrations.
@@ -57,11 +50,27 @@ func Migrate(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) er
 		return fmt.Errorf("failed to get current migration version: %w", err)
 	}
 
+	if progress != nil {
+		progress.start(version, int32(len(migrator.Migrations)))
+		migrator.OnStart = func(sequence int32, name, _, _ string) {
+			progress.applying(sequence, name)
+			logger.Info().Int32("version", sequence).Str("migration", name).Msg("applying migration")
+		}
+	}
+
 	// Apply all pending migrations to update the database schema.
 	if err := migrator.Migrate(ctx); err != nil {
+		if progress != nil {
+			current, _ := migrator.GetCurrentVersion(ctx)
+			progress.finish(current, err)
+		}
 		return err
 	}
 
+	if progress != nil {
+		progress.finish(int32(len(migrator.Migrations)), nil)
+	}
+
 	// Log the migration result.
 	// If the version hasn't changed, the database was already up to date.
 	// Otherwise, log the old and new version numbers.
@@ -73,3 +82,52 @@ func Migrate(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) er
 
 	return nil
 }
+
+// MigrationStatus returns the schema version of the database behind conn and
+// the latest version known to this binary.
+func MigrationStatus(ctx context.Context, conn *pgx.Conn) (current int32, latest int32, err error) {
+	migrator, err := newMigrator(ctx, conn)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	current, err = migrator.GetCurrentVersion(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get current migration version: %w", err)
+	}
+
+	return current, int32(len(migrator.Migrations)), nil
+}
+
+// MigrateConn applies every pending migration to the database behind conn, e.g. a
+// scratch database the schema is inspected in.
+func MigrateConn(ctx context.Context, conn *pgx.Conn) error {
+	migrator, err := newMigrator(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Migrate(ctx)
+}
+
+// newMigrator creates a migrator for conn with every embedded migration loaded.
+func newMigrator(ctx context.Context, conn *pgx.Conn) (*tern.Migrator, error) {
+	// Create a new migrator instance with the database connection and the schema version table name.
+	migrator, err := tern.NewMigrator(ctx, conn, "schema_version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+
+	// Access the "migrations" subdirectory from the embedded filesystem
+	fsImplementation, err := fs.Sub(migrationFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub filesystem: %w", err)
+	}
+
+	// Load all SQL migration files into the migrator.
+	if err := migrator.LoadMigrations(fsImplementation); err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	return migrator, nil
+}
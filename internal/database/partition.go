@@ -0,0 +1,250 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PartitionInterval string
+
+const (
+	PartitionDaily   PartitionInterval = "day"
+	PartitionMonthly PartitionInterval = "month"
+)
+
+// PartitionedTable describes a table partitioned by RANGE on a timestamptz column, one
+// partition per UTC day or month named <table>_pYYYY_MM_DD or <table>_pYYYY_MM.
+// Migrations create such tables with the shared/time_partitions.sql template, which
+// follows the same naming, and the partition maintenance job keeps them going.
+type PartitionedTable struct {
+	Name     string
+	Column   string
+	Interval PartitionInterval
+}
+
+// Partition is one child table covering [From, Until).
+type Partition struct {
+	Name  string
+	From  time.Time
+	Until time.Time
+}
+
+// PartitionedTables lists every time-partitioned table the maintenance job manages.
+// Register new partitioned tables here.
+var PartitionedTables = []PartitionedTable{
+	{Name: "audit_logs", Column: "created_at", Interval: PartitionMonthly},
+}
+
+func (t PartitionedTable) nameLayout() string {
+	if t.Interval == PartitionDaily {
+		return "2006_01_02"
+	}
+	return "2006_01"
+}
+
+func (t PartitionedTable) defaultPartition() string {
+	return t.Name + "_default"
+}
+
+// PartitionFor returns the partition ts belongs to.
+func (t PartitionedTable) PartitionFor(ts time.Time) Partition {
+	ts = ts.UTC()
+
+	var from, until time.Time
+	if t.Interval == PartitionDaily {
+		from = time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)
+		until = from.AddDate(0, 0, 1)
+	} else {
+		from = time.Date(ts.Year(), ts.Month(), 1, 0, 0, 0, 0, time.UTC)
+		until = from.AddDate(0, 1, 0)
+	}
+
+	return Partition{
+		Name:  t.Name + "_p" + from.Format(t.nameLayout()),
+		From:  from,
+		Until: until,
+	}
+}
+
+// ListPartitions returns the partitions of t ordered by range, the default partition
+// and children not following the naming scheme are left out.
+func (db *Database) ListPartitions(ctx context.Context, t PartitionedTable) ([]Partition, error) {
+	query := `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = $1::regclass
+		ORDER BY c.relname
+	`
+
+	rows, err := db.Pool.Query(ctx, query, t.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions of %s: %w", t.Name, err)
+	}
+
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:pg_inherits: %w", err)
+	}
+
+	partitions := make([]Partition, 0, len(names))
+	for _, name := range names {
+		suffix, ok := strings.CutPrefix(name, t.Name+"_p")
+		if !ok {
+			continue
+		}
+
+		from, err := time.Parse(t.nameLayout(), suffix)
+		if err != nil {
+			continue
+		}
+
+		partitions = append(partitions, t.PartitionFor(from))
+	}
+
+	return partitions, nil
+}
+
+// EnsurePartitions creates the partitions covering now and the ahead intervals after it,
+// skipping existing ones, and returns the partitions it created.
+func (db *Database) EnsurePartitions(ctx context.Context, t PartitionedTable, now time.Time, ahead int) ([]Partition, error) {
+	existing, err := db.ListPartitions(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	exists := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		exists[p.Name] = true
+	}
+
+	var created []Partition
+	next := t.PartitionFor(now)
+	for i := 0; i <= ahead; i++ {
+		if !exists[next.Name] {
+			if err := db.createPartition(ctx, t, next); err != nil {
+				return created, err
+			}
+			created = append(created, next)
+		}
+
+		next = t.PartitionFor(next.Until)
+	}
+
+	return created, nil
+}
+
+// createPartition creates p detached, moves rows of its range out of the default
+// partition and attaches it. Attaching directly would fail if the default partition
+// already holds rows of the range, e.g. when the maintenance job didn't run for a while.
+func (db *Database) createPartition(ctx context.Context, t PartitionedTable, p Partition) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	parent := pgx.Identifier{t.Name}.Sanitize()
+	child := pgx.Identifier{p.Name}.Sanitize()
+	column := pgx.Identifier{t.Column}.Sanitize()
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`, child, parent)); err != nil {
+		return fmt.Errorf("failed to create partition %s: %w", p.Name, err)
+	}
+
+	var hasDefault bool
+	if err := tx.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, t.defaultPartition()).Scan(&hasDefault); err != nil {
+		return fmt.Errorf("failed to look up default partition of %s: %w", t.Name, err)
+	}
+
+	if hasDefault {
+		move := fmt.Sprintf(`
+			WITH moved AS (
+				DELETE FROM %s WHERE %s >= $1 AND %s < $2 RETURNING *
+			)
+			INSERT INTO %s SELECT * FROM moved
+		`, pgx.Identifier{t.defaultPartition()}.Sanitize(), column, column, child)
+
+		if _, err := tx.Exec(ctx, move, p.From, p.Until); err != nil {
+			return fmt.Errorf("failed to move rows of %s out of the default partition: %w", p.Name, err)
+		}
+	}
+
+	// DDL doesn't take bind parameters, the bounds are generated so inlining them is safe.
+	attach := fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`,
+		parent, child, p.From.Format(time.RFC3339), p.Until.Format(time.RFC3339))
+	if _, err := tx.Exec(ctx, attach); err != nil {
+		return fmt.Errorf("failed to attach partition %s: %w", p.Name, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit partition %s: %w", p.Name, err)
+	}
+
+	return nil
+}
+
+// DropPartitionsBefore detaches and drops every partition of t ending at or before cutoff.
+// With requireEmpty partitions still holding rows are kept and returned as skipped,
+// callers that archive rows first use it so unarchived data is never dropped.
+func (db *Database) DropPartitionsBefore(ctx context.Context, t PartitionedTable, cutoff time.Time, requireEmpty bool) (dropped, skipped []Partition, err error) {
+	partitions, err := db.ListPartitions(ctx, t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, p := range partitions {
+		if p.Until.After(cutoff) {
+			continue
+		}
+
+		child := pgx.Identifier{p.Name}.Sanitize()
+
+		if requireEmpty {
+			var hasRows bool
+			if err := db.Pool.QueryRow(ctx, fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s)`, child)).Scan(&hasRows); err != nil {
+				return dropped, skipped, fmt.Errorf("failed to check partition %s for rows: %w", p.Name, err)
+			}
+			if hasRows {
+				skipped = append(skipped, p)
+				continue
+			}
+		}
+
+		if err := db.dropPartition(ctx, t, p); err != nil {
+			return dropped, skipped, err
+		}
+		dropped = append(dropped, p)
+	}
+
+	return dropped, skipped, nil
+}
+
+func (db *Database) dropPartition(ctx context.Context, t PartitionedTable, p Partition) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	parent := pgx.Identifier{t.Name}.Sanitize()
+	child := pgx.Identifier{p.Name}.Sanitize()
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`ALTER TABLE %s DETACH PARTITION %s`, parent, child)); err != nil {
+		return fmt.Errorf("failed to detach partition %s: %w", p.Name, err)
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`DROP TABLE %s`, child)); err != nil {
+		return fmt.Errorf("failed to drop partition %s: %w", p.Name, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit dropping partition %s: %w", p.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	whitespacePattern    = regexp.MustCompile(`\s+`)
+	stringLiteralPattern = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numberLiteralPattern = regexp.MustCompile(`(^|[^$\w.])\d+(?:\.\d+)?`)
+)
+
+type queryShapesKey struct{}
+
+// QueryShapes counts the queries of one request by shape, the SQL with its literals
+// replaced, so the same query run once per row of an earlier result (N+1) stands out.
+// It is safe for concurrent use.
+type QueryShapes struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// RepeatedQuery is a query shape and how often it ran.
+type RepeatedQuery struct {
+	SQL   string
+	Count int
+}
+
+// WithQueryShapes returns ctx carrying a new QueryShapes, which the development
+// tracer counts the queries made with ctx in.
+func WithQueryShapes(ctx context.Context) (context.Context, *QueryShapes) {
+	shapes := &QueryShapes{counts: make(map[string]int)}
+	return context.WithValue(ctx, queryShapesKey{}, shapes), shapes
+}
+
+// QueryShapesFromContext returns the QueryShapes of ctx, or nil.
+func QueryShapesFromContext(ctx context.Context) *QueryShapes {
+	shapes, _ := ctx.Value(queryShapesKey{}).(*QueryShapes)
+	return shapes
+}
+
+func (qs *QueryShapes) add(sql string) {
+	shape := queryShape(sql)
+
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	qs.counts[shape]++
+}
+
+// Repeated returns the shapes run at least threshold times, most frequent first.
+func (qs *QueryShapes) Repeated(threshold int) []RepeatedQuery {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	var repeated []RepeatedQuery
+	for sql, count := range qs.counts {
+		if count >= threshold {
+			repeated = append(repeated, RepeatedQuery{SQL: sql, Count: count})
+		}
+	}
+
+	slices.SortFunc(repeated, func(a, b RepeatedQuery) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.SQL, b.SQL)
+	})
+
+	return repeated
+}
+
+// queryShape normalizes whitespace and replaces inline literals with "?", so queries
+// differing only in those count as one. Placeholders ($1) are kept as they are.
+func queryShape(sql string) string {
+	shape := stringLiteralPattern.ReplaceAllString(sql, "?")
+	shape = numberLiteralPattern.ReplaceAllString(shape, "${1}?")
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(shape, " "))
+}
+
+// queryShapeTracer counts queries in the QueryShapes of their request, if it has one.
+type queryShapeTracer struct{}
+
+func (qt *queryShapeTracer) TraceQueryStart(ctx context.Context, connection *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if shapes := QueryShapesFromContext(ctx); shapes != nil {
+		shapes.add(data.SQL)
+	}
+	return ctx
+}
+
+func (qt *queryShapeTracer) TraceQueryEnd(ctx context.Context, connection *pgx.Conn, data pgx.TraceQueryEndData) {
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+//go:embed seeds/*.sql
+var seedFS embed.FS
+
+// Seed fills a migrated database with the data local development needs, running every
+// file in seeds/ in name order within one transaction. Seeds must be idempotent
+// (e.g. ON CONFLICT DO NOTHING), they run on every "dev up".
+func Seed(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) error {
+	dsn, err := cfg.Database.DSN()
+	if err != nil {
+		return fmt.Errorf("failed to build database dsn: %w", err)
+	}
+
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	names, err := fs.Glob(seedFS, "seeds/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		for _, name := range names {
+			sql, err := seedFS.ReadFile(name)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec(ctx, string(sql)); err != nil {
+				return fmt.Errorf("failed to run seed %s: %w", name, err)
+			}
+			logger.Info().Str("seed", name).Msg("applied seed")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,4 @@
+-- Admins may do anything locally, nothing is allowed without a policy otherwise.
+INSERT INTO authz_policies (subject, action, resource, effect, description)
+VALUES ('role:admin', '*', '*', 'allow', 'Seeded for local development')
+ON CONFLICT (subject, action, resource, effect) DO NOTHING;
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxApplicationNameLength is NAMEDATALEN-1, Postgres silently truncates longer names.
+const maxApplicationNameLength = 63
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the ID of the HTTP request it belongs to.
+// Transactions started with Begin or BeginTx tag their connection with it.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID, or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+type statementTimeoutKey struct{}
+
+// WithStatementTimeout returns a context whose transactions may spend at most d on a
+// single statement, overriding the connection's statement_timeout. Zero keeps the default.
+func WithStatementTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutKey{}, d)
+}
+
+// StatementTimeoutFromContext returns the timeout stored by WithStatementTimeout, or zero.
+func StatementTimeoutFromContext(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(statementTimeoutKey{}).(time.Duration)
+	return d
+}
+
+// Begin starts a transaction tagged with the request ID from ctx, see BeginTx.
+func (db *Database) Begin(ctx context.Context) (pgx.Tx, error) {
+	return db.BeginTx(ctx, pgx.TxOptions{})
+}
+
+// BeginTx starts a transaction and, when ctx carries a request ID, tags the connection
+// for the lifetime of the transaction (the equivalent of SET LOCAL):
+//   - application_name gets the request ID appended, so it shows up in pg_stat_activity
+//     and in the server's slow query log next to the statement.
+//   - app.request_id holds the bare ID for use in triggers and audit functions.
+//
+// A timeout from WithStatementTimeout is applied the same way to statement_timeout, so a
+// statement running past it fails with SQLSTATE 57014, which sqlerr turns into a 504.
+func (db *Database) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	tx, err := db.Pool.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		_, err = tx.Exec(ctx, "SELECT set_config('application_name', $1, true), set_config('app.request_id', $2, true)",
+			truncateApplicationName(db.applicationName+" req:"+requestID), requestID)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return nil, fmt.Errorf("failed to tag transaction with request id: %w", err)
+		}
+	}
+
+	if timeout := StatementTimeoutFromContext(ctx); timeout > 0 {
+		_, err = tx.Exec(ctx, "SELECT set_config('statement_timeout', $1, true)", strconv.FormatInt(timeout.Milliseconds(), 10))
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return nil, fmt.Errorf("failed to set statement timeout: %w", err)
+		}
+	}
+
+	return tx, nil
+}
+
+// applicationName returns the configured application name, or one built from the
+// service name, environment and version, e.g. "marketmind-production-1a2b3c4".
+func applicationName(cfg *config.Config) string {
+	if cfg.Database.ApplicationName != "" {
+		return truncateApplicationName(cfg.Database.ApplicationName)
+	}
+
+	serviceName := "go-boilerplate"
+	if cfg.Observability != nil && cfg.Observability.ServiceName != "" {
+		serviceName = cfg.Observability.ServiceName
+	}
+
+	return truncateApplicationName(fmt.Sprintf("%s-%s-%s", serviceName, cfg.Primary.Env, cfg.Primary.GetVersion()))
+}
+
+// setApplicationName is used as the pool's AfterConnect hook.
+func setApplicationName(name string) func(ctx context.Context, conn *pgx.Conn) error {
+	return func(ctx context.Context, conn *pgx.Conn) error {
+		if _, err := conn.Exec(ctx, "SELECT set_config('application_name', $1, false)", name); err != nil {
+			return fmt.Errorf("failed to set application_name: %w", err)
+		}
+		return nil
+	}
+}
+
+func truncateApplicationName(name string) string {
+	if len(name) > maxApplicationNameLength {
+		return name[:maxApplicationNameLength]
+	}
+	return name
+}
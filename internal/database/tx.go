@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type txKey struct{}
+
+// txState is the transaction carried by a context and the hooks waiting for its commit.
+// Nested calls share the hooks of the outermost transaction.
+type txState struct {
+	tx          pgx.Tx
+	afterCommit *[]func(ctx context.Context)
+}
+
+// TxManager runs functions within a transaction carried by their context, so code deep
+// in a call chain can join it (TxFromContext) or defer work until it commits (AfterCommit).
+type TxManager struct {
+	db *Database
+}
+
+func NewTxManager(db *Database) *TxManager {
+	return &TxManager{
+		db: db,
+	}
+}
+
+// WithinTx runs fn in a transaction that is committed if fn returns nil and rolled back
+// otherwise. If ctx already carries a transaction fn runs in a savepoint of it, so its
+// failure only undoes its own writes; the outermost call commits. AfterCommit hooks run
+// after the outermost commit, with ctx, and are dropped on rollback.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if outer, ok := ctx.Value(txKey{}).(*txState); ok {
+		return withinSavepoint(ctx, outer, fn)
+	}
+
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	state := &txState{tx: tx, afterCommit: new([]func(ctx context.Context))}
+	if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, hook := range *state.afterCommit {
+		hook(ctx)
+	}
+
+	return nil
+}
+
+// withinSavepoint runs fn in a savepoint of the outer transaction, released if fn
+// returns nil and rolled back to otherwise.
+func withinSavepoint(ctx context.Context, outer *txState, fn func(ctx context.Context) error) error {
+	savepoint, err := outer.tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	defer savepoint.Rollback(ctx)
+
+	// Hooks of a rolled back savepoint are dropped with it
+	hooks := len(*outer.afterCommit)
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{tx: savepoint, afterCommit: outer.afterCommit})); err != nil {
+		*outer.afterCommit = (*outer.afterCommit)[:hooks]
+		return err
+	}
+
+	if err := savepoint.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// ContextWithTx returns a ctx carrying tx as if WithinTx had started it, so code run with
+// it joins tx. Whoever began tx ends it; AfterCommit hooks registered on it never run.
+// Tests use it to run code in a transaction they roll back.
+func ContextWithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, &txState{tx: tx, afterCommit: new([]func(ctx context.Context))})
+}
+
+// TxFromContext returns the transaction started by WithinTx that ctx carries.
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+	return state.tx, true
+}
+
+// AfterCommit defers hook until the transaction carried by ctx commits; it never runs
+// if the transaction rolls back. It returns false, without registering the hook, if
+// ctx carries no transaction, callers then run the work right away.
+func AfterCommit(ctx context.Context, hook func(ctx context.Context)) bool {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return false
+	}
+
+	*state.afterCommit = append(*state.afterCommit, hook)
+	return true
+}
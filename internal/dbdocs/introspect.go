@@ -0,0 +1,241 @@
+// Package dbdocs documents the database schema: it reads the tables, columns,
+// constraints, indexes and comments of a migrated database from the Postgres catalogs
+// and renders them as Markdown or HTML with a mermaid ER diagram. Comments come from
+// COMMENT ON statements in the migrations, so the docs are written next to the schema.
+package dbdocs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Schema is the documented part of a database.
+type Schema struct {
+	Name   string
+	Tables []Table
+}
+
+type Table struct {
+	Name    string
+	Comment string
+	// Partitioned tables are documented once, their partitions aren't.
+	Partitioned bool
+	Columns     []Column
+	Constraints []Constraint
+	Indexes     []Index
+}
+
+type Column struct {
+	Name       string
+	Type       string
+	Nullable   bool
+	Default    string
+	Comment    string
+	PrimaryKey bool
+	Unique     bool
+	// References is the table a foreign key on this column alone points to.
+	References string
+}
+
+// Constraint types, as in pg_constraint.contype.
+const (
+	ConstraintPrimaryKey = "p"
+	ConstraintForeignKey = "f"
+	ConstraintUnique     = "u"
+	ConstraintCheck      = "c"
+	ConstraintExclusion  = "x"
+)
+
+type Constraint struct {
+	Name       string
+	Type       string
+	Definition string
+	Columns    []string
+	// References is the referenced table of a foreign key.
+	References string
+}
+
+type Index struct {
+	Name       string
+	Definition string
+	// Constraint reports indexes backing a primary key or unique constraint.
+	Constraint bool
+}
+
+// ignoredTables are bookkeeping tables, not part of the documented schema.
+var ignoredTables = []string{"schema_version"}
+
+// Load reads the tables of schema (usually "public").
+func Load(ctx context.Context, conn *pgx.Conn, schema string) (*Schema, error) {
+	s := &Schema{Name: schema}
+
+	rows, err := conn.Query(ctx, `
+		SELECT c.oid, c.relname::text, COALESCE(obj_description(c.oid, 'pg_class'), ''), c.relkind = 'p'
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1
+			AND c.relkind IN ('r', 'p')
+			AND NOT c.relispartition
+			AND c.relname <> ALL($2)
+		ORDER BY c.relname
+	`, schema, ignoredTables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	var oids []uint32
+	for rows.Next() {
+		var oid uint32
+		var table Table
+		if err := rows.Scan(&oid, &table.Name, &table.Comment, &table.Partitioned); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read table: %w", err)
+		}
+		oids = append(oids, oid)
+		s.Tables = append(s.Tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	for i := range s.Tables {
+		table := &s.Tables[i]
+
+		if table.Columns, err = loadColumns(ctx, conn, oids[i]); err != nil {
+			return nil, fmt.Errorf("table %s: %w", table.Name, err)
+		}
+		if table.Constraints, err = loadConstraints(ctx, conn, oids[i]); err != nil {
+			return nil, fmt.Errorf("table %s: %w", table.Name, err)
+		}
+		if table.Indexes, err = loadIndexes(ctx, conn, oids[i]); err != nil {
+			return nil, fmt.Errorf("table %s: %w", table.Name, err)
+		}
+
+		annotateColumns(table)
+	}
+
+	return s, nil
+}
+
+func loadColumns(ctx context.Context, conn *pgx.Conn, oid uint32) ([]Column, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT a.attname::text,
+			format_type(a.atttypid, a.atttypmod),
+			NOT a.attnotnull,
+			COALESCE(pg_get_expr(d.adbin, d.adrelid), ''),
+			COALESCE(col_description(a.attrelid, a.attnum), '')
+		FROM pg_attribute a
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum
+	`, oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list columns: %w", err)
+	}
+
+	columns, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Column, error) {
+		var column Column
+		err := row.Scan(&column.Name, &column.Type, &column.Nullable, &column.Default, &column.Comment)
+		return column, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	return columns, nil
+}
+
+func loadConstraints(ctx context.Context, conn *pgx.Conn, oid uint32) ([]Constraint, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT con.conname::text,
+			con.contype::text,
+			pg_get_constraintdef(con.oid),
+			COALESCE(ARRAY(
+				SELECT a.attname::text FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, position)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.position
+			), '{}'),
+			COALESCE(ref.relname::text, '')
+		FROM pg_constraint con
+		LEFT JOIN pg_class ref ON ref.oid = con.confrelid
+		WHERE con.conrelid = $1
+		ORDER BY con.contype, con.conname
+	`, oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list constraints: %w", err)
+	}
+
+	constraints, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Constraint, error) {
+		var constraint Constraint
+		err := row.Scan(&constraint.Name, &constraint.Type, &constraint.Definition, &constraint.Columns, &constraint.References)
+		return constraint, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read constraints: %w", err)
+	}
+
+	return constraints, nil
+}
+
+func loadIndexes(ctx context.Context, conn *pgx.Conn, oid uint32) ([]Index, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT c.relname::text,
+			pg_get_indexdef(i.indexrelid),
+			EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = i.indexrelid AND con.contype IN ('p', 'u', 'x'))
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		WHERE i.indrelid = $1
+		ORDER BY c.relname
+	`, oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+
+	indexes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Index, error) {
+		var index Index
+		err := row.Scan(&index.Name, &index.Definition, &index.Constraint)
+		return index, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// annotateColumns marks the columns covered by single-column keys.
+func annotateColumns(table *Table) {
+	byName := make(map[string]*Column, len(table.Columns))
+	for i := range table.Columns {
+		byName[table.Columns[i].Name] = &table.Columns[i]
+	}
+
+	for _, constraint := range table.Constraints {
+		if constraint.Type == ConstraintPrimaryKey {
+			for _, name := range constraint.Columns {
+				if column, ok := byName[name]; ok {
+					column.PrimaryKey = true
+				}
+			}
+			continue
+		}
+
+		if len(constraint.Columns) != 1 {
+			continue
+		}
+		column, ok := byName[constraint.Columns[0]]
+		if !ok {
+			continue
+		}
+
+		switch constraint.Type {
+		case ConstraintUnique:
+			column.Unique = true
+		case ConstraintForeignKey:
+			column.References = constraint.References
+		}
+	}
+}
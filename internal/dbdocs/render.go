@@ -0,0 +1,244 @@
+package dbdocs
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// header marks the documents as generated, so they aren't edited by hand.
+const header = "Generated by go-boilerplate gen dbdocs from the migrated schema. DO NOT EDIT."
+
+// Mermaid renders the tables and their foreign keys as a mermaid ER diagram.
+func Mermaid(s *Schema) string {
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+
+	for _, table := range s.Tables {
+		fmt.Fprintf(&b, "    %s {\n", table.Name)
+		for _, column := range table.Columns {
+			var keys []string
+			if column.PrimaryKey {
+				keys = append(keys, "PK")
+			}
+			if column.References != "" {
+				keys = append(keys, "FK")
+			}
+			if column.Unique {
+				keys = append(keys, "UK")
+			}
+
+			fmt.Fprintf(&b, "        %s %s", mermaidType(column.Type), column.Name)
+			if len(keys) > 0 {
+				fmt.Fprintf(&b, " %s", strings.Join(keys, ","))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("    }\n")
+	}
+
+	for _, table := range s.Tables {
+		for _, constraint := range table.Constraints {
+			if constraint.Type != ConstraintForeignKey || constraint.References == "" {
+				continue
+			}
+
+			// A nullable reference is optional on the referencing side
+			cardinality := "||"
+			if nullable(table, constraint.Columns) {
+				cardinality = "o|"
+			}
+			fmt.Fprintf(&b, "    %s }o--%s %s : %q\n", table.Name, cardinality, constraint.References, strings.Join(constraint.Columns, ", "))
+		}
+	}
+
+	return b.String()
+}
+
+// nullable reports whether any of columns of table is nullable.
+func nullable(table Table, columns []string) bool {
+	for _, column := range table.Columns {
+		for _, name := range columns {
+			if column.Name == name && column.Nullable {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var mermaidUnsafe = regexp.MustCompile(`[^A-Za-z0-9_()\[\]-]+`)
+
+// mermaidType turns a SQL type into a mermaid attribute type, which can't contain
+// spaces or commas, e.g. "timestamp with time zone" into "timestamp_with_time_zone".
+func mermaidType(sqlType string) string {
+	return mermaidUnsafe.ReplaceAllString(sqlType, "_")
+}
+
+// Markdown renders the schema as a Markdown document, the ER diagram as a mermaid block.
+func Markdown(s *Schema) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "<!-- %s -->\n\n", header)
+	fmt.Fprintf(&b, "# Database schema\n\n")
+	fmt.Fprintf(&b, "%d tables in schema `%s`.\n\n", len(s.Tables), s.Name)
+
+	b.WriteString("```mermaid\n")
+	b.WriteString(Mermaid(s))
+	b.WriteString("```\n\n")
+
+	b.WriteString("## Tables\n\n")
+	for _, table := range s.Tables {
+		fmt.Fprintf(&b, "- [%s](#%s)\n", table.Name, table.Name)
+	}
+
+	for _, table := range s.Tables {
+		fmt.Fprintf(&b, "\n## %s\n\n", table.Name)
+		if table.Comment != "" {
+			fmt.Fprintf(&b, "%s\n\n", table.Comment)
+		}
+		if table.Partitioned {
+			b.WriteString("Partitioned table, its partitions share this structure.\n\n")
+		}
+
+		b.WriteString("| Column | Type | Nullable | Default | Keys | Description |\n")
+		b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+		for _, column := range table.Columns {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+				cell(column.Name), cell(column.Type), yesNo(column.Nullable), code(column.Default), cell(columnKeys(column)), cell(column.Comment))
+		}
+
+		if len(table.Constraints) > 0 {
+			b.WriteString("\n**Constraints**\n\n")
+			for _, constraint := range table.Constraints {
+				fmt.Fprintf(&b, "- `%s`: `%s`\n", constraint.Name, constraint.Definition)
+			}
+		}
+
+		if len(table.Indexes) > 0 {
+			b.WriteString("\n**Indexes**\n\n")
+			for _, index := range table.Indexes {
+				fmt.Fprintf(&b, "- `%s`: `%s`\n", index.Name, index.Definition)
+			}
+		}
+	}
+
+	return b.Bytes()
+}
+
+// columnKeys describes the keys a column is part of, e.g. "PK" or "FK → users".
+func columnKeys(column Column) string {
+	var keys []string
+	if column.PrimaryKey {
+		keys = append(keys, "PK")
+	}
+	if column.Unique {
+		keys = append(keys, "unique")
+	}
+	if column.References != "" {
+		keys = append(keys, "FK → "+column.References)
+	}
+	return strings.Join(keys, ", ")
+}
+
+// cell escapes text for a Markdown table cell.
+func cell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+func code(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "`" + cell(text) + "`"
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+var htmlTemplate = template.Must(template.New("schema").Funcs(template.FuncMap{
+	"keys":  columnKeys,
+	"yesNo": yesNo,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="generator" content="{{.Header}}">
+<title>Database schema</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem auto; max-width: 72rem; padding: 0 1rem; color: #1f2328; }
+table { border-collapse: collapse; width: 100%; margin: 1rem 0; }
+th, td { border: 1px solid #d0d7de; padding: .35rem .6rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+code { font-size: .9em; }
+</style>
+</head>
+<body>
+<h1>Database schema</h1>
+<p>{{len .Schema.Tables}} tables in schema <code>{{.Schema.Name}}</code>.</p>
+<pre class="mermaid">
+{{.Diagram}}</pre>
+<ul>
+{{- range .Schema.Tables}}
+<li><a href="#{{.Name}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+{{- range .Schema.Tables}}
+<h2 id="{{.Name}}">{{.Name}}</h2>
+{{- if .Comment}}
+<p>{{.Comment}}</p>
+{{- end}}
+{{- if .Partitioned}}
+<p>Partitioned table, its partitions share this structure.</p>
+{{- end}}
+<table>
+<tr><th>Column</th><th>Type</th><th>Nullable</th><th>Default</th><th>Keys</th><th>Description</th></tr>
+{{- range .Columns}}
+<tr><td>{{.Name}}</td><td>{{.Type}}</td><td>{{yesNo .Nullable}}</td><td>{{if .Default}}<code>{{.Default}}</code>{{end}}</td><td>{{keys .}}</td><td>{{.Comment}}</td></tr>
+{{- end}}
+</table>
+{{- if .Constraints}}
+<h3>Constraints</h3>
+<ul>
+{{- range .Constraints}}
+<li><code>{{.Name}}</code>: <code>{{.Definition}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Indexes}}
+<h3>Indexes</h3>
+<ul>
+{{- range .Indexes}}
+<li><code>{{.Name}}</code>: <code>{{.Definition}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+<script type="module">
+import mermaid from "https://cdn.jsdelivr.net/npm/mermaid@11/dist/mermaid.esm.min.mjs";
+mermaid.initialize({ startOnLoad: true });
+</script>
+</body>
+</html>
+`))
+
+// HTML renders the schema as a standalone HTML page, the ER diagram drawn by mermaid.
+func HTML(s *Schema) ([]byte, error) {
+	var b bytes.Buffer
+	err := htmlTemplate.Execute(&b, map[string]any{
+		"Header":  header,
+		"Schema":  s,
+		"Diagram": Mermaid(s),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render schema docs: %w", err)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+// Package enumgen generates string enum types from a JSON declaration file.
+// Every generated type validates itself when bound from JSON or query params,
+// when scanned from or written to the database, and through the "enum"
+// validator tag, so an invalid status can't slip in at any boundary.
+package enumgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// Declarations is the content of a declaration file.
+type Declarations struct {
+	Enums []Enum `json:"enums"`
+}
+
+// Enum declares one enum type.
+type Enum struct {
+	// Name is the Go type name, e.g. DataExportStatus.
+	Name string `json:"name"`
+	// Doc is the doc comment of the type, without the leading "//".
+	Doc string `json:"doc"`
+	// Prefix is prepended to every constant name, defaults to Name.
+	Prefix string  `json:"prefix"`
+	Values []Value `json:"values"`
+}
+
+// Value is one member of an enum.
+type Value struct {
+	// Name is the constant suffix, e.g. Pending for DataExportStatusPending.
+	Name string `json:"name"`
+	// Value is the string stored in the database and sent over the wire.
+	Value string `json:"value"`
+}
+
+var (
+	identifier = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	// enumValue keeps values safe to embed in Go string literals and SQL CHECK constraints.
+	enumValue = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+)
+
+// LoadDeclarations reads and validates a declaration file.
+func LoadDeclarations(path string) (*Declarations, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var decl Declarations
+	if err := json.Unmarshal(data, &decl); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if err := decl.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid declarations in %s: %w", path, err)
+	}
+
+	return &decl, nil
+}
+
+// Validate checks names are exported identifiers and neither types nor values repeat.
+func (d *Declarations) Validate() error {
+	types := make(map[string]bool, len(d.Enums))
+
+	for _, enum := range d.Enums {
+		if !identifier.MatchString(enum.Name) {
+			return fmt.Errorf("enum name %q must be an exported Go identifier", enum.Name)
+		}
+		if types[enum.Name] {
+			return fmt.Errorf("enum %s is declared twice", enum.Name)
+		}
+		types[enum.Name] = true
+
+		if len(enum.Values) == 0 {
+			return fmt.Errorf("enum %s has no values", enum.Name)
+		}
+
+		names := make(map[string]bool, len(enum.Values))
+		values := make(map[string]bool, len(enum.Values))
+		for _, v := range enum.Values {
+			if !identifier.MatchString(v.Name) {
+				return fmt.Errorf("enum %s: value name %q must be an exported Go identifier", enum.Name, v.Name)
+			}
+			if !enumValue.MatchString(v.Value) {
+				return fmt.Errorf("enum %s: value %s (%q) may only contain letters, digits, '_', '.', ':' and '-'", enum.Name, v.Name, v.Value)
+			}
+			if names[v.Name] || values[v.Value] {
+				return fmt.Errorf("enum %s: value %s (%q) is declared twice", enum.Name, v.Name, v.Value)
+			}
+			names[v.Name] = true
+			values[v.Value] = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package enumgen
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+)
+
+// Generate renders every declared enum as Go source for package pkg.
+func Generate(decl *Declarations, pkg string) ([]byte, error) {
+	var buf strings.Builder
+
+	buf.WriteString("// Code generated by go-boilerplate gen enums. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n\"database/sql/driver\"\n\"fmt\"\n)\n", pkg)
+
+	for _, enum := range decl.Enums {
+		writeEnum(&buf, enum)
+	}
+
+	source, err := format.Source([]byte(buf.String()))
+	if err != nil {
+		return nil, fmt.Errorf("generated code does not compile: %w", err)
+	}
+
+	return source, nil
+}
+
+func writeEnum(buf *strings.Builder, enum Enum) {
+	name := enum.Name
+	prefix := enum.Prefix
+	if prefix == "" {
+		prefix = name
+	}
+	list := lowerFirst(name) + "Values"
+
+	values := make([]string, len(enum.Values))
+	for i, v := range enum.Values {
+		values[i] = v.Value
+	}
+	allowed := strings.Join(values, " | ")
+
+	p := func(format string, args ...any) {
+		fmt.Fprintf(buf, format, args...)
+	}
+
+	p("\n")
+	if enum.Doc != "" {
+		for _, line := range strings.Split(strings.TrimSpace(enum.Doc), "\n") {
+			p("// %s\n", line)
+		}
+	}
+	p("type %s string\n\nconst (\n", name)
+	for _, v := range enum.Values {
+		p("%s%s %s = %q\n", prefix, v.Name, name, v.Value)
+	}
+	p(")\n\n")
+
+	p("// %s lists every valid %s in declaration order.\n", list, name)
+	p("var %s = []%s{", list, name)
+	for i, v := range enum.Values {
+		if i > 0 {
+			p(", ")
+		}
+		p("%s%s", prefix, v.Name)
+	}
+	p("}\n\n")
+
+	p("// %sValues returns every valid %s in declaration order.\n", name, name)
+	p("func %sValues() []%s {\nreturn append([]%s(nil), %s...)\n}\n\n", name, name, name, list)
+
+	p("// Parse%s returns the %s matching s, or an error if s is not declared.\n", name, name)
+	p("func Parse%s(s string) (%s, error) {\n", name, name)
+	p("for _, v := range %s {\nif string(v) == s {\nreturn v, nil\n}\n}\n", list)
+	p("return \"\", fmt.Errorf(\"invalid %s %%q: must be one of %s\", s)\n}\n\n", name, allowed)
+
+	p("// IsValid reports whether e is a declared %s.\n", name)
+	p("func (e %s) IsValid() bool {\n_, err := Parse%s(string(e))\nreturn err == nil\n}\n\n", name, name)
+
+	p("// EnumValues returns the declared values, the \"enum\" validator tag lists them in its message.\n")
+	p("func (e %s) EnumValues() []string {\nreturn []string{", name)
+	for i, v := range values {
+		if i > 0 {
+			p(", ")
+		}
+		p("%q", v)
+	}
+	p("}\n}\n\n")
+
+	p("func (e %s) String() string {\nreturn string(e)\n}\n\n", name)
+
+	p("// UnmarshalText rejects undeclared values when binding JSON bodies and query params.\n")
+	p("func (e *%s) UnmarshalText(text []byte) error {\n", name)
+	p("v, err := Parse%s(string(text))\nif err != nil {\nreturn err\n}\n*e = v\nreturn nil\n}\n\n", name)
+
+	p("// Scan implements sql.Scanner, rejecting values the database should never hold.\n")
+	p("func (e *%s) Scan(src any) error {\nswitch v := src.(type) {\n", name)
+	p("case string:\nreturn e.UnmarshalText([]byte(v))\n")
+	p("case []byte:\nreturn e.UnmarshalText(v)\n")
+	p("default:\nreturn fmt.Errorf(\"cannot scan %%T into %s\", src)\n}\n}\n\n", name)
+
+	p("// Value implements driver.Valuer, refusing to write undeclared values.\n")
+	p("func (e %s) Value() (driver.Value, error) {\n", name)
+	p("if !e.IsValid() {\nreturn nil, fmt.Errorf(\"invalid %s %%q: must be one of %s\", string(e))\n}\n", name, allowed)
+	p("return string(e), nil\n}\n")
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
@@ -0,0 +1,201 @@
+package errs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// codePattern is the shape of every error code, e.g. "EXPORT_TOO_LARGE".
+var codePattern = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+
+// placeholderPattern matches the placeholders of generated codes, e.g. "{ENTITY}".
+var placeholderPattern = regexp.MustCompile(`\{[A-Z]+\}`)
+
+// CatalogEntry documents one error code.
+type CatalogEntry struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+	// Pattern marks a family of generated codes, Code holds placeholders such as
+	// "{ENTITY}" standing for any upper case word.
+	Pattern bool `json:"pattern,omitempty"`
+}
+
+// Catalog is the registry of the error codes the API responds with. Codes are
+// registered where they are emitted, registering a code twice panics, so two features
+// can't silently share a code with different meanings.
+type Catalog struct {
+	mu       sync.RWMutex
+	entries  map[string]CatalogEntry
+	patterns map[string]*regexp.Regexp
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{
+		entries:  make(map[string]CatalogEntry),
+		patterns: make(map[string]*regexp.Regexp),
+	}
+}
+
+// Codes is the catalog of the API, exported with "go-boilerplate gen errors".
+var Codes = NewCatalog()
+
+// Register adds code to Codes and returns it, so codes are declared once next to where
+// they are emitted:
+//
+//	var codeExportTooLarge = errs.Register("EXPORT_TOO_LARGE", http.StatusBadRequest, "The export has too many rows to be built synchronously.")
+func Register(code string, status int, description string) string {
+	return Codes.Register(code, status, description)
+}
+
+// RegisterPattern adds a family of generated codes to Codes, see Catalog.RegisterPattern.
+func RegisterPattern(pattern string, status int, description string) string {
+	return Codes.RegisterPattern(pattern, status, description)
+}
+
+// Register adds code, emitted with status. It panics if code is malformed or registered
+// already, both are programming errors caught at startup.
+func (c *Catalog) Register(code string, status int, description string) string {
+	if !codePattern.MatchString(code) {
+		panic(fmt.Sprintf("errs: error code %q is not UPPER_SNAKE_CASE", code))
+	}
+
+	c.add(CatalogEntry{Code: code, Status: status, Description: description})
+	return code
+}
+
+// RegisterPattern adds the codes matching pattern, e.g. "{ENTITY}_NOT_FOUND" for codes
+// built from table names. Codes registered on their own take precedence over patterns.
+func (c *Catalog) RegisterPattern(pattern string, status int, description string) string {
+	parts := placeholderPattern.Split(pattern, -1)
+	if len(parts) < 2 {
+		panic(fmt.Sprintf("errs: error code pattern %q has no placeholder", pattern))
+	}
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	c.add(CatalogEntry{Code: pattern, Status: status, Description: description, Pattern: true})
+
+	c.mu.Lock()
+	c.patterns[pattern] = regexp.MustCompile("^" + strings.Join(parts, "[A-Z0-9]+(?:_[A-Z0-9]+)*") + "$")
+	c.mu.Unlock()
+
+	return pattern
+}
+
+func (c *Catalog) add(entry CatalogEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.entries[entry.Code]; ok {
+		panic(fmt.Sprintf("errs: error code %s is registered twice (status %d: %q and status %d: %q)",
+			entry.Code, existing.Status, existing.Description, entry.Status, entry.Description))
+	}
+	c.entries[entry.Code] = entry
+}
+
+// Lookup returns the entry of code, or of the pattern it matches.
+func (c *Catalog) Lookup(code string) (CatalogEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if entry, ok := c.entries[code]; ok && !entry.Pattern {
+		return entry, true
+	}
+
+	for pattern, re := range c.patterns {
+		if re.MatchString(code) {
+			return c.entries[pattern], true
+		}
+	}
+
+	return CatalogEntry{}, false
+}
+
+// Check reports whether code is registered for status.
+func (c *Catalog) Check(code string, status int) error {
+	entry, ok := c.Lookup(code)
+	if !ok {
+		return fmt.Errorf("error code %s is not registered in the catalog", code)
+	}
+	if entry.Status != status {
+		return fmt.Errorf("error code %s is registered with status %d, emitted with %d", code, entry.Status, status)
+	}
+	return nil
+}
+
+// Entries returns every entry, ordered by code.
+func (c *Catalog) Entries() []CatalogEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries := make([]CatalogEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b CatalogEntry) int {
+		return strings.Compare(a.Code, b.Code)
+	})
+
+	return entries
+}
+
+// JSON renders the catalog as a JSON array of entries, ordered by code.
+func (c *Catalog) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(c.Entries(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Markdown renders the catalog as a table, for frontend teams mapping codes to messages.
+func (c *Catalog) Markdown() []byte {
+	var b bytes.Buffer
+	b.WriteString("# Error codes\n\n")
+	b.WriteString("Every error response carries one of these codes. Generated by `go-boilerplate gen errors`, do not edit.\n")
+	b.WriteString("Codes with placeholders such as `{ENTITY}` stand for a family of codes, e.g. `USER_ALREADY_EXISTS`.\n\n")
+	b.WriteString("| Code | Status | Description |\n")
+	b.WriteString("| --- | --- | --- |\n")
+
+	for _, entry := range c.Entries() {
+		fmt.Fprintf(&b, "| `%s` | %d %s | %s |\n", entry.Code, entry.Status, http.StatusText(entry.Status), strings.ReplaceAll(entry.Description, "|", "\\|"))
+	}
+
+	return b.Bytes()
+}
+
+// statusCode registers the generic code of status, the one errors without a code of
+// their own get (e.g. "NOT_FOUND").
+func statusCode(status int, description string) string {
+	return Register(MakeUpperCaseWithUnderscores(http.StatusText(status)), status, description)
+}
+
+// Generic codes, derived from the status text of errors without a code of their own.
+var (
+	_ = statusCode(http.StatusBadRequest, "The request is malformed or failed validation, fields lists the invalid fields.")
+	_ = statusCode(http.StatusUnauthorized, "The request is not authenticated.")
+	_ = statusCode(http.StatusPaymentRequired, "The request needs a paid plan.")
+	_ = statusCode(http.StatusForbidden, "The caller is not allowed to do this.")
+	_ = statusCode(http.StatusNotFound, "The resource or route doesn't exist.")
+	_ = statusCode(http.StatusMethodNotAllowed, "The route doesn't support the request method.")
+	_ = statusCode(http.StatusNotAcceptable, "None of the accepted response formats is supported.")
+	_ = statusCode(http.StatusRequestTimeout, "The request took too long to be received.")
+	_ = statusCode(http.StatusConflict, "The request conflicts with the current state of the resource.")
+	_ = statusCode(http.StatusRequestEntityTooLarge, "The request body is too large.")
+	_ = statusCode(http.StatusUnsupportedMediaType, "The request body has an unsupported content type.")
+	_ = statusCode(http.StatusUnprocessableEntity, "The request is well-formed but can't be processed.")
+	_ = statusCode(http.StatusLocked, "The resource is locked.")
+	_ = statusCode(http.StatusTooManyRequests, "Too many requests, retry later.")
+	_ = statusCode(http.StatusInternalServerError, "An unexpected error occurred on the server.")
+	_ = statusCode(http.StatusBadGateway, "A dependency of the API returned an invalid response.")
+	_ = statusCode(http.StatusServiceUnavailable, "The API or one of its dependencies is temporarily unavailable.")
+	_ = statusCode(http.StatusGatewayTimeout, "The request took too long to complete.")
+)
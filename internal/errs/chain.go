@@ -0,0 +1,144 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	pkgErrors "github.com/pkg/errors"
+)
+
+// maxChainLength bounds the walk down an error chain, so a cyclic Unwrap can't hang logging.
+const maxChainLength = 32
+
+// ChainLink is one level of a wrapped error.
+type ChainLink struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// Chain unwraps err level by level, including errors joined with errors.Join,
+// and returns the type and message of every level, outermost first.
+func Chain(err error) []ChainLink {
+	var chain []ChainLink
+
+	queue := []error{err}
+	for len(queue) > 0 && len(chain) < maxChainLength {
+		current := queue[0]
+		queue = queue[1:]
+		if current == nil {
+			continue
+		}
+
+		chain = append(chain, ChainLink{
+			Type:    fmt.Sprintf("%T", current),
+			Message: current.Error(),
+		})
+
+		switch wrapped := current.(type) {
+		case interface{ Unwrap() error }:
+			queue = append(queue, wrapped.Unwrap())
+		case interface{ Unwrap() []error }:
+			queue = append(queue, wrapped.Unwrap()...)
+		}
+	}
+
+	return chain
+}
+
+// RootType returns the type of the innermost error in the chain.
+func RootType(err error) string {
+	chain := Chain(err)
+	if len(chain) == 0 {
+		return ""
+	}
+	return chain[len(chain)-1].Type
+}
+
+type stackTracer interface {
+	StackTrace() pkgErrors.StackTrace
+}
+
+// StackFrames returns the stack recorded where the error was created, as "function file:line".
+// Errors created with github.com/pkg/errors carry a stack; the deepest one in the chain is used
+// since it points closest to the origin. It returns nil when no error in the chain has a stack.
+func StackFrames(err error) []string {
+	var stack pkgErrors.StackTrace
+
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if tracer, ok := current.(stackTracer); ok {
+			stack = tracer.StackTrace()
+		}
+	}
+
+	frames := make([]string, 0, len(stack))
+	for _, frame := range stack {
+		frames = append(frames, strings.TrimSpace(fmt.Sprintf("%n %s:%d", frame, frame, frame)))
+	}
+
+	return frames
+}
+
+// Class tells whether an error is expected in normal operation or points at a bug.
+type Class string
+
+const (
+	// ClassOperational errors come from the outside world: bad input, missing records,
+	// timeouts, cancelled requests, unreachable dependencies. Retrying or fixing the
+	// environment resolves them.
+	ClassOperational Class = "operational"
+	// ClassProgrammer errors are bugs: unexpected states, invalid queries, panics.
+	// Teams should alert on them.
+	ClassProgrammer Class = "programmer"
+)
+
+// Classify returns the class of an error that resulted in the given HTTP status.
+// Client errors (4xx) are always operational; server errors are operational only
+// when caused by a known infrastructure condition.
+func Classify(err error, status int) Class {
+	if status < 500 {
+		return ClassOperational
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return ClassOperational
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ClassOperational
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return ClassOperational
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && isOperationalSQLState(pgErr.Code) {
+		return ClassOperational
+	}
+
+	return ClassProgrammer
+}
+
+// isOperationalSQLState reports SQLSTATE classes caused by load or the environment rather
+// than by the query: connection exceptions (08), serialization failures and deadlocks (40),
+// insufficient resources (53) and operator intervention such as cancelled queries (57).
+func isOperationalSQLState(code string) bool {
+	if len(code) < 2 {
+		return false
+	}
+
+	switch code[:2] {
+	case "08", "40", "53", "57":
+		return true
+	}
+
+	return false
+}
@@ -14,6 +14,15 @@ type ActionType string
 
 const (
 	ActionTypeRedirect ActionType = "redirect"
+	// ActionTypeRechallenge asks the frontend to show the CAPTCHA widget again and
+	// retry with a fresh token, Value names the provider.
+	ActionTypeRechallenge ActionType = "rechallenge"
+	// ActionTypeRetryLater tells the frontend the feature is temporarily off and to
+	// retry later rather than right away, Message says why.
+	ActionTypeRetryLater ActionType = "retry_later"
+	// ActionTypeVerifyEmail asks the frontend to take the user through email verification,
+	// Value is the endpoint sending the verification link.
+	ActionTypeVerifyEmail ActionType = "verify_email"
 )
 
 type Action struct {
@@ -11,6 +11,23 @@ func ForbididdenError(message string, override bool) *HttpError {
 	}
 }
 
+// ForbiddenCodeError is a 403 with a code of its own, action tells the client how to be let in.
+func ForbiddenCodeError(message string, override bool, code *string, action *Action) *HttpError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusForbidden))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HttpError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusForbidden,
+		Override: override,
+		Action:   action,
+	}
+}
+
 func UnauthorizedError(message string, override bool) *HttpError {
 	return &HttpError{
 		Code:     MakeUpperCaseWithUnderscores(http.StatusText(http.StatusUnauthorized)),
@@ -64,3 +81,109 @@ func NotFoundError(message string, override bool, code *string) *HttpError {
 func validationError() *HttpError {
 	return BadRequestError("validation unsuccessful", false, nil, nil, nil)
 }
+
+func PaymentRequiredError(message string, override bool, code *string) *HttpError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusPaymentRequired))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HttpError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusPaymentRequired,
+		Override: override,
+	}
+}
+
+func TooManyRequestsError(message string, override bool, code *string) *HttpError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusTooManyRequests))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HttpError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusTooManyRequests,
+		Override: override,
+	}
+}
+
+func GatewayTimeoutError(message string, override bool, code *string) *HttpError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusGatewayTimeout))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HttpError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusGatewayTimeout,
+		Override: override,
+	}
+}
+
+func LockedError(message string, override bool, code *string) *HttpError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusLocked))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HttpError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusLocked,
+		Override: override,
+	}
+}
+
+func ServiceUnavailableError(message string, override bool, code *string, action *Action) *HttpError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusServiceUnavailable))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HttpError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusServiceUnavailable,
+		Override: override,
+		Action:   action,
+	}
+}
+
+func PayloadTooLargeError(message string, override bool, code *string) *HttpError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusRequestEntityTooLarge))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HttpError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusRequestEntityTooLarge,
+		Override: override,
+	}
+}
+
+func UnsupportedMediaTypeError(message string, override bool, code *string) *HttpError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusUnsupportedMediaType))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HttpError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusUnsupportedMediaType,
+		Override: override,
+	}
+}
@@ -0,0 +1,182 @@
+// Package graphql serves the optional GraphQL API at /graphql. gqlgen generates its
+// executor from schema.graphql, see gqlgen.yml, and the resolver stubs in
+// schema.resolvers.go, which call the same services as the REST handlers for the caller
+// requestctx carries. Related records are loaded through per-request dataloaders so a list
+// resolves them with one query, not one per item.
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/99designs/gqlgen/graphql/executor"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/dataloader"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// API executes queries against the schema.
+type API struct {
+	server   *server.Server
+	services *service.Services
+	executor *executor.Executor
+}
+
+// New builds the executor of the generated schema with the configured limits, the New
+// Relic tracer and, where enabled, introspection.
+func New(s *server.Server, services *service.Services) *API {
+	cfg := s.Config.GraphQL
+
+	introspection := s.Config.Primary.Env == "local" || s.Config.Primary.Env == "development"
+	if cfg.Introspection != nil {
+		introspection = *cfg.Introspection
+	}
+
+	exec := executor.New(NewExecutableSchema(Config{Resolvers: &Resolver{services: services}}))
+	// Panics are returned as resolver errors, which the handler logs and hides like any
+	// other unexpected error
+	exec.SetRecoverFunc(func(_ context.Context, err any) error {
+		return fmt.Errorf("graphql resolver panicked: %v", err)
+	})
+	exec.Use(limits{maxDepth: cfg.MaxDepth, maxQueryLength: cfg.MaxQueryLength})
+	exec.Use(newRelicTracer{})
+	if introspection {
+		exec.Use(extension.Introspection{})
+	}
+
+	return &API{
+		server:   s,
+		services: services,
+		executor: exec,
+	}
+}
+
+// Exec runs a query for the caller ctx carries, with dataloaders of its own.
+func (a *API) Exec(ctx context.Context, query, operationName string, variables map[string]any) *graphql.Response {
+	ctx = context.WithValue(ctx, loadersKey{}, a.newLoaders())
+	ctx = context.WithValue(ctx, workersKey{}, make(chan struct{}, a.server.Config.GraphQL.MaxParallelism))
+	ctx = graphql.StartOperationTrace(ctx)
+
+	params := &graphql.RawParams{
+		Query:         query,
+		OperationName: operationName,
+		Variables:     variables,
+		ReadTime: graphql.TraceTiming{
+			Start: graphql.GetStartTime(ctx),
+			End:   graphql.Now(),
+		},
+	}
+
+	opCtx, errs := a.executor.CreateOperationContext(ctx, params)
+	if errs != nil {
+		return a.executor.DispatchError(graphql.WithOperationContext(ctx, opCtx), errs)
+	}
+
+	responses, ctx := a.executor.DispatchOperation(ctx, opCtx)
+	return responses(ctx)
+}
+
+type loadersKey struct{}
+
+// loaders batch the loads of one request's resolvers.
+type loaders struct {
+	limits *dataloader.Loader[string, *model.TenantLimits]
+	// subscriptions are keyed by owner, "org:<id>" or "user:<id>", see middleware.GetQuotaConsumer.
+	subscriptions *dataloader.Loader[string, []model.Subscription]
+}
+
+func (a *API) newLoaders() *loaders {
+	wait, size := a.server.Config.GraphQL.BatchWait, a.server.Config.GraphQL.BatchSize
+
+	return &loaders{
+		limits:        dataloader.New(a.services.TenantService.GetLimitsByOrganizations, wait, size),
+		subscriptions: dataloader.New(a.services.PaymentService.ListSubscriptionsByOwners, wait, size),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// workersKey holds the semaphore bounding how many resolvers of a request run at once.
+type workersKey struct{}
+
+// limits rejects queries longer or nested deeper than configured, before they run, and
+// bounds the resolvers of a query running in parallel.
+type limits struct {
+	maxDepth       int
+	maxQueryLength int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationParameterMutator
+	graphql.OperationContextMutator
+	graphql.FieldInterceptor
+} = limits{}
+
+func (limits) ExtensionName() string {
+	return "Limits"
+}
+
+func (limits) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (l limits) MutateOperationParameters(_ context.Context, params *graphql.RawParams) *gqlerror.Error {
+	if len(params.Query) > l.maxQueryLength {
+		err := gqlerror.Errorf("query length %d exceeds the maximum allowed query length of %d bytes", len(params.Query), l.maxQueryLength)
+		errcode.Set(err, errcode.ValidationFailed)
+		return err
+	}
+
+	return nil
+}
+
+func (l limits) MutateOperationContext(_ context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	if depth := selectionDepth(opCtx.Operation.SelectionSet); depth > l.maxDepth {
+		err := gqlerror.Errorf("query has depth %d, which exceeds max depth of %d", depth, l.maxDepth)
+		errcode.Set(err, errcode.ValidationFailed)
+		return err
+	}
+
+	return nil
+}
+
+func (limits) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	// Only resolvers do work, the other fields read a struct
+	if !graphql.GetFieldContext(ctx).IsResolver {
+		return next(ctx)
+	}
+
+	workers := ctx.Value(workersKey{}).(chan struct{})
+	workers <- struct{}{}
+	defer func() { <-workers }()
+
+	return next(ctx)
+}
+
+// selectionDepth is how deeply the fields of selections nest, through fragments.
+func selectionDepth(selections ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			depth = max(depth, 1+selectionDepth(selection.SelectionSet))
+		case *ast.InlineFragment:
+			depth = max(depth, selectionDepth(selection.SelectionSet))
+		case *ast.FragmentSpread:
+			if selection.Definition != nil {
+				depth = max(depth, selectionDepth(selection.Definition.SelectionSet))
+			}
+		}
+	}
+
+	return depth
+}
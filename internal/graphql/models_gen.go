@@ -0,0 +1,16 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graphql
+
+type Organization struct {
+	ID string `json:"id"`
+}
+
+type Query struct {
+}
+
+type User struct {
+	ID string `json:"id"`
+	// The caller's role in their active organization.
+	Role *string `json:"role,omitempty"`
+}
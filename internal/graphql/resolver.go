@@ -0,0 +1,23 @@
+package graphql
+
+import (
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+)
+
+// maxOrganizations bounds the organizations listed at once.
+const maxOrganizations = 100
+
+// Resolver is the root of the resolvers gqlgen generates into schema.resolvers.go, they
+// resolve through the same services as the REST handlers, for the caller requestctx
+// carries.
+type Resolver struct {
+	services *service.Services
+}
+
+func quotaValue(quota *int64) *float64 {
+	if quota == nil {
+		return nil
+	}
+	value := float64(*quota)
+	return &value
+}
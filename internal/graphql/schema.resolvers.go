@@ -0,0 +1,149 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+)
+
+// Limits is the resolver for the limits field.
+func (r *organizationResolver) Limits(ctx context.Context, obj *Organization) (*model.TenantLimits, error) {
+	return loadersFrom(ctx).limits.Load(ctx, obj.ID)
+}
+
+// Subscriptions is the resolver for the subscriptions field.
+func (r *organizationResolver) Subscriptions(ctx context.Context, obj *Organization) ([]model.Subscription, error) {
+	return loadersFrom(ctx).subscriptions.Load(ctx, "org:"+obj.ID)
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*User, error) {
+	caller := requestctx.Get(ctx)
+
+	user := &User{ID: caller.UserID}
+	if caller.Role != "" {
+		user.Role = &caller.Role
+	}
+
+	return user, nil
+}
+
+// Organization is the resolver for the organization field.
+func (r *queryResolver) Organization(ctx context.Context, id *string) (*Organization, error) {
+	caller := requestctx.Get(ctx)
+
+	organizationID := caller.OrganizationID
+	if id != nil {
+		organizationID = *id
+	}
+	if organizationID == "" {
+		return nil, nil
+	}
+
+	// Callers only read their active organization, platform operators any, as on the
+	// REST admin routes
+	if organizationID != caller.OrganizationID && !caller.Operator {
+		return nil, errs.ForbididdenError("Only platform operators can read other organizations", true)
+	}
+
+	return &Organization{ID: organizationID}, nil
+}
+
+// Organizations is the resolver for the organizations field.
+func (r *queryResolver) Organizations(ctx context.Context, first *int) ([]Organization, error) {
+	if !requestctx.Get(ctx).Operator {
+		return nil, errs.ForbididdenError("Only platform operators can list organizations", true)
+	}
+
+	limit := maxOrganizations
+	if first != nil {
+		limit = min(max(*first, 1), maxOrganizations)
+	}
+
+	organizationIDs, err := r.services.TenantService.ListOrganizationIDs(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	organizations := make([]Organization, len(organizationIDs))
+	for i, organizationID := range organizationIDs {
+		organizations[i] = Organization{ID: organizationID}
+	}
+
+	return organizations, nil
+}
+
+// ID is the resolver for the id field.
+func (r *subscriptionResolver) ID(ctx context.Context, obj *model.Subscription) (string, error) {
+	return obj.ID.String(), nil
+}
+
+// Status is the resolver for the status field.
+func (r *subscriptionResolver) Status(ctx context.Context, obj *model.Subscription) (string, error) {
+	return string(obj.Status), nil
+}
+
+// DailyQuota is the resolver for the dailyQuota field.
+func (r *tenantLimitsResolver) DailyQuota(ctx context.Context, obj *model.TenantLimits) (*float64, error) {
+	return quotaValue(obj.DailyQuota), nil
+}
+
+// MonthlyQuota is the resolver for the monthlyQuota field.
+func (r *tenantLimitsResolver) MonthlyQuota(ctx context.Context, obj *model.TenantLimits) (*float64, error) {
+	return quotaValue(obj.MonthlyQuota), nil
+}
+
+// Organization is the resolver for the organization field.
+func (r *userResolver) Organization(ctx context.Context, obj *User) (*Organization, error) {
+	organizationID := requestctx.Get(ctx).OrganizationID
+	if organizationID == "" {
+		return nil, nil
+	}
+
+	return &Organization{ID: organizationID}, nil
+}
+
+// EmailVerification is the resolver for the emailVerification field.
+func (r *userResolver) EmailVerification(ctx context.Context, obj *User) (*model.EmailVerification, error) {
+	verification, err := r.services.EmailVerification.GetVerification(ctx)
+	if err != nil {
+		// No verification requested yet is null, not an error
+		var httpErr *errs.HttpError
+		if errors.As(err, &httpErr) && httpErr.Status == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return verification, nil
+}
+
+// Subscriptions is the resolver for the subscriptions field.
+func (r *userResolver) Subscriptions(ctx context.Context, obj *User) ([]model.Subscription, error) {
+	return loadersFrom(ctx).subscriptions.Load(ctx, "user:"+obj.ID)
+}
+
+// Organization returns OrganizationResolver implementation.
+func (r *Resolver) Organization() OrganizationResolver { return &organizationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+// TenantLimits returns TenantLimitsResolver implementation.
+func (r *Resolver) TenantLimits() TenantLimitsResolver { return &tenantLimitsResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type organizationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
+type tenantLimitsResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
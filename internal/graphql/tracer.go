@@ -0,0 +1,60 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// newRelicTracer traces queries within the request's New Relic transaction: the operation
+// is recorded as an attribute and every resolver is a segment (e.g.
+// GraphQL/Organization.limits), fields read off a struct are not.
+type newRelicTracer struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = newRelicTracer{}
+
+func (newRelicTracer) ExtensionName() string {
+	return "NewRelicTracer"
+}
+
+func (newRelicTracer) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (newRelicTracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	txn := newrelic.FromContext(ctx)
+	if txn == nil {
+		return next(ctx)
+	}
+
+	if operationName := graphql.GetOperationContext(ctx).OperationName; operationName != "" {
+		txn.AddAttribute("graphql.operation", operationName)
+	}
+
+	response := next(ctx)
+	if response != nil {
+		txn.AddAttribute("graphql.errors", len(response.Errors))
+	}
+
+	return response
+}
+
+func (newRelicTracer) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	txn := newrelic.FromContext(ctx)
+	field := graphql.GetFieldContext(ctx)
+	if !field.IsResolver || txn == nil {
+		return next(ctx)
+	}
+
+	// Resolvers of a query run on goroutines of their own
+	txn = txn.NewGoroutine()
+	segment := txn.StartSegment("GraphQL/" + field.Object + "." + field.Field.Name)
+	defer segment.End()
+
+	return next(newrelic.NewContext(ctx, txn))
+}
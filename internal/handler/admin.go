@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+type AdminHandler struct {
+	Handler
+	adminService  *service.AdminService
+	tenantService *service.TenantService
+}
+
+func NewAdminHandler(s *server.Server, adminService *service.AdminService, tenantService *service.TenantService) *AdminHandler {
+	return &AdminHandler{
+		Handler:       NewHandler(s),
+		adminService:  adminService,
+		tenantService: tenantService,
+	}
+}
+
+// ListAuditLogs returns a page of the audit trail of every user.
+func (h *AdminHandler) ListAuditLogs(c echo.Context) error {
+	params, err := parseListParams(c)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.adminService.ListAuditLogs(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, page)
+}
+
+// ListConfigChanges returns a page of the changes operators made to runtime settings, with old and new values.
+func (h *AdminHandler) ListConfigChanges(c echo.Context) error {
+	params, err := parseListParams(c)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.adminService.ListConfigChanges(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, page)
+}
+
+// ListEmailSuppressions returns a page of the addresses excluded from non-essential emails.
+func (h *AdminHandler) ListEmailSuppressions(c echo.Context) error {
+	params, err := parseListParams(c)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.adminService.ListEmailSuppressions(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, page)
+}
+
+// GetTenantLimits returns the rate limit and quota overrides of an organization.
+func (h *AdminHandler) GetTenantLimits(c echo.Context) error {
+	limits, err := h.tenantService.GetLimits(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, limits)
+}
+
+// UpdateTenantLimits replaces the rate limit and quota overrides of an organization, e.g. when its plan changes.
+func (h *AdminHandler) UpdateTenantLimits(c echo.Context) error {
+	var payload model.UpdateTenantLimitsPayload
+	if err := validation.BindAndValidate(c, &payload); err != nil {
+		return err
+	}
+
+	limits, err := h.tenantService.SetLimits(requestctx.From(c), c.Param("id"), &payload)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, limits)
+}
+
+// DeleteTenantLimits returns an organization to the default rate limit and quotas.
+func (h *AdminHandler) DeleteTenantLimits(c echo.Context) error {
+	if err := h.tenantService.DeleteLimits(requestctx.From(c), c.Param("id")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
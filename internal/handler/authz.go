@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+type AuthzHandler struct {
+	Handler
+	authzService *service.AuthzService
+}
+
+func NewAuthzHandler(s *server.Server, authzService *service.AuthzService) *AuthzHandler {
+	return &AuthzHandler{
+		Handler:      NewHandler(s),
+		authzService: authzService,
+	}
+}
+
+// ListPolicies returns every authorization policy.
+func (h *AuthzHandler) ListPolicies(c echo.Context) error {
+	policies, err := h.authzService.ListPolicies(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, policies)
+}
+
+// CreatePolicy adds an authorization policy, applied without a restart.
+func (h *AuthzHandler) CreatePolicy(c echo.Context) error {
+	var payload model.CreatePolicyPayload
+	if err := validation.BindAndValidate(c, &payload); err != nil {
+		return err
+	}
+
+	policy, err := h.authzService.CreatePolicy(requestctx.From(c), &payload)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusCreated, policy)
+}
+
+// DeletePolicy removes an authorization policy.
+func (h *AuthzHandler) DeletePolicy(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.authzService.DeletePolicy(requestctx.From(c), id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler is embedded by every handler and gives access to the server's dependencies.
+type Handler struct {
+	server *server.Server
+}
+
+// NewHandler returns a base Handler tied to the server.
+func NewHandler(s *server.Server) Handler {
+	return Handler{
+		server: s,
+	}
+}
+
+// respond writes body with the given status, in the format negotiated from the Accept
+// header: JSON by default, MessagePack or Protobuf for clients asking for them.
+func (h Handler) respond(c echo.Context, status int, body any) error {
+	if middleware.RefuseDoubleWrite(c) {
+		return nil
+	}
+
+	s := h.server.Serializers.Negotiate(c.Request().Header.Get(echo.HeaderAccept))
+
+	data, err := s.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	// Caches must not hand a MessagePack response to a client that asked for JSON.
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	return c.Blob(status, s.ContentType(), data)
+}
+
+// parsePageParams reads the page and limit query parameters, failing with a 400 if they are not numbers.
+// Out of range values are clamped by the repository.
+func parsePageParams(c echo.Context) (model.PageParams, error) {
+	var params model.PageParams
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &params); err != nil {
+		return params, errs.BadRequestError("Invalid pagination parameters", false, nil, []errs.FieldError{
+			{Field: "page", Error: "must be a number"},
+			{Field: "limit", Error: "must be a number"},
+		}, nil)
+	}
+
+	return params.Normalize(), nil
+}
+
+// parseTimeRange reads the optional from and until query parameters (RFC 3339), failing with a 400 if they are malformed.
+func parseTimeRange(c echo.Context) (model.TimeRange, error) {
+	var within model.TimeRange
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &within); err != nil {
+		return within, errs.BadRequestError("Invalid time range", false, nil, []errs.FieldError{
+			{Field: "from", Error: "must be an RFC 3339 timestamp"},
+			{Field: "until", Error: "must be an RFC 3339 timestamp"},
+		}, nil)
+	}
+
+	if within.From != nil && within.Until != nil && !within.From.Before(*within.Until) {
+		return within, errs.BadRequestError("Invalid time range", false, nil, []errs.FieldError{
+			{Field: "until", Error: "must be after from"},
+		}, nil)
+	}
+
+	return within, nil
+}
+
+// filterParam matches list filter query parameters, e.g. filter[created_at][gte].
+var filterParam = regexp.MustCompile(`^filter\[([a-z_]+)\]\[([a-z]+)\]$`)
+
+// parseListParams reads the shared listing contract from the query: limit, cursor, sort
+// and filter[<field>][<op>]=<value>. Fields, operators and values are validated by the repository.
+func parseListParams(c echo.Context) (model.ListParams, error) {
+	query := c.QueryParams()
+
+	params := model.ListParams{
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return params, errs.BadRequestError("Invalid list parameters", false, nil, []errs.FieldError{
+				{Field: "limit", Error: "must be a number"},
+			}, nil)
+		}
+		params.Limit = value
+	}
+
+	for name, values := range query {
+		match := filterParam.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		for _, value := range values {
+			params.Filters = append(params.Filters, model.Filter{Field: match[1], Op: model.FilterOp(match[2]), Value: value})
+		}
+	}
+
+	return params.Normalize(), nil
+}
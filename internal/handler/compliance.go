@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/signedurl"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ComplianceHandler struct {
+	Handler
+	complianceService *service.ComplianceService
+}
+
+func NewComplianceHandler(s *server.Server, complianceService *service.ComplianceService) *ComplianceHandler {
+	return &ComplianceHandler{
+		Handler:           NewHandler(s),
+		complianceService: complianceService,
+	}
+}
+
+// RequestDataExport starts an export of all of the authenticated user's data.
+func (h *ComplianceHandler) RequestDataExport(c echo.Context) error {
+	export, err := h.complianceService.RequestDataExport(requestctx.From(c))
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusAccepted, export)
+}
+
+// GetDataExport returns the status of one of the user's exports and, once ready, a signed download link.
+func (h *ComplianceHandler) GetDataExport(c echo.Context) error {
+	exportID, err := parseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	export, err := h.complianceService.GetDataExport(requestctx.From(c), exportID)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, export)
+}
+
+// DownloadDataExport serves an export archive. Access is granted by the URL signature, not by a session.
+func (h *ComplianceHandler) DownloadDataExport(c echo.Context) error {
+	exportID, err := parseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	archive, err := h.complianceService.DownloadDataExport(c.Request().Context(), exportID,
+		c.QueryParam(signedurl.ExpiresParam), c.QueryParam(signedurl.SignatureParam))
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="data-export-`+exportID.String()+`.zip"`)
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	return c.Blob(http.StatusOK, "application/zip", archive)
+}
+
+// RequestAccountDeletion schedules deletion of the authenticated user's account.
+func (h *ComplianceHandler) RequestAccountDeletion(c echo.Context) error {
+	deletion, err := h.complianceService.RequestAccountDeletion(requestctx.From(c))
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusAccepted, deletion)
+}
+
+// CancelAccountDeletion cancels a pending account deletion during its grace period.
+func (h *ComplianceHandler) CancelAccountDeletion(c echo.Context) error {
+	deletion, err := h.complianceService.CancelAccountDeletion(requestctx.From(c))
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, deletion)
+}
+
+// ListAuditLogs returns a page of the authenticated user's audit trail, optionally limited to a time range.
+// Users have few entries each, so an exact total is cheap here.
+func (h *ComplianceHandler) ListAuditLogs(c echo.Context) error {
+	params, err := parsePageParams(c)
+	if err != nil {
+		return err
+	}
+
+	within, err := parseTimeRange(c)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.complianceService.ListAuditLogs(requestctx.From(c), within, params, model.CountExact)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, page)
+}
+
+// parseIDParam reads a UUID path parameter, failing with a 400 if it is malformed.
+func parseIDParam(c echo.Context, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		return uuid.Nil, errs.BadRequestError("Invalid "+name, false, nil, []errs.FieldError{
+			{Field: name, Error: "must be a valid UUID"},
+		}, nil)
+	}
+
+	return id, nil
+}
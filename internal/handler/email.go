@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type EmailHandler struct {
+	Handler
+	emailService *service.EmailService
+}
+
+func NewEmailHandler(s *server.Server, emailService *service.EmailService) *EmailHandler {
+	return &EmailHandler{
+		Handler:      NewHandler(s),
+		emailService: emailService,
+	}
+}
+
+// GetUnsubscribe describes an unsubscribe link, for a page asking the recipient to confirm.
+// Access is granted by the signed token, not by a session.
+func (h *EmailHandler) GetUnsubscribe(c echo.Context) error {
+	status, err := h.emailService.DescribeUnsubscribe(c.Request().Context(), c.QueryParam("token"))
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, status)
+}
+
+// Unsubscribe unsubscribes the recipient of an unsubscribe link from bulk emails. Mailbox
+// providers call it directly for one-click unsubscribes, posting "List-Unsubscribe=One-Click".
+func (h *EmailHandler) Unsubscribe(c echo.Context) error {
+	oneClick := c.FormValue("List-Unsubscribe") == "One-Click"
+
+	status, err := h.emailService.Unsubscribe(c.Request().Context(), c.QueryParam("token"), oneClick)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, status)
+}
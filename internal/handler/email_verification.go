@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+type EmailVerificationHandler struct {
+	Handler
+	verificationService *service.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(s *server.Server, verificationService *service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		Handler:             NewHandler(s),
+		verificationService: verificationService,
+	}
+}
+
+// GetEmailVerification returns whether the caller's email address is verified.
+func (h *EmailVerificationHandler) GetEmailVerification(c echo.Context) error {
+	verification, err := h.verificationService.GetVerification(requestctx.From(c))
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, verification)
+}
+
+// RequestEmailVerification sends a verification link to the caller's email address.
+func (h *EmailVerificationHandler) RequestEmailVerification(c echo.Context) error {
+	var payload model.RequestEmailVerificationPayload
+	if err := validation.BindAndValidate(c, &payload); err != nil {
+		return err
+	}
+
+	verification, err := h.verificationService.RequestVerification(requestctx.From(c), &payload)
+	if err != nil {
+		return err
+	}
+
+	if verification.Verified {
+		return h.respond(c, http.StatusOK, verification)
+	}
+	return h.respond(c, http.StatusAccepted, verification)
+}
+
+// ResendEmailVerification sends a new link to the address pending verification.
+func (h *EmailVerificationHandler) ResendEmailVerification(c echo.Context) error {
+	verification, err := h.verificationService.ResendVerification(requestctx.From(c))
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusAccepted, verification)
+}
+
+// VerifyEmail verifies the address of a verification link, opened from the email.
+// Access is granted by the token, not by a session.
+func (h *EmailVerificationHandler) VerifyEmail(c echo.Context) error {
+	verification, err := h.verificationService.Verify(c.Request().Context(), c.QueryParam("token"))
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, verification)
+}
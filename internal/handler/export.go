@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/signedurl"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+type ExportHandler struct {
+	Handler
+	exportService *service.ExportService
+}
+
+func NewExportHandler(s *server.Server, exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		Handler:       NewHandler(s),
+		exportService: exportService,
+	}
+}
+
+// StreamExport streams a dataset as CSV or XLSX in the response, e.g.
+// ?format=xlsx&columns=created_at,action&from=2025-01-01T00:00:00Z. Headers are
+// localized from Accept-Language.
+func (h *ExportHandler) StreamExport(c echo.Context) error {
+	within, err := parseTimeRange(c)
+	if err != nil {
+		return err
+	}
+
+	var columns []string
+	if raw := c.QueryParam("columns"); raw != "" {
+		columns = strings.Split(raw, ",")
+	}
+
+	prepared, err := h.exportService.PrepareExport(requestctx.From(c), service.ExportRequest{
+		Dataset: c.Param("dataset"),
+		Format:  c.QueryParam("format"),
+		Columns: columns,
+		Within:  within,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, prepared.ContentType)
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+prepared.Filename+`"`)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().WriteHeader(http.StatusOK)
+
+	// The status is already sent, a failure can only cut the file short
+	if _, err := prepared.Stream(c.Request().Context(), c.Response()); err != nil {
+		middleware.GetLogger(c).Error().Err(err).Str("dataset", c.Param("dataset")).Msg("export stream aborted")
+	}
+
+	return nil
+}
+
+// RequestExport starts an async export of a dataset, for exports too large to stream in a request.
+func (h *ExportHandler) RequestExport(c echo.Context) error {
+	var payload model.CreateExportPayload
+	if err := validation.BindAndValidate(c, &payload); err != nil {
+		return err
+	}
+
+	exp, err := h.exportService.RequestExport(requestctx.From(c), c.Param("dataset"), &payload)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusAccepted, exp)
+}
+
+// GetExport returns the status of an async export and, once ready, a signed download link.
+func (h *ExportHandler) GetExport(c echo.Context) error {
+	exportID, err := parseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	exp, err := h.exportService.GetExport(requestctx.From(c), exportID)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, exp)
+}
+
+// DownloadExport serves the file of an async export. Access is granted by the URL signature, not by a session.
+func (h *ExportHandler) DownloadExport(c echo.Context) error {
+	exportID, err := parseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	exp, err := h.exportService.DownloadExport(c.Request().Context(), exportID,
+		c.QueryParam(signedurl.ExpiresParam), c.QueryParam(signedurl.SignatureParam))
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+service.ExportFilename(exp)+`"`)
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	return c.Blob(http.StatusOK, service.ExportContentType(exp), exp.Content)
+}
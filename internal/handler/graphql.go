@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/graphql"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// graphQLRequest is a query as sent by GraphQL clients over HTTP.
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type GraphQLHandler struct {
+	Handler
+	api *graphql.API
+}
+
+func NewGraphQLHandler(s *server.Server, api *graphql.API) *GraphQLHandler {
+	return &GraphQLHandler{
+		Handler: NewHandler(s),
+		api:     api,
+	}
+}
+
+// Query executes a GraphQL query for the caller. Like every GraphQL server it answers
+// 200 with the errors of the query in the response, resolver errors carry the code and
+// status the REST API would have answered with in their extensions.
+func (h *GraphQLHandler) Query(c echo.Context) error {
+	var req graphQLRequest
+	if err := (&echo.DefaultBinder{}).BindBody(c, &req); err != nil || strings.TrimSpace(req.Query) == "" {
+		return errs.BadRequestError("The body must be a JSON GraphQL request with a query", false, nil, nil, nil)
+	}
+
+	response := h.api.Exec(requestctx.From(c), req.Query, req.OperationName, req.Variables)
+
+	for _, queryErr := range response.Errors {
+		if queryErr.Err == nil {
+			// Syntax and validation errors are the client's
+			continue
+		}
+
+		var httpErr *errs.HttpError
+		if errors.As(queryErr.Err, &httpErr) {
+			queryErr.Extensions = map[string]any{"code": httpErr.Code, "status": httpErr.Status}
+			continue
+		}
+
+		// Unexpected errors may carry queries or internals, they are logged, not returned
+		middleware.GetLogger(c).Error().Err(queryErr.Err).Str("path", queryErr.Path.String()).Msg("graphql resolver failed")
+		internal := errs.InternalServerError()
+		queryErr.Message = internal.Message
+		queryErr.Extensions = map[string]any{"code": internal.Code, "status": internal.Status}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -1,12 +1,53 @@
 package handler
 
 import (
+	"github.com/Barry-dE/go-backend-boilerplate/internal/graphql"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
 )
 
-type Handlers struct{}
+type Handlers struct {
+	Health       *HealthHandler
+	OpenAPI      *OpenAPIHandler
+	Compliance   *ComplianceHandler
+	Quota        *QuotaHandler
+	Admin        *AdminHandler
+	Email        *EmailHandler
+	Export       *ExportHandler
+	Import       *ImportHandler
+	Payment      *PaymentHandler
+	Notification *NotificationHandler
+	Static       *StaticHandler
+	Authz        *AuthzHandler
+	Operation    *OperationHandler
+	Usage        *UsageHandler
+	Settings     *SettingsHandler
+	RouteToggle  *RouteToggleHandler
+	Verification *EmailVerificationHandler
+	WellKnown    *WellKnownHandler
+	GraphQL      *GraphQLHandler
+}
 
-func NewHandler(s *server.Server, services *service.Services) *Handlers {
-return &Handlers{}
-}
\ No newline at end of file
+func NewHandlers(s *server.Server, services *service.Services) *Handlers {
+	return &Handlers{
+		Health:       NewHealthHandler(s, services.HealthService),
+		OpenAPI:      NewOpenAPIHandler(s),
+		Compliance:   NewComplianceHandler(s, services.ComplianceService),
+		Quota:        NewQuotaHandler(s, services.QuotaService),
+		Admin:        NewAdminHandler(s, services.AdminService, services.TenantService),
+		Email:        NewEmailHandler(s, services.EmailService),
+		Export:       NewExportHandler(s, services.ExportService),
+		Import:       NewImportHandler(s, services.ImportService),
+		Payment:      NewPaymentHandler(s, services.PaymentService),
+		Notification: NewNotificationHandler(s, services.Notification),
+		Static:       NewStaticHandler(s),
+		Authz:        NewAuthzHandler(s, services.AuthzService),
+		Operation:    NewOperationHandler(s),
+		Usage:        NewUsageHandler(s, services.UsageService),
+		Settings:     NewSettingsHandler(s, services.SettingsService),
+		RouteToggle:  NewRouteToggleHandler(s, services.RouteToggles),
+		Verification: NewEmailVerificationHandler(s, services.EmailVerification),
+		WellKnown:    NewWellKnownHandler(s),
+		GraphQL:      NewGraphQLHandler(s, graphql.New(s, services)),
+	}
+}
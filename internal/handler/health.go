@@ -6,21 +6,46 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/backup"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
 	"github.com/labstack/echo/v4"
 )
 
 type HealthHandler struct {
 	Handler
+	healthService *service.HealthService
 }
 
-func NewHealthHandler(s *server.Server) *HealthHandler {
+func NewHealthHandler(s *server.Server, healthService *service.HealthService) *HealthHandler {
 	return &HealthHandler{
-		Handler: NewHandler(s),
+		Handler:       NewHandler(s),
+		healthService: healthService,
 	}
 }
 
+// DeepHealthCheck exercises the database, the job queue and email delivery end to end,
+// for synthetic monitors. It is slow and has side effects, so it is admin-only.
+func (h *HealthHandler) DeepHealthCheck(c echo.Context) error {
+	result := h.healthService.DeepCheck(c.Request().Context())
+
+	status := http.StatusOK
+	if result.Status != "healthy" {
+		status = http.StatusServiceUnavailable
+	}
+
+	return h.respond(c, status, result)
+}
+
+// PoolStats returns the database and Redis pool stats last sampled by the pool collector,
+// for scrapers and dashboards outside New Relic. They are empty until the first collection.
+func (h *HealthHandler) PoolStats(c echo.Context) error {
+	return h.respond(c, http.StatusOK, h.server.PoolCollector.Snapshot())
+}
+
 func (h *HealthHandler) HealthCheck(c echo.Context) error {
 	start := time.Now()
 	logger := middleware.GetLogger(c).With().Str("operation", "health_check").Logger()
@@ -38,7 +63,7 @@ func (h *HealthHandler) HealthCheck(c echo.Context) error {
 	isHealthy := true
 
 	// Add database connectivity check
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), time.Second*5)
 	defer cancel()
 
 	databaseTimerStart := time.Now()
@@ -53,16 +78,14 @@ func (h *HealthHandler) HealthCheck(c echo.Context) error {
 		isHealthy = false
 		logger.Error().Err(err).Dur("response_time", time.Since(databaseTimerStart)).Msg("database health check failed")
 
-		// Record New Relic custom event for database health check failure
-		if h.server.LoggerService != nil && h.server.LoggerService.GetNewRelicApp() != nil {
-			h.server.LoggerService.GetNewRelicApp().RecordCustomEvent("HealthCheckError", map[string]interface{}{
-				"operation":        "health_check",
-				"check_type":       "database_health",
-				"error_type":       "database_unhealthy",
-				"response_time_ms": time.Since(databaseTimerStart).Milliseconds(),
-				"error_message":    err.Error(),
-			})
-		}
+		// Record an event for the database health check failure
+		observe.Event("HealthCheckError", map[string]any{
+			"operation":        "health_check",
+			"check_type":       "database_health",
+			"error_type":       "database_unhealthy",
+			"response_time_ms": time.Since(databaseTimerStart).Milliseconds(),
+			"error_message":    err.Error(),
+		})
 
 	} else {
 		checks["database"] = map[string]interface{}{
@@ -74,7 +97,7 @@ func (h *HealthHandler) HealthCheck(c echo.Context) error {
 
 	// check Redis connectivity if enabled
 	if h.server.Redis != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		ctx, cancel := context.WithTimeout(c.Request().Context(), time.Second*5)
 		defer cancel()
 
 		redisStartTimer := time.Now()
@@ -89,15 +112,13 @@ func (h *HealthHandler) HealthCheck(c echo.Context) error {
 
 			logger.Error().Err(err).Dur("response_time", time.Since(redisStartTimer)).Msg("redis health check failed")
 
-			if h.server.LoggerService != nil && h.server.LoggerService.GetNewRelicApp() != nil {
-				h.server.LoggerService.GetNewRelicApp().RecordCustomEvent("HealthCheckError", map[string]interface{}{
-					"operation":        "health_check",
-					"check_type":       "redis_health",
-					"error_type":       "redis_unhealthy",
-					"response_time_ms": time.Since(redisStartTimer).Milliseconds(),
-					"error_message":    err.Error(),
-				})
-			}
+			observe.Event("HealthCheckError", map[string]any{
+				"operation":        "health_check",
+				"check_type":       "redis_health",
+				"error_type":       "redis_unhealthy",
+				"response_time_ms": time.Since(redisStartTimer).Milliseconds(),
+				"error_message":    err.Error(),
+			})
 		} else {
 			checks["redis"] = map[string]interface{}{
 				"status":        "healthy",
@@ -108,6 +129,12 @@ func (h *HealthHandler) HealthCheck(c echo.Context) error {
 		}
 	}
 
+	// Report backup freshness. It doesn't affect the overall status, a missed backup is
+	// no reason to take the instance out of rotation, alert on the "stale" status instead.
+	if h.server.Config.Backup.Enabled && h.server.Redis != nil {
+		checks["backup"] = h.backupCheck(c.Request().Context())
+	}
+
 	// Overall health status
 	if !isHealthy {
 
@@ -115,14 +142,12 @@ func (h *HealthHandler) HealthCheck(c echo.Context) error {
 
 		logger.Warn().Dur("total_duration", time.Since(start)).Msg("health check failed")
 
-		if h.server.LoggerService != nil && h.server.LoggerService.GetNewRelicApp() != nil {
-			h.server.LoggerService.GetNewRelicApp().RecordCustomEvent("HealthCheckError", map[string]interface{}{
-				"operation":              "health_check",
-				"check_type":             "overall_health",
-				"error_type":             "overall_unhealthy",
-				"total_response_time_ms": time.Since(start).Milliseconds(),
-			})
-		}
+		observe.Event("HealthCheckError", map[string]any{
+			"operation":              "health_check",
+			"check_type":             "overall_health",
+			"error_type":             "overall_unhealthy",
+			"total_response_time_ms": time.Since(start).Milliseconds(),
+		})
 
 		return c.JSON(http.StatusServiceUnavailable, response)
 	}
@@ -133,17 +158,47 @@ func (h *HealthHandler) HealthCheck(c echo.Context) error {
 
 		logger.Error().Err(err).Msg("failed to write JSON response")
 
-		if h.server.LoggerService != nil && h.server.LoggerService.GetNewRelicApp() != nil {
-			h.server.LoggerService.GetNewRelicApp().RecordCustomEvent("HealthCheckError", map[string]interface{}{
-				"operation":     "health_check",
-				"check_type":    "response",
-				"error_type":    "json_response",
-				"error_message": err.Error(),
-			})
-		}
+		observe.Event("HealthCheckError", map[string]any{
+			"operation":     "health_check",
+			"check_type":    "response",
+			"error_type":    "json_response",
+			"error_message": err.Error(),
+		})
 
 		return fmt.Errorf("failed to write JSON response: %w", err)
 	}
 
 	return nil
 }
+
+// backupCheck describes the latest database backup: healthy if one succeeded within the
+// configured max age, stale otherwise.
+func (h *HealthHandler) backupCheck(ctx context.Context) map[string]interface{} {
+	status, err := backup.LoadStatus(ctx, h.server.Redis, h.server.Keys.Space(keys.Backup))
+	if err != nil {
+		return map[string]interface{}{
+			"status": "unknown",
+			"error":  err.Error(),
+		}
+	}
+
+	check := map[string]interface{}{
+		"status": "stale",
+	}
+	if status == nil {
+		return check
+	}
+
+	check["last_run_at"] = status.LastRunAt
+	check["last_success_at"] = status.LastSuccessAt
+	check["verified"] = status.Verified
+	if status.LastError != "" {
+		check["last_error"] = status.LastError
+	}
+
+	if status.LastSuccessAt != nil && time.Since(*status.LastSuccessAt) <= h.server.Config.Backup.MaxAge {
+		check["status"] = "healthy"
+	}
+
+	return check
+}
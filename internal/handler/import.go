@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type ImportHandler struct {
+	Handler
+	importService *service.ImportService
+}
+
+func NewImportHandler(s *server.Server, importService *service.ImportService) *ImportHandler {
+	return &ImportHandler{
+		Handler:       NewHandler(s),
+		importService: importService,
+	}
+}
+
+// UploadImport validates a CSV uploaded as the multipart field "file" and stages it for
+// import. Row errors are reported in the response; with ?skip_invalid=true the valid
+// rows are imported regardless, otherwise nothing is.
+func (h *ImportHandler) UploadImport(c echo.Context) error {
+	skipInvalid := false
+	if raw := c.QueryParam("skip_invalid"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errs.BadRequestError("Invalid skip_invalid", false, nil, []errs.FieldError{
+				{Field: "skip_invalid", Error: "must be true or false"},
+			}, nil)
+		}
+		skipInvalid = parsed
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return errs.BadRequestError("Missing import file", false, nil, []errs.FieldError{
+			{Field: "file", Error: "must be a CSV file uploaded as multipart/form-data"},
+		}, nil)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	imp, err := h.importService.Upload(requestctx.From(c), c.Param("dataset"), header.Filename, file, skipInvalid)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusAccepted, imp)
+}
+
+// GetImport returns the validation report and commit progress of an import.
+func (h *ImportHandler) GetImport(c echo.Context) error {
+	importID, err := parseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	imp, err := h.importService.GetImport(requestctx.From(c), importID)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, imp)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+type NotificationHandler struct {
+	Handler
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(s *server.Server, notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		Handler:             NewHandler(s),
+		notificationService: notificationService,
+	}
+}
+
+// RegisterDevice registers the push token of one of the caller's app installs.
+func (h *NotificationHandler) RegisterDevice(c echo.Context) error {
+	var payload model.RegisterDevicePayload
+	if err := validation.BindAndValidate(c, &payload); err != nil {
+		return err
+	}
+
+	device, err := h.notificationService.RegisterDevice(requestctx.From(c), &payload)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusCreated, device)
+}
+
+// ListDevices returns the caller's devices registered for push notifications.
+func (h *NotificationHandler) ListDevices(c echo.Context) error {
+	devices, err := h.notificationService.ListDevices(requestctx.From(c))
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, devices)
+}
+
+// DeleteDevice stops push notifications to one of the caller's devices, e.g. on sign out.
+func (h *NotificationHandler) DeleteDevice(c echo.Context) error {
+	deviceID, err := parseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.notificationService.DeleteDevice(requestctx.From(c), deviceID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SMSStatus processes a delivery status callback of the SMS provider, verified by
+// WebhookMiddleware. Errors make the provider deliver it again.
+func (h *NotificationHandler) SMSStatus(c echo.Context) error {
+	form, err := url.ParseQuery(string(middleware.GetWebhookRawBody(c)))
+	if err != nil {
+		return errs.BadRequestError("Invalid status callback", false, nil, nil, nil)
+	}
+
+	if err := h.notificationService.HandleSMSStatus(c.Request().Context(), form); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
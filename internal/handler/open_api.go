@@ -1,10 +1,11 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/examples"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
 	"github.com/labstack/echo/v4"
 )
@@ -21,18 +22,40 @@ func NewOpenAPIHandler(s *server.Server) *OpenAPIHandler {
 }
 
 func (o *OpenAPIHandler) OpenAPIUI(c echo.Context) error {
-	templateByte, err := os.ReadFile("static/openapi.html")
+	page, ok := o.server.Assets.Get("openapi.html")
+	if !ok {
+		return fmt.Errorf("failed to read OpenAPI template: openapi.html is not embedded")
+	}
 
-	c.Response().Header().Set("Cache-Control", "no-cache")
+	return serveAsset(c, page, false)
+}
+
+// Spec serves the OpenAPI document with the access each route was declared with (see
+// routeaccess) and the examples captured in development (see config.DocsConfig)
+// embedded into its operations.
+func (o *OpenAPIHandler) Spec(c echo.Context) error {
+	spec, ok := o.server.Assets.Get("openapi.json")
+	if !ok {
+		return fmt.Errorf("failed to read OpenAPI spec: openapi.json is not embedded")
+	}
 
+	captured, err := examples.NewStore(o.server.Config.Docs.ExamplesDir).Load()
 	if err != nil {
-		return fmt.Errorf("failed to read OpenAPI template: %w ", err)
+		// The docs stay usable without examples
+		o.server.Logger.Warn().Err(err).Msg("failed to load docs examples")
+	}
+	if len(captured) == 0 && o.server.RouteAccess.Len() == 0 {
+		return serveAsset(c, spec, false)
 	}
-	templateString := string(templateByte)
 
-	if err := c.HTML(http.StatusOK, templateString); err != nil {
-		return fmt.Errorf("failed to write HTML response")
+	c.Response().Header().Set("Cache-Control", "no-cache")
+
+	var document map[string]any
+	if err := json.Unmarshal(spec.Data, &document); err != nil {
+		return fmt.Errorf("failed to parse OpenAPI spec: %w", err)
 	}
+	o.server.RouteAccess.Embed(document)
+	examples.Embed(document, captured)
 
-	return nil
+	return c.JSON(http.StatusOK, document)
 }
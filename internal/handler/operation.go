@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// progressHeartbeat is how often an idle progress stream sends a comment, so proxies
+// don't close it and draining instances hand their clients over.
+const progressHeartbeat = 15 * time.Second
+
+// OperationHandler serves the progress of long running operations (async exports and
+// imports, by their ID).
+type OperationHandler struct {
+	Handler
+}
+
+func NewOperationHandler(s *server.Server) *OperationHandler {
+	return &OperationHandler{
+		Handler: NewHandler(s),
+	}
+}
+
+// GetProgress returns the last progress update of an operation.
+func (h *OperationHandler) GetProgress(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	update, err := h.server.Progress.Latest(c.Request().Context(), id.String())
+	if err != nil {
+		return err
+	}
+	if update == nil {
+		return errs.NotFoundError("No progress reported for this operation", false, nil)
+	}
+
+	return h.respond(c, http.StatusOK, update)
+}
+
+// StreamProgress streams the progress updates of an operation as server-sent events,
+// starting with the last one reported. The stream ends once the operation is done;
+// EventSource clients reconnect by themselves if it ends before.
+func (h *OperationHandler) StreamProgress(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	subscription, err := h.server.Progress.Subscribe(ctx, id.String())
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-store")
+	// Keeps nginx from buffering the stream
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	// The status is already sent, failures can only end the stream
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", (3 * time.Second).Milliseconds()); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(progressHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if !h.server.Drain.Ready() {
+				return nil
+			}
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case update, ok := <-subscription.Updates:
+			if !ok {
+				return nil
+			}
+
+			data, err := json.Marshal(update)
+			if err != nil {
+				middleware.GetLogger(c).Error().Err(err).Msg("failed to encode progress update")
+				return nil
+			}
+			if _, err := fmt.Fprintf(res, "event: progress\ndata: %s\n\n", data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+type PaymentHandler struct {
+	Handler
+	paymentService *service.PaymentService
+}
+
+func NewPaymentHandler(s *server.Server, paymentService *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		Handler:        NewHandler(s),
+		paymentService: paymentService,
+	}
+}
+
+// CreateCheckout starts a hosted checkout for the caller's organization, or the caller
+// outside of one. Only organization admins may subscribe their organization.
+func (h *PaymentHandler) CreateCheckout(c echo.Context) error {
+	if middleware.GetOrganizationID(c) != "" {
+		if role, _ := c.Get(middleware.UserRoleKey).(string); role != middleware.AdminRole {
+			return errs.ForbididdenError("Only organization admins can manage billing", true)
+		}
+	}
+
+	var payload model.CreateCheckoutPayload
+	if err := validation.BindAndValidate(c, &payload); err != nil {
+		return err
+	}
+
+	session, err := h.paymentService.CreateCheckout(requestctx.From(c), &payload)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusCreated, session)
+}
+
+// ListSubscriptions returns the subscriptions of the caller's organization, or the caller outside of one.
+func (h *PaymentHandler) ListSubscriptions(c echo.Context) error {
+	subscriptions, err := h.paymentService.ListSubscriptions(requestctx.From(c))
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, subscriptions)
+}
+
+// Webhook processes a webhook of the payments provider, verified by WebhookMiddleware.
+// Any 2xx acknowledges the event, errors make the provider deliver it again.
+func (h *PaymentHandler) Webhook(c echo.Context) error {
+	if err := h.paymentService.HandleWebhook(c.Request().Context(), middleware.GetWebhookRawBody(c)); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// Readiness reports whether the instance accepts new traffic. It turns unhealthy as
+// soon as draining starts so Kubernetes removes the pod from the service endpoints,
+// and reports the startup migration's progress and the cache warmup.
+func (h *HealthHandler) Readiness(c echo.Context) error {
+	if !h.server.Drain.Ready() {
+		return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+			"status":    "draining",
+			"in_flight": h.server.Drain.InFlight(),
+		})
+	}
+
+	response := map[string]interface{}{
+		"status": "ready",
+	}
+
+	// Migrations normally finish before the server starts, see server.StartupProbe.
+	if h.server.Migrations != nil {
+		response["migration"] = h.server.Migrations.State()
+
+		if !h.server.Migrations.Done() {
+			response["status"] = "starting"
+			return c.JSON(http.StatusServiceUnavailable, response)
+		}
+	}
+
+	// Critical caches are primed concurrently with serving, see warmup.Warmer.
+	if h.server.Warmer != nil {
+		response["warmup"] = h.server.Warmer.Statuses()
+
+		if h.server.Config.Cache.Warmup.GateReadiness && !h.server.Warmer.Ready() {
+			response["status"] = "warming"
+			return c.JSON(http.StatusServiceUnavailable, response)
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// preStopTokenHeader carries server.prestop_token.
+const preStopTokenHeader = "X-PreStop-Token"
+
+// PreStop flips readiness off and blocks until every in-flight request finished or the
+// drain timeout elapsed. It is meant to be called from a Kubernetes preStop hook so the
+// pod keeps serving until the load balancer stopped routing to it.
+func (h *HealthHandler) PreStop(c echo.Context) error {
+	// The endpoint is for the kubelet only, never for traffic coming through the ingress.
+	// Without a token it is off, as if the route didn't exist.
+	token := h.server.Config.Server.PreStopToken
+	if token == "" {
+		return errs.NotFoundError("Not found", false, nil)
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Request().Header.Get(preStopTokenHeader)), []byte(token)) != 1 {
+		return errs.ForbididdenError("prestop requires the prestop token", false)
+	}
+
+	logger := middleware.GetLogger(c).With().Str("operation", "prestop").Logger()
+
+	timeout := time.Duration(h.server.Config.Server.DrainTimeout) * time.Second
+	logger.Info().Int64("in_flight", h.server.Drain.InFlight()).Dur("timeout", timeout).Msg("draining in-flight requests")
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+	defer cancel()
+
+	result := h.server.Drain.Wait(ctx)
+
+	event := logger.Info()
+	if result.TimedOut {
+		event = logger.Warn()
+	}
+	event.Dur("drain_duration", result.Duration).
+		Int64("remaining_in_flight", result.Remaining).
+		Bool("timed_out", result.TimedOut).
+		Msg("drain finished")
+
+	if h.server.LoggerService != nil && h.server.LoggerService.GetNewRelicApp() != nil {
+		h.server.LoggerService.GetNewRelicApp().RecordCustomMetric("Custom/Drain/Duration", result.Duration.Seconds())
+	}
+	observe.Event("ConnectionDrain", map[string]any{
+		"duration_ms":         result.Duration.Milliseconds(),
+		"remaining_in_flight": result.Remaining,
+		"timed_out":           result.TimedOut,
+	})
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"drained":             !result.TimedOut,
+		"remaining_in_flight": result.Remaining,
+		"duration_ms":         result.Duration.Milliseconds(),
+	})
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+var codeQuotaDisabled = errs.Register("QUOTA_DISABLED", http.StatusNotFound, "Request quotas are not enabled on this deployment.")
+
+type QuotaHandler struct {
+	Handler
+	quotaService *service.QuotaService
+}
+
+func NewQuotaHandler(s *server.Server, quotaService *service.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		Handler:      NewHandler(s),
+		quotaService: quotaService,
+	}
+}
+
+// GetQuota returns the remaining daily and monthly quota of the caller.
+func (h *QuotaHandler) GetQuota(c echo.Context) error {
+	if !h.server.Config.Quota.Enabled {
+		code := codeQuotaDisabled
+		return errs.NotFoundError("Request quotas are not enabled", true, &code)
+	}
+
+	limits := middleware.ResolveTenantLimits(c, h.server.TenantLimits)
+	usage, err := h.quotaService.GetUsage(requestctx.From(c), limits.Quota)
+	if err != nil {
+		return err
+	}
+
+	middleware.SetQuotaHeaders(c, usage)
+
+	return h.respond(c, http.StatusOK, usage)
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/routetoggle"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+type RouteToggleHandler struct {
+	Handler
+	routeToggleService *service.RouteToggleService
+}
+
+func NewRouteToggleHandler(s *server.Server, routeToggleService *service.RouteToggleService) *RouteToggleHandler {
+	return &RouteToggleHandler{
+		Handler:            NewHandler(s),
+		routeToggleService: routeToggleService,
+	}
+}
+
+// ListDisabledRoutes returns the routes disabled by operators.
+func (h *RouteToggleHandler) ListDisabledRoutes(c echo.Context) error {
+	return h.respond(c, http.StatusOK, h.routeToggleService.ListDisabledRoutes())
+}
+
+// DisableRoute disables a route on every instance, its requests are answered with 503
+// ROUTE_DISABLED until it is enabled again.
+func (h *RouteToggleHandler) DisableRoute(c echo.Context) error {
+	var payload model.DisableRoutePayload
+	if err := validation.BindAndValidate(c, &payload); err != nil {
+		return err
+	}
+
+	if !routeRegistered(c.Echo(), payload.Method, payload.Path) {
+		return errs.BadRequestError("Unknown route", false, nil, []errs.FieldError{
+			{Field: "path", Error: "must be a registered route of the method, e.g. /api/v1/me/devices/:id"},
+		}, nil)
+	}
+
+	toggle, err := h.routeToggleService.DisableRoute(requestctx.From(c), &payload)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, toggle)
+}
+
+// EnableRoute enables a disabled route again, named by the method and path query parameters.
+func (h *RouteToggleHandler) EnableRoute(c echo.Context) error {
+	method, path := c.QueryParam("method"), c.QueryParam("path")
+	if method == "" || path == "" {
+		return errs.BadRequestError("The method and path query parameters are required", false, nil, nil, nil)
+	}
+
+	if err := h.routeToggleService.EnableRoute(requestctx.From(c), method, path); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// routeRegistered reports whether path is a route of e for method, any method for routetoggle.AnyMethod.
+func routeRegistered(e *echo.Echo, method, path string) bool {
+	for _, route := range e.Routes() {
+		if route.Path == path && (method == routetoggle.AnyMethod || strings.EqualFold(route.Method, method)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+type SettingsHandler struct {
+	Handler
+	settingsService *service.SettingsService
+}
+
+func NewSettingsHandler(s *server.Server, settingsService *service.SettingsService) *SettingsHandler {
+	return &SettingsHandler{
+		Handler:         NewHandler(s),
+		settingsService: settingsService,
+	}
+}
+
+// ListSettings returns every runtime setting with its current value.
+func (h *SettingsHandler) ListSettings(c echo.Context) error {
+	settings, err := h.settingsService.ListSettings(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, settings)
+}
+
+// GetSetting returns a runtime setting with its current value.
+func (h *SettingsHandler) GetSetting(c echo.Context) error {
+	setting, err := h.settingsService.GetSetting(c.Request().Context(), c.Param("key"))
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, setting)
+}
+
+// UpdateSetting changes the value of a runtime setting, applied without a redeploy.
+func (h *SettingsHandler) UpdateSetting(c echo.Context) error {
+	var payload model.UpdateSettingPayload
+	if err := validation.BindAndValidate(c, &payload); err != nil {
+		return err
+	}
+
+	setting, err := h.settingsService.SetSetting(requestctx.From(c), c.Param("key"), &payload)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, setting)
+}
+
+// DeleteSetting returns a runtime setting to its default.
+func (h *SettingsHandler) DeleteSetting(c echo.Context) error {
+	if err := h.settingsService.ResetSetting(requestctx.From(c), c.Param("key")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/assets"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// immutableCacheControl is sent with hashed asset names, whose content never changes.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// StaticHandler serves the static files embedded into the binary (see assets.Set).
+type StaticHandler struct {
+	Handler
+}
+
+func NewStaticHandler(s *server.Server) *StaticHandler {
+	return &StaticHandler{
+		Handler: NewHandler(s),
+	}
+}
+
+// Serve serves the asset at the path below /static, by name or by hashed name. Hashed
+// names are cached forever, plain names are revalidated with their ETag.
+func (h *StaticHandler) Serve(c echo.Context) error {
+	asset, hashed, ok := h.server.Assets.Lookup(c.Param("*"))
+	if !ok {
+		return errs.NotFoundError("Asset not found", false, nil)
+	}
+
+	return serveAsset(c, asset, hashed)
+}
+
+// serveAsset writes the asset in the best encoding the client accepts. immutable assets
+// may be cached forever, others must be revalidated, which costs a 304 while unchanged.
+func serveAsset(c echo.Context, asset *assets.Asset, immutable bool) error {
+	header := c.Response().Header()
+	header.Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+	header.Set("ETag", asset.ETag)
+	if immutable {
+		header.Set("Cache-Control", immutableCacheControl)
+	} else {
+		header.Set("Cache-Control", "no-cache")
+	}
+
+	if c.Request().Header.Get("If-None-Match") == asset.ETag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	body, encoding := asset.Encode(c.Request().Header.Get(echo.HeaderAcceptEncoding))
+	if encoding != "" {
+		header.Set(echo.HeaderContentEncoding, encoding)
+	}
+
+	return c.Blob(http.StatusOK, asset.ContentType, body)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type UsageHandler struct {
+	Handler
+	usageService *service.UsageService
+}
+
+func NewUsageHandler(s *server.Server, usageService *service.UsageService) *UsageHandler {
+	return &UsageHandler{
+		Handler:      NewHandler(s),
+		usageService: usageService,
+	}
+}
+
+// ListUsage returns a page of the API usage rollup, e.g. the clients still calling
+// deprecated routes with filter[deprecated][eq]=true.
+func (h *UsageHandler) ListUsage(c echo.Context) error {
+	params, err := parseListParams(c)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.usageService.ListUsage(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, http.StatusOK, page)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+	"github.com/labstack/echo/v4"
+)
+
+// RequestBody is an endpoint taking a validated request body.
+type RequestBody struct {
+	Method string
+	// Path is the route as registered with echo, e.g. "/api/v1/admin/tenants/:id/limits".
+	Path        string
+	OperationID string
+	Payload     validation.Validatable
+	// Schema optionally names a JSON Schema in static/schemas/, the body is validated
+	// against it before being unmarshalled into Payload.
+	Schema string
+}
+
+// RequestBodies lists every endpoint binding a body with validation.BindAndValidate.
+// Add new endpoints here, the router fails to start if one doesn't match a route.
+var RequestBodies = []RequestBody{
+	{Method: http.MethodPut, Path: "/api/v1/admin/tenants/:id/limits", OperationID: "adminUpdateTenantLimits", Payload: &model.UpdateTenantLimitsPayload{}},
+	{Method: http.MethodPut, Path: "/api/v1/admin/settings/:key", OperationID: "adminUpdateSetting", Payload: &model.UpdateSettingPayload{}},
+	{Method: http.MethodPut, Path: "/api/v1/admin/routes/disabled", OperationID: "adminDisableRoute", Payload: &model.DisableRoutePayload{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/authz/policies", OperationID: "adminCreatePolicy", Payload: &model.CreatePolicyPayload{}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/datasets/:dataset/exports", OperationID: "adminRequestExport", Payload: &model.CreateExportPayload{}},
+	{Method: http.MethodPost, Path: "/api/v1/me/billing/checkout", OperationID: "createCheckout", Payload: &model.CreateCheckoutPayload{}},
+	{Method: http.MethodPost, Path: "/api/v1/me/email-verification", OperationID: "requestEmailVerification", Payload: &model.RequestEmailVerificationPayload{}},
+	{Method: http.MethodPost, Path: "/api/v1/me/devices", OperationID: "registerDevice", Payload: &model.RegisterDevicePayload{}, Schema: "register_device.json"},
+}
+
+// EndpointSchema holds the constraints of an endpoint's request body.
+type EndpointSchema struct {
+	Method      string                   `json:"method"`
+	Path        string                   `json:"path"`
+	OperationID string                   `json:"operation_id"`
+	Fields      []validation.FieldSchema `json:"fields"`
+	// Schema is the URL of the endpoint's JSON Schema, if it has one.
+	Schema string `json:"schema,omitempty"`
+}
+
+var validationSchema = sync.OnceValue(func() []EndpointSchema {
+	endpoints := make([]EndpointSchema, 0, len(RequestBodies))
+	for _, body := range RequestBodies {
+		endpoints = append(endpoints, EndpointSchema{
+			Method:      body.Method,
+			Path:        body.Path,
+			OperationID: body.OperationID,
+			Fields:      validation.Describe(body.Payload),
+			Schema:      schemaURL(body.Schema),
+		})
+	}
+	return endpoints
+})
+
+// ValidationSchema returns the constraints of every request body, generated from the
+// validate tags of the payload types, so frontends can mirror validation client-side.
+func (o *OpenAPIHandler) ValidationSchema(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]any{
+		"endpoints": validationSchema(),
+	})
+}
+
+func schemaURL(name string) string {
+	if name == "" {
+		return ""
+	}
+	return "/static/schemas/" + name
+}
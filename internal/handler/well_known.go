@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// wellKnownAssets is the directory under static/ whose files are served under /.well-known/.
+const wellKnownAssets = "well-known/"
+
+// WellKnownHandler serves the small standard documents under /.well-known/, built from
+// the well_known config or embedded under static/well-known/.
+type WellKnownHandler struct {
+	Handler
+}
+
+func NewWellKnownHandler(s *server.Server) *WellKnownHandler {
+	return &WellKnownHandler{
+		Handler: NewHandler(s),
+	}
+}
+
+// SecurityTxt serves security.txt (RFC 9116) built from the configured contacts, or
+// the embedded file when none are configured.
+func (h *WellKnownHandler) SecurityTxt(c echo.Context) error {
+	cfg := h.server.Config.WellKnown.SecurityTxt
+	if len(cfg.Contacts) == 0 {
+		return h.serveEmbedded(c, "security.txt")
+	}
+
+	expires := cfg.Expires
+	if expires == "" {
+		expires = time.Now().UTC().AddDate(1, 0, 0).Truncate(24 * time.Hour).Format(time.RFC3339)
+	}
+
+	var b strings.Builder
+	for _, contact := range cfg.Contacts {
+		b.WriteString("Contact: " + contact + "\n")
+	}
+	b.WriteString("Expires: " + expires + "\n")
+
+	fields := []struct{ name, value string }{
+		{"Encryption", cfg.Encryption},
+		{"Acknowledgments", cfg.Acknowledgments},
+		{"Policy", cfg.Policy},
+		{"Hiring", cfg.Hiring},
+		{"Preferred-Languages", cfg.PreferredLanguages},
+	}
+	for _, field := range fields {
+		if field.value != "" {
+			b.WriteString(field.name + ": " + field.value + "\n")
+		}
+	}
+
+	if baseURL := h.server.Config.Server.BaseURL; baseURL != "" {
+		b.WriteString("Canonical: " + strings.TrimSuffix(baseURL, "/") + "/.well-known/security.txt\n")
+	}
+
+	c.Response().Header().Set("Cache-Control", "public, max-age=86400")
+	return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, []byte(b.String()))
+}
+
+// ChangePassword redirects password managers to the page where users change their
+// password (https://w3c.github.io/webappsec-change-password-url/).
+func (h *WellKnownHandler) ChangePassword(c echo.Context) error {
+	target := h.server.Config.WellKnown.ChangePasswordURL
+	if target == "" {
+		return errs.NotFoundError("No change password page is configured", false, nil)
+	}
+
+	return c.Redirect(http.StatusFound, target)
+}
+
+// JWKS publishes the public key of the service tokens this service mints, when enabled
+// and signing with an Ed25519 key.
+func (h *WellKnownHandler) JWKS(c echo.Context) error {
+	if !h.server.Config.WellKnown.JWKS || h.server.ServiceTokens == nil {
+		return errs.NotFoundError("No key set is published", false, nil)
+	}
+
+	key, ok := h.server.ServiceTokens.PublicJWK()
+	if !ok {
+		return errs.NotFoundError("No key set is published", false, nil)
+	}
+
+	// Callees refetch on an unknown key ID, an hour of caching is safe across rotations
+	c.Response().Header().Set("Cache-Control", "public, max-age=3600")
+	return c.JSON(http.StatusOK, map[string]any{"keys": []any{key}})
+}
+
+// Serve serves the files embedded under static/well-known/, e.g. assetlinks.json or
+// apple-app-site-association.
+func (h *WellKnownHandler) Serve(c echo.Context) error {
+	return h.serveEmbedded(c, c.Param("*"))
+}
+
+func (h *WellKnownHandler) serveEmbedded(c echo.Context, name string) error {
+	asset, ok := h.server.Assets.Get(wellKnownAssets + name)
+	if !ok {
+		return errs.NotFoundError("Document not found", false, nil)
+	}
+
+	return serveAsset(c, asset, false)
+}
@@ -0,0 +1,203 @@
+// Package assets serves static files from a file system, typically one embedded into
+// the binary. Every file is also served under a name carrying a hash of its content
+// (openapi.json as openapi.3f2a1b4c.json), which can be cached forever: a changed file
+// gets a new name. Compressible files are gzipped once at load, and brotli variants
+// shipped next to a file (openapi.json.br) are picked up, so nothing is compressed per
+// request.
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// hashLength is the number of hex characters of the content hash in hashed names.
+const hashLength = 8
+
+// Content encodings an asset can be served with, besides identity.
+const (
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+)
+
+// Asset is a file ready to be served.
+type Asset struct {
+	// Name is the path of the file in the file system, e.g. openapi.json.
+	Name string
+	// HashedName carries the content hash, e.g. openapi.3f2a1b4c.json.
+	HashedName  string
+	ContentType string
+	// ETag is the strong validator of the content.
+	ETag string
+	Data []byte
+	// Brotli and Gzip are the compressed variants, nil when there is none.
+	Brotli []byte
+	Gzip   []byte
+}
+
+// Encode returns the smallest variant of the asset the client accepts, per its
+// Accept-Encoding header, and its content encoding ("" for identity).
+func (a *Asset) Encode(acceptEncoding string) ([]byte, string) {
+	if a.Brotli != nil && accepts(acceptEncoding, EncodingBrotli) {
+		return a.Brotli, EncodingBrotli
+	}
+	if a.Gzip != nil && accepts(acceptEncoding, EncodingGzip) {
+		return a.Gzip, EncodingGzip
+	}
+	return a.Data, ""
+}
+
+// accepts reports whether the Accept-Encoding header lists encoding without q=0.
+func accepts(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !ok {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
+
+// Set holds the assets of a file system, addressable by name and by hashed name.
+type Set struct {
+	prefix string
+	byName map[string]*Asset
+	// byPath maps both names of every asset, as requested below prefix.
+	byPath map[string]*Asset
+}
+
+// Load reads every file of fsys. prefix is the URL path the assets are served under,
+// e.g. /static. Go sources and the brotli variants themselves aren't assets.
+func Load(fsys fs.FS, prefix string) (*Set, error) {
+	s := &Set{
+		prefix: strings.TrimSuffix(prefix, "/"),
+		byName: make(map[string]*Asset),
+		byPath: make(map[string]*Asset),
+	}
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || path.Ext(name) == ".go" || path.Ext(name) == ".br" {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("failed to read asset %s: %w", name, err)
+		}
+
+		asset, err := newAsset(name, data)
+		if err != nil {
+			return err
+		}
+
+		if brotli, err := fs.ReadFile(fsys, name+".br"); err == nil {
+			asset.Brotli = brotli
+		}
+
+		s.byName[name] = asset
+		s.byPath[name] = asset
+		s.byPath[asset.HashedName] = asset
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func newAsset(name string, data []byte) (*Asset, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:hashLength]
+
+	ext := path.Ext(name)
+	contentType := mime.TypeByExtension(ext)
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	asset := &Asset{
+		Name:        name,
+		HashedName:  strings.TrimSuffix(name, ext) + "." + hash + ext,
+		ContentType: contentType,
+		ETag:        `"` + hash + `"`,
+		Data:        data,
+	}
+
+	if compressible(contentType) {
+		var buf bytes.Buffer
+		gz, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := gz.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to compress asset %s: %w", name, err)
+		}
+		if err := gz.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress asset %s: %w", name, err)
+		}
+
+		// Tiny files can grow when compressed
+		if buf.Len() < len(data) {
+			asset.Gzip = buf.Bytes()
+		}
+	}
+
+	return asset, nil
+}
+
+// compressible reports whether content of the type is text. Images and fonts are
+// compressed already.
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" ||
+		mediaType == "application/javascript" ||
+		mediaType == "application/xml" ||
+		mediaType == "image/svg+xml"
+}
+
+// Get returns the asset named name (openapi.json).
+func (s *Set) Get(name string) (*Asset, bool) {
+	asset, ok := s.byName[name]
+	return asset, ok
+}
+
+// Lookup returns the asset requested below the prefix, by name or hashed name, and
+// whether it was requested by its hashed name, which can be cached forever.
+func (s *Set) Lookup(requested string) (*Asset, bool, bool) {
+	asset, ok := s.byPath[strings.TrimPrefix(requested, "/")]
+	if !ok {
+		return nil, false, false
+	}
+	return asset, strings.TrimPrefix(requested, "/") == asset.HashedName, true
+}
+
+// URL returns the path serving the current version of the asset named name, e.g.
+// /static/openapi.3f2a1b4c.json, to reference it from pages. Unknown names are returned
+// unhashed.
+func (s *Set) URL(name string) string {
+	if asset, ok := s.byName[name]; ok {
+		return s.prefix + "/" + asset.HashedName
+	}
+	return s.prefix + "/" + name
+}
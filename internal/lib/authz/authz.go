@@ -0,0 +1,312 @@
+// Package authz decides whether a subject (a user, with their role and organization)
+// may perform an action on a resource, for apps needing more than role checks.
+//
+// A policy grants or denies a subject pattern an action on resources:
+//
+//	subject   action           resource                 effect
+//	role:admin  *              *                        allow
+//	role:member exports:read   organization/{org}/*     allow
+//	user:u_42   exports:*      organization/*           deny
+//
+// Subjects are "*", "user:<id>", "role:<role>" or "org:<id>". Actions and resources
+// are patterns where "*" matches any run of characters; {user} and {org} in a resource
+// are replaced by the subject's user and organization ID, so one policy can scope every
+// member to their own organization. A matching deny wins over any allow, and nothing is
+// allowed without a policy.
+//
+// The Engine keeps the policies in memory and reloads them from its Loader, on an
+// interval and whenever they change, so edits apply without a restart.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Effects of a policy.
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Placeholders in resource patterns, replaced by the subject's IDs.
+const (
+	UserPlaceholder         = "{user}"
+	OrganizationPlaceholder = "{org}"
+)
+
+// ErrInvalidPolicy is returned for policies that can't be evaluated.
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+// Policy allows or denies the subjects matching Subject the actions matching Action on
+// the resources matching Resource.
+type Policy struct {
+	ID       string
+	Subject  string
+	Action   string
+	Resource string
+	Effect   string
+}
+
+// Validate checks the policy can be evaluated.
+func (p Policy) Validate() error {
+	if p.Effect != EffectAllow && p.Effect != EffectDeny {
+		return fmt.Errorf("%w: effect must be %s or %s", ErrInvalidPolicy, EffectAllow, EffectDeny)
+	}
+
+	if p.Subject != "*" {
+		kind, id, ok := strings.Cut(p.Subject, ":")
+		if !ok || id == "" || (kind != "user" && kind != "role" && kind != "org") {
+			return fmt.Errorf("%w: subject must be *, user:<id>, role:<role> or org:<id>", ErrInvalidPolicy)
+		}
+	}
+
+	if p.Action == "" || p.Resource == "" {
+		return fmt.Errorf("%w: action and resource are required", ErrInvalidPolicy)
+	}
+
+	return nil
+}
+
+// Subject is who asks for access.
+type Subject struct {
+	UserID         string
+	Role           string
+	OrganizationID string
+}
+
+// matches reports whether the policy subject pattern applies to s.
+func (s Subject) matches(pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+
+	kind, id, _ := strings.Cut(pattern, ":")
+	switch kind {
+	case "user":
+		return s.UserID != "" && s.UserID == id
+	case "role":
+		return s.Role != "" && s.Role == id
+	case "org":
+		return s.OrganizationID != "" && s.OrganizationID == id
+	default:
+		return false
+	}
+}
+
+// Decision is the outcome of an access check.
+type Decision struct {
+	Subject  Subject
+	Action   string
+	Resource string
+	Allowed  bool
+	// Policy is the policy that decided, nil when none matched and access was denied.
+	Policy *Policy
+}
+
+// Loader loads every policy, e.g. from the database.
+type Loader func(ctx context.Context) ([]Policy, error)
+
+// Engine evaluates access checks against the loaded policies.
+type Engine struct {
+	logger   *zerolog.Logger
+	policies atomic.Pointer[[]Policy]
+
+	mu         sync.RWMutex
+	loader     Loader
+	onDecision func(ctx context.Context, decision Decision)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewEngine returns an Engine denying everything until policies are loaded.
+func NewEngine(logger *zerolog.Logger) *Engine {
+	e := &Engine{logger: logger}
+	e.policies.Store(&[]Policy{})
+	return e
+}
+
+// SetLoader sets where Reload loads policies from.
+func (e *Engine) SetLoader(loader Loader) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.loader = loader
+}
+
+// OnDecision calls hook with every decision made, e.g. to log it.
+func (e *Engine) OnDecision(hook func(ctx context.Context, decision Decision)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onDecision = hook
+}
+
+// Reload replaces the policies with the loader's. Invalid policies are skipped and
+// logged; if loading fails the current policies stay in effect.
+func (e *Engine) Reload(ctx context.Context) error {
+	e.mu.RLock()
+	loader := e.loader
+	e.mu.RUnlock()
+
+	if loader == nil {
+		return nil
+	}
+
+	loaded, err := loader(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load policies: %w", err)
+	}
+
+	policies := make([]Policy, 0, len(loaded))
+	for _, policy := range loaded {
+		if err := policy.Validate(); err != nil {
+			e.logger.Warn().Err(err).Str("policy_id", policy.ID).Msg("skipping invalid authorization policy")
+			continue
+		}
+		policies = append(policies, policy)
+	}
+
+	e.policies.Store(&policies)
+
+	return nil
+}
+
+// Start reloads the policies every interval until Stop, picking up changes made
+// through other instances.
+func (e *Engine) Start(interval time.Duration) {
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+
+	go func() {
+		defer close(e.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				if err := e.Reload(ctx); err != nil {
+					e.logger.Error().Err(err).Msg("failed to reload authorization policies, keeping the current ones")
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// Stop stops the reloading started by Start.
+func (e *Engine) Stop() {
+	if e.stop == nil {
+		return
+	}
+	close(e.stop)
+	<-e.done
+}
+
+// Can reports whether subject may perform action on resource.
+func (e *Engine) Can(ctx context.Context, subject Subject, action, resource string) bool {
+	return e.Decide(ctx, subject, action, resource).Allowed
+}
+
+// Decide evaluates an access check: a matching deny policy denies, otherwise a matching
+// allow policy allows, otherwise access is denied.
+func (e *Engine) Decide(ctx context.Context, subject Subject, action, resource string) Decision {
+	decision := Decision{
+		Subject:  subject,
+		Action:   action,
+		Resource: resource,
+	}
+
+	for _, policy := range *e.policies.Load() {
+		if !subject.matches(policy.Subject) || !match(policy.Action, action) {
+			continue
+		}
+		if pattern, ok := subject.expand(policy.Resource); !ok || !match(pattern, resource) {
+			continue
+		}
+
+		if policy.Effect == EffectDeny {
+			decision.Allowed = false
+			decision.Policy = &policy
+			break
+		}
+		if decision.Policy == nil {
+			decision.Allowed = true
+			decision.Policy = &policy
+		}
+	}
+
+	e.mu.RLock()
+	hook := e.onDecision
+	e.mu.RUnlock()
+
+	if hook != nil {
+		hook(ctx, decision)
+	}
+
+	return decision
+}
+
+// Policies returns the policies in effect.
+func (e *Engine) Policies() []Policy {
+	return append([]Policy(nil), *e.policies.Load()...)
+}
+
+// expand replaces the placeholders of a resource pattern with the subject's IDs. It
+// reports false when the pattern uses a placeholder the subject has no ID for, the policy
+// doesn't apply to it then.
+func (s Subject) expand(pattern string) (string, bool) {
+	if !strings.Contains(pattern, "{") {
+		return pattern, true
+	}
+
+	if (s.UserID == "" && strings.Contains(pattern, UserPlaceholder)) ||
+		(s.OrganizationID == "" && strings.Contains(pattern, OrganizationPlaceholder)) {
+		return "", false
+	}
+
+	return strings.NewReplacer(UserPlaceholder, s.UserID, OrganizationPlaceholder, s.OrganizationID).Replace(pattern), true
+}
+
+// match reports whether value matches pattern, where "*" matches any run of characters.
+func match(pattern, value string) bool {
+	if pattern == "*" {
+		return true
+	}
+
+	// Greedy matching, backtracking to the last star
+	p, v := 0, 0
+	star, mark := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, v
+			p++
+		case p < len(pattern) && pattern[p] == value[v]:
+			p++
+			v++
+		case star >= 0:
+			p = star + 1
+			mark++
+			v = mark
+		default:
+			return false
+		}
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
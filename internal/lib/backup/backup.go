@@ -0,0 +1,160 @@
+// Package backup takes logical backups of a Postgres database with pg_dump, restores
+// them with pg_restore, and encrypts them with AES-256-GCM for storage.
+//
+// Dumps use pg_dump's custom format, which is compressed and restorable selectively.
+// The client binaries should match the server's major version.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const formatVersion = 1
+
+var ErrInvalid = errors.New("invalid or tampered backup")
+
+// libpqParams are the connection parameters passed on to pg_dump and pg_restore. Others,
+// e.g. statement_timeout, are understood by pgx but rejected by libpq.
+var libpqParams = map[string]bool{
+	"sslmode":          true,
+	"sslrootcert":      true,
+	"sslcert":          true,
+	"sslkey":           true,
+	"connect_timeout":  true,
+	"application_name": true,
+}
+
+// Dump returns a custom-format dump of the database at dsn, a postgres:// URL.
+func Dump(ctx context.Context, pgDump, dsn string) ([]byte, error) {
+	connString, env, err := connection(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, pgDump, "--format=custom", "--no-owner", "--no-privileges", "--dbname", connString)
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, commandError("pg_dump", err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// Restore loads a dump taken by Dump into the database at dsn, replacing the objects it contains.
+func Restore(ctx context.Context, pgRestore, dsn string, dump []byte) error {
+	connString, env, err := connection(dsn)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, pgRestore, "--clean", "--if-exists", "--no-owner", "--no-privileges", "--exit-on-error", "--dbname", connString)
+	cmd.Env = env
+	cmd.Stdin = bytes.NewReader(dump)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return commandError("pg_restore", err, stderr.String())
+	}
+
+	return nil
+}
+
+// connection turns dsn into a connection string libpq accepts. The password is passed
+// in the environment, so it doesn't show up in the process list.
+func connection(dsn string) (string, []string, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil || (parsed.Scheme != "postgres" && parsed.Scheme != "postgresql") {
+		// The parse error echoes the URL, which may contain the password.
+		return "", nil, fmt.Errorf("backup needs a postgres:// database url")
+	}
+
+	env := os.Environ()
+	if parsed.User != nil {
+		if password, ok := parsed.User.Password(); ok {
+			env = append(env, "PGPASSWORD="+password)
+		}
+		parsed.User = url.User(parsed.User.Username())
+	}
+
+	query := url.Values{}
+	for name, values := range parsed.Query() {
+		if libpqParams[name] {
+			query[name] = values
+		}
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String(), env, nil
+}
+
+// commandError adds the last line of the tool's stderr, which holds the reason, to err.
+func commandError(tool string, err error, stderr string) error {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" {
+		return fmt.Errorf("%s failed: %w", tool, err)
+	}
+
+	lines := strings.Split(stderr, "\n")
+	return fmt.Errorf("%s failed: %w: %s", tool, err, lines[len(lines)-1])
+}
+
+// Encrypt seals a dump with key, a 32-byte AES-256 key.
+func Encrypt(key, dump []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, 1+len(nonce)+len(dump)+aead.Overhead())
+	out = append(out, formatVersion)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, dump, []byte{formatVersion}), nil
+}
+
+// Decrypt opens a dump sealed by Encrypt.
+func Decrypt(key, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < 1+aead.NonceSize() || sealed[0] != formatVersion {
+		return nil, ErrInvalid
+	}
+
+	nonce, ciphertext := sealed[1:1+aead.NonceSize()], sealed[1+aead.NonceSize():]
+	dump, err := aead.Open(nil, nonce, ciphertext, []byte{formatVersion})
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	return dump, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
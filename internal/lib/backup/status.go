@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// Status is the outcome of the latest backup runs, kept in Redis for the health check.
+type Status struct {
+	LastRunAt time.Time `json:"last_run_at"`
+	// LastError is the error of the latest run, empty if it succeeded.
+	LastError string `json:"last_error,omitempty"`
+	// LastSuccessAt, LastKey and SizeBytes describe the latest successful backup.
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastKey       string     `json:"last_key,omitempty"`
+	SizeBytes     int64      `json:"size_bytes,omitempty"`
+	// Verified is set if the latest successful backup was restored into the scratch database.
+	Verified bool `json:"verified"`
+}
+
+// LoadStatus returns the status stored in space, nil if no backup ran yet.
+func LoadStatus(ctx context.Context, client *redis.Client, space keys.Space) (*Status, error) {
+	data, err := client.Get(ctx, space.Key("status")).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup status: %w", err)
+	}
+
+	var status Status
+	if err := keys.JSON.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to decode backup status: %w", err)
+	}
+
+	return &status, nil
+}
+
+// SaveStatus stores status in space.
+func SaveStatus(ctx context.Context, client *redis.Client, space keys.Space, status *Status) error {
+	data, err := keys.JSON.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to encode backup status: %w", err)
+	}
+
+	if err := client.Set(ctx, space.Key("status"), data, keys.StatusTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store backup status: %w", err)
+	}
+
+	return nil
+}
+
+// Querier is a connection or pool Inventory runs on.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Inventory is what a restored database is compared with the source by.
+type Inventory struct {
+	// Tables are the schema-qualified user tables, sorted.
+	Tables []string
+	// SchemaVersion is the migration version, zero if the database isn't migrated.
+	SchemaVersion int32
+}
+
+// TakeInventory lists the user tables and the migration version of a database.
+func TakeInventory(ctx context.Context, q Querier) (*Inventory, error) {
+	rows, err := q.Query(ctx, `
+		SELECT table_schema || '.' || table_name
+		FROM information_schema.tables
+		WHERE table_type = 'BASE TABLE' AND table_schema NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY 1`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	tables, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	inventory := &Inventory{Tables: tables}
+	if slices.Contains(tables, "public.schema_version") {
+		if err := q.QueryRow(ctx, `SELECT version FROM schema_version`).Scan(&inventory.SchemaVersion); err != nil {
+			return nil, fmt.Errorf("failed to read schema version: %w", err)
+		}
+	}
+
+	return inventory, nil
+}
+
+// Missing returns the tables of inventory absent from restored.
+func (inventory *Inventory) Missing(restored *Inventory) []string {
+	var missing []string
+	for _, table := range inventory.Tables {
+		if !slices.Contains(restored.Tables, table) {
+			missing = append(missing, table)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,191 @@
+// Package bruteforce protects authentication from guessing. Failed attempts are counted
+// per key (an IP or a user) in Redis, so the count is shared by every instance. Past a
+// few failures clients must wait a delay doubling with every failure before trying
+// again; past the lockout threshold the key is locked out for a while.
+package bruteforce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/redis/go-redis/v9"
+)
+
+// LockoutEvent is published on the event bus when a key gets locked out, with a Lockout payload.
+const LockoutEvent = "security.auth_lockout"
+
+// failScript counts a failure and returns {failures, delay in ms, lockout in ms}. Once the
+// threshold is reached the key is locked and its count starts over, and so do the delays
+// once the lockout expired.
+var failScript = redis.NewScript(`
+local failures = redis.call('HINCRBY', KEYS[1], 'failures', 1)
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+
+local threshold = tonumber(ARGV[6])
+if threshold > 0 and failures >= threshold then
+	redis.call('SET', KEYS[2], failures, 'PX', ARGV[7])
+	redis.call('DEL', KEYS[1])
+	return {failures, 0, tonumber(ARGV[7])}
+end
+
+local delay = 0
+local delayAfter = tonumber(ARGV[3])
+if failures > delayAfter then
+	delay = math.floor(math.min(tonumber(ARGV[4]) * 2 ^ (failures - delayAfter - 1), tonumber(ARGV[5])))
+end
+redis.call('HSET', KEYS[1], 'next_at', tonumber(ARGV[2]) + delay)
+
+return {failures, delay, 0}
+`)
+
+// Policy sets when failures are delayed and when they lock a key out.
+type Policy struct {
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+	// DelayAfter failures are allowed without delay, after that the delay starts at
+	// BaseDelay and doubles with every failure, up to MaxDelay.
+	DelayAfter int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	// LockoutThreshold failures lock the key out for LockoutDuration, zero never locks.
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+}
+
+// Block tells why an attempt is refused.
+type Block struct {
+	Key string
+	// Locked is set during a lockout, otherwise the client has to wait out a delay.
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+// Failure is the outcome of recording a failed attempt.
+type Failure struct {
+	Key      string
+	Failures int
+	// Delay is how long the key has to wait before its next attempt.
+	Delay time.Duration
+	// LockedFor is set when this failure locked the key out.
+	LockedFor time.Duration
+}
+
+type Guard struct {
+	redis  *redis.Client
+	keys   keys.Space
+	policy Policy
+}
+
+// NewGuard returns a Guard keeping failure counts and lockouts in space.
+func NewGuard(client *redis.Client, space keys.Space, policy Policy) *Guard {
+	return &Guard{
+		redis:  client,
+		keys:   space,
+		policy: policy,
+	}
+}
+
+// IPKey returns the key counting the failures of a client IP.
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// UserKey returns the key counting the failures of attempts on a user.
+func UserKey(userID string) string {
+	return "user:" + userID
+}
+
+// Check returns why an attempt by keys is refused, the longest wait among them, or nil
+// if it may go ahead.
+func (g *Guard) Check(ctx context.Context, now time.Time, keys ...string) (*Block, error) {
+	pipe := g.redis.Pipeline()
+
+	locks := make([]*redis.DurationCmd, len(keys))
+	nextAts := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		locks[i] = pipe.PTTL(ctx, g.lockKey(key))
+		nextAts[i] = pipe.HGet(ctx, g.failuresKey(key), "next_at")
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to check authentication failures: %w", err)
+	}
+
+	var block *Block
+	for i, key := range keys {
+		// PTTL is negative for keys that don't exist or have no expiry.
+		if ttl := locks[i].Val(); ttl > 0 {
+			if block == nil || !block.Locked || ttl > block.RetryAfter {
+				block = &Block{Key: key, Locked: true, RetryAfter: ttl}
+			}
+			continue
+		}
+
+		if block != nil && block.Locked {
+			continue
+		}
+
+		nextAt, err := nextAts[i].Int64()
+		if err != nil {
+			continue
+		}
+
+		if wait := time.UnixMilli(nextAt).Sub(now); wait > 0 && (block == nil || wait > block.RetryAfter) {
+			block = &Block{Key: key, RetryAfter: wait}
+		}
+	}
+
+	return block, nil
+}
+
+// Fail records a failed attempt by key.
+func (g *Guard) Fail(ctx context.Context, now time.Time, key string) (*Failure, error) {
+	values, err := failScript.Run(ctx, g.redis, []string{g.failuresKey(key), g.lockKey(key)},
+		g.policy.Window.Milliseconds(),
+		now.UnixMilli(),
+		g.policy.DelayAfter,
+		g.policy.BaseDelay.Milliseconds(),
+		g.policy.MaxDelay.Milliseconds(),
+		g.policy.LockoutThreshold,
+		g.policy.LockoutDuration.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to record authentication failure of %s: %w", key, err)
+	}
+
+	return &Failure{
+		Key:       key,
+		Failures:  int(values[0]),
+		Delay:     time.Duration(values[1]) * time.Millisecond,
+		LockedFor: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
+
+// Reset forgets the failures of key, e.g. after a successful login. Lockouts stay in place.
+func (g *Guard) Reset(ctx context.Context, key string) error {
+	if err := g.redis.Del(ctx, g.failuresKey(key)).Err(); err != nil {
+		return fmt.Errorf("failed to reset authentication failures of %s: %w", key, err)
+	}
+	return nil
+}
+
+// Lockout is the payload of LockoutEvent.
+type Lockout struct {
+	Key      string
+	Failures int
+	Until    time.Time
+	// IP and Path are those of the attempt that caused the lockout.
+	IP   string
+	Path string
+}
+
+func (g *Guard) failuresKey(key string) string {
+	return g.keys.Key("failures", key)
+}
+
+func (g *Guard) lockKey(key string) string {
+	return g.keys.Key("lock", key)
+}
@@ -0,0 +1,311 @@
+// Package cache is a two-tier cache for hot lookups (config, feature flags, users):
+// a small in-process LRU in front of Redis, so most reads don't pay for a network hop.
+// Writes and deletes go to Redis and are broadcast over Redis pub/sub, every instance
+// drops its local copy on receipt. While the subscription is down the local tier is
+// bypassed, entries also expire locally after a short TTL as a safety net.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/clock"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// ErrMiss is returned by Get when the key is in neither tier.
+var ErrMiss = errors.New("cache miss")
+
+type Options struct {
+	// LocalSize is the number of entries kept in process, zero disables the local tier.
+	LocalSize int
+	// LocalTTL bounds how long an entry is served from the local tier.
+	LocalTTL time.Duration
+	// Codec serializes the values of Fetch, keys.JSON if unset.
+	Codec keys.Codec
+	// Clock expires local entries, clock.Real if unset. Redis expires its keys on its own clock.
+	Clock clock.Clock
+}
+
+// Stats counts where reads were answered from since startup.
+type Stats struct {
+	LocalHits  uint64 `json:"local_hits"`
+	RedisHits  uint64 `json:"redis_hits"`
+	Misses     uint64 `json:"misses"`
+	LocalSize  int    `json:"local_size"`
+	Subscribed bool   `json:"subscribed"`
+}
+
+type Cache struct {
+	redis    *redis.Client
+	keys     keys.Space
+	codec    keys.Codec
+	local    *lru
+	localTTL time.Duration
+	clock    clock.Clock
+	logger   *zerolog.Logger
+
+	// subscribed is set while invalidations are received, the local tier is only
+	// used meanwhile since it could serve stale entries otherwise.
+	subscribed atomic.Bool
+
+	localHits atomic.Uint64
+	redisHits atomic.Uint64
+	misses    atomic.Uint64
+
+	stopOnce sync.Once
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// New returns a Cache on top of client, keeping its entries in space. Keys passed to
+// the cache are relative to space. Call Start to enable the local tier.
+func New(client *redis.Client, space keys.Space, opts Options, logger *zerolog.Logger) *Cache {
+	c := &Cache{
+		redis:    client,
+		keys:     space,
+		codec:    opts.Codec,
+		localTTL: opts.LocalTTL,
+		clock:    clock.OrReal(opts.Clock),
+		logger:   logger,
+	}
+
+	if c.codec == nil {
+		c.codec = keys.JSON
+	}
+
+	if opts.LocalSize > 0 && opts.LocalTTL > 0 {
+		c.local = newLRU(opts.LocalSize)
+	}
+
+	return c
+}
+
+// Start subscribes to invalidations in the background, until Stop is called.
+// Without the local tier there is nothing to invalidate and Start does nothing.
+func (c *Cache) Start() {
+	if c.local == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background()) //nolint:forbidigo // background subscription, stopped by Stop
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go c.subscribe(ctx)
+}
+
+// Stop ends the subscription and waits for it to finish.
+func (c *Cache) Stop() {
+	c.stopOnce.Do(func() {
+		if c.cancel == nil {
+			return
+		}
+
+		c.cancel()
+		<-c.done
+	})
+}
+
+// Get returns the value of key, from the local tier if possible, ErrMiss if it isn't cached.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	useLocal := c.local != nil && c.subscribed.Load()
+
+	var generation uint64
+	if useLocal {
+		if value, ok := c.local.get(key, c.clock.Now()); ok {
+			c.localHits.Add(1)
+			return value, nil
+		}
+		generation = c.local.currentGeneration()
+	}
+
+	value, err := c.redis.Get(ctx, c.keys.Key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		c.misses.Add(1)
+		return nil, ErrMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache key %s: %w", key, err)
+	}
+
+	c.redisHits.Add(1)
+	if useLocal {
+		c.local.addIfGeneration(key, value, c.clock.Now().Add(c.localTTL), generation)
+	}
+
+	return value, nil
+}
+
+// Set stores value in Redis for ttl (zero means keys.CacheTTL) and tells the other
+// instances to drop their local copy.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = keys.CacheTTL
+	}
+
+	if err := c.redis.Set(ctx, c.keys.Key(key), value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to write cache key %s: %w", key, err)
+	}
+
+	if err := c.publish(ctx, key); err != nil {
+		return err
+	}
+
+	if c.local != nil && c.subscribed.Load() {
+		localTTL := c.localTTL
+		if ttl < localTTL {
+			localTTL = ttl
+		}
+		c.local.add(key, value, c.clock.Now().Add(localTTL))
+	}
+
+	return nil
+}
+
+// Delete removes keys from Redis and from the local tier of every instance.
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if c.local != nil {
+		c.local.remove(keys...)
+	}
+
+	namespaced := make([]string, len(keys))
+	for i, key := range keys {
+		namespaced[i] = c.keys.Key(key)
+	}
+
+	if err := c.redis.Del(ctx, namespaced...).Err(); err != nil {
+		return fmt.Errorf("failed to delete cache keys: %w", err)
+	}
+
+	return c.publish(ctx, keys...)
+}
+
+// Stats returns the hit counters and the state of the local tier.
+func (c *Cache) Stats() Stats {
+	stats := Stats{
+		LocalHits:  c.localHits.Load(),
+		RedisHits:  c.redisHits.Load(),
+		Misses:     c.misses.Load(),
+		Subscribed: c.subscribed.Load(),
+	}
+
+	if c.local != nil {
+		stats.LocalSize = c.local.len()
+	}
+
+	return stats
+}
+
+// Fetch returns the value cached under key, decoded with the cache's codec. On a miss it calls
+// load and caches the result for ttl, a nil pointer or empty value is cached too
+// so lookups of things that don't exist don't hit the database either.
+func Fetch[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+
+	cached, err := c.Get(ctx, key)
+	if err == nil {
+		if err := c.codec.Unmarshal(cached, &value); err == nil {
+			return value, nil
+		}
+	} else if !errors.Is(err, ErrMiss) {
+		return value, err
+	}
+
+	value, err = load(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return value, fmt.Errorf("failed to encode cache key %s: %w", key, err)
+	}
+
+	if err := c.Set(ctx, key, data, ttl); err != nil {
+		return value, err
+	}
+
+	return value, nil
+}
+
+func (c *Cache) publish(ctx context.Context, keys ...string) error {
+	if c.local == nil {
+		return nil
+	}
+
+	message, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache invalidation: %w", err)
+	}
+
+	if err := c.redis.Publish(ctx, c.invalidationChannel(), message).Err(); err != nil {
+		return fmt.Errorf("failed to publish cache invalidation: %w", err)
+	}
+
+	return nil
+}
+
+// invalidationChannel is the Redis pub/sub channel invalidated keys are published on.
+func (c *Cache) invalidationChannel() string {
+	return c.keys.Key("invalidate")
+}
+
+// subscribe applies invalidations until ctx ends. Messages published while the
+// connection is down are lost, so the local tier is purged and bypassed until
+// the subscription is back.
+func (c *Cache) subscribe(ctx context.Context) {
+	defer close(c.done)
+
+	pubsub := c.redis.Subscribe(ctx, c.invalidationChannel())
+	defer pubsub.Close()
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if ctx.Err() != nil {
+			c.subscribed.Store(false)
+			return
+		}
+
+		if err != nil {
+			if c.subscribed.Swap(false) {
+				c.logger.Warn().Err(err).Msg("cache invalidation subscription lost, bypassing local cache")
+			}
+			c.local.purge()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.clock.After(time.Second):
+			}
+			continue
+		}
+
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			if msg.Kind == "subscribe" {
+				c.local.purge()
+				c.subscribed.Store(true)
+			}
+		case *redis.Message:
+			var keys []string
+			if err := json.Unmarshal([]byte(msg.Payload), &keys); err != nil {
+				// Can't tell what changed, start over rather than serve stale entries.
+				c.local.purge()
+				continue
+			}
+			c.local.remove(keys...)
+		}
+	}
+}
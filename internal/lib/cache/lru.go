@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru is the in-process tier: a size-bounded map evicting the least recently used
+// entry, whose entries also expire after their TTL.
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	// generation changes with every removal, so a value read from Redis before an
+	// invalidation arrived isn't stored afterwards, see addIfGeneration.
+	generation uint64
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+func (l *lru) get(key string, now time.Time) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !now.Before(entry.expiresAt) {
+		l.order.Remove(elem)
+		delete(l.entries, key)
+		return nil, false
+	}
+
+	l.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (l *lru) currentGeneration() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.generation
+}
+
+// add stores value until expiresAt, evicting the least recently used entry when full.
+func (l *lru) add(key string, value []byte, expiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.addLocked(key, value, expiresAt)
+}
+
+// addIfGeneration stores value unless something was invalidated since generation was read.
+func (l *lru) addIfGeneration(key string, value []byte, expiresAt time.Time, generation uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.generation != generation {
+		return
+	}
+	l.addLocked(key, value, expiresAt)
+}
+
+func (l *lru) addLocked(key string, value []byte, expiresAt time.Time) {
+	if elem, ok := l.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (l *lru) remove(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.generation++
+	for _, key := range keys {
+		if elem, ok := l.entries[key]; ok {
+			l.order.Remove(elem)
+			delete(l.entries, key)
+		}
+	}
+}
+
+func (l *lru) purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.generation++
+	l.entries = make(map[string]*list.Element, l.size)
+	l.order.Init()
+}
+
+func (l *lru) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
@@ -0,0 +1,137 @@
+// Package captcha verifies CAPTCHA tokens with the provider that issued them:
+// Cloudflare Turnstile, hCaptcha or Google reCAPTCHA. All three take the same
+// siteverify request, so one Verifier covers them, pointed at the provider's endpoint.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/latency"
+)
+
+type Provider string
+
+const (
+	ProviderTurnstile Provider = "turnstile"
+	ProviderHCaptcha  Provider = "hcaptcha"
+	ProviderReCaptcha Provider = "recaptcha"
+)
+
+var endpoints = map[Provider]string{
+	ProviderTurnstile: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	ProviderHCaptcha:  "https://api.hcaptcha.com/siteverify",
+	ProviderReCaptcha: "https://www.google.com/recaptcha/api/siteverify",
+}
+
+const defaultTimeout = 5 * time.Second
+
+var (
+	ErrMissingToken = errors.New("captcha token is missing")
+	ErrRejected     = errors.New("captcha token was rejected")
+	ErrLowScore     = errors.New("captcha score is below the minimum")
+)
+
+// Result is the provider's verdict on a token.
+type Result struct {
+	Success  bool   `json:"success"`
+	Hostname string `json:"hostname"`
+	// Action is the action the token was issued for (Turnstile and reCAPTCHA v3).
+	Action string `json:"action"`
+	// Score is set by reCAPTCHA v3 and hCaptcha Enterprise, from 0 (bot) to 1 (human).
+	Score      *float64 `json:"score"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+type Verifier struct {
+	provider   Provider
+	secret     string
+	endpoint   string
+	minScore   float64
+	httpClient *http.Client
+}
+
+// NewVerifier returns a Verifier for provider. Tokens carrying a score below minScore
+// are rejected, zero accepts any score. A zero timeout uses 5 seconds.
+func NewVerifier(provider Provider, secret string, minScore float64, timeout time.Duration) (*Verifier, error) {
+	endpoint, ok := endpoints[provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown captcha provider %q", provider)
+	}
+
+	if secret == "" {
+		return nil, fmt.Errorf("captcha secret is required")
+	}
+
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Verifier{
+		provider:   provider,
+		secret:     secret,
+		endpoint:   endpoint,
+		minScore:   minScore,
+		httpClient: &http.Client{Timeout: timeout, Transport: &latency.Transport{}},
+	}, nil
+}
+
+// Provider returns the provider tokens are verified with.
+func (v *Verifier) Provider() Provider {
+	return v.provider
+}
+
+// Verify checks token with the provider. A token the provider turns down returns
+// ErrRejected or ErrLowScore together with the result, any other error means the
+// provider couldn't be asked and says nothing about the token.
+func (v *Verifier) Verify(ctx context.Context, token, remoteIP string) (*Result, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	form := url.Values{
+		"secret":   {v.secret},
+		"response": {token},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s verification request: %w", v.provider, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", v.provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s verification failed with status %d", v.provider, resp.StatusCode)
+	}
+
+	var result Result
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode %s verification response: %w", v.provider, err)
+	}
+
+	if !result.Success {
+		return &result, ErrRejected
+	}
+
+	if v.minScore > 0 && result.Score != nil && *result.Score < v.minScore {
+		return &result, ErrLowScore
+	}
+
+	return &result, nil
+}
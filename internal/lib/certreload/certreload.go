@@ -0,0 +1,127 @@
+// Package certreload serves a TLS certificate from files that are replaced while the
+// server runs, e.g. by cert-manager renewing short-lived certificates into a secret
+// mount. The files are polled and a changed pair is swapped in without a restart.
+package certreload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Certificate describes a loaded certificate, for logging rotations.
+type Certificate struct {
+	Subject      string
+	SerialNumber string
+	NotAfter     time.Time
+}
+
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	cert     atomic.Pointer[tls.Certificate]
+	mu       sync.Mutex
+	checksum []byte
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewReloader loads the key pair, failing if it is missing or invalid.
+func NewReloader(certFile, keyFile string) (*Reloader, *Certificate, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		stop:     make(chan struct{}),
+	}
+
+	loaded, _, err := r.Reload()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, loaded, nil
+}
+
+// GetCertificate is meant for tls.Config.GetCertificate, it returns the current certificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Reload reads the files and swaps in the key pair if it changed. changed is false if
+// the files still hold the current pair. On error the current certificate stays in
+// use, e.g. when the certificate was replaced but the key not yet.
+func (r *Reloader) Reload() (loaded *Certificate, changed bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read certificate: %w", err)
+	}
+
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	hash := sha256.New()
+	hash.Write(certPEM)
+	hash.Write(keyPEM)
+	checksum := hash.Sum(nil)
+	if bytes.Equal(checksum, r.checksum) {
+		return nil, false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load key pair: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	cert.Leaf = leaf
+
+	r.cert.Store(&cert)
+	r.checksum = checksum
+
+	return &Certificate{
+		Subject:      leaf.Subject.String(),
+		SerialNumber: leaf.SerialNumber.String(),
+		NotAfter:     leaf.NotAfter,
+	}, true, nil
+}
+
+// Watch polls the files every interval until Stop, calling onReload after every
+// attempt that swapped in a new pair or failed.
+func (r *Reloader) Watch(interval time.Duration, onReload func(loaded *Certificate, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			loaded, changed, err := r.Reload()
+			if changed || err != nil {
+				onReload(loaded, err)
+			}
+		}
+	}
+}
+
+// Stop ends Watch.
+func (r *Reloader) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
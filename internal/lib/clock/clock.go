@@ -0,0 +1,60 @@
+// Package clock abstracts time for the components making time-based decisions (cache
+// expiry, rate limit windows, job scheduling), so tests can substitute Fake and control
+// time instead of sleeping. Production code uses Real. Durations only measured for logs
+// and metrics keep using the time package.
+package clock
+
+import "time"
+
+// Clock tells the time and waits for it to pass.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	// After sends the current time once d elapsed, like time.After.
+	After(d time.Duration) <-chan time.Time
+	// NewTicker sends the current time every d, like time.NewTicker. d must be positive.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is a time.Ticker of a Clock.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the wall clock.
+var Real Clock = realClock{}
+
+// OrReal returns c, or Real if c is nil, for components where a clock is optional.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,132 @@
+// Package dataloader batches the loads of concurrent resolvers into one fetch, so a list
+// of N items resolving a related record runs one query instead of N (the N+1 problem).
+// A Loader caches what it loaded and is meant to live for one request.
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// FetchFunc loads the values of keys, typically with one query. Keys missing from the
+// map load as the zero value, e.g. nil for records that don't exist.
+type FetchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys loaded within wait of the first one, or until maxBatch keys
+// are collected, and fetches them at once.
+type Loader[K comparable, V any] struct {
+	fetch    FetchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	pending *batch[K, V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	// ctx is the context of the first load, the loads of a request share its deadline.
+	ctx     context.Context
+	keys    []K
+	results []*result[V]
+	timer   *time.Timer
+}
+
+func New[K comparable, V any](fetch FetchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: max(maxBatch, 1),
+		results:  make(map[K]*result[V]),
+	}
+}
+
+// Load returns the value of key, from the cache if it was loaded before. It blocks until
+// the batch key is part of is fetched, or ctx is done.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	res, ok := l.results[key]
+	if !ok {
+		res = &result[V]{done: make(chan struct{})}
+		l.results[key] = res
+		l.enqueue(ctx, key, res)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-res.done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// enqueue adds key to the pending batch, l.mu must be held.
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K, res *result[V]) {
+	if l.pending == nil {
+		b := &batch[K, V]{ctx: ctx}
+		b.timer = time.AfterFunc(l.wait, func() { l.dispatch(b) })
+		l.pending = b
+	}
+
+	b := l.pending
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, res)
+
+	if len(b.keys) >= l.maxBatch {
+		b.timer.Stop()
+		l.pending = nil
+		go l.run(b)
+	}
+}
+
+// dispatch fetches b once its wait is over, unless it filled up and was fetched already.
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.pending != b {
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mu.Unlock()
+
+	l.run(b)
+}
+
+func (l *Loader[K, V]) run(b *batch[K, V]) {
+	values, err := l.safeFetch(b)
+
+	for i, key := range b.keys {
+		res := b.results[i]
+		res.value, res.err = values[key], err
+		close(res.done)
+	}
+}
+
+// safeFetch runs the fetch on the batch's goroutine, which no Recover middleware covers,
+// so a panic fails the batch instead of the process.
+func (l *Loader[K, V]) safeFetch(b *batch[K, V]) (values map[K]V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			values, err = nil, fmt.Errorf("dataloader fetch panicked: %v", r)
+		}
+	}()
+
+	ctx := b.ctx
+	if txn := newrelic.FromContext(ctx); txn != nil {
+		ctx = newrelic.NewContext(ctx, txn.NewGoroutine())
+	}
+
+	return l.fetch(ctx, b.keys)
+}
@@ -0,0 +1,90 @@
+package drain
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pollInterval is how often Wait re-checks the in-flight counter.
+const pollInterval = 50 * time.Millisecond
+
+// Tracker counts in-flight requests and holds the readiness state of the instance.
+// Once draining starts the instance reports itself as not ready so the load
+// balancer stops sending new traffic, while requests already accepted finish.
+type Tracker struct {
+	inFlight   atomic.Int64
+	draining   atomic.Bool
+	mu         sync.Mutex
+	drainStart time.Time
+}
+
+// Result describes the outcome of waiting for in-flight requests to finish.
+type Result struct {
+	Duration  time.Duration
+	Remaining int64
+	TimedOut  bool
+}
+
+// NewTracker returns a Tracker in the ready state with no requests in flight.
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Begin marks the start of a request. Every call must be paired with End.
+func (t *Tracker) Begin() {
+	t.inFlight.Add(1)
+}
+
+// End marks a request as finished.
+func (t *Tracker) End() {
+	t.inFlight.Add(-1)
+}
+
+// InFlight returns the number of requests currently being served.
+func (t *Tracker) InFlight() int64 {
+	return t.inFlight.Load()
+}
+
+// Ready reports whether the instance should receive new traffic.
+func (t *Tracker) Ready() bool {
+	return !t.draining.Load()
+}
+
+// StartDraining flips readiness off. It returns the time draining started,
+// repeated calls keep the original start time.
+func (t *Tracker) StartDraining() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.draining.CompareAndSwap(false, true) {
+		t.drainStart = time.Now()
+	}
+
+	return t.drainStart
+}
+
+// Wait blocks until no requests are in flight or ctx is done, whichever comes first.
+func (t *Tracker) Wait(ctx context.Context) Result {
+	start := t.StartDraining()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if remaining := t.InFlight(); remaining <= 0 {
+			return Result{Duration: time.Since(start)}
+		}
+
+		select {
+		case <-ctx.Done():
+			return Result{
+				Duration:  time.Since(start),
+				Remaining: t.InFlight(),
+				TimedOut:  true,
+			}
+		case <-ticker.C:
+		}
+	}
+}
@@ -6,57 +6,118 @@
 package email
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"html/template"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
-	"github.com/pkg/errors"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/unsubscribe"
 	"github.com/resend/resend-go/v2"
 	"github.com/rs/zerolog"
 )
 
+// ProviderResend names Resend, the provider emails are sent through, in email.send_rates.
+const ProviderResend = "resend"
+
+// UnsubscribePath is the route unsubscribe links point to, relative to the server's base URL.
+const UnsubscribePath = "/api/v1/email/unsubscribe"
+
+// VerifyPath is the route email verification links point to, relative to the server's base URL.
+const VerifyPath = "/api/v1/email/verify"
+
 type Client struct {
-	client *resend.Client
-	logger *zerolog.Logger
+	client   *resend.Client
+	logger   *zerolog.Logger
+	renderer *Renderer
+
+	baseURL        string
+	unsubscribe    *unsubscribe.Signer
+	unsubscribeTTL time.Duration
 }
 
 // NewClient initializes and returns a new email Client.
 func NewClient(cfg *config.Config, logger *zerolog.Logger) *Client {
+	// Cache parsed templates everywhere but local development, so template edits show up without a restart.
+	cacheTemplates := cfg.Primary.Env != "local" && cfg.Primary.Env != "development"
+
 	return &Client{
-		client: resend.NewClient(cfg.Integration.ResendAPIKey),
-		logger: logger,
+		client:   resend.NewClient(cfg.Integration.ResendAPIKey),
+		logger:   logger,
+		renderer: NewRenderer(TemplatesDir, cacheTemplates),
+
+		baseURL:        cfg.Server.BaseURL,
+		unsubscribe:    unsubscribe.NewSigner(cfg.Auth.GetURLSigningKey()),
+		unsubscribeTTL: cfg.Email.UnsubscribeLinkTTL,
 	}
 }
 
+// UnsubscribeURL returns the link unsubscribing to from the emails of template.
+func (c *Client) UnsubscribeURL(to string, template Template) string {
+	token := c.unsubscribe.Issue(to, string(template), time.Now().Add(c.unsubscribeTTL))
+	return c.baseURL + UnsubscribePath + "?" + url.Values{"token": {token}}.Encode()
+}
+
+// VerificationURL returns the link verifying an email address with token.
+func (c *Client) VerificationURL(token string) string {
+	return c.baseURL + VerifyPath + "?" + url.Values{"token": {token}}.Encode()
+}
+
 // SendEmail renders an HTML template with dynamic data and sends it via the Resend API.
 // Parameters:
 // - to: recipient email address.
 // - subject: subject line for the email.
 // - templateName: name of the email template file (without path).
 // - data: key-value pairs passed into the HTML template for rendering.
-func (c *Client) SendEmail(to, subject string, templateName Template, data map[string]string) error {
+func (c *Client) SendEmail(to, subject string, templateName Template, data map[string]any) error {
+	// Bulk emails get a per-recipient unsubscribe link, in the footer and as the
+	// List-Unsubscribe headers for one-click unsubscribing (RFC 8058).
+	var headers map[string]string
+	if templateName.Bulk() {
+		unsubscribeURL := c.UnsubscribeURL(to, templateName)
 
-	// Build full path to the HTML template file (e.g., "templates/emails/welcome.html").
-	templatePath := fmt.Sprintf("%s/%s.html", "templates/emails", templateName)
+		withLink := make(map[string]any, len(data)+1)
+		for key, value := range data {
+			withLink[key] = value
+		}
+		withLink["UnsubscribeURL"] = unsubscribeURL
+		data = withLink
 
-	// Parse the template file from the given path.
-	templ, err := template.ParseFiles(templatePath)
-	if err != nil {
-		return errors.Wrapf(err, "failed to parse email template %s", templateName)
-	}
-	// Execute the parsed template with the provided data and write the result into a buffer.
-	var body bytes.Buffer
-	if err := templ.Execute(&body, data); err != nil {
-		return errors.Wrapf(err, "failed to execute email template %s", templateName)
+		headers = map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
 	}
 
-	//  Build the Resend SendEmailRequest object with the rendered HTML body and other parameters.
+	//  Build the Resend SendEmailRequest object with the rendered body and other parameters.
 	params := &resend.SendEmailRequest{
 		From:    fmt.Sprintf("%s <%s>", "Go-Boilerplate", "onboarding@resend.dev"),
 		To:      []string{to},
 		Subject: subject,
-		Html:    body.String(),
+		Headers: headers,
+	}
+
+	// Render the template (e.g., "templates/emails/welcome.html") inside the base layout, with CSS inlined.
+	// If the template is broken, the plaintext fallback is sent instead so the recipient still gets the email.
+	body, err := c.renderer.Render(templateName, data)
+	if err != nil {
+		text, fallbackErr := renderFallback(templateName, data)
+		if fallbackErr != nil {
+			c.logger.Error().Err(fallbackErr).Str("template", string(templateName)).Msg("email template fallback failed")
+			return err
+		}
+
+		c.logger.Error().Err(err).Str("template", string(templateName)).Msg("email template failed to render, sending the plaintext fallback")
+		observe.Event("EmailTemplateFallback", map[string]any{
+			"template": string(templateName),
+			"error":    err.Error(),
+		})
+
+		params.Text = text
+	} else {
+		params.Html = body
 	}
 
 	// Send the email using the Resend client.
@@ -67,3 +128,31 @@ func (c *Client) SendEmail(to, subject string, templateName Template, data map[s
 
 	return nil
 }
+
+// Message is an email to send later, e.g. from a job: a template rendered with Data.
+type Message struct {
+	To       string         `json:"to"`
+	Subject  string         `json:"subject"`
+	Template Template       `json:"template"`
+	Data     map[string]any `json:"data,omitempty"`
+}
+
+// Send sends msg, see SendEmail.
+func (c *Client) Send(msg Message) error {
+	return c.SendEmail(msg.To, msg.Subject, msg.Template, msg.Data)
+}
+
+// VerifyAPIKey checks that Resend accepts the configured API key.
+// Sending-only keys are rejected for anything but sending, which still proves they're valid.
+func (c *Client) VerifyAPIKey(ctx context.Context) error {
+	_, err := c.client.ApiKeys.ListWithContext(ctx)
+	if err != nil && !strings.Contains(err.Error(), "restricted") {
+		return fmt.Errorf("resend rejected the api key: %w", err)
+	}
+	return nil
+}
+
+// CheckTemplates verifies every known email template parses.
+func (c *Client) CheckTemplates() error {
+	return c.renderer.Check(Templates...)
+}
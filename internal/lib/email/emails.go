@@ -2,11 +2,97 @@
 // welcome messages, notifications, and password resets to users.
 package email
 
+import "time"
+
 // SendWelcomeEmail sends a personalized "Welcome" email to a new user.
 func (c *Client) SendWelcomeEmail(to, firstName string) error {
-	data := map[string]string{
+	data := map[string]any{
 		"UserFirstName": firstName,
 	}
 
 	return c.SendEmail(to, "Welcome to TradeAnalyze", TemplateWelcome, data)
 }
+
+// SendHealthProbeEmail sends the probe email of a deep health check.
+func (c *Client) SendHealthProbeEmail(to, probeID string) error {
+	data := map[string]any{
+		"ProbeID": probeID,
+	}
+
+	return c.SendEmail(to, "Health probe "+probeID, TemplateHealthProbe, data)
+}
+
+// SendVerificationEmail sends the link verifying to, valid until expiresAt.
+func (c *Client) SendVerificationEmail(to, verificationURL string, expiresAt time.Time) error {
+	data := map[string]any{
+		"VerificationURL": verificationURL,
+		"ExpiresAt":       expiresAt,
+	}
+
+	return c.SendEmail(to, "Verify your email address", TemplateVerifyEmail, data)
+}
+
+// DigestActivity is one line of a digest email.
+type DigestActivity struct {
+	Action string
+	Count  int64
+}
+
+// SendDigestEmail sends a summary of the user's activity between since and until.
+func (c *Client) SendDigestEmail(to, firstName string, since, until time.Time, activity []DigestActivity) error {
+	var total int64
+	for _, item := range activity {
+		total += item.Count
+	}
+
+	data := map[string]any{
+		"UserFirstName": firstName,
+		"Since":         since,
+		"Until":         until,
+		"Activity":      activity,
+		"Total":         total,
+	}
+
+	return c.SendEmail(to, "Your activity summary", TemplateDigest, data)
+}
+
+// DeprecatedRoute is one line of a deprecation notice.
+type DeprecatedRoute struct {
+	Route      string
+	Requests   int64
+	LastSeenAt time.Time
+}
+
+// SendDeprecationNoticeEmail tells the user which deprecated routes they called since since.
+func (c *Client) SendDeprecationNoticeEmail(to, firstName string, since time.Time, routes []DeprecatedRoute) error {
+	data := map[string]any{
+		"UserFirstName": firstName,
+		"Since":         since,
+		"Routes":        routes,
+	}
+
+	return c.SendEmail(to, "You are using deprecated API endpoints", TemplateDeprecationNotice, data)
+}
+
+// DeadTask is the background task an on-call alert is about.
+type DeadTask struct {
+	ID       string
+	Type     string
+	Queue    string
+	Retried  int
+	MaxRetry int
+	Error    string
+	Payload  string
+	DiedAt   time.Time
+	// Cooldown is how long further dead tasks of the type don't alert again.
+	Cooldown time.Duration
+}
+
+// SendDeadTaskEmail alerts on-call that a background task ran out of retries.
+func (c *Client) SendDeadTaskEmail(to string, task DeadTask) error {
+	data := map[string]any{
+		"Task": task,
+	}
+
+	return c.SendEmail(to, "Background task "+task.Type+" ran out of retries", TemplateDeadTask, data)
+}
@@ -0,0 +1,72 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// fallbackTemplates are minimal plaintext versions of the email templates, carrying only
+// the data the recipient can't do without. They are sent when the HTML template fails to
+// parse or execute, so a template regression doesn't stop critical emails. They live in
+// code rather than in TemplatesDir so a broken deploy of the template files can't break
+// them too, and are parsed at startup so a mistake in them fails fast.
+var fallbackTemplates = map[Template]*template.Template{
+	TemplateWelcome: parseFallback(TemplateWelcome, `Hi{{ with .UserFirstName }} {{ . }}{{ end }},
+
+Welcome to TradeAnalyze, your account is ready.
+`),
+	TemplateVerifyEmail: parseFallback(TemplateVerifyEmail, `Hi,
+
+Please confirm this is your email address by opening this link:
+
+{{ .VerificationURL }}
+
+The link is valid until {{ date "Jan 2, 2006 15:04 MST" .ExpiresAt }}. If you didn't ask for it, you can ignore this email.
+`),
+	TemplateDigest: parseFallback(TemplateDigest, `Hi{{ with .UserFirstName }} {{ . }}{{ end }},
+
+Here is your activity summary{{ with .Since }} since {{ date "Jan 2, 2006" . }}{{ end }}:
+{{ range .Activity }}
+- {{ .Action }}: {{ .Count }}{{ end }}
+
+Total: {{ .Total }}
+{{ with .UnsubscribeURL }}
+Unsubscribe: {{ . }}
+{{ end }}`),
+	TemplateDeprecationNotice: parseFallback(TemplateDeprecationNotice, `Hi{{ with .UserFirstName }} {{ . }}{{ end }},
+
+You called these deprecated API endpoints{{ with .Since }} since {{ date "Jan 2, 2006" . }}{{ end }}:
+{{ range .Routes }}
+- {{ .Route }} ({{ .Requests }} requests){{ end }}
+
+Please move to their replacements before they are removed.
+`),
+	TemplateHealthProbe: parseFallback(TemplateHealthProbe, `Health probe {{ .ProbeID }}
+`),
+	TemplateDeadTask: parseFallback(TemplateDeadTask, `A {{ .Task.Type }} task ran out of retries and was archived.
+
+Task: {{ .Task.ID }}
+Queue: {{ .Task.Queue }}
+Error: {{ .Task.Error }}
+`),
+}
+
+func parseFallback(name Template, text string) *template.Template {
+	return template.Must(template.New(string(name)).Funcs(template.FuncMap(TemplateFuncs())).Parse(text))
+}
+
+// renderFallback executes the plaintext fallback of the named template with data.
+func renderFallback(name Template, data any) (string, error) {
+	templ, ok := fallbackTemplates[name]
+	if !ok {
+		return "", fmt.Errorf("email template %s has no fallback", name)
+	}
+
+	var body bytes.Buffer
+	if err := templ.Execute(&body, data); err != nil {
+		return "", fmt.Errorf("failed to execute fallback of email template %s: %w", name, err)
+	}
+
+	return body.String(), nil
+}
@@ -0,0 +1,234 @@
+package email
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	cssCommentRegex      = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssSelectorPartRegex = regexp.MustCompile(`[.#]?[^.#]+`)
+)
+
+// cssRule is a single selector of a stylesheet rule with its declarations.
+// Only simple selectors are inlined: tag, .class, #id and combinations like tag.class.
+type cssRule struct {
+	tag          string
+	id           string
+	classes      []string
+	declarations string
+	specificity  int
+	order        int
+}
+
+// InlineCSS copies the rules of every <style> block onto the style attribute of matching
+// elements. Declarations already present inline win over stylesheet rules. The <style>
+// blocks are kept for clients that support them (media queries can't be inlined).
+func InlineCSS(document string) (string, error) {
+	rules := parseStylesheets(document)
+	if len(rules) == 0 {
+		return document, nil
+	}
+
+	var out bytes.Buffer
+	tokenizer := html.NewTokenizer(strings.NewReader(document))
+
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return out.String(), nil
+			}
+			return "", tokenizer.Err()
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			// Copy the raw bytes before Token() since the tokenizer reuses its buffer.
+			raw := string(tokenizer.Raw())
+			token := tokenizer.Token()
+
+			style := matchingDeclarations(rules, token)
+			if style == "" {
+				out.WriteString(raw)
+				continue
+			}
+
+			token.Attr = mergeStyleAttr(token.Attr, style)
+			out.WriteString(token.String())
+
+		default:
+			// Everything else (text, style contents, comments, end tags) is written as-is.
+			out.Write(tokenizer.Raw())
+		}
+	}
+}
+
+// parseStylesheets extracts the inlinable rules from every <style> element.
+func parseStylesheets(document string) []cssRule {
+	var rules []cssRule
+
+	tokenizer := html.NewTokenizer(strings.NewReader(document))
+	inStyle := false
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return rules
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			inStyle = string(name) == "style"
+		case html.EndTagToken:
+			inStyle = false
+		case html.TextToken:
+			if inStyle {
+				rules = append(rules, parseCSS(string(tokenizer.Text()), len(rules))...)
+			}
+		}
+	}
+}
+
+// parseCSS parses top-level rules of a stylesheet. At-rules such as @media are skipped.
+func parseCSS(css string, orderOffset int) []cssRule {
+	css = cssCommentRegex.ReplaceAllString(css, "")
+
+	var rules []cssRule
+	depth := 0
+	start := 0
+	selectorEnd := -1
+
+	for i, char := range css {
+		switch char {
+		case '{':
+			if depth == 0 {
+				selectorEnd = i
+			}
+			depth++
+		case '}':
+			depth--
+			if depth == 0 && selectorEnd >= 0 {
+				selectors := strings.TrimSpace(css[start:selectorEnd])
+				declarations := strings.TrimSpace(css[selectorEnd+1 : i])
+
+				if !strings.HasPrefix(selectors, "@") {
+					for _, selector := range strings.Split(selectors, ",") {
+						if rule, ok := parseSelector(strings.TrimSpace(selector)); ok {
+							rule.declarations = declarations
+							rule.order = orderOffset + len(rules)
+							rules = append(rules, rule)
+						}
+					}
+				}
+
+				start = i + 1
+				selectorEnd = -1
+			}
+		}
+	}
+
+	return rules
+}
+
+// parseSelector parses a simple selector, it reports false for selectors that can't be inlined
+// (descendant combinators, pseudo classes, attribute selectors, ...).
+func parseSelector(selector string) (cssRule, bool) {
+	if selector == "" || strings.ContainsAny(selector, " >+~:[*") {
+		return cssRule{}, false
+	}
+
+	var rule cssRule
+	parts := cssSelectorPartRegex.FindAllString(selector, -1)
+
+	for _, part := range parts {
+		switch part[0] {
+		case '.':
+			rule.classes = append(rule.classes, part[1:])
+			rule.specificity += 10
+		case '#':
+			rule.id = part[1:]
+			rule.specificity += 100
+		default:
+			rule.tag = strings.ToLower(part)
+			rule.specificity++
+		}
+	}
+
+	return rule, true
+}
+
+func (rule cssRule) matches(token html.Token) bool {
+	if rule.tag != "" && rule.tag != token.Data {
+		return false
+	}
+
+	var id, class string
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "id":
+			id = attr.Val
+		case "class":
+			class = attr.Val
+		}
+	}
+
+	if rule.id != "" && rule.id != id {
+		return false
+	}
+
+	classes := strings.Fields(class)
+	for _, required := range rule.classes {
+		found := false
+		for _, c := range classes {
+			if c == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchingDeclarations concatenates the declarations of every rule matching the element,
+// ordered by specificity and then source order so later declarations win.
+func matchingDeclarations(rules []cssRule, token html.Token) string {
+	var matched []cssRule
+	for _, rule := range rules {
+		if rule.matches(token) {
+			matched = append(matched, rule)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].specificity != matched[j].specificity {
+			return matched[i].specificity < matched[j].specificity
+		}
+		return matched[i].order < matched[j].order
+	})
+
+	declarations := make([]string, 0, len(matched))
+	for _, rule := range matched {
+		declarations = append(declarations, strings.TrimSuffix(rule.declarations, ";"))
+	}
+
+	return strings.Join(declarations, "; ")
+}
+
+// mergeStyleAttr prepends style to the element's existing style attribute.
+func mergeStyleAttr(attrs []html.Attribute, style string) []html.Attribute {
+	for i, attr := range attrs {
+		if attr.Key == "style" {
+			attrs[i].Val = style + "; " + attr.Val
+			return attrs
+		}
+	}
+
+	return append(attrs, html.Attribute{Key: "style", Val: style})
+}
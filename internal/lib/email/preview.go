@@ -1,7 +1,40 @@
 package email
 
-var PreviewData = map[string]map[string]string{
+import "time"
+
+var PreviewData = map[string]map[string]any{
 	"welcome": {
 		"UserFirstName": "John",
 	},
+	"health_probe": {
+		"ProbeID": "8c0f5e2a-6d1b-4a57-9f3e-2b7c1d9e4a10",
+	},
+	"verify_email": {
+		"VerificationURL": "https://example.com/api/v1/email/verify?token=preview",
+		"ExpiresAt":       time.Date(2025, time.January, 7, 12, 0, 0, 0, time.UTC),
+	},
+	"dead_task": {
+		"Task": DeadTask{
+			ID:       "5b7e2c1a-9f4d-4e3b-8a6c-1d2f3e4a5b6c",
+			Type:     "email:welcome",
+			Queue:    "default",
+			Retried:  3,
+			MaxRetry: 3,
+			Error:    "failed to send email: provider unavailable",
+			Payload:  "52 bytes, keys first_name, to",
+			DiedAt:   time.Date(2025, time.January, 7, 12, 0, 0, 0, time.UTC),
+			Cooldown: 15 * time.Minute,
+		},
+	},
+	"digest": {
+		"UserFirstName": "John",
+		"Since":         time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC),
+		"Until":         time.Date(2025, time.January, 13, 0, 0, 0, 0, time.UTC),
+		"Activity": []map[string]any{
+			{"Action": "Data export requested", "Count": 1},
+			{"Action": "Data export downloaded", "Count": 2},
+		},
+		"Total":          3,
+		"UnsubscribeURL": "https://example.com/api/v1/email/unsubscribe?token=preview",
+	},
 }
@@ -0,0 +1,213 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TemplatesDir is where email templates live, relative to the working directory.
+	TemplatesDir = "templates/emails"
+	// layoutTemplate is the entry point every email is rendered through.
+	layoutTemplate = "layout"
+)
+
+// Renderer renders email templates inside the shared base layout.
+//
+// Templates are laid out as:
+//   - layouts/base.html defines "layout", the HTML skeleton and shared <style> block.
+//   - partials/*.html define reusable blocks such as "header" and "footer".
+//   - <name>.html defines "content" (and optionally "title") for one email.
+//
+// After rendering, the CSS from <style> blocks is inlined into style attributes,
+// since many email clients ignore or strip stylesheets.
+type Renderer struct {
+	dir   string
+	cache bool
+
+	mu        sync.RWMutex
+	templates map[Template]*template.Template
+}
+
+// NewRenderer returns a Renderer reading templates from dir. When cache is true
+// parsed templates are kept in memory, disable it in development to pick up edits.
+func NewRenderer(dir string, cache bool) *Renderer {
+	return &Renderer{
+		dir:       dir,
+		cache:     cache,
+		templates: make(map[Template]*template.Template),
+	}
+}
+
+// Render executes the named template with data and returns the HTML body with CSS inlined.
+func (r *Renderer) Render(name Template, data any) (string, error) {
+	templ, err := r.load(name)
+	if err != nil {
+		return "", err
+	}
+
+	var body bytes.Buffer
+	if err := templ.ExecuteTemplate(&body, layoutTemplate, data); err != nil {
+		return "", errors.Wrapf(err, "failed to execute email template %s", name)
+	}
+
+	inlined, err := InlineCSS(body.String())
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to inline css of email template %s", name)
+	}
+
+	return inlined, nil
+}
+
+// Check parses the given templates and reports the first one that is missing or broken.
+func (r *Renderer) Check(names ...Template) error {
+	for _, name := range names {
+		if _, err := r.load(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Renderer) load(name Template) (*template.Template, error) {
+	if r.cache {
+		r.mu.RLock()
+		templ, ok := r.templates[name]
+		r.mu.RUnlock()
+		if ok {
+			return templ, nil
+		}
+	}
+
+	partials, err := filepath.Glob(filepath.Join(r.dir, "partials", "*.html"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list email partials")
+	}
+
+	// The page is parsed last so its "title" and "content" override the layout defaults.
+	files := append([]string{filepath.Join(r.dir, "layouts", "base.html")}, partials...)
+	files = append(files, filepath.Join(r.dir, fmt.Sprintf("%s.html", name)))
+
+	templ, err := template.New(string(name)).Funcs(TemplateFuncs()).ParseFiles(files...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse email template %s", name)
+	}
+
+	if r.cache {
+		r.mu.Lock()
+		r.templates[name] = templ
+		r.mu.Unlock()
+	}
+
+	return templ, nil
+}
+
+// TemplateFuncs returns the helper functions available in every email template.
+// Arguments are ordered so the value can be piped in, e.g. {{ .Total | currency "USD" }}.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"currency":  formatCurrency,
+		"date":      formatDate,
+		"pluralize": pluralize,
+		"now":       time.Now,
+		"upper":     strings.ToUpper,
+	}
+}
+
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"NGN": "₦",
+	"JPY": "¥",
+}
+
+// formatCurrency formats an amount in major units, e.g. currency "USD" 1234.5 -> "$1,234.50".
+func formatCurrency(code string, amount any) (string, error) {
+	value, err := toFloat(amount)
+	if err != nil {
+		return "", err
+	}
+
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = math.Abs(value)
+	}
+
+	formatted := strconv.FormatFloat(value, 'f', 2, 64)
+	whole, fraction, _ := strings.Cut(formatted, ".")
+
+	// Group the whole part in thousands.
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	code = strings.ToUpper(code)
+	if symbol, ok := currencySymbols[code]; ok {
+		return sign + symbol + grouped.String() + "." + fraction, nil
+	}
+	return sign + grouped.String() + "." + fraction + " " + code, nil
+}
+
+// formatDate formats t with a Go time layout, e.g. date "Jan 2, 2006" .CreatedAt.
+func formatDate(layout string, t any) (string, error) {
+	switch value := t.(type) {
+	case time.Time:
+		return value.Format(layout), nil
+	case *time.Time:
+		if value == nil {
+			return "", nil
+		}
+		return value.Format(layout), nil
+	default:
+		return "", fmt.Errorf("date: unsupported value of type %T", t)
+	}
+}
+
+// pluralize returns "<count> <word>" with the singular or plural form, e.g. pluralize 3 "item" "items".
+func pluralize(count any, singular, plural string) (string, error) {
+	value, err := toFloat(count)
+	if err != nil {
+		return "", err
+	}
+
+	word := plural
+	if value == 1 {
+		word = singular
+	}
+
+	return strconv.FormatFloat(value, 'f', -1, 64) + " " + word, nil
+}
+
+func toFloat(v any) (float64, error) {
+	switch value := v.(type) {
+	case int:
+		return float64(value), nil
+	case int32:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case float32:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	case string:
+		return strconv.ParseFloat(value, 64)
+	default:
+		return 0, fmt.Errorf("unsupported numeric value of type %T", v)
+	}
+}
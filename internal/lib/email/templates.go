@@ -2,4 +2,36 @@ package email
 
 type Template string
 
-const TemplateWelcome Template = "welcome"
+const (
+	TemplateWelcome Template = "welcome"
+	TemplateDigest  Template = "digest"
+	// TemplateDeprecationNotice lists the deprecated routes a user still calls.
+	TemplateDeprecationNotice Template = "deprecation_notice"
+	// TemplateHealthProbe is sent by the deep health check, to a sandbox address.
+	TemplateHealthProbe Template = "health_probe"
+	// TemplateVerifyEmail carries the link verifying a user's email address.
+	TemplateVerifyEmail Template = "verify_email"
+	// TemplateDeadTask alerts on-call to a background task that ran out of retries.
+	TemplateDeadTask Template = "dead_task"
+)
+
+// Templates lists every email template the application sends.
+var Templates = []Template{
+	TemplateWelcome,
+	TemplateDigest,
+	TemplateDeprecationNotice,
+	TemplateHealthProbe,
+	TemplateVerifyEmail,
+	TemplateDeadTask,
+}
+
+// bulkTemplates are the non-essential emails sent to many users at once. They carry
+// an unsubscribe link and the List-Unsubscribe headers mailbox providers require.
+var bulkTemplates = map[Template]bool{
+	TemplateDigest: true,
+}
+
+// Bulk reports whether t is a bulk email recipients can unsubscribe from.
+func (t Template) Bulk() bool {
+	return bulkTemplates[t]
+}
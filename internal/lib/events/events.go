@@ -0,0 +1,82 @@
+// Package events is an in-process event bus. Publishing within a transaction started
+// by database.TxManager defers delivery until the commit and drops the event on
+// rollback, so listeners never observe events about data that was not persisted.
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/rs/zerolog"
+)
+
+// Event is something that happened, e.g. "account_deletion.completed".
+type Event struct {
+	Name       string
+	Payload    any
+	OccurredAt time.Time
+}
+
+// Handler reacts to an event. Handlers run synchronously, in subscription order,
+// long-running work should be handed to the job queue.
+type Handler func(ctx context.Context, event Event)
+
+type Bus struct {
+	logger *zerolog.Logger
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewBus(logger *zerolog.Logger) *Bus {
+	return &Bus{
+		logger:   logger,
+		handlers: make(map[string][]Handler),
+	}
+}
+
+// Subscribe registers handler for events named name.
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Publish delivers event to its handlers. Within a transaction carried by ctx delivery
+// is deferred until the transaction commits, and the event is discarded on rollback.
+func (b *Bus) Publish(ctx context.Context, name string, payload any) {
+	event := Event{
+		Name:       name,
+		Payload:    payload,
+		OccurredAt: time.Now(),
+	}
+
+	if database.AfterCommit(ctx, func(ctx context.Context) { b.dispatch(ctx, event) }) {
+		return
+	}
+
+	b.dispatch(ctx, event)
+}
+
+func (b *Bus) dispatch(ctx context.Context, event Event) {
+	b.mu.RLock()
+	handlers := b.handlers[event.Name]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.run(ctx, handler, event)
+	}
+}
+
+// run calls handler, a panicking handler is logged and doesn't stop the others.
+func (b *Bus) run(ctx context.Context, handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error().Interface("panic", r).Str("event", event.Name).Msg("event handler panicked")
+		}
+	}()
+
+	handler(ctx, event)
+}
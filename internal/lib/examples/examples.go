@@ -0,0 +1,250 @@
+// Package examples keeps request/response examples captured from real traffic in
+// development, one JSON file per route, and embeds them into the OpenAPI spec so the
+// docs show realistic payloads without anyone writing them by hand.
+//
+// Bodies are anonymized before they are stored: values of sensitive fields (tokens,
+// passwords, emails, phone numbers, ...) are replaced, as are e-mail addresses and
+// UUIDs wherever they appear.
+package examples
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Placeholders replacing anonymized values.
+const (
+	RedactedValue = "<redacted>"
+	ExampleEmail  = "user@example.com"
+	ExampleUUID   = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	uuidPattern  = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	// sensitiveFields are redacted when a field name contains one of them.
+	sensitiveFields = []string{"password", "secret", "token", "authorization", "api_key", "apikey", "phone", "ip", "user_agent", "signature", "card", "iban", "ssn"}
+	// routeParam matches echo path parameters, ":id" becomes "{id}" in OpenAPI.
+	routeParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+)
+
+// Example is a request and its response captured on a route.
+type Example struct {
+	Method string `json:"method"`
+	// Path is the OpenAPI path of the route, e.g. /api/v1/me/devices/{id}.
+	Path     string          `json:"path"`
+	Status   int             `json:"status"`
+	Request  json.RawMessage `json:"request,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+	// CapturedAt is when the example was last replaced.
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// OpenAPIPath converts an echo route (/exports/:id) to its OpenAPI path (/exports/{id}).
+func OpenAPIPath(route string) string {
+	return routeParam.ReplaceAllString(route, "{$1}")
+}
+
+// Anonymize returns the JSON body with sensitive values replaced, or nil if the body
+// isn't JSON.
+func Anonymize(body []byte) json.RawMessage {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+
+	anonymized, err := json.Marshal(anonymize("", value))
+	if err != nil {
+		return nil
+	}
+
+	return anonymized
+}
+
+func anonymize(field string, value any) any {
+	if field != "" && isSensitive(field) {
+		switch value.(type) {
+		case nil, bool, float64:
+			return value
+		case string:
+			return RedactedValue
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, nested := range v {
+			v[key] = anonymize(key, nested)
+		}
+		return v
+	case []any:
+		for i, nested := range v {
+			v[i] = anonymize(field, nested)
+		}
+		return v
+	case string:
+		v = emailPattern.ReplaceAllString(v, ExampleEmail)
+		return uuidPattern.ReplaceAllString(v, ExampleUUID)
+	default:
+		return v
+	}
+}
+
+func isSensitive(field string) bool {
+	field = strings.ToLower(field)
+	for _, sensitive := range sensitiveFields {
+		if field == sensitive || strings.HasPrefix(field, sensitive+"_") || strings.HasSuffix(field, "_"+sensitive) || strings.Contains(field, "_"+sensitive+"_") {
+			return true
+		}
+	}
+	return strings.Contains(field, "password") || strings.Contains(field, "secret") || strings.Contains(field, "token")
+}
+
+// Store keeps the latest example per route and status code in dir, one file per route.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Save stores ex, replacing the example of the same route and status.
+func (s *Store) Save(ex Example) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := filepath.Join(s.dir, fileName(ex.Method, ex.Path))
+
+	route := make(map[string]Example)
+	if data, err := os.ReadFile(path); err == nil {
+		// A corrupt file is replaced
+		_ = json.Unmarshal(data, &route)
+	}
+	route[strconv.Itoa(ex.Status)] = ex
+
+	data, err := json.MarshalIndent(route, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create examples directory: %w", err)
+	}
+
+	// Written next to the file and renamed, so a concurrent Load never reads half a file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write example: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load returns every stored example, sorted by method, path and status. A missing
+// directory holds none.
+func (s *Store) Load() ([]Example, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var examples []Example
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		route := make(map[string]Example)
+		if err := json.Unmarshal(data, &route); err != nil {
+			return nil, fmt.Errorf("invalid example file %s: %w", entry.Name(), err)
+		}
+		for _, ex := range route {
+			examples = append(examples, ex)
+		}
+	}
+
+	sort.Slice(examples, func(i, j int) bool {
+		a, b := examples[i], examples[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Status < b.Status
+	})
+
+	return examples, nil
+}
+
+// fileName is the file of a route, e.g. "post_api_v1_me_devices.json".
+func fileName(method, path string) string {
+	name := strings.ToLower(method) + "_" + strings.Trim(path, "/")
+	name = strings.NewReplacer("/", "_", "{", "", "}", "").Replace(name)
+	return name + ".json"
+}
+
+// Embed adds examples to the operations of an OpenAPI document: captured requests to
+// the JSON request body, captured responses to the JSON content of their status. Operations
+// and statuses the document doesn't describe are left alone, examples never add to the API.
+func Embed(document map[string]any, examples []Example) {
+	paths, _ := document["paths"].(map[string]any)
+
+	for _, ex := range examples {
+		pathItem, _ := paths[ex.Path].(map[string]any)
+		operation, _ := pathItem[strings.ToLower(ex.Method)].(map[string]any)
+		if operation == nil {
+			continue
+		}
+
+		if len(ex.Request) > 0 {
+			if requestBody, ok := operation["requestBody"].(map[string]any); ok {
+				setExample(requestBody, ex.Request)
+			}
+		}
+
+		if len(ex.Response) > 0 {
+			responses, _ := operation["responses"].(map[string]any)
+			if response, ok := responses[strconv.Itoa(ex.Status)].(map[string]any); ok {
+				setExample(response, ex.Response)
+			}
+		}
+	}
+}
+
+// setExample sets the captured example of the JSON content of a request body or response.
+func setExample(object map[string]any, value json.RawMessage) {
+	content, _ := object["content"].(map[string]any)
+	media, ok := content["application/json"].(map[string]any)
+	if !ok {
+		return
+	}
+
+	named, _ := media["examples"].(map[string]any)
+	if named == nil {
+		named = make(map[string]any)
+		media["examples"] = named
+	}
+	named["captured"] = map[string]any{
+		"summary": "Captured in development",
+		"value":   value,
+	}
+}
@@ -0,0 +1,209 @@
+// Package export streams query results as CSV or XLSX files.
+//
+// Rows are read from the database one at a time and written straight to the output,
+// so memory stays flat however large the export is. The output applies backpressure:
+// the next row is only read once the previous one was written, so a slow client slows
+// down the query instead of rows piling up in memory. Exports too large for a request
+// are built by a job instead, see service.ExportService.
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/text/language"
+)
+
+// Format is the file format of an export.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat returns the format named s, CSV if s is empty.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q: must be one of csv | xlsx", s)
+	}
+}
+
+// ContentType is the MIME type files of the format are served with.
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Extension is the file name extension of the format, without the dot.
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Column is one exportable column of a dataset.
+type Column struct {
+	// Key is the column name in the query result, and what clients select columns by.
+	Key string
+	// Header is the default (English) header of the column.
+	Header string
+	// Headers holds the header translated per language tag, e.g. "de" or "pt-BR".
+	Headers map[string]string
+}
+
+// Dataset is a set of rows that can be exported, e.g. the audit log.
+type Dataset struct {
+	Name    string
+	Columns []Column
+}
+
+// ErrUnknownColumn is returned when selecting a column the dataset doesn't have.
+var ErrUnknownColumn = errors.New("unknown export column")
+
+// Select returns the columns named by keys in the order given, or every column if keys is empty.
+func (d Dataset) Select(keys []string) ([]Column, error) {
+	if len(keys) == 0 {
+		return d.Columns, nil
+	}
+
+	selected := make([]Column, 0, len(keys))
+	for _, key := range keys {
+		column, ok := d.column(key)
+		if !ok {
+			return nil, fmt.Errorf("%w %q of dataset %s", ErrUnknownColumn, key, d.Name)
+		}
+		selected = append(selected, column)
+	}
+
+	return selected, nil
+}
+
+func (d Dataset) column(key string) (Column, bool) {
+	for _, column := range d.Columns {
+		if column.Key == key {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+// Headers returns the headers of columns in the language best matching acceptLanguage,
+// an Accept-Language header value. Columns without a matching translation keep their default header.
+func Headers(columns []Column, acceptLanguage string) []string {
+	preferred, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.Header
+		if len(column.Headers) == 0 || len(preferred) == 0 {
+			continue
+		}
+
+		tags := []language.Tag{language.English}
+		names := []string{column.Header}
+		for tag, name := range column.Headers {
+			parsed, err := language.Parse(tag)
+			if err != nil {
+				continue
+			}
+			tags = append(tags, parsed)
+			names = append(names, name)
+		}
+
+		if _, index, confidence := language.NewMatcher(tags).Match(preferred...); confidence != language.No {
+			headers[i] = names[index]
+		}
+	}
+
+	return headers
+}
+
+// Options tune how an export is streamed.
+type Options struct {
+	// FlushEvery is how many rows are buffered before they are flushed to the client.
+	FlushEvery int
+}
+
+// Stream writes the selected columns of rows to w and returns how many rows were written.
+// It closes rows. If w can be flushed (e.g. an http.ResponseWriter) it is flushed every
+// FlushEvery rows, so the client receives the file while it is being generated.
+func Stream(ctx context.Context, w io.Writer, format Format, columns []Column, headers []string, rows pgx.Rows, opts Options) (int64, error) {
+	defer rows.Close()
+
+	indexes, err := columnIndexes(rows, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	out := NewWriter(format, w)
+	if err := out.WriteHeader(headers); err != nil {
+		return 0, fmt.Errorf("failed to write export header: %w", err)
+	}
+
+	flushEvery := max(opts.FlushEvery, 1)
+	record := make([]any, len(indexes))
+
+	var written int64
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+
+		values, err := rows.Values()
+		if err != nil {
+			return written, fmt.Errorf("failed to read export row: %w", err)
+		}
+
+		for i, index := range indexes {
+			record[i] = values[index]
+		}
+		if err := out.WriteRow(record); err != nil {
+			return written, fmt.Errorf("failed to write export row: %w", err)
+		}
+
+		written++
+		if written%int64(flushEvery) == 0 {
+			if err := out.Flush(); err != nil {
+				return written, fmt.Errorf("failed to flush export: %w", err)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return written, fmt.Errorf("failed to read export rows: %w", err)
+	}
+
+	if err := out.Close(); err != nil {
+		return written, fmt.Errorf("failed to finish export: %w", err)
+	}
+
+	return written, nil
+}
+
+// columnIndexes maps every column to its position in the query result.
+func columnIndexes(rows pgx.Rows, columns []Column) ([]int, error) {
+	positions := make(map[string]int)
+	for i, field := range rows.FieldDescriptions() {
+		positions[field.Name] = i
+	}
+
+	indexes := make([]int, len(columns))
+	for i, column := range columns {
+		index, ok := positions[column.Key]
+		if !ok {
+			return nil, fmt.Errorf("%w %q: not in the query result", ErrUnknownColumn, column.Key)
+		}
+		indexes[i] = index
+	}
+
+	return indexes, nil
+}
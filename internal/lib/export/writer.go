@@ -0,0 +1,263 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Writer writes an export file row by row.
+type Writer interface {
+	WriteHeader(headers []string) error
+	WriteRow(values []any) error
+	// Flush sends everything buffered so far to the underlying writer, and flushes
+	// that too if it can be flushed.
+	Flush() error
+	// Close finishes the file. It doesn't close the underlying writer.
+	Close() error
+}
+
+// flusher is implemented by writers that buffer, e.g. http.ResponseWriter.
+type flusher interface {
+	Flush()
+}
+
+// NewWriter returns a writer producing a file of the given format on w.
+func NewWriter(format Format, w io.Writer) Writer {
+	if format == FormatXLSX {
+		return newXLSXWriter(w)
+	}
+	return newCSVWriter(w)
+}
+
+func flushUnderlying(w io.Writer) {
+	if f, ok := w.(flusher); ok {
+		f.Flush()
+	}
+}
+
+type csvWriter struct {
+	dst    io.Writer
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{dst: w, w: csv.NewWriter(w)}
+}
+
+func (cw *csvWriter) WriteHeader(headers []string) error {
+	return cw.w.Write(headers)
+}
+
+func (cw *csvWriter) WriteRow(values []any) error {
+	cw.record = cw.record[:0]
+	for _, value := range values {
+		text, numeric := formatValue(value)
+		if !numeric {
+			text = escapeFormula(text)
+		}
+		cw.record = append(cw.record, text)
+	}
+
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	if err := cw.w.Error(); err != nil {
+		return err
+	}
+
+	flushUnderlying(cw.dst)
+	return nil
+}
+
+func (cw *csvWriter) Close() error {
+	return cw.Flush()
+}
+
+// escapeFormula keeps spreadsheet applications from evaluating user-controlled text as
+// a formula (CSV injection) by prefixing it with a quote.
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// XLSX parts that don't depend on the data. The sheet is written with inline strings,
+// so no shared strings table has to be kept in memory.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxWriter streams a single-sheet workbook. The static parts are written up front,
+// then the sheet is written as the last zip entry, row by row.
+type xlsxWriter struct {
+	dst     io.Writer
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	row     bytes.Buffer
+	err     error
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	xw := &xlsxWriter{dst: w, archive: zip.NewWriter(w)}
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, part := range parts {
+		if xw.err = xw.writePart(part.name, part.content); xw.err != nil {
+			return xw
+		}
+	}
+
+	sheet, err := xw.archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		xw.err = err
+		return xw
+	}
+	xw.sheet = bufio.NewWriter(sheet)
+	_, xw.err = xw.sheet.WriteString(xlsxSheetStart)
+
+	return xw
+}
+
+func (xw *xlsxWriter) writePart(name, content string) error {
+	part, err := xw.archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, content)
+	return err
+}
+
+func (xw *xlsxWriter) WriteHeader(headers []string) error {
+	values := make([]any, len(headers))
+	for i, header := range headers {
+		values[i] = header
+	}
+	return xw.WriteRow(values)
+}
+
+func (xw *xlsxWriter) WriteRow(values []any) error {
+	if xw.err != nil {
+		return xw.err
+	}
+
+	// Rows are assembled in a buffer first, the sheet writer is only written (and checked) once per row
+	xw.row.Reset()
+	xw.row.WriteString("<row>")
+	for _, value := range values {
+		text, numeric := formatValue(value)
+		if numeric {
+			xw.row.WriteString("<c><v>" + text + "</v></c>")
+			continue
+		}
+
+		xw.row.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(&xw.row, []byte(text)); err != nil {
+			return err
+		}
+		xw.row.WriteString("</t></is></c>")
+	}
+	xw.row.WriteString("</row>")
+
+	_, xw.err = xw.sheet.Write(xw.row.Bytes())
+	return xw.err
+}
+
+func (xw *xlsxWriter) Flush() error {
+	if xw.err != nil {
+		return xw.err
+	}
+	if xw.err = xw.sheet.Flush(); xw.err != nil {
+		return xw.err
+	}
+	if xw.err = xw.archive.Flush(); xw.err != nil {
+		return xw.err
+	}
+
+	flushUnderlying(xw.dst)
+	return nil
+}
+
+func (xw *xlsxWriter) Close() error {
+	if xw.err != nil {
+		return xw.err
+	}
+	if _, xw.err = xw.sheet.WriteString(xlsxSheetEnd); xw.err != nil {
+		return xw.err
+	}
+	if xw.err = xw.sheet.Flush(); xw.err != nil {
+		return xw.err
+	}
+	if xw.err = xw.archive.Close(); xw.err != nil {
+		return xw.err
+	}
+
+	flushUnderlying(xw.dst)
+	return nil
+}
+
+// formatValue renders a value read from pgx as cell text, and reports whether it is a number.
+func formatValue(value any) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, false
+	case []byte:
+		return string(v), false
+	case bool:
+		return strconv.FormatBool(v), false
+	case int16, int32, int64, int:
+		return fmt.Sprint(v), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case float64:
+		// NaN and infinities have no numeric cell representation
+		return strconv.FormatFloat(v, 'f', -1, 64), !math.IsNaN(v) && !math.IsInf(v, 0)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339), false
+	case [16]byte:
+		// pgx returns UUID columns as raw bytes
+		return uuid.UUID(v).String(), false
+	case map[string]any, []any:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v), false
+		}
+		return string(encoded), false
+	case fmt.Stringer:
+		return v.String(), false
+	default:
+		return fmt.Sprint(v), false
+	}
+}
@@ -0,0 +1,256 @@
+// Package importer reads CSV uploads and validates them against a typed schema.
+//
+// Every row is checked and every problem is collected with its line number, so a
+// single upload reports all errors at once instead of failing on the first one. Valid
+// rows are handed on one at a time as their normalized text, to be staged and
+// committed later, see service.ImportService.
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the type a column's values must have.
+type FieldType string
+
+const (
+	TypeString FieldType = "string"
+	TypeEmail  FieldType = "email"
+	TypeInt    FieldType = "int"
+	TypeBool   FieldType = "bool"
+	TypeTime   FieldType = "time"
+	TypeEnum   FieldType = "enum"
+)
+
+// Field is one column of an import.
+type Field struct {
+	Name     string
+	Type     FieldType
+	Required bool
+	// Values are the allowed values of an enum field, matched case-insensitively.
+	Values []string
+	// MaxLength limits string values, zero means unlimited.
+	MaxLength int
+}
+
+// Schema describes the columns of an import. Columns are matched to fields by their
+// header, case-insensitively and in any order.
+type Schema struct {
+	Name   string
+	Fields []Field
+}
+
+// RowError is a problem with one row of an upload.
+type RowError struct {
+	// Line is the line of the row in the file, the header is line 1.
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report summarizes the validation of an upload.
+type Report struct {
+	TotalRows  int64 `json:"total_rows"`
+	ValidRows  int64 `json:"valid_rows"`
+	ErrorCount int64 `json:"error_count"`
+	// Errors holds the first errors found, at most the limit passed to Read.
+	Errors []RowError `json:"errors"`
+}
+
+// ErrInvalidFile is returned when the upload can't be read as a CSV matching the schema at all.
+var ErrInvalidFile = errors.New("invalid import file")
+
+// Read validates every row of the CSV read from r against schema and calls fn with each
+// valid row. At most maxErrors errors are kept in the report, all are counted. An error
+// from fn aborts the read.
+func Read(ctx context.Context, r io.Reader, schema Schema, maxErrors int, fn func(line int, row Row) error) (*Report, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: the file is empty", ErrInvalidFile)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	columns, err := schema.columns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Errors: []RowError{}}
+	addError := func(line int, field, message string) {
+		report.ErrorCount++
+		if len(report.Errors) < maxErrors {
+			report.Errors = append(report.Errors, RowError{Line: line, Field: field, Message: message})
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// Malformed quoting can't be recovered from, the rest of the file would be misread
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		report.TotalRows++
+
+		if len(record) != len(header) {
+			addError(line, "", fmt.Sprintf("has %d columns, the header has %d", len(record), len(header)))
+			continue
+		}
+
+		row := make(Row, len(schema.Fields))
+		valid := true
+		for i, field := range columns {
+			value, err := field.normalize(strings.TrimSpace(record[i]))
+			if err != nil {
+				addError(line, field.Name, err.Error())
+				valid = false
+				continue
+			}
+			if value != "" {
+				row[field.Name] = value
+			}
+		}
+		if !valid {
+			continue
+		}
+
+		report.ValidRows++
+		if err := fn(line, row); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// columns maps every header column to its field. Columns the schema doesn't know are
+// rejected, so a typo in a header doesn't silently drop a column.
+func (s Schema) columns(header []string) ([]*Field, error) {
+	columns := make([]*Field, len(header))
+	seen := make(map[string]bool, len(header))
+
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet applications often start UTF-8 files with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		index := slices.IndexFunc(s.Fields, func(f Field) bool { return strings.EqualFold(f.Name, name) })
+		if index < 0 {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidFile, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidFile, name)
+		}
+
+		seen[name] = true
+		columns[i] = &s.Fields[index]
+	}
+
+	for _, field := range s.Fields {
+		if field.Required && !seen[strings.ToLower(field.Name)] {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidFile, field.Name)
+		}
+	}
+
+	return columns, nil
+}
+
+// normalize validates value and returns its canonical text, e.g. a lowercased email.
+func (f *Field) normalize(value string) (string, error) {
+	if value == "" {
+		if f.Required {
+			return "", errors.New("is required")
+		}
+		return "", nil
+	}
+
+	if f.MaxLength > 0 && len([]rune(value)) > f.MaxLength {
+		return "", fmt.Errorf("must be at most %d characters", f.MaxLength)
+	}
+
+	switch f.Type {
+	case TypeEmail:
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value {
+			return "", errors.New("must be a valid email address")
+		}
+		return strings.ToLower(value), nil
+	case TypeInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", errors.New("must be an integer")
+		}
+		return strconv.FormatInt(n, 10), nil
+	case TypeBool:
+		switch strings.ToLower(value) {
+		case "true", "yes", "1":
+			return "true", nil
+		case "false", "no", "0":
+			return "false", nil
+		}
+		return "", errors.New("must be true or false")
+	case TypeTime:
+		for _, layout := range []string{time.RFC3339, time.DateOnly} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t.UTC().Format(time.RFC3339), nil
+			}
+		}
+		return "", errors.New("must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	case TypeEnum:
+		for _, allowed := range f.Values {
+			if strings.EqualFold(allowed, value) {
+				return allowed, nil
+			}
+		}
+		return "", fmt.Errorf("must be one of %s", strings.Join(f.Values, " | "))
+	default:
+		return value, nil
+	}
+}
+
+// Row is a validated row by field name, holding the normalized text of every non-empty
+// value. The typed accessors return the zero value for missing fields.
+type Row map[string]string
+
+func (r Row) String(name string) string {
+	return r[name]
+}
+
+func (r Row) Int(name string) int64 {
+	n, _ := strconv.ParseInt(r[name], 10, 64)
+	return n
+}
+
+func (r Row) Bool(name string) bool {
+	return r[name] == "true"
+}
+
+func (r Row) Time(name string) time.Time {
+	t, _ := time.Parse(time.RFC3339, r[name])
+	return t
+}
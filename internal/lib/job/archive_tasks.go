@@ -0,0 +1,15 @@
+package job
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TaskAuditArchive = "archive:audit_logs"
+
+// NewAuditArchiveTask creates the periodic task exporting old audit logs to object storage.
+// It is unique for an hour, so the schedulers of several instances enqueue it only once.
+func NewAuditArchiveTask() *Task {
+	return NewTask(TaskAuditArchive, nil, asynq.Timeout(time.Hour), asynq.Queue(QueueFor(TaskAuditArchive)), asynq.Unique(time.Hour))
+}
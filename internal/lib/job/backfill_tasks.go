@@ -0,0 +1,15 @@
+package job
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TaskBackfill = "maintenance:backfill"
+
+// NewBackfillTask creates the periodic task resuming the backfills that haven't completed yet.
+// It is unique for an hour, so the schedulers of several instances enqueue it only once.
+func NewBackfillTask(timeout time.Duration) *Task {
+	return NewTask(TaskBackfill, nil, asynq.Timeout(timeout), asynq.Queue(QueueFor(TaskBackfill)), asynq.Unique(time.Hour))
+}
@@ -0,0 +1,298 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/clock"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/hibiken/asynq"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/rs/zerolog"
+)
+
+// OverflowPolicy decides what Enqueue does with a task whose queue is at its ceiling.
+type OverflowPolicy string
+
+const (
+	// OverflowReject fails the enqueue with ErrQueueFull, the caller decides.
+	OverflowReject OverflowPolicy = "reject"
+	// OverflowDrop discards the task, for work that is fine to lose (e.g. digests).
+	OverflowDrop OverflowPolicy = "drop"
+	// OverflowShed moves the task to the low queue, unless that one is full as well.
+	OverflowShed OverflowPolicy = "shed"
+	// OverflowOutbox persists the task, the outbox relay enqueues it once there is room.
+	OverflowOutbox OverflowPolicy = "outbox"
+)
+
+// shedQueue is where OverflowShed moves tasks to.
+const shedQueue = "low"
+
+var ErrQueueFull = errors.New("job queue is full")
+
+// taskQueues is the queue of every built-in task type, task constructors use it
+// so the backpressure check looks at the queue the task actually goes to.
+var taskQueues = map[string]string{
+	TaskWelcomeEmail:         "default",
+	TaskDataExport:           "low",
+	TaskAccountDeletion:      "default",
+	TaskDigestDispatch:       "low",
+	TaskDigestEmail:          "low",
+	TaskAuditArchive:         "low",
+	TaskPartitionMaintenance: "low",
+	TaskOutboxRelay:          "low",
+	TaskBatchEmail:           "low",
+	TaskDatabaseBackup:       "low",
+	TaskBackfill:             "low",
+	TaskHealthProbe:          "default",
+	TaskExport:               "low",
+	TaskImportCommit:         "low",
+	TaskNotification:         "default",
+	TaskScheduledEmail:       "default",
+	TaskVerificationEmail:    "default",
+	TaskUsageRollup:          "low",
+	TaskDeprecationDispatch:  "low",
+	TaskDeprecationNotice:    "low",
+}
+
+// QueueFor returns the queue tasks of taskType are enqueued on.
+func QueueFor(taskType string) string {
+	if queue, ok := taskQueues[taskType]; ok {
+		return queue
+	}
+	return "default"
+}
+
+// Outbox persists tasks that didn't fit in their queue. It is implemented by the
+// repository layer and set with SetOutbox. options are the task's options encoded by
+// the job service, RestoreOptions decodes them when the task is relayed.
+type Outbox interface {
+	SaveTask(ctx context.Context, taskType string, payload []byte, queue string, options []byte) error
+}
+
+// outboxOptions are the options of an outboxed task, asynq's options can't be encoded
+// themselves. The queue is kept by the outbox and the retry policy applied again on
+// relay, other options (e.g. asynq.Group) are lost.
+type outboxOptions struct {
+	MaxRetry  *int          `json:"max_retry,omitempty"`
+	Timeout   time.Duration `json:"timeout,omitempty"`
+	Deadline  time.Time     `json:"deadline,omitzero"`
+	Unique    time.Duration `json:"unique,omitempty"`
+	ProcessAt time.Time     `json:"process_at,omitzero"`
+	TaskID    string        `json:"task_id,omitempty"`
+	Retention time.Duration `json:"retention,omitempty"`
+}
+
+// encodeOutboxOptions encodes opts for the outbox. ProcessIn is stored as the time it
+// resolves to, so a delayed task isn't delayed again by its time in the outbox.
+func encodeOutboxOptions(opts []asynq.Option, now time.Time) ([]byte, error) {
+	var o outboxOptions
+	for _, opt := range opts {
+		switch opt.Type() {
+		case asynq.MaxRetryOpt:
+			maxRetry, _ := opt.Value().(int)
+			o.MaxRetry = &maxRetry
+		case asynq.TimeoutOpt:
+			o.Timeout, _ = opt.Value().(time.Duration)
+		case asynq.DeadlineOpt:
+			o.Deadline, _ = opt.Value().(time.Time)
+		case asynq.UniqueOpt:
+			o.Unique, _ = opt.Value().(time.Duration)
+		case asynq.ProcessAtOpt:
+			o.ProcessAt, _ = opt.Value().(time.Time)
+		case asynq.ProcessInOpt:
+			delay, _ := opt.Value().(time.Duration)
+			o.ProcessAt = now.Add(delay)
+		case asynq.TaskIDOpt:
+			o.TaskID, _ = opt.Value().(string)
+		case asynq.RetentionOpt:
+			o.Retention, _ = opt.Value().(time.Duration)
+		}
+	}
+
+	return json.Marshal(o)
+}
+
+// RestoreOptions decodes the options an outboxed task was saved with.
+func RestoreOptions(encoded []byte) ([]asynq.Option, error) {
+	var o outboxOptions
+	if len(encoded) > 0 {
+		if err := json.Unmarshal(encoded, &o); err != nil {
+			return nil, fmt.Errorf("invalid outbox task options: %w", err)
+		}
+	}
+
+	var opts []asynq.Option
+	if o.MaxRetry != nil {
+		opts = append(opts, asynq.MaxRetry(*o.MaxRetry))
+	}
+	if o.Timeout > 0 {
+		opts = append(opts, asynq.Timeout(o.Timeout))
+	}
+	if !o.Deadline.IsZero() {
+		opts = append(opts, asynq.Deadline(o.Deadline))
+	}
+	if o.Unique > 0 {
+		opts = append(opts, asynq.Unique(o.Unique))
+	}
+	if !o.ProcessAt.IsZero() {
+		opts = append(opts, asynq.ProcessAt(o.ProcessAt))
+	}
+	if o.TaskID != "" {
+		opts = append(opts, asynq.TaskID(o.TaskID))
+	}
+	if o.Retention > 0 {
+		opts = append(opts, asynq.Retention(o.Retention))
+	}
+
+	return opts, nil
+}
+
+// queueSizer returns the backlog of a queue on the job backend.
+type queueSizer func(queue string) (int, error)
+
+// redisQueueSize reads backlogs from Redis: pending, scheduled and retrying tasks.
+func redisQueueSize(inspector *asynq.Inspector) queueSizer {
+	return func(queue string) (int, error) {
+		info, err := inspector.GetQueueInfo(queue)
+		if err != nil {
+			return 0, err
+		}
+		return info.Pending + info.Scheduled + info.Retry, nil
+	}
+}
+
+// backpressure checks queue backlogs against their ceilings. Backlog sizes are cached
+// for a short while so enqueueing doesn't cost an extra round trip every time.
+type backpressure struct {
+	sizeOf      queueSizer
+	logger      *zerolog.Logger
+	newRelicApp *newrelic.Application
+	ceilings    map[string]int
+	policies    map[string]OverflowPolicy
+	ttl         time.Duration
+	clock       clock.Clock
+
+	mu     sync.Mutex
+	sizes  map[string]queueSize
+	outbox Outbox
+}
+
+type queueSize struct {
+	size      int
+	checkedAt time.Time
+}
+
+func newBackpressure(sizeOf queueSizer, logger *zerolog.Logger, newRelicApp *newrelic.Application, cfg config.BackpressureConfig, clk clock.Clock) *backpressure {
+	policies := make(map[string]OverflowPolicy, len(cfg.OverflowPolicies))
+	for taskType, policy := range cfg.OverflowPolicies {
+		policies[taskType] = OverflowPolicy(policy)
+	}
+
+	return &backpressure{
+		sizeOf:      sizeOf,
+		logger:      logger,
+		newRelicApp: newRelicApp,
+		ceilings:    cfg.Ceilings,
+		policies:    policies,
+		ttl:         cfg.SizeCacheTTL,
+		clock:       clk,
+		sizes:       make(map[string]queueSize),
+	}
+}
+
+func (bp *backpressure) policyFor(taskType string) OverflowPolicy {
+	if policy, ok := bp.policies[taskType]; ok {
+		return policy
+	}
+	return OverflowReject
+}
+
+// saturated reports whether queue's backlog reached its ceiling.
+func (bp *backpressure) saturated(queue string) bool {
+	ceiling, ok := bp.ceilings[queue]
+	if !ok {
+		return false
+	}
+
+	bp.mu.Lock()
+	cached, ok := bp.sizes[queue]
+	bp.mu.Unlock()
+
+	if !ok || bp.clock.Since(cached.checkedAt) > bp.ttl {
+		// A queue only exists in Redis once a task was enqueued on it, so an error
+		// usually means an empty queue. Real backend failures fail the enqueue anyway.
+		size, err := bp.sizeOf(queue)
+		if err != nil {
+			bp.logger.Debug().Err(err).Str("queue", queue).Msg("failed to read queue size, assuming empty")
+			size = 0
+		}
+
+		cached = queueSize{size: size, checkedAt: bp.clock.Now()}
+		bp.mu.Lock()
+		bp.sizes[queue] = cached
+		bp.mu.Unlock()
+
+		if cached.size >= ceiling {
+			bp.logger.Warn().Str("queue", queue).Int("size", cached.size).Int("ceiling", ceiling).Msg("job queue saturated")
+
+			observe.Event("JobQueueSaturated", map[string]any{
+				"queue":   queue,
+				"size":    cached.size,
+				"ceiling": ceiling,
+			})
+		}
+	}
+
+	return cached.size >= ceiling
+}
+
+// added counts a task enqueued on queue into the cached backlog until the next refresh.
+func (bp *backpressure) added(queue string) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	if cached, ok := bp.sizes[queue]; ok {
+		cached.size++
+		bp.sizes[queue] = cached
+	}
+}
+
+func (bp *backpressure) setOutbox(outbox Outbox) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	bp.outbox = outbox
+}
+
+func (bp *backpressure) getOutbox() Outbox {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	return bp.outbox
+}
+
+func (bp *backpressure) recordOverflow(taskType, queue string, policy OverflowPolicy) {
+	bp.logger.Warn().Str("type", taskType).Str("queue", queue).Str("policy", string(policy)).Msg("job queue full, applying overflow policy")
+
+	if bp.newRelicApp != nil {
+		bp.newRelicApp.RecordCustomMetric(fmt.Sprintf("Custom/Jobs/Overflow/%s/%s", queue, policy), 1)
+	}
+}
+
+// queueOf returns the queue task goes to: the one passed by the caller, or its type's queue.
+func queueOf(task *Task, opts []asynq.Option) string {
+	queue := QueueFor(task.Type())
+	for _, opt := range slices.Concat(task.Options, opts) {
+		if opt.Type() == asynq.QueueOpt {
+			if name, ok := opt.Value().(string); ok {
+				queue = name
+			}
+		}
+	}
+	return queue
+}
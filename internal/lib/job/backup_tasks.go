@@ -0,0 +1,15 @@
+package job
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TaskDatabaseBackup = "maintenance:database_backup"
+
+// NewDatabaseBackupTask creates the periodic task dumping the database to object storage.
+// It is unique for an hour, so the schedulers of several instances enqueue it only once.
+func NewDatabaseBackupTask(timeout time.Duration) *Task {
+	return NewTask(TaskDatabaseBackup, nil, asynq.Timeout(timeout), asynq.Queue(QueueFor(TaskDatabaseBackup)), asynq.Unique(time.Hour))
+}
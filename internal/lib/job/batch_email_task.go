@@ -0,0 +1,75 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/email"
+	"github.com/hibiken/asynq"
+)
+
+const TaskBatchEmail = "email:batch"
+
+// MaxBatchEmailRecipients bounds the recipients of one batch task, larger sends are split
+// into several tasks so a payload stays small enough for Redis.
+const MaxBatchEmailRecipients = 10000
+
+type BatchEmailTaskPayload struct {
+	Template email.Template `json:"template"`
+	Subject  string         `json:"subject"`
+	// Data is rendered into the template for every recipient.
+	Data       map[string]any        `json:"data,omitempty"`
+	Recipients []BatchEmailRecipient `json:"recipients"`
+}
+
+// BatchEmailRecipient is one recipient of a batch email. Data is merged over the shared
+// data, keys set here win; a non-empty Subject replaces the shared one.
+type BatchEmailRecipient struct {
+	To      string         `json:"to"`
+	Subject string         `json:"subject,omitempty"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+// BatchEmailResult summarizes a batch email task. It is written as the task's result,
+// after every chunk while the batch is sent and once more when it is done.
+type BatchEmailResult struct {
+	Total      int                 `json:"total"`
+	Sent       int                 `json:"sent"`
+	Suppressed int                 `json:"suppressed"`
+	Failed     int                 `json:"failed"`
+	Failures   []BatchEmailFailure `json:"failures,omitempty"`
+	Done       bool                `json:"done"`
+}
+
+type BatchEmailFailure struct {
+	To    string `json:"to"`
+	Error string `json:"error"`
+}
+
+// NewBatchEmailTask creates a task sending template to every recipient, in chunks.
+// Failures are isolated per recipient and reported in the task result, they don't fail
+// the task. A task that fails as a whole resumes after its last completed chunk.
+func NewBatchEmailTask(template email.Template, subject string, data map[string]any, recipients []BatchEmailRecipient) (*Task, error) {
+	if !slices.Contains(email.Templates, template) {
+		return nil, fmt.Errorf("unknown email template %q", template)
+	}
+
+	if len(recipients) == 0 || len(recipients) > MaxBatchEmailRecipients {
+		return nil, fmt.Errorf("batch email needs between 1 and %d recipients, got %d", MaxBatchEmailRecipients, len(recipients))
+	}
+
+	jsonPayload, err := json.Marshal(BatchEmailTaskPayload{
+		Template:   template,
+		Subject:    subject,
+		Data:       data,
+		Recipients: recipients,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTask(TaskBatchEmail, jsonPayload, asynq.Timeout(2*time.Hour), asynq.Queue(QueueFor(TaskBatchEmail))), nil
+}
@@ -0,0 +1,49 @@
+package job
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	TaskDataExport      = "compliance:data_export"
+	TaskAccountDeletion = "compliance:account_deletion"
+)
+
+type DataExportTaskPayload struct {
+	ExportID uuid.UUID `json:"export_id"` // data export request to process
+}
+
+type AccountDeletionTaskPayload struct {
+	DeletionID uuid.UUID `json:"deletion_id"` // account deletion request to execute
+}
+
+// NewDataExportTask creates a task that builds the export archive for a data export request
+func NewDataExportTask(exportID uuid.UUID) (*Task, error) {
+	jsonPayload, err := json.Marshal(DataExportTaskPayload{
+		ExportID: exportID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTask(TaskDataExport, jsonPayload, asynq.Timeout(10*time.Minute), asynq.Queue(QueueFor(TaskDataExport))), nil
+}
+
+// NewAccountDeletionTask creates a task that deletes an account once its grace period is over.
+// The handler re-checks the request status, so cancelled deletions are skipped.
+func NewAccountDeletionTask(deletionID uuid.UUID, processAt time.Time) (*Task, error) {
+	jsonPayload, err := json.Marshal(AccountDeletionTaskPayload{
+		DeletionID: deletionID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTask(TaskAccountDeletion, jsonPayload, asynq.ProcessAt(processAt), asynq.Timeout(10*time.Minute), asynq.Queue(QueueFor(TaskAccountDeletion))), nil
+}
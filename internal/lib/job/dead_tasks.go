@@ -0,0 +1,229 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/clock"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/email"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog"
+)
+
+// deadTaskAlertTimeout bounds the on-call email and webhook of one dead task.
+const deadTaskAlertTimeout = 10 * time.Second
+
+// DeadTask is a task that ran out of retries, or whose handler returned asynq.SkipRetry,
+// and was archived by its backend.
+type DeadTask struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Queue string `json:"queue"`
+	// Retried is the number of retries the task had, MaxRetry the number it was allowed.
+	Retried  int    `json:"retried"`
+	MaxRetry int    `json:"max_retry"`
+	Error    string `json:"error"`
+	// Payload summarizes the payload: its size and top-level keys, never its values.
+	Payload string    `json:"payload"`
+	DiedAt  time.Time `json:"died_at"`
+}
+
+// deadTasks raises the alerts of dead tasks: an error log and a JobRetriesExhausted
+// event for each, and the configured on-call email and webhook at most once per task
+// type and cooldown.
+type deadTasks struct {
+	cfg        config.DeadTasksConfig
+	logger     *zerolog.Logger
+	clock      clock.Clock
+	httpClient *http.Client
+
+	mu         sync.Mutex
+	lastAlerts map[string]time.Time
+}
+
+func newDeadTasks(cfg config.DeadTasksConfig, logger *zerolog.Logger, clk clock.Clock) *deadTasks {
+	return &deadTasks{
+		cfg:        cfg,
+		logger:     logger,
+		clock:      clk,
+		httpClient: &http.Client{Timeout: deadTaskAlertTimeout},
+		lastAlerts: make(map[string]time.Time),
+	}
+}
+
+// report raises the alerts of a dead task. The on-call alerts are sent in the
+// background, the worker that archived the task doesn't wait for them.
+func (d *deadTasks) report(task DeadTask, payload []byte) {
+	task.Payload = summarizePayload(payload, d.cfg.PayloadKeys)
+	task.DiedAt = d.clock.Now().UTC()
+
+	d.logger.Error().
+		Str("type", task.Type).
+		Str("task_id", task.ID).
+		Str("queue", task.Queue).
+		Int("retried", task.Retried).
+		Int("max_retry", task.MaxRetry).
+		Str("payload", task.Payload).
+		Str("error", task.Error).
+		Msg("task ran out of retries and was archived")
+
+	observe.Event("JobRetriesExhausted", map[string]any{
+		"type":      task.Type,
+		"task_id":   task.ID,
+		"queue":     task.Queue,
+		"retried":   task.Retried,
+		"max_retry": task.MaxRetry,
+		"error":     task.Error,
+	})
+
+	if len(d.cfg.Emails) == 0 && d.cfg.WebhookURL == "" {
+		return
+	}
+	if !d.claimAlert(task.Type) {
+		return
+	}
+
+	go d.alert(task)
+}
+
+// claimAlert reports whether on-call may be alerted about a dead task of taskType,
+// i.e. the last alert of the type is older than the cooldown.
+func (d *deadTasks) claimAlert(taskType string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.clock.Now()
+	if last, ok := d.lastAlerts[taskType]; ok && now.Sub(last) < d.cfg.Cooldown {
+		return false
+	}
+	d.lastAlerts[taskType] = now
+	return true
+}
+
+func (d *deadTasks) alert(task DeadTask) {
+	logger := d.logger.With().Str("type", task.Type).Str("task_id", task.ID).Logger()
+
+	// The email goes out directly, not through the queue the task died on
+	if len(d.cfg.Emails) > 0 {
+		if emailClient == nil {
+			logger.Warn().Msg("dead task email not sent, the email client isn't initialized")
+		} else {
+			for _, to := range d.cfg.Emails {
+				if err := emailClient.SendDeadTaskEmail(to, d.emailData(task)); err != nil {
+					logger.Error().Err(err).Str("to", to).Msg("failed to send dead task email")
+				}
+			}
+		}
+	}
+
+	if d.cfg.WebhookURL != "" {
+		if err := d.callWebhook(task); err != nil {
+			logger.Error().Err(err).Msg("failed to call dead task webhook")
+		}
+	}
+}
+
+func (d *deadTasks) emailData(task DeadTask) email.DeadTask {
+	return email.DeadTask{
+		ID:       task.ID,
+		Type:     task.Type,
+		Queue:    task.Queue,
+		Retried:  task.Retried,
+		MaxRetry: task.MaxRetry,
+		Error:    task.Error,
+		Payload:  task.Payload,
+		DiedAt:   task.DiedAt,
+		Cooldown: d.cfg.Cooldown,
+	}
+}
+
+func (d *deadTasks) callWebhook(task DeadTask) error {
+	body, err := json.Marshal(map[string]any{
+		"event": "job.retries_exhausted",
+		"task":  task,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode dead task: %w", err)
+	}
+
+	// Alerts are sent in the background, after the task's context is done
+	ctx, cancel := context.WithTimeout(context.Background(), deadTaskAlertTimeout) //nolint:forbidigo // outlives the task context
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// summarizePayload describes payload without its values, which may carry personal
+// data: its size and, for JSON objects, up to maxKeys of its top-level keys.
+func summarizePayload(payload []byte, maxKeys int) string {
+	summary := fmt.Sprintf("%d bytes", len(payload))
+
+	var object map[string]json.RawMessage
+	if json.Unmarshal(payload, &object) != nil || len(object) == 0 {
+		return summary
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	if len(keys) > maxKeys {
+		return fmt.Sprintf("%s, keys %s and %d more", summary, strings.Join(keys[:maxKeys], ", "), len(keys)-maxKeys)
+	}
+	return fmt.Sprintf("%s, keys %s", summary, strings.Join(keys, ", "))
+}
+
+// asynqErrorHandler reports the tasks of the redis backend that failed for the last time,
+// asynq archives them after the handler. Revoked tasks are dropped, not archived.
+func asynqErrorHandler(dead *deadTasks) asynq.ErrorHandler {
+	return asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
+		if errors.Is(err, asynq.RevokeTask) {
+			return
+		}
+
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		if retried < maxRetry && !errors.Is(err, asynq.SkipRetry) {
+			return
+		}
+
+		id, _ := asynq.GetTaskID(ctx)
+		queue, _ := asynq.GetQueueName(ctx)
+
+		dead.report(DeadTask{
+			ID:       id,
+			Type:     task.Type(),
+			Queue:    queue,
+			Retried:  retried,
+			MaxRetry: maxRetry,
+			Error:    err.Error(),
+		}, task.Payload())
+	})
+}
@@ -0,0 +1,45 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const (
+	TaskDigestDispatch = "digest:dispatch"
+	TaskDigestEmail    = "email:digest"
+)
+
+type DigestEmailTaskPayload struct {
+	UserID string    `json:"user_id"` // recipient user
+	Since  time.Time `json:"since"`   // start of the summarized period
+	Until  time.Time `json:"until"`   // end of the summarized period
+}
+
+// NewDigestDispatchTask creates the periodic task that fans out one digest email task per user.
+// It is unique for most of period, so the schedulers of several instances enqueue it only once.
+func NewDigestDispatchTask(period time.Duration) *Task {
+	return NewTask(TaskDigestDispatch, nil, asynq.Timeout(30*time.Minute), asynq.Queue(QueueFor(TaskDigestDispatch)), asynq.Unique(period/2))
+}
+
+// NewDigestEmailTask creates a task sending one user's digest for the given period.
+// The task ID makes re-dispatching the same period a no-op for users already enqueued,
+// the task is retained for the period after it completes so its ID stays taken.
+func NewDigestEmailTask(userID string, since, until time.Time) (*Task, error) {
+	jsonPayload, err := json.Marshal(DigestEmailTaskPayload{
+		UserID: userID,
+		Since:  since,
+		Until:  until,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	taskID := fmt.Sprintf("digest:%s:%d", userID, until.Unix())
+
+	return NewTask(TaskDigestEmail, jsonPayload, asynq.TaskID(taskID), asynq.Retention(until.Sub(since)), asynq.Timeout(time.Minute), asynq.Queue(QueueFor(TaskDigestEmail))), nil
+}
@@ -15,7 +15,7 @@ type WelcomeEmailTaskPayload struct {
 }
 
 // NewWelcomeEmailTask creates a new task to send a welcome email to a user
-func NewWelcomeEmailTask(to string, firstName string) (*asynq.Task, error) {
+func NewWelcomeEmailTask(to string, firstName string) (*Task, error) {
 	jsonPayload, err := json.Marshal(WelcomeEmailTaskPayload{
 		To:        to,
 		FirstName: firstName,
@@ -25,5 +25,25 @@ func NewWelcomeEmailTask(to string, firstName string) (*asynq.Task, error) {
 		return nil, err
 	}
 
-	return asynq.NewTask(TaskWelcomeEmail, jsonPayload, asynq.Timeout(30*time.Second), asynq.MaxRetry(3), asynq.Queue("default")), nil
+	return NewTask(TaskWelcomeEmail, jsonPayload, asynq.Timeout(30*time.Second), asynq.Queue(QueueFor(TaskWelcomeEmail))), nil
+}
+
+const TaskVerificationEmail = "email:verification"
+
+type VerificationEmailTaskPayload struct {
+	UserID string `json:"user_id"`
+	To     string `json:"to"`
+	// URL is the verification link, its token is only stored hashed.
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewVerificationEmailTask creates a task sending the email verification link of a user.
+func NewVerificationEmailTask(payload VerificationEmailTaskPayload) (*Task, error) {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTask(TaskVerificationEmail, jsonPayload, asynq.Timeout(30*time.Second), asynq.Queue(QueueFor(TaskVerificationEmail))), nil
 }
@@ -0,0 +1,27 @@
+package job
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+const TaskExport = "export:build"
+
+type ExportTaskPayload struct {
+	ExportID uuid.UUID `json:"export_id"` // async export to build
+}
+
+// NewExportTask creates a task that builds the file of an async CSV/XLSX export.
+func NewExportTask(exportID uuid.UUID) (*Task, error) {
+	jsonPayload, err := json.Marshal(ExportTaskPayload{
+		ExportID: exportID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTask(TaskExport, jsonPayload, asynq.Timeout(30*time.Minute), asynq.Queue(QueueFor(TaskExport))), nil
+}
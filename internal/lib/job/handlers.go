@@ -7,15 +7,20 @@ import (
 
 	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/email"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/sendrate"
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog"
 )
 
-var emailClient *email.Client
+var (
+	emailClient   *email.Client
+	emailThrottle *sendrate.Throttle
+)
 
 // InitHandlers initializes dependencies required by the job handlers.
-func (j *JobService) InitHandlers(config *config.Config, logger *zerolog.Logger) {
+func (j *JobService) InitHandlers(config *config.Config, logger *zerolog.Logger, throttle *sendrate.Throttle) {
 	emailClient = email.NewClient(config, logger)
+	emailThrottle = throttle
 }
 
 func (j *JobService) handleWelcomeEmailTask(ctx context.Context, t *asynq.Task) error {
@@ -29,6 +34,11 @@ func (j *JobService) handleWelcomeEmailTask(ctx context.Context, t *asynq.Task)
 	// Log that the task is being processed.
 	j.logger.Info().Str("type", "welcome").Str("to", p.To).Msg("processing welcome email task")
 
+	// Wait for a slot within the provider's sending limit, or retry once the burst has passed.
+	if err := emailThrottle.Wait(ctx, email.ProviderResend); err != nil {
+		return err
+	}
+
 	// Attempt to send the welcome email to the specified recipient.
 	err := emailClient.SendWelcomeEmail(p.To, p.FirstName)
 	if err != nil {
@@ -0,0 +1,28 @@
+package job
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+const TaskHealthProbe = "health:probe"
+
+type HealthProbeTaskPayload struct {
+	ProbeID uuid.UUID `json:"probe_id"` // probe row of the deep health check to mark processed
+}
+
+// NewHealthProbeTask creates the task the deep health check waits for. Its retry policy
+// doesn't retry it, a probe that didn't go through at once already failed the check.
+func NewHealthProbeTask(probeID uuid.UUID) (*Task, error) {
+	jsonPayload, err := json.Marshal(HealthProbeTaskPayload{
+		ProbeID: probeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTask(TaskHealthProbe, jsonPayload, asynq.Timeout(30*time.Second), asynq.Queue(QueueFor(TaskHealthProbe))), nil
+}
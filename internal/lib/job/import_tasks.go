@@ -0,0 +1,28 @@
+package job
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+const TaskImportCommit = "import:commit"
+
+type ImportCommitTaskPayload struct {
+	ImportID uuid.UUID `json:"import_id"` // validated import whose staged rows to commit
+}
+
+// NewImportCommitTask creates a task that commits the staged rows of an import in batches.
+// Committed batches are removed from the staging table, so a retry resumes where it failed.
+func NewImportCommitTask(importID uuid.UUID) (*Task, error) {
+	jsonPayload, err := json.Marshal(ImportCommitTaskPayload{
+		ImportID: importID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTask(TaskImportCommit, jsonPayload, asynq.Timeout(30*time.Minute), asynq.Queue(QueueFor(TaskImportCommit))), nil
+}
@@ -1,21 +1,86 @@
 package job
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
 	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/clock"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
 	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog"
 )
 
-// - Client is used to enqueue tasks
+// Task is a task together with the options it is enqueued with, e.g. its queue, timeout
+// or uniqueness. asynq keeps the options a task was created with private, so tasks carry
+// them here and Enqueue and RegisterPeriodic hand them to whichever backend runs them.
+type Task struct {
+	*asynq.Task
+	Options []asynq.Option
+}
+
+// NewTask returns a task of taskType enqueued with opts. The options of its retry policy
+// are applied after them, so retries and retention belong in the policy, and options
+// passed to Enqueue or RegisterPeriodic override both.
+func NewTask(taskType string, payload []byte, opts ...asynq.Option) *Task {
+	return &Task{Task: asynq.NewTask(taskType, payload), Options: opts}
+}
+
+// - Client is used to enqueue tasks, nil with the postgres backend
 // - server runs worker goroutines that process tasks
 // - logger logs start / stop messages
+// - mux routes incoming tasks to their handlers
+// - scheduler enqueues periodic tasks on their cron schedule
+// - pg replaces client, server and scheduler with the postgres backend
+// - policies holds the retry policy of every task type
+// - backpressure keeps queues below their configured ceilings
+// - streams processes the task types registered with RegisterStream, nil with the postgres backend
+// - dead alerts on tasks that ran out of retries, on every backend
 type JobService struct {
-	Client *asynq.Client
-	logger *zerolog.Logger
-	server *asynq.Server
+	Client       *asynq.Client
+	logger       *zerolog.Logger
+	server       *asynq.Server
+	mux          *asynq.ServeMux
+	scheduler    *asynq.Scheduler
+	pg           *pgQueue
+	policies     map[string]RetryPolicy
+	backpressure *backpressure
+	streams      *streams
+	dead         *deadTasks
 }
 
-func NewJobService(logger *zerolog.Logger, cfg *config.Config) *JobService {
+// NewJobService creates the job service on the backend selected by jobs.backend.
+// pool is only used by the postgres backend. clk schedules the postgres and stream
+// backends and expires cached queue sizes, asynq keeps time on its own.
+func NewJobService(logger *zerolog.Logger, cfg *config.Config, newRelicApp *newrelic.Application, pool *pgxpool.Pool, clk clock.Clock) (*JobService, error) {
+	clk = clock.OrReal(clk)
+
+	// Resolve the retry policy of every task type from code defaults and config
+	policies := retryPolicies(cfg.Jobs)
+
+	// Alert on tasks that ran out of retries, whichever backend archived them
+	dead := newDeadTasks(cfg.Jobs.DeadTasks, logger, clk)
+
+	if cfg.Jobs.Backend == BackendPostgres {
+		// Queue tasks in Postgres and process them in process, for deployments without Redis
+		queue := newPgQueue(pool, logger, policies, cfg.Jobs.Postgres, dead, clk)
+
+		return &JobService{
+			logger:       logger,
+			mux:          asynq.NewServeMux(),
+			pg:           queue,
+			policies:     policies,
+			backpressure: newBackpressure(queue.size, logger, newRelicApp, cfg.Jobs.Backpressure, clk),
+			dead:         dead,
+		}, nil
+	}
+
 	// Read Redis address from config
 	redisAddress := cfg.Redis.Address
 
@@ -24,44 +89,263 @@ func NewJobService(logger *zerolog.Logger, cfg *config.Config) *JobService {
 		Addr: redisAddress,
 	})
 
-	// Create an asynq server which will execute tasks with a given concurrency and queue weights
+	// Create an asynq server which will execute tasks with the configured concurrency and queue weights
+	workers := cfg.Jobs.Workers
 	server := asynq.NewServer(asynq.RedisClientOpt{
 		Addr: redisAddress,
 	}, asynq.Config{
-		Concurrency: 10,
-		Queues: map[string]int{
-			"critical": 6, // more capacity for important tasks
-			"default":  3, // normal tasks
-			"low":      1, // non-urgent tasks
+		Concurrency:         workers.Concurrency,
+		Queues:              workers.Queues,
+		StrictPriority:      workers.StrictPriority,
+		ShutdownTimeout:     workers.ShutdownTimeout,
+		HealthCheckInterval: workers.HealthCheckInterval,
+		HealthCheckFunc: func(err error) {
+			if err != nil {
+				logger.Error().Err(err).Msg("job server health check failed")
+			}
+		},
+		// Delay retries according to the backoff curve of the task type's policy
+		RetryDelayFunc: func(n int, err error, t *asynq.Task) time.Duration {
+			return retryDelay(policies, t.Type(), n, err)
 		},
+		ErrorHandler: asynqErrorHandler(dead),
+	})
+	// Create a scheduler enqueueing periodic tasks, schedules are interpreted in UTC
+	scheduler := asynq.NewScheduler(asynq.RedisClientOpt{
+		Addr: redisAddress,
+	}, &asynq.SchedulerOpts{
+		Location: time.UTC,
+	})
+
+	// Create an inspector reading queue sizes for the backpressure check
+	inspector := asynq.NewInspector(asynq.RedisClientOpt{
+		Addr: redisAddress,
+	})
+
+	// Stream tasks are read with a client of their own, workers hold a connection each
+	// while waiting for entries.
+	streamsClient := redis.NewClient(&redis.Options{
+		Addr: redisAddress,
 	})
+
 	return &JobService{
 		Client: client,
 		logger: logger,
 		server: server,
+		// create a new multiplexer to route incoming tasks to handlers
+		mux:          asynq.NewServeMux(),
+		scheduler:    scheduler,
+		policies:     policies,
+		backpressure: newBackpressure(redisQueueSize(inspector), logger, newRelicApp, cfg.Jobs.Backpressure, clk),
+		streams:      newStreams(streamsClient, keys.New(cfg.Redis.App, cfg.Primary.Env).Space(keys.JobStreams), logger, policies, cfg.Jobs.Streams, dead, clk),
+		dead:         dead,
+	}, nil
+}
+
+// RetryPolicy returns the retry policy applied to a task type.
+func (js *JobService) RetryPolicy(taskType string) RetryPolicy {
+	return policyFor(js.policies, taskType)
+}
+
+// Enqueue enqueues a task with its type's retry policy applied.
+// Options passed by the caller are applied last, so they override the policy for this task only.
+// If the task's queue is at its ceiling, the overflow policy of the task type applies:
+// rejected tasks fail with ErrQueueFull, dropped and outboxed tasks return a nil TaskInfo.
+// Stream tasks have no queue and are never held back.
+func (js *JobService) Enqueue(ctx context.Context, task *Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	queue := queueOf(task, opts)
+	if js.streams.routed(task.Type()) || !js.backpressure.saturated(queue) {
+		return js.enqueue(ctx, task, queue, opts)
+	}
+
+	policy := js.backpressure.policyFor(task.Type())
+	js.backpressure.recordOverflow(task.Type(), queue, policy)
+
+	switch policy {
+	case OverflowDrop:
+		return nil, nil
+
+	case OverflowShed:
+		if queue != shedQueue && !js.backpressure.saturated(shedQueue) {
+			return js.enqueue(ctx, task, shedQueue, append(opts, asynq.Queue(shedQueue)))
+		}
+
+	case OverflowOutbox:
+		if outbox := js.backpressure.getOutbox(); outbox != nil {
+			options, err := encodeOutboxOptions(slices.Concat(task.Options, opts), time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode options of %s task: %w", task.Type(), err)
+			}
+			if err := outbox.SaveTask(ctx, task.Type(), task.Payload(), queue, options); err != nil {
+				return nil, fmt.Errorf("failed to write %s task to the outbox: %w", task.Type(), err)
+			}
+			return nil, nil
+		}
 	}
+
+	return nil, fmt.Errorf("%w: %s task not enqueued on queue %s", ErrQueueFull, task.Type(), queue)
 }
 
-func (js *JobService) Start() error {
-	// create a new multiplexer to route incoming tasks to handlers
-	mux := asynq.NewServeMux()
+func (js *JobService) enqueue(ctx context.Context, task *Task, queue string, opts []asynq.Option) (*asynq.TaskInfo, error) {
+	options := slices.Concat(task.Options, js.RetryPolicy(task.Type()).Options(), opts)
+
+	if js.streams.routed(task.Type()) {
+		return js.streams.enqueue(ctx, task.Task, options)
+	}
+
+	var info *asynq.TaskInfo
+	var err error
+	if js.pg != nil {
+		info, err = js.pg.enqueue(ctx, task.Task, options)
+	} else {
+		info, err = js.Client.EnqueueContext(ctx, task.Task, options...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	js.backpressure.added(queue)
+	return info, nil
+}
 
+// EnqueueUnchecked enqueues a task with its type's retry policy applied, skipping the
+// backpressure check. The outbox relay uses it since it checks HasCapacity itself,
+// going through Enqueue would apply the overflow policy and outbox the task again.
+func (js *JobService) EnqueueUnchecked(ctx context.Context, task *Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	return js.enqueue(ctx, task, queueOf(task, opts), opts)
+}
+
+// SetOutbox sets where tasks with the OverflowOutbox policy are persisted. Without
+// an outbox those tasks are rejected like OverflowReject.
+func (js *JobService) SetOutbox(outbox Outbox) {
+	js.backpressure.setOutbox(outbox)
+}
+
+// HasCapacity reports whether queue is below its ceiling. The outbox relay uses it
+// to only move tasks back once there is room.
+func (js *JobService) HasCapacity(queue string) bool {
+	return !js.backpressure.saturated(queue)
+}
+
+func policyFor(policies map[string]RetryPolicy, taskType string) RetryPolicy {
+	if policy, ok := policies[taskType]; ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
+// RegisterHandler registers a handler for a task type owned by another layer
+// (e.g. services that need repositories). The multiplexer is safe for concurrent
+// use, so handlers can be registered after the job server has started.
+// Task types without a policy of their own are retried with DefaultRetryPolicy.
+func (js *JobService) RegisterHandler(taskType string, handler func(context.Context, *asynq.Task) error) {
+	js.mux.HandleFunc(taskType, handler)
+}
+
+// RegisterStream registers a handler for a task type processed from Redis streams instead
+// of an asynq queue: tasks sharing an ordering key are processed one at a time in the order
+// they were enqueued, across all instances. Tasks are enqueued with Enqueue as usual and
+// retried in place with their type's retry policy, later tasks of the partition wait.
+// Delivery is at least once, so the handler must be idempotent, see GetTaskID. Only the
+// redis backend supports streams. Like RegisterHandler it can be called after the job
+// server has started.
+func (js *JobService) RegisterStream(taskType string, opts StreamOptions, handler func(context.Context, *asynq.Task) error) error {
+	if js.streams == nil {
+		return fmt.Errorf("stream task %s requires the redis job backend", taskType)
+	}
+
+	js.mux.HandleFunc(taskType, handler)
+	return js.streams.route(taskType, opts)
+}
+
+// RegisterPeriodic enqueues task on a cron schedule (e.g. "0 8 * * 1"), with its type's retry policy applied.
+// Every instance runs a scheduler, so periodic tasks should be made unique (asynq.Unique) to be enqueued
+// only once per tick. Like RegisterHandler it can be called after the job server has started.
+func (js *JobService) RegisterPeriodic(cronspec string, task *Task, opts ...asynq.Option) (string, error) {
+	if js.streams.routed(task.Type()) {
+		return "", fmt.Errorf("stream task %s can't be periodic, enqueue it from a periodic task instead", task.Type())
+	}
+
+	options := slices.Concat(task.Options, js.RetryPolicy(task.Type()).Options(), opts)
+
+	var entryID string
+	var err error
+	if js.pg != nil {
+		entryID, err = js.pg.register(cronspec, task.Task, options)
+	} else {
+		entryID, err = js.scheduler.Register(cronspec, task.Task, options...)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	js.logger.Info().Str("type", task.Type()).Str("schedule", cronspec).Msg("registered periodic task")
+
+	return entryID, nil
+}
+
+// PreviousTick returns the latest time at or before now that cronspec (UTC) fires at.
+// Periodic tasks don't carry the tick that enqueued them, their handlers use it to
+// work on the same tick however late they run or often they are retried.
+func PreviousTick(cronspec string, now time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(cronspec)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid schedule %q: %w", cronspec, err)
+	}
+
+	now = now.UTC()
+	// Look back further until a tick is found, then walk forward to the last one
+	for lookback := time.Hour; lookback <= 5*366*24*time.Hour; lookback *= 2 {
+		tick := schedule.Next(now.Add(-lookback))
+		if tick.IsZero() || tick.After(now) {
+			continue
+		}
+		for next := schedule.Next(tick); !next.After(now); next = schedule.Next(tick) {
+			tick = next
+		}
+		return tick, nil
+	}
+
+	return time.Time{}, fmt.Errorf("schedule %q didn't fire in the last 5 years", cronspec)
+}
+
+func (js *JobService) Start() error {
 	// register a handler function for each task type
-	mux.HandleFunc(TaskWelcomeEmail, js.handleWelcomeEmailTask)
+	js.mux.HandleFunc(TaskWelcomeEmail, js.handleWelcomeEmailTask)
 
 	js.logger.Info().Msg("Starting job server...")
 
+	if js.pg != nil {
+		js.pg.start(js.mux)
+		return nil
+	}
+
 	// if starting the server fails, return the error so caller can handle it
-	if err := js.server.Start(mux); err != nil {
+	if err := js.server.Start(js.mux); err != nil {
 		return err
 	}
 
+	// start enqueueing periodic tasks, entries registered later are picked up as well
+	if err := js.scheduler.Start(); err != nil {
+		return err
+	}
+
+	// work on the partitions of stream tasks, types registered later are picked up as well
+	js.streams.start(js.mux)
+
 	return nil
 }
 
 // graceful shutdown
 func (js *JobService) Stop() {
 	js.logger.Info().Msg("stopping job server...")
+	if js.pg != nil {
+		js.pg.stop()
+		return
+	}
+	js.scheduler.Shutdown()
+	js.streams.stop()
 	js.server.Shutdown()
 	js.Client.Close()
+	js.streams.client.Close()
 }
@@ -0,0 +1,30 @@
+package job
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TaskNotification = "notification:send"
+
+type NotificationTaskPayload struct {
+	Channel string `json:"channel"` // "sms" or "push"
+	UserID  string `json:"user_id"`
+	// To is the phone number of SMS, push notifications go to every device of the user.
+	To    string            `json:"to,omitempty"`
+	Title string            `json:"title,omitempty"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// NewNotificationTask creates a task that sends an SMS or push notification to a user.
+func NewNotificationTask(payload NotificationTaskPayload) (*Task, error) {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTask(TaskNotification, jsonPayload, asynq.Timeout(time.Minute), asynq.Queue(QueueFor(TaskNotification))), nil
+}
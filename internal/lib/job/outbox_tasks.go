@@ -0,0 +1,14 @@
+package job
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TaskOutboxRelay = "outbox:relay"
+
+// NewOutboxRelayTask creates the task moving outboxed tasks back to their queues.
+func NewOutboxRelayTask() *Task {
+	return NewTask(TaskOutboxRelay, nil, asynq.Timeout(5*time.Minute), asynq.Queue(QueueFor(TaskOutboxRelay)), asynq.Unique(time.Minute))
+}
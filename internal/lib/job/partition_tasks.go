@@ -0,0 +1,15 @@
+package job
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TaskPartitionMaintenance = "maintenance:partitions"
+
+// NewPartitionMaintenanceTask creates the periodic task creating upcoming partitions and dropping expired ones.
+// It is unique for an hour, so the schedulers of several instances enqueue it only once.
+func NewPartitionMaintenanceTask() *Task {
+	return NewTask(TaskPartitionMaintenance, nil, asynq.Timeout(30*time.Minute), asynq.Queue(QueueFor(TaskPartitionMaintenance)), asynq.Unique(time.Hour))
+}
@@ -0,0 +1,559 @@
+package job
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/clock"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// BackendRedis queues tasks in Redis with asynq.
+	BackendRedis = "redis"
+	// BackendPostgres queues tasks in the job_queue table, processed in process.
+	BackendPostgres = "postgres"
+)
+
+const (
+	// defaultTaskTimeout applies to tasks with neither a timeout nor a deadline, like in asynq.
+	defaultTaskTimeout = 30 * time.Minute
+	// defaultMaxRetry applies to tasks without a max retry option, like in asynq.
+	defaultMaxRetry = 25
+	// leaseGrace is how long an active task's lease outlives its timeout before the task
+	// is considered lost with its worker and put back in its queue.
+	leaseGrace = time.Minute
+	// maintenanceInterval is how often lost tasks are recovered and expired ones purged.
+	maintenanceInterval = time.Minute
+)
+
+// queuePriority is the order the postgres backend serves queues in. Unlike asynq's
+// weighted queues it is strict: low tasks only run while nothing more urgent is due.
+var queuePriority = []string{"critical", "default", "low"}
+
+// pgQueue is the postgres job backend, for deployments without Redis. Tasks are rows
+// of job_queue, claimed by a bounded worker pool polling with FOR UPDATE SKIP LOCKED,
+// so any number of instances can work off the same table. It honours the asynq options
+// tasks are enqueued with (queue, timeout, deadline, unique, process at/in, task ID, max
+// retry and retention) and dispatches through the same ServeMux, handlers don't tell
+// the backends apart.
+type pgQueue struct {
+	pool     *pgxpool.Pool
+	logger   *zerolog.Logger
+	policies map[string]RetryPolicy
+	cfg      config.PostgresQueueConfig
+	dead     *deadTasks
+	clock    clock.Clock
+	cron     *cron.Cron
+
+	handler     asynq.Handler
+	slots       chan struct{}
+	workers     sync.WaitGroup
+	cancelPoll  context.CancelFunc
+	cancelTasks context.CancelFunc
+	done        chan struct{}
+}
+
+// lostWorkerError is the last error of tasks whose worker was lost.
+const lostWorkerError = "task lease expired, its worker was lost"
+
+// lostTask is a task maintain took back from a lost worker, archived if it had no
+// retries left.
+type lostTask struct {
+	pgTask
+	Archived bool `db:"archived"`
+}
+
+// pgTask is a claimed row of job_queue.
+type pgTask struct {
+	ID        string     `db:"id"`
+	Type      string     `db:"type"`
+	Payload   []byte     `db:"payload"`
+	Queue     string     `db:"queue"`
+	Retried   int        `db:"retried"`
+	MaxRetry  int        `db:"max_retry"`
+	TimeoutMS int64      `db:"timeout_ms"`
+	Deadline  *time.Time `db:"deadline"`
+}
+
+func newPgQueue(pool *pgxpool.Pool, logger *zerolog.Logger, policies map[string]RetryPolicy, cfg config.PostgresQueueConfig, dead *deadTasks, clk clock.Clock) *pgQueue {
+	return &pgQueue{
+		pool:     pool,
+		logger:   logger,
+		policies: policies,
+		cfg:      cfg,
+		dead:     dead,
+		clock:    clk,
+		cron:     cron.New(cron.WithLocation(time.UTC)),
+		slots:    make(chan struct{}, cfg.Concurrency),
+	}
+}
+
+// taskOptions are the options of a task resolved the way asynq resolves them, later
+// options override earlier ones.
+type taskOptions struct {
+	queue     string
+	maxRetry  int
+	timeout   time.Duration
+	deadline  time.Time
+	unique    time.Duration
+	processAt time.Time
+	taskID    string
+	retention time.Duration
+}
+
+func resolveOptions(opts []asynq.Option, now time.Time) taskOptions {
+	o := taskOptions{
+		queue:     "default",
+		maxRetry:  defaultMaxRetry,
+		processAt: now,
+	}
+
+	for _, opt := range opts {
+		switch opt.Type() {
+		case asynq.QueueOpt:
+			o.queue, _ = opt.Value().(string)
+		case asynq.MaxRetryOpt:
+			o.maxRetry, _ = opt.Value().(int)
+		case asynq.TimeoutOpt:
+			o.timeout, _ = opt.Value().(time.Duration)
+		case asynq.DeadlineOpt:
+			o.deadline, _ = opt.Value().(time.Time)
+		case asynq.UniqueOpt:
+			o.unique, _ = opt.Value().(time.Duration)
+		case asynq.ProcessAtOpt:
+			o.processAt, _ = opt.Value().(time.Time)
+		case asynq.ProcessInOpt:
+			delay, _ := opt.Value().(time.Duration)
+			o.processAt = now.Add(delay)
+		case asynq.TaskIDOpt:
+			o.taskID, _ = opt.Value().(string)
+		case asynq.RetentionOpt:
+			o.retention, _ = opt.Value().(time.Duration)
+		}
+	}
+
+	return o
+}
+
+// enqueue inserts task with opts, Task.Options included, failing with asynq.ErrDuplicateTask or asynq.ErrTaskIDConflict
+// in the same cases asynq does.
+func (q *pgQueue) enqueue(ctx context.Context, task *asynq.Task, opts []asynq.Option) (*asynq.TaskInfo, error) {
+	now := q.clock.Now()
+	o := resolveOptions(opts, now)
+
+	id := o.taskID
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	tx, err := q.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var uniqueKey *string
+	var uniqueUntil *time.Time
+	if o.unique > 0 {
+		key := taskUniqueKey(o.queue, task)
+		until := now.Add(o.unique)
+		uniqueKey, uniqueUntil = &key, &until
+
+		// Serializes enqueues of the same unique task across instances until commit.
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, key); err != nil {
+			return nil, fmt.Errorf("failed to lock unique key of %s task: %w", task.Type(), err)
+		}
+
+		var exists bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM job_queue
+				WHERE unique_key = $1 AND unique_until > now() AND state IN ('pending', 'active')
+			)
+		`, key).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check unique key of %s task: %w", task.Type(), err)
+		}
+		if exists {
+			return nil, asynq.ErrDuplicateTask
+		}
+	}
+
+	var deadline *time.Time
+	if !o.deadline.IsZero() {
+		deadline = &o.deadline
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO job_queue (id, type, payload, queue, max_retry, timeout_ms, deadline, retention_ms, unique_key, unique_until, process_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (id) DO NOTHING
+	`, id, task.Type(), task.Payload(), o.queue, o.maxRetry, o.timeout.Milliseconds(), deadline, o.retention.Milliseconds(), uniqueKey, uniqueUntil, o.processAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert %s task: %w", task.Type(), err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, asynq.ErrTaskIDConflict
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit %s task: %w", task.Type(), err)
+	}
+
+	state := asynq.TaskStatePending
+	if o.processAt.After(now) {
+		state = asynq.TaskStateScheduled
+	}
+
+	return &asynq.TaskInfo{
+		ID:            id,
+		Queue:         o.queue,
+		Type:          task.Type(),
+		Payload:       task.Payload(),
+		State:         state,
+		MaxRetry:      o.maxRetry,
+		Timeout:       o.timeout,
+		Deadline:      o.deadline,
+		NextProcessAt: o.processAt,
+		Retention:     o.retention,
+	}, nil
+}
+
+// taskUniqueKey identifies a task for asynq.Unique the way asynq does: by queue, type and payload.
+func taskUniqueKey(queue string, task *asynq.Task) string {
+	sum := md5.Sum(task.Payload())
+	return queue + ":" + task.Type() + ":" + hex.EncodeToString(sum[:])
+}
+
+// size returns the backlog of queue: pending, scheduled and retrying tasks.
+func (q *pgQueue) size(queue string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second) //nolint:forbidigo // backpressure check, called without a context
+	defer cancel()
+
+	var size int
+	if err := q.pool.QueryRow(ctx, `SELECT count(*) FROM job_queue WHERE queue = $1 AND state = 'pending'`, queue).Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to count tasks of queue %s: %w", queue, err)
+	}
+
+	return size, nil
+}
+
+// register enqueues task on a cron schedule from every instance, unique tasks are
+// enqueued once per tick like with asynq's scheduler.
+func (q *pgQueue) register(cronspec string, task *asynq.Task, opts []asynq.Option) (string, error) {
+	entryID, err := q.cron.AddFunc(cronspec, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second) //nolint:forbidigo // cron tick, not tied to a request
+		defer cancel()
+
+		if _, err := q.enqueue(ctx, task, opts); err != nil {
+			if errors.Is(err, asynq.ErrDuplicateTask) || errors.Is(err, asynq.ErrTaskIDConflict) {
+				q.logger.Debug().Str("type", task.Type()).Msg("periodic task already enqueued")
+				return
+			}
+			q.logger.Error().Err(err).Str("type", task.Type()).Msg("failed to enqueue periodic task")
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(int(entryID)), nil
+}
+
+// start runs the scheduler and the poll loop, tasks are dispatched to handler.
+func (q *pgQueue) start(handler asynq.Handler) {
+	q.handler = handler
+
+	pollCtx, cancelPoll := context.WithCancel(context.Background())   //nolint:forbidigo // background poll loop, stopped by stop
+	tasksCtx, cancelTasks := context.WithCancel(context.Background()) //nolint:forbidigo // running tasks, cancelled by stop
+	q.cancelPoll, q.cancelTasks = cancelPoll, cancelTasks
+	q.done = make(chan struct{})
+
+	go q.poll(pollCtx, tasksCtx)
+	q.cron.Start()
+}
+
+// stop stops claiming tasks and waits for the running ones. Tasks still running after
+// the shutdown timeout are cancelled and put back in their queue.
+func (q *pgQueue) stop() {
+	<-q.cron.Stop().Done()
+
+	if q.cancelPoll == nil {
+		return
+	}
+	q.cancelPoll()
+	<-q.done
+
+	finished := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-q.clock.After(q.cfg.ShutdownTimeout):
+		q.logger.Warn().Msg("job workers didn't finish in time, cancelling running tasks")
+		q.cancelTasks()
+		<-finished
+	}
+	q.cancelTasks()
+}
+
+func (q *pgQueue) poll(ctx, tasksCtx context.Context) {
+	defer close(q.done)
+
+	ticker := q.clock.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var maintainedAt time.Time
+	for {
+		if q.clock.Since(maintainedAt) >= maintenanceInterval {
+			q.maintain(ctx)
+			maintainedAt = q.clock.Now()
+		}
+
+		q.dispatch(ctx, tasksCtx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// dispatch claims due tasks while workers are free. Only the poll loop takes slots,
+// so the free count can only grow while it claims.
+func (q *pgQueue) dispatch(ctx, tasksCtx context.Context) {
+	for {
+		free := cap(q.slots) - len(q.slots)
+		if free == 0 {
+			return
+		}
+
+		tasks, err := q.claim(ctx, free)
+		if err != nil {
+			if ctx.Err() == nil {
+				q.logger.Error().Err(err).Msg("failed to claim job tasks")
+			}
+			return
+		}
+
+		for _, task := range tasks {
+			q.slots <- struct{}{}
+			q.workers.Add(1)
+
+			go func() {
+				defer func() {
+					<-q.slots
+					q.workers.Done()
+				}()
+				q.process(tasksCtx, task)
+			}()
+		}
+
+		if len(tasks) < free {
+			return
+		}
+	}
+}
+
+func (q *pgQueue) claim(ctx context.Context, limit int) ([]pgTask, error) {
+	rows, err := q.pool.Query(ctx, `
+		UPDATE job_queue SET
+			state = 'active',
+			lease_until = now() + (CASE WHEN timeout_ms > 0 THEN timeout_ms ELSE $3 END + $4) * interval '1 millisecond',
+			updated_at = now()
+		WHERE id IN (
+			SELECT id FROM job_queue
+			WHERE state = 'pending' AND process_at <= now()
+			ORDER BY array_position($1::text[], queue) NULLS LAST, process_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, type, payload, queue, retried, max_retry, timeout_ms, deadline
+	`, queuePriority, limit, defaultTaskTimeout.Milliseconds(), leaseGrace.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[pgTask])
+}
+
+// process runs task and records the outcome. ctx is only cancelled when the shutdown
+// timeout ran out, the task is then put back without counting a retry.
+func (q *pgQueue) process(ctx context.Context, task pgTask) {
+	logger := q.logger.With().Str("type", task.Type).Str("task_id", task.ID).Logger()
+
+	// Context deadlines run on the wall clock, not on the queue's clock
+	deadline := time.Now().Add(defaultTaskTimeout)
+	if task.TimeoutMS > 0 {
+		deadline = time.Now().Add(time.Duration(task.TimeoutMS) * time.Millisecond)
+	}
+	if task.Deadline != nil && (task.TimeoutMS == 0 || task.Deadline.Before(deadline)) {
+		deadline = *task.Deadline
+	}
+
+	taskCtx, cancel := context.WithDeadline(withTaskMetadata(ctx, &taskMetadata{
+		id:       task.ID,
+		retried:  task.Retried,
+		maxRetry: task.MaxRetry,
+		writeResult: func(ctx context.Context, data []byte) error {
+			_, err := q.pool.Exec(ctx, `UPDATE job_queue SET result = $2, updated_at = now() WHERE id = $1`, task.ID, data)
+			return err
+		},
+	}), deadline)
+	err := q.run(taskCtx, task)
+	cancel()
+	cancelled := err != nil && ctx.Err() != nil
+
+	// The outcome is recorded even when ctx was cancelled by the shutdown.
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second) //nolint:forbidigo // outlives the cancelled task context
+	defer cancel()
+
+	switch {
+	case err == nil:
+		err = q.complete(ctx, task)
+	case cancelled:
+		logger.Warn().Msg("task cancelled by shutdown, putting it back")
+		_, err = q.pool.Exec(ctx, `UPDATE job_queue SET state = 'pending', lease_until = NULL, updated_at = now() WHERE id = $1`, task.ID)
+	default:
+		err = q.fail(ctx, task, err, &logger)
+	}
+
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to record task outcome")
+	}
+}
+
+func (q *pgQueue) run(ctx context.Context, task pgTask) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	// A deadline may have passed while the task waited in its queue.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return q.handler.ProcessTask(ctx, asynq.NewTask(task.Type, task.Payload))
+}
+
+// complete deletes task, or keeps it as completed for its retention.
+func (q *pgQueue) complete(ctx context.Context, task pgTask) error {
+	_, err := q.pool.Exec(ctx, `
+		WITH deleted AS (
+			DELETE FROM job_queue WHERE id = $1 AND retention_ms = 0 RETURNING id
+		)
+		UPDATE job_queue SET state = 'completed', completed_at = now(), lease_until = NULL, updated_at = now()
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM deleted)
+	`, task.ID)
+	return err
+}
+
+// fail schedules the next retry of task with its type's backoff, or archives it once
+// it ran out of retries or the handler returned asynq.SkipRetry.
+func (q *pgQueue) fail(ctx context.Context, task pgTask, taskErr error, logger *zerolog.Logger) error {
+	if task.Retried >= task.MaxRetry || errors.Is(taskErr, asynq.SkipRetry) {
+		logger.Error().Err(taskErr).Int("retried", task.Retried).Msg("task failed, archiving it")
+
+		_, err := q.pool.Exec(ctx, `
+			UPDATE job_queue SET state = 'archived', last_error = $2, lease_until = NULL, updated_at = now()
+			WHERE id = $1
+		`, task.ID, taskErr.Error())
+		if err != nil {
+			return err
+		}
+
+		q.dead.report(DeadTask{
+			ID:       task.ID,
+			Type:     task.Type,
+			Queue:    task.Queue,
+			Retried:  task.Retried,
+			MaxRetry: task.MaxRetry,
+			Error:    taskErr.Error(),
+		}, task.Payload)
+		return nil
+	}
+
+	delay := retryDelay(q.policies, task.Type, task.Retried, taskErr)
+	logger.Error().Err(taskErr).Int("retried", task.Retried).Dur("retry_in", delay).Msg("task failed, retrying")
+
+	_, err := q.pool.Exec(ctx, `
+		UPDATE job_queue SET
+			state = 'pending',
+			retried = retried + 1,
+			process_at = now() + $3 * interval '1 millisecond',
+			last_error = $2,
+			lease_until = NULL,
+			updated_at = now()
+		WHERE id = $1
+	`, task.ID, taskErr.Error(), delay.Milliseconds())
+	return err
+}
+
+// maintain puts tasks whose worker was lost (their lease expired) back in their queue,
+// counting a retry, and purges completed and archived tasks past their retention.
+func (q *pgQueue) maintain(ctx context.Context) {
+	rows, err := q.pool.Query(ctx, `
+		UPDATE job_queue SET
+			state = CASE WHEN retried < max_retry THEN 'pending' ELSE 'archived' END,
+			retried = CASE WHEN retried < max_retry THEN retried + 1 ELSE retried END,
+			process_at = now(),
+			last_error = $1,
+			lease_until = NULL,
+			updated_at = now()
+		WHERE state = 'active' AND lease_until < now()
+		RETURNING id, type, payload, queue, retried, max_retry, timeout_ms, deadline, state = 'archived' AS archived
+	`, lostWorkerError)
+	if err == nil {
+		var lost []lostTask
+		lost, err = pgx.CollectRows(rows, pgx.RowToStructByName[lostTask])
+		if len(lost) > 0 {
+			q.logger.Warn().Int("tasks", len(lost)).Msg("recovered job tasks of lost workers")
+		}
+		for _, task := range lost {
+			if task.Archived {
+				q.dead.report(DeadTask{
+					ID:       task.ID,
+					Type:     task.Type,
+					Queue:    task.Queue,
+					Retried:  task.Retried,
+					MaxRetry: task.MaxRetry,
+					Error:    lostWorkerError,
+				}, task.Payload)
+			}
+		}
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			q.logger.Error().Err(err).Msg("failed to recover lost job tasks")
+		}
+		return
+	}
+
+	_, err = q.pool.Exec(ctx, `
+		DELETE FROM job_queue
+		WHERE (state = 'completed' AND completed_at + retention_ms * interval '1 millisecond' < now())
+			OR (state = 'archived' AND updated_at < now() - $1 * interval '1 millisecond')
+	`, q.cfg.ArchiveRetention.Milliseconds())
+	if err != nil && ctx.Err() == nil {
+		q.logger.Error().Err(err).Msg("failed to purge expired job tasks")
+	}
+}
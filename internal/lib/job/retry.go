@@ -0,0 +1,173 @@
+package job
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/hibiken/asynq"
+)
+
+// BackoffCurve decides how the delay between retries grows.
+type BackoffCurve string
+
+const (
+	BackoffConstant    BackoffCurve = "constant"
+	BackoffLinear      BackoffCurve = "linear"
+	BackoffExponential BackoffCurve = "exponential"
+)
+
+// RetryPolicy describes how a task type is retried and how long it is kept after completion.
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    BackoffCurve
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	Retention  time.Duration
+}
+
+// DefaultRetryPolicy applies to every task type without a policy of its own.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	Backoff:    BackoffExponential,
+	BaseDelay:  10 * time.Second,
+	MaxDelay:   10 * time.Minute,
+	Retention:  0,
+}
+
+// defaultRetryPolicies are the code-level policies of the built-in task types.
+// Configuration (jobs.retry_policies) takes precedence over these.
+var defaultRetryPolicies = map[string]RetryPolicy{
+	TaskWelcomeEmail: DefaultRetryPolicy,
+	TaskDataExport: {
+		MaxRetries: 3,
+		Backoff:    BackoffExponential,
+		BaseDelay:  time.Minute,
+		MaxDelay:   30 * time.Minute,
+		Retention:  24 * time.Hour,
+	},
+	TaskAccountDeletion: {
+		MaxRetries: 5,
+		Backoff:    BackoffExponential,
+		BaseDelay:  time.Minute,
+		MaxDelay:   time.Hour,
+		Retention:  7 * 24 * time.Hour,
+	},
+	// Retries resume after the last completed chunk, the result is kept for senders to read the summary.
+	TaskBatchEmail: {
+		MaxRetries: 3,
+		Backoff:    BackoffExponential,
+		BaseDelay:  time.Minute,
+		MaxDelay:   10 * time.Minute,
+		Retention:  7 * 24 * time.Hour,
+	},
+	// A probe that didn't go through at once already failed the deep health check.
+	TaskHealthProbe: {
+		MaxRetries: 0,
+		Backoff:    BackoffConstant,
+		Retention:  0,
+	},
+	// A failed backup is retried a couple of times, the next scheduled run takes over after that.
+	TaskDatabaseBackup: {
+		MaxRetries: 2,
+		Backoff:    BackoffExponential,
+		BaseDelay:  15 * time.Minute,
+		MaxDelay:   time.Hour,
+		Retention:  0,
+	},
+}
+
+// Delay returns how long to wait before the given retry attempt (1-based).
+func (p RetryPolicy) Delay(retried int) time.Duration {
+	if retried < 1 {
+		retried = 1
+	}
+
+	limit := time.Duration(math.MaxInt64)
+	if p.MaxDelay > 0 {
+		limit = p.MaxDelay
+	}
+
+	var delay float64
+	switch p.Backoff {
+	case BackoffConstant:
+		return min(p.BaseDelay, limit)
+	case BackoffLinear:
+		delay = float64(p.BaseDelay) * float64(retried)
+	default:
+		delay = float64(p.BaseDelay) * math.Pow(2, float64(retried-1))
+	}
+
+	// Clamped before converting, a float past the range of Duration converts to garbage
+	// (a negative delay on amd64), which the exponential curve reaches after 30-odd retries.
+	if delay >= float64(limit) {
+		return limit
+	}
+
+	return time.Duration(delay)
+}
+
+// retryAfter is implemented by errors knowing when the task can succeed again, e.g. a
+// throttled send (sendrate.ThrottledError).
+type retryAfter interface {
+	RetryAfter() time.Duration
+}
+
+// retryDelay returns how long to wait before retrying a task of taskType that failed with
+// err: the delay the error asks for, or the one of the task type's policy.
+func retryDelay(policies map[string]RetryPolicy, taskType string, retried int, err error) time.Duration {
+	var after retryAfter
+	if errors.As(err, &after) && after.RetryAfter() > 0 {
+		return after.RetryAfter()
+	}
+	return policyFor(policies, taskType).Delay(retried)
+}
+
+// Options returns the asynq options enforcing the policy on a task.
+func (p RetryPolicy) Options() []asynq.Option {
+	opts := []asynq.Option{asynq.MaxRetry(p.MaxRetries)}
+	if p.Retention > 0 {
+		opts = append(opts, asynq.Retention(p.Retention))
+	}
+	return opts
+}
+
+// mergeRetryPolicy overlays the values set in cfg onto base.
+func mergeRetryPolicy(base RetryPolicy, cfg config.RetryPolicyConfig) RetryPolicy {
+	if cfg.MaxRetries != nil {
+		base.MaxRetries = *cfg.MaxRetries
+	}
+	if cfg.Backoff != "" {
+		base.Backoff = BackoffCurve(cfg.Backoff)
+	}
+	if cfg.BaseDelay > 0 {
+		base.BaseDelay = cfg.BaseDelay
+	}
+	if cfg.MaxDelay > 0 {
+		base.MaxDelay = cfg.MaxDelay
+	}
+	if cfg.Retention > 0 {
+		base.Retention = cfg.Retention
+	}
+	return base
+}
+
+// retryPolicies resolves the policy of every task type from the code defaults and configuration.
+func retryPolicies(cfg config.JobsConfig) map[string]RetryPolicy {
+	policies := make(map[string]RetryPolicy, len(defaultRetryPolicies)+len(cfg.RetryPolicies))
+
+	for taskType, policy := range defaultRetryPolicies {
+		policies[taskType] = policy
+	}
+
+	for taskType, override := range cfg.RetryPolicies {
+		base, ok := policies[taskType]
+		if !ok {
+			base = DefaultRetryPolicy
+		}
+		policies[taskType] = mergeRetryPolicy(base, override)
+	}
+
+	return policies
+}
@@ -0,0 +1,39 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/email"
+	"github.com/hibiken/asynq"
+)
+
+const TaskScheduledEmail = "email:scheduled"
+
+type ScheduledEmailTaskPayload struct {
+	// ID identifies the schedule, it cancels the email until it is sent.
+	ID      string        `json:"id"`
+	Message email.Message `json:"message"`
+}
+
+// NewScheduledEmailTask creates a task sending msg at at. The task ID is derived from
+// the schedule ID, so scheduling the same ID twice enqueues the email once.
+func NewScheduledEmailTask(id string, msg email.Message, at time.Time) (*Task, error) {
+	if !slices.Contains(email.Templates, msg.Template) {
+		return nil, fmt.Errorf("unknown email template %q", msg.Template)
+	}
+
+	jsonPayload, err := json.Marshal(ScheduledEmailTaskPayload{
+		ID:      id,
+		Message: msg,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTask(TaskScheduledEmail, jsonPayload, asynq.TaskID("scheduled_email:"+id), asynq.ProcessAt(at),
+		asynq.Timeout(time.Minute), asynq.Queue(QueueFor(TaskScheduledEmail))), nil
+}
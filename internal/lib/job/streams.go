@@ -0,0 +1,605 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/clock"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// streamGroup is the consumer group every instance reads the streams with.
+const streamGroup = "workers"
+
+// streamReadCount is the number of entries a worker reads at once.
+const streamReadCount = 10
+
+// renewLeaseScript extends the lease of a partition if this instance still holds it.
+// Returns 1 if it was extended.
+var renewLeaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLeaseScript deletes the lease of a partition if this instance still holds it.
+var releaseLeaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// StreamOptions routes a task type to Redis streams instead of an asynq queue, for
+// workflows that need their tasks processed in order.
+type StreamOptions struct {
+	// Partitions is the number of streams the task type is split into. Each partition is
+	// processed by one instance at a time, in enqueue order. Defaults to 1, a single
+	// strictly ordered stream.
+	Partitions int
+	// Key returns the ordering key of a task, e.g. the user ID of its payload. Tasks with
+	// the same key always go to the same partition, tasks without one to a random one.
+	Key func(task *asynq.Task) string
+}
+
+// streams is the Redis Streams backend of the task types registered with RegisterStream,
+// running alongside asynq. Every partition is a stream read by the consumer group of all
+// instances, but only the instance holding the partition's lease reads it, one entry at
+// a time, so entries are processed in order. A failed entry blocks its partition until it
+// succeeds or runs out of retries, it is then moved to the dead letter stream of its type.
+//
+// Delivery is at least once: an instance lost mid-task leaves the entry pending, the next
+// owner of the partition claims it and runs it again. Handlers of stream tasks must be
+// idempotent, GetTaskID returns the same ID on every delivery to deduplicate side effects
+// by. Tasks enqueued with asynq.TaskID are rejected with asynq.ErrTaskIDConflict while an
+// earlier one with the same ID is remembered (jobs.streams.id_retention).
+type streams struct {
+	client   *redis.Client
+	keys     keys.Space
+	logger   *zerolog.Logger
+	policies map[string]RetryPolicy
+	cfg      config.StreamsConfig
+	dead     *deadTasks
+	clock    clock.Clock
+	consumer string
+
+	mu          sync.Mutex
+	routes      map[string]StreamOptions
+	handler     asynq.Handler
+	readCtx     context.Context
+	tasksCtx    context.Context
+	cancelRead  context.CancelFunc
+	cancelTasks context.CancelFunc
+	workers     sync.WaitGroup
+}
+
+func newStreams(client *redis.Client, space keys.Space, logger *zerolog.Logger, policies map[string]RetryPolicy, cfg config.StreamsConfig, dead *deadTasks, clk clock.Clock) *streams {
+	host, _ := os.Hostname()
+
+	return &streams{
+		client:   client,
+		keys:     space,
+		logger:   logger,
+		policies: policies,
+		cfg:      cfg,
+		dead:     dead,
+		clock:    clk,
+		// Unique per process, a restarted instance must not take over the pending
+		// entries of its previous run without holding the lease.
+		consumer: host + "-" + uuid.NewString()[:8],
+		routes:   make(map[string]StreamOptions),
+	}
+}
+
+// routed reports whether taskType is processed from streams. streams is nil with the
+// postgres backend.
+func (s *streams) routed(taskType string) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.routes[taskType]
+	return ok
+}
+
+// route sends taskType to streams from now on. Its partitions are worked on right away
+// if the backend has started.
+func (s *streams) route(taskType string, opts StreamOptions) error {
+	if opts.Partitions < 1 {
+		opts.Partitions = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.routes[taskType]; ok {
+		return fmt.Errorf("stream task %s is already registered", taskType)
+	}
+	s.routes[taskType] = opts
+
+	if s.handler != nil {
+		s.startWorkers(taskType, opts.Partitions)
+	}
+	return nil
+}
+
+func (s *streams) streamKey(taskType string, partition int) string {
+	return s.keys.Key(taskType, strconv.Itoa(partition))
+}
+
+func (s *streams) partition(task *asynq.Task) int {
+	s.mu.Lock()
+	opts := s.routes[task.Type()]
+	s.mu.Unlock()
+
+	if opts.Partitions == 1 {
+		return 0
+	}
+
+	var key string
+	if opts.Key != nil {
+		key = opts.Key(task)
+	}
+	if key == "" {
+		return rand.IntN(opts.Partitions)
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(opts.Partitions))
+}
+
+// enqueue appends task to its partition. Streams process entries in order, so unique
+// and delayed tasks aren't supported.
+func (s *streams) enqueue(ctx context.Context, task *asynq.Task, opts []asynq.Option) (*asynq.TaskInfo, error) {
+	now := s.clock.Now()
+	o := resolveOptions(opts, now)
+
+	if o.unique > 0 || o.processAt.After(now) {
+		return nil, fmt.Errorf("stream task %s can't be unique or delayed", task.Type())
+	}
+
+	if o.taskID != "" {
+		ok, err := s.client.SetNX(ctx, s.keys.Key(task.Type(), "id", o.taskID), 1, s.cfg.IDRetention).Result()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, asynq.ErrTaskIDConflict
+		}
+	}
+
+	values := map[string]any{
+		"payload":   task.Payload(),
+		"task_id":   o.taskID,
+		"max_retry": o.maxRetry,
+		"timeout":   o.timeout.Milliseconds(),
+	}
+	if !o.deadline.IsZero() {
+		values["deadline"] = o.deadline.UnixMilli()
+	}
+
+	stream := s.streamKey(task.Type(), s.partition(task))
+	entryID, err := s.client.XAdd(ctx, &redis.XAddArgs{Stream: stream, Values: values}).Result()
+	if err != nil {
+		if o.taskID != "" {
+			s.client.Del(ctx, s.keys.Key(task.Type(), "id", o.taskID))
+		}
+		return nil, err
+	}
+
+	id := o.taskID
+	if id == "" {
+		id = entryID
+	}
+
+	return &asynq.TaskInfo{
+		ID:            id,
+		Queue:         stream,
+		Type:          task.Type(),
+		Payload:       task.Payload(),
+		State:         asynq.TaskStatePending,
+		MaxRetry:      o.maxRetry,
+		Timeout:       o.timeout,
+		Deadline:      o.deadline,
+		NextProcessAt: now,
+	}, nil
+}
+
+// start works on the partitions of every registered task type, entries are dispatched
+// to handler.
+func (s *streams) start(handler asynq.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.readCtx, s.cancelRead = context.WithCancel(context.Background())   //nolint:forbidigo // background read loops, stopped by stop
+	s.tasksCtx, s.cancelTasks = context.WithCancel(context.Background()) //nolint:forbidigo // running tasks, cancelled by stop
+	s.handler = handler
+
+	for taskType, opts := range s.routes {
+		s.startWorkers(taskType, opts.Partitions)
+	}
+}
+
+// startWorkers must be called with mu held.
+func (s *streams) startWorkers(taskType string, partitions int) {
+	for partition := range partitions {
+		s.workers.Add(1)
+		go s.work(taskType, partition)
+	}
+}
+
+// stop stops reading and waits for the running tasks, which release their partition
+// once done. Tasks still running after the shutdown timeout are cancelled and stay
+// pending for the next owner of their partition.
+func (s *streams) stop() {
+	s.mu.Lock()
+	if s.handler == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.cancelRead()
+	s.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-s.clock.After(s.cfg.ShutdownTimeout):
+		s.logger.Warn().Msg("stream workers didn't finish in time, cancelling running tasks")
+		s.cancelTasks()
+		<-finished
+	}
+	s.cancelTasks()
+}
+
+// work holds the lease of one partition whenever it's free and processes its entries
+// while holding it.
+func (s *streams) work(taskType string, partition int) {
+	defer s.workers.Done()
+
+	stream := s.streamKey(taskType, partition)
+	lease := s.keys.Key(taskType, strconv.Itoa(partition), "lease")
+	logger := s.logger.With().Str("type", taskType).Int("partition", partition).Logger()
+
+	for {
+		acquired, err := s.client.SetNX(s.readCtx, lease, s.consumer, s.cfg.LeaseTTL).Result()
+		if err != nil && s.readCtx.Err() == nil {
+			logger.Error().Err(err).Msg("failed to acquire stream partition lease")
+		}
+
+		if acquired {
+			s.own(taskType, stream, lease, &logger)
+		}
+
+		select {
+		case <-s.readCtx.Done():
+			return
+		case <-s.clock.After(s.cfg.LeaseTTL / 3):
+		}
+	}
+}
+
+// own processes the partition until the lease is lost or the backend stops, then
+// releases the lease.
+func (s *streams) own(taskType, stream, lease string, logger *zerolog.Logger) {
+	logger.Debug().Msg("acquired stream partition")
+
+	// The lease is renewed while a task runs, so cancelling leaseCtx (once the lease is
+	// lost) stops the task, the new owner runs it again.
+	leaseCtx, loseLease := context.WithCancel(s.tasksCtx)
+	defer loseLease()
+
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		s.renew(leaseCtx, lease, logger)
+		loseLease()
+	}()
+
+	defer func() {
+		loseLease()
+		<-renewed
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second) //nolint:forbidigo // the lease is released after reading stopped
+		defer cancel()
+		if err := releaseLeaseScript.Run(ctx, s.client, []string{lease}, s.consumer).Err(); err != nil {
+			logger.Warn().Err(err).Msg("failed to release stream partition lease")
+		}
+	}()
+
+	if err := s.client.XGroupCreateMkStream(leaseCtx, stream, streamGroup, "0").Err(); err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		logger.Error().Err(err).Msg("failed to create stream consumer group")
+		return
+	}
+	s.removeIdleConsumers(leaseCtx, stream, logger)
+
+	for s.readCtx.Err() == nil && leaseCtx.Err() == nil {
+		// Reads wait at most the block timeout, an entry read after the lease was lost
+		// isn't run but stays pending for the new owner.
+		messages, err := s.read(s.readCtx, stream)
+		if err != nil {
+			if s.readCtx.Err() == nil {
+				logger.Error().Err(err).Msg("failed to read stream")
+			}
+			return
+		}
+
+		for _, message := range messages {
+			if s.readCtx.Err() != nil || !s.process(leaseCtx, taskType, stream, message, logger) {
+				return
+			}
+		}
+	}
+}
+
+// renew extends the lease until ctx is done or the lease was lost.
+func (s *streams) renew(ctx context.Context, lease string, logger *zerolog.Logger) {
+	ticker := s.clock.NewTicker(s.cfg.LeaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+
+		renewed, err := renewLeaseScript.Run(ctx, s.client, []string{lease}, s.consumer, s.cfg.LeaseTTL.Milliseconds()).Int()
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error().Err(err).Msg("failed to renew stream partition lease, giving it up")
+			}
+			return
+		}
+		if renewed == 0 {
+			logger.Warn().Msg("lost stream partition lease")
+			return
+		}
+	}
+}
+
+// read returns the entries to process next in order: first the entries still pending
+// (claimed from the previous owner of the partition or left by a failed read), then new
+// ones, waiting up to the block timeout for them.
+func (s *streams) read(ctx context.Context, stream string) ([]redis.XMessage, error) {
+	// Entries delivered to another consumer are pending for nobody holding the lease,
+	// they are taken over before anything newer.
+	start := "0-0"
+	for {
+		_, next, err := s.client.XAutoClaimJustID(ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    streamGroup,
+			Consumer: s.consumer,
+			Start:    start,
+			Count:    100,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		if next == "0-0" {
+			break
+		}
+		start = next
+	}
+
+	for _, id := range []string{"0", ">"} {
+		result, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    streamGroup,
+			Consumer: s.consumer,
+			Streams:  []string{stream, id},
+			Count:    streamReadCount,
+			Block:    s.cfg.BlockTimeout,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(result) > 0 && len(result[0].Messages) > 0 {
+			return result[0].Messages, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// removeIdleConsumers deletes the consumers of instances that are gone. Their pending
+// entries were claimed already, consumers with pending entries are kept regardless.
+func (s *streams) removeIdleConsumers(ctx context.Context, stream string, logger *zerolog.Logger) {
+	consumers, err := s.client.XInfoConsumers(ctx, stream, streamGroup).Result()
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to list stream consumers")
+		return
+	}
+
+	for _, consumer := range consumers {
+		if consumer.Name == s.consumer || consumer.Pending > 0 || consumer.Idle < s.cfg.LeaseTTL {
+			continue
+		}
+		if err := s.client.XGroupDelConsumer(ctx, stream, streamGroup, consumer.Name).Err(); err != nil {
+			logger.Warn().Err(err).Str("consumer", consumer.Name).Msg("failed to remove idle stream consumer")
+		}
+	}
+}
+
+// streamEntry is a task read from a stream.
+type streamEntry struct {
+	entryID  string
+	taskID   string
+	taskType string
+	payload  []byte
+	maxRetry int
+	timeout  time.Duration
+	deadline time.Time
+}
+
+func parseStreamEntry(taskType string, message redis.XMessage) streamEntry {
+	field := func(name string) string {
+		value, _ := message.Values[name].(string)
+		return value
+	}
+
+	entry := streamEntry{
+		entryID:  message.ID,
+		taskID:   field("task_id"),
+		taskType: taskType,
+		payload:  []byte(field("payload")),
+		maxRetry: defaultMaxRetry,
+	}
+	if entry.taskID == "" {
+		entry.taskID = message.ID
+	}
+	if maxRetry, err := strconv.Atoi(field("max_retry")); err == nil {
+		entry.maxRetry = maxRetry
+	}
+	if timeout, err := strconv.ParseInt(field("timeout"), 10, 64); err == nil {
+		entry.timeout = time.Duration(timeout) * time.Millisecond
+	}
+	if deadline, err := strconv.ParseInt(field("deadline"), 10, 64); err == nil {
+		entry.deadline = time.UnixMilli(deadline)
+	}
+
+	return entry
+}
+
+// process runs an entry until it succeeds or runs out of retries, retrying in place so
+// later entries of the partition wait for it. It returns false if the partition must be
+// given up, the entry then stays pending.
+func (s *streams) process(ctx context.Context, taskType, stream string, message redis.XMessage, logger *zerolog.Logger) bool {
+	entry := parseStreamEntry(taskType, message)
+
+	taskLogger := logger.With().Str("task_id", entry.taskID).Str("entry_id", entry.entryID).Logger()
+
+	for retried := 0; ; retried++ {
+		err := s.run(ctx, entry, retried)
+		if err == nil {
+			return s.acknowledge(stream, entry, "", &taskLogger)
+		}
+
+		if ctx.Err() != nil {
+			taskLogger.Warn().Msg("stream task interrupted, leaving it pending")
+			return false
+		}
+
+		if retried >= entry.maxRetry || errors.Is(err, asynq.SkipRetry) {
+			taskLogger.Error().Err(err).Int("retried", retried).Msg("stream task failed, moving it to the dead letter stream")
+			if !s.acknowledge(stream, entry, err.Error(), &taskLogger) {
+				return false
+			}
+
+			s.dead.report(DeadTask{
+				ID:       entry.taskID,
+				Type:     taskType,
+				Queue:    stream,
+				Retried:  retried,
+				MaxRetry: entry.maxRetry,
+				Error:    err.Error(),
+			}, entry.payload)
+			return true
+		}
+
+		delay := retryDelay(s.policies, taskType, retried, err)
+		taskLogger.Error().Err(err).Int("retried", retried).Dur("retry_in", delay).Msg("stream task failed, retrying")
+
+		select {
+		case <-s.readCtx.Done():
+			return false
+		case <-ctx.Done():
+			return false
+		case <-s.clock.After(delay):
+		}
+	}
+}
+
+func (s *streams) run(ctx context.Context, entry streamEntry, retried int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	// Context deadlines run on the wall clock, not on the queue's clock
+	deadline := time.Now().Add(defaultTaskTimeout)
+	if entry.timeout > 0 {
+		deadline = time.Now().Add(entry.timeout)
+	}
+	if !entry.deadline.IsZero() && (entry.timeout == 0 || entry.deadline.Before(deadline)) {
+		deadline = entry.deadline
+	}
+
+	taskCtx, cancel := context.WithDeadline(withTaskMetadata(ctx, &taskMetadata{
+		id:       entry.taskID,
+		retried:  retried,
+		maxRetry: entry.maxRetry,
+		writeResult: func(ctx context.Context, data []byte) error {
+			retention := policyFor(s.policies, entry.taskType).Retention
+			if retention == 0 {
+				retention = s.cfg.IDRetention
+			}
+			return s.client.Set(ctx, s.keys.Key(entry.taskType, "result", entry.taskID), data, retention).Err()
+		},
+	}), deadline)
+	defer cancel()
+
+	// A deadline may have passed while the task waited in its stream.
+	if err := taskCtx.Err(); err != nil {
+		return err
+	}
+
+	return s.handler.ProcessTask(taskCtx, asynq.NewTask(entry.taskType, entry.payload))
+}
+
+// acknowledge removes a processed entry from its stream, entries that failed (lastError
+// is set) are added to the dead letter stream of their type in the same transaction.
+func (s *streams) acknowledge(stream string, entry streamEntry, lastError string, logger *zerolog.Logger) bool {
+	// The outcome is recorded even when reading stopped in the meantime.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) //nolint:forbidigo // outlives the task context
+	defer cancel()
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if lastError != "" {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: s.keys.Key(entry.taskType, "dead"),
+				Values: map[string]any{
+					"stream":     stream,
+					"entry_id":   entry.entryID,
+					"task_id":    entry.taskID,
+					"payload":    entry.payload,
+					"last_error": lastError,
+				},
+			})
+		}
+		pipe.XAck(ctx, stream, streamGroup, entry.entryID)
+		pipe.XDel(ctx, stream, entry.entryID)
+		return nil
+	})
+	if err != nil {
+		// The entry stays pending and runs again, the handler is idempotent.
+		logger.Error().Err(err).Msg("failed to acknowledge stream task")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,68 @@
+package job
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hibiken/asynq"
+)
+
+// taskMetadata describes the task being processed by the postgres backend, asynq
+// keeps the same information in the task context where only asynq can put it.
+type taskMetadata struct {
+	id          string
+	retried     int
+	maxRetry    int
+	writeResult func(ctx context.Context, data []byte) error
+}
+
+type taskMetadataKey struct{}
+
+func withTaskMetadata(ctx context.Context, metadata *taskMetadata) context.Context {
+	return context.WithValue(ctx, taskMetadataKey{}, metadata)
+}
+
+func taskMetadataFrom(ctx context.Context) (*taskMetadata, bool) {
+	metadata, ok := ctx.Value(taskMetadataKey{}).(*taskMetadata)
+	return metadata, ok
+}
+
+// GetTaskID returns the ID of the task being processed, on either backend.
+// Handlers use it instead of asynq.GetTaskID.
+func GetTaskID(ctx context.Context) (string, bool) {
+	if metadata, ok := taskMetadataFrom(ctx); ok {
+		return metadata.id, true
+	}
+	return asynq.GetTaskID(ctx)
+}
+
+// GetRetryCount returns how often the task being processed was retried so far.
+func GetRetryCount(ctx context.Context) (int, bool) {
+	if metadata, ok := taskMetadataFrom(ctx); ok {
+		return metadata.retried, true
+	}
+	return asynq.GetRetryCount(ctx)
+}
+
+// GetMaxRetry returns how often the task being processed may be retried.
+func GetMaxRetry(ctx context.Context) (int, bool) {
+	if metadata, ok := taskMetadataFrom(ctx); ok {
+		return metadata.maxRetry, true
+	}
+	return asynq.GetMaxRetry(ctx)
+}
+
+// WriteResult stores data as the result of task, kept for the retention of its type.
+// Handlers use it instead of the task's ResultWriter, which only asynq sets.
+func WriteResult(ctx context.Context, t *asynq.Task, data []byte) error {
+	if metadata, ok := taskMetadataFrom(ctx); ok {
+		return metadata.writeResult(ctx, data)
+	}
+
+	if w := t.ResultWriter(); w != nil {
+		_, err := w.Write(data)
+		return err
+	}
+
+	return errors.New("task has no result writer")
+}
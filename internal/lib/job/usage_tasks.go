@@ -0,0 +1,58 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	TaskUsageRollup         = "usage:rollup"
+	TaskDeprecationDispatch = "usage:deprecation_dispatch"
+	TaskDeprecationNotice   = "email:deprecation_notice"
+)
+
+type UsageRollupTaskPayload struct {
+	Counts []model.UsageCount `json:"counts"`
+}
+
+type DeprecationNoticeTaskPayload struct {
+	UserID string    `json:"user_id"` // recipient user
+	Since  time.Time `json:"since"`   // start of the period deprecated calls are reported for
+}
+
+// NewUsageRollupTask creates a task adding counts to the usage rollup.
+func NewUsageRollupTask(counts []model.UsageCount) (*Task, error) {
+	jsonPayload, err := json.Marshal(UsageRollupTaskPayload{Counts: counts})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTask(TaskUsageRollup, jsonPayload, asynq.Timeout(time.Minute), asynq.Queue(QueueFor(TaskUsageRollup))), nil
+}
+
+// NewDeprecationDispatchTask creates the periodic task that enqueues one deprecation notice
+// per user still calling deprecated routes. It is unique for most of lookback, so the
+// schedulers of several instances enqueue it only once.
+func NewDeprecationDispatchTask(lookback time.Duration) *Task {
+	return NewTask(TaskDeprecationDispatch, nil, asynq.Timeout(10*time.Minute), asynq.Queue(QueueFor(TaskDeprecationDispatch)), asynq.Unique(lookback/2))
+}
+
+// NewDeprecationNoticeTask creates a task notifying one user of the deprecated routes they
+// called since since. The task ID makes re-dispatching the same period a no-op.
+func NewDeprecationNoticeTask(userID string, since time.Time) (*Task, error) {
+	jsonPayload, err := json.Marshal(DeprecationNoticeTaskPayload{
+		UserID: userID,
+		Since:  since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	taskID := fmt.Sprintf("deprecation_notice:%s:%d", userID, since.Unix())
+
+	return NewTask(TaskDeprecationNotice, jsonPayload, asynq.TaskID(taskID), asynq.Timeout(time.Minute), asynq.Queue(QueueFor(TaskDeprecationNotice))), nil
+}
@@ -0,0 +1,23 @@
+package keys
+
+import "encoding/json"
+
+// Codec serializes the values stores keep in Redis.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSON is the default codec, values stay readable with redis-cli. A more compact
+// codec such as msgpack can be swapped in by implementing Codec.
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
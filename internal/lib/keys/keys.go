@@ -0,0 +1,77 @@
+// Package keys holds the Redis conventions of the API. Every key is namespaced as
+// app:env:component:..., so apps and environments sharing a Redis can't collide and
+// each component owns its part of the keyspace. It also keeps the default TTLs of the
+// stores and the codec values are serialized with in one place.
+package keys
+
+import (
+	"strings"
+	"time"
+)
+
+// Components owning a part of the keyspace.
+const (
+	RateLimit      = "ratelimit"
+	Quota          = "quota"
+	BruteForce     = "bruteforce"
+	Cache          = "cache"
+	Backup         = "backup"
+	BatchEmail     = "batch_email"
+	Notify         = "notify"
+	ScheduledEmail = "scheduled_email"
+	Progress       = "progress"
+	SendRate       = "sendrate"
+	JobStreams     = "job_streams"
+	RouteToggles   = "route_toggles"
+	Verification   = "verification"
+)
+
+// Default TTLs of the stores. Keys without a natural expiry still get one, so nothing
+// lingers in Redis after the code using it is gone.
+const (
+	// CacheTTL applies to cached values set without a TTL of their own.
+	CacheTTL = time.Hour
+	// ProgressTTL bounds how long the progress of a background task is kept for its retries.
+	ProgressTTL = 7 * 24 * time.Hour
+	// StatusTTL bounds how long the status of a periodic job is kept, well beyond any schedule.
+	StatusTTL = 30 * 24 * time.Hour
+)
+
+const separator = ":"
+
+// Namespace is the keyspace of one app in one environment.
+type Namespace struct {
+	prefix string
+}
+
+// New returns the namespace of app in env, e.g. New("marketmind", "production").
+func New(app, env string) Namespace {
+	return Namespace{
+		prefix: app + separator + env,
+	}
+}
+
+// Space returns the keyspace of component.
+func (n Namespace) Space(component string) Space {
+	return Space{
+		prefix: n.prefix + separator + component,
+	}
+}
+
+// Space is the keyspace of one component.
+type Space struct {
+	prefix string
+}
+
+// Key joins parts into a key of the component, e.g. "marketmind:production:quota:daily:20240102:user_1".
+func (s Space) Key(parts ...string) string {
+	return s.prefix + separator + strings.Join(parts, separator)
+}
+
+// Prefix returns the prefix every key starting with parts shares, for SCAN patterns.
+func (s Space) Prefix(parts ...string) string {
+	if len(parts) == 0 {
+		return s.prefix + separator
+	}
+	return s.Key(parts...) + separator
+}
@@ -0,0 +1,98 @@
+// Package latency accounts where a request spends its time. The request middleware
+// attaches a Breakdown to the request context, the database tracer and the Transport
+// of outgoing HTTP clients add the time spent on queries and external calls to it,
+// so blown latency budgets can be logged with a breakdown instead of a single number.
+package latency
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+type contextKey struct{}
+
+// Breakdown accumulates time spent outside the handler's own code. It is safe for
+// concurrent use, e.g. by queries running in parallel goroutines.
+type Breakdown struct {
+	db            atomic.Int64
+	dbQueries     atomic.Int64
+	external      atomic.Int64
+	externalCalls atomic.Int64
+}
+
+// Snapshot is a copy of a Breakdown's totals.
+type Snapshot struct {
+	DB            time.Duration
+	DBQueries     int64
+	External      time.Duration
+	ExternalCalls int64
+}
+
+// WithBreakdown returns ctx carrying a new Breakdown.
+func WithBreakdown(ctx context.Context) (context.Context, *Breakdown) {
+	b := &Breakdown{}
+	return context.WithValue(ctx, contextKey{}, b), b
+}
+
+// FromContext returns the Breakdown of ctx, nil outside a tracked request. All
+// methods of a nil Breakdown are no-ops, so callers don't need to check.
+func FromContext(ctx context.Context) *Breakdown {
+	b, _ := ctx.Value(contextKey{}).(*Breakdown)
+	return b
+}
+
+// AddDB records a database query that took d.
+func (b *Breakdown) AddDB(d time.Duration) {
+	if b == nil {
+		return
+	}
+	b.db.Add(int64(d))
+	b.dbQueries.Add(1)
+}
+
+// AddExternal records a call to an external service that took d.
+func (b *Breakdown) AddExternal(d time.Duration) {
+	if b == nil {
+		return
+	}
+	b.external.Add(int64(d))
+	b.externalCalls.Add(1)
+}
+
+// Snapshot returns the totals recorded so far.
+func (b *Breakdown) Snapshot() Snapshot {
+	if b == nil {
+		return Snapshot{}
+	}
+
+	return Snapshot{
+		DB:            time.Duration(b.db.Load()),
+		DBQueries:     b.dbQueries.Load(),
+		External:      time.Duration(b.external.Load()),
+		ExternalCalls: b.externalCalls.Load(),
+	}
+}
+
+// Transport records the duration of every request it sends as external call time
+// of the Breakdown in the request's context.
+//
+//	client := &http.Client{Transport: &latency.Transport{}}
+type Transport struct {
+	// Base sends the requests, http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	FromContext(req.Context()).AddExternal(time.Since(start))
+
+	return resp, err
+}
@@ -0,0 +1,276 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/rs/zerolog"
+)
+
+// Names of the dependencies the health prober checks, as listed in health_check.checks.
+const (
+	HealthCheckDatabase = "database"
+	HealthCheckRedis    = "redis"
+	// HealthCheckServer is the readiness of the instance itself, down while it starts up.
+	// Draining isn't reported, it is part of every rollout.
+	HealthCheckServer = "server"
+)
+
+// defaultHealthCheckTimeout bounds a check when health_check.timeout is unset, as /health does.
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// DependencyHealth is the outcome of the latest check of one dependency.
+type DependencyHealth struct {
+	Healthy   bool          `json:"healthy"`
+	Latency   time.Duration `json:"latency"`
+	Error     string        `json:"error,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+	// Since is when the dependency last changed between healthy and unhealthy.
+	Since time.Time `json:"since"`
+}
+
+// HealthCheck reports whether a dependency is up, it must return within ctx's deadline.
+type HealthCheck func(ctx context.Context) error
+
+// HealthProber checks the dependencies of the instance on a fixed interval, the same
+// checks /health runs on request, and publishes the results so dashboards and alerts
+// don't have to scrape the endpoint: per dependency a Custom/Health/<name>/Up gauge (1 or
+// 0) and a Custom/Health/<name>/LatencyMs gauge in New Relic, a DependencyHealth event
+// through the configured event exporter (New Relic or OTLP), and a HealthStateChanged
+// event whenever a dependency goes down or recovers.
+type HealthProber struct {
+	newRelicApp *newrelic.Application
+	logger      *zerolog.Logger
+	interval    time.Duration
+	timeout     time.Duration
+	enabled     map[string]bool
+
+	mu     sync.RWMutex
+	names  []string
+	checks map[string]HealthCheck
+	latest map[string]DependencyHealth
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewHealthProber creates a prober for the checks listed in cfg, every registered check
+// is run if the list is empty.
+func NewHealthProber(cfg config.HealthCheckConfig, newRelicApp *newrelic.Application, logger *zerolog.Logger) *HealthProber {
+	var enabled map[string]bool
+	if len(cfg.Checks) > 0 {
+		enabled = make(map[string]bool, len(cfg.Checks))
+		for _, name := range cfg.Checks {
+			enabled[name] = true
+		}
+	}
+
+	interval := cfg.Interval
+	if !cfg.Enabled {
+		interval = 0
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	return &HealthProber{
+		newRelicApp: newRelicApp,
+		logger:      logger,
+		interval:    interval,
+		timeout:     timeout,
+		enabled:     enabled,
+		checks:      make(map[string]HealthCheck),
+		latest:      make(map[string]DependencyHealth),
+	}
+}
+
+// Register adds the check of a dependency. Checks not listed in health_check.checks are ignored.
+func (hp *HealthProber) Register(name string, check HealthCheck) {
+	if hp.enabled != nil && !hp.enabled[name] {
+		return
+	}
+
+	hp.mu.Lock()
+	defer hp.mu.Unlock()
+
+	if _, ok := hp.checks[name]; !ok {
+		hp.names = append(hp.names, name)
+	}
+	hp.checks[name] = check
+}
+
+// Start begins periodic probing in a background goroutine, probing once right away.
+// It is a no-op when health checks are disabled or the interval is zero.
+func (hp *HealthProber) Start() {
+	if hp.interval <= 0 || hp.stop != nil {
+		return
+	}
+
+	hp.stop = make(chan struct{})
+	hp.done = make(chan struct{})
+
+	go func() {
+		defer close(hp.done)
+
+		hp.Probe()
+
+		ticker := time.NewTicker(hp.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				hp.Probe()
+			case <-hp.stop:
+				return
+			}
+		}
+	}()
+
+	hp.logger.Info().Dur("interval", hp.interval).Strs("checks", hp.names).Msg("health prober started")
+}
+
+// Stop halts periodic probing and waits for the background goroutine to exit.
+func (hp *HealthProber) Stop() {
+	if hp.stop == nil {
+		return
+	}
+
+	close(hp.stop)
+	<-hp.done
+	hp.stop = nil
+}
+
+// Snapshot returns the latest result of every dependency, keyed by name.
+func (hp *HealthProber) Snapshot() map[string]DependencyHealth {
+	hp.mu.RLock()
+	defer hp.mu.RUnlock()
+
+	snapshot := make(map[string]DependencyHealth, len(hp.latest))
+	for name, health := range hp.latest {
+		snapshot[name] = health
+	}
+
+	return snapshot
+}
+
+// Probe runs every check once, concurrently, and publishes the results.
+func (hp *HealthProber) Probe() map[string]DependencyHealth {
+	hp.mu.RLock()
+	names := append([]string(nil), hp.names...)
+	checks := make([]HealthCheck, len(names))
+	for i, name := range names {
+		checks[i] = hp.checks[name]
+	}
+	hp.mu.RUnlock()
+
+	results := make([]DependencyHealth, len(names))
+
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = hp.run(checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i, name := range names {
+		hp.publish(name, results[i])
+	}
+
+	return hp.Snapshot()
+}
+
+func (hp *HealthProber) run(check HealthCheck) DependencyHealth {
+	ctx, cancel := context.WithTimeout(context.Background(), hp.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+
+	health := DependencyHealth{
+		Healthy:   err == nil,
+		Latency:   time.Since(start),
+		CheckedAt: time.Now().UTC(),
+	}
+	if err != nil {
+		health.Error = err.Error()
+	}
+
+	return health
+}
+
+// publish stores the result of a dependency, records its metrics and reports a change of state.
+func (hp *HealthProber) publish(name string, health DependencyHealth) {
+	hp.mu.Lock()
+	previous, seen := hp.latest[name]
+	health.Since = health.CheckedAt
+	if seen && previous.Healthy == health.Healthy {
+		health.Since = previous.Since
+	}
+	hp.latest[name] = health
+	hp.mu.Unlock()
+
+	up := 0.0
+	if health.Healthy {
+		up = 1
+	}
+	hp.record("Custom/Health/"+name+"/Up", up)
+	hp.record("Custom/Health/"+name+"/LatencyMs", float64(health.Latency.Milliseconds()))
+
+	observe.Event("DependencyHealth", map[string]any{
+		"dependency": name,
+		"healthy":    health.Healthy,
+		"latency_ms": health.Latency.Milliseconds(),
+	})
+
+	// The first result is a change too when the dependency starts out down
+	if (!seen && health.Healthy) || (seen && previous.Healthy == health.Healthy) {
+		return
+	}
+
+	from, to := healthState(previous.Healthy, seen), healthState(health.Healthy, true)
+	attrs := map[string]any{
+		"dependency": name,
+		"from":       from,
+		"to":         to,
+		"latency_ms": health.Latency.Milliseconds(),
+	}
+	if seen {
+		attrs["previous_duration_ms"] = health.CheckedAt.Sub(previous.Since).Milliseconds()
+	}
+
+	if health.Healthy {
+		hp.logger.Info().Str("dependency", name).Str("from", from).Str("to", to).Msg("dependency recovered")
+	} else {
+		attrs["error_message"] = health.Error
+		hp.logger.Error().Str("dependency", name).Str("from", from).Str("to", to).Str("error", health.Error).Msg("dependency unhealthy")
+	}
+
+	observe.Event("HealthStateChanged", attrs)
+}
+
+func healthState(healthy, seen bool) string {
+	switch {
+	case !seen:
+		return "unknown"
+	case healthy:
+		return "healthy"
+	default:
+		return "unhealthy"
+	}
+}
+
+func (hp *HealthProber) record(name string, value float64) {
+	if hp.newRelicApp != nil {
+		hp.newRelicApp.RecordCustomMetric(name, value)
+	}
+}
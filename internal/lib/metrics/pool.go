@@ -0,0 +1,253 @@
+// Package metrics periodically collects runtime statistics of the application's
+// connection pools and publishes them as metrics. The latest snapshot is kept in memory
+// and served to internal scrapers at /internal/pool-stats.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// DatabasePoolStats is a point-in-time view of the pgx connection pool.
+type DatabasePoolStats struct {
+	AcquiredConns        int32         `json:"acquired_conns"`
+	IdleConns            int32         `json:"idle_conns"`
+	TotalConns           int32         `json:"total_conns"`
+	MaxConns             int32         `json:"max_conns"`
+	AcquireCount         int64         `json:"acquire_count"`
+	EmptyAcquireCount    int64         `json:"empty_acquire_count"`
+	CanceledAcquireCount int64         `json:"canceled_acquire_count"`
+	EmptyAcquireWaitTime time.Duration `json:"empty_acquire_wait_time"`
+	// AverageAcquireWait is the average time spent waiting for a connection
+	// during the last collection interval.
+	AverageAcquireWait time.Duration `json:"average_acquire_wait"`
+}
+
+// RedisPoolStats is a point-in-time view of the go-redis connection pool.
+type RedisPoolStats struct {
+	Hits       uint32 `json:"hits"`
+	Misses     uint32 `json:"misses"`
+	Timeouts   uint32 `json:"timeouts"`
+	TotalConns uint32 `json:"total_conns"`
+	IdleConns  uint32 `json:"idle_conns"`
+	StaleConns uint32 `json:"stale_conns"`
+	WaitCount  uint32 `json:"wait_count"`
+	// AverageWait is the average time spent waiting for a connection
+	// during the last collection interval.
+	AverageWait time.Duration `json:"average_wait"`
+}
+
+// PoolSnapshot holds the stats of every pool collected in one run.
+type PoolSnapshot struct {
+	CollectedAt time.Time          `json:"collected_at"`
+	Database    *DatabasePoolStats `json:"database,omitempty"`
+	Redis       *RedisPoolStats    `json:"redis,omitempty"`
+}
+
+// PoolCollector samples the database and Redis pools on a fixed interval,
+// records the values as New Relic custom metrics and warns when acquiring a
+// connection takes longer than the configured threshold.
+type PoolCollector struct {
+	dbPool      *pgxpool.Pool
+	redisClient *redis.Client
+	newRelicApp *newrelic.Application
+	logger      *zerolog.Logger
+	interval    time.Duration
+	threshold   time.Duration
+
+	mu       sync.RWMutex
+	latest   PoolSnapshot
+	previous poolCounters
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// poolCounters keeps the cumulative counters of the previous run so
+// averages can be computed per interval instead of since startup.
+type poolCounters struct {
+	dbAcquireCount    int64
+	dbAcquireDuration time.Duration
+	redisWaitCount    uint32
+	redisWaitDuration time.Duration
+}
+
+// NewPoolCollector creates a collector for the given pools. Either pool may be nil.
+func NewPoolCollector(cfg config.MetricsConfig, dbPool *pgxpool.Pool, redisClient *redis.Client, newRelicApp *newrelic.Application, logger *zerolog.Logger) *PoolCollector {
+	return &PoolCollector{
+		dbPool:      dbPool,
+		redisClient: redisClient,
+		newRelicApp: newRelicApp,
+		logger:      logger,
+		interval:    cfg.PoolCollectionInterval,
+		threshold:   cfg.PoolAcquireWaitThreshold,
+	}
+}
+
+// Start begins periodic collection in a background goroutine.
+// It is a no-op when the collection interval is zero.
+func (pc *PoolCollector) Start() {
+	if pc.interval <= 0 || pc.stop != nil {
+		return
+	}
+
+	pc.stop = make(chan struct{})
+	pc.done = make(chan struct{})
+
+	go func() {
+		defer close(pc.done)
+
+		ticker := time.NewTicker(pc.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				pc.Collect()
+			case <-pc.stop:
+				return
+			}
+		}
+	}()
+
+	pc.logger.Info().Dur("interval", pc.interval).Msg("pool metrics collector started")
+}
+
+// Stop halts periodic collection and waits for the background goroutine to exit.
+func (pc *PoolCollector) Stop() {
+	if pc.stop == nil {
+		return
+	}
+
+	close(pc.stop)
+	<-pc.done
+	pc.stop = nil
+}
+
+// Snapshot returns the most recently collected pool stats.
+func (pc *PoolCollector) Snapshot() PoolSnapshot {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	return pc.latest
+}
+
+// Collect samples every pool once, publishes the metrics and stores the snapshot.
+func (pc *PoolCollector) Collect() PoolSnapshot {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	snapshot := PoolSnapshot{CollectedAt: time.Now().UTC()}
+
+	if pc.dbPool != nil {
+		snapshot.Database = pc.collectDatabase()
+	}
+
+	if pc.redisClient != nil {
+		snapshot.Redis = pc.collectRedis()
+	}
+
+	pc.latest = snapshot
+
+	return snapshot
+}
+
+func (pc *PoolCollector) collectDatabase() *DatabasePoolStats {
+	stat := pc.dbPool.Stat()
+
+	acquires := stat.AcquireCount() - pc.previous.dbAcquireCount
+	waited := stat.AcquireDuration() - pc.previous.dbAcquireDuration
+	pc.previous.dbAcquireCount = stat.AcquireCount()
+	pc.previous.dbAcquireDuration = stat.AcquireDuration()
+
+	var averageWait time.Duration
+	if acquires > 0 {
+		averageWait = waited / time.Duration(acquires)
+	}
+
+	stats := &DatabasePoolStats{
+		AcquiredConns:        stat.AcquiredConns(),
+		IdleConns:            stat.IdleConns(),
+		TotalConns:           stat.TotalConns(),
+		MaxConns:             stat.MaxConns(),
+		AcquireCount:         stat.AcquireCount(),
+		EmptyAcquireCount:    stat.EmptyAcquireCount(),
+		CanceledAcquireCount: stat.CanceledAcquireCount(),
+		EmptyAcquireWaitTime: stat.EmptyAcquireWaitTime(),
+		AverageAcquireWait:   averageWait,
+	}
+
+	pc.record("Custom/Database/Pool/AcquiredConns", float64(stats.AcquiredConns))
+	pc.record("Custom/Database/Pool/IdleConns", float64(stats.IdleConns))
+	pc.record("Custom/Database/Pool/TotalConns", float64(stats.TotalConns))
+	pc.record("Custom/Database/Pool/MaxConns", float64(stats.MaxConns))
+	pc.record("Custom/Database/Pool/EmptyAcquireCount", float64(stats.EmptyAcquireCount))
+	pc.record("Custom/Database/Pool/AverageAcquireWaitMs", float64(averageWait.Milliseconds()))
+
+	if pc.threshold > 0 && averageWait > pc.threshold {
+		pc.logger.Warn().
+			Str("pool", "database").
+			Dur("average_acquire_wait", averageWait).
+			Dur("threshold", pc.threshold).
+			Int32("acquired_conns", stats.AcquiredConns).
+			Int32("max_conns", stats.MaxConns).
+			Msg("database pool acquire wait exceeds threshold")
+	}
+
+	return stats
+}
+
+func (pc *PoolCollector) collectRedis() *RedisPoolStats {
+	stat := pc.redisClient.PoolStats()
+
+	waits := stat.WaitCount - pc.previous.redisWaitCount
+	waited := time.Duration(stat.WaitDurationNs) - pc.previous.redisWaitDuration
+	pc.previous.redisWaitCount = stat.WaitCount
+	pc.previous.redisWaitDuration = time.Duration(stat.WaitDurationNs)
+
+	var averageWait time.Duration
+	if waits > 0 {
+		averageWait = waited / time.Duration(waits)
+	}
+
+	stats := &RedisPoolStats{
+		Hits:        stat.Hits,
+		Misses:      stat.Misses,
+		Timeouts:    stat.Timeouts,
+		TotalConns:  stat.TotalConns,
+		IdleConns:   stat.IdleConns,
+		StaleConns:  stat.StaleConns,
+		WaitCount:   stat.WaitCount,
+		AverageWait: averageWait,
+	}
+
+	pc.record("Custom/Redis/Pool/TotalConns", float64(stats.TotalConns))
+	pc.record("Custom/Redis/Pool/IdleConns", float64(stats.IdleConns))
+	pc.record("Custom/Redis/Pool/Timeouts", float64(stats.Timeouts))
+	pc.record("Custom/Redis/Pool/Misses", float64(stats.Misses))
+	pc.record("Custom/Redis/Pool/AverageWaitMs", float64(averageWait.Milliseconds()))
+
+	if pc.threshold > 0 && averageWait > pc.threshold {
+		pc.logger.Warn().
+			Str("pool", "redis").
+			Dur("average_wait", averageWait).
+			Dur("threshold", pc.threshold).
+			Uint32("total_conns", stats.TotalConns).
+			Uint32("timeouts", stats.Timeouts).
+			Msg("redis pool acquire wait exceeds threshold")
+	}
+
+	return stats
+}
+
+func (pc *PoolCollector) record(name string, value float64) {
+	if pc.newRelicApp != nil {
+		pc.newRelicApp.RecordCustomMetric(name, value)
+	}
+}
@@ -0,0 +1,201 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/latency"
+)
+
+const (
+	apnsProductionURL = "https://api.push.apple.com"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com"
+	// apnsTokenLifetime is how long a provider token is reused. Apple rejects tokens older
+	// than an hour and refreshing them more than every 20 minutes.
+	apnsTokenLifetime = 50 * time.Minute
+	// apnsMaxResponseSize bounds how much of an API response is read.
+	apnsMaxResponseSize = 1 << 16
+)
+
+// APNsOptions configure the APNs push provider with a token signing key (.p8) of the
+// Apple developer account.
+type APNsOptions struct {
+	TeamID string
+	KeyID  string
+	// PrivateKey is the PEM encoded signing key.
+	PrivateKey []byte
+	// Topic is the bundle ID of the app.
+	Topic string
+	// Sandbox sends to development builds of the app.
+	Sandbox bool
+	// BaseURL overrides the endpoint picked by Sandbox, e.g. for a local mock.
+	BaseURL string
+	Timeout time.Duration
+}
+
+// APNs sends push notifications straight to Apple's Push Notification service, over
+// HTTP/2 with a provider token signed by the account's key.
+type APNs struct {
+	opts       APNsOptions
+	key        *ecdsa.PrivateKey
+	baseURL    string
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+func NewAPNs(opts APNsOptions) (*APNs, error) {
+	if opts.TeamID == "" || opts.KeyID == "" || opts.Topic == "" {
+		return nil, errors.New("apns team id, key id and topic are required")
+	}
+
+	block, _ := pem.Decode(opts.PrivateKey)
+	if block == nil {
+		return nil, errors.New("apns private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse apns private key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("apns private key is not an ECDSA key")
+	}
+
+	baseURL := apnsProductionURL
+	if opts.Sandbox {
+		baseURL = apnsSandboxURL
+	}
+	if opts.BaseURL != "" {
+		baseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	}
+
+	return &APNs{
+		opts:    opts,
+		key:     key,
+		baseURL: baseURL,
+		// The default transport negotiates HTTP/2, which APNs requires
+		httpClient: &http.Client{Timeout: opts.Timeout, Transport: &latency.Transport{}},
+	}, nil
+}
+
+func (a *APNs) send(ctx context.Context, msg Message) (*Receipt, error) {
+	token, err := a.providerToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// Custom data sits next to the aps dictionary
+	notification := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{
+				"title": msg.Title,
+				"body":  msg.Body,
+			},
+		},
+	}
+	for key, value := range msg.Data {
+		if key != "aps" {
+			notification[key] = value
+		}
+	}
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/3/device/"+url.PathEscape(msg.To), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("apns-topic", a.opts.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach apns: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Reason string `json:"reason"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, apnsMaxResponseSize)).Decode(&failure)
+
+		err := fmt.Errorf("apns error: status %d: %s", resp.StatusCode, failure.Reason)
+		switch {
+		case resp.StatusCode == http.StatusGone, failure.Reason == "BadDeviceToken", failure.Reason == "DeviceTokenNotForTopic":
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRecipient, err)
+		case failure.Reason == "ExpiredProviderToken", failure.Reason == "InvalidProviderToken":
+			a.resetToken()
+		}
+		return nil, err
+	}
+
+	return &Receipt{
+		Provider:          "apns",
+		ProviderMessageID: resp.Header.Get("apns-id"),
+		Status:            StatusSent,
+	}, nil
+}
+
+// providerToken returns the current provider token, signing a new one once it is due.
+func (a *APNs) providerToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if a.token != "" && now.Sub(a.issuedAt) < apnsTokenLifetime {
+		return a.token, nil
+	}
+
+	token, err := signJWT(
+		map[string]string{"alg": "ES256", "kid": a.opts.KeyID},
+		map[string]any{"iss": a.opts.TeamID, "iat": now.Unix()},
+		func(digest []byte) ([]byte, error) {
+			r, s, err := ecdsa.Sign(rand.Reader, a.key, digest)
+			if err != nil {
+				return nil, err
+			}
+			// JWS wants the raw 64 byte r || s, not ASN.1
+			signature := make([]byte, 64)
+			r.FillBytes(signature[:32])
+			s.FillBytes(signature[32:])
+			return signature, nil
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign apns provider token: %w", err)
+	}
+
+	a.token = token
+	a.issuedAt = now
+
+	return token, nil
+}
+
+func (a *APNs) resetToken() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.token = ""
+}
@@ -0,0 +1,240 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/latency"
+)
+
+const (
+	fcmBaseURL = "https://fcm.googleapis.com/v1"
+	fcmScope   = "https://www.googleapis.com/auth/firebase.messaging"
+	// fcmTokenLifetime is how long requested access tokens live, Google's maximum.
+	fcmTokenLifetime = time.Hour
+	// fcmMaxResponseSize bounds how much of an API response is read.
+	fcmMaxResponseSize = 1 << 16
+)
+
+// fcmServiceAccount is the part of a Google service account key file FCM needs.
+type fcmServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCM sends push notifications through Firebase Cloud Messaging's HTTP v1 API. It
+// authenticates as a service account: a token signed with the account's key is traded
+// for an access token, which is reused until shortly before it expires.
+type FCM struct {
+	account    fcmServiceAccount
+	key        *rsa.PrivateKey
+	baseURL    string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCM returns an FCM provider for the service account key file credentials, as
+// downloaded from the Firebase console. An empty baseURL uses Google's endpoint.
+func NewFCM(credentials []byte, baseURL string, timeout time.Duration) (*FCM, error) {
+	var account fcmServiceAccount
+	if err := json.Unmarshal(credentials, &account); err != nil {
+		return nil, fmt.Errorf("failed to parse fcm credentials: %w", err)
+	}
+	if account.ProjectID == "" || account.ClientEmail == "" || account.TokenURI == "" {
+		return nil, errors.New("fcm credentials lack project_id, client_email or token_uri")
+	}
+
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, errors.New("fcm credentials hold no PEM private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fcm private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("fcm private key is not an RSA key")
+	}
+
+	if baseURL == "" {
+		baseURL = fcmBaseURL
+	}
+
+	return &FCM{
+		account:    account,
+		key:        key,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout, Transport: &latency.Transport{}},
+	}, nil
+}
+
+func (f *FCM) send(ctx context.Context, msg Message) (*Receipt, error) {
+	accessToken, err := f.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	message := map[string]any{
+		"token": msg.To,
+		"notification": map[string]string{
+			"title": msg.Title,
+			"body":  msg.Body,
+		},
+	}
+	if len(msg.Data) > 0 {
+		message["data"] = msg.Data
+	}
+
+	payload, err := json.Marshal(map[string]any{"message": message})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := f.baseURL + "/projects/" + url.PathEscape(f.account.ProjectID) + "/messages:send"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach fcm: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, fcmMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fcm response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fcmError(resp.StatusCode, body)
+	}
+
+	var sent struct {
+		// Name is "projects/<project>/messages/<id>"
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &sent); err != nil {
+		return nil, fmt.Errorf("failed to decode fcm response: %w", err)
+	}
+
+	return &Receipt{
+		Provider:          "fcm",
+		ProviderMessageID: sent.Name,
+		Status:            StatusSent,
+	}, nil
+}
+
+// fcmError turns an FCM error response into an error, wrapping ErrInvalidRecipient for
+// tokens that were unregistered or don't belong to the project.
+func fcmError(statusCode int, body []byte) error {
+	var envelope struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &envelope)
+
+	code := envelope.Error.Status
+	for _, detail := range envelope.Error.Details {
+		if detail.ErrorCode != "" {
+			code = detail.ErrorCode
+		}
+	}
+
+	err := fmt.Errorf("fcm error: status %d: %s: %s", statusCode, code, envelope.Error.Message)
+	switch code {
+	case "UNREGISTERED", "SENDER_ID_MISMATCH":
+		return fmt.Errorf("%w: %w", ErrInvalidRecipient, err)
+	default:
+		return err
+	}
+}
+
+// token returns a valid access token, requesting a new one when the cached one is
+// about to expire.
+func (f *FCM) token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	if f.accessToken != "" && now.Before(f.expiresAt.Add(-time.Minute)) {
+		return f.accessToken, nil
+	}
+
+	assertion, err := signJWT(
+		map[string]string{"alg": "RS256", "typ": "JWT"},
+		map[string]any{
+			"iss":   f.account.ClientEmail,
+			"scope": fcmScope,
+			"aud":   f.account.TokenURI,
+			"iat":   now.Unix(),
+			"exp":   now.Add(fcmTokenLifetime).Unix(),
+		},
+		func(digest []byte) ([]byte, error) {
+			return rsa.SignPKCS1v15(nil, f.key, crypto.SHA256, digest)
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign fcm token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach fcm token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fcm token request failed with status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, fcmMaxResponseSize)).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode fcm token response: %w", err)
+	}
+
+	f.accessToken = token.AccessToken
+	f.expiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+
+	return f.accessToken, nil
+}
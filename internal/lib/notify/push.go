@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+)
+
+// Push providers a device token can be issued by.
+const (
+	ProviderFCM  = "fcm"
+	ProviderAPNs = "apns"
+)
+
+// pushProvider sends to the device tokens it issued.
+type pushProvider interface {
+	send(ctx context.Context, msg Message) (*Receipt, error)
+}
+
+// Push is the push channel. It sends every message through the provider that issued
+// its device token, so FCM and APNs apps can be served side by side.
+type Push struct {
+	providers map[string]pushProvider
+}
+
+// NewPush returns the push channel of the given providers, either may be nil.
+func NewPush(fcm *FCM, apns *APNs) *Push {
+	p := &Push{providers: make(map[string]pushProvider)}
+	if fcm != nil {
+		p.providers[ProviderFCM] = fcm
+	}
+	if apns != nil {
+		p.providers[ProviderAPNs] = apns
+	}
+	return p
+}
+
+func (p *Push) Name() string {
+	return ChannelPush
+}
+
+// Supports reports whether tokens of provider can be sent to.
+func (p *Push) Supports(provider string) bool {
+	_, ok := p.providers[provider]
+	return ok
+}
+
+func (p *Push) Send(ctx context.Context, msg Message) (*Receipt, error) {
+	provider, ok := p.providers[msg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedProvider, msg.Provider)
+	}
+
+	return provider.send(ctx, msg)
+}
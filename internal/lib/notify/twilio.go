@@ -0,0 +1,183 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/latency"
+)
+
+const (
+	twilioBaseURL = "https://api.twilio.com/2010-04-01"
+	// twilioMaxResponseSize bounds how much of an API response is read.
+	twilioMaxResponseSize = 1 << 16
+)
+
+// twilioInvalidRecipientCodes are Twilio error codes that won't go away on retry, e.g.
+// 21211 invalid "To" number, 21610 recipient unsubscribed (replied STOP), 21614 not a mobile number.
+var twilioInvalidRecipientCodes = map[int]bool{21211: true, 21408: true, 21610: true, 21612: true, 21614: true}
+
+// TwilioOptions configure the Twilio SMS channel. Messages are sent from From, or through
+// the messaging service, which picks a sender of its pool, when MessagingServiceSID is set.
+type TwilioOptions struct {
+	AccountSID          string
+	AuthToken           string
+	From                string
+	MessagingServiceSID string
+	// BaseURL overrides the API endpoint, e.g. for a local mock.
+	BaseURL string
+	Timeout time.Duration
+}
+
+// Twilio sends SMS through Twilio's Messages API.
+type Twilio struct {
+	opts       TwilioOptions
+	baseURL    string
+	httpClient *http.Client
+}
+
+// TwilioError is an error returned by the Twilio API.
+type TwilioError struct {
+	StatusCode int
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *TwilioError) Error() string {
+	return fmt.Sprintf("twilio error: status %d: %d: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func NewTwilio(opts TwilioOptions) (*Twilio, error) {
+	if opts.AccountSID == "" || opts.AuthToken == "" {
+		return nil, errors.New("twilio account sid and auth token are required")
+	}
+	if opts.From == "" && opts.MessagingServiceSID == "" {
+		return nil, errors.New("twilio requires a from number or a messaging service sid")
+	}
+
+	baseURL := twilioBaseURL
+	if opts.BaseURL != "" {
+		baseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	}
+
+	return &Twilio{
+		opts:       opts,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: opts.Timeout, Transport: &latency.Transport{}},
+	}, nil
+}
+
+func (t *Twilio) Name() string {
+	return ChannelSMS
+}
+
+func (t *Twilio) Send(ctx context.Context, msg Message) (*Receipt, error) {
+	form := url.Values{
+		"To":   {msg.To},
+		"Body": {msg.Body},
+	}
+	if t.opts.MessagingServiceSID != "" {
+		form.Set("MessagingServiceSid", t.opts.MessagingServiceSID)
+	} else {
+		form.Set("From", t.opts.From)
+	}
+	if msg.StatusCallbackURL != "" {
+		form.Set("StatusCallback", msg.StatusCallbackURL)
+	}
+
+	endpoint := t.baseURL + "/Accounts/" + url.PathEscape(t.opts.AccountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(t.opts.AccountSID, t.opts.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, twilioMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read twilio response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		twilioErr := &TwilioError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(body, twilioErr); err != nil {
+			twilioErr.Message = http.StatusText(resp.StatusCode)
+		}
+		if twilioInvalidRecipientCodes[twilioErr.Code] {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRecipient, twilioErr)
+		}
+		return nil, twilioErr
+	}
+
+	var message struct {
+		SID    string `json:"sid"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &message); err != nil {
+		return nil, fmt.Errorf("failed to decode twilio response: %w", err)
+	}
+
+	return &Receipt{
+		Provider:          "twilio",
+		ProviderMessageID: message.SID,
+		Status:            twilioStatus(message.Status),
+	}, nil
+}
+
+// StatusUpdate is a delivery update reported by a status callback.
+type StatusUpdate struct {
+	ProviderMessageID string
+	Status            string
+	// Error describes why the message wasn't delivered, empty otherwise.
+	Error string
+}
+
+// ParseTwilioStatusCallback parses the form of a Twilio status callback. The signature
+// must have been verified already, see webhook.SchemeTwilio.
+func ParseTwilioStatusCallback(form url.Values) (*StatusUpdate, error) {
+	sid := form.Get("MessageSid")
+	status := form.Get("MessageStatus")
+	if sid == "" || status == "" {
+		return nil, errors.New("twilio status callback without MessageSid or MessageStatus")
+	}
+
+	update := &StatusUpdate{
+		ProviderMessageID: sid,
+		Status:            twilioStatus(status),
+	}
+	if code := form.Get("ErrorCode"); code != "" {
+		update.Error = "twilio error " + code
+	}
+
+	return update, nil
+}
+
+// twilioStatus maps a Twilio message status to a delivery status.
+func twilioStatus(status string) string {
+	switch status {
+	case "sent":
+		return StatusSent
+	case "delivered", "read":
+		return StatusDelivered
+	case "undelivered":
+		return StatusUndelivered
+	case "failed", "canceled":
+		return StatusFailed
+	default:
+		// accepted, scheduled, queued and sending
+		return StatusQueued
+	}
+}
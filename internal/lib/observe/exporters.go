@@ -0,0 +1,162 @@
+package observe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/latency"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// NewRelicExporter records events as New Relic custom events. The agent batches and
+// harvests them itself.
+type NewRelicExporter struct {
+	app *newrelic.Application
+}
+
+func NewNewRelicExporter(app *newrelic.Application) *NewRelicExporter {
+	return &NewRelicExporter{app: app}
+}
+
+func (n *NewRelicExporter) Export(_ context.Context, records []Record) error {
+	for _, record := range records {
+		n.app.RecordCustomEvent(record.Name, record.Attrs)
+	}
+	return nil
+}
+
+// NopExporter discards every event.
+type NopExporter struct{}
+
+func (NopExporter) Export(context.Context, []Record) error {
+	return nil
+}
+
+// OTLPExporter sends events as OTLP log records over HTTP with JSON encoding, to a
+// collector or any backend accepting OTLP. The event name is the record's event_name
+// and body, its attributes the record's attributes.
+type OTLPExporter struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	environment string
+	httpClient  *http.Client
+}
+
+// NewOTLPExporter returns an exporter posting to endpoint, the collector's base URL
+// (e.g. http://otel-collector:4318), with headers added to every request.
+func NewOTLPExporter(endpoint string, headers map[string]string, serviceName, environment string, timeout time.Duration) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint:    strings.TrimSuffix(endpoint, "/") + "/v1/logs",
+		headers:     headers,
+		serviceName: serviceName,
+		environment: environment,
+		httpClient:  &http.Client{Timeout: timeout, Transport: &latency.Transport{}},
+	}
+}
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	EventName    string         `json:"eventName"`
+	SeverityText string         `json:"severityText"`
+	Body         map[string]any `json:"body"`
+	Attributes   []otlpKeyValue `json:"attributes"`
+}
+
+func (o *OTLPExporter) Export(ctx context.Context, records []Record) error {
+	logRecords := make([]otlpLogRecord, len(records))
+	for i, record := range records {
+		attributes := make([]otlpKeyValue, 0, len(record.Attrs)+1)
+		attributes = append(attributes, otlpKeyValue{Key: "event.name", Value: otlpValue(record.Name)})
+		for key, value := range record.Attrs {
+			attributes = append(attributes, otlpKeyValue{Key: key, Value: otlpValue(value)})
+		}
+
+		logRecords[i] = otlpLogRecord{
+			TimeUnixNano: strconv.FormatInt(record.Time.UnixNano(), 10),
+			EventName:    record.Name,
+			SeverityText: "INFO",
+			Body:         otlpValue(record.Name),
+			Attributes:   attributes,
+		}
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"resourceLogs": []any{map[string]any{
+			"resource": map[string]any{
+				"attributes": []otlpKeyValue{
+					{Key: "service.name", Value: otlpValue(o.serviceName)},
+					{Key: "deployment.environment", Value: otlpValue(o.environment)},
+				},
+			},
+			"scopeLogs": []any{map[string]any{
+				"scope":      map[string]string{"name": "observe"},
+				"logRecords": logRecords,
+			}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range o.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach otlp endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("otlp export failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// otlpValue encodes an attribute value as an OTLP AnyValue, 64-bit integers as strings
+// as the JSON encoding requires.
+func otlpValue(value any) map[string]any {
+	switch v := value.(type) {
+	case string:
+		return map[string]any{"stringValue": v}
+	case bool:
+		return map[string]any{"boolValue": v}
+	case int:
+		return map[string]any{"intValue": strconv.FormatInt(int64(v), 10)}
+	case int32:
+		return map[string]any{"intValue": strconv.FormatInt(int64(v), 10)}
+	case int64:
+		return map[string]any{"intValue": strconv.FormatInt(v, 10)}
+	case float32:
+		return map[string]any{"doubleValue": float64(v)}
+	case float64:
+		return map[string]any{"doubleValue": v}
+	case time.Time:
+		return map[string]any{"stringValue": v.Format(time.RFC3339Nano)}
+	case fmt.Stringer:
+		return map[string]any{"stringValue": v.String()}
+	default:
+		return map[string]any{"stringValue": fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,237 @@
+// Package observe records structured events (rate limit hits, slow requests, backups, ...)
+// without tying the code emitting them to one APM vendor. Code calls Event; the default
+// Emitter batches events in the background and hands them to an Exporter: New Relic
+// custom events, OTLP log records, or nothing at all.
+//
+// Events are soft rate limited: every event name may emit MaxPerSecond events each
+// second at full rate, beyond that, and whenever the buffer is filling up, events are
+// sampled. Sampled events carry a sample_rate attribute so counts can be weighted back.
+package observe
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// SampleRateAttribute is set on sampled events to the fraction of events of that name kept.
+const SampleRateAttribute = "sample_rate"
+
+// Record is one event as handed to an Exporter.
+type Record struct {
+	Name  string
+	Time  time.Time
+	Attrs map[string]any
+}
+
+// Exporter ships batches of events to a backend.
+type Exporter interface {
+	Export(ctx context.Context, records []Record) error
+}
+
+// Options tune how an Emitter batches and samples events.
+type Options struct {
+	// BufferSize is how many events may wait for export, events are dropped beyond it.
+	BufferSize int
+	// BatchSize is the most events exported at once.
+	BatchSize int
+	// FlushInterval is how often a partial batch is exported.
+	FlushInterval time.Duration
+	// MaxPerSecond is how many events of one name are kept each second before sampling.
+	MaxPerSecond int
+	// SampleRate is the fraction of events kept once sampling.
+	SampleRate float64
+}
+
+// Emitter buffers events and exports them in batches from a background goroutine.
+type Emitter struct {
+	exporter Exporter
+	opts     Options
+	logger   *zerolog.Logger
+
+	queue chan Record
+	stop  chan struct{}
+	done  chan struct{}
+
+	mu      sync.Mutex
+	seconds map[string]*nameWindow
+
+	dropped atomic.Int64
+	sampled atomic.Int64
+}
+
+// nameWindow counts the events of a name within the current second.
+type nameWindow struct {
+	second int64
+	count  int
+}
+
+func NewEmitter(exporter Exporter, opts Options, logger *zerolog.Logger) *Emitter {
+	return &Emitter{
+		exporter: exporter,
+		opts:     opts,
+		logger:   logger,
+		queue:    make(chan Record, opts.BufferSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		seconds:  make(map[string]*nameWindow),
+	}
+}
+
+// Event records an event of name with attrs. It never blocks: when the buffer is full
+// the event is dropped.
+func (e *Emitter) Event(name string, attrs map[string]any) {
+	now := time.Now()
+
+	rate, keep := e.sample(name, now)
+	if !keep {
+		e.sampled.Add(1)
+		return
+	}
+	if rate < 1 {
+		copied := make(map[string]any, len(attrs)+1)
+		for key, value := range attrs {
+			copied[key] = value
+		}
+		copied[SampleRateAttribute] = rate
+		attrs = copied
+	}
+
+	select {
+	case e.queue <- Record{Name: name, Time: now, Attrs: attrs}:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// sample decides whether to keep an event and at which rate it was sampled, 1 when
+// the event is under its name's limit and the buffer is less than half full.
+func (e *Emitter) sample(name string, now time.Time) (float64, bool) {
+	second := now.Unix()
+
+	e.mu.Lock()
+	window, ok := e.seconds[name]
+	if !ok {
+		window = &nameWindow{}
+		e.seconds[name] = window
+	}
+	if window.second != second {
+		window.second = second
+		window.count = 0
+	}
+	window.count++
+	overLimit := e.opts.MaxPerSecond > 0 && window.count > e.opts.MaxPerSecond
+	e.mu.Unlock()
+
+	underLoad := len(e.queue) > cap(e.queue)/2
+	if !overLimit && !underLoad {
+		return 1, true
+	}
+
+	return e.opts.SampleRate, rand.Float64() < e.opts.SampleRate
+}
+
+// Start exports events in the background until Shutdown.
+func (e *Emitter) Start() {
+	go e.run()
+}
+
+func (e *Emitter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Record, 0, e.opts.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			e.export(batch)
+			batch = batch[:0]
+		}
+		e.reportLosses()
+	}
+
+	for {
+		select {
+		case record := <-e.queue:
+			batch = append(batch, record)
+			if len(batch) >= e.opts.BatchSize {
+				e.export(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stop:
+			// Drain what was recorded before the shutdown
+			for {
+				select {
+				case record := <-e.queue:
+					batch = append(batch, record)
+					if len(batch) >= e.opts.BatchSize {
+						e.export(batch)
+						batch = batch[:0]
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *Emitter) export(batch []Record) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := e.exporter.Export(ctx, batch); err != nil {
+		e.logger.Warn().Err(err).Int("events", len(batch)).Msg("failed to export events")
+	}
+
+	// Forget names not seen this second, so the map doesn't grow with one-off names
+	second := time.Now().Unix()
+	e.mu.Lock()
+	for name, window := range e.seconds {
+		if window.second < second-1 {
+			delete(e.seconds, name)
+		}
+	}
+	e.mu.Unlock()
+}
+
+// reportLosses logs how many events were sampled out or dropped since the last report.
+func (e *Emitter) reportLosses() {
+	dropped := e.dropped.Swap(0)
+	sampled := e.sampled.Swap(0)
+	if dropped > 0 || sampled > 0 {
+		e.logger.Warn().Int64("dropped", dropped).Int64("sampled_out", sampled).Msg("events not exported under load")
+	}
+}
+
+// Shutdown exports the buffered events and stops the Emitter.
+func (e *Emitter) Shutdown(ctx context.Context) {
+	close(e.stop)
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+	}
+}
+
+var defaultEmitter atomic.Pointer[Emitter]
+
+// SetDefault makes e the Emitter of Event.
+func SetDefault(e *Emitter) {
+	defaultEmitter.Store(e)
+}
+
+// Event records an event on the default Emitter. Until one is set events are discarded.
+func Event(name string, attrs map[string]any) {
+	if e := defaultEmitter.Load(); e != nil {
+		e.Event(name, attrs)
+	}
+}
@@ -0,0 +1,264 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDocumentSize caps the discovery document and key set read from the provider.
+const maxDocumentSize = 1 << 20
+
+// keySet caches the signing keys of the provider, keyed by key ID. Keys are refetched
+// once the refresh interval passed and, at most once per cooldown, when a token names a
+// key that isn't known yet: providers publish a new key before signing with it, so a
+// rotation is picked up by the first token signed with the new key.
+type keySet struct {
+	client          *http.Client
+	issuer          string
+	jwksURL         string
+	refreshInterval time.Duration
+	cooldown        time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+
+	// fetching serializes fetches, concurrent lookups of an unknown key wait for one fetch.
+	fetching sync.Mutex
+}
+
+// key returns the key with ID kid. Without a kid the key set must hold a single key.
+func (s *keySet) key(ctx context.Context, kid string, now time.Time) (crypto.PublicKey, error) {
+	s.mu.RLock()
+	key, ok := s.lookup(kid)
+	stale := now.Sub(s.fetchedAt) > s.refreshInterval
+	s.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if err := s.refresh(ctx, now, !ok); err != nil {
+		// Keep verifying with the keys fetched before, the provider may be down briefly.
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// lookup must be called with mu held.
+func (s *keySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" {
+		if len(s.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// refresh fetches the keys unless another lookup fetched them meanwhile. A missing key
+// only triggers a fetch once per cooldown, so tokens naming random key IDs can't make
+// the API hammer the provider.
+func (s *keySet) refresh(ctx context.Context, now time.Time, missing bool) error {
+	s.fetching.Lock()
+	defer s.fetching.Unlock()
+
+	s.mu.RLock()
+	stale := now.Sub(s.fetchedAt) > s.refreshInterval
+	cooling := now.Sub(s.attemptedAt) < s.cooldown
+	s.mu.RUnlock()
+
+	if cooling || (!missing && !stale) {
+		return nil
+	}
+
+	return s.load(ctx, now)
+}
+
+// load fetches the keys and replaces the cached ones, it must be called with fetching held.
+func (s *keySet) load(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	s.attemptedAt = now
+	s.mu.Unlock()
+
+	keys, err := s.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fetchedAt = now
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *keySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	if s.jwksURL == "" {
+		jwksURL, err := s.discover(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.jwksURL = jwksURL
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := s.getJSON(ctx, s.jwksURL, &set); err != nil {
+		return nil, fmt.Errorf("oidc: failed to fetch keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		// Encryption keys and key types we can't verify with are skipped, not fatal.
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("oidc: %s holds no usable signing key", s.jwksURL)
+	}
+	return keys, nil
+}
+
+// discover reads the JWKS endpoint from the discovery document of the issuer.
+func (s *keySet) discover(ctx context.Context) (string, error) {
+	var document struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := s.getJSON(ctx, strings.TrimSuffix(s.issuer, "/")+"/.well-known/openid-configuration", &document); err != nil {
+		return "", fmt.Errorf("oidc: failed to fetch discovery document: %w", err)
+	}
+
+	// The document must be the issuer's own, see OpenID Connect Discovery 1.0 section 4.3.
+	if document.Issuer != s.issuer {
+		return "", fmt.Errorf("oidc: discovery document is for issuer %q, want %q", document.Issuer, s.issuer)
+	}
+	if document.JWKSURI == "" {
+		return "", fmt.Errorf("oidc: discovery document has no jwks_uri")
+	}
+	return document.JWKSURI, nil
+}
+
+func (s *keySet) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// jsonWebKey is a public key of a JWK Set (RFC 7517), RSA or EC.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 || n.BitLen() < 2048 {
+			return nil, fmt.Errorf("oidc: rsa key %s is too weak or malformed", k.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("oidc: unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("oidc: ec key %s is not on curve %s", k.Kid, k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("oidc: unsupported key type %q", k.Kty)
+	}
+}
+
+var curves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+func decodeBigInt(encoded string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("oidc: malformed key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,336 @@
+// Package oidc verifies the ID and access tokens of an OpenID Connect provider, e.g.
+// Keycloak or Auth0, so users can sign in without Clerk. Tokens are JWTs signed with
+// one of the provider's keys, which are found through its discovery document and
+// cached, rotations are picked up as tokens signed with new keys come in.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMissingToken         = errors.New("missing token")
+	ErrMalformedToken       = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("token signed with an unaccepted algorithm")
+	ErrUnknownKey           = errors.New("token signed with an unknown key")
+	ErrInvalidSignature     = errors.New("invalid token signature")
+	ErrWrongIssuer          = errors.New("token from another issuer")
+	ErrWrongAudience        = errors.New("token is for another audience")
+	ErrExpiredToken         = errors.New("token has expired or is not valid yet")
+)
+
+// algorithms maps the accepted JWS algorithms to their hash. Symmetric algorithms and
+// "none" are never accepted, the keys of the provider are public.
+var algorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"PS256": crypto.SHA256,
+	"PS384": crypto.SHA384,
+	"PS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// Options configures a Verifier.
+type Options struct {
+	// Issuer is the issuer URL of the provider, tokens must carry it as iss.
+	Issuer string
+	// JWKSURL is where the provider publishes its keys, read from the discovery
+	// document of Issuer when empty.
+	JWKSURL string
+	// Audiences are the accepted aud values, a token must be for at least one of them.
+	Audiences []string
+	// Algorithms are the accepted signing algorithms, RS256 when empty.
+	Algorithms []string
+	// RefreshInterval is how often the keys are refetched.
+	RefreshInterval time.Duration
+	// RefreshCooldown is the least time between fetches triggered by unknown keys.
+	RefreshCooldown time.Duration
+	// Leeway absorbs clock drift between the provider and the API.
+	Leeway time.Duration
+	// Client fetches the discovery document and keys, a client with a 10 second
+	// timeout when nil.
+	Client *http.Client
+}
+
+// Verifier checks the tokens of one provider.
+type Verifier struct {
+	issuer     string
+	audiences  []string
+	algorithms []string
+	leeway     time.Duration
+	keys       *keySet
+}
+
+// NewVerifier returns a Verifier for the provider described by opts. Keys are only
+// fetched once needed, or by Refresh.
+func NewVerifier(opts Options) (*Verifier, error) {
+	if opts.Issuer == "" {
+		return nil, fmt.Errorf("oidc: issuer is required")
+	}
+	if len(opts.Audiences) == 0 {
+		return nil, fmt.Errorf("oidc: at least one audience is required")
+	}
+
+	accepted := opts.Algorithms
+	if len(accepted) == 0 {
+		accepted = []string{"RS256"}
+	}
+	for _, algorithm := range accepted {
+		if _, ok := algorithms[algorithm]; !ok {
+			return nil, fmt.Errorf("oidc: unsupported algorithm %q", algorithm)
+		}
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Verifier{
+		issuer:     opts.Issuer,
+		audiences:  opts.Audiences,
+		algorithms: accepted,
+		leeway:     opts.Leeway,
+		keys: &keySet{
+			client:          client,
+			issuer:          opts.Issuer,
+			jwksURL:         opts.JWKSURL,
+			refreshInterval: opts.RefreshInterval,
+			cooldown:        opts.RefreshCooldown,
+		},
+	}, nil
+}
+
+// Refresh fetches the keys of the provider, so the first request doesn't wait for them.
+func (v *Verifier) Refresh(ctx context.Context) error {
+	v.keys.fetching.Lock()
+	defer v.keys.fetching.Unlock()
+
+	return v.keys.load(ctx, time.Now())
+}
+
+// Claims are the verified contents of a token.
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+	// Raw holds every claim, for provider specific ones such as roles.
+	Raw map[string]any
+}
+
+// String returns the string claim at path, nested claims are separated by dots, e.g.
+// "realm_access.role". Lists return their first string.
+func (c *Claims) String(path string) string {
+	switch value := c.lookup(path).(type) {
+	case string:
+		return value
+	case []any:
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// Strings returns the list of strings at path, a single string is a list of one and
+// space separated strings (like the scope claim) are split.
+func (c *Claims) Strings(path string) []string {
+	switch value := c.lookup(path).(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Bool reports whether the claim at path is true.
+func (c *Claims) Bool(path string) bool {
+	value, _ := c.lookup(path).(bool)
+	return value
+}
+
+func (c *Claims) lookup(path string) any {
+	if path == "" {
+		return nil
+	}
+
+	// Claims may be URLs containing dots themselves (Auth0 namespaces custom claims).
+	if value, ok := c.Raw[path]; ok {
+		return value
+	}
+
+	var value any = c.Raw
+	for _, part := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+	return value
+}
+
+// Verify checks token and returns its claims.
+func (v *Verifier) Verify(ctx context.Context, token string, now time.Time) (*Claims, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrMalformedToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if !slices.Contains(v.algorithms, header.Alg) {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	key, err := v.keys.key(ctx, header.Kid, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	return v.checkClaims(raw, now)
+}
+
+func (v *Verifier) checkClaims(raw map[string]any, now time.Time) (*Claims, error) {
+	claims := &Claims{Raw: raw}
+	claims.Subject = claims.String("sub")
+	claims.Issuer, _ = raw["iss"].(string)
+	claims.Audience = claims.Strings("aud")
+
+	if claims.Issuer != v.issuer {
+		return nil, ErrWrongIssuer
+	}
+
+	if !slices.ContainsFunc(claims.Audience, func(audience string) bool { return slices.Contains(v.audiences, audience) }) {
+		return nil, ErrWrongAudience
+	}
+
+	// exp is required, nbf optional.
+	exp, ok := raw["exp"].(float64)
+	if !ok {
+		return nil, ErrExpiredToken
+	}
+	claims.ExpiresAt = time.Unix(int64(exp), 0)
+	if now.Add(-v.leeway).After(claims.ExpiresAt) {
+		return nil, ErrExpiredToken
+	}
+	if nbf, ok := raw["nbf"].(float64); ok && now.Add(v.leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, ErrExpiredToken
+	}
+
+	if claims.Subject == "" {
+		return nil, ErrMalformedToken
+	}
+
+	return claims, nil
+}
+
+// verifySignature checks signature over signed with key. The key type must match the
+// algorithm, a token can't make an EC key verify RSA signatures or the other way round.
+func verifySignature(algorithm string, key crypto.PublicKey, signed string, signature []byte) error {
+	digest := newHash(algorithms[algorithm])
+	digest.Write([]byte(signed))
+	sum := digest.Sum(nil)
+
+	switch algorithm[:2] {
+	case "RS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPKCS1v15(rsaKey, algorithms[algorithm], sum, signature) != nil {
+			return ErrInvalidSignature
+		}
+	case "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPSS(rsaKey, algorithms[algorithm], sum, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) != nil {
+			return ErrInvalidSignature
+		}
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		// JWS signatures are r and s concatenated, each the size of the curve.
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, sum, r, s) {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrUnsupportedAlgorithm
+	}
+
+	return nil
+}
+
+func newHash(h crypto.Hash) hash.Hash {
+	switch h {
+	case crypto.SHA384:
+		return sha512.New384()
+	case crypto.SHA512:
+		return sha512.New()
+	default:
+		return sha256.New()
+	}
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
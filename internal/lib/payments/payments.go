@@ -0,0 +1,106 @@
+// Package payments takes payments through a provider, Stripe for now. Customers pay in
+// the provider's hosted checkout, and the provider reports what happened afterwards
+// (subscriptions started, renewed, cancelled) through webhooks. Those events are parsed
+// into provider independent types, so the subscription state can be kept in Postgres.
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrUnknownProvider is returned by New for a provider without an implementation.
+	ErrUnknownProvider = errors.New("unknown payments provider")
+	// ErrInvalidEvent is returned when a webhook body can't be parsed as an event.
+	ErrInvalidEvent = errors.New("invalid payments event")
+)
+
+// Provider is a payments provider.
+type Provider interface {
+	// Name is the provider's name, also the webhook source its events are verified with.
+	Name() string
+	// CreateCustomer creates the customer payments of an owner are billed to.
+	CreateCustomer(ctx context.Context, params CustomerParams) (*Customer, error)
+	// CreateCheckoutSession starts a hosted checkout subscribing a customer to a price.
+	CreateCheckoutSession(ctx context.Context, params CheckoutParams) (*CheckoutSession, error)
+	// ParseEvent parses the body of a webhook. The signature must have been verified already.
+	ParseEvent(payload []byte) (*Event, error)
+}
+
+// CustomerParams describe a customer to create.
+type CustomerParams struct {
+	// OwnerID is who the customer pays for, stored with the customer at the provider.
+	OwnerID string
+	Email   string
+	Name    string
+	// IdempotencyKey makes retried requests return the first customer instead of a duplicate.
+	IdempotencyKey string
+}
+
+type Customer struct {
+	ID string
+}
+
+// CheckoutParams describe a checkout of a subscription.
+type CheckoutParams struct {
+	CustomerID string
+	OwnerID    string
+	PriceID    string
+	SuccessURL string
+	CancelURL  string
+}
+
+type CheckoutSession struct {
+	ID string
+	// URL is the hosted checkout page to send the customer to.
+	URL       string
+	ExpiresAt time.Time
+}
+
+// Event is something that happened at the provider, reported through a webhook.
+type Event struct {
+	// ID is unique per event, providers deliver events at least once so it is used to
+	// skip events that were already processed.
+	ID        string
+	Type      string
+	CreatedAt time.Time
+	// Subscription is the state of the subscription after the event, nil for events
+	// that aren't about a subscription.
+	Subscription *Subscription
+}
+
+// Subscription is the state of a subscription at the provider.
+type Subscription struct {
+	ID         string
+	CustomerID string
+	// OwnerID is the owner the subscription was checked out for, empty if it was
+	// created outside the app.
+	OwnerID string
+	// Status is the provider's status, e.g. trialing, active, past_due or canceled.
+	Status            string
+	PriceID           string
+	CurrentPeriodEnd  *time.Time
+	CancelAtPeriodEnd bool
+	CanceledAt        *time.Time
+}
+
+// Options configure a provider.
+type Options struct {
+	SecretKey string
+	// BaseURL overrides the provider's API endpoint, the default is used when empty.
+	BaseURL string
+	Timeout time.Duration
+}
+
+// New returns the provider named name.
+func New(name string, opts Options) (Provider, error) {
+	switch name {
+	case "stripe":
+		return NewStripe(opts)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownProvider, name)
+	}
+}
@@ -0,0 +1,249 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/latency"
+)
+
+const (
+	stripeBaseURL = "https://api.stripe.com/v1"
+	// stripeMaxResponseSize bounds how much of an API response is read.
+	stripeMaxResponseSize = 1 << 20
+)
+
+// Stripe talks to the Stripe API directly, its requests are simple form posts.
+type Stripe struct {
+	secretKey  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// StripeError is an error returned by the Stripe API.
+type StripeError struct {
+	StatusCode int
+	Type       string `json:"type"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *StripeError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("stripe error: status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("stripe error: status %d: %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+// NewStripe returns a Stripe provider using the secret API key of opts.
+func NewStripe(opts Options) (*Stripe, error) {
+	if opts.SecretKey == "" {
+		return nil, errors.New("stripe secret key is required")
+	}
+
+	baseURL := stripeBaseURL
+	if opts.BaseURL != "" {
+		baseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	}
+
+	return &Stripe{
+		secretKey:  opts.SecretKey,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: opts.Timeout, Transport: &latency.Transport{}},
+	}, nil
+}
+
+func (s *Stripe) Name() string {
+	return "stripe"
+}
+
+func (s *Stripe) CreateCustomer(ctx context.Context, params CustomerParams) (*Customer, error) {
+	form := url.Values{
+		"metadata[owner_id]": {params.OwnerID},
+	}
+	if params.Email != "" {
+		form.Set("email", params.Email)
+	}
+	if params.Name != "" {
+		form.Set("name", params.Name)
+	}
+
+	var customer struct {
+		ID string `json:"id"`
+	}
+	if err := s.post(ctx, "/customers", form, params.IdempotencyKey, &customer); err != nil {
+		return nil, fmt.Errorf("failed to create stripe customer: %w", err)
+	}
+
+	return &Customer{ID: customer.ID}, nil
+}
+
+func (s *Stripe) CreateCheckoutSession(ctx context.Context, params CheckoutParams) (*CheckoutSession, error) {
+	form := url.Values{
+		"mode":                    {"subscription"},
+		"customer":                {params.CustomerID},
+		"client_reference_id":     {params.OwnerID},
+		"line_items[0][price]":    {params.PriceID},
+		"line_items[0][quantity]": {"1"},
+		"success_url":             {params.SuccessURL},
+		"cancel_url":              {params.CancelURL},
+		// The owner travels with the subscription, so its events can be attributed even
+		// if the customer was created outside the app
+		"subscription_data[metadata][owner_id]": {params.OwnerID},
+	}
+
+	var session struct {
+		ID        string `json:"id"`
+		URL       string `json:"url"`
+		ExpiresAt int64  `json:"expires_at"`
+	}
+	if err := s.post(ctx, "/checkout/sessions", form, "", &session); err != nil {
+		return nil, fmt.Errorf("failed to create stripe checkout session: %w", err)
+	}
+
+	return &CheckoutSession{
+		ID:        session.ID,
+		URL:       session.URL,
+		ExpiresAt: time.Unix(session.ExpiresAt, 0).UTC(),
+	}, nil
+}
+
+// stripeEvent is the envelope of every Stripe webhook.
+type stripeEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+type stripeSubscription struct {
+	ID                string `json:"id"`
+	Object            string `json:"object"`
+	Customer          string `json:"customer"`
+	Status            string `json:"status"`
+	CancelAtPeriodEnd bool   `json:"cancel_at_period_end"`
+	CanceledAt        *int64 `json:"canceled_at"`
+	Metadata          struct {
+		OwnerID string `json:"owner_id"`
+	} `json:"metadata"`
+	// CurrentPeriodEnd moved to the subscription items in API version 2025-03-31,
+	// both are read so the webhook endpoint may use either version.
+	CurrentPeriodEnd *int64 `json:"current_period_end"`
+	Items            struct {
+		Data []struct {
+			CurrentPeriodEnd *int64 `json:"current_period_end"`
+			Price            struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// ParseEvent parses a Stripe event. Subscription events (customer.subscription.*) carry
+// the subscription, other events are returned without one.
+func (s *Stripe) ParseEvent(payload []byte) (*Event, error) {
+	var raw stripeEvent
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+	}
+	if raw.ID == "" || raw.Type == "" {
+		return nil, fmt.Errorf("%w: missing id or type", ErrInvalidEvent)
+	}
+
+	event := &Event{
+		ID:        raw.ID,
+		Type:      raw.Type,
+		CreatedAt: time.Unix(raw.Created, 0).UTC(),
+	}
+
+	if !strings.HasPrefix(raw.Type, "customer.subscription.") {
+		return event, nil
+	}
+
+	var sub stripeSubscription
+	if err := json.Unmarshal(raw.Data.Object, &sub); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+	}
+	if sub.Object != "subscription" || sub.ID == "" {
+		return nil, fmt.Errorf("%w: %s doesn't carry a subscription", ErrInvalidEvent, raw.Type)
+	}
+
+	subscription := &Subscription{
+		ID:                sub.ID,
+		CustomerID:        sub.Customer,
+		OwnerID:           sub.Metadata.OwnerID,
+		Status:            sub.Status,
+		CancelAtPeriodEnd: sub.CancelAtPeriodEnd,
+		CanceledAt:        unixTime(sub.CanceledAt),
+		CurrentPeriodEnd:  unixTime(sub.CurrentPeriodEnd),
+	}
+	if len(sub.Items.Data) > 0 {
+		item := sub.Items.Data[0]
+		subscription.PriceID = item.Price.ID
+		if subscription.CurrentPeriodEnd == nil {
+			subscription.CurrentPeriodEnd = unixTime(item.CurrentPeriodEnd)
+		}
+	}
+
+	event.Subscription = subscription
+
+	return event, nil
+}
+
+// post sends a form to the Stripe API and decodes the response into out.
+func (s *Stripe) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.secretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach stripe: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, stripeMaxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read stripe response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var envelope struct {
+			Error *StripeError `json:"error"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil || envelope.Error == nil {
+			return &StripeError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		}
+		envelope.Error.StatusCode = resp.StatusCode
+		return envelope.Error
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode stripe response: %w", err)
+	}
+
+	return nil
+}
+
+func unixTime(seconds *int64) *time.Time {
+	if seconds == nil {
+		return nil
+	}
+	t := time.Unix(*seconds, 0).UTC()
+	return &t
+}
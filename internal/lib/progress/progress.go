@@ -0,0 +1,182 @@
+// Package progress tracks the progress of long running operations, e.g. async exports
+// and imports, so clients can follow them live instead of polling. Job handlers report
+// updates, which are kept in Redis (the latest one per operation) and published on a
+// channel per operation, so a client connected to any instance receives them.
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/redis/go-redis/v9"
+)
+
+// States of an operation.
+const (
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateFailed    = "failed"
+)
+
+// Update is the progress of an operation at one point.
+type Update struct {
+	OperationID string `json:"operation_id"`
+	State       string `json:"state"`
+	// Percent is 0 to 100, -1 when the operation can't tell how far it is.
+	Percent   int       `json:"percent"`
+	Message   string    `json:"message,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Done reports whether the operation is over, no update follows.
+func (u Update) Done() bool {
+	return u.State == StateCompleted || u.State == StateFailed
+}
+
+// Tracker stores and publishes progress updates.
+type Tracker struct {
+	redis *redis.Client
+	keys  keys.Space
+}
+
+func NewTracker(client *redis.Client, space keys.Space) *Tracker {
+	return &Tracker{
+		redis: client,
+		keys:  space,
+	}
+}
+
+// Report records the progress of an operation, Percent is clamped to 0..100 unless -1.
+func (t *Tracker) Report(ctx context.Context, operationID, state string, percent int, message string) error {
+	if percent != -1 {
+		percent = min(max(percent, 0), 100)
+	}
+
+	update := Update{
+		OperationID: operationID,
+		State:       state,
+		Percent:     percent,
+		Message:     message,
+		UpdatedAt:   time.Now().UTC(),
+	}
+
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to encode progress update: %w", err)
+	}
+
+	pipe := t.redis.TxPipeline()
+	pipe.Set(ctx, t.keys.Key("latest", operationID), payload, keys.ProgressTTL)
+	pipe.Publish(ctx, t.channel(operationID), payload)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to report progress: %w", err)
+	}
+
+	return nil
+}
+
+// Latest returns the last update of an operation, nil if none was reported.
+func (t *Tracker) Latest(ctx context.Context, operationID string) (*Update, error) {
+	payload, err := t.redis.Get(ctx, t.keys.Key("latest", operationID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get progress: %w", err)
+	}
+
+	var update Update
+	if err := json.Unmarshal(payload, &update); err != nil {
+		return nil, fmt.Errorf("failed to decode progress update: %w", err)
+	}
+
+	return &update, nil
+}
+
+// Subscription receives the updates of one operation.
+type Subscription struct {
+	pubsub *redis.PubSub
+	// Updates delivers the latest update known when subscribing, if any, then every
+	// update reported since. It is closed once the operation is done or the
+	// subscription closed.
+	Updates <-chan Update
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() error {
+	return s.pubsub.Close()
+}
+
+// Subscribe follows the updates of an operation until ctx ends or it is done.
+func (t *Tracker) Subscribe(ctx context.Context, operationID string) (*Subscription, error) {
+	pubsub := t.redis.Subscribe(ctx, t.channel(operationID))
+
+	// Wait for the subscription before reading the latest update, so nothing reported
+	// in between is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to progress: %w", err)
+	}
+
+	latest, err := t.Latest(ctx, operationID)
+	if err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	updates := make(chan Update, 16)
+	go func() {
+		defer close(updates)
+
+		var last time.Time
+		deliver := func(update Update) bool {
+			// The latest update may also arrive on the channel
+			if !update.UpdatedAt.After(last) {
+				return true
+			}
+			last = update.UpdatedAt
+
+			select {
+			case updates <- update:
+			case <-ctx.Done():
+				return false
+			}
+			return !update.Done()
+		}
+
+		if latest != nil && !deliver(*latest) {
+			return
+		}
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var update Update
+				if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+					continue
+				}
+				if !deliver(update) {
+					return
+				}
+			}
+		}
+	}()
+
+	return &Subscription{pubsub: pubsub, Updates: updates}, nil
+}
+
+// channel is the Redis pub/sub channel the updates of an operation are published on.
+func (t *Tracker) channel(operationID string) string {
+	return t.keys.Key("updates", operationID)
+}
@@ -0,0 +1,265 @@
+// Package quota tracks daily and monthly request quotas per consumer (an API key,
+// organization or user) in Redis. Unlike the burst rate limiter it counts every
+// request of a billing period, so plans can be enforced.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/redis/go-redis/v9"
+)
+
+type Period string
+
+const (
+	PeriodDaily   Period = "daily"
+	PeriodMonthly Period = "monthly"
+)
+
+// Limits holds the number of requests allowed per period, zero means unlimited.
+type Limits struct {
+	Daily   int64
+	Monthly int64
+}
+
+// PeriodUsage is the usage of a consumer within one period.
+type PeriodUsage struct {
+	Period    Period    `json:"period"`
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// Usage is the current usage of a consumer. Exceeded names the period
+// whose quota is exhausted, it is empty while requests are allowed.
+type Usage struct {
+	Consumer string      `json:"consumer"`
+	Daily    PeriodUsage `json:"daily"`
+	Monthly  PeriodUsage `json:"monthly"`
+	Exceeded Period      `json:"exceeded,omitempty"`
+}
+
+// Counter is the raw value of one consumer's counter, used to snapshot usage to Postgres.
+type Counter struct {
+	Consumer    string
+	Period      Period
+	PeriodStart time.Time
+	Used        int64
+}
+
+// consumeScript checks both quotas and only increments the counters when neither is exhausted,
+// so rejected requests don't eat into the quota.
+// Returns {allowed, daily_used, monthly_used, exceeded (0 none, 1 daily, 2 monthly)}.
+var consumeScript = redis.NewScript(`
+local daily = tonumber(redis.call('GET', KEYS[1]) or '0')
+local monthly = tonumber(redis.call('GET', KEYS[2]) or '0')
+local dailyLimit = tonumber(ARGV[1])
+local monthlyLimit = tonumber(ARGV[2])
+
+if monthlyLimit > 0 and monthly >= monthlyLimit then
+	return {0, daily, monthly, 2}
+end
+if dailyLimit > 0 and daily >= dailyLimit then
+	return {0, daily, monthly, 1}
+end
+
+daily = redis.call('INCR', KEYS[1])
+if redis.call('TTL', KEYS[1]) < 0 then
+	redis.call('EXPIRE', KEYS[1], ARGV[3])
+end
+monthly = redis.call('INCR', KEYS[2])
+if redis.call('TTL', KEYS[2]) < 0 then
+	redis.call('EXPIRE', KEYS[2], ARGV[4])
+end
+
+return {1, daily, monthly, 0}
+`)
+
+type Tracker struct {
+	redis  *redis.Client
+	keys   keys.Space
+	limits Limits
+}
+
+// NewTracker returns a Tracker keeping its counters in space.
+func NewTracker(client *redis.Client, space keys.Space, limits Limits) *Tracker {
+	return &Tracker{
+		redis:  client,
+		keys:   space,
+		limits: limits,
+	}
+}
+
+// Limits returns the configured limits, which apply to consumers without limits of their own.
+func (t *Tracker) Limits() Limits {
+	return t.limits
+}
+
+// Consume counts one request for consumer if its quotas allow it, checked against limits
+// (e.g. the consumer's plan, Limits() otherwise).
+// The returned usage tells whether the request is allowed (Exceeded is empty).
+func (t *Tracker) Consume(ctx context.Context, consumer string, limits Limits, now time.Time) (*Usage, error) {
+	now = now.UTC()
+
+	counterKeys := []string{t.key(consumer, PeriodDaily, now), t.key(consumer, PeriodMonthly, now)}
+	args := []any{
+		limits.Daily,
+		limits.Monthly,
+		int64(counterTTL(PeriodDaily, now).Seconds()),
+		int64(counterTTL(PeriodMonthly, now).Seconds()),
+	}
+
+	result, err := consumeScript.Run(ctx, t.redis, counterKeys, args...).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume quota for %s: %w", consumer, err)
+	}
+
+	usage := usageOf(consumer, limits, result[1], result[2], now)
+	switch result[3] {
+	case 1:
+		usage.Exceeded = PeriodDaily
+	case 2:
+		usage.Exceeded = PeriodMonthly
+	}
+
+	return usage, nil
+}
+
+// Get returns the consumer's usage against limits without counting a request.
+func (t *Tracker) Get(ctx context.Context, consumer string, limits Limits, now time.Time) (*Usage, error) {
+	now = now.UTC()
+
+	values, err := t.redis.MGet(ctx, t.key(consumer, PeriodDaily, now), t.key(consumer, PeriodMonthly, now)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota usage for %s: %w", consumer, err)
+	}
+
+	return usageOf(consumer, limits, parseCount(values[0]), parseCount(values[1]), now), nil
+}
+
+// Seed sets a counter to used unless it already exists, restoring usage from a snapshot
+// after Redis lost its data. Existing counters are never lowered.
+func (t *Tracker) Seed(ctx context.Context, counter Counter, now time.Time) error {
+	key := t.key(counter.Consumer, counter.Period, counter.PeriodStart)
+	if err := t.redis.SetNX(ctx, key, counter.Used, counterTTL(counter.Period, now.UTC())).Err(); err != nil {
+		return fmt.Errorf("failed to seed quota counter %s: %w", key, err)
+	}
+	return nil
+}
+
+// Counters returns every counter of the current daily and monthly periods.
+func (t *Tracker) Counters(ctx context.Context, now time.Time) ([]Counter, error) {
+	now = now.UTC()
+
+	var counters []Counter
+	for _, period := range []Period{PeriodDaily, PeriodMonthly} {
+		prefix := t.keys.Prefix(string(period), periodLabel(period, now))
+
+		var keys []string
+		iter := t.redis.Scan(ctx, 0, prefix+"*", 500).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return nil, fmt.Errorf("failed to scan %s quota counters: %w", period, err)
+		}
+
+		if len(keys) == 0 {
+			continue
+		}
+
+		values, err := t.redis.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s quota counters: %w", period, err)
+		}
+
+		for i, key := range keys {
+			counters = append(counters, Counter{
+				Consumer:    key[len(prefix):],
+				Period:      period,
+				PeriodStart: PeriodStart(period, now),
+				Used:        parseCount(values[i]),
+			})
+		}
+	}
+
+	return counters, nil
+}
+
+func usageOf(consumer string, limits Limits, daily, monthly int64, now time.Time) *Usage {
+	return &Usage{
+		Consumer: consumer,
+		Daily:    periodUsage(PeriodDaily, limits.Daily, daily, now),
+		Monthly:  periodUsage(PeriodMonthly, limits.Monthly, monthly, now),
+	}
+}
+
+func periodUsage(period Period, limit, used int64, now time.Time) PeriodUsage {
+	remaining := int64(-1) // unlimited
+	if limit > 0 {
+		remaining = max(limit-used, 0)
+	}
+
+	return PeriodUsage{
+		Period:    period,
+		Limit:     limit,
+		Used:      used,
+		Remaining: remaining,
+		ResetsAt:  PeriodEnd(period, now),
+	}
+}
+
+// key returns the Redis key of a consumer's counter for the period containing at.
+func (t *Tracker) key(consumer string, period Period, at time.Time) string {
+	return t.keys.Key(string(period), periodLabel(period, at), consumer)
+}
+
+// periodLabel identifies the period containing at, e.g. "20240102" or "202401".
+func periodLabel(period Period, at time.Time) string {
+	format := "20060102"
+	if period == PeriodMonthly {
+		format = "200601"
+	}
+
+	return at.UTC().Format(format)
+}
+
+// PeriodStart returns the start of the period containing at, in UTC.
+func PeriodStart(period Period, at time.Time) time.Time {
+	at = at.UTC()
+	if period == PeriodMonthly {
+		return time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// PeriodEnd returns when the period containing at resets.
+func PeriodEnd(period Period, at time.Time) time.Time {
+	start := PeriodStart(period, at)
+	if period == PeriodMonthly {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// counterTTL keeps counters a day past their period, so late snapshots can still read them.
+func counterTTL(period Period, now time.Time) time.Duration {
+	return PeriodEnd(period, now).Sub(now) + 24*time.Hour
+}
+
+func parseCount(value any) int64 {
+	str, ok := value.(string)
+	if !ok {
+		return 0
+	}
+
+	var count int64
+	if _, err := fmt.Sscan(str, &count); err != nil {
+		return 0
+	}
+	return count
+}
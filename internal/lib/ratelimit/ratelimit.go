@@ -0,0 +1,82 @@
+// Package ratelimit limits requests per key (e.g. a tenant) across every instance,
+// counting them in Redis in fixed windows. Unlike the global per-IP limiter it is
+// shared by all instances, so limits hold no matter where requests are routed.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/clock"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/redis/go-redis/v9"
+)
+
+// allowScript increments the window's counter unless the limit is reached, so rejected
+// requests don't extend the time until the client is let through again.
+// Returns {allowed, count}.
+var allowScript = redis.NewScript(`
+local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+if count >= tonumber(ARGV[1]) then
+	return {0, count}
+end
+
+count = redis.call('INCR', KEYS[1])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+
+return {1, count}
+`)
+
+// Result is the outcome of a rate limit check.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetsAt  time.Time
+	// RetryAfter is how long until the window resets.
+	RetryAfter time.Duration
+}
+
+type Limiter struct {
+	redis  *redis.Client
+	keys   keys.Space
+	window time.Duration
+	clock  clock.Clock
+}
+
+// NewLimiter returns a Limiter counting requests in windows of the given length, as
+// told by clk, its counters are kept in space.
+func NewLimiter(client *redis.Client, space keys.Space, window time.Duration, clk clock.Clock) *Limiter {
+	return &Limiter{
+		redis:  client,
+		keys:   space,
+		window: window,
+		clock:  clock.OrReal(clk),
+	}
+}
+
+// Allow counts a request for key if fewer than limit requests were made in the current window.
+func (l *Limiter) Allow(ctx context.Context, key string, limit int) (*Result, error) {
+	windowStart := l.clock.Now().Truncate(l.window)
+
+	values, err := allowScript.Run(ctx, l.redis, []string{l.key(key, windowStart)}, limit, l.window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rate limit of %s: %w", key, err)
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		Remaining:  max(limit-int(values[1]), 0),
+		ResetsAt:   windowStart.Add(l.window),
+		RetryAfter: windowStart.Add(l.window).Sub(l.clock.Now()),
+	}, nil
+}
+
+func (l *Limiter) key(key string, windowStart time.Time) string {
+	return l.keys.Key(strconv.FormatInt(windowStart.Unix(), 10), key)
+}
@@ -0,0 +1,114 @@
+// Package requestctx carries who a call is made by and on whose behalf in its
+// context.Context, so services read it from the context they are given instead of
+// taking it as arguments or depending on echo. Handlers bridge from echo with From,
+// jobs and other entry points (e.g. gRPC) build the context with With.
+package requestctx
+
+import (
+	"context"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+// bridgedKey marks an echo context whose request context was already filled by From.
+const bridgedKey = "requestctx_bridged"
+
+type valuesKey struct{}
+
+type loggerKey struct{}
+
+// Values describe the caller of a request.
+type Values struct {
+	UserID         string
+	OrganizationID string
+	Role           string
+	// Operator is whether the caller is a platform operator, see middleware.RequireOperator.
+	Operator bool
+	// Tenant is who usage is accounted to: "org:<id>" for organization members,
+	// "user:<id>" otherwise, see middleware.GetQuotaConsumer.
+	Tenant string
+	// Locale is the caller's Accept-Language, used to localize generated content.
+	Locale    string
+	RequestID string
+}
+
+// With returns ctx carrying v. The request ID also tags the transactions started with
+// ctx, see database.WithRequestID.
+func With(ctx context.Context, v Values) context.Context {
+	if v.RequestID != "" {
+		ctx = database.WithRequestID(ctx, v.RequestID)
+	}
+	return context.WithValue(ctx, valuesKey{}, v)
+}
+
+// WithLogger returns ctx carrying logger, see Logger.
+func WithLogger(ctx context.Context, logger *zerolog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// From copies the caller of the request from echo's context into the request's
+// context.Context and returns it. It must be called after the route's authentication
+// middleware, the identity is copied on the first call and later ones return it as is.
+//
+//	device, err := h.notificationService.RegisterDevice(requestctx.From(c), &payload)
+func From(c echo.Context) context.Context {
+	if bridged, _ := c.Get(bridgedKey).(bool); bridged {
+		return c.Request().Context()
+	}
+
+	role, _ := c.Get(middleware.UserRoleKey).(string)
+
+	ctx := With(c.Request().Context(), Values{
+		UserID:         middleware.GetUserID(c),
+		OrganizationID: middleware.GetOrganizationID(c),
+		Role:           role,
+		Operator:       middleware.IsOperator(c),
+		Tenant:         middleware.GetQuotaConsumer(c),
+		Locale:         c.Request().Header.Get("Accept-Language"),
+		RequestID:      middleware.GetRequestID(c),
+	})
+	ctx = WithLogger(ctx, middleware.GetLogger(c))
+
+	c.SetRequest(c.Request().WithContext(ctx))
+	c.Set(bridgedKey, true)
+
+	return ctx
+}
+
+// Get returns the Values ctx carries, empty ones outside a request.
+func Get(ctx context.Context) Values {
+	v, _ := ctx.Value(valuesKey{}).(Values)
+	return v
+}
+
+// UserID returns the ID of the calling user, or an empty string.
+func UserID(ctx context.Context) string {
+	return Get(ctx).UserID
+}
+
+// OrganizationID returns the ID of the caller's active organization, or an empty string.
+func OrganizationID(ctx context.Context) string {
+	return Get(ctx).OrganizationID
+}
+
+// Tenant returns who the call's usage is accounted to, or an empty string.
+func Tenant(ctx context.Context) string {
+	return Get(ctx).Tenant
+}
+
+// Locale returns the caller's Accept-Language, or an empty string.
+func Locale(ctx context.Context) string {
+	return Get(ctx).Locale
+}
+
+// Logger returns the request's logger, already tagged with its request ID and caller,
+// or fallback if ctx carries none.
+func Logger(ctx context.Context, fallback *zerolog.Logger) *zerolog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zerolog.Logger); ok && logger != nil {
+		return logger
+	}
+	return fallback
+}
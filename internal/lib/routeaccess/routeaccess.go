@@ -0,0 +1,212 @@
+// Package routeaccess records who may call each route: the router declares the access of
+// its routes once, the auth middlewares enforce it and the OpenAPI document served at
+// /openapi.json is annotated with it, so the docs tell integrators what every endpoint
+// needs and can't drift from what is enforced.
+package routeaccess
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Names of the security schemes in the OpenAPI document.
+const (
+	// SchemeBearer is a user session token.
+	SchemeBearer = "bearerAuth"
+	// SchemeServiceToken is the token of an internal caller, see svcauth.
+	SchemeServiceToken = "serviceToken"
+)
+
+// Access declares who may call a route. The zero value is any authenticated user.
+type Access struct {
+	// Roles, if any, are the organization roles allowed, one of them is required.
+	Roles []string
+	// Operator routes are for platform operators only, whatever their organization role.
+	Operator bool
+	// Internal routes are called by other services with a service token instead of a user session.
+	Internal bool
+	// Services are the internal callers allowed, any configured one if empty.
+	Services []string
+}
+
+// User is the access of routes open to any authenticated user.
+var User = Access{}
+
+// Internal is the access of routes open to any configured internal caller.
+var Internal = Access{Internal: true}
+
+// Operator is the access of routes open to platform operators only.
+var Operator = Access{Operator: true}
+
+// Role returns the access of routes requiring one of roles.
+func Role(roles ...string) Access {
+	return Access{Roles: roles}
+}
+
+// Describe summarizes the access in a sentence for the docs.
+func (a Access) Describe() string {
+	switch {
+	case a.Internal && len(a.Services) > 0:
+		return "Requires a service token of " + strings.Join(a.Services, ", ") + "."
+	case a.Internal:
+		return "Requires a service token of an internal caller."
+	case a.Operator:
+		return "Requires a platform operator."
+	case len(a.Roles) == 1:
+		return "Requires the organization role " + a.Roles[0] + "."
+	case len(a.Roles) > 1:
+		return "Requires one of the organization roles " + strings.Join(a.Roles, ", ") + "."
+	default:
+		return "Requires an authenticated user."
+	}
+}
+
+// Registry holds the access of every declared route.
+type Registry struct {
+	mu     sync.RWMutex
+	routes map[string]Access
+}
+
+func NewRegistry() *Registry {
+	return &Registry{routes: make(map[string]Access)}
+}
+
+// Record declares the access of a route, path as registered with the router.
+func (r *Registry) Record(method, path string, access Access) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes[routeKey(method, path)] = access
+}
+
+// Lookup returns the access of a route, false for routes not declared, e.g. public ones.
+func (r *Registry) Lookup(method, path string) (Access, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	access, ok := r.routes[routeKey(method, path)]
+	return access, ok
+}
+
+// Len returns the number of declared routes.
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.routes)
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// pathParamPattern matches the {param} segments of OpenAPI paths.
+var pathParamPattern = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// Embed annotates the operations of an OpenAPI document with the access of their route:
+// the security scheme they need, the roles (x-required-roles), operators
+// (x-required-operator) or internal callers (x-allowed-services) allowed, a sentence in their description and their 401 and 403
+// responses. Operations of undeclared routes are left as documented.
+func (r *Registry) Embed(document map[string]any) {
+	paths, _ := document["paths"].(map[string]any)
+
+	internal := false
+	for path, item := range paths {
+		operations, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		// /todos/{id} is registered as /todos/:id
+		route := pathParamPattern.ReplaceAllString(path, ":$1")
+
+		for method, value := range operations {
+			operation, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			access, ok := r.Lookup(method, route)
+			if !ok {
+				continue
+			}
+
+			annotate(operation, access)
+			internal = internal || access.Internal
+		}
+	}
+
+	if internal {
+		addServiceTokenScheme(document)
+	}
+}
+
+func annotate(operation map[string]any, access Access) {
+	scheme := SchemeBearer
+	if access.Internal {
+		scheme = SchemeServiceToken
+	}
+	// Scopes only apply to oauth2 and openIdConnect schemes, roles go into extensions
+	operation["security"] = []any{map[string]any{scheme: []any{}}}
+
+	if len(access.Roles) > 0 {
+		operation["x-required-roles"] = access.Roles
+	}
+	if access.Operator {
+		operation["x-required-operator"] = true
+	}
+	if len(access.Services) > 0 {
+		operation["x-allowed-services"] = access.Services
+	}
+
+	description, _ := operation["description"].(string)
+	if description != "" {
+		description += "\n\n"
+	}
+	operation["description"] = description + access.Describe()
+
+	responses, ok := operation["responses"].(map[string]any)
+	if !ok {
+		responses = make(map[string]any)
+		operation["responses"] = responses
+	}
+	if _, ok := responses["401"]; !ok {
+		responses["401"] = errorResponse("Missing or invalid credentials")
+	}
+	if _, ok := responses["403"]; !ok && (len(access.Roles) > 0 || len(access.Services) > 0 || access.Operator) {
+		responses["403"] = errorResponse(fmt.Sprintf("Insufficient permissions. %s", access.Describe()))
+	}
+}
+
+func errorResponse(description string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content": map[string]any{
+			"application/json": map[string]any{
+				"schema": map[string]any{"$ref": "#/components/schemas/ErrorResponse"},
+			},
+		},
+	}
+}
+
+func addServiceTokenScheme(document map[string]any) {
+	components, ok := document["components"].(map[string]any)
+	if !ok {
+		components = make(map[string]any)
+		document["components"] = components
+	}
+
+	schemes, ok := components["securitySchemes"].(map[string]any)
+	if !ok {
+		schemes = make(map[string]any)
+		components["securitySchemes"] = schemes
+	}
+
+	if _, ok := schemes[SchemeServiceToken]; !ok {
+		schemes[SchemeServiceToken] = map[string]any{
+			"type":         "http",
+			"scheme":       "bearer",
+			"bearerFormat": "JWT",
+			"description":  "Token minted by an internal service with its service auth key",
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package routetoggle keeps the routes operators disabled at runtime, e.g. to quarantine
+// a misbehaving endpoint during an incident without a deploy. Disabled routes are kept
+// in a Redis hash shared by every instance; each instance reads them from an in-memory
+// copy refreshed on an interval, so checking a request never waits on Redis.
+package routetoggle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/clock"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// RefreshInterval bounds how long a change made on one instance takes to apply on the others.
+const RefreshInterval = 5 * time.Second
+
+// AnyMethod disables every method of a path.
+const AnyMethod = "*"
+
+// Toggle is a disabled route.
+type Toggle struct {
+	// Method is the HTTP method of the route, or AnyMethod.
+	Method string `json:"method"`
+	// Path is the route as registered with the router, e.g. /api/v1/todos/:id.
+	Path       string    `json:"path"`
+	Reason     string    `json:"reason"`
+	DisabledBy string    `json:"disabled_by,omitempty"`
+	DisabledAt time.Time `json:"disabled_at"`
+}
+
+// Name identifies a route, e.g. "GET /api/v1/todos/:id".
+func Name(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+type Store struct {
+	client *redis.Client
+	key    string
+	clock  clock.Clock
+	logger *zerolog.Logger
+
+	disabled atomic.Pointer[map[string]Toggle]
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewStore returns a Store keeping the disabled routes in space. No route is disabled
+// until the first Refresh.
+func NewStore(client *redis.Client, space keys.Space, clk clock.Clock, logger *zerolog.Logger) *Store {
+	s := &Store{
+		client: client,
+		key:    space.Key("disabled"),
+		clock:  clock.OrReal(clk),
+		logger: logger,
+	}
+	s.disabled.Store(&map[string]Toggle{})
+	return s
+}
+
+// Disabled returns the toggle disabling the route matched by a request, a toggle of
+// the exact method winning over one of AnyMethod.
+func (s *Store) Disabled(method, path string) (Toggle, bool) {
+	disabled := *s.disabled.Load()
+	if len(disabled) == 0 {
+		return Toggle{}, false
+	}
+
+	if toggle, ok := disabled[Name(method, path)]; ok {
+		return toggle, true
+	}
+	toggle, ok := disabled[Name(AnyMethod, path)]
+	return toggle, ok
+}
+
+// List returns the disabled routes as last refreshed, ordered by path and method.
+func (s *Store) List() []Toggle {
+	disabled := *s.disabled.Load()
+
+	toggles := make([]Toggle, 0, len(disabled))
+	for _, toggle := range disabled {
+		toggles = append(toggles, toggle)
+	}
+	sort.Slice(toggles, func(i, j int) bool {
+		if toggles[i].Path != toggles[j].Path {
+			return toggles[i].Path < toggles[j].Path
+		}
+		return toggles[i].Method < toggles[j].Method
+	})
+	return toggles
+}
+
+// Disable disables a route on every instance, replacing the reason if it was already
+// disabled. It returns the previous toggle of the route, if any.
+func (s *Store) Disable(ctx context.Context, toggle Toggle) (*Toggle, error) {
+	toggle.Method = strings.ToUpper(toggle.Method)
+	if toggle.DisabledAt.IsZero() {
+		toggle.DisabledAt = s.clock.Now().UTC()
+	}
+
+	raw, err := json.Marshal(toggle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode route toggle: %w", err)
+	}
+
+	name := Name(toggle.Method, toggle.Path)
+	previous, err := s.get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.HSet(ctx, s.key, name, raw).Err(); err != nil {
+		return nil, fmt.Errorf("failed to disable route %s: %w", name, err)
+	}
+
+	// This instance applies the change right away, the others on their next refresh
+	s.refreshLogged(ctx)
+	return previous, nil
+}
+
+// Enable enables a disabled route again on every instance. It returns the toggle that
+// disabled the route, nil if it wasn't disabled.
+func (s *Store) Enable(ctx context.Context, method, path string) (*Toggle, error) {
+	name := Name(method, path)
+	previous, err := s.get(ctx, name)
+	if err != nil || previous == nil {
+		return nil, err
+	}
+
+	if err := s.client.HDel(ctx, s.key, name).Err(); err != nil {
+		return nil, fmt.Errorf("failed to enable route %s: %w", name, err)
+	}
+
+	s.refreshLogged(ctx)
+	return previous, nil
+}
+
+// Refresh reloads the disabled routes from Redis. If that fails the routes last loaded
+// stay disabled, and no other route is.
+func (s *Store) Refresh(ctx context.Context) error {
+	fields, err := s.client.HGetAll(ctx, s.key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to load disabled routes: %w", err)
+	}
+
+	disabled := make(map[string]Toggle, len(fields))
+	for name, raw := range fields {
+		var toggle Toggle
+		if err := json.Unmarshal([]byte(raw), &toggle); err != nil {
+			s.logger.Warn().Err(err).Str("route", name).Msg("skipping malformed route toggle")
+			continue
+		}
+		disabled[name] = toggle
+	}
+
+	s.disabled.Store(&disabled)
+	return nil
+}
+
+// Start refreshes the disabled routes right away and then every interval until Stop,
+// picking up changes made through other instances.
+func (s *Store) Start(interval time.Duration) {
+	if s.stop != nil {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		s.refreshLogged(context.Background())
+
+		ticker := s.clock.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C():
+				s.refreshLogged(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop stops the refreshing started by Start.
+func (s *Store) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
+
+func (s *Store) refreshLogged(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, RefreshInterval)
+	defer cancel()
+
+	if err := s.Refresh(ctx); err != nil {
+		s.logger.Error().Err(err).Msg("failed to refresh disabled routes, keeping the current ones")
+	}
+}
+
+func (s *Store) get(ctx context.Context, name string) (*Toggle, error) {
+	raw, err := s.client.HGet(ctx, s.key, name).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read route toggle %s: %w", name, err)
+	}
+
+	var toggle Toggle
+	if err := json.Unmarshal([]byte(raw), &toggle); err != nil {
+		return nil, fmt.Errorf("failed to decode route toggle %s: %w", name, err)
+	}
+	return &toggle, nil
+}
@@ -0,0 +1,244 @@
+// Package securecookie encrypts and authenticates cookie values with AES-256-GCM.
+// Values are sealed with the first key of a key ring and opened with any of them,
+// so keys can be rotated without logging everybody out: prepend the new key, and
+// remove the old one once the cookies it sealed have expired.
+package securecookie
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// KeySize is the length of every key in the ring, AES-256 needs 32 bytes.
+const KeySize = 32
+
+const (
+	formatVersion = 1
+	keyIDSize     = 4
+	expiresSize   = 8
+)
+
+var (
+	ErrInvalid = errors.New("invalid cookie value")
+	ErrExpired = errors.New("cookie value has expired")
+)
+
+// Options are the attributes of the cookies a Codec issues.
+type Options struct {
+	Domain   string
+	Path     string
+	Secure   bool
+	SameSite http.SameSite
+	// MaxAge bounds how long a value stays valid, it is sealed into the value so an
+	// expired cookie is rejected even if the client keeps sending it. Zero issues
+	// session cookies whose values don't expire.
+	MaxAge time.Duration
+}
+
+// DefaultOptions returns the options for env: Secure is only left off in local and
+// development, where the app is usually served over plain HTTP.
+func DefaultOptions(env string) Options {
+	return Options{
+		Path:     "/",
+		Secure:   env != "local" && env != "development",
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   24 * time.Hour,
+	}
+}
+
+type ringKey struct {
+	id   [keyIDSize]byte
+	aead cipher.AEAD
+}
+
+type Codec struct {
+	keys    []ringKey
+	options Options
+}
+
+// NewCodec returns a Codec sealing with keys[0] and opening with any key of the ring.
+func NewCodec(keys [][]byte, options Options) (*Codec, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("securecookie: at least one key is required")
+	}
+
+	if options.SameSite == http.SameSiteNoneMode && !options.Secure {
+		return nil, fmt.Errorf("securecookie: SameSite=None cookies must be Secure")
+	}
+
+	c := &Codec{options: options}
+	for i, key := range keys {
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("securecookie: key %d is %d bytes, want %d", i, len(key), KeySize)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("securecookie: key %d: %w", i, err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("securecookie: key %d: %w", i, err)
+		}
+
+		// The key ID lets Decode pick the right key instead of trying all of them.
+		sum := sha256.Sum256(key)
+		rk := ringKey{aead: aead}
+		copy(rk.id[:], sum[:keyIDSize])
+		c.keys = append(c.keys, rk)
+	}
+
+	return c, nil
+}
+
+// ParseKeys decodes a comma-separated list of base64-encoded keys, primary key first.
+func ParseKeys(s string) ([][]byte, error) {
+	var keys [][]byte
+	for _, encoded := range strings.Split(s, ",") {
+		encoded = strings.TrimSpace(encoded)
+		if encoded == "" {
+			continue
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			key, err = base64.RawURLEncoding.DecodeString(encoded)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("securecookie: key %d is not valid base64", len(keys))
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Options returns the attributes of the cookies c issues.
+func (c *Codec) Options() Options {
+	return c.options
+}
+
+// Encode seals value for the cookie name. The name is authenticated too, a value
+// can't be moved to another cookie.
+func (c *Codec) Encode(name string, value []byte, now time.Time) (string, error) {
+	var expires int64
+	if c.options.MaxAge > 0 {
+		expires = now.Add(c.options.MaxAge).Unix()
+	}
+
+	plaintext := make([]byte, expiresSize, expiresSize+len(value))
+	binary.BigEndian.PutUint64(plaintext, uint64(expires))
+	plaintext = append(plaintext, value...)
+
+	key := c.keys[0]
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("securecookie: failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, 1+keyIDSize+len(nonce)+len(plaintext)+key.aead.Overhead())
+	out = append(out, formatVersion)
+	out = append(out, key.id[:]...)
+	out = append(out, nonce...)
+	out = key.aead.Seal(out, nonce, plaintext, []byte(name))
+
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// Decode opens a value sealed by Encode for the cookie name. stale reports that it
+// was sealed with an older key of the ring, callers should issue the cookie again.
+func (c *Codec) Decode(name, encoded string, now time.Time) (value []byte, stale bool, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(data) < 1+keyIDSize || data[0] != formatVersion {
+		return nil, false, ErrInvalid
+	}
+
+	id, data := data[1:1+keyIDSize], data[1+keyIDSize:]
+	for i, key := range c.keys {
+		if string(key.id[:]) != string(id) {
+			continue
+		}
+
+		nonceSize := key.aead.NonceSize()
+		if len(data) < nonceSize {
+			return nil, false, ErrInvalid
+		}
+
+		plaintext, err := key.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(name))
+		if err != nil || len(plaintext) < expiresSize {
+			return nil, false, ErrInvalid
+		}
+
+		expires := int64(binary.BigEndian.Uint64(plaintext))
+		if expires != 0 && now.Unix() > expires {
+			return nil, false, ErrExpired
+		}
+
+		return plaintext[expiresSize:], i > 0, nil
+	}
+
+	// Sealed with a key that has been rotated out, or not by us at all.
+	return nil, false, ErrInvalid
+}
+
+// Cookie returns the cookie name holding the sealed value, with the Codec's attributes.
+func (c *Codec) Cookie(name string, value []byte, now time.Time) (*http.Cookie, error) {
+	encoded, err := c.Encode(name, value, now)
+	if err != nil {
+		return nil, err
+	}
+
+	cookie := c.cookie(name, encoded)
+	if c.options.MaxAge > 0 {
+		cookie.MaxAge = int(c.options.MaxAge.Seconds())
+		cookie.Expires = now.Add(c.options.MaxAge)
+	}
+
+	return cookie, nil
+}
+
+// Read opens the cookie name of r, see Decode. It returns http.ErrNoCookie if r doesn't carry it.
+func (c *Codec) Read(r *http.Request, name string, now time.Time) (value []byte, stale bool, err error) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return c.Decode(name, cookie.Value, now)
+}
+
+// Expire returns a cookie removing name from the client.
+func (c *Codec) Expire(name string) *http.Cookie {
+	cookie := c.cookie(name, "")
+	cookie.MaxAge = -1
+	cookie.Expires = time.Unix(0, 0)
+	return cookie
+}
+
+func (c *Codec) cookie(name, value string) *http.Cookie {
+	path := c.options.Path
+	if path == "" {
+		path = "/"
+	}
+
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Domain:   c.options.Domain,
+		Path:     path,
+		Secure:   c.options.Secure,
+		HttpOnly: true,
+		SameSite: c.options.SameSite,
+	}
+}
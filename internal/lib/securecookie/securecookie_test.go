@@ -0,0 +1,123 @@
+package securecookie_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/securecookie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	oldKey = bytes.Repeat([]byte{1}, securecookie.KeySize)
+	newKey = bytes.Repeat([]byte{2}, securecookie.KeySize)
+	now    = time.Unix(1_700_000_000, 0)
+)
+
+func newCodec(t *testing.T, keys ...[]byte) *securecookie.Codec {
+	t.Helper()
+
+	codec, err := securecookie.NewCodec(keys, securecookie.Options{MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	return codec
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// sealedWith encodes "session" at now, decodedWith decodes it under name at decodedAt.
+		sealedWith  *securecookie.Codec
+		decodedWith *securecookie.Codec
+		decodedName string
+		decodedAt   time.Time
+		stale       bool
+		err         error
+	}{
+		{
+			name:        "primary key",
+			sealedWith:  newCodec(t, newKey, oldKey),
+			decodedWith: newCodec(t, newKey, oldKey),
+			decodedName: "session",
+			decodedAt:   now,
+		},
+		{
+			name:        "moved to another cookie",
+			sealedWith:  newCodec(t, newKey),
+			decodedWith: newCodec(t, newKey),
+			decodedName: "preferences",
+			decodedAt:   now,
+			err:         securecookie.ErrInvalid,
+		},
+		{
+			name:        "older key of the ring",
+			sealedWith:  newCodec(t, oldKey),
+			decodedWith: newCodec(t, newKey, oldKey),
+			decodedName: "session",
+			decodedAt:   now,
+			stale:       true,
+		},
+		{
+			name:        "key rotated out",
+			sealedWith:  newCodec(t, oldKey),
+			decodedWith: newCodec(t, newKey),
+			decodedName: "session",
+			decodedAt:   now,
+			err:         securecookie.ErrInvalid,
+		},
+		{
+			name:        "at expiry",
+			sealedWith:  newCodec(t, newKey),
+			decodedWith: newCodec(t, newKey),
+			decodedName: "session",
+			decodedAt:   now.Add(time.Hour),
+		},
+		{
+			name:        "expired",
+			sealedWith:  newCodec(t, newKey),
+			decodedWith: newCodec(t, newKey),
+			decodedName: "session",
+			decodedAt:   now.Add(time.Hour + time.Second),
+			err:         securecookie.ErrExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			encoded, err := tt.sealedWith.Encode("session", []byte("user-1"), now)
+			require.NoError(t, err)
+
+			value, stale, err := tt.decodedWith.Decode(tt.decodedName, encoded, tt.decodedAt)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				assert.Nil(t, value)
+				assert.False(t, stale)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []byte("user-1"), value)
+			assert.Equal(t, tt.stale, stale)
+		})
+	}
+}
+
+func TestDecodeWithoutMaxAgeNeverExpires(t *testing.T) {
+	t.Parallel()
+
+	codec, err := securecookie.NewCodec([][]byte{newKey}, securecookie.Options{})
+	require.NoError(t, err)
+
+	encoded, err := codec.Encode("session", []byte("user-1"), now)
+	require.NoError(t, err)
+
+	value, _, err := codec.Decode("session", encoded, now.AddDate(10, 0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("user-1"), value)
+}
@@ -0,0 +1,141 @@
+// Package selfcheck runs a set of startup checks and collects their outcome
+// into a single structured report, so a misconfigured deployment is obvious
+// from the first lines of its logs.
+package selfcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Check is a single startup check. Critical checks prevent the server from
+// starting in strict mode when they fail.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(ctx context.Context) Result
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Name     string         `json:"name"`
+	Status   Status         `json:"status"`
+	Critical bool           `json:"critical"`
+	Message  string         `json:"message,omitempty"`
+	Details  map[string]any `json:"details,omitempty"`
+	Duration time.Duration  `json:"duration"`
+}
+
+// OK returns a passing result.
+func OK(message string, details map[string]any) Result {
+	return Result{Status: StatusOK, Message: message, Details: details}
+}
+
+// Warn returns a result for a problem that doesn't prevent the server from working.
+func Warn(message string, details map[string]any) Result {
+	return Result{Status: StatusWarn, Message: message, Details: details}
+}
+
+// Fail returns a failing result.
+func Fail(err error) Result {
+	return Result{Status: StatusFail, Message: err.Error()}
+}
+
+type Report struct {
+	Results  []Result      `json:"results"`
+	Duration time.Duration `json:"duration"`
+}
+
+// CriticalFailures returns the failed results of critical checks.
+func (r *Report) CriticalFailures() []Result {
+	var failures []Result
+	for _, result := range r.Results {
+		if result.Critical && result.Status == StatusFail {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// Counts returns how many checks ended in each status.
+func (r *Report) Counts() map[Status]int {
+	counts := map[Status]int{StatusOK: 0, StatusWarn: 0, StatusFail: 0}
+	for _, result := range r.Results {
+		counts[result.Status]++
+	}
+	return counts
+}
+
+// Log writes one line per check followed by a summary line.
+func (r *Report) Log(logger *zerolog.Logger) {
+	for _, result := range r.Results {
+		event := logger.Info()
+		switch result.Status {
+		case StatusWarn:
+			event = logger.Warn()
+		case StatusFail:
+			event = logger.Error()
+		}
+
+		event.
+			Str("check", result.Name).
+			Str("status", string(result.Status)).
+			Bool("critical", result.Critical).
+			Dur("duration", result.Duration)
+		if len(result.Details) > 0 {
+			event.Fields(result.Details)
+		}
+		event.Msg(result.Message)
+	}
+
+	counts := r.Counts()
+	logger.Info().
+		Int("ok", counts[StatusOK]).
+		Int("warn", counts[StatusWarn]).
+		Int("fail", counts[StatusFail]).
+		Int("critical_failures", len(r.CriticalFailures())).
+		Dur("duration", r.Duration).
+		Msg("startup self-check finished")
+}
+
+// Run executes the checks one after another, each bounded by timeout.
+// A panicking check is reported as failed instead of crashing the server.
+func Run(ctx context.Context, timeout time.Duration, checks []Check) *Report {
+	start := time.Now()
+
+	report := &Report{Results: make([]Result, 0, len(checks))}
+	for _, check := range checks {
+		report.Results = append(report.Results, run(ctx, timeout, check))
+	}
+	report.Duration = time.Since(start)
+
+	return report
+}
+
+func run(ctx context.Context, timeout time.Duration, check Check) (result Result) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result = Fail(fmt.Errorf("check panicked: %v", r))
+		}
+		result.Name = check.Name
+		result.Critical = check.Critical
+		result.Duration = time.Since(start)
+	}()
+
+	return check.Run(ctx)
+}
@@ -0,0 +1,145 @@
+// Package sendrate keeps outbound sends within the rate limits of the providers they go
+// through (e.g. Resend allows 2 requests per second). Every instance draws from one token
+// bucket per provider in Redis, so the limit holds however many workers are sending.
+//
+// Sends reserve their token rather than polling for one: during a burst each worker is
+// told exactly when its turn comes, so sends are spread evenly over the refill rate
+// instead of every worker retrying at once. A send whose turn is further away than the
+// maximum wait doesn't reserve anything and gets a ThrottledError, job handlers return it
+// so the task is retried once the burst has passed rather than holding a worker.
+package sendrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/redis/go-redis/v9"
+)
+
+// reserveScript takes a token from the bucket, letting it go negative for sends that
+// wait for their turn, unless the wait would exceed ARGV[3]. The server's clock is used
+// so instances with skewed clocks share one timeline.
+// Returns {reserved, wait in milliseconds}.
+var reserveScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local max_wait = tonumber(ARGV[3])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate) - 1
+
+local wait = 0
+if tokens < 0 then
+	wait = math.ceil(-tokens / rate)
+end
+
+if wait > max_wait then
+	return {0, wait}
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + wait + 1000)
+
+return {1, wait}
+`)
+
+// Limit is the sending rate a provider allows.
+type Limit struct {
+	// PerSecond is the sustained rate tokens are refilled at.
+	PerSecond float64
+	// Burst is the number of sends allowed at once after a quiet period.
+	Burst int
+}
+
+// ThrottledError is returned for a send whose turn is further away than the maximum wait.
+type ThrottledError struct {
+	Provider string
+	Wait     time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("%s send rate exceeded, next slot in %s", e.Provider, e.Wait)
+}
+
+// RetryAfter is when a token should be available again, the job backends delay the
+// retry of a task failing with it by as much.
+func (e *ThrottledError) RetryAfter() time.Duration {
+	return e.Wait
+}
+
+type Throttle struct {
+	redis       *redis.Client
+	keys        keys.Space
+	limits      map[string]Limit
+	maxWait     time.Duration
+	newRelicApp *newrelic.Application
+}
+
+// NewThrottle returns a Throttle limiting each provider to its limit, its buckets are
+// kept in space. Providers without a limit are not throttled.
+func NewThrottle(client *redis.Client, space keys.Space, limits map[string]Limit, maxWait time.Duration, newRelicApp *newrelic.Application) *Throttle {
+	return &Throttle{
+		redis:       client,
+		keys:        space,
+		limits:      limits,
+		maxWait:     maxWait,
+		newRelicApp: newRelicApp,
+	}
+}
+
+// Wait blocks until provider may take another send, or returns a ThrottledError if that
+// is more than the maximum wait away. A Redis failure lets the send through, the
+// provider rejecting it is no worse than not sending at all.
+func (t *Throttle) Wait(ctx context.Context, provider string) error {
+	if t == nil {
+		return nil
+	}
+
+	limit, ok := t.limits[provider]
+	if !ok || limit.PerSecond <= 0 {
+		return nil
+	}
+
+	burst := max(limit.Burst, 1)
+	values, err := reserveScript.Run(ctx, t.redis, []string{t.keys.Key(provider)},
+		limit.PerSecond/1000, burst, t.maxWait.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil
+	}
+
+	wait := time.Duration(values[1]) * time.Millisecond
+	if values[0] == 0 {
+		t.record("Custom/SendRate/Deferred/"+provider, 1)
+		return &ThrottledError{Provider: provider, Wait: wait}
+	}
+
+	t.record("Custom/SendRate/Wait/"+provider, wait.Seconds())
+	if wait == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Throttle) record(name string, value float64) {
+	if t.newRelicApp != nil {
+		t.newRelicApp.RecordCustomMetric(name, value)
+	}
+}
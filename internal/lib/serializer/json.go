@@ -0,0 +1,27 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// JSON encodes bodies with encoding/json, the way echo's c.JSON does. <, > and & are
+// not escaped: responses are served as application/json with nosniff, so browsers
+// never render them as HTML.
+type JSON struct{}
+
+func (JSON) ContentType() string {
+	return "application/json"
+}
+
+func (JSON) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	// Encode terminates the document with a newline, Marshal doesn't
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,42 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Protobuf encodes bodies in the protobuf wire format. Values that are protobuf
+// messages are encoded as they are; any other value is encoded as a
+// google.protobuf.Value holding its JSON document, which every protobuf runtime
+// can decode without a schema of ours.
+type Protobuf struct{}
+
+func (Protobuf) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (Protobuf) Marshal(v any) ([]byte, error) {
+	if message, ok := v.(proto.Message); ok {
+		return proto.Marshal(message)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var document any
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	value, err := structpb.NewValue(document)
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.MarshalOptions{Deterministic: true}.Marshal(value)
+}
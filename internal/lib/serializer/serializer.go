@@ -0,0 +1,107 @@
+// Package serializer encodes response bodies in the format a client asked for in its
+// Accept header. JSON is the default; internal high-volume consumers can ask for the
+// more compact MessagePack or Protobuf encodings. Formats are pluggable: register a
+// Serializer and every handler responding through the registry can produce it.
+package serializer
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Serializer encodes response bodies in one format.
+type Serializer interface {
+	// ContentType is the media type the Serializer is negotiated by and responds with.
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+}
+
+// Registry picks the Serializer matching a request's Accept header.
+type Registry struct {
+	mu          sync.RWMutex
+	serializers map[string]Serializer
+	fallback    Serializer
+}
+
+// NewRegistry returns a Registry falling back to fallback, which is registered too.
+func NewRegistry(fallback Serializer) *Registry {
+	r := &Registry{
+		serializers: make(map[string]Serializer),
+		fallback:    fallback,
+	}
+	r.Register(fallback)
+	return r
+}
+
+// NewDefaultRegistry returns a Registry with JSON (the fallback), MessagePack and Protobuf.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry(JSON{})
+	r.Register(MessagePack{})
+	r.Register(Protobuf{})
+	return r
+}
+
+// Register adds s, replacing the Serializer of the same content type.
+func (r *Registry) Register(s Serializer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.serializers[s.ContentType()] = s
+}
+
+// Negotiate returns the registered Serializer the Accept header prefers. Without a
+// match, e.g. for "*/*" or a missing header, it returns the fallback, so clients that
+// don't ask for a format keep getting JSON.
+func (r *Registry) Negotiate(accept string) Serializer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, mediaType := range parseAccept(accept) {
+		if s, ok := r.serializers[mediaType]; ok {
+			return s
+		}
+	}
+
+	return r.fallback
+}
+
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept returns the media types of an Accept header, most preferred first.
+// Ranges with q=0 are left out, ties keep the client's order.
+func parseAccept(accept string) []string {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	mediaTypes := make([]string, len(ranges))
+	for i, r := range ranges {
+		mediaTypes[i] = r.mediaType
+	}
+	return mediaTypes
+}
@@ -0,0 +1,252 @@
+// Package settings resolves the runtime settings of the application (support email,
+// banner text, limits) that operators change through the admin API without a redeploy.
+// Settings are declared in code with a type and a default; changed values are stored in
+// Postgres and cached in memory and Redis, settings never changed keep their default.
+package settings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/cache"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/snapshot"
+)
+
+// cacheKey caches the stored values of every setting at once, there are only a few.
+const cacheKey = "app_settings"
+
+// Type is the type of a setting's value.
+type Type string
+
+const (
+	TypeString Type = "string"
+	TypeInt    Type = "int"
+	TypeBool   Type = "bool"
+	// TypeJSON is any JSON document, e.g. a list of links.
+	TypeJSON Type = "json"
+)
+
+// Definition declares a setting.
+type Definition struct {
+	Key  string
+	Type Type
+	// Default is the value until one is stored, it must be of the setting's type.
+	Default     any
+	Description string
+}
+
+// Loader loads the stored values of every setting from the database, keyed by setting.
+type Loader func(ctx context.Context) (map[string]json.RawMessage, error)
+
+type Store struct {
+	cache *cache.Cache
+
+	mu          sync.RWMutex
+	definitions map[string]Definition
+	defaults    map[string]json.RawMessage
+	loader      Loader
+}
+
+// NewStore returns a Store caching values in c. Until a loader is set with SetLoader
+// every setting has its default.
+func NewStore(c *cache.Cache) *Store {
+	return &Store{
+		cache:       c,
+		definitions: make(map[string]Definition),
+		defaults:    make(map[string]json.RawMessage),
+	}
+}
+
+// Register declares settings. It fails if a key is declared twice or a default doesn't
+// match its setting's type.
+func (s *Store) Register(definitions ...Definition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, def := range definitions {
+		if _, ok := s.definitions[def.Key]; ok {
+			return fmt.Errorf("setting %s is declared twice", def.Key)
+		}
+
+		raw, err := json.Marshal(def.Default)
+		if err != nil {
+			return fmt.Errorf("failed to encode default of setting %s: %w", def.Key, err)
+		}
+		if err := def.Check(raw); err != nil {
+			return fmt.Errorf("default of setting %s: %w", def.Key, err)
+		}
+
+		s.definitions[def.Key] = def
+		s.defaults[def.Key] = raw
+	}
+
+	return nil
+}
+
+// SetLoader sets where stored values are loaded from on a cache miss.
+func (s *Store) SetLoader(loader Loader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loader = loader
+}
+
+// Definitions returns every declared setting, ordered by key.
+func (s *Store) Definitions() []Definition {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	definitions := make([]Definition, 0, len(s.definitions))
+	for _, def := range s.definitions {
+		definitions = append(definitions, def)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Key < definitions[j].Key
+	})
+	return definitions
+}
+
+// Definition returns the declaration of key.
+func (s *Store) Definition(key string) (Definition, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	def, ok := s.definitions[key]
+	return def, ok
+}
+
+// Default returns the encoded default of key.
+func (s *Store) Default(key string) (json.RawMessage, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	raw, ok := s.defaults[key]
+	return raw, ok
+}
+
+// Stored returns the stored values of every setting, settings with their default are left out.
+func (s *Store) Stored(ctx context.Context) (map[string]json.RawMessage, error) {
+	s.mu.RLock()
+	loader := s.loader
+	s.mu.RUnlock()
+
+	if loader == nil {
+		return nil, nil
+	}
+
+	values, err := cache.Fetch(ctx, s.cache, cacheKey, 0, loader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	return values, nil
+}
+
+// Invalidate drops the cached values on every instance, the next read reloads them.
+func (s *Store) Invalidate(ctx context.Context) error {
+	if err := s.cache.Delete(ctx, cacheKey); err != nil {
+		return fmt.Errorf("failed to invalidate settings: %w", err)
+	}
+	return nil
+}
+
+// Raw returns the encoded value of key. If the stored values can't be read the default
+// is returned together with the error, so callers may carry on.
+func (s *Store) Raw(ctx context.Context, key string) (json.RawMessage, error) {
+	def, ok := s.Default(key)
+	if !ok {
+		return nil, fmt.Errorf("setting %s is not declared", key)
+	}
+
+	values, err := s.Stored(ctx)
+	if err != nil {
+		snapshot.RecordSetting(ctx, key, def)
+		return def, err
+	}
+
+	raw, ok := values[key]
+	if !ok {
+		raw = def
+	}
+
+	// Error reports of the request show which value it got
+	snapshot.RecordSetting(ctx, key, raw)
+
+	return raw, nil
+}
+
+// String returns the value of the string setting key, see Raw.
+func (s *Store) String(ctx context.Context, key string) (string, error) {
+	var value string
+	err := s.decode(ctx, key, TypeString, &value)
+	return value, err
+}
+
+// Int returns the value of the int setting key, see Raw.
+func (s *Store) Int(ctx context.Context, key string) (int64, error) {
+	var value int64
+	err := s.decode(ctx, key, TypeInt, &value)
+	return value, err
+}
+
+// Bool returns the value of the bool setting key, see Raw.
+func (s *Store) Bool(ctx context.Context, key string) (bool, error) {
+	var value bool
+	err := s.decode(ctx, key, TypeBool, &value)
+	return value, err
+}
+
+// JSON decodes the value of the json setting key into dst, see Raw.
+func (s *Store) JSON(ctx context.Context, key string, dst any) error {
+	return s.decode(ctx, key, TypeJSON, dst)
+}
+
+func (s *Store) decode(ctx context.Context, key string, typ Type, dst any) error {
+	if def, ok := s.Definition(key); ok && def.Type != typ {
+		return fmt.Errorf("setting %s is a %s setting, not %s", key, def.Type, typ)
+	}
+
+	raw, loadErr := s.Raw(ctx, key)
+	if raw == nil {
+		return loadErr
+	}
+
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("failed to decode setting %s: %w", key, err)
+	}
+	return loadErr
+}
+
+// Check reports whether raw is a valid value of the setting.
+func (d Definition) Check(raw json.RawMessage) error {
+	// null decodes into any Go value without an error
+	if d.Type != TypeJSON && string(bytes.TrimSpace(raw)) == "null" {
+		return fmt.Errorf("must not be null")
+	}
+
+	switch d.Type {
+	case TypeString:
+		var value string
+		if json.Unmarshal(raw, &value) != nil {
+			return fmt.Errorf("must be a string")
+		}
+	case TypeInt:
+		var value int64
+		if json.Unmarshal(raw, &value) != nil {
+			return fmt.Errorf("must be an integer")
+		}
+	case TypeBool:
+		var value bool
+		if json.Unmarshal(raw, &value) != nil {
+			return fmt.Errorf("must be true or false")
+		}
+	case TypeJSON:
+		if !json.Valid(raw) {
+			return fmt.Errorf("must be a JSON document")
+		}
+	default:
+		return fmt.Errorf("unknown setting type %q", d.Type)
+	}
+	return nil
+}
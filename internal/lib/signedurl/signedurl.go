@@ -0,0 +1,73 @@
+// Package signedurl creates and verifies expiring, HMAC-signed URLs. A signed URL
+// can be handed to a client (or embedded in an email) and later be verified
+// without any server-side state.
+package signedurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	ExpiresParam   = "expires"
+	SignatureParam = "signature"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid url signature")
+	ErrExpired          = errors.New("signed url has expired")
+)
+
+type Signer struct {
+	key []byte
+}
+
+// NewSigner returns a Signer using key to compute signatures.
+func NewSigner(key string) *Signer {
+	return &Signer{
+		key: []byte(key),
+	}
+}
+
+// Sign returns path with the expiry and signature query parameters appended.
+func (s *Signer) Sign(path string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set(ExpiresParam, expires)
+	query.Set(SignatureParam, s.signature(path, expires))
+
+	return path + "?" + query.Encode()
+}
+
+// Verify checks that signature matches path and expires, and that the URL hasn't expired.
+func (s *Signer) Verify(path, expires, signature string, now time.Time) error {
+	expected := s.signature(path, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if now.Unix() > expiresAt {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+func (s *Signer) signature(path, expires string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(path))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
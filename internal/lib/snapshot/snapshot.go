@@ -0,0 +1,172 @@
+// Package snapshot captures a bounded view of the safe values of a request's context
+// (route, parameters, caller, the runtime settings it evaluated) for the error reports
+// of panics and server errors, so production incidents can be reproduced. Request
+// bodies, headers and query strings are left out, they may carry credentials.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Bounds of a snapshot, so a report stays small whatever the request.
+const (
+	MaxParams      = 16
+	MaxSettings    = 32
+	MaxValueLength = 128
+)
+
+// Snapshot is what a report tells about the request that failed.
+type Snapshot struct {
+	Method         string            `json:"method"`
+	Route          string            `json:"route"`
+	Params         map[string]string `json:"params,omitempty"`
+	RequestID      string            `json:"request_id,omitempty"`
+	UserID         string            `json:"user_id,omitempty"`
+	OrganizationID string            `json:"organization_id,omitempty"`
+	Role           string            `json:"role,omitempty"`
+	Tenant         string            `json:"tenant,omitempty"`
+	// Settings are the runtime settings the request read, with the value it got.
+	Settings map[string]string `json:"settings,omitempty"`
+	// Truncated is set if parameters or settings were left out to respect the bounds.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// SetParams sets the route parameters, the first MaxParams by name.
+func (s *Snapshot) SetParams(names, values []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	s.Params = make(map[string]string, min(len(names), MaxParams))
+	for i, name := range names {
+		if i >= len(values) {
+			break
+		}
+		if len(s.Params) == MaxParams {
+			s.Truncated = true
+			break
+		}
+		s.Params[name] = Truncate(values[i])
+	}
+}
+
+// Attributes flattens the snapshot into scalar attributes prefixed with "snapshot.", as
+// New Relic events and transaction attributes take them.
+func (s *Snapshot) Attributes() map[string]any {
+	attributes := map[string]any{
+		"snapshot.method": s.Method,
+		"snapshot.route":  s.Route,
+	}
+
+	add := func(name, value string) {
+		if value != "" {
+			attributes["snapshot."+name] = value
+		}
+	}
+	add("request_id", s.RequestID)
+	add("user_id", s.UserID)
+	add("organization_id", s.OrganizationID)
+	add("role", s.Role)
+	add("tenant", s.Tenant)
+	add("params", join(s.Params))
+	add("settings", join(s.Settings))
+
+	if s.Truncated {
+		attributes["snapshot.truncated"] = true
+	}
+
+	return attributes
+}
+
+// join renders values as "a=1,b=2", ordered by key.
+func join(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + values[key]
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Truncate cuts value to MaxValueLength bytes, on a rune boundary.
+func Truncate(value string) string {
+	if len(value) <= MaxValueLength {
+		return value
+	}
+
+	cut := MaxValueLength
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut] + "…"
+}
+
+type recorderKey struct{}
+
+// Recorder collects what a request evaluated while it runs, e.g. runtime settings.
+type Recorder struct {
+	mu        sync.Mutex
+	settings  map[string]string
+	truncated bool
+}
+
+// WithRecorder returns ctx carrying a new Recorder.
+func WithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	recorder := &Recorder{}
+	return context.WithValue(ctx, recorderKey{}, recorder), recorder
+}
+
+// FromContext returns the Recorder of ctx, nil outside a request.
+func FromContext(ctx context.Context) *Recorder {
+	recorder, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return recorder
+}
+
+// RecordSetting records that the request read the setting key and got value. It does
+// nothing outside a request.
+func RecordSetting(ctx context.Context, key string, value json.RawMessage) {
+	recorder := FromContext(ctx)
+	if recorder == nil {
+		return
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if _, ok := recorder.settings[key]; !ok && len(recorder.settings) == MaxSettings {
+		recorder.truncated = true
+		return
+	}
+	if recorder.settings == nil {
+		recorder.settings = make(map[string]string)
+	}
+	recorder.settings[key] = Truncate(string(value))
+}
+
+// Fill copies what was recorded into s.
+func (r *Recorder) Fill(s *Snapshot) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.settings) > 0 {
+		s.Settings = make(map[string]string, len(r.settings))
+		for key, value := range r.settings {
+			s.Settings[key] = value
+		}
+	}
+	s.Truncated = s.Truncated || r.truncated
+}
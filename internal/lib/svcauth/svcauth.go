@@ -0,0 +1,300 @@
+// Package svcauth authenticates calls between our own services with short-lived signed
+// tokens, so internal endpoints don't rely on network boundaries alone. A token names
+// the calling service (issuer) and the service it is meant for (audience), and is signed
+// either with a secret shared by both (HMAC-SHA256) or with the caller's Ed25519 key,
+// in which case the callee only needs the caller's public key.
+//
+// Tokens look like "<alg>.<claims>.<signature>", with base64url encoded JSON claims.
+package svcauth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Header carries the token. It is separate from Authorization, so a service can forward
+// the end user's session along with its own token.
+const Header = "X-Service-Token"
+
+type Algorithm string
+
+const (
+	AlgorithmHMAC    Algorithm = "hs256"
+	AlgorithmEd25519 Algorithm = "ed25519"
+)
+
+var (
+	ErrMissingToken     = errors.New("missing service token")
+	ErrMalformedToken   = errors.New("malformed service token")
+	ErrUnknownCaller    = errors.New("service token from unknown caller")
+	ErrInvalidSignature = errors.New("invalid service token signature")
+	ErrWrongAudience    = errors.New("service token is for another service")
+	ErrExpiredToken     = errors.New("service token has expired")
+)
+
+// Claims are the signed contents of a token.
+type Claims struct {
+	Issuer    string `json:"iss"`
+	Audience  string `json:"aud"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Key signs or verifies tokens of one service.
+type Key struct {
+	algorithm  Algorithm
+	secret     []byte
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// HMACKey returns a key for a secret shared by caller and callee.
+func HMACKey(secret []byte) (Key, error) {
+	if len(secret) < 32 {
+		return Key{}, fmt.Errorf("svcauth: hmac secret must be at least 32 bytes")
+	}
+	return Key{algorithm: AlgorithmHMAC, secret: secret}, nil
+}
+
+// Ed25519SigningKey returns a key signing with privateKey, given as a 32 byte seed or a 64 byte private key.
+func Ed25519SigningKey(privateKey []byte) (Key, error) {
+	switch len(privateKey) {
+	case ed25519.SeedSize:
+		return Key{algorithm: AlgorithmEd25519, privateKey: ed25519.NewKeyFromSeed(privateKey)}, nil
+	case ed25519.PrivateKeySize:
+		return Key{algorithm: AlgorithmEd25519, privateKey: ed25519.PrivateKey(privateKey)}, nil
+	default:
+		return Key{}, fmt.Errorf("svcauth: ed25519 private key is %d bytes, want %d or %d", len(privateKey), ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
+
+// Ed25519VerifyingKey returns a key verifying tokens signed by the owner of publicKey.
+func Ed25519VerifyingKey(publicKey []byte) (Key, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return Key{}, fmt.Errorf("svcauth: ed25519 public key is %d bytes, want %d", len(publicKey), ed25519.PublicKeySize)
+	}
+	return Key{algorithm: AlgorithmEd25519, publicKey: ed25519.PublicKey(publicKey)}, nil
+}
+
+// DecodeKey decodes a base64 encoded key or secret, standard or URL-safe.
+func DecodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		key, err = base64.RawURLEncoding.DecodeString(encoded)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("svcauth: key is not valid base64")
+	}
+	return key, nil
+}
+
+// Signer mints tokens for the calls of one service.
+type Signer struct {
+	service string
+	key     Key
+	ttl     time.Duration
+}
+
+// NewSigner returns a Signer minting tokens issued by service, valid for ttl.
+func NewSigner(service string, key Key, ttl time.Duration) (*Signer, error) {
+	if service == "" {
+		return nil, fmt.Errorf("svcauth: service name is required")
+	}
+	if key.secret == nil && key.privateKey == nil {
+		return nil, fmt.Errorf("svcauth: signing requires an hmac secret or an ed25519 private key")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("svcauth: token ttl must be positive")
+	}
+
+	return &Signer{
+		service: service,
+		key:     key,
+		ttl:     ttl,
+	}, nil
+}
+
+// Mint returns a token for a call to audience.
+func (s *Signer) Mint(audience string, now time.Time) (string, error) {
+	claims, err := json.Marshal(Claims{
+		Issuer:    s.service,
+		Audience:  audience,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.ttl).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("svcauth: failed to encode claims: %w", err)
+	}
+
+	signed := string(s.key.algorithm) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	var signature []byte
+	switch s.key.algorithm {
+	case AlgorithmHMAC:
+		signature = hmacSum(s.key.secret, signed)
+	case AlgorithmEd25519:
+		signature = ed25519.Sign(s.key.privateKey, []byte(signed))
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Attach sets a fresh token for a call to audience on req.
+func (s *Signer) Attach(req *http.Request, audience string) error {
+	token, err := s.Mint(audience, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(Header, token)
+	return nil
+}
+
+// JWK is a public key in JSON Web Key format (RFC 8037 for Ed25519).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+}
+
+// PublicJWK returns the public key verifying the signer's tokens, false for HMAC secrets,
+// which must never be published. Its key ID is the RFC 7638 thumbprint of the key.
+func (s *Signer) PublicJWK() (JWK, bool) {
+	if s.key.algorithm != AlgorithmEd25519 {
+		return JWK{}, false
+	}
+
+	x := base64.RawURLEncoding.EncodeToString(s.key.privateKey.Public().(ed25519.PublicKey))
+
+	// The thumbprint hashes the required members in lexicographic order, without whitespace
+	thumbprint := sha256.Sum256([]byte(`{"crv":"Ed25519","kty":"OKP","x":"` + x + `"}`))
+
+	return JWK{
+		KeyType:   "OKP",
+		Curve:     "Ed25519",
+		X:         x,
+		KeyID:     base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+		Use:       "sig",
+		Algorithm: "EdDSA",
+	}, true
+}
+
+// Transport attaches a token to every request it sends, for HTTP clients calling one service.
+//
+//	client := &http.Client{Transport: &svcauth.Transport{Signer: signer, Audience: "billing"}}
+type Transport struct {
+	Signer   *Signer
+	Audience string
+	// Base sends the requests, http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request.
+	req = req.Clone(req.Context())
+	if err := t.Signer.Attach(req, t.Audience); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// Verifier checks tokens sent to one service.
+type Verifier struct {
+	audience string
+	callers  map[string]Key
+	leeway   time.Duration
+}
+
+// NewVerifier returns a Verifier accepting tokens for audience from the given callers,
+// keyed by service name. leeway absorbs clock drift between hosts.
+func NewVerifier(audience string, callers map[string]Key, leeway time.Duration) *Verifier {
+	return &Verifier{
+		audience: audience,
+		callers:  callers,
+		leeway:   leeway,
+	}
+}
+
+// Verify checks token and returns its claims. Which callers an endpoint accepts is
+// up to the caller of Verify, every known service passes here.
+func (v *Verifier) Verify(token string, now time.Time) (*Claims, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	key, ok := v.callers[claims.Issuer]
+	if !ok {
+		return nil, ErrUnknownCaller
+	}
+
+	// The algorithm is taken from the caller's key, never from the token, so a token
+	// can't downgrade an Ed25519 caller to HMAC keyed with its public key.
+	if Algorithm(parts[0]) != key.algorithm {
+		return nil, ErrInvalidSignature
+	}
+
+	signed := parts[0] + "." + parts[1]
+	switch key.algorithm {
+	case AlgorithmHMAC:
+		if !hmac.Equal(signature, hmacSum(key.secret, signed)) {
+			return nil, ErrInvalidSignature
+		}
+	case AlgorithmEd25519:
+		if key.publicKey == nil || !ed25519.Verify(key.publicKey, []byte(signed), signature) {
+			return nil, ErrInvalidSignature
+		}
+	default:
+		return nil, ErrInvalidSignature
+	}
+
+	if claims.Audience != v.audience {
+		return nil, ErrWrongAudience
+	}
+
+	if now.Add(-v.leeway).Unix() > claims.ExpiresAt || now.Add(v.leeway).Unix() < claims.IssuedAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+func hmacSum(secret []byte, signed string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
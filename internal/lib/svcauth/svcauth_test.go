@@ -0,0 +1,187 @@
+package svcauth_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/svcauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const leeway = 30 * time.Second
+
+var now = time.Unix(1_700_000_000, 0)
+
+// mint returns a token issued by service for audience at issuedAt, valid for a minute.
+func mint(t *testing.T, service string, key svcauth.Key, audience string, issuedAt time.Time) string {
+	t.Helper()
+
+	signer, err := svcauth.NewSigner(service, key, time.Minute)
+	require.NoError(t, err)
+
+	token, err := signer.Mint(audience, issuedAt)
+	require.NoError(t, err)
+
+	return token
+}
+
+// tamper re-encodes the claims of token after edit, keeping its signature.
+func tamper(t *testing.T, token string, edit func(*svcauth.Claims)) string {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+
+	var claims svcauth.Claims
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	edit(&claims)
+
+	payload, err = json.Marshal(claims)
+	require.NoError(t, err)
+
+	return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	hmacKey, err := svcauth.HMACKey([]byte(strings.Repeat("s", 32)))
+	require.NoError(t, err)
+	otherHMACKey, err := svcauth.HMACKey([]byte(strings.Repeat("o", 32)))
+	require.NoError(t, err)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signingKey, err := svcauth.Ed25519SigningKey(privateKey)
+	require.NoError(t, err)
+	verifyingKey, err := svcauth.Ed25519VerifyingKey(publicKey)
+	require.NoError(t, err)
+
+	// An attacker knowing the public key of an Ed25519 caller signs with it as an HMAC secret
+	publicAsSecret, err := svcauth.HMACKey(publicKey)
+	require.NoError(t, err)
+
+	verifier := svcauth.NewVerifier("billing", map[string]svcauth.Key{
+		"api":     hmacKey,
+		"reports": verifyingKey,
+	}, leeway)
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{
+			name:  "hmac",
+			token: mint(t, "api", hmacKey, "billing", now),
+		},
+		{
+			name:  "ed25519",
+			token: mint(t, "reports", signingKey, "billing", now),
+		},
+		{
+			name:  "missing",
+			token: "",
+			err:   svcauth.ErrMissingToken,
+		},
+		{
+			name:  "malformed",
+			token: "hs256.not-a-token",
+			err:   svcauth.ErrMalformedToken,
+		},
+		{
+			name:  "unknown issuer",
+			token: mint(t, "mailer", hmacKey, "billing", now),
+			err:   svcauth.ErrUnknownCaller,
+		},
+		{
+			name:  "wrong hmac secret",
+			token: mint(t, "api", otherHMACKey, "billing", now),
+			err:   svcauth.ErrInvalidSignature,
+		},
+		{
+			name:  "algorithm mismatch",
+			token: mint(t, "api", signingKey, "billing", now),
+			err:   svcauth.ErrInvalidSignature,
+		},
+		{
+			name:  "downgrade to hmac keyed with the public key",
+			token: mint(t, "reports", publicAsSecret, "billing", now),
+			err:   svcauth.ErrInvalidSignature,
+		},
+		{
+			name:  "algorithm relabeled",
+			token: "hs256" + strings.TrimPrefix(mint(t, "reports", signingKey, "billing", now), "ed25519"),
+			err:   svcauth.ErrInvalidSignature,
+		},
+		{
+			name:  "wrong audience",
+			token: mint(t, "api", hmacKey, "payments", now),
+			err:   svcauth.ErrWrongAudience,
+		},
+		{
+			name: "tampered audience",
+			token: tamper(t, mint(t, "api", hmacKey, "payments", now), func(c *svcauth.Claims) {
+				c.Audience = "billing"
+			}),
+			err: svcauth.ErrInvalidSignature,
+		},
+		{
+			name: "tampered expiry",
+			token: tamper(t, mint(t, "reports", signingKey, "billing", now.Add(-time.Hour)), func(c *svcauth.Claims) {
+				c.ExpiresAt = now.Add(time.Hour).Unix()
+			}),
+			err: svcauth.ErrInvalidSignature,
+		},
+		{
+			name: "tampered issuer",
+			token: tamper(t, mint(t, "api", hmacKey, "billing", now), func(c *svcauth.Claims) {
+				c.Issuer = "reports"
+			}),
+			err: svcauth.ErrInvalidSignature,
+		},
+		{
+			name:  "expired within leeway",
+			token: mint(t, "api", hmacKey, "billing", now.Add(-time.Minute-leeway+time.Second)),
+		},
+		{
+			name:  "expired beyond leeway",
+			token: mint(t, "api", hmacKey, "billing", now.Add(-time.Minute-leeway-time.Second)),
+			err:   svcauth.ErrExpiredToken,
+		},
+		{
+			name:  "issued ahead within leeway",
+			token: mint(t, "api", hmacKey, "billing", now.Add(leeway)),
+		},
+		{
+			name:  "issued ahead beyond leeway",
+			token: mint(t, "api", hmacKey, "billing", now.Add(leeway+time.Second)),
+			err:   svcauth.ErrExpiredToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			claims, err := verifier.Verify(tt.token, now)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				assert.Nil(t, claims)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "billing", claims.Audience)
+		})
+	}
+}
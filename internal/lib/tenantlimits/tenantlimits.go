@@ -0,0 +1,147 @@
+// Package tenantlimits resolves the rate limit and request quotas of a tenant
+// (organization). Overrides, e.g. granted by a paid plan, are stored in Postgres
+// and cached in memory and Redis; tenants without an override get the configured defaults.
+package tenantlimits
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/cache"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/quota"
+)
+
+const keyPrefix = "tenant_limits"
+
+// Limits are the effective limits of a tenant.
+type Limits struct {
+	// Plan is the plan the limits come from, empty for the defaults.
+	Plan string
+	// RequestsPerMinute is the rate limit, zero means unlimited.
+	RequestsPerMinute int
+	Quota             quota.Limits
+}
+
+// Override holds a tenant's own limits, nil fields keep the default.
+type Override struct {
+	Plan              *string `json:"plan,omitempty"`
+	RequestsPerMinute *int    `json:"requests_per_minute,omitempty"`
+	DailyQuota        *int64  `json:"daily_quota,omitempty"`
+	MonthlyQuota      *int64  `json:"monthly_quota,omitempty"`
+}
+
+// Loader loads the override of a tenant from the database, returning nil without one.
+type Loader func(ctx context.Context, tenantID string) (*Override, error)
+
+type Resolver struct {
+	cache *cache.Cache
+	ttl   time.Duration
+
+	mu       sync.RWMutex
+	loader   Loader
+	defaults Limits
+}
+
+// NewResolver returns a Resolver caching overrides for ttl. Until a loader is set
+// with SetLoader every tenant gets defaults.
+func NewResolver(c *cache.Cache, defaults Limits, ttl time.Duration) *Resolver {
+	return &Resolver{
+		cache:    c,
+		defaults: defaults,
+		ttl:      ttl,
+	}
+}
+
+// SetLoader sets where overrides are loaded from on a cache miss.
+func (r *Resolver) SetLoader(loader Loader) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.loader = loader
+}
+
+// SetDefaults replaces the limits of tenants without an override, e.g. after a config
+// reload. Overrides apply on top of the new defaults right away.
+func (r *Resolver) SetDefaults(defaults Limits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaults = defaults
+}
+
+// Defaults returns the limits of tenants without an override.
+func (r *Resolver) Defaults() Limits {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaults
+}
+
+// Resolve returns the effective limits of tenantID. If the override can't be read
+// the defaults are returned together with the error, so callers may carry on.
+func (r *Resolver) Resolve(ctx context.Context, tenantID string) (Limits, error) {
+	if tenantID == "" {
+		return r.Defaults(), nil
+	}
+
+	override, err := r.override(ctx, tenantID)
+	if err != nil {
+		return r.Defaults(), err
+	}
+
+	return r.apply(override), nil
+}
+
+// Invalidate drops the cached override of tenantID on every instance, the next Resolve reloads it.
+func (r *Resolver) Invalidate(ctx context.Context, tenantID string) error {
+	if err := r.cache.Delete(ctx, Key(tenantID)); err != nil {
+		return fmt.Errorf("failed to invalidate limits of tenant %s: %w", tenantID, err)
+	}
+	return nil
+}
+
+func (r *Resolver) override(ctx context.Context, tenantID string) (*Override, error) {
+	r.mu.RLock()
+	loader := r.loader
+	r.mu.RUnlock()
+
+	if loader == nil {
+		return nil, nil
+	}
+
+	// Tenants without an override are cached as "null" so they don't hit the database either.
+	override, err := cache.Fetch(ctx, r.cache, Key(tenantID), r.ttl, func(ctx context.Context) (*Override, error) {
+		return loader(ctx, tenantID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve limits of tenant %s: %w", tenantID, err)
+	}
+
+	return override, nil
+}
+
+func (r *Resolver) apply(override *Override) Limits {
+	limits := r.Defaults()
+	if override == nil {
+		return limits
+	}
+
+	if override.Plan != nil {
+		limits.Plan = *override.Plan
+	}
+	if override.RequestsPerMinute != nil {
+		limits.RequestsPerMinute = *override.RequestsPerMinute
+	}
+	if override.DailyQuota != nil {
+		limits.Quota.Daily = *override.DailyQuota
+	}
+	if override.MonthlyQuota != nil {
+		limits.Quota.Monthly = *override.MonthlyQuota
+	}
+
+	return limits
+}
+
+// Key returns the Redis key caching the override of tenantID.
+func Key(tenantID string) string {
+	return keyPrefix + ":" + tenantID
+}
@@ -0,0 +1,176 @@
+// Package warmup primes caches at startup, so the first requests after a deploy don't
+// all miss at once and stampede the database. Components register a Primer while they
+// are wired up (e.g. authorization policies, tenant overrides), Start runs them all
+// concurrently, each within its timeout. Readiness can be held back until the primers
+// marked critical finished, failed ones included, so a broken primer never keeps an
+// instance out of rotation for good.
+package warmup
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Primer fills a cache.
+type Primer struct {
+	Name string
+	// Critical primers hold back readiness, when it is gated, until they finished.
+	Critical bool
+	// Timeout bounds the primer, zero uses the Warmer's default.
+	Timeout time.Duration
+	Prime   func(ctx context.Context) error
+}
+
+// Status is the outcome of a primer, reported by readiness.
+type Status struct {
+	Name       string `json:"name"`
+	Critical   bool   `json:"critical"`
+	State      string `json:"state"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// States of a primer.
+const (
+	StatePending = "pending"
+	StateRunning = "running"
+	StateWarm    = "warm"
+	StateFailed  = "failed"
+)
+
+type Warmer struct {
+	logger         *zerolog.Logger
+	defaultTimeout time.Duration
+
+	mu       sync.Mutex
+	primers  []Primer
+	statuses map[string]*Status
+	started  bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     sync.WaitGroup
+}
+
+// New returns a Warmer running primers without a timeout of their own for at most defaultTimeout.
+func New(logger *zerolog.Logger, defaultTimeout time.Duration) *Warmer {
+	return &Warmer{
+		logger:         logger,
+		defaultTimeout: defaultTimeout,
+		statuses:       make(map[string]*Status),
+	}
+}
+
+// Register adds a primer, it runs once Start is called. Primers registered after
+// Start run right away.
+func (w *Warmer) Register(p Primer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.primers = append(w.primers, p)
+	w.statuses[p.Name] = &Status{Name: p.Name, Critical: p.Critical, State: StatePending}
+
+	if w.started {
+		w.run(w.ctx, p)
+	}
+}
+
+// Start runs every registered primer concurrently and returns right away, Stop cancels
+// the ones still running.
+func (w *Warmer) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.started {
+		return
+	}
+	w.started = true
+
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+
+	w.logger.Info().Int("primers", len(w.primers)).Msg("warming caches")
+	for _, p := range w.primers {
+		w.run(w.ctx, p)
+	}
+}
+
+// run starts p, w.mu must be held.
+func (w *Warmer) run(ctx context.Context, p Primer) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = w.defaultTimeout
+	}
+
+	w.statuses[p.Name].State = StateRunning
+	w.done.Add(1)
+
+	go func() {
+		defer w.done.Done()
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		start := time.Now()
+		err := p.Prime(ctx)
+		elapsed := time.Since(start)
+
+		w.mu.Lock()
+		status := w.statuses[p.Name]
+		status.DurationMS = elapsed.Milliseconds()
+		if err != nil {
+			status.State = StateFailed
+			status.Error = err.Error()
+		} else {
+			status.State = StateWarm
+		}
+		w.mu.Unlock()
+
+		if err != nil {
+			w.logger.Warn().Err(err).Str("primer", p.Name).Bool("critical", p.Critical).Dur("duration", elapsed).Msg("cache warmup failed, the cache fills on demand")
+			return
+		}
+		w.logger.Info().Str("primer", p.Name).Dur("duration", elapsed).Msg("cache warmed")
+	}()
+}
+
+// Ready reports whether every critical primer finished.
+func (w *Warmer) Ready() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started {
+		return false
+	}
+	for _, status := range w.statuses {
+		if status.Critical && (status.State == StatePending || status.State == StateRunning) {
+			return false
+		}
+	}
+	return true
+}
+
+// Statuses returns the state of every primer, in registration order.
+func (w *Warmer) Statuses() []Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	statuses := make([]Status, 0, len(w.primers))
+	for _, p := range w.primers {
+		statuses = append(statuses, *w.statuses[p.Name])
+	}
+	return statuses
+}
+
+// Stop cancels the primers still running and waits for them to return.
+func (w *Warmer) Stop() {
+	w.mu.Lock()
+	cancel := w.cancel
+	w.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	w.done.Wait()
+}
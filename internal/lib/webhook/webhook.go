@@ -0,0 +1,238 @@
+// Package webhook verifies the signatures of inbound webhooks. It supports Stripe's
+// scheme, the Svix scheme used by Clerk and Resend, Twilio's scheme, and a generic
+// HMAC-SHA256 scheme for everything else. Every scheme checks a signed timestamp when
+// there is one, so captured requests can't be replayed later. Twilio signs none.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Scheme string
+
+const (
+	SchemeStripe Scheme = "stripe"
+	SchemeSvix   Scheme = "svix"
+	SchemeHMAC   Scheme = "hmac"
+	SchemeTwilio Scheme = "twilio"
+)
+
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidTimestamp = errors.New("invalid webhook timestamp")
+	ErrExpiredTimestamp = errors.New("webhook timestamp outside tolerance")
+)
+
+// Verifier checks the signature of webhooks from one source.
+type Verifier struct {
+	scheme          Scheme
+	secret          []byte
+	signatureHeader string
+	timestampHeader string
+	idHeader        string
+	tolerance       time.Duration
+}
+
+// NewVerifier returns a Verifier for scheme. Empty headers fall back to the scheme's defaults.
+// Svix secrets are accepted with or without their "whsec_" prefix.
+func NewVerifier(scheme Scheme, secret, signatureHeader, timestampHeader string, tolerance time.Duration) (*Verifier, error) {
+	v := &Verifier{
+		scheme:          scheme,
+		secret:          []byte(secret),
+		signatureHeader: signatureHeader,
+		timestampHeader: timestampHeader,
+		tolerance:       tolerance,
+	}
+
+	switch scheme {
+	case SchemeStripe:
+		v.signatureHeader = withDefault(signatureHeader, "Stripe-Signature")
+	case SchemeSvix:
+		v.signatureHeader = withDefault(signatureHeader, "svix-signature")
+		v.timestampHeader = withDefault(timestampHeader, "svix-timestamp")
+		v.idHeader = "svix-id"
+
+		key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, "whsec_"))
+		if err != nil {
+			return nil, fmt.Errorf("svix secret is not valid base64: %w", err)
+		}
+		v.secret = key
+	case SchemeTwilio:
+		v.signatureHeader = withDefault(signatureHeader, "X-Twilio-Signature")
+	case SchemeHMAC:
+		if signatureHeader == "" {
+			return nil, errors.New("hmac scheme requires a signature header")
+		}
+	default:
+		return nil, fmt.Errorf("unknown webhook scheme %q", scheme)
+	}
+
+	return v, nil
+}
+
+// Verify checks the signature of a webhook with the given headers and raw body.
+// requestURL is the full public URL the webhook was sent to, only Twilio signs it.
+func (v *Verifier) Verify(requestURL string, header http.Header, body []byte, now time.Time) error {
+	signature := header.Get(v.signatureHeader)
+	if signature == "" {
+		return ErrMissingSignature
+	}
+
+	switch v.scheme {
+	case SchemeStripe:
+		return v.verifyStripe(signature, body, now)
+	case SchemeSvix:
+		return v.verifySvix(header, signature, body, now)
+	case SchemeTwilio:
+		return v.verifyTwilio(requestURL, signature, body)
+	default:
+		return v.verifyHMAC(header, signature, body, now)
+	}
+}
+
+// verifyStripe checks "t=<unix>,v1=<hex>[,v1=<hex>]" signatures of "<t>.<body>".
+func (v *Verifier) verifyStripe(signature string, body []byte, now time.Time) error {
+	var (
+		timestamp  string
+		signatures []string
+	)
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if err := v.checkTimestamp(timestamp, now); err != nil {
+		return err
+	}
+
+	expected := v.sign([]byte(timestamp), []byte("."), body)
+	for _, candidate := range signatures {
+		if decoded, err := hex.DecodeString(candidate); err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// verifySvix checks "v1,<base64> [v1,<base64>]" signatures of "<id>.<timestamp>.<body>".
+func (v *Verifier) verifySvix(header http.Header, signature string, body []byte, now time.Time) error {
+	timestamp := header.Get(v.timestampHeader)
+	if err := v.checkTimestamp(timestamp, now); err != nil {
+		return err
+	}
+
+	expected := v.sign([]byte(header.Get(v.idHeader)), []byte("."), []byte(timestamp), []byte("."), body)
+	for _, part := range strings.Fields(signature) {
+		version, value, _ := strings.Cut(part, ",")
+		if version != "v1" {
+			continue
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(value); err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// verifyTwilio checks a base64 HMAC-SHA1 signature of the request URL followed by every
+// form parameter, sorted by name, as "<name><value>".
+func (v *Verifier) verifyTwilio(requestURL, signature string, body []byte) error {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mac := hmac.New(sha1.New, v.secret)
+	mac.Write([]byte(requestURL))
+	for _, name := range names {
+		values := form[name]
+		sort.Strings(values)
+		for _, value := range values {
+			mac.Write([]byte(name))
+			mac.Write([]byte(value))
+		}
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// verifyHMAC checks a hex signature, optionally prefixed with "sha256=", of the body,
+// or of "<timestamp>.<body>" when a timestamp header is configured.
+func (v *Verifier) verifyHMAC(header http.Header, signature string, body []byte, now time.Time) error {
+	parts := [][]byte{body}
+
+	if v.timestampHeader != "" {
+		timestamp := header.Get(v.timestampHeader)
+		if err := v.checkTimestamp(timestamp, now); err != nil {
+			return err
+		}
+		parts = [][]byte{[]byte(timestamp), []byte("."), body}
+	}
+
+	decoded, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || !hmac.Equal(decoded, v.sign(parts...)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func (v *Verifier) checkTimestamp(timestamp string, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > v.tolerance || age < -v.tolerance {
+		return ErrExpiredTimestamp
+	}
+
+	return nil
+}
+
+func (v *Verifier) sign(parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, v.secret)
+	for _, part := range parts {
+		mac.Write(part)
+	}
+	return mac.Sum(nil)
+}
+
+func withDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
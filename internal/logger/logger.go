@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 	"time"
 
@@ -89,22 +90,7 @@ func NewLoggerWithConig(cfg *config.MonitoringConfig) zerolog.Logger {
 }
 
 func NewLoggerWithService(cfg *config.MonitoringConfig, loggerservice *LoggerService) zerolog.Logger {
-	var logLevel zerolog.Level
-	level := cfg.GetLogLevel()
-
-	switch level {
-	case "debug":
-		logLevel = zerolog.DebugLevel
-	case "info":
-		logLevel = zerolog.InfoLevel
-	case "warn":
-		logLevel = zerolog.WarnLevel
-	case "error":
-		logLevel = zerolog.ErrorLevel
-
-	default:
-		logLevel = zerolog.InfoLevel
-	}
+	SetLevel(cfg)
 
 	zerolog.TimeFieldFormat = ZerologTimeFormat
 	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
@@ -130,7 +116,7 @@ func NewLoggerWithService(cfg *config.MonitoringConfig, loggerservice *LoggerSer
 		writer = consoleWriter
 	}
 
-	logger := zerolog.New(writer).Level(logLevel).With().Timestamp().Str("service", cfg.ServiceName).Str("environment", cfg.Environment).Logger()
+	logger := zerolog.New(writer).With().Timestamp().Str("service", cfg.ServiceName).Str("environment", cfg.Environment).Logger()
 
 	// Add stack traces for dev errors
 	if !cfg.IsProductin() {
@@ -141,6 +127,28 @@ func NewLoggerWithService(cfg *config.MonitoringConfig, loggerservice *LoggerSer
 
 }
 
+// SetLevel applies the log level of cfg. It is zerolog's global level, not the level of
+// each logger, so a config reload changes it for the loggers already handed out.
+func SetLevel(cfg *config.MonitoringConfig) {
+	var logLevel zerolog.Level
+
+	switch cfg.GetLogLevel() {
+	case "debug":
+		logLevel = zerolog.DebugLevel
+	case "info":
+		logLevel = zerolog.InfoLevel
+	case "warn":
+		logLevel = zerolog.WarnLevel
+	case "error":
+		logLevel = zerolog.ErrorLevel
+
+	default:
+		logLevel = zerolog.InfoLevel
+	}
+
+	zerolog.SetGlobalLevel(logLevel)
+}
+
 // Add distributed tracing metadata from a new relic transaction to logger
 func WithTraceContext(logger zerolog.Logger, txn *newrelic.Transaction) zerolog.Logger {
 	if txn == nil {
@@ -151,21 +159,121 @@ func WithTraceContext(logger zerolog.Logger, txn *newrelic.Transaction) zerolog.
 	return logger.With().Str("trace_id", traceMetadata.TraceID).Str("span_id", traceMetadata.SpanID).Logger()
 }
 
+// SQLArgsMode controls how query arguments are rendered in database logs.
+type SQLArgsMode int
+
+const (
+	// SQLArgsRaw renders argument values verbatim. Only safe for local development.
+	SQLArgsRaw SQLArgsMode = iota
+	// SQLArgsSanitized masks string and byte values and truncates long arrays,
+	// so user data and secrets never reach shared log storage.
+	SQLArgsSanitized
+)
+
+const (
+	sqlMaskedValue         = "****"
+	sqlMaxLoggedArrayItems = 5
+)
+
+// SQLArgsModeForEnv picks the argument rendering mode for an environment.
+// Raw values are only allowed in local and development environments;
+// everything else gets sanitized arguments.
+func SQLArgsModeForEnv(env string) SQLArgsMode {
+	switch env {
+	case "local", "development":
+		return SQLArgsRaw
+	default:
+		return SQLArgsSanitized
+	}
+}
+
 // FormatSQLWithArgs reconstructs a SQL query by replacing positional
 // placeholders (e.g., $1, $2, …) with the provided argument values.
 // This is intended for development use only, as it makes debugging
 // and reproducing queries easier by showing the fully interpolated SQL.
 func FormatSQLWithArgs(sqlStr string, args []any) string {
+	return FormatSQLWithArgsMode(sqlStr, args, SQLArgsRaw)
+}
+
+// FormatSQLWithArgsMode works like FormatSQLWithArgs but renders the
+// arguments according to mode. Use SQLArgsSanitized outside development.
+func FormatSQLWithArgsMode(sqlStr string, args []any, mode SQLArgsMode) string {
+	if mode == SQLArgsSanitized {
+		args = SanitizeSQLArgs(args)
+	}
+
 	output := sqlStr
 
-	for i, arg := range args {
+	// Replace from the highest placeholder down so $1 never matches the prefix of $10.
+	for i := len(args) - 1; i >= 0; i-- {
 		placeholder := fmt.Sprintf("$%d", i+1)
-		value := fmt.Sprintf("'%v'", arg)
-		output = strings.Replace(output, placeholder, value, 1)
+		value := fmt.Sprintf("'%v'", args[i])
+		output = strings.ReplaceAll(output, placeholder, value)
 	}
 	return output
 }
 
+// SanitizeSQLArgs returns a copy of args that is safe to log:
+//   - strings and byte slices are masked, keeping only their length.
+//   - slices and arrays are truncated to a few elements, each sanitized.
+//   - numbers, booleans, times and nil are kept as they are.
+func SanitizeSQLArgs(args []any) []any {
+	sanitized := make([]any, len(args))
+	for i, arg := range args {
+		sanitized[i] = sanitizeSQLArg(arg)
+	}
+	return sanitized
+}
+
+func sanitizeSQLArg(arg any) any {
+	switch value := arg.(type) {
+	case nil:
+		return nil
+	case string:
+		return fmt.Sprintf("%s(len=%d)", sqlMaskedValue, len(value))
+	case []byte:
+		return fmt.Sprintf("%s(bytes=%d)", sqlMaskedValue, len(value))
+	case fmt.Stringer:
+		// Types such as uuid.UUID or pgtype values may carry user data in their string form.
+		if _, isTime := value.(time.Time); isTime {
+			return value
+		}
+		return sqlMaskedValue
+	}
+
+	reflected := reflect.ValueOf(arg)
+	switch reflected.Kind() {
+	case reflect.Pointer:
+		if reflected.IsNil() {
+			return nil
+		}
+		return sanitizeSQLArg(reflected.Elem().Interface())
+	case reflect.String:
+		// Named string types, e.g. type Email string, hold user data like plain strings
+		return fmt.Sprintf("%s(len=%d)", sqlMaskedValue, reflected.Len())
+	case reflect.Slice, reflect.Array:
+		if reflected.Type().Elem().Kind() == reflect.Uint8 {
+			// Named byte slices such as json.RawMessage
+			return fmt.Sprintf("%s(bytes=%d)", sqlMaskedValue, reflected.Len())
+		}
+		total := reflected.Len()
+		limit := min(total, sqlMaxLoggedArrayItems)
+
+		items := make([]any, 0, limit+1)
+		for i := range limit {
+			items = append(items, sanitizeSQLArg(reflected.Index(i).Interface()))
+		}
+		if total > limit {
+			items = append(items, fmt.Sprintf("...(+%d more)", total-limit))
+		}
+		return items
+	case reflect.Map, reflect.Struct:
+		return sqlMaskedValue
+	default:
+		return arg
+	}
+}
+
 // DatabaseLogger creates a zerolog-based logger tailored for database operations.
 // It outputs logs to the console with custom formatting:
 //   - Long strings are truncated to 200 characters.
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/routeaccess"
+	"github.com/labstack/echo/v4"
+)
+
+// Require returns the middlewares enforcing access: Authenticate and RequireRole or
+// RequireOperator for users, RequireInternalCaller for internal routes. Register routes with Declare too, or
+// through the router's helpers doing both, so the API docs show what they need.
+func (auth *AuthMiddleware) Require(access routeaccess.Access) []echo.MiddlewareFunc {
+	if access.Internal {
+		return []echo.MiddlewareFunc{auth.RequireInternalCaller(access.Services...)}
+	}
+
+	middlewares := []echo.MiddlewareFunc{auth.Authenticate}
+	if len(access.Roles) > 0 {
+		middlewares = append(middlewares, auth.RequireRole(access.Roles...))
+	}
+	if access.Operator {
+		middlewares = append(middlewares, auth.RequireOperator)
+	}
+	return middlewares
+}
+
+// Declare records the access of routes for the API docs, see routeaccess.Registry.Embed.
+func (auth *AuthMiddleware) Declare(access routeaccess.Access, routes ...*echo.Route) {
+	for _, route := range routes {
+		auth.server.RouteAccess.Record(route.Method, route.Path, access)
+	}
+}
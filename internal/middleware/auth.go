@@ -1,17 +1,26 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/bruteforce"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/oidc"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
 	"github.com/clerk/clerk-sdk-go/v2"
 	clerkHttp "github.com/clerk/clerk-sdk-go/v2/http"
 	"github.com/labstack/echo/v4"
 )
 
+// AdminRole is the Clerk role of organization admins, e.g. allowed to manage the billing
+// of their organization. It is held by whoever creates an organization and grants nothing
+// beyond it, the /admin routes require a platform operator (see RequireOperator).
+const AdminRole = "org:admin"
+
 type AuthMiddleware struct {
 	server *server.Server
 }
@@ -24,14 +33,50 @@ func NewAuthMiddleware(s *server.Server) *AuthMiddleware {
 	}
 }
 
-// Authenticate is an Echo middleware that checks if the incoming request is authenticated via Clerk.
-// It wraps Clerk's HTTP middleware to handle Authorization headers and session validation.
+// Authenticate is an Echo middleware that checks if the incoming request is authenticated,
+// via Clerk or, with the oidc auth provider, a token of the configured OpenID Connect provider.
 // On authentication failure, it returns a JSON 401 response and logs the error.
 // On success, it extracts user claims from the context and stores them for downstream handlers.
+// Failures are counted per IP, clients failing repeatedly are delayed and then locked out
+// (see ProtectCredentials).
 func (auth *AuthMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return TimeStage(StageAuth, auth.authenticate)(next)
+}
+
+func (auth *AuthMiddleware) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	var authenticate echo.HandlerFunc
+	if auth.server.OIDC != nil {
+		authenticate = auth.authenticateOIDC(next)
+	} else {
+		authenticate = auth.authenticateClerk(next)
+	}
+
+	return func(c echo.Context) error {
+		ip := bruteforce.IPKey(c.RealIP())
+		if err := auth.checkAttempt(c, ip); err != nil {
+			return err
+		}
+
+		err := authenticate(c)
+
+		// The user is only missing if authentication failed, whatever the handler returned.
+		if GetUserID(c) == "" {
+			auth.recordFailure(c, ip)
+		}
+
+		return err
+	}
+}
+
+// authenticateClerk wraps Clerk's HTTP middleware to handle Authorization headers and session validation.
+func (auth *AuthMiddleware) authenticateClerk(next echo.HandlerFunc) echo.HandlerFunc {
 	return echo.WrapMiddleware(
 		// This wraps Clerk’s HTTP middleware to handle Authorization headers and manage session validation automatically.
 		clerkHttp.WithHeaderAuthorization(
+			// Custom claims of the session token, e.g. the user's public metadata marking operators.
+			clerkHttp.CustomClaimsConstructor(func(context.Context) any {
+				return &map[string]any{}
+			}),
 			// Custom handler for when Clerk authentication fails.
 			clerkHttp.AuthorizationFailureHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				start := time.Now()
@@ -75,6 +120,12 @@ func (auth *AuthMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc
 		c.Set("user_id", claims.Subject)
 		c.Set("user_role", claims.ActiveOrganizationRole)
 		c.Set("permissions", claims.Claims.ActiveOrganizationPermissions)
+		c.Set(OrganizationIDKey, claims.ActiveOrganizationID)
+
+		custom, _ := claims.Custom.(*map[string]any)
+		if custom != nil {
+			c.Set(OperatorKey, (&oidc.Claims{Raw: *custom}).Bool(auth.server.Config.Auth.OperatorClaim))
+		}
 
 		// Log successful authentication for visibility and debugging.
 		auth.server.Logger.Info().
@@ -87,3 +138,76 @@ func (auth *AuthMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc
 		return next(c)
 	})
 }
+
+// authenticateOIDC verifies the bearer token against the keys of the OpenID Connect
+// provider and stores the same user information as Clerk, read from the configured claims.
+func (auth *AuthMiddleware) authenticateOIDC(next echo.HandlerFunc) echo.HandlerFunc {
+	cfg := auth.server.Config.Auth.OIDC
+
+	return func(c echo.Context) error {
+		start := time.Now()
+
+		token, found := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if !found {
+			token = ""
+		}
+
+		claims, err := auth.server.OIDC.Verify(c.Request().Context(), strings.TrimSpace(token), start)
+		if err != nil {
+			auth.server.Logger.Error().
+				Err(err).
+				Str("function", "Authenticate").
+				Str("request_id", GetRequestID(c)).
+				Dur("duration", time.Since(start)).
+				Msg("could not verify oidc token")
+
+			return errs.UnauthorizedError("Unauthorized", false)
+		}
+
+		c.Set("user_id", claims.Subject)
+		c.Set("user_role", claims.String(cfg.RoleClaim))
+		c.Set("permissions", claims.Strings(cfg.PermissionsClaim))
+		c.Set(OrganizationIDKey, claims.String(cfg.OrganizationClaim))
+		c.Set(OperatorKey, claims.Bool(auth.server.Config.Auth.OperatorClaim))
+
+		auth.server.Logger.Info().
+			Str("function", "Authenticate").
+			Str("user_id", claims.Subject).
+			Str("request_id", GetRequestID(c)).
+			Dur("duration", time.Since(start)).
+			Msg("user authenticated successfully")
+
+		return next(c)
+	}
+}
+
+// RequireRole rejects authenticated requests whose active organization role is not one of roles.
+// It must run after Authenticate, which stores the role.
+func (auth *AuthMiddleware) RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role, _ := c.Get(UserRoleKey).(string)
+			for _, allowed := range roles {
+				if role == allowed {
+					return next(c)
+				}
+			}
+
+			return errs.ForbididdenError("Insufficient permissions", false)
+		}
+	}
+}
+
+// RequireOperator rejects authenticated requests of users who aren't platform operators,
+// per the claim named by auth.operator_claim. Organization roles don't count, every
+// customer is the admin of the organizations they create. It must run after
+// Authenticate, which reads the claim.
+func (auth *AuthMiddleware) RequireOperator(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !IsOperator(c) {
+			return errs.ForbididdenError("Insufficient permissions", false)
+		}
+
+		return next(c)
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/authz"
+	"github.com/labstack/echo/v4"
+)
+
+// Authorize rejects authenticated requests the policy engine (see lib/authz) doesn't
+// allow to perform action on the resource named by resource. It must run after
+// Authenticate, which stores the user, role and organization the policies match.
+//
+//	r.GET("/exports/:id", h.Export.Get, m.AuthMiddleware.Authorize("exports:read", func(c echo.Context) string {
+//		return "organization/" + middleware.GetOrganizationID(c) + "/exports/" + c.Param("id")
+//	}))
+func (auth *AuthMiddleware) Authorize(action string, resource func(c echo.Context) string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role, _ := c.Get(UserRoleKey).(string)
+			subject := authz.Subject{
+				UserID:         GetUserID(c),
+				Role:           role,
+				OrganizationID: GetOrganizationID(c),
+			}
+
+			if !auth.server.Authz.Can(c.Request().Context(), subject, action, resource(c)) {
+				return errs.ForbididdenError("Insufficient permissions", false)
+			}
+
+			return next(c)
+		}
+	}
+}
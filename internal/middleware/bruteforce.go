@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/bruteforce"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/labstack/echo/v4"
+)
+
+// Codes of clients blocked after repeated authentication failures.
+var (
+	codeAuthLocked    = errs.Register("AUTH_LOCKED", http.StatusLocked, "Too many failed authentication attempts, the client is locked out until Retry-After.")
+	codeAuthThrottled = errs.Register("AUTH_THROTTLED", http.StatusTooManyRequests, "Too many failed authentication attempts, the client must wait for Retry-After.")
+)
+
+// ProtectCredentials guards endpoints verifying credentials (e.g. a login, a password
+// reset or an MFA code) from guessing. identify returns the user the attempt is for,
+// empty if unknown; failures are counted per IP and per user. A handler error with a
+// 401 or 403 status counts as a failed attempt, success clears the user's failures.
+// Authenticate protects itself, per IP.
+//
+//	r.POST("/mfa/verify", h.MFA.Verify, m.AuthMiddleware.ProtectCredentials(func(c echo.Context) string {
+//		return middleware.GetUserID(c)
+//	}))
+func (auth *AuthMiddleware) ProtectCredentials(identify func(c echo.Context) string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			keys := []string{bruteforce.IPKey(c.RealIP())}
+			if userID := identify(c); userID != "" {
+				keys = append(keys, bruteforce.UserKey(userID))
+			}
+
+			if err := auth.checkAttempt(c, keys...); err != nil {
+				return err
+			}
+
+			err := next(c)
+
+			var httpErr *errs.HttpError
+			if errors.As(err, &httpErr) && (httpErr.Status == http.StatusUnauthorized || httpErr.Status == http.StatusForbidden) {
+				auth.recordFailure(c, keys...)
+			} else if err == nil && len(keys) > 1 {
+				// Only the user's failures: one valid login must not clear an IP spraying others.
+				if resetErr := auth.server.BruteForce.Reset(c.Request().Context(), keys[1]); resetErr != nil {
+					GetLogger(c).Warn().Err(resetErr).Msg("failed to reset authentication failures")
+				}
+			}
+
+			return err
+		}
+	}
+}
+
+// checkAttempt refuses the attempt while one of keys is locked out (423) or has to wait
+// out its delay (429), with a Retry-After header. If Redis is unavailable attempts are
+// let through, like rate limits.
+func (auth *AuthMiddleware) checkAttempt(c echo.Context, keys ...string) error {
+	if auth.server.BruteForce == nil {
+		return nil
+	}
+
+	block, err := auth.server.BruteForce.Check(c.Request().Context(), time.Now(), keys...)
+	if err != nil {
+		GetLogger(c).Warn().Err(err).Msg("brute-force check failed, allowing request")
+		return nil
+	}
+	if block == nil {
+		return nil
+	}
+
+	c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(block.RetryAfter.Seconds())+1))
+
+	if block.Locked {
+		auth.recordAuthMetric("Locked")
+		code := codeAuthLocked
+		return errs.LockedError("Too many failed authentication attempts, try again later", true, &code)
+	}
+
+	auth.recordAuthMetric("Throttled")
+	code := codeAuthThrottled
+	return errs.TooManyRequestsError("Too many failed authentication attempts, slow down", true, &code)
+}
+
+// recordFailure counts a failed attempt against every key, publishing a lockout event
+// (written to the audit log) for the keys it locks out.
+func (auth *AuthMiddleware) recordFailure(c echo.Context, keys ...string) {
+	auth.recordAuthMetric("Failures")
+
+	if auth.server.BruteForce == nil {
+		return
+	}
+
+	ctx := c.Request().Context()
+	now := time.Now()
+
+	for _, key := range keys {
+		failure, err := auth.server.BruteForce.Fail(ctx, now, key)
+		if err != nil {
+			GetLogger(c).Warn().Err(err).Msg("failed to record authentication failure")
+			continue
+		}
+
+		if failure.LockedFor == 0 {
+			continue
+		}
+
+		GetLogger(c).Warn().
+			Str("key", key).
+			Int("failures", failure.Failures).
+			Dur("locked_for", failure.LockedFor).
+			Msg("authentication locked out after repeated failures")
+
+		auth.recordAuthMetric("Lockouts")
+		observe.Event("AuthLockout", map[string]any{
+			"key":      key,
+			"failures": failure.Failures,
+			"path":     c.Path(),
+		})
+
+		auth.server.Events.Publish(ctx, bruteforce.LockoutEvent, bruteforce.Lockout{
+			Key:      key,
+			Failures: failure.Failures,
+			Until:    now.Add(failure.LockedFor),
+			IP:       c.RealIP(),
+			Path:     c.Path(),
+		})
+	}
+}
+
+// recordAuthMetric counts authentication failures, throttled and locked out attempts and
+// lockouts in New Relic (Custom/Auth/<name>), for alerting on brute-force attacks.
+func (auth *AuthMiddleware) recordAuthMetric(name string) {
+	if auth.server.LoggerService != nil && auth.server.LoggerService.GetNewRelicApp() != nil {
+		auth.server.LoggerService.GetNewRelicApp().RecordCustomMetric("Custom/Auth/"+name, 1)
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/captcha"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// Codes of requests failing the CAPTCHA check, the action asks to show the widget again.
+var (
+	codeCaptchaRequired = errs.Register("CAPTCHA_REQUIRED", http.StatusBadRequest, "The route requires a CAPTCHA token, none was sent.")
+	codeCaptchaInvalid  = errs.Register("CAPTCHA_INVALID", http.StatusBadRequest, "The CAPTCHA token was rejected by the provider or scored too low.")
+)
+
+// CaptchaMiddleware verifies CAPTCHA tokens on abuse-prone endpoints such as signup,
+// login and contact forms.
+type CaptchaMiddleware struct {
+	server *server.Server
+}
+
+func NewCaptchaMiddleware(s *server.Server) *CaptchaMiddleware {
+	return &CaptchaMiddleware{
+		server: s,
+	}
+}
+
+// RequireCaptcha rejects requests without a valid token from the configured provider
+// (see config.CaptchaConfig) in the token header. Failures are a 400 whose action tells
+// the frontend to re-challenge the user, tokens are single use.
+//
+//	r.POST("/contact", h.Contact.Send, m.CaptchaMiddleware.RequireCaptcha())
+func (cm *CaptchaMiddleware) RequireCaptcha() echo.MiddlewareFunc {
+	cfg := cm.server.Config.Captcha
+	verifier := cm.server.Captcha
+	env := cm.server.Config.Primary.Env
+	bypass := verifier == nil && (env == "local" || env == "development")
+
+	if verifier == nil && !bypass {
+		cm.server.Logger.Error().Msg("captcha verification is not configured, requests will be rejected")
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if bypass {
+				return next(c)
+			}
+			if verifier == nil {
+				return errs.InternalServerError()
+			}
+
+			result, err := verifier.Verify(c.Request().Context(), c.Request().Header.Get(cfg.TokenHeader), c.RealIP())
+			switch {
+			case err == nil:
+				return next(c)
+			case errors.Is(err, captcha.ErrMissingToken):
+				return captchaError(verifier, codeCaptchaRequired, "Please complete the CAPTCHA challenge")
+			case errors.Is(err, captcha.ErrRejected), errors.Is(err, captcha.ErrLowScore):
+				GetLogger(c).Warn().Err(err).Strs("error_codes", result.ErrorCodes).Msg("rejected captcha token")
+				return captchaError(verifier, codeCaptchaInvalid, "CAPTCHA verification failed, please try again")
+			default:
+				// The provider is unreachable, that's not the user's fault.
+				GetLogger(c).Error().Err(err).Msg("failed to verify captcha token")
+				return echo.NewHTTPError(http.StatusServiceUnavailable, "CAPTCHA verification is temporarily unavailable")
+			}
+		}
+	}
+}
+
+func captchaError(verifier *captcha.Verifier, code, message string) *errs.HttpError {
+	return errs.BadRequestError(message, true, &code, nil, &errs.Action{
+		Type:    string(errs.ActionTypeRechallenge),
+		Message: message,
+		Value:   string(verifier.Provider()),
+	})
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Names of the global middlewares, used to order and skip them in a Chain.
+const (
+	StageServerHeaders  = "server_headers"
+	StageRateLimit      = "rate_limit"
+	StageCORS           = "cors"
+	StageSecure         = "secure"
+	StageBodyLimit      = "body_limit"
+	StageDecompress     = "decompress"
+	StageResponse       = "response_controller"
+	StageResponseWriter = "response_writer"
+	StageRequestID      = "request_id"
+	StageTracing        = "tracing"
+	StageEnhanceTracing = "enhance_tracing"
+	StageContext        = "context"
+	StageLogger         = "logger"
+	StageSlowRequest    = "slow_request"
+	StageLatencyBudget  = "latency_budget"
+	StageNPlusOne       = "n_plus_one"
+	StageUsage          = "usage"
+	StageRecover        = "recover"
+	StageListener       = "listener"
+	StageInFlight       = "in_flight"
+	StageRouteToggle    = "route_toggle"
+	StageExamples       = "examples"
+)
+
+// stageRequirements lists, per stage, the stages that must run before it. It is the
+// single place ordering rules live, so no router can build a chain that breaks them.
+var stageRequirements = map[string][]string{
+	// Tracing wraps the response writer, per-route write timeouts need the connection's controller first.
+	StageTracing:        {StageResponse},
+	StageEnhanceTracing: {StageRequestID, StageTracing},
+	StageContext:        {StageRequestID},
+	StageLogger:         {StageRequestID, StageContext, StageResponseWriter},
+	StageSlowRequest:    {StageRequestID, StageContext},
+	StageLatencyBudget:  {StageRequestID, StageContext},
+	StageNPlusOne:       {StageRequestID, StageContext},
+	StageRecover:        {StageLogger},
+	// Listener chains run with the request's logger and the global panic recovery.
+	StageListener: {StageRecover},
+	// Capture failures are logged with the request's logger.
+	StageExamples: {StageContext},
+	// Requests of disabled routes are rejected with the request's logger.
+	StageRouteToggle: {StageLogger},
+}
+
+type chainEntry struct {
+	name       string
+	middleware echo.MiddlewareFunc
+	skip       []string
+}
+
+// Chain composes the global middleware chain declaratively. Stages are added in the
+// order they run, individual stages can be skipped for path prefixes (e.g. no rate
+// limit on health checks), and Build refuses chains violating the ordering rules.
+type Chain struct {
+	entries []*chainEntry
+	errs    []error
+	timings *MiddlewareTimingMiddleware
+}
+
+// Chain returns an empty chain builder. The stages of the chain it builds are timed
+// on the requests MiddlewareTiming samples.
+func (m *Middlewares) Chain() *Chain {
+	return &Chain{timings: m.MiddlewareTiming}
+}
+
+// Use appends the stage name running mw.
+func (ch *Chain) Use(name string, mw echo.MiddlewareFunc) *Chain {
+	if ch.find(name) != nil {
+		ch.errs = append(ch.errs, fmt.Errorf("middleware %s is added twice", name))
+		return ch
+	}
+
+	ch.entries = append(ch.entries, &chainEntry{name: name, middleware: mw})
+	return ch
+}
+
+// SkipFor bypasses the stage name for requests whose path starts with one of prefixes.
+func (ch *Chain) SkipFor(name string, prefixes ...string) *Chain {
+	entry := ch.find(name)
+	if entry == nil {
+		ch.errs = append(ch.errs, fmt.Errorf("cannot skip middleware %s, it is not in the chain", name))
+		return ch
+	}
+
+	entry.skip = append(entry.skip, prefixes...)
+	return ch
+}
+
+// Build validates the chain and returns the middlewares in order, ready for echo's Use.
+func (ch *Chain) Build() ([]echo.MiddlewareFunc, error) {
+	errList := append([]error(nil), ch.errs...)
+
+	position := make(map[string]int, len(ch.entries))
+	for i, entry := range ch.entries {
+		position[entry.name] = i
+	}
+
+	for i, entry := range ch.entries {
+		// A skipped stage would leave the stages depending on it without their input.
+		if dependents := ch.dependents(entry.name); len(entry.skip) > 0 && len(dependents) > 0 {
+			errList = append(errList, fmt.Errorf("cannot skip middleware %s, %s depend on it", entry.name, strings.Join(dependents, ", ")))
+		}
+
+		for _, required := range stageRequirements[entry.name] {
+			at, ok := position[required]
+			if !ok {
+				errList = append(errList, fmt.Errorf("middleware %s requires %s, which is not in the chain", entry.name, required))
+			} else if at > i {
+				errList = append(errList, fmt.Errorf("middleware %s must run after %s", entry.name, required))
+			}
+		}
+	}
+
+	if len(errList) > 0 {
+		return nil, fmt.Errorf("invalid middleware chain: %w", errors.Join(errList...))
+	}
+
+	timed := ch.timings != nil && ch.timings.enabled()
+
+	middlewares := make([]echo.MiddlewareFunc, 0, len(ch.entries)+1)
+	if timed {
+		middlewares = append(middlewares, ch.timings.Record())
+	}
+	for _, entry := range ch.entries {
+		if timed {
+			middlewares = append(middlewares, TimeStage(entry.name, entry.build()))
+		} else {
+			middlewares = append(middlewares, entry.build())
+		}
+	}
+
+	return middlewares, nil
+}
+
+func (ch *Chain) find(name string) *chainEntry {
+	for _, entry := range ch.entries {
+		if entry.name == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+// dependents returns the stages in the chain that require name.
+func (ch *Chain) dependents(name string) []string {
+	var names []string
+	for _, entry := range ch.entries {
+		for _, required := range stageRequirements[entry.name] {
+			if required == name {
+				names = append(names, entry.name)
+			}
+		}
+	}
+	return names
+}
+
+func (entry *chainEntry) build() echo.MiddlewareFunc {
+	if len(entry.skip) == 0 {
+		return entry.middleware
+	}
+
+	skip := entry.skip
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		wrapped := entry.middleware(next)
+
+		return func(c echo.Context) error {
+			path := c.Request().URL.Path
+			for _, prefix := range skip {
+				if strings.HasPrefix(path, prefix) {
+					return next(c)
+				}
+			}
+			return wrapped(c)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package middleware
 import (
 	"context"
 
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/snapshot"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/logger"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
 	"github.com/labstack/echo/v4"
@@ -11,8 +13,10 @@ import (
 )
 
 const (
-	UserRoleKey = "user_role"
-	UserIDkEY   = "user_id"
+	UserRoleKey       = "user_role"
+	UserIDkEY         = "user_id"
+	OrganizationIDKey = "organization_id"
+	OperatorKey       = "operator"
 )
 
 // contextKey is unexported so other packages can't collide with our keys.
@@ -67,8 +71,14 @@ func (ce *ContextEnhancer) EnhanceContext() echo.MiddlewareFunc {
 			// Store the enhanced logger in Echo’s context so handlers can access it
 			c.Set(echoLoggerKey, &contextLogger)
 
-			// create a new context with the logger
+			// create a new context with the logger and the request ID, so database transactions can be traced back to the request
 			ctx := context.WithValue(c.Request().Context(), loggerKey, &contextLogger)
+			ctx = database.WithRequestID(ctx, requestID)
+			// Collects what the request evaluates, for the snapshot of its error reports
+			ctx, _ = snapshot.WithRecorder(ctx)
+			if timeout := ce.server.Config.Database.RequestStatementTimeout; timeout > 0 {
+				ctx = database.WithStatementTimeout(ctx, timeout)
+			}
 			c.SetRequest(c.Request().WithContext(ctx))
 
 			return next(c)
@@ -110,3 +120,18 @@ func GetUserID(c echo.Context) string {
 	}
 	return ""
 }
+
+// IsOperator reports whether the authenticated user is a platform operator, see
+// AuthMiddleware.RequireOperator.
+func IsOperator(c echo.Context) bool {
+	operator, _ := c.Get(OperatorKey).(bool)
+	return operator
+}
+
+func GetOrganizationID(c echo.Context) string {
+	organizationID, ok := c.Get(OrganizationIDKey).(string)
+	if ok {
+		return organizationID
+	}
+	return ""
+}
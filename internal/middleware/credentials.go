@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/labstack/echo/v4"
+)
+
+// InvalidCredentialsCode is the code of every rejected credential, whatever was wrong with it.
+var InvalidCredentialsCode = errs.Register("INVALID_CREDENTIALS", http.StatusUnauthorized, "The credentials were rejected, the response doesn't tell which part was wrong.")
+
+// NormalizeCredentialResponses keeps endpoints taking credentials (e.g. a login or a
+// magic link request) from revealing which accounts exist. Rejections (401, 403 and 404:
+// unknown user, wrong password, disabled account, ...) all become the same 401 with the
+// same message, and no response leaves before the configured minimum response time,
+// rounded up to a multiple of it, so an unknown user isn't answered faster than a wrong
+// password. Throttling and lockouts (429, 423) pass through. Use it outside ProtectCredentials.
+//
+//	r.POST("/login", h.Auth.Login, m.AuthMiddleware.NormalizeCredentialResponses(), m.AuthMiddleware.ProtectCredentials(identify))
+func (auth *AuthMiddleware) NormalizeCredentialResponses() echo.MiddlewareFunc {
+	floor := auth.server.Config.BruteForce.MinResponseTime
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			ctx := c.Request().Context()
+
+			// Responses the handler writes itself are held back until the floor
+			res := c.Response()
+			writer := &paddedWriter{ResponseWriter: res.Writer, ctx: ctx, start: start, floor: floor}
+			res.Writer = writer
+			defer func() { res.Writer = writer.ResponseWriter }()
+
+			err := next(c)
+
+			var httpErr *errs.HttpError
+			if errors.As(err, &httpErr) {
+				switch httpErr.Status {
+				case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+					rejected := errs.UnauthorizedError("Invalid credentials", true)
+					rejected.Code = InvalidCredentialsCode
+					err = rejected
+				}
+			}
+
+			// Errors are written by the error handler once the chain returns
+			writer.wait()
+
+			return err
+		}
+	}
+}
+
+// paddedWriter delays the response until start plus floor, or the next multiple of floor.
+type paddedWriter struct {
+	http.ResponseWriter
+	ctx    context.Context
+	start  time.Time
+	floor  time.Duration
+	waited bool
+}
+
+func (w *paddedWriter) wait() {
+	if w.waited || w.floor <= 0 {
+		return
+	}
+	w.waited = true
+
+	elapsed := time.Since(w.start)
+	padded := w.floor
+	if elapsed > padded {
+		padded = (elapsed + w.floor - 1) / w.floor * w.floor
+	}
+
+	timer := time.NewTimer(padded - elapsed)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-w.ctx.Done():
+	}
+}
+
+func (w *paddedWriter) WriteHeader(status int) {
+	w.wait()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *paddedWriter) Write(b []byte) (int, error) {
+	w.wait()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the connection.
+func (w *paddedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
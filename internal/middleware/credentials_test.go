@@ -0,0 +1,134 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveLogin serves a POST /login answered by handler behind NormalizeCredentialResponses
+// with a minimum response time of floor, and returns the response and how long it took.
+func serveLogin(t *testing.T, floor time.Duration, handler echo.HandlerFunc) (*httptest.ResponseRecorder, time.Duration) {
+	t.Helper()
+
+	srv := &server.Server{Config: &config.Config{
+		Primary:    config.Primary{Env: "production"},
+		BruteForce: config.BruteForceConfig{MinResponseTime: floor},
+	}}
+
+	e := echo.New()
+	e.HTTPErrorHandler = middleware.NewGlobalMiddleWare(srv).GlobalErrorHandler
+	e.POST("/login", handler, middleware.NewAuthMiddleware(srv).NormalizeCredentialResponses())
+
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", nil))
+
+	return rec, time.Since(start)
+}
+
+func TestNormalizeCredentialResponsesCollapsesRejections(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "unauthorized", err: errs.UnauthorizedError("Wrong password", false)},
+		{name: "forbidden", err: errs.ForbididdenError("Account disabled", false)},
+		{name: "not found", err: errs.NotFoundError("User not found", false, nil)},
+	}
+
+	bodies := make([]string, len(tests))
+	for i, tt := range tests {
+		rec, _ := serveLogin(t, 0, func(echo.Context) error { return tt.err })
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, tt.name)
+		assert.Contains(t, rec.Body.String(), middleware.InvalidCredentialsCode, tt.name)
+		bodies[i] = rec.Body.String()
+	}
+
+	for i := range bodies[1:] {
+		assert.Equal(t, bodies[0], bodies[i+1], "%s and %s answered differently", tests[0].name, tests[i+1].name)
+	}
+}
+
+func TestNormalizeCredentialResponsesPassesThrottling(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "too many requests", err: errs.TooManyRequestsError("Slow down", true, nil), status: http.StatusTooManyRequests},
+		{name: "locked", err: errs.LockedError("Locked out", true, nil), status: http.StatusLocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec, _ := serveLogin(t, 0, func(echo.Context) error { return tt.err })
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.NotContains(t, rec.Body.String(), middleware.InvalidCredentialsCode)
+		})
+	}
+}
+
+func TestNormalizeCredentialResponsesPadsToFloor(t *testing.T) {
+	t.Parallel()
+
+	floor := 50 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		handler echo.HandlerFunc
+		status  int
+		atLeast time.Duration
+	}{
+		{
+			name:    "rejection",
+			handler: func(echo.Context) error { return errs.NotFoundError("User not found", false, nil) },
+			status:  http.StatusUnauthorized,
+			atLeast: floor,
+		},
+		{
+			name:    "success",
+			handler: func(c echo.Context) error { return c.NoContent(http.StatusOK) },
+			status:  http.StatusOK,
+			atLeast: floor,
+		},
+		{
+			name: "slower than the floor",
+			handler: func(c echo.Context) error {
+				time.Sleep(floor + floor/2)
+				return c.NoContent(http.StatusOK)
+			},
+			status: http.StatusOK,
+			// Rounded up to the next multiple of the floor
+			atLeast: 2 * floor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec, elapsed := serveLogin(t, floor, tt.handler)
+
+			require.Equal(t, tt.status, rec.Code)
+			assert.GreaterOrEqual(t, elapsed, tt.atLeast)
+		})
+	}
+}
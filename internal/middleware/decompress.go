@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/bytes"
+)
+
+var (
+	codeUnsupportedContentEncoding = errs.Register("UNSUPPORTED_CONTENT_ENCODING", http.StatusUnsupportedMediaType, "The request body is compressed with an encoding the API doesn't accept, send it as identity or one of the accepted encodings.")
+	codeInvalidCompressedBody      = errs.Register("INVALID_COMPRESSED_BODY", http.StatusBadRequest, "The request body doesn't decompress with its Content-Encoding, it is corrupt or truncated.")
+	codeDecompressedBodyTooLarge   = errs.Register("DECOMPRESSED_BODY_TOO_LARGE", http.StatusRequestEntityTooLarge, "The request body is larger than the decompressed size limit once decompressed.")
+)
+
+// Decompress decompresses request bodies sent with a Content-Encoding of the configured
+// server decompression encodings, so handlers and binders read them as if sent plain.
+// It runs after BodyLimit, which caps the compressed body, and caps the decompressed
+// stream at the decompression max size so a zip bomb is cut off, not inflated in memory.
+// Other encodings, and every encoding while decompression is disabled, answer 415.
+func (gm *GlobalMiddleware) Decompress() echo.MiddlewareFunc {
+	cfg := gm.server.Config.Server.Decompression
+
+	// Validated with the config
+	limit, _ := bytes.Parse(cfg.MaxSize)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			encoding := strings.ToLower(strings.TrimSpace(req.Header.Get(echo.HeaderContentEncoding)))
+			if encoding == "" || encoding == "identity" {
+				return next(c)
+			}
+
+			if !cfg.Enabled || !slices.Contains(cfg.Encodings, encoding) {
+				accepted := "identity"
+				if cfg.Enabled {
+					accepted = strings.Join(append([]string{"identity"}, cfg.Encodings...), ", ")
+				}
+				message := fmt.Sprintf("Content-Encoding %s is not supported, use one of: %s", encoding, accepted)
+				return errs.UnsupportedMediaTypeError(message, true, &codeUnsupportedContentEncoding)
+			}
+
+			source := &sourceReader{r: req.Body}
+			decompressor, err := newDecompressor(encoding, source)
+			if err != nil {
+				if source.err != nil {
+					return source.err
+				}
+				return errs.BadRequestError("The request body is not valid "+encoding, true, &codeInvalidCompressedBody, nil, nil)
+			}
+
+			req.Body = &decompressedBody{
+				decompressor: decompressor,
+				source:       source,
+				body:         req.Body,
+				encoding:     encoding,
+				limit:        limit,
+				remaining:    limit,
+			}
+			// The decompressed length is unknown, and the body is no longer encoded
+			req.Header.Del(echo.HeaderContentEncoding)
+			req.Header.Del(echo.HeaderContentLength)
+			req.ContentLength = -1
+
+			return next(c)
+		}
+	}
+}
+
+// newDecompressor reads the header of the compressed stream. HTTP's deflate is a zlib
+// stream, but some clients send raw deflate data, both are accepted.
+func newDecompressor(encoding string, source io.Reader) (io.ReadCloser, error) {
+	if encoding == "gzip" {
+		return gzip.NewReader(source)
+	}
+
+	buffered := bufio.NewReader(source)
+	header, err := buffered.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	// A zlib header is a deflate method byte whose 16 bit value is a multiple of 31
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// sourceReader remembers the errors of the compressed body, e.g. BodyLimit's 413, so
+// they aren't mistaken for corrupt data.
+type sourceReader struct {
+	r   io.Reader
+	err error
+}
+
+func (s *sourceReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil && err != io.EOF {
+		s.err = err
+	}
+	return n, err
+}
+
+// decompressedBody is the request body Decompress hands on, its read errors are
+// HttpErrors so binding a corrupt or oversized body answers with their status.
+type decompressedBody struct {
+	decompressor io.ReadCloser
+	source       *sourceReader
+	body         io.ReadCloser
+	encoding     string
+	limit        int64
+	remaining    int64
+}
+
+func (d *decompressedBody) Read(p []byte) (int, error) {
+	// Read one byte past the limit to tell a body of exactly the limit from a larger one
+	if int64(len(p)) > d.remaining+1 {
+		p = p[:d.remaining+1]
+	}
+
+	n, err := d.decompressor.Read(p)
+	if int64(n) > d.remaining {
+		message := fmt.Sprintf("The decompressed request body is larger than %s", bytes.Format(d.limit))
+		return 0, errs.PayloadTooLargeError(message, true, &codeDecompressedBodyTooLarge)
+	}
+	d.remaining -= int64(n)
+
+	if err != nil && err != io.EOF {
+		if d.source.err != nil {
+			return n, d.source.err
+		}
+		return n, errs.BadRequestError("The request body is not valid "+d.encoding, true, &codeInvalidCompressedBody, nil, nil)
+	}
+
+	return n, err
+}
+
+func (d *decompressedBody) Close() error {
+	return errors.Join(d.decompressor.Close(), d.body.Close())
+}
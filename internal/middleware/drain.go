@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// internalPathPrefix groups endpoints called by the orchestrator rather than clients.
+// They are not counted as in-flight, otherwise the preStop call would wait on itself.
+const internalPathPrefix = "/internal/"
+
+// DrainMiddleware counts in-flight requests so a preStop hook can wait for them to finish.
+type DrainMiddleware struct {
+	server *server.Server
+}
+
+// NewDrainMiddleware returns a new DrainMiddleware tied to the server.
+func NewDrainMiddleware(s *server.Server) *DrainMiddleware {
+	return &DrainMiddleware{
+		server: s,
+	}
+}
+
+// TrackInFlight increments the in-flight counter for the duration of every request
+// except the internal orchestration endpoints.
+func (dm *DrainMiddleware) TrackInFlight() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if strings.HasPrefix(c.Request().URL.Path, internalPathPrefix) {
+				return next(c)
+			}
+
+			dm.server.Drain.Begin()
+			defer dm.server.Drain.End()
+
+			return next(c)
+		}
+	}
+}
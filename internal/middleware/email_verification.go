@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+var codeEmailNotVerified = errs.Register("EMAIL_NOT_VERIFIED", http.StatusForbidden, "The endpoint requires a verified email address, the user hasn't verified theirs.")
+
+// requestVerificationPath is where clients send the verification link from.
+const requestVerificationPath = "/api/v1/me/email-verification"
+
+// EmailVerificationMiddleware keeps users who haven't verified their email address out
+// of selected routes, see internal/lib/emailverify.
+type EmailVerificationMiddleware struct {
+	server *server.Server
+}
+
+// NewEmailVerificationMiddleware returns a new EmailVerificationMiddleware tied to the server.
+func NewEmailVerificationMiddleware(s *server.Server) *EmailVerificationMiddleware {
+	return &EmailVerificationMiddleware{
+		server: s,
+	}
+}
+
+// RequireVerifiedEmail answers 403 EMAIL_NOT_VERIFIED to users whose email address isn't
+// verified, with a verify_email action pointing at the endpoint sending the link. Until
+// email.verification.required is set it only logs them. It must run after Authenticate.
+func (ev *EmailVerificationMiddleware) RequireVerifiedEmail() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID := GetUserID(c)
+
+			verified, err := ev.server.Verification.Verified(c.Request().Context(), userID)
+			if err != nil {
+				return err
+			}
+			if verified {
+				return next(c)
+			}
+
+			if !ev.server.Config.Email.Verification.Required {
+				GetLogger(c).Info().Str("route", c.Path()).Msg("unverified email let through, verification is not required yet")
+				return next(c)
+			}
+
+			return errs.ForbiddenCodeError("Verify your email address to continue", true, &codeEmailNotVerified, &errs.Action{
+				Type:    string(errs.ActionTypeVerifyEmail),
+				Message: "A verification link is sent to the address posted to this endpoint",
+				Value:   requestVerificationPath,
+			})
+		}
+	}
+}
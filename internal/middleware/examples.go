@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/examples"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// ExampleCaptureMiddleware records served requests and their responses, anonymized, as
+// examples of their route for the API docs (see config.DocsConfig).
+type ExampleCaptureMiddleware struct {
+	server *server.Server
+	store  *examples.Store
+}
+
+func NewExampleCaptureMiddleware(s *server.Server) *ExampleCaptureMiddleware {
+	return &ExampleCaptureMiddleware{
+		server: s,
+		store:  examples.NewStore(s.Config.Docs.ExamplesDir),
+	}
+}
+
+// Capture stores the request and response of every routed request its handler answered
+// without a server error, replacing the previous example of its route and status. It only
+// captures in local and development, real user data must never end up in the docs.
+func (em *ExampleCaptureMiddleware) Capture() echo.MiddlewareFunc {
+	cfg := em.server.Config.Docs
+	env := em.server.Config.Primary.Env
+	enabled := cfg.CaptureExamples && (env == "local" || env == "development")
+
+	if cfg.CaptureExamples && !enabled {
+		em.server.Logger.Warn().Str("env", env).Msg("docs example capture is only available in local and development, not capturing")
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !enabled {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			var requestBody []byte
+			if req.Body != nil && req.Body != http.NoBody {
+				read, err := io.ReadAll(io.LimitReader(req.Body, int64(cfg.MaxExampleSize)+1))
+				if err != nil {
+					return err
+				}
+				// The handler reads the whole body, whatever was read here included
+				req.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(read), req.Body), req.Body}
+
+				if len(read) <= cfg.MaxExampleSize {
+					requestBody = read
+				}
+			}
+
+			res := c.Response()
+			recorder := &exampleRecorder{ResponseWriter: res.Writer, limit: cfg.MaxExampleSize}
+			res.Writer = recorder
+			defer func() { res.Writer = recorder.ResponseWriter }()
+
+			// Errors are written by the error handler after the chain, only responses the
+			// handler wrote itself are captured
+			if err := next(c); err != nil {
+				return err
+			}
+
+			status := res.Status
+			if c.Path() == "" || status >= http.StatusInternalServerError || recorder.overflow {
+				return nil
+			}
+
+			example := examples.Example{
+				Method:     req.Method,
+				Path:       examples.OpenAPIPath(c.Path()),
+				Status:     status,
+				Request:    examples.Anonymize(requestBody),
+				Response:   examples.Anonymize(recorder.body.Bytes()),
+				CapturedAt: time.Now().UTC(),
+			}
+			if err := em.store.Save(example); err != nil {
+				GetLogger(c).Warn().Err(err).Str("path", example.Path).Msg("failed to capture docs example")
+			}
+
+			return nil
+		}
+	}
+}
+
+// exampleRecorder copies the response body, up to limit bytes, as it is written.
+type exampleRecorder struct {
+	http.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (r *exampleRecorder) Write(b []byte) (int, error) {
+	if !r.overflow {
+		if r.body.Len()+len(b) > r.limit {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to flush streams.
+func (r *exampleRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
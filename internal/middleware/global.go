@@ -1,14 +1,23 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"path"
+	"strings"
+	"sync/atomic"
 
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/snapshot"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/sqlerr"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
 	"github.com/labstack/echo/v4"
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/rs/zerolog"
 )
 
@@ -28,11 +37,36 @@ func NewGlobalMiddleWare(s *server.Server) *GlobalMiddleware {
 // CORS configures Cross-Origin Resource Sharing using allowed origins from server config.
 // This enables browsers to safely call the API from specified domains.
 func (gm *GlobalMiddleware) CORS() echo.MiddlewareFunc {
+	// The allowed origins follow config reloads
+	var origins atomic.Pointer[[]string]
+	origins.Store(&gm.server.Config.Server.CORSAllowedOrigins)
+	config.OnChange(func(cfg *config.Config) {
+		origins.Store(&cfg.Server.CORSAllowedOrigins)
+	})
+
 	return echoMiddleware.CORSWithConfig(echoMiddleware.CORSConfig{
-		AllowOrigins: gm.server.Config.Server.CORSAllowedOrigin,
+		AllowOriginFunc: func(origin string) (bool, error) {
+			return allowOrigin(*origins.Load(), origin), nil
+		},
 	})
 }
 
+// allowOrigin reports whether origin is one of allowed, which holds origins, "*" for any
+// or patterns with wildcards like https://*.example.com. Allowed origins are echoed back
+// in Access-Control-Allow-Origin, those allowed by "*" too.
+func allowOrigin(allowed []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, candidate := range allowed {
+		candidate = strings.ToLower(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if matched, _ := path.Match(candidate, origin); matched {
+			return true
+		}
+	}
+	return false
+}
 
 // RequestLogger logs every HTTP request passing through the server.
 // It captures request details, latency, and errors, using structured logging via zerolog.
@@ -92,7 +126,7 @@ func (gm *GlobalMiddleware) RequestLogger() echo.MiddlewareFunc {
 			}
 
 			// Log full structured data
-			e.Dur("latency", v.Latency).Int("status", statusCode).Str("method", v.Method).Str("uri", v.URI).Str("route", c.Path()).Str("host", v.Host).Str("ip", c.RealIP()).Str("user_agent", c.Request().UserAgent()).Msg("API")
+			e.Dur("latency", v.Latency).Int("status", statusCode).Int64("bytes_out", GetBytesOut(c)).Str("method", v.Method).Str("uri", v.URI).Str("route", c.Path()).Str("host", v.Host).Str("ip", c.RealIP()).Str("user_agent", c.Request().UserAgent()).Msg("API")
 			return nil
 		},
 	})
@@ -103,16 +137,63 @@ func (gm *GlobalMiddleware) Secure() echo.MiddlewareFunc {
 	return echoMiddleware.Secure()
 }
 
+// ServerHeaders replaces or strips the Server header and drops X-Powered-By, so responses
+// don't advertise software versions, and adds X-Service with the service name and version
+// when the server service header is on. Headers are rewritten right before they are
+// written, after handlers and error handling set theirs.
+func (gm *GlobalMiddleware) ServerHeaders() echo.MiddlewareFunc {
+	cfg := gm.server.Config
+	service := cfg.Observability.ServiceName + "/" + cfg.Primary.GetVersion()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			res := c.Response()
+			res.Before(func() {
+				header := res.Header()
+				if cfg.Server.ServerHeader != "" {
+					header.Set(echo.HeaderServer, cfg.Server.ServerHeader)
+				} else {
+					header.Del(echo.HeaderServer)
+				}
+				header.Del("X-Powered-By")
+
+				if cfg.Server.ServiceHeader {
+					header.Set("X-Service", service)
+				}
+			})
+
+			return next(c)
+		}
+	}
+}
+
+// BodyLimit rejects request bodies larger than the configured server body limit with a 413.
+func (gm *GlobalMiddleware) BodyLimit() echo.MiddlewareFunc {
+	return echoMiddleware.BodyLimit(gm.server.Config.Server.BodyLimit)
+}
+
+// ImportBodyLimit rejects import uploads larger than the configured import file size.
+// Import routes skip the global BodyLimit in the router and use this one instead.
+func (gm *GlobalMiddleware) ImportBodyLimit() echo.MiddlewareFunc {
+	return echoMiddleware.BodyLimit(gm.server.Config.Import.MaxFileSize)
+}
+
 // Recover gracefully handles panics to prevent the server from crashing.
-// It logs the panic and returns a generic 500 error to the client.
+// The panic is handed to GlobalErrorHandler with its stack, which reports it with a
+// snapshot of the request and returns a generic 500 error to the client.
 func (gm *GlobalMiddleware) Recover() echo.MiddlewareFunc {
-	return echoMiddleware.Recover()
+	return echoMiddleware.RecoverWithConfig(echoMiddleware.RecoverConfig{
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			c.Set(panicStackKey, stack)
+			return err
+		},
+	})
 }
 
 // GlobalErrorHandler provides centralized handling for any unhandled error in the app.
 // It ensures consistent JSON error responses and detailed server-side logging.
 func (gm *GlobalMiddleware) GlobalErrorHandler(err error, c echo.Context) {
-	
+
 	// Preserve stack trace and raw diagnostic info of original error for logging.
 	originalErr := err
 
@@ -126,8 +207,9 @@ func (gm *GlobalMiddleware) GlobalErrorHandler(err error, c echo.Context) {
 				err = errs.NotFoundError("Route not found", false, nil)
 			}
 		} else {
-			/// Handle possible database errors
-			sqlerr.HandleError(err)
+			// Convert database errors (constraint violations, missing rows) into structured
+			// 400/404 responses; anything unrecognised becomes a generic 500.
+			err = sqlerr.HandleError(err)
 		}
 	}
 
@@ -150,9 +232,9 @@ func (gm *GlobalMiddleware) GlobalErrorHandler(err error, c echo.Context) {
 	case errors.As(err, &echoErr):
 		status = echoErr.Code
 		code = errs.MakeUpperCaseWithUnderscores(http.StatusText(status))
-		if msg, ok := echoErr.Message.(string); ok{
+		if msg, ok := echoErr.Message.(string); ok {
 			message = msg
-		}else{
+		} else {
 			message = http.StatusText(echoErr.Code)
 		}
 	// Fallback for unknown errors
@@ -160,24 +242,142 @@ func (gm *GlobalMiddleware) GlobalErrorHandler(err error, c echo.Context) {
 		status = http.StatusInternalServerError
 		code = errs.MakeUpperCaseWithUnderscores(http.StatusText(http.StatusInternalServerError))
 		message = http.StatusText(http.StatusInternalServerError)
-	
+
+	}
+
+	// Log the original error with all relevant context, including every level of the error chain
+	// and whether it is operational (expected) or a programmer error (a bug)
+	logger := *GetLogger(c)
+	class := errs.Classify(originalErr, status)
+
+	event := logger.Error().Stack().Err(originalErr).
+		Int("status", status).
+		Str("error_code", code).
+		Str("error_class", string(class)).
+		Interface("error_chain", errs.Chain(originalErr))
+
+	// Server errors are reported with what the request was about, so they can be reproduced
+	var snap *snapshot.Snapshot
+	if status >= http.StatusInternalServerError {
+		if frames := errs.StackFrames(originalErr); len(frames) > 0 {
+			event = event.Strs("stack_frames", frames)
+		}
+
+		snap = RequestSnapshot(c)
+		event = event.Interface("request_snapshot", snap)
+	}
+
+	if stack, ok := c.Get(panicStackKey).([]byte); ok {
+		event = event.Bool("panic", true).Str("panic_stack", string(stack))
+	}
+
+	env := gm.server.Config.Primary.Env
+	development := env == "local" || env == "development"
+
+	// In development database errors are logged with the constraint's definition, the
+	// offending values and how to fix them. They stay out of the response.
+	if development {
+		if diagnosis := sqlerr.Diagnose(context.WithoutCancel(c.Request().Context()), gm.server.DB.Pool, originalErr); diagnosis != nil {
+			event = event.Interface("db_diagnosis", diagnosis)
+		}
+	}
+
+	event.Msg(message)
+
+	// In development every emitted code must be in the catalog, so codes clients can't
+	// look up are caught before they ship.
+	if development {
+		if err := errs.Codes.Check(code, status); err != nil {
+			logger.Error().Err(err).Str("error_code", code).Int("status", status).Msg("error code missing from the catalog, register it with errs.Register")
+		}
+	}
+
+	if status >= http.StatusInternalServerError {
+		gm.recordServerError(c, originalErr, status, class, snap)
+	}
+
+	if failure, ok := validation.GetFailure(c); ok {
+		gm.recordValidationFailure(c, failure)
+	}
+
+	// Send a structured JSON error response if nothing has been sent yet
+	if !c.Response().Committed {
+		_ = c.JSON(status, errs.HttpError{
+			Code:     code,
+			Message:  message,
+			Status:   status,
+			Override: httpErr != nil && httpErr.Override,
+			Errors:   fieldErrors,
+			Action:   action,
+		})
+	}
+
 }
 
-// Log the original error with all relevant context
-logger := *GetLogger(c)
+// recordServerError counts 5xx responses in New Relic, split by class, and records an event
+// for each, so teams can alert on programmer errors without being paged for timeouts and
+// unreachable dependencies. The event and the request's transaction carry the snapshot.
+func (gm *GlobalMiddleware) recordServerError(c echo.Context, err error, status int, class errs.Class, snap *snapshot.Snapshot) {
+	attributes := map[string]any{
+		"error_class": string(class),
+		"root_type":   errs.RootType(err),
+		"status":      status,
+		"route":       c.Path(),
+		"method":      c.Request().Method,
+		"request_id":  GetRequestID(c),
+	}
+	_, panicked := c.Get(panicStackKey).([]byte)
+	attributes["panic"] = panicked
 
-logger.Error().Stack().Err(originalErr).Int("status", status).Str("error_code", code).Msg(message)
+	snapshotAttributes := snap.Attributes()
+	for name, value := range snapshotAttributes {
+		attributes[name] = value
+	}
+	observe.Event("ServerError", attributes)
 
-// Send a structured JSON error response if nothing has been sent yet
-if !c.Response().Committed{
-	_ = c.JSON(status, errs.HttpError{
-		Code: code,
-		Message: message,
-		Status: status,
-		Override: httpErr != nil && httpErr.Override,
-		Errors: fieldErrors,
-		Action: action,
-	})
+	if txn := newrelic.FromContext(c.Request().Context()); txn != nil {
+		for name, value := range snapshotAttributes {
+			txn.AddAttribute(name, value)
+		}
+	}
+
+	if gm.server.LoggerService == nil || gm.server.LoggerService.GetNewRelicApp() == nil {
+		return
+	}
+
+	gm.server.LoggerService.GetNewRelicApp().RecordCustomMetric("Custom/Errors/Server/"+string(class), 1)
 }
 
+// recordValidationFailure counts requests BindAndValidate rejected per endpoint and per
+// field and rule, showing which fields confuse integrators. The event keeps the caller,
+// so endpoints probed by bots with undecodable bodies stand out.
+func (gm *GlobalMiddleware) recordValidationFailure(c echo.Context, failure *validation.Failure) {
+	var app *newrelic.Application
+	if gm.server.LoggerService != nil {
+		app = gm.server.LoggerService.GetNewRelicApp()
+	}
+
+	name := "Custom/Validation/" + c.Request().Method + " " + c.Path()
+	if app != nil {
+		app.RecordCustomMetric(name+"/"+failure.Stage, 1)
+	}
+
+	fields := make([]string, len(failure.Fields))
+	for i, field := range failure.Fields {
+		if app != nil {
+			app.RecordCustomMetric(name+"/Fields/"+field.Field+"/"+field.Tag, 1)
+		}
+		fields[i] = field.Field + ":" + field.Tag
+	}
+
+	observe.Event("ValidationFailed", map[string]any{
+		"stage":      failure.Stage,
+		"fields":     strings.Join(fields, ","),
+		"route":      c.Path(),
+		"method":     c.Request().Method,
+		"ip":         c.RealIP(),
+		"user_agent": c.Request().UserAgent(),
+		"user_id":    GetUserID(c),
+		"request_id": GetRequestID(c),
+	})
 }
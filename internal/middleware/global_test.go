@@ -0,0 +1,90 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	// Outside development the handler doesn't diagnose database errors, so it needs no database
+	srv := &server.Server{Config: &config.Config{Primary: config.Primary{Env: "production"}}}
+	gm := middleware.NewGlobalMiddleWare(srv)
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		msg    string
+	}{
+		{
+			name:   "http error",
+			err:    errs.BadRequestError("Invalid title", true, nil, nil, nil),
+			status: http.StatusBadRequest,
+			code:   "BAD_REQUEST",
+			msg:    "Invalid title",
+		},
+		{
+			name:   "echo bad request",
+			err:    echo.NewHTTPError(http.StatusBadRequest, "malformed body"),
+			status: http.StatusBadRequest,
+			code:   "BAD_REQUEST",
+			msg:    "malformed body",
+		},
+		{
+			name:   "unknown route",
+			err:    echo.ErrNotFound,
+			status: http.StatusNotFound,
+			code:   "NOT_FOUND",
+			msg:    "Route not found",
+		},
+		{
+			name:   "constraint violation",
+			err:    fmt.Errorf("failed to create todo: %w", &pgconn.PgError{Code: "23505", Severity: "ERROR", TableName: "todos"}),
+			status: http.StatusBadRequest,
+			code:   "TODO_ALREADY_EXISTS",
+			msg:    "A Todo with this identifier already exists",
+		},
+		{
+			name:   "missing row",
+			err:    fmt.Errorf("failed to get todo: %w", pgx.ErrNoRows),
+			status: http.StatusNotFound,
+			code:   "NOT_FOUND",
+			msg:    "Resource not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/todos", nil), rec)
+
+			gm.GlobalErrorHandler(tt.err, c)
+
+			require.Equal(t, tt.status, rec.Code)
+
+			var body errs.HttpError
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.status, body.Status)
+			assert.Equal(t, tt.code, body.Code)
+			assert.Equal(t, tt.msg, body.Message)
+		})
+	}
+}
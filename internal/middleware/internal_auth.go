@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/svcauth"
+	"github.com/labstack/echo/v4"
+)
+
+// InternalCallerKey holds the name of the service that made an internal call.
+const InternalCallerKey = "internal_caller"
+
+// RequireInternalCaller rejects requests without a valid service token (see svcauth)
+// from one of services, or from any configured caller when none are given.
+//
+//	r.POST("/internal/invoices/sync", h.Billing.Sync, m.AuthMiddleware.RequireInternalCaller("billing"))
+func (auth *AuthMiddleware) RequireInternalCaller(services ...string) echo.MiddlewareFunc {
+	verifier := auth.server.ServiceAuth
+	if verifier == nil {
+		auth.server.Logger.Error().Strs("services", services).Msg("service auth is not configured, internal calls will be rejected")
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if verifier == nil {
+				return errs.InternalServerError()
+			}
+
+			claims, err := verifier.Verify(c.Request().Header.Get(svcauth.Header), time.Now())
+			if err != nil {
+				GetLogger(c).Warn().Err(err).Msg("rejected internal call")
+
+				if errors.Is(err, svcauth.ErrExpiredToken) {
+					return errs.UnauthorizedError("Service token has expired", false)
+				}
+				return errs.UnauthorizedError("Unauthorized", false)
+			}
+
+			if len(services) > 0 && !slices.Contains(services, claims.Issuer) {
+				GetLogger(c).Warn().Str("caller", claims.Issuer).Strs("allowed", services).Msg("internal caller not allowed")
+				return errs.ForbididdenError("Caller is not allowed", false)
+			}
+
+			c.Set(InternalCallerKey, claims.Issuer)
+			return next(c)
+		}
+	}
+}
+
+// GetInternalCaller returns the service that made the request, set by RequireInternalCaller.
+func GetInternalCaller(c echo.Context) string {
+	caller, _ := c.Get(InternalCallerKey).(string)
+	return caller
+}
@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/latency"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+const latencyBudgetKey = "latency_budget"
+
+// LatencyBudgetMiddleware holds requests to the latency budget of their route and
+// reports, per route, how fast the latency SLO's error budget is burning.
+type LatencyBudgetMiddleware struct {
+	server *server.Server
+
+	mu     sync.Mutex
+	routes map[string]*routeLatency
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// routeLatency counts the requests of a route since the last report.
+type routeLatency struct {
+	budget     time.Duration
+	requests   int64
+	violations int64
+}
+
+// NewLatencyBudgetMiddleware returns a new LatencyBudgetMiddleware tied to the server,
+// reporting burn rates until the server shuts down.
+func NewLatencyBudgetMiddleware(s *server.Server) *LatencyBudgetMiddleware {
+	lb := &LatencyBudgetMiddleware{
+		server: s,
+		routes: make(map[string]*routeLatency),
+	}
+
+	if interval := s.Config.SLO.ReportInterval; interval > 0 {
+		lb.stop = make(chan struct{})
+		lb.done = make(chan struct{})
+		go lb.reportLoop(interval)
+
+		s.OnShutdown(func(ctx context.Context) {
+			close(lb.stop)
+			<-lb.done
+		})
+	}
+
+	return lb
+}
+
+// Budget declares the latency budget of a route, overriding the configured default.
+//
+//	r.GET("/me/quota", h.Quota.GetQuota, m.LatencyBudget.Budget(200*time.Millisecond))
+func (lb *LatencyBudgetMiddleware) Budget(budget time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(latencyBudgetKey, budget)
+			return next(c)
+		}
+	}
+}
+
+// Track times every request against the budget of its route. Requests over budget
+// are logged with a breakdown of the time spent on database queries and external
+// calls, collected through the latency.Breakdown in the request context.
+func (lb *LatencyBudgetMiddleware) Track() echo.MiddlewareFunc {
+	defaultBudget := lb.server.Config.SLO.DefaultLatencyBudget
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			ctx, breakdown := latency.WithBreakdown(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+
+			budget, ok := c.Get(latencyBudgetKey).(time.Duration)
+			if !ok {
+				budget = defaultBudget
+			}
+			// Unmatched paths have no route to hold to a budget.
+			if budget <= 0 || c.Path() == "" {
+				return err
+			}
+
+			elapsed := time.Since(start)
+			route := c.Request().Method + " " + c.Path()
+			exceeded := elapsed > budget
+			lb.record(route, budget, exceeded)
+
+			if exceeded {
+				lb.reportExceeded(c, route, budget, elapsed, breakdown.Snapshot())
+			}
+
+			return err
+		}
+	}
+}
+
+func (lb *LatencyBudgetMiddleware) record(route string, budget time.Duration, exceeded bool) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	stats, ok := lb.routes[route]
+	if !ok {
+		stats = &routeLatency{}
+		lb.routes[route] = stats
+	}
+
+	stats.budget = budget
+	stats.requests++
+	if exceeded {
+		stats.violations++
+	}
+}
+
+func (lb *LatencyBudgetMiddleware) reportExceeded(c echo.Context, route string, budget, elapsed time.Duration, spent latency.Snapshot) {
+	// Whatever isn't database or external time was spent in the service itself. Parallel
+	// queries or calls can add up to more than the elapsed time, so this is a lower bound.
+	own := max(elapsed-spent.DB-spent.External, 0)
+
+	GetLogger(c).Warn().
+		Str("route", route).
+		Int("status", c.Response().Status).
+		Dur("elapsed", elapsed).
+		Dur("budget", budget).
+		Dur("db_time", spent.DB).
+		Int64("db_queries", spent.DBQueries).
+		Dur("external_time", spent.External).
+		Int64("external_calls", spent.ExternalCalls).
+		Dur("own_time", own).
+		Msg("latency budget exceeded")
+
+	observe.Event("LatencyBudgetExceeded", map[string]any{
+		"request_id":      GetRequestID(c),
+		"route":           route,
+		"elapsed_ms":      elapsed.Milliseconds(),
+		"budget_ms":       budget.Milliseconds(),
+		"db_ms":           spent.DB.Milliseconds(),
+		"db_queries":      spent.DBQueries,
+		"external_ms":     spent.External.Milliseconds(),
+		"external_calls":  spent.ExternalCalls,
+		"own_ms":          own.Milliseconds(),
+		"over_budget_pct": float64(elapsed-budget) / float64(budget) * 100,
+		"response_status": c.Response().Status,
+	})
+}
+
+func (lb *LatencyBudgetMiddleware) reportLoop(interval time.Duration) {
+	defer close(lb.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lb.stop:
+			return
+		case <-ticker.C:
+			lb.report()
+		}
+	}
+}
+
+// report exports the burn rate of every route seen since the last report: the share
+// of requests over budget relative to the share the SLO objective allows. A burn
+// rate of 1 uses up the error budget exactly, higher ones exhaust it early.
+func (lb *LatencyBudgetMiddleware) report() {
+	lb.mu.Lock()
+	routes := lb.routes
+	lb.routes = make(map[string]*routeLatency, len(routes))
+	lb.mu.Unlock()
+
+	allowed := 1 - lb.server.Config.SLO.Objective
+
+	for route, stats := range routes {
+		burnRate := float64(stats.violations) / float64(stats.requests) / allowed
+
+		if burnRate > 1 {
+			lb.server.Logger.Warn().
+				Str("route", route).
+				Dur("budget", stats.budget).
+				Int64("requests", stats.requests).
+				Int64("violations", stats.violations).
+				Float64("burn_rate", burnRate).
+				Msg("latency SLO error budget burning too fast")
+		}
+
+		if lb.server.LoggerService != nil && lb.server.LoggerService.GetNewRelicApp() != nil {
+			app := lb.server.LoggerService.GetNewRelicApp()
+			name := "Custom/SLO/Latency/" + route
+			app.RecordCustomMetric(name+"/Requests", float64(stats.requests))
+			app.RecordCustomMetric(name+"/Violations", float64(stats.violations))
+			app.RecordCustomMetric(name+"/BurnRate", burnRate)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// PerListener runs, for every request, the middlewares chains lists for the listener
+// it came in on (see config.ServerConfig.Listeners), e.g. path restrictions on a public
+// port or service token checks on an internal one. Requests of listeners without a chain
+// go straight on.
+func (gm *GlobalMiddleware) PerListener(chains map[string][]echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		// Each chain is composed once, not per request
+		handlers := make(map[string]echo.HandlerFunc, len(chains))
+		for name, chain := range chains {
+			h := next
+			for i := len(chain) - 1; i >= 0; i-- {
+				h = chain[i](h)
+			}
+			handlers[name] = h
+		}
+
+		return func(c echo.Context) error {
+			if h, ok := handlers[server.ListenerFromContext(c.Request().Context())]; ok {
+				return h(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// RestrictPaths answers 404, as for an unknown route, for requests whose path isn't under
+// one of allow (when any are given) or is under one of deny, so a listener doesn't even
+// reveal the routes it hides.
+func RestrictPaths(allow, deny []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			path := c.Request().URL.Path
+
+			if len(allow) > 0 && !hasAnyPrefix(path, allow) {
+				return errs.NotFoundError("Route not found", false, nil)
+			}
+			if hasAnyPrefix(path, deny) {
+				return errs.NotFoundError("Route not found", false, nil)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func hasAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -6,26 +6,51 @@ import (
 )
 
 type Middlewares struct {
-	GlobalMiddleware     *GlobalMiddleware
+	GlobalMiddleware      *GlobalMiddleware
 	AuthMiddleware        *AuthMiddleware
 	TracingMiddleware     *TracingMiddleware
 	RateLimiterMiddleware *RateLimiterMiddleware
 	ContextEnhancer       *ContextEnhancer
+	SlowRequestMiddleware *SlowRequestMiddleware
+	QuotaMiddleware       *QuotaMiddleware
+	WebhookMiddleware     *WebhookMiddleware
+	DrainMiddleware       *DrainMiddleware
+	CaptchaMiddleware     *CaptchaMiddleware
+	LatencyBudget         *LatencyBudgetMiddleware
+	ExampleCapture        *ExampleCaptureMiddleware
+	RequestID             *RequestIDMiddleware
+	NPlusOne              *NPlusOneMiddleware
+	Usage                 *UsageMiddleware
+	MiddlewareTiming      *MiddlewareTimingMiddleware
+	RouteToggle           *RouteToggleMiddleware
+	EmailVerification     *EmailVerificationMiddleware
 }
 
-func NewMiddlewares(s *server.Server) *Middlewares{
+func NewMiddlewares(s *server.Server) *Middlewares {
 	var newrelicApp *newrelic.Application
-	if s.LoggerService != nil{
+	if s.LoggerService != nil {
 		newrelicApp = s.LoggerService.GetNewRelicApp()
 	}
 
 	return &Middlewares{
-		GlobalMiddleware: NewGlobalMiddleWare(s),
-		AuthMiddleware: NewAuthMiddleware(s),
-		TracingMiddleware: NewTracingMiddleware(s, newrelicApp),
+		GlobalMiddleware:      NewGlobalMiddleWare(s),
+		AuthMiddleware:        NewAuthMiddleware(s),
+		TracingMiddleware:     NewTracingMiddleware(s, newrelicApp),
 		RateLimiterMiddleware: NewRateLimiter(s),
-		ContextEnhancer: NewContextEnhancer(s),
+		ContextEnhancer:       NewContextEnhancer(s),
+		SlowRequestMiddleware: NewSlowRequestMiddleware(s),
+		QuotaMiddleware:       NewQuotaMiddleware(s),
+		WebhookMiddleware:     NewWebhookMiddleware(s),
+		DrainMiddleware:       NewDrainMiddleware(s),
+		CaptchaMiddleware:     NewCaptchaMiddleware(s),
+		LatencyBudget:         NewLatencyBudgetMiddleware(s),
+		ExampleCapture:        NewExampleCaptureMiddleware(s),
+		RequestID:             NewRequestIDMiddleware(s),
+		NPlusOne:              NewNPlusOneMiddleware(s),
+		Usage:                 NewUsageMiddleware(s),
+		MiddlewareTiming:      NewMiddlewareTimingMiddleware(s),
+		RouteToggle:           NewRouteToggleMiddleware(s),
+		EmailVerification:     NewEmailVerificationMiddleware(s),
 	}
 
 }
-
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+// maxReportedQueryLength bounds the SQL of each repeated query in the warning.
+const maxReportedQueryLength = 300
+
+// NPlusOneMiddleware reports requests running the same query over and over, typically a
+// repository call in a loop over the rows of an earlier query (N+1). It only runs in
+// local development, where the database tracer counts the query shapes of each request.
+type NPlusOneMiddleware struct {
+	server *server.Server
+}
+
+// NewNPlusOneMiddleware returns a new NPlusOneMiddleware tied to the server.
+func NewNPlusOneMiddleware(s *server.Server) *NPlusOneMiddleware {
+	return &NPlusOneMiddleware{
+		server: s,
+	}
+}
+
+// Detect counts the queries of every request by shape and logs a single warning per
+// request listing the shapes run at least logging.n_plus_one_threshold times.
+func (np *NPlusOneMiddleware) Detect() echo.MiddlewareFunc {
+	threshold := np.server.Config.Observability.Logging.NPlusOneThreshold
+	enabled := np.server.Config.Primary.Env == "local" && threshold > 0
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !enabled {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			ctx, shapes := database.WithQueryShapes(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+
+			if repeated := shapes.Repeated(threshold); len(repeated) > 0 {
+				queries := zerolog.Arr()
+				for _, query := range repeated {
+					sql := query.SQL
+					if len(sql) > maxReportedQueryLength {
+						sql = sql[:maxReportedQueryLength] + "..."
+					}
+					queries.Dict(zerolog.Dict().Str("sql", sql).Int("count", query.Count))
+				}
+
+				GetLogger(c).Warn().
+					Str("route", c.Request().Method+" "+c.Path()).
+					Int("threshold", threshold).
+					Array("repeated_queries", queries).
+					Msg("possible N+1 query pattern, the same query ran repeatedly in one request")
+			}
+
+			return err
+		}
+	}
+}
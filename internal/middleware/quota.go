@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/quota"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+// Codes of callers who used up their request quota.
+var (
+	codeMonthlyQuotaExceeded = errs.Register("MONTHLY_QUOTA_EXCEEDED", http.StatusPaymentRequired, "The monthly request quota of the plan is used up, upgrading the plan lifts it.")
+	codeDailyQuotaExceeded   = errs.Register("DAILY_QUOTA_EXCEEDED", http.StatusTooManyRequests, "The daily request quota is used up until Retry-After.")
+)
+
+const (
+	QuotaDailyLimitHeader       = "X-Quota-Daily-Limit"
+	QuotaDailyRemainingHeader   = "X-Quota-Daily-Remaining"
+	QuotaMonthlyLimitHeader     = "X-Quota-Monthly-Limit"
+	QuotaMonthlyRemainingHeader = "X-Quota-Monthly-Remaining"
+	QuotaResetHeader            = "X-Quota-Reset"
+)
+
+// QuotaMiddleware enforces the daily and monthly request quotas of authenticated consumers.
+type QuotaMiddleware struct {
+	server *server.Server
+}
+
+func NewQuotaMiddleware(s *server.Server) *QuotaMiddleware {
+	return &QuotaMiddleware{
+		server: s,
+	}
+}
+
+// EnforceQuota counts the request against the consumer's quotas and adds quota headers to the response.
+// Organizations with an override (e.g. from their plan) are held to their own quotas.
+// Exhausting the daily quota returns a 429, exhausting the monthly (plan) quota returns a 402.
+// It must run after authentication; anonymous requests are not counted.
+// If Redis is unavailable requests are let through, quotas are not worth an outage.
+func (qm *QuotaMiddleware) EnforceQuota() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !qm.server.Config.Quota.Enabled {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			consumer := GetQuotaConsumer(c)
+			if consumer == "" {
+				return next(c)
+			}
+
+			limits := ResolveTenantLimits(c, qm.server.TenantLimits)
+			usage, err := qm.server.Quota.Consume(c.Request().Context(), consumer, limits.Quota, time.Now())
+			if err != nil {
+				GetLogger(c).Warn().Err(err).Str("consumer", consumer).Msg("quota check failed, allowing request")
+				return next(c)
+			}
+
+			SetQuotaHeaders(c, usage)
+
+			switch usage.Exceeded {
+			case quota.PeriodMonthly:
+				qm.recordExhausted(consumer, limits.Plan, usage.Exceeded)
+				code := codeMonthlyQuotaExceeded
+				return errs.PaymentRequiredError("Monthly request quota exhausted, upgrade your plan to continue", true, &code)
+			case quota.PeriodDaily:
+				qm.recordExhausted(consumer, limits.Plan, usage.Exceeded)
+				c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(time.Until(usage.Daily.ResetsAt).Seconds())))
+				code := codeDailyQuotaExceeded
+				return errs.TooManyRequestsError("Daily request quota exhausted", true, &code)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// GetQuotaConsumer returns who a request is billed to: the organization when the user
+// acts within one, the user otherwise. It returns an empty string for anonymous requests.
+func GetQuotaConsumer(c echo.Context) string {
+	if organizationID := GetOrganizationID(c); organizationID != "" {
+		return "org:" + organizationID
+	}
+
+	if userID := GetUserID(c); userID != "" {
+		return "user:" + userID
+	}
+
+	return ""
+}
+
+// SetQuotaHeaders writes the consumer's remaining quota to the response headers.
+// Unlimited periods are omitted.
+func SetQuotaHeaders(c echo.Context, usage *quota.Usage) {
+	header := c.Response().Header()
+
+	if usage.Daily.Limit > 0 {
+		header.Set(QuotaDailyLimitHeader, strconv.FormatInt(usage.Daily.Limit, 10))
+		header.Set(QuotaDailyRemainingHeader, strconv.FormatInt(usage.Daily.Remaining, 10))
+		header.Set(QuotaResetHeader, usage.Daily.ResetsAt.Format(time.RFC3339))
+	}
+
+	if usage.Monthly.Limit > 0 {
+		header.Set(QuotaMonthlyLimitHeader, strconv.FormatInt(usage.Monthly.Limit, 10))
+		header.Set(QuotaMonthlyRemainingHeader, strconv.FormatInt(usage.Monthly.Remaining, 10))
+	}
+}
+
+// recordExhausted records a quota exhaustion event
+func (qm *QuotaMiddleware) recordExhausted(consumer, plan string, period quota.Period) {
+	observe.Event("QuotaExhausted", map[string]any{
+		"consumer": consumer,
+		"plan":     plan,
+		"period":   string(period),
+	})
+}
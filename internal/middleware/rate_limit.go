@@ -1,6 +1,27 @@
 package middleware
 
-import "github.com/Barry-dE/go-backend-boilerplate/internal/server"
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/tenantlimits"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+var codeRateLimitExceeded = errs.Register("RATE_LIMIT_EXCEEDED", http.StatusTooManyRequests, "The tenant's requests per minute are used up until Retry-After.")
+
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// tenantLimitsKey caches the resolved tenant limits in echo's context for the request.
+const tenantLimitsKey = "tenant_limits"
 
 type RateLimiterMiddleware struct {
 	server *server.Server
@@ -12,11 +33,77 @@ func NewRateLimiter(s *server.Server) *RateLimiterMiddleware {
 	}
 }
 
-// RecordHit records a rate limit breach event to New Relic
+// RecordHit records a rate limit breach event
 func (rl *RateLimiterMiddleware) RecordHit(endpoint string) {
-	if rl.server.LoggerService != nil && rl.server.LoggerService.GetNewRelicApp() != nil {
-		rl.server.LoggerService.GetNewRelicApp().RecordCustomEvent("RateLimitHit", map[string]interface{}{
-			"endpoint": endpoint,
-		})
+	observe.Event("RateLimitHit", map[string]any{
+		"endpoint": endpoint,
+	})
+}
+
+// TenantRateLimit limits authenticated requests per tenant, the organization the user acts
+// in or the user outside of one (see GetQuotaConsumer). The limit is the organization's
+// override (e.g. from its plan) or the configured default. It must run after authentication;
+// anonymous requests are left to the global per-IP limiter.
+// If Redis is unavailable requests are let through, like quotas.
+func (rl *RateLimiterMiddleware) TenantRateLimit() echo.MiddlewareFunc {
+	return TimeStage(StageTenantRateLimit, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			consumer := GetQuotaConsumer(c)
+			if consumer == "" {
+				return next(c)
+			}
+
+			limits := ResolveTenantLimits(c, rl.server.TenantLimits)
+			if limits.RequestsPerMinute == 0 {
+				return next(c)
+			}
+
+			result, err := rl.server.RateLimiter.Allow(c.Request().Context(), consumer, limits.RequestsPerMinute)
+			if err != nil {
+				GetLogger(c).Warn().Err(err).Str("consumer", consumer).Msg("rate limit check failed, allowing request")
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+			header.Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+			header.Set(RateLimitResetHeader, result.ResetsAt.Format(time.RFC3339))
+
+			if !result.Allowed {
+				rl.recordTenantHit(consumer, limits)
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(int(result.RetryAfter.Seconds())+1))
+				code := codeRateLimitExceeded
+				return errs.TooManyRequestsError("Rate limit exceeded", true, &code)
+			}
+
+			return next(c)
+		}
+	})
+}
+
+// ResolveTenantLimits returns the limits of the organization the request acts in, the
+// defaults outside of one. They are resolved once per request; if the override can't
+// be read the defaults apply, limits are not worth failing the request for.
+func ResolveTenantLimits(c echo.Context, resolver *tenantlimits.Resolver) tenantlimits.Limits {
+	if limits, ok := c.Get(tenantLimitsKey).(tenantlimits.Limits); ok {
+		return limits
 	}
+
+	organizationID := GetOrganizationID(c)
+	limits, err := resolver.Resolve(c.Request().Context(), organizationID)
+	if err != nil {
+		GetLogger(c).Warn().Err(err).Str(OrganizationIDKey, organizationID).Msg("failed to resolve tenant limits, using defaults")
+	}
+
+	c.Set(tenantLimitsKey, limits)
+	return limits
+}
+
+// recordTenantHit records a tenant rate limit breach event
+func (rl *RateLimiterMiddleware) recordTenantHit(consumer string, limits tenantlimits.Limits) {
+	observe.Event("TenantRateLimitHit", map[string]any{
+		"consumer": consumer,
+		"plan":     limits.Plan,
+		"limit":    limits.RequestsPerMinute,
+	})
 }
@@ -1,6 +1,11 @@
 package middleware
 
 import (
+	"net/netip"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/svcauth"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -10,19 +15,51 @@ const (
 	RequestIDKey    = "request_id"
 )
 
+// requestIDPrefixLength is the length of the server-generated component prefixed to kept IDs.
+const requestIDPrefixLength = 8
+
+// RequestIDMiddleware assigns every request its ID, keeping the caller's X-Request-ID
+// only where config.RequestIDConfig trusts it.
+type RequestIDMiddleware struct {
+	server   *server.Server
+	networks []netip.Prefix
+}
+
+func NewRequestIDMiddleware(s *server.Server) *RequestIDMiddleware {
+	rm := &RequestIDMiddleware{server: s}
+
+	// Validated with the config
+	for _, network := range s.Config.RequestID.TrustedNetworks {
+		if prefix, err := netip.ParsePrefix(network); err == nil {
+			rm.networks = append(rm.networks, prefix.Masked())
+		}
+	}
+
+	return rm
+}
+
 // RequestID is middleware that ensures each incoming HTTP request
-// has a unique identifier. If the client doesn’t send one,
-// it generates a new UUID and attaches it to both the request context
-// and the response header for traceability.
-func RequestID() echo.MiddlewareFunc {
+// has a unique identifier. A trusted caller's ID is kept if it is valid,
+// otherwise a new UUID is generated. The ID is attached to both the
+// request context and the response header for traceability.
+func (rm *RequestIDMiddleware) RequestID() echo.MiddlewareFunc {
+	cfg := rm.server.Config.RequestID
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// Check if the client already provided a request ID.
+			// Keep the caller's request ID only if we trust it and it is well-formed.
 			requestID := c.Request().Header.Get(RequestIDHeader)
-			// If not, create a new one.
+			if requestID != "" && (!rm.trusted(c) || !validRequestID(requestID, cfg.MaxLength)) {
+				requestID = ""
+			}
+
+			// If there is none, create a new one, otherwise make it unique if configured.
 			if requestID == "" {
 				requestID = uuid.New().String()
+			} else if cfg.Prefix {
+				requestID = uuid.New().String()[:requestIDPrefixLength] + "." + requestID
 			}
+
 			// Store the request ID in the context so other parts of the app (like logs) can access it.
 			c.Set(RequestIDKey, requestID)
 			// Add the request ID to the response header
@@ -33,6 +70,52 @@ func RequestID() echo.MiddlewareFunc {
 	}
 }
 
+// trusted reports whether the caller may propagate its request ID.
+func (rm *RequestIDMiddleware) trusted(c echo.Context) bool {
+	switch rm.server.Config.RequestID.Trust {
+	case "all":
+		return true
+	case "none":
+		return false
+	}
+
+	// The connection's address, forwarded addresses can be set by anyone
+	if peer, err := netip.ParseAddrPort(c.Request().RemoteAddr); err == nil {
+		addr := peer.Addr().Unmap()
+		for _, network := range rm.networks {
+			if network.Contains(addr) {
+				return true
+			}
+		}
+	}
+
+	token := c.Request().Header.Get(svcauth.Header)
+	if token == "" || rm.server.ServiceAuth == nil {
+		return false
+	}
+	_, err := rm.server.ServiceAuth.Verify(token, time.Now())
+	return err == nil
+}
+
+// validRequestID reports whether id is at most maxLength characters of letters, digits
+// and "-", "_", ".", ":", which keeps it safe to log and to put into headers.
+func validRequestID(id string, maxLength int) bool {
+	if len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		switch b := id[i]; {
+		case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9':
+		case b == '-', b == '_', b == '.', b == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
 // GetRequestID retrieves the request ID stored in the request context.
 // Returns an empty string if none is found.
 func GetRequestID(c echo.Context) string {
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+// responseWriterKey holds the request's responseWriter in echo's context.
+const responseWriterKey = "response_writer"
+
+// errResponseFinished is returned for writes made after the request finished, e.g. by a
+// goroutine the handler left running.
+var errResponseFinished = errors.New("response already finished")
+
+// responseWriter counts the bytes of the response body and refuses the writes that would
+// corrupt it: a second status line, and writes after the request finished.
+type responseWriter struct {
+	http.ResponseWriter
+	c echo.Context
+
+	status   int
+	bytes    atomic.Int64
+	finished atomic.Bool
+	// logger is the request's logger once the request finished, echo's context is reused
+	// by then and must not be read anymore.
+	logger atomic.Pointer[zerolog.Logger]
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		GetLogger(w.c).Error().
+			Int("status", w.status).
+			Int("superfluous_status", status).
+			Str("caller", externalCaller()).
+			Msg("response status written twice, the second one is dropped")
+		return
+	}
+
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.finished.Load() {
+		w.logger.Load().Error().
+			Int("bytes", len(b)).
+			Str("caller", externalCaller()).
+			Msg("response written after the request finished, the write is dropped")
+		return 0, errResponseFinished
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes.Add(int64(n))
+	return n, err
+}
+
+// Unwrap lets http.ResponseController, and echo's Flush and Hijack through it, reach the
+// connection's writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// ResponseWriter wraps the response writer of every request to count the bytes sent,
+// logged as bytes_out, and to log writes that would corrupt the response instead of
+// sending them: a second status, and writes after the request finished.
+func (gm *GlobalMiddleware) ResponseWriter() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			w := &responseWriter{ResponseWriter: c.Response().Writer, c: c}
+			c.Response().Writer = w
+			c.Set(responseWriterKey, w)
+
+			err := next(c)
+
+			w.logger.Store(GetLogger(c))
+			w.finished.Store(true)
+			return err
+		}
+	}
+}
+
+// GetBytesOut returns the number of body bytes sent so far, or the size echo counted for
+// requests not passing through ResponseWriter.
+func GetBytesOut(c echo.Context) int64 {
+	if w, ok := c.Get(responseWriterKey).(*responseWriter); ok {
+		return w.bytes.Load()
+	}
+	return c.Response().Size
+}
+
+// RefuseDoubleWrite reports whether the response was already written, logging the
+// attempt to write another one. Encoding a second body would append it to the first,
+// responders check it first and drop the second body instead.
+func RefuseDoubleWrite(c echo.Context) bool {
+	if !c.Response().Committed {
+		return false
+	}
+
+	GetLogger(c).Error().
+		Int("status", c.Response().Status).
+		Str("caller", externalCaller()).
+		Msg("response already written, the second body is dropped")
+	return true
+}
+
+// JSONSerializer returns echo's JSON serializer for c.JSON and c.Bind. It refuses to
+// write into a response already written (see RefuseDoubleWrite) and doesn't escape
+// <, > and & in strings: responses are served as application/json with nosniff, so
+// browsers never render them as HTML, and escaping would only obscure them.
+func (gm *GlobalMiddleware) JSONSerializer() echo.JSONSerializer {
+	return strictJSONSerializer{}
+}
+
+type strictJSONSerializer struct{}
+
+func (strictJSONSerializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	if RefuseDoubleWrite(c) {
+		return nil
+	}
+
+	enc := json.NewEncoder(c.Response())
+	enc.SetEscapeHTML(false)
+	if indent != "" {
+		enc.SetIndent("", indent)
+	}
+	return enc.Encode(i)
+}
+
+// Deserialize decodes request bodies the way echo's default serializer does.
+func (strictJSONSerializer) Deserialize(c echo.Context, i interface{}) error {
+	err := json.NewDecoder(c.Request().Body).Decode(i)
+
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unmarshal type error: expected=%v, got=%v, field=%v, offset=%v", typeErr.Type, typeErr.Value, typeErr.Field, typeErr.Offset)).SetInternal(err)
+	case errors.As(err, &syntaxErr):
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Syntax error: offset=%v, error=%v", syntaxErr.Offset, syntaxErr.Error())).SetInternal(err)
+	}
+	return err
+}
+
+// externalCaller returns the file and line of the first caller outside echo and this
+// file, i.e. the handler or middleware responsible for a refused write.
+func externalCaller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.File, "labstack/echo") && !strings.HasSuffix(frame.File, "internal/middleware/response.go") &&
+			!strings.HasPrefix(frame.Function, "encoding/json.") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+var codeRouteDisabled = errs.Register("ROUTE_DISABLED", http.StatusServiceUnavailable, "The endpoint was disabled by an operator, usually during an incident, retry later.")
+
+// RouteToggleMiddleware rejects the requests of routes operators disabled at runtime,
+// see internal/lib/routetoggle.
+type RouteToggleMiddleware struct {
+	server *server.Server
+}
+
+// NewRouteToggleMiddleware returns a new RouteToggleMiddleware tied to the server.
+func NewRouteToggleMiddleware(s *server.Server) *RouteToggleMiddleware {
+	return &RouteToggleMiddleware{
+		server: s,
+	}
+}
+
+// Check answers 503 ROUTE_DISABLED to requests of a disabled route, with a retry_later
+// action carrying the operator's reason. Routes are matched as registered, so it must
+// run after routing; echo's Use middlewares do.
+func (rt *RouteToggleMiddleware) Check() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			toggle, disabled := rt.server.RouteToggles.Disabled(c.Request().Method, c.Path())
+			if !disabled {
+				return next(c)
+			}
+
+			GetLogger(c).Warn().
+				Str("route", c.Path()).
+				Str("reason", toggle.Reason).
+				Msg("request rejected, the route is disabled")
+
+			observe.Event("RouteDisabledHit", map[string]any{
+				"method": c.Request().Method,
+				"route":  c.Path(),
+			})
+
+			message := "This endpoint is temporarily disabled"
+			return errs.ServiceUnavailableError(message, true, &codeRouteDisabled, &errs.Action{
+				Type:    string(errs.ActionTypeRetryLater),
+				Message: toggle.Reason,
+			})
+		}
+	}
+}
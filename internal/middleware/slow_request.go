@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// maxStackSnapshotSize bounds the goroutine dump captured for a slow request.
+	maxStackSnapshotSize = 1 << 20
+	// stackSnapshotInterval is the least time between two goroutine dumps.
+	stackSnapshotInterval = 10 * time.Second
+)
+
+// SlowRequestMiddleware watches requests while they are still running and warns
+// about the ones exceeding the configured threshold. Unlike the access log, which
+// is only written once a request completes, this catches handlers that hang forever.
+type SlowRequestMiddleware struct {
+	server *server.Server
+	// lastDump is when the goroutines were last dumped, in Unix nanoseconds.
+	lastDump atomic.Int64
+}
+
+// NewSlowRequestMiddleware returns a new SlowRequestMiddleware tied to the server.
+func NewSlowRequestMiddleware(s *server.Server) *SlowRequestMiddleware {
+	return &SlowRequestMiddleware{
+		server: s,
+	}
+}
+
+// DetectSlowRequests starts a timer for every request. If the handler is still running
+// once the threshold elapses, a "slow request in progress" warning is logged together
+// with a stack snapshot of the goroutine serving the request, if one was taken (see
+// goroutineStack). A zero threshold disables the middleware.
+func (sr *SlowRequestMiddleware) DetectSlowRequests() echo.MiddlewareFunc {
+	threshold := sr.server.Config.Observability.Logging.SlowRequestThreshold
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if threshold <= 0 {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			// Capture everything the timer needs up front, echo's context
+			// must not be read from another goroutine while the handler runs.
+			logger := GetLogger(c)
+			requestID := GetRequestID(c)
+			method := c.Request().Method
+			route := c.Path()
+			uri := c.Request().RequestURI
+			goroutineID := currentGoroutineID()
+
+			timer := time.AfterFunc(threshold, func() {
+				elapsed := time.Since(start)
+
+				event := logger.Warn().
+					Str("request_id", requestID).
+					Str("method", method).
+					Str("route", route).
+					Str("uri", uri).
+					Dur("elapsed", elapsed).
+					Dur("threshold", threshold)
+				if stack, ok := sr.goroutineStack(goroutineID); ok {
+					event = event.Str("stack", stack)
+				}
+				event.Msg("slow request in progress")
+
+				observe.Event("SlowRequestInProgress", map[string]any{
+					"request_id": requestID,
+					"method":     method,
+					"route":      route,
+					"elapsed_ms": elapsed.Milliseconds(),
+				})
+			})
+			defer timer.Stop()
+
+			return next(c)
+		}
+	}
+}
+
+// currentGoroutineID parses the id of the calling goroutine from its stack header
+// ("goroutine 42 [running]:"). It returns an empty string if parsing fails.
+func currentGoroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+
+	fields := bytes.Fields(buf)
+	if len(fields) < 2 {
+		return ""
+	}
+	if _, err := strconv.ParseUint(string(fields[1]), 10, 64); err != nil {
+		return ""
+	}
+
+	return string(fields[1])
+}
+
+// goroutineStack returns the stack of the goroutine with the given id. Go only dumps
+// all goroutines at once, stopping the world while it does, so a dump is taken at most
+// once per stackSnapshotInterval: when many requests are slow at once, e.g. while the
+// database hangs, only the first gets its stack. Only that goroutine's stack is returned.
+func (sr *SlowRequestMiddleware) goroutineStack(goroutineID string) (string, bool) {
+	if goroutineID == "" {
+		return "", false
+	}
+
+	now := time.Now().UnixNano()
+	last := sr.lastDump.Load()
+	if now-last < int64(stackSnapshotInterval) || !sr.lastDump.CompareAndSwap(last, now) {
+		return "", false
+	}
+
+	buf := make([]byte, maxStackSnapshotSize)
+	dump := buf[:runtime.Stack(buf, true)]
+
+	header := []byte("goroutine " + goroutineID + " [")
+	for _, stack := range bytes.Split(dump, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, header) {
+			return string(stack), true
+		}
+	}
+
+	// The request finished while the goroutines were dumped
+	return "", false
+}
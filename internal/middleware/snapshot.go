@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/snapshot"
+	"github.com/labstack/echo/v4"
+)
+
+// panicStackKey holds the stack of a recovered panic until the error handler reports it.
+const panicStackKey = "panic_stack"
+
+// RequestSnapshot captures the safe values of the request's context for error reports,
+// see internal/lib/snapshot. The caller is only known after authentication, so it is
+// captured when the report is made rather than when the request comes in.
+func RequestSnapshot(c echo.Context) *snapshot.Snapshot {
+	role, _ := c.Get(UserRoleKey).(string)
+
+	snap := &snapshot.Snapshot{
+		Method:         c.Request().Method,
+		Route:          c.Path(),
+		RequestID:      GetRequestID(c),
+		UserID:         GetUserID(c),
+		OrganizationID: GetOrganizationID(c),
+		Role:           role,
+		Tenant:         GetQuotaConsumer(c),
+	}
+	snap.SetParams(c.ParamNames(), c.ParamValues())
+	snapshot.FromContext(c.Request().Context()).Fill(snap)
+
+	return snap
+}
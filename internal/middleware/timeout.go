@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/labstack/echo/v4"
+)
+
+// responseControllerKey holds the http.ResponseController of the request's connection.
+// It is captured before tracing, New Relic wraps the response writer in one that
+// http.ResponseController can't see through.
+const responseControllerKey = "response_controller"
+
+// CaptureResponseController stores the controller of the connection for WriteTimeout.
+func (gm *GlobalMiddleware) CaptureResponseController() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(responseControllerKey, http.NewResponseController(c.Response().Writer))
+			return next(c)
+		}
+	}
+}
+
+// WriteTimeout overrides the server's WriteTimeout for a route, counted from the moment the
+// route is reached; zero removes the limit. Normal endpoints keep the strict global limit.
+//
+//	r.GET("/events", h.Events.Stream, m.GlobalMiddleware.WriteTimeout(10*time.Minute))
+func (gm *GlobalMiddleware) WriteTimeout(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			controller, ok := c.Get(responseControllerKey).(*http.ResponseController)
+			if !ok {
+				controller = http.NewResponseController(c.Response())
+			}
+
+			var deadline time.Time
+			if timeout > 0 {
+				deadline = time.Now().Add(timeout)
+			}
+
+			// The server resets the deadline for the next request on the connection.
+			if err := controller.SetWriteDeadline(deadline); err != nil {
+				GetLogger(c).Warn().Err(err).Dur("timeout", timeout).Msg("failed to override write timeout")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// ReadTimeout overrides the server's ReadTimeout for a route, counted from the moment the
+// route is reached; zero removes the limit. It lets large uploads finish reading their body.
+//
+//	r.POST("/imports/:dataset", h.Import.UploadImport, m.GlobalMiddleware.ReadTimeout(10*time.Minute))
+func (gm *GlobalMiddleware) ReadTimeout(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			controller, ok := c.Get(responseControllerKey).(*http.ResponseController)
+			if !ok {
+				controller = http.NewResponseController(c.Response())
+			}
+
+			var deadline time.Time
+			if timeout > 0 {
+				deadline = time.Now().Add(timeout)
+			}
+
+			if err := controller.SetReadDeadline(deadline); err != nil {
+				GetLogger(c).Warn().Err(err).Dur("timeout", timeout).Msg("failed to override read timeout")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// StreamingWriteTimeout is WriteTimeout with the configured stream write timeout, for
+// server-sent events, websockets and other long-lived responses.
+func (gm *GlobalMiddleware) StreamingWriteTimeout() echo.MiddlewareFunc {
+	return gm.WriteTimeout(time.Duration(gm.server.Config.Server.StreamWriteTimeout) * time.Second)
+}
+
+// StatementTimeout overrides database.request_statement_timeout for a route: every
+// statement of the transactions the request runs (TxManager.WithinTx, DB.Begin) may take
+// at most timeout, applied with SET LOCAL. Statements running over fail with a 504.
+// Queries made outside a transaction keep the connection's statement_timeout.
+//
+//	admin.GET("/reports/usage", h.Report.Usage, m.GlobalMiddleware.StatementTimeout(30*time.Second))
+func (gm *GlobalMiddleware) StatementTimeout(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := database.WithStatementTimeout(c.Request().Context(), timeout)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+// Names of route middlewares timed with TimeStage, next to the global stages.
+const (
+	StageAuth            = "auth"
+	StageTenantRateLimit = "tenant_rate_limit"
+)
+
+// middlewareTimingsKey holds the timings of a sampled request in echo's context.
+const middlewareTimingsKey = "middleware_timings"
+
+// stageTiming is the time a request spent in one middleware.
+type stageTiming struct {
+	name string
+	// total runs from entering the middleware until it returned, inner is the part spent
+	// in the middlewares and handler it called.
+	total time.Duration
+	inner time.Duration
+}
+
+// middlewareTimings collects the stages of one request in the order they were entered.
+// Middlewares nest, so the stage on top of the stack is the one calling next.
+type middlewareTimings struct {
+	start  time.Time
+	stages []*stageTiming
+	stack  []*stageTiming
+}
+
+// MiddlewareTimingMiddleware logs how long each middleware of a request took on its own,
+// so a latency regression can be traced to a layer (auth, rate limiting, tracing, ...) or
+// the handler. Every request is timed in local development, a sample of them elsewhere.
+type MiddlewareTimingMiddleware struct {
+	server *server.Server
+}
+
+// NewMiddlewareTimingMiddleware returns a new MiddlewareTimingMiddleware tied to the server.
+func NewMiddlewareTimingMiddleware(s *server.Server) *MiddlewareTimingMiddleware {
+	return &MiddlewareTimingMiddleware{
+		server: s,
+	}
+}
+
+// sampleRate returns the fraction of requests timed.
+func (mt *MiddlewareTimingMiddleware) sampleRate() float64 {
+	if mt.server.Config.Primary.Env == "local" {
+		return 1
+	}
+	return mt.server.Config.Observability.Logging.MiddlewareTimingSampleRate
+}
+
+// enabled reports whether any request is timed.
+func (mt *MiddlewareTimingMiddleware) enabled() bool {
+	return mt.sampleRate() > 0
+}
+
+// Record samples requests for timing and logs the timings of the sampled ones once they
+// complete. It must run first, the chain adds it in front of the global middlewares.
+func (mt *MiddlewareTimingMiddleware) Record() echo.MiddlewareFunc {
+	rate := mt.sampleRate()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if rate <= 0 {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			if rate < 1 && rand.Float64() >= rate {
+				return next(c)
+			}
+
+			timings := &middlewareTimings{start: time.Now()}
+			c.Set(middlewareTimingsKey, timings)
+
+			err := next(c)
+
+			mt.log(c, timings, rate)
+			return err
+		}
+	}
+}
+
+func (mt *MiddlewareTimingMiddleware) log(c echo.Context, timings *middlewareTimings, rate float64) {
+	total := time.Since(timings.start)
+
+	// What no timed middleware accounts for is the handler, with the route middlewares not timed themselves
+	handler := total
+	stages := zerolog.Arr()
+	for _, stage := range timings.stages {
+		self := stage.total - stage.inner
+		handler -= self
+		stages.Dict(zerolog.Dict().Str("stage", stage.name).Dur("duration", self))
+	}
+
+	// Requests rejected before the context stage have no request logger yet
+	logger := GetLogger(c)
+	if _, ok := c.Get(echoLoggerKey).(*zerolog.Logger); !ok {
+		logger = mt.server.Logger
+	}
+
+	logger.Info().
+		Str("method", c.Request().Method).
+		Str("route", c.Path()).
+		Int("status", c.Response().Status).
+		Float64("sample_rate", rate).
+		Dur("total", total).
+		Dur("handler", handler).
+		Array("stages", stages).
+		Msg("middleware timings")
+}
+
+// TimeStage wraps mw so the time sampled requests spend in it, without the middlewares and
+// handler it calls, is logged under name. Requests that aren't sampled pass straight
+// through. The global chain times its stages itself, wrap route middlewares with it,
+// e.g. TimeStage(StageAuth, m.Authenticate).
+func TimeStage(name string, mw echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		wrapped := mw(func(c echo.Context) error {
+			timings, ok := c.Get(middlewareTimingsKey).(*middlewareTimings)
+			if !ok || len(timings.stack) == 0 {
+				return next(c)
+			}
+
+			stage := timings.stack[len(timings.stack)-1]
+			start := time.Now()
+			err := next(c)
+			stage.inner += time.Since(start)
+			return err
+		})
+
+		return func(c echo.Context) error {
+			timings, ok := c.Get(middlewareTimingsKey).(*middlewareTimings)
+			if !ok {
+				return wrapped(c)
+			}
+
+			stage := &stageTiming{name: name}
+			timings.stages = append(timings.stages, stage)
+			timings.stack = append(timings.stack, stage)
+
+			start := time.Now()
+			err := wrapped(c)
+			stage.total = time.Since(start)
+
+			timings.stack = timings.stack[:len(timings.stack)-1]
+			return err
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/labstack/echo/v4"
+)
+
+const usageDeprecatedKey = "usage_deprecated"
+
+// versionPattern extracts the API version from the route, e.g. "v1" from "/api/v1/me".
+var versionPattern = regexp.MustCompile(`^/api/(v\d+)(?:/|$)`)
+
+// UsageMiddleware counts the requests of every client per route, so the API usage
+// analytics show who calls what and deprecated routes can be retired once nobody
+// calls them anymore. Counts are kept in memory and flushed periodically through the
+// job queue, so tracking a request never waits on the database.
+type UsageMiddleware struct {
+	server *server.Server
+
+	mu     sync.Mutex
+	counts map[usageKey]*model.UsageCount
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// usageKey matches the unique key of the rollup, a flush must not count a row twice.
+type usageKey struct {
+	day    time.Time
+	client string
+	method string
+	route  string
+}
+
+// NewUsageMiddleware returns a new UsageMiddleware tied to the server, flushing counts
+// until the server shuts down and once more on shutdown.
+func NewUsageMiddleware(s *server.Server) *UsageMiddleware {
+	um := &UsageMiddleware{
+		server: s,
+		counts: make(map[usageKey]*model.UsageCount),
+	}
+
+	if !s.Config.Usage.Disabled && s.Job != nil {
+		um.stop = make(chan struct{})
+		um.done = make(chan struct{})
+		go um.flushLoop(s.Config.Usage.FlushInterval)
+
+		s.OnShutdown(func(ctx context.Context) {
+			close(um.stop)
+			<-um.done
+			um.flush(ctx)
+		})
+	}
+
+	return um
+}
+
+// Deprecated marks a route deprecated: responses announce it with the Deprecation and
+// Sunset headers (RFC 9745, RFC 8594) and its callers are sent deprecation notices. A
+// zero sunset omits the Sunset header, link points to the migration guide, if any.
+//
+//	r.GET("/me/quota", h.Quota.GetQuota, m.Usage.Deprecated(since, sunset, "https://docs.example.com/migrate"))
+func (um *UsageMiddleware) Deprecated(since, sunset time.Time, link string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			header.Set("Deprecation", "@"+strconv.FormatInt(since.Unix(), 10))
+			if !sunset.IsZero() {
+				header.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			if link != "" {
+				header.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, link))
+			}
+
+			c.Set(usageDeprecatedKey, true)
+			return next(c)
+		}
+	}
+}
+
+// Track counts every request once it was handled, when the route's authentication
+// middleware has identified the caller.
+func (um *UsageMiddleware) Track() echo.MiddlewareFunc {
+	enabled := !um.server.Config.Usage.Disabled && um.server.Job != nil
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !enabled {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			err := next(c)
+
+			// Unmatched paths have no route to count against.
+			if c.Path() == "" {
+				return err
+			}
+
+			deprecated, _ := c.Get(usageDeprecatedKey).(bool)
+			um.record(c.Request().Method, c.Path(), usageClient(c), deprecated, err != nil || c.Response().Status >= http.StatusBadRequest)
+
+			return err
+		}
+	}
+}
+
+// usageClient identifies the caller: the calling service, the authenticated user or
+// nobody.
+func usageClient(c echo.Context) string {
+	if caller := GetInternalCaller(c); caller != "" {
+		return "service:" + caller
+	}
+	if userID := GetUserID(c); userID != "" {
+		return "user:" + userID
+	}
+	return model.AnonymousUsageClient
+}
+
+func (um *UsageMiddleware) record(method, route, client string, deprecated, failed bool) {
+	now := time.Now().UTC()
+	key := usageKey{day: now.Truncate(24 * time.Hour), client: client, method: method, route: route}
+
+	um.mu.Lock()
+	defer um.mu.Unlock()
+
+	count, ok := um.counts[key]
+	if !ok {
+		count = &model.UsageCount{Day: key.day, Client: client, Method: method, Route: route}
+		if match := versionPattern.FindStringSubmatch(route); match != nil {
+			count.Version = match[1]
+		}
+		um.counts[key] = count
+	}
+
+	count.Requests++
+	if failed {
+		count.Errors++
+	}
+	count.Deprecated = count.Deprecated || deprecated
+	count.LastSeenAt = now
+}
+
+func (um *UsageMiddleware) flushLoop(interval time.Duration) {
+	defer close(um.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-um.stop:
+			return
+		case <-ticker.C:
+			um.flush(context.Background())
+		}
+	}
+}
+
+// flush enqueues the counts since the last flush. Counts that couldn't be enqueued are
+// dropped, analytics aren't worth piling up memory for while the queue is down.
+func (um *UsageMiddleware) flush(ctx context.Context) {
+	um.mu.Lock()
+	if len(um.counts) == 0 {
+		um.mu.Unlock()
+		return
+	}
+	counts := make([]model.UsageCount, 0, len(um.counts))
+	for _, count := range um.counts {
+		counts = append(counts, *count)
+	}
+	um.counts = make(map[usageKey]*model.UsageCount, len(counts))
+	um.mu.Unlock()
+
+	task, err := job.NewUsageRollupTask(counts)
+	if err != nil {
+		um.server.Logger.Error().Err(err).Int("counts", len(counts)).Msg("failed to create usage rollup task")
+		return
+	}
+
+	if _, err := um.server.Job.Enqueue(ctx, task); err != nil {
+		um.server.Logger.Warn().Err(err).Int("counts", len(counts)).Msg("failed to enqueue usage rollup, counts dropped")
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actor used for audit entries written by the system itself (jobs, schedulers).
+const AuditActorSystem = "system"
+
+// Actor used for audit entries written on behalf of an email recipient acting through
+// a signed link, e.g. unsubscribing. The address is the entry's resource ID.
+const AuditActorEmailRecipient = "email_recipient"
+
+// Resource type of audit entries recording a change of a runtime setting. Their
+// resource ID is the setting, followed by ":<scope>" for scoped settings.
+const AuditResourceConfig = "config"
+
+// Resource type of audit entries recording a security event, e.g. an authentication
+// lockout. Their resource ID is what it applies to, e.g. "ip:203.0.113.7" or "user:<id>".
+const AuditResourceAuth = "auth"
+
+// Resource type of audit entries about an email address, e.g. unsubscribing it from
+// bulk emails. Their resource ID is the normalized address.
+const AuditResourceEmail = "email"
+
+// Resource type of audit entries recording an authorization decision of the policy
+// engine. Their resource ID is the resource access was asked for.
+const AuditResourceAuthz = "authz"
+
+// AuditLog is an append-only record of a security or compliance relevant action.
+type AuditLog struct {
+	ID           uuid.UUID      `json:"id" db:"id"`
+	ActorID      string         `json:"actor_id" db:"actor_id"`
+	Action       string         `json:"action" db:"action"`
+	ResourceType string         `json:"resource_type" db:"resource_type"`
+	ResourceID   *string        `json:"resource_id,omitempty" db:"resource_id"`
+	RequestID    *string        `json:"request_id,omitempty" db:"request_id"`
+	Metadata     map[string]any `json:"metadata" db:"metadata"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+}
+
+// AuditActionCount is the number of times an action was recorded.
+type AuditActionCount struct {
+	Action string `json:"action" db:"action"`
+	Count  int64  `json:"count" db:"count"`
+}
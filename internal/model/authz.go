@@ -0,0 +1,29 @@
+package model
+
+import "github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+
+// Policy is an authorization policy: it allows or denies the subjects matching Subject
+// the actions matching Action on the resources matching Resource (see internal/lib/authz).
+type Policy struct {
+	Base
+	// Subject is "*", "user:<id>", "role:<role>" or "org:<id>".
+	Subject string `json:"subject" db:"subject"`
+	// Action and Resource are patterns, "*" matches any run of characters.
+	Action      string       `json:"action" db:"action"`
+	Resource    string       `json:"resource" db:"resource"`
+	Effect      PolicyEffect `json:"effect" db:"effect"`
+	Description *string      `json:"description,omitempty" db:"description"`
+}
+
+// CreatePolicyPayload adds an authorization policy.
+type CreatePolicyPayload struct {
+	Subject     string       `json:"subject" validate:"required,max=256"`
+	Action      string       `json:"action" validate:"required,max=256"`
+	Resource    string       `json:"resource" validate:"required,max=512"`
+	Effect      PolicyEffect `json:"effect" validate:"required,oneof=allow deny"`
+	Description *string      `json:"description" validate:"omitempty,max=512"`
+}
+
+func (p *CreatePolicyPayload) Validate() error {
+	return validation.NewValidator().Struct(p)
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// DataExport is a user-initiated request to export all of their data.
+type DataExport struct {
+	Base
+	UserID      string           `json:"user_id" db:"user_id"`
+	Status      DataExportStatus `json:"status" db:"status"`
+	Archive     []byte           `json:"-" db:"archive"`
+	SizeBytes   *int64           `json:"size_bytes,omitempty" db:"size_bytes"`
+	Error       *string          `json:"error,omitempty" db:"error"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty" db:"completed_at"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty" db:"expires_at"`
+}
+
+// AccountDeletion is a scheduled deletion of a user's account. It can be
+// cancelled until ScheduledFor, after which the deletion job anonymizes the user's data.
+type AccountDeletion struct {
+	Base
+	UserID       string                `json:"user_id" db:"user_id"`
+	Status       AccountDeletionStatus `json:"status" db:"status"`
+	ScheduledFor time.Time             `json:"scheduled_for" db:"scheduled_for"`
+	CancelledAt  *time.Time            `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CompletedAt  *time.Time            `json:"completed_at,omitempty" db:"completed_at"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// EmailSuppression is an address that must not receive non-essential emails.
+type EmailSuppression struct {
+	Email     string                 `json:"email" db:"email"`
+	Reason    EmailSuppressionReason `json:"reason" db:"reason"`
+	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+}
+
+// EmailUnsubscribe is the state of an unsubscribe link, for the page confirming it.
+type EmailUnsubscribe struct {
+	Email string `json:"email"`
+	// List is the kind of email the link unsubscribes from, e.g. "digest".
+	List         string `json:"list"`
+	Unsubscribed bool   `json:"unsubscribed"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+)
+
+// EmailVerification is the verification state of a user's email address.
+type EmailVerification struct {
+	UserID string `json:"user_id" db:"user_id"`
+	Email  string `json:"email" db:"email"`
+	// Verified is true once the address was verified, until it is changed.
+	Verified  bool    `json:"verified" db:"verified"`
+	TokenHash *string `json:"-" db:"token_hash"`
+	// TokenExpiresAt is when the last link sent stops working, unset once verified.
+	TokenExpiresAt  *time.Time `json:"token_expires_at,omitempty" db:"token_expires_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	LastSentAt      *time.Time `json:"last_sent_at,omitempty" db:"last_sent_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// RequestEmailVerificationPayload sends a verification link to the caller's address.
+type RequestEmailVerificationPayload struct {
+	Email string `json:"email" validate:"required,email,max=320"`
+}
+
+func (p *RequestEmailVerificationPayload) Validate() error {
+	return validation.NewValidator().Struct(p)
+}
@@ -0,0 +1,522 @@
+// Code generated by go-boilerplate gen enums. DO NOT EDIT.
+
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// DataExportStatus is the lifecycle state of a data export.
+type DataExportStatus string
+
+const (
+	DataExportStatusPending    DataExportStatus = "pending"
+	DataExportStatusProcessing DataExportStatus = "processing"
+	DataExportStatusCompleted  DataExportStatus = "completed"
+	DataExportStatusFailed     DataExportStatus = "failed"
+)
+
+// dataExportStatusValues lists every valid DataExportStatus in declaration order.
+var dataExportStatusValues = []DataExportStatus{DataExportStatusPending, DataExportStatusProcessing, DataExportStatusCompleted, DataExportStatusFailed}
+
+// DataExportStatusValues returns every valid DataExportStatus in declaration order.
+func DataExportStatusValues() []DataExportStatus {
+	return append([]DataExportStatus(nil), dataExportStatusValues...)
+}
+
+// ParseDataExportStatus returns the DataExportStatus matching s, or an error if s is not declared.
+func ParseDataExportStatus(s string) (DataExportStatus, error) {
+	for _, v := range dataExportStatusValues {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid DataExportStatus %q: must be one of pending | processing | completed | failed", s)
+}
+
+// IsValid reports whether e is a declared DataExportStatus.
+func (e DataExportStatus) IsValid() bool {
+	_, err := ParseDataExportStatus(string(e))
+	return err == nil
+}
+
+// EnumValues returns the declared values, the "enum" validator tag lists them in its message.
+func (e DataExportStatus) EnumValues() []string {
+	return []string{"pending", "processing", "completed", "failed"}
+}
+
+func (e DataExportStatus) String() string {
+	return string(e)
+}
+
+// UnmarshalText rejects undeclared values when binding JSON bodies and query params.
+func (e *DataExportStatus) UnmarshalText(text []byte) error {
+	v, err := ParseDataExportStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+
+// Scan implements sql.Scanner, rejecting values the database should never hold.
+func (e *DataExportStatus) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return e.UnmarshalText([]byte(v))
+	case []byte:
+		return e.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into DataExportStatus", src)
+	}
+}
+
+// Value implements driver.Valuer, refusing to write undeclared values.
+func (e DataExportStatus) Value() (driver.Value, error) {
+	if !e.IsValid() {
+		return nil, fmt.Errorf("invalid DataExportStatus %q: must be one of pending | processing | completed | failed", string(e))
+	}
+	return string(e), nil
+}
+
+// AccountDeletionStatus is the lifecycle state of a scheduled account deletion.
+type AccountDeletionStatus string
+
+const (
+	AccountDeletionStatusPending   AccountDeletionStatus = "pending"
+	AccountDeletionStatusCancelled AccountDeletionStatus = "cancelled"
+	AccountDeletionStatusCompleted AccountDeletionStatus = "completed"
+)
+
+// accountDeletionStatusValues lists every valid AccountDeletionStatus in declaration order.
+var accountDeletionStatusValues = []AccountDeletionStatus{AccountDeletionStatusPending, AccountDeletionStatusCancelled, AccountDeletionStatusCompleted}
+
+// AccountDeletionStatusValues returns every valid AccountDeletionStatus in declaration order.
+func AccountDeletionStatusValues() []AccountDeletionStatus {
+	return append([]AccountDeletionStatus(nil), accountDeletionStatusValues...)
+}
+
+// ParseAccountDeletionStatus returns the AccountDeletionStatus matching s, or an error if s is not declared.
+func ParseAccountDeletionStatus(s string) (AccountDeletionStatus, error) {
+	for _, v := range accountDeletionStatusValues {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid AccountDeletionStatus %q: must be one of pending | cancelled | completed", s)
+}
+
+// IsValid reports whether e is a declared AccountDeletionStatus.
+func (e AccountDeletionStatus) IsValid() bool {
+	_, err := ParseAccountDeletionStatus(string(e))
+	return err == nil
+}
+
+// EnumValues returns the declared values, the "enum" validator tag lists them in its message.
+func (e AccountDeletionStatus) EnumValues() []string {
+	return []string{"pending", "cancelled", "completed"}
+}
+
+func (e AccountDeletionStatus) String() string {
+	return string(e)
+}
+
+// UnmarshalText rejects undeclared values when binding JSON bodies and query params.
+func (e *AccountDeletionStatus) UnmarshalText(text []byte) error {
+	v, err := ParseAccountDeletionStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+
+// Scan implements sql.Scanner, rejecting values the database should never hold.
+func (e *AccountDeletionStatus) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return e.UnmarshalText([]byte(v))
+	case []byte:
+		return e.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into AccountDeletionStatus", src)
+	}
+}
+
+// Value implements driver.Valuer, refusing to write undeclared values.
+func (e AccountDeletionStatus) Value() (driver.Value, error) {
+	if !e.IsValid() {
+		return nil, fmt.Errorf("invalid AccountDeletionStatus %q: must be one of pending | cancelled | completed", string(e))
+	}
+	return string(e), nil
+}
+
+// EmailSuppressionReason is why an address stopped receiving non-essential emails.
+type EmailSuppressionReason string
+
+const (
+	EmailSuppressionBounce      EmailSuppressionReason = "bounce"
+	EmailSuppressionComplaint   EmailSuppressionReason = "complaint"
+	EmailSuppressionUnsubscribe EmailSuppressionReason = "unsubscribe"
+)
+
+// emailSuppressionReasonValues lists every valid EmailSuppressionReason in declaration order.
+var emailSuppressionReasonValues = []EmailSuppressionReason{EmailSuppressionBounce, EmailSuppressionComplaint, EmailSuppressionUnsubscribe}
+
+// EmailSuppressionReasonValues returns every valid EmailSuppressionReason in declaration order.
+func EmailSuppressionReasonValues() []EmailSuppressionReason {
+	return append([]EmailSuppressionReason(nil), emailSuppressionReasonValues...)
+}
+
+// ParseEmailSuppressionReason returns the EmailSuppressionReason matching s, or an error if s is not declared.
+func ParseEmailSuppressionReason(s string) (EmailSuppressionReason, error) {
+	for _, v := range emailSuppressionReasonValues {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid EmailSuppressionReason %q: must be one of bounce | complaint | unsubscribe", s)
+}
+
+// IsValid reports whether e is a declared EmailSuppressionReason.
+func (e EmailSuppressionReason) IsValid() bool {
+	_, err := ParseEmailSuppressionReason(string(e))
+	return err == nil
+}
+
+// EnumValues returns the declared values, the "enum" validator tag lists them in its message.
+func (e EmailSuppressionReason) EnumValues() []string {
+	return []string{"bounce", "complaint", "unsubscribe"}
+}
+
+func (e EmailSuppressionReason) String() string {
+	return string(e)
+}
+
+// UnmarshalText rejects undeclared values when binding JSON bodies and query params.
+func (e *EmailSuppressionReason) UnmarshalText(text []byte) error {
+	v, err := ParseEmailSuppressionReason(string(text))
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+
+// Scan implements sql.Scanner, rejecting values the database should never hold.
+func (e *EmailSuppressionReason) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return e.UnmarshalText([]byte(v))
+	case []byte:
+		return e.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into EmailSuppressionReason", src)
+	}
+}
+
+// Value implements driver.Valuer, refusing to write undeclared values.
+func (e EmailSuppressionReason) Value() (driver.Value, error) {
+	if !e.IsValid() {
+		return nil, fmt.Errorf("invalid EmailSuppressionReason %q: must be one of bounce | complaint | unsubscribe", string(e))
+	}
+	return string(e), nil
+}
+
+// ImportStatus is the lifecycle state of a bulk import.
+type ImportStatus string
+
+const (
+	ImportStatusValidating ImportStatus = "validating"
+	ImportStatusInvalid    ImportStatus = "invalid"
+	ImportStatusPending    ImportStatus = "pending"
+	ImportStatusProcessing ImportStatus = "processing"
+	ImportStatusCompleted  ImportStatus = "completed"
+	ImportStatusFailed     ImportStatus = "failed"
+)
+
+// importStatusValues lists every valid ImportStatus in declaration order.
+var importStatusValues = []ImportStatus{ImportStatusValidating, ImportStatusInvalid, ImportStatusPending, ImportStatusProcessing, ImportStatusCompleted, ImportStatusFailed}
+
+// ImportStatusValues returns every valid ImportStatus in declaration order.
+func ImportStatusValues() []ImportStatus {
+	return append([]ImportStatus(nil), importStatusValues...)
+}
+
+// ParseImportStatus returns the ImportStatus matching s, or an error if s is not declared.
+func ParseImportStatus(s string) (ImportStatus, error) {
+	for _, v := range importStatusValues {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid ImportStatus %q: must be one of validating | invalid | pending | processing | completed | failed", s)
+}
+
+// IsValid reports whether e is a declared ImportStatus.
+func (e ImportStatus) IsValid() bool {
+	_, err := ParseImportStatus(string(e))
+	return err == nil
+}
+
+// EnumValues returns the declared values, the "enum" validator tag lists them in its message.
+func (e ImportStatus) EnumValues() []string {
+	return []string{"validating", "invalid", "pending", "processing", "completed", "failed"}
+}
+
+func (e ImportStatus) String() string {
+	return string(e)
+}
+
+// UnmarshalText rejects undeclared values when binding JSON bodies and query params.
+func (e *ImportStatus) UnmarshalText(text []byte) error {
+	v, err := ParseImportStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+
+// Scan implements sql.Scanner, rejecting values the database should never hold.
+func (e *ImportStatus) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return e.UnmarshalText([]byte(v))
+	case []byte:
+		return e.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ImportStatus", src)
+	}
+}
+
+// Value implements driver.Valuer, refusing to write undeclared values.
+func (e ImportStatus) Value() (driver.Value, error) {
+	if !e.IsValid() {
+		return nil, fmt.Errorf("invalid ImportStatus %q: must be one of validating | invalid | pending | processing | completed | failed", string(e))
+	}
+	return string(e), nil
+}
+
+// SubscriptionStatus is the billing state of a subscription, as reported by the payments provider.
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusTrialing          SubscriptionStatus = "trialing"
+	SubscriptionStatusActive            SubscriptionStatus = "active"
+	SubscriptionStatusPastDue           SubscriptionStatus = "past_due"
+	SubscriptionStatusUnpaid            SubscriptionStatus = "unpaid"
+	SubscriptionStatusPaused            SubscriptionStatus = "paused"
+	SubscriptionStatusIncomplete        SubscriptionStatus = "incomplete"
+	SubscriptionStatusIncompleteExpired SubscriptionStatus = "incomplete_expired"
+	SubscriptionStatusCanceled          SubscriptionStatus = "canceled"
+)
+
+// subscriptionStatusValues lists every valid SubscriptionStatus in declaration order.
+var subscriptionStatusValues = []SubscriptionStatus{SubscriptionStatusTrialing, SubscriptionStatusActive, SubscriptionStatusPastDue, SubscriptionStatusUnpaid, SubscriptionStatusPaused, SubscriptionStatusIncomplete, SubscriptionStatusIncompleteExpired, SubscriptionStatusCanceled}
+
+// SubscriptionStatusValues returns every valid SubscriptionStatus in declaration order.
+func SubscriptionStatusValues() []SubscriptionStatus {
+	return append([]SubscriptionStatus(nil), subscriptionStatusValues...)
+}
+
+// ParseSubscriptionStatus returns the SubscriptionStatus matching s, or an error if s is not declared.
+func ParseSubscriptionStatus(s string) (SubscriptionStatus, error) {
+	for _, v := range subscriptionStatusValues {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid SubscriptionStatus %q: must be one of trialing | active | past_due | unpaid | paused | incomplete | incomplete_expired | canceled", s)
+}
+
+// IsValid reports whether e is a declared SubscriptionStatus.
+func (e SubscriptionStatus) IsValid() bool {
+	_, err := ParseSubscriptionStatus(string(e))
+	return err == nil
+}
+
+// EnumValues returns the declared values, the "enum" validator tag lists them in its message.
+func (e SubscriptionStatus) EnumValues() []string {
+	return []string{"trialing", "active", "past_due", "unpaid", "paused", "incomplete", "incomplete_expired", "canceled"}
+}
+
+func (e SubscriptionStatus) String() string {
+	return string(e)
+}
+
+// UnmarshalText rejects undeclared values when binding JSON bodies and query params.
+func (e *SubscriptionStatus) UnmarshalText(text []byte) error {
+	v, err := ParseSubscriptionStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+
+// Scan implements sql.Scanner, rejecting values the database should never hold.
+func (e *SubscriptionStatus) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return e.UnmarshalText([]byte(v))
+	case []byte:
+		return e.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into SubscriptionStatus", src)
+	}
+}
+
+// Value implements driver.Valuer, refusing to write undeclared values.
+func (e SubscriptionStatus) Value() (driver.Value, error) {
+	if !e.IsValid() {
+		return nil, fmt.Errorf("invalid SubscriptionStatus %q: must be one of trialing | active | past_due | unpaid | paused | incomplete | incomplete_expired | canceled", string(e))
+	}
+	return string(e), nil
+}
+
+// NotificationStatus is the delivery state of an SMS or push notification.
+type NotificationStatus string
+
+const (
+	NotificationStatusQueued      NotificationStatus = "queued"
+	NotificationStatusSent        NotificationStatus = "sent"
+	NotificationStatusDelivered   NotificationStatus = "delivered"
+	NotificationStatusUndelivered NotificationStatus = "undelivered"
+	NotificationStatusFailed      NotificationStatus = "failed"
+)
+
+// notificationStatusValues lists every valid NotificationStatus in declaration order.
+var notificationStatusValues = []NotificationStatus{NotificationStatusQueued, NotificationStatusSent, NotificationStatusDelivered, NotificationStatusUndelivered, NotificationStatusFailed}
+
+// NotificationStatusValues returns every valid NotificationStatus in declaration order.
+func NotificationStatusValues() []NotificationStatus {
+	return append([]NotificationStatus(nil), notificationStatusValues...)
+}
+
+// ParseNotificationStatus returns the NotificationStatus matching s, or an error if s is not declared.
+func ParseNotificationStatus(s string) (NotificationStatus, error) {
+	for _, v := range notificationStatusValues {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid NotificationStatus %q: must be one of queued | sent | delivered | undelivered | failed", s)
+}
+
+// IsValid reports whether e is a declared NotificationStatus.
+func (e NotificationStatus) IsValid() bool {
+	_, err := ParseNotificationStatus(string(e))
+	return err == nil
+}
+
+// EnumValues returns the declared values, the "enum" validator tag lists them in its message.
+func (e NotificationStatus) EnumValues() []string {
+	return []string{"queued", "sent", "delivered", "undelivered", "failed"}
+}
+
+func (e NotificationStatus) String() string {
+	return string(e)
+}
+
+// UnmarshalText rejects undeclared values when binding JSON bodies and query params.
+func (e *NotificationStatus) UnmarshalText(text []byte) error {
+	v, err := ParseNotificationStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+
+// Scan implements sql.Scanner, rejecting values the database should never hold.
+func (e *NotificationStatus) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return e.UnmarshalText([]byte(v))
+	case []byte:
+		return e.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into NotificationStatus", src)
+	}
+}
+
+// Value implements driver.Valuer, refusing to write undeclared values.
+func (e NotificationStatus) Value() (driver.Value, error) {
+	if !e.IsValid() {
+		return nil, fmt.Errorf("invalid NotificationStatus %q: must be one of queued | sent | delivered | undelivered | failed", string(e))
+	}
+	return string(e), nil
+}
+
+// PolicyEffect is whether an authorization policy allows or denies what it matches.
+type PolicyEffect string
+
+const (
+	PolicyEffectAllow PolicyEffect = "allow"
+	PolicyEffectDeny  PolicyEffect = "deny"
+)
+
+// policyEffectValues lists every valid PolicyEffect in declaration order.
+var policyEffectValues = []PolicyEffect{PolicyEffectAllow, PolicyEffectDeny}
+
+// PolicyEffectValues returns every valid PolicyEffect in declaration order.
+func PolicyEffectValues() []PolicyEffect {
+	return append([]PolicyEffect(nil), policyEffectValues...)
+}
+
+// ParsePolicyEffect returns the PolicyEffect matching s, or an error if s is not declared.
+func ParsePolicyEffect(s string) (PolicyEffect, error) {
+	for _, v := range policyEffectValues {
+		if string(v) == s {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid PolicyEffect %q: must be one of allow | deny", s)
+}
+
+// IsValid reports whether e is a declared PolicyEffect.
+func (e PolicyEffect) IsValid() bool {
+	_, err := ParsePolicyEffect(string(e))
+	return err == nil
+}
+
+// EnumValues returns the declared values, the "enum" validator tag lists them in its message.
+func (e PolicyEffect) EnumValues() []string {
+	return []string{"allow", "deny"}
+}
+
+func (e PolicyEffect) String() string {
+	return string(e)
+}
+
+// UnmarshalText rejects undeclared values when binding JSON bodies and query params.
+func (e *PolicyEffect) UnmarshalText(text []byte) error {
+	v, err := ParsePolicyEffect(string(text))
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+
+// Scan implements sql.Scanner, rejecting values the database should never hold.
+func (e *PolicyEffect) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return e.UnmarshalText([]byte(v))
+	case []byte:
+		return e.UnmarshalText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into PolicyEffect", src)
+	}
+}
+
+// Value implements driver.Valuer, refusing to write undeclared values.
+func (e PolicyEffect) Value() (driver.Value, error) {
+	if !e.IsValid() {
+		return nil, fmt.Errorf("invalid PolicyEffect %q: must be one of allow | deny", string(e))
+	}
+	return string(e), nil
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+)
+
+// Export is an async CSV/XLSX export of a dataset. It shares the lifecycle of
+// DataExport: pending, processing, then completed or failed.
+type Export struct {
+	Base
+	RequestedBy string           `json:"requested_by" db:"requested_by"`
+	Dataset     string           `json:"dataset" db:"dataset"`
+	Format      string           `json:"format" db:"format"`
+	Columns     []string         `json:"columns" db:"columns"`
+	Language    string           `json:"language,omitempty" db:"language"`
+	RangeFrom   *time.Time       `json:"from,omitempty" db:"range_from"`
+	RangeUntil  *time.Time       `json:"until,omitempty" db:"range_until"`
+	Status      DataExportStatus `json:"status" db:"status"`
+	Content     []byte           `json:"-" db:"content"`
+	RowCount    *int64           `json:"row_count,omitempty" db:"row_count"`
+	SizeBytes   *int64           `json:"size_bytes,omitempty" db:"size_bytes"`
+	Error       *string          `json:"error,omitempty" db:"error"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty" db:"completed_at"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty" db:"expires_at"`
+}
+
+// CreateExportPayload requests an async export of a dataset.
+type CreateExportPayload struct {
+	Format  string     `json:"format" validate:"omitempty,oneof=csv xlsx"`
+	Columns []string   `json:"columns" validate:"omitempty,dive,required"`
+	From    *time.Time `json:"from"`
+	Until   *time.Time `json:"until"`
+}
+
+func (p *CreateExportPayload) Validate() error {
+	if err := validation.NewValidator().Struct(p); err != nil {
+		return err
+	}
+
+	if p.From != nil && p.Until != nil && !p.From.Before(*p.Until) {
+		return validation.CustomValidationErrors{{Field: "until", Message: "must be after from"}}
+	}
+
+	return nil
+}
+
+// TimeRange returns the range of rows the export covers.
+func (e *Export) TimeRange() TimeRange {
+	return TimeRange{From: e.RangeFrom, Until: e.RangeUntil}
+}
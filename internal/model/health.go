@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HealthProbe is the row a deep health check writes, reads and has a probe job process.
+type HealthProbe struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	ProcessedAt *time.Time `json:"processed_at" db:"processed_at"`
+}
+
+// DeepHealth is the outcome of a deep health check, healthy if every step passed.
+type DeepHealth struct {
+	Status     string            `json:"status"`
+	DurationMS int64             `json:"duration_ms"`
+	Steps      []DeepHealthCheck `json:"steps"`
+}
+
+// DeepHealthCheck is one step of a deep health check.
+type DeepHealthCheck struct {
+	Name string `json:"name"`
+	// Status is "healthy", "unhealthy" or "skipped" when the step is disabled or
+	// couldn't run because an earlier step failed.
+	Status     string `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// Import is a bulk CSV import into a dataset. Rows are validated on upload, the first
+// errors are kept in Errors, and the valid rows are committed by a job, with
+// ProcessedRows counting its progress.
+type Import struct {
+	Base
+	RequestedBy   string        `json:"requested_by" db:"requested_by"`
+	Dataset       string        `json:"dataset" db:"dataset"`
+	Filename      string        `json:"filename" db:"filename"`
+	Status        ImportStatus  `json:"status" db:"status"`
+	TotalRows     int64         `json:"total_rows" db:"total_rows"`
+	ValidRows     int64         `json:"valid_rows" db:"valid_rows"`
+	ProcessedRows int64         `json:"processed_rows" db:"processed_rows"`
+	ErrorCount    int64         `json:"error_count" db:"error_count"`
+	Errors        []ImportError `json:"errors" db:"errors"`
+	Error         *string       `json:"error,omitempty" db:"error"`
+	CompletedAt   *time.Time    `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// ImportError is a problem with one row of an import.
+type ImportError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// StagedImportRow is a validated row of an import waiting to be committed.
+type StagedImportRow struct {
+	Line int               `json:"line" db:"line"`
+	Data map[string]string `json:"data" db:"data"`
+}
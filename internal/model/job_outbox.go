@@ -0,0 +1,19 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxTask is a job task persisted because its queue was full.
+type OutboxTask struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TaskType string    `json:"task_type" db:"task_type"`
+	Payload  []byte    `json:"payload" db:"payload"`
+	Queue    string    `json:"queue" db:"queue"`
+	// Options are the task's options as encoded by the job service, see job.RestoreOptions.
+	Options   json.RawMessage `json:"options" db:"options"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
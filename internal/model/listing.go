@@ -0,0 +1,62 @@
+package model
+
+// FilterOp is a comparison operator of a list filter, e.g. filter[created_at][gte]=2026-01-01T00:00:00Z.
+type FilterOp string
+
+const (
+	FilterEq  FilterOp = "eq"
+	FilterNe  FilterOp = "ne"
+	FilterLt  FilterOp = "lt"
+	FilterLte FilterOp = "lte"
+	FilterGt  FilterOp = "gt"
+	FilterGte FilterOp = "gte"
+	// FilterIn matches any of a comma-separated list of values.
+	FilterIn FilterOp = "in"
+	// FilterPrefix matches strings starting with the value.
+	FilterPrefix FilterOp = "prefix"
+)
+
+// Filter restricts a list to items whose Field compares to Value with Op.
+type Filter struct {
+	Field string
+	Op    FilterOp
+	Value string
+}
+
+// ListParams is the shared listing contract of the admin endpoints: cursor pagination,
+// a sort field from the endpoint's allowlist ("-" prefix for descending) and filters.
+type ListParams struct {
+	Cursor  string
+	Limit   int
+	Sort    string
+	Filters []Filter
+}
+
+// Normalize clamps the limit to valid values.
+func (p ListParams) Normalize() ListParams {
+	if p.Limit < 1 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	return p
+}
+
+// CursorInfo describes a cursor-paginated page. NextCursor is passed back as the
+// cursor parameter to fetch the following page, it is only valid with the same sort.
+type CursorInfo struct {
+	Limit      int     `json:"limit"`
+	Sort       string  `json:"sort"`
+	HasNext    bool    `json:"has_next"`
+	NextCursor *string `json:"next_cursor,omitempty"`
+	// EstimatedTotal is about how many items the unfiltered listing has, on listings
+	// that offer it.
+	EstimatedTotal *int64 `json:"estimated_total,omitempty"`
+}
+
+// CursorPage is a cursor-paginated page of items together with its metadata.
+type CursorPage[T any] struct {
+	Items      []T        `json:"items"`
+	CursorInfo CursorInfo `json:"cursor_info"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+)
+
+// Device is an app install of a user that receives push notifications.
+type Device struct {
+	Base
+	UserID string `json:"user_id" db:"user_id"`
+	// Provider issued the token, "fcm" or "apns".
+	Provider   string    `json:"provider" db:"provider"`
+	Platform   string    `json:"platform" db:"platform"`
+	Token      string    `json:"-" db:"token"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
+
+// RegisterDevicePayload registers the push token of an app install for the caller.
+type RegisterDevicePayload struct {
+	Token    string `json:"token" validate:"required,max=4096"`
+	Provider string `json:"provider" validate:"required,oneof=fcm apns"`
+	Platform string `json:"platform" validate:"required,oneof=ios android web"`
+}
+
+func (p *RegisterDevicePayload) Validate() error {
+	return validation.NewValidator().Struct(p)
+}
+
+// NotificationDelivery is an SMS or push notification sent to a user.
+type NotificationDelivery struct {
+	Base
+	UserID  string `json:"user_id" db:"user_id"`
+	Channel string `json:"channel" db:"channel"`
+	// Provider sent the notification, e.g. "twilio" or "fcm".
+	Provider string `json:"provider" db:"provider"`
+	// Recipient is the phone number or device token.
+	Recipient         string             `json:"recipient" db:"recipient"`
+	ProviderMessageID *string            `json:"provider_message_id,omitempty" db:"provider_message_id"`
+	Status            NotificationStatus `json:"status" db:"status"`
+	Error             *string            `json:"error,omitempty" db:"error"`
+}
+
+// NotificationData is everything held about a user's notifications, for data exports.
+type NotificationData struct {
+	Devices    []Device               `json:"devices"`
+	Deliveries []NotificationDelivery `json:"deliveries"`
+}
@@ -0,0 +1,63 @@
+package model
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// CountStrategy selects how the total of a paginated list is computed.
+// Exact counts scan every matching row, which gets slow on large tables,
+// so each route picks the cheapest strategy its clients can live with.
+type CountStrategy string
+
+const (
+	// CountExact runs COUNT(*) over the filtered query.
+	CountExact CountStrategy = "exact"
+	// CountEstimated uses pg_class.reltuples for unfiltered lists and the planner's
+	// row estimate otherwise. Cheap, but can be off until the table is analyzed.
+	CountEstimated CountStrategy = "estimated"
+	// CountNone skips counting, clients only learn whether a next page exists.
+	CountNone CountStrategy = "none"
+)
+
+// PageParams are the requested page and page size.
+type PageParams struct {
+	Page  int `query:"page"`
+	Limit int `query:"limit"`
+}
+
+// Normalize clamps the params to valid values.
+func (p PageParams) Normalize() PageParams {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.Limit < 1 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	return p
+}
+
+// Offset returns the number of rows to skip.
+func (p PageParams) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// PageInfo describes a page of a list. Total and TotalPages are only set when
+// the list was counted, and are approximate with CountEstimated.
+type PageInfo struct {
+	Page          int           `json:"page"`
+	Limit         int           `json:"limit"`
+	HasNext       bool          `json:"has_next"`
+	Total         *int64        `json:"total,omitempty"`
+	TotalPages    *int64        `json:"total_pages,omitempty"`
+	CountStrategy CountStrategy `json:"count_strategy"`
+}
+
+// Page is a page of items together with its metadata.
+type Page[T any] struct {
+	Items    []T      `json:"items"`
+	PageInfo PageInfo `json:"page_info"`
+}
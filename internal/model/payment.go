@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+)
+
+// PaymentCustomer links an owner, the organization or user billed, to its customer at
+// the payments provider.
+type PaymentCustomer struct {
+	OwnerID    string    `json:"owner_id" db:"owner_id"`
+	Provider   string    `json:"provider" db:"provider"`
+	CustomerID string    `json:"customer_id" db:"customer_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Subscription is a subscription of an owner, synced from the payments provider's webhooks.
+type Subscription struct {
+	Base
+	Provider               string             `json:"provider" db:"provider"`
+	ProviderSubscriptionID string             `json:"provider_subscription_id" db:"provider_subscription_id"`
+	CustomerID             string             `json:"customer_id" db:"customer_id"`
+	OwnerID                string             `json:"owner_id" db:"owner_id"`
+	Status                 SubscriptionStatus `json:"status" db:"status"`
+	PriceID                string             `json:"price_id" db:"price_id"`
+	CurrentPeriodEnd       *time.Time         `json:"current_period_end,omitempty" db:"current_period_end"`
+	CancelAtPeriodEnd      bool               `json:"cancel_at_period_end" db:"cancel_at_period_end"`
+	CanceledAt             *time.Time         `json:"canceled_at,omitempty" db:"canceled_at"`
+	SyncedAt               time.Time          `json:"synced_at" db:"synced_at"`
+}
+
+// CreateCheckoutPayload starts a checkout subscribing the caller to a price.
+type CreateCheckoutPayload struct {
+	PriceID string `json:"price_id" validate:"required,max=255"`
+}
+
+func (p *CreateCheckoutPayload) Validate() error {
+	return validation.NewValidator().Struct(p)
+}
+
+// CheckoutSession is a hosted checkout to send the customer to.
+type CheckoutSession struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
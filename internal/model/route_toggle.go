@@ -0,0 +1,17 @@
+package model
+
+import "github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+
+// DisableRoutePayload disables a route, see internal/lib/routetoggle.
+type DisableRoutePayload struct {
+	// Method is the HTTP method of the route, or * for all of them.
+	Method string `json:"method" validate:"required,oneof=GET POST PUT PATCH DELETE HEAD OPTIONS *"`
+	// Path is the route as registered, e.g. /api/v1/me/devices/:id, not a request URL.
+	Path string `json:"path" validate:"required,startswith=/"`
+	// Reason is shown to the clients of the route, e.g. "Exports are paused during maintenance".
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func (p *DisableRoutePayload) Validate() error {
+	return validation.NewValidator().Struct(p)
+}
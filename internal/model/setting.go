@@ -0,0 +1,40 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+)
+
+// AppSetting is the stored value of a runtime setting, see internal/lib/settings.
+type AppSetting struct {
+	Key       string          `json:"key" db:"key"`
+	Value     json.RawMessage `json:"value" db:"value"`
+	UpdatedBy *string         `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// Setting is a runtime setting as shown to operators: its declaration and current value.
+type Setting struct {
+	Key string `json:"key"`
+	// Type is "string", "int", "bool" or "json".
+	Type        string          `json:"type"`
+	Description string          `json:"description"`
+	Value       json.RawMessage `json:"value"`
+	Default     json.RawMessage `json:"default"`
+	// Overridden is false while the setting has its default.
+	Overridden bool       `json:"overridden"`
+	UpdatedBy  *string    `json:"updated_by,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// UpdateSettingPayload changes the value of a setting, it must be of the setting's type.
+type UpdateSettingPayload struct {
+	Value json.RawMessage `json:"value" validate:"required"`
+}
+
+func (p *UpdateSettingPayload) Validate() error {
+	return validation.NewValidator().Struct(p)
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+)
+
+// TenantLimits overrides the rate limit and quotas of an organization, e.g. for its plan.
+// Nil limits keep the configured default, zero means unlimited.
+type TenantLimits struct {
+	OrganizationID    string    `json:"organization_id" db:"organization_id"`
+	Plan              *string   `json:"plan" db:"plan"`
+	RequestsPerMinute *int      `json:"requests_per_minute" db:"requests_per_minute"`
+	DailyQuota        *int64    `json:"daily_quota" db:"daily_quota"`
+	MonthlyQuota      *int64    `json:"monthly_quota" db:"monthly_quota"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateTenantLimitsPayload replaces an organization's overrides, omitted limits fall back to the default.
+type UpdateTenantLimitsPayload struct {
+	Plan              *string `json:"plan" validate:"omitempty,min=1,max=64"`
+	RequestsPerMinute *int    `json:"requests_per_minute" validate:"omitempty,min=0"`
+	DailyQuota        *int64  `json:"daily_quota" validate:"omitempty,min=0"`
+	MonthlyQuota      *int64  `json:"monthly_quota" validate:"omitempty,min=0"`
+}
+
+func (p *UpdateTenantLimitsPayload) Validate() error {
+	return validation.NewValidator().Struct(p)
+}
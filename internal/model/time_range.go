@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// TimeRange restricts a query to [From, Until), either bound is optional.
+// On time-partitioned tables a bounded range lets Postgres skip every partition outside it.
+type TimeRange struct {
+	From  *time.Time `query:"from"`
+	Until *time.Time `query:"until"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// APIUsage is the requests of one client to one route in a day. Client is
+// "user:<id>", "service:<name>" or "anonymous".
+type APIUsage struct {
+	Base
+	Day        time.Time `json:"day" db:"day"`
+	Client     string    `json:"client" db:"client"`
+	Method     string    `json:"method" db:"method"`
+	Route      string    `json:"route" db:"route"`
+	Version    string    `json:"version" db:"version"`
+	Deprecated bool      `json:"deprecated" db:"deprecated"`
+	Requests   int64     `json:"requests" db:"requests"`
+	Errors     int64     `json:"errors" db:"errors"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
+
+// UsageCount is the requests of one client to one route in a day, as counted by one
+// instance since its last flush.
+type UsageCount struct {
+	Day        time.Time `json:"day"`
+	Client     string    `json:"client"`
+	Method     string    `json:"method"`
+	Route      string    `json:"route"`
+	Version    string    `json:"version"`
+	Deprecated bool      `json:"deprecated"`
+	Requests   int64     `json:"requests"`
+	Errors     int64     `json:"errors"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// DeprecatedRouteUsage is how often a client called a deprecated route over a period.
+type DeprecatedRouteUsage struct {
+	Method     string    `json:"method" db:"method"`
+	Route      string    `json:"route" db:"route"`
+	Requests   int64     `json:"requests" db:"requests"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
+
+// AnonymousUsageClient is the client of unauthenticated requests, and of anonymized users.
+const AnonymousUsageClient = "anonymous"
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// anonymizedActorID replaces the actor of audit entries written by a deleted user.
+// The entries themselves are kept, the audit trail must stay complete.
+const anonymizedActorID = "anonymized"
+
+type AuditRepository struct {
+	db *instrumentedDB
+}
+
+func NewAuditRepository(db *instrumentedDB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+// Create appends an entry to the audit log.
+func (r *AuditRepository) Create(ctx context.Context, entry *model.AuditLog) error {
+	if entry.Metadata == nil {
+		entry.Metadata = map[string]any{}
+	}
+
+	query := `
+		INSERT INTO audit_logs (actor_id, action, resource_type, resource_id, request_id, metadata)
+		VALUES (@actor_id, @action, @resource_type, @resource_id, @request_id, @metadata)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, pgx.NamedArgs{
+		"actor_id":      entry.ActorID,
+		"action":        entry.Action,
+		"resource_type": entry.ResourceType,
+		"resource_id":   entry.ResourceID,
+		"request_id":    entry.RequestID,
+		"metadata":      entry.Metadata,
+	}).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log entry for action %s: %w", entry.Action, err)
+	}
+
+	return nil
+}
+
+// ListByActor returns every audit entry written by actorID, newest first.
+func (r *AuditRepository) ListByActor(ctx context.Context, actorID string) ([]model.AuditLog, error) {
+	query := `
+		SELECT id, actor_id, action, resource_type, resource_id, request_id, metadata, created_at
+		FROM audit_logs
+		WHERE actor_id = @actor_id
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"actor_id": actorID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit logs for actor %s: %w", actorID, err)
+	}
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.AuditLog])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:audit_logs: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ListPageByActor returns one page of the audit entries written by actorID within r, newest first.
+func (r *AuditRepository) ListPageByActor(ctx context.Context, actorID string, within model.TimeRange, params model.PageParams, strategy model.CountStrategy) ([]model.AuditLog, model.PageInfo, error) {
+	args := pgx.NamedArgs{"actor_id": actorID}
+
+	return listPage[model.AuditLog](ctx, r.db, ListQuery{
+		Columns: "id, actor_id, action, resource_type, resource_id, request_id, metadata, created_at",
+		Table:   "audit_logs",
+		Where:   "actor_id = @actor_id AND " + timeRangeClause("created_at", within, args),
+		OrderBy: "created_at DESC, id DESC",
+		Args:    args,
+	}, params, strategy)
+}
+
+// auditLogListSpec is the listing contract of the admin audit log endpoint.
+var auditLogListSpec = ListSpec{
+	Query: ListQuery{
+		Columns: "id, actor_id, action, resource_type, resource_id, request_id, metadata, created_at",
+		Table:   "audit_logs",
+	},
+	Fields: map[string]ListField{
+		"id":            {Column: "id", Type: FieldUUID, Ops: []model.FilterOp{model.FilterEq}},
+		"actor_id":      {Column: "actor_id", Type: FieldString, Ops: []model.FilterOp{model.FilterEq, model.FilterIn}},
+		"action":        {Column: "action", Type: FieldString, Ops: []model.FilterOp{model.FilterEq, model.FilterNe, model.FilterIn, model.FilterPrefix}},
+		"resource_type": {Column: "resource_type", Type: FieldString, Ops: []model.FilterOp{model.FilterEq, model.FilterIn}},
+		"resource_id":   {Column: "resource_id", Type: FieldString, Ops: []model.FilterOp{model.FilterEq}},
+		"request_id":    {Column: "request_id", Type: FieldString, Ops: []model.FilterOp{model.FilterEq}},
+		// Bounding created_at also limits the scan to the matching partitions.
+		"created_at": {Column: "created_at", Type: FieldTime, Sortable: true, Ops: []model.FilterOp{model.FilterLt, model.FilterLte, model.FilterGt, model.FilterGte}},
+	},
+	DefaultSort: "-created_at",
+	IDField:     "id",
+}
+
+// List returns one page of every audit entry, using the shared listing contract.
+func (r *AuditRepository) List(ctx context.Context, params model.ListParams) ([]model.AuditLog, model.CursorInfo, error) {
+	return listByCursor[model.AuditLog](ctx, r.db, auditLogListSpec, params)
+}
+
+// configChangeListSpec is the listing contract of the admin configuration change history.
+var configChangeListSpec = ListSpec{
+	Query: ListQuery{
+		Columns: "id, actor_id, action, resource_type, resource_id, request_id, metadata, created_at",
+		Table:   "audit_logs",
+		Where:   "resource_type = @resource_type",
+		Args:    pgx.NamedArgs{"resource_type": model.AuditResourceConfig},
+	},
+	Fields: map[string]ListField{
+		"id":       {Column: "id", Type: FieldUUID, Ops: []model.FilterOp{model.FilterEq}},
+		"actor_id": {Column: "actor_id", Type: FieldString, Ops: []model.FilterOp{model.FilterEq, model.FilterIn}},
+		"action":   {Column: "action", Type: FieldString, Ops: []model.FilterOp{model.FilterEq, model.FilterIn, model.FilterPrefix}},
+		// The setting, with its scope, e.g. "tenant_limits:org_123". Prefix matches every scope.
+		"resource_id": {Column: "resource_id", Type: FieldString, Ops: []model.FilterOp{model.FilterEq, model.FilterPrefix}},
+		"request_id":  {Column: "request_id", Type: FieldString, Ops: []model.FilterOp{model.FilterEq}},
+		"created_at":  {Column: "created_at", Type: FieldTime, Sortable: true, Ops: []model.FilterOp{model.FilterLt, model.FilterLte, model.FilterGt, model.FilterGte}},
+	},
+	DefaultSort: "-created_at",
+	IDField:     "id",
+}
+
+// ListConfigChanges returns one page of the changes of runtime settings, using the shared listing contract.
+func (r *AuditRepository) ListConfigChanges(ctx context.Context, params model.ListParams) ([]model.AuditLog, model.CursorInfo, error) {
+	return listByCursor[model.AuditLog](ctx, r.db, configChangeListSpec, params)
+}
+
+// CountActionsByActor counts the actions actorID performed in [since, until), most frequent first.
+func (r *AuditRepository) CountActionsByActor(ctx context.Context, actorID string, since, until time.Time) ([]model.AuditActionCount, error) {
+	query := `
+		SELECT action, COUNT(*) AS count
+		FROM audit_logs
+		WHERE actor_id = @actor_id AND created_at >= @since AND created_at < @until
+		GROUP BY action
+		ORDER BY count DESC, action
+	`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{
+		"actor_id": actorID,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count audit log actions for actor %s: %w", actorID, err)
+	}
+
+	counts, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.AuditActionCount])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:audit_logs: %w", err)
+	}
+
+	return counts, nil
+}
+
+// OldestCreatedAt returns the creation time of the oldest audit entry, or nil if there is none.
+func (r *AuditRepository) OldestCreatedAt(ctx context.Context) (*time.Time, error) {
+	var oldest *time.Time
+
+	err := r.db.QueryRow(ctx, `SELECT MIN(created_at) FROM audit_logs`).Scan(&oldest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query oldest audit log entry: %w", err)
+	}
+
+	return oldest, nil
+}
+
+// ListRange returns every audit entry created in [from, until), oldest first.
+func (r *AuditRepository) ListRange(ctx context.Context, from, until time.Time) ([]model.AuditLog, error) {
+	query := `
+		SELECT id, actor_id, action, resource_type, resource_id, request_id, metadata, created_at
+		FROM audit_logs
+		WHERE created_at >= @from AND created_at < @until
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"from": from, "until": until})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit logs from %s until %s: %w", from, until, err)
+	}
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.AuditLog])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:audit_logs: %w", err)
+	}
+
+	return entries, nil
+}
+
+// DeleteRange deletes the audit entries created in [from, until) within tx and returns how many were deleted.
+func (r *AuditRepository) DeleteRange(ctx context.Context, tx pgx.Tx, from, until time.Time) (int64, error) {
+	query := `DELETE FROM audit_logs WHERE created_at >= @from AND created_at < @until`
+
+	tag, err := tx.Exec(ctx, query, pgx.NamedArgs{"from": from, "until": until})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete audit logs from %s until %s: %w", from, until, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// QueryExport returns the audit entries created within r, oldest first, for an export
+// to stream. The caller must close the rows.
+func (r *AuditRepository) QueryExport(ctx context.Context, within model.TimeRange) (pgx.Rows, error) {
+	args := pgx.NamedArgs{}
+	query := `
+		SELECT id, actor_id, action, resource_type, resource_id, request_id, metadata, created_at
+		FROM audit_logs
+		WHERE ` + timeRangeClause("created_at", within, args) + `
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit logs for export: %w", err)
+	}
+
+	return rows, nil
+}
+
+// CountExport counts the audit entries created within r, stopping at limit so counting
+// a huge range stays cheap.
+func (r *AuditRepository) CountExport(ctx context.Context, within model.TimeRange, limit int64) (int64, error) {
+	args := pgx.NamedArgs{"limit": limit}
+	query := `
+		SELECT count(*) FROM (
+			SELECT 1 FROM audit_logs WHERE ` + timeRangeClause("created_at", within, args) + ` LIMIT @limit
+		) AS bounded
+	`
+
+	var count int64
+	if err := r.db.QueryRow(ctx, query, args).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count audit logs for export: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *AuditRepository) UserDataSection() string {
+	return "audit_logs"
+}
+
+func (r *AuditRepository) ExportUserData(ctx context.Context, userID string) (any, error) {
+	return r.ListByActor(ctx, userID)
+}
+
+func (r *AuditRepository) AnonymizeUserData(ctx context.Context, tx pgx.Tx, userID string) error {
+	query := `UPDATE audit_logs SET actor_id = @anonymized WHERE actor_id = @actor_id`
+
+	_, err := tx.Exec(ctx, query, pgx.NamedArgs{
+		"anonymized": anonymizedActorID,
+		"actor_id":   userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to anonymize audit logs: %w", err)
+	}
+
+	return nil
+}
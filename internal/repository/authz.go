@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const policyColumns = `id, subject, action, resource, effect, description, created_at, updated_at`
+
+type AuthzRepository struct {
+	db *instrumentedDB
+}
+
+func NewAuthzRepository(db *instrumentedDB) *AuthzRepository {
+	return &AuthzRepository{
+		db: db,
+	}
+}
+
+// ListPolicies returns every authorization policy, oldest first.
+func (r *AuthzRepository) ListPolicies(ctx context.Context) ([]model.Policy, error) {
+	query := `SELECT ` + policyColumns + ` FROM authz_policies ORDER BY created_at, id`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query authz policies: %w", err)
+	}
+
+	policies, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Policy])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:authz_policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// GetPolicy returns a policy, nil if it doesn't exist.
+func (r *AuthzRepository) GetPolicy(ctx context.Context, id uuid.UUID) (*model.Policy, error) {
+	query := `SELECT ` + policyColumns + ` FROM authz_policies WHERE id = @id`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query authz policy %s: %w", id, err)
+	}
+
+	policy, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.Policy])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:authz_policies: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// CreatePolicy adds a policy. An identical policy violates the table's unique constraint.
+func (r *AuthzRepository) CreatePolicy(ctx context.Context, payload *model.CreatePolicyPayload) (*model.Policy, error) {
+	query := `
+		INSERT INTO authz_policies (subject, action, resource, effect, description)
+		VALUES (@subject, @action, @resource, @effect, @description)
+		RETURNING ` + policyColumns
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{
+		"subject":     payload.Subject,
+		"action":      payload.Action,
+		"resource":    payload.Resource,
+		"effect":      payload.Effect,
+		"description": payload.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert authz policy: %w", err)
+	}
+
+	policy, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Policy])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:authz_policies: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// DeletePolicy removes a policy and reports whether it existed.
+func (r *AuthzRepository) DeletePolicy(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `DELETE FROM authz_policies WHERE id = @id`
+
+	tag, err := r.db.Exec(ctx, query, pgx.NamedArgs{"id": id})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete authz policy %s: %w", id, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	dataExportColumns      = `id, user_id, status, archive, size_bytes, error, completed_at, expires_at, created_at, updated_at`
+	accountDeletionColumns = `id, user_id, status, scheduled_for, cancelled_at, completed_at, created_at, updated_at`
+)
+
+type ComplianceRepository struct {
+	db *instrumentedDB
+}
+
+func NewComplianceRepository(db *instrumentedDB) *ComplianceRepository {
+	return &ComplianceRepository{
+		db: db,
+	}
+}
+
+func (r *ComplianceRepository) CreateDataExport(ctx context.Context, userID string) (*model.DataExport, error) {
+	query := `INSERT INTO data_exports (user_id) VALUES (@user_id) RETURNING ` + dataExportColumns
+
+	return r.queryDataExport(ctx, query, pgx.NamedArgs{"user_id": userID})
+}
+
+func (r *ComplianceRepository) GetDataExport(ctx context.Context, exportID uuid.UUID) (*model.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE id = @id`
+
+	return r.queryDataExport(ctx, query, pgx.NamedArgs{"id": exportID})
+}
+
+// GetUserDataExport returns the export only if it belongs to userID.
+func (r *ComplianceRepository) GetUserDataExport(ctx context.Context, userID string, exportID uuid.UUID) (*model.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE id = @id AND user_id = @user_id`
+
+	return r.queryDataExport(ctx, query, pgx.NamedArgs{"id": exportID, "user_id": userID})
+}
+
+func (r *ComplianceRepository) UpdateDataExportStatus(ctx context.Context, exportID uuid.UUID, status model.DataExportStatus) error {
+	query := `UPDATE data_exports SET status = @status, updated_at = now() WHERE id = @id`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{"id": exportID, "status": status})
+	if err != nil {
+		return fmt.Errorf("failed to update status of data export %s: %w", exportID, err)
+	}
+
+	return nil
+}
+
+// CompleteDataExport stores the generated archive and marks the export as completed.
+func (r *ComplianceRepository) CompleteDataExport(ctx context.Context, exportID uuid.UUID, archive []byte, expiresAt time.Time) error {
+	query := `
+		UPDATE data_exports
+		SET status = @status, archive = @archive, size_bytes = @size_bytes, error = NULL,
+			completed_at = now(), expires_at = @expires_at, updated_at = now()
+		WHERE id = @id
+	`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{
+		"id":         exportID,
+		"status":     model.DataExportStatusCompleted,
+		"archive":    archive,
+		"size_bytes": len(archive),
+		"expires_at": expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete data export %s: %w", exportID, err)
+	}
+
+	return nil
+}
+
+func (r *ComplianceRepository) FailDataExport(ctx context.Context, exportID uuid.UUID, reason string) error {
+	query := `UPDATE data_exports SET status = @status, error = @error, updated_at = now() WHERE id = @id`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{
+		"id":     exportID,
+		"status": model.DataExportStatusFailed,
+		"error":  reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark data export %s as failed: %w", exportID, err)
+	}
+
+	return nil
+}
+
+func (r *ComplianceRepository) CreateAccountDeletion(ctx context.Context, userID string, scheduledFor time.Time) (*model.AccountDeletion, error) {
+	query := `INSERT INTO account_deletions (user_id, scheduled_for) VALUES (@user_id, @scheduled_for) RETURNING ` + accountDeletionColumns
+
+	return r.queryAccountDeletion(ctx, r.db, query, pgx.NamedArgs{
+		"user_id":       userID,
+		"scheduled_for": scheduledFor,
+	})
+}
+
+func (r *ComplianceRepository) GetAccountDeletion(ctx context.Context, deletionID uuid.UUID) (*model.AccountDeletion, error) {
+	query := `SELECT ` + accountDeletionColumns + ` FROM account_deletions WHERE id = @id`
+
+	return r.queryAccountDeletion(ctx, r.db, query, pgx.NamedArgs{"id": deletionID})
+}
+
+func (r *ComplianceRepository) GetPendingAccountDeletion(ctx context.Context, userID string) (*model.AccountDeletion, error) {
+	query := `SELECT ` + accountDeletionColumns + ` FROM account_deletions WHERE user_id = @user_id AND status = @status`
+
+	return r.queryAccountDeletion(ctx, r.db, query, pgx.NamedArgs{
+		"user_id": userID,
+		"status":  model.AccountDeletionStatusPending,
+	})
+}
+
+// CancelAccountDeletion cancels the user's pending deletion. It returns pgx.ErrNoRows
+// when there is nothing left to cancel.
+func (r *ComplianceRepository) CancelAccountDeletion(ctx context.Context, userID string) (*model.AccountDeletion, error) {
+	query := `
+		UPDATE account_deletions
+		SET status = @cancelled, cancelled_at = now(), updated_at = now()
+		WHERE user_id = @user_id AND status = @pending
+		RETURNING ` + accountDeletionColumns
+
+	return r.queryAccountDeletion(ctx, r.db, query, pgx.NamedArgs{
+		"user_id":   userID,
+		"cancelled": model.AccountDeletionStatusCancelled,
+		"pending":   model.AccountDeletionStatusPending,
+	})
+}
+
+// CompleteAccountDeletion marks a pending deletion as completed within tx.
+// It returns pgx.ErrNoRows if the deletion is no longer pending.
+func (r *ComplianceRepository) CompleteAccountDeletion(ctx context.Context, tx pgx.Tx, deletionID uuid.UUID) (*model.AccountDeletion, error) {
+	query := `
+		UPDATE account_deletions
+		SET status = @completed, completed_at = now(), updated_at = now()
+		WHERE id = @id AND status = @pending
+		RETURNING ` + accountDeletionColumns
+
+	return r.queryAccountDeletion(ctx, tx, query, pgx.NamedArgs{
+		"id":        deletionID,
+		"completed": model.AccountDeletionStatusCompleted,
+		"pending":   model.AccountDeletionStatusPending,
+	})
+}
+
+func (r *ComplianceRepository) UserDataSection() string {
+	return "data_exports"
+}
+
+// ExportUserData lists the user's previous export requests, without their archives.
+func (r *ComplianceRepository) ExportUserData(ctx context.Context, userID string) (any, error) {
+	query := `
+		SELECT id, user_id, status, NULL::bytea AS archive, size_bytes, error, completed_at, expires_at, created_at, updated_at
+		FROM data_exports
+		WHERE user_id = @user_id
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"user_id": userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data exports for user %s: %w", userID, err)
+	}
+
+	exports, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.DataExport])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:data_exports: %w", err)
+	}
+
+	return exports, nil
+}
+
+// AnonymizeUserData drops every export archive of the user, they contain personal data.
+func (r *ComplianceRepository) AnonymizeUserData(ctx context.Context, tx pgx.Tx, userID string) error {
+	_, err := tx.Exec(ctx, `DELETE FROM data_exports WHERE user_id = @user_id`, pgx.NamedArgs{"user_id": userID})
+	if err != nil {
+		return fmt.Errorf("failed to delete data exports: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ComplianceRepository) queryDataExport(ctx context.Context, query string, args pgx.NamedArgs) (*model.DataExport, error) {
+	rows, err := r.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data export: %w", err)
+	}
+
+	export, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.DataExport])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:data_exports: %w", err)
+	}
+
+	return &export, nil
+}
+
+// querier is satisfied by both the pool and a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func (r *ComplianceRepository) queryAccountDeletion(ctx context.Context, q querier, query string, args pgx.NamedArgs) (*model.AccountDeletion, error) {
+	rows, err := q.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account deletion: %w", err)
+	}
+
+	deletion, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.AccountDeletion])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:account_deletions: %w", err)
+	}
+
+	return &deletion, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// suppressionCheckTTL is how long IsSuppressed answers are cached. Suppressing an
+// address drops its cached answer, so this only bounds how stale it gets when that fails.
+const suppressionCheckTTL = 5 * time.Minute
+
+type EmailSuppressionRepository struct {
+	db *instrumentedDB
+}
+
+func NewEmailSuppressionRepository(db *instrumentedDB) *EmailSuppressionRepository {
+	return &EmailSuppressionRepository{
+		db: db,
+	}
+}
+
+// Suppress adds an address to the suppression list and reports whether it wasn't on it
+// yet. Suppressing an address twice keeps the first reason.
+func (r *EmailSuppressionRepository) Suppress(ctx context.Context, email string, reason model.EmailSuppressionReason) (bool, error) {
+	query := `
+		INSERT INTO email_suppressions (email, reason)
+		VALUES (@email, @reason)
+		ON CONFLICT (email) DO NOTHING
+	`
+
+	tag, err := r.db.Exec(ctx, query, pgx.NamedArgs{
+		"email":  normalizeEmail(email),
+		"reason": reason,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to suppress email: %w", err)
+	}
+
+	r.forgetChecks(ctx, email)
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// SuppressBatch adds addresses to the suppression list within tx, reasons[i] being the
+// reason of emails[i], and returns how many weren't on it yet. Like Suppress it keeps
+// the first reason of addresses already suppressed. tx must be the one ctx carries
+// (TxManager.WithinTx), their cached IsSuppressed answers are dropped once it commits.
+func (r *EmailSuppressionRepository) SuppressBatch(ctx context.Context, tx pgx.Tx, emails []string, reasons []model.EmailSuppressionReason) (int64, error) {
+	normalized := make([]string, len(emails))
+	for i, email := range emails {
+		normalized[i] = normalizeEmail(email)
+	}
+
+	query := `
+		INSERT INTO email_suppressions (email, reason)
+		SELECT email, reason FROM unnest(@emails::text[], @reasons::text[]) AS batch (email, reason)
+		ON CONFLICT (email) DO NOTHING
+	`
+
+	tag, err := tx.Exec(ctx, query, pgx.NamedArgs{
+		"emails":  normalized,
+		"reasons": reasons,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to suppress %d emails: %w", len(emails), err)
+	}
+
+	r.forgetChecks(ctx, emails...)
+
+	return tag.RowsAffected(), nil
+}
+
+// IsSuppressed reports whether email is on the suppression list. It is checked before
+// every email sent, so answers are cached.
+func (r *EmailSuppressionRepository) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	suppressed, err := cachedExistsBy(ctx, r.db, "email_suppressions", suppressionCond(email), suppressionCheckTTL)
+	if err != nil {
+		return false, fmt.Errorf("failed to check email suppression: %w", err)
+	}
+
+	return suppressed, nil
+}
+
+// FilterSuppressed returns which of emails are on the suppression list, normalized.
+func (r *EmailSuppressionRepository) FilterSuppressed(ctx context.Context, emails []string) (map[string]bool, error) {
+	normalized := make([]string, len(emails))
+	for i, email := range emails {
+		normalized[i] = normalizeEmail(email)
+	}
+
+	query := `SELECT email FROM email_suppressions WHERE email = ANY(@emails)`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"emails": normalized})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email suppressions: %w", err)
+	}
+
+	suppressed, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:email_suppressions: %w", err)
+	}
+
+	result := make(map[string]bool, len(suppressed))
+	for _, email := range suppressed {
+		result[email] = true
+	}
+
+	return result, nil
+}
+
+// emailSuppressionListSpec is the listing contract of the admin email suppression endpoint.
+var emailSuppressionListSpec = ListSpec{
+	Query: ListQuery{
+		Columns: "email, reason, created_at",
+		Table:   "email_suppressions",
+	},
+	Fields: map[string]ListField{
+		"email":      {Column: "email", Type: FieldString, Sortable: true, Ops: []model.FilterOp{model.FilterEq, model.FilterPrefix}},
+		"reason":     {Column: "reason", Type: FieldString, Ops: []model.FilterOp{model.FilterEq, model.FilterIn}},
+		"created_at": {Column: "created_at", Type: FieldTime, Sortable: true, Ops: []model.FilterOp{model.FilterLt, model.FilterLte, model.FilterGt, model.FilterGte}},
+	},
+	DefaultSort: "-created_at",
+	IDField:     "email",
+}
+
+// List returns one page of the suppression list, using the shared listing contract.
+// Unfiltered pages carry an estimate of the list's size, counting it exactly would
+// scan the whole table on every page.
+func (r *EmailSuppressionRepository) List(ctx context.Context, params model.ListParams) ([]model.EmailSuppression, model.CursorInfo, error) {
+	suppressions, info, err := listByCursor[model.EmailSuppression](ctx, r.db, emailSuppressionListSpec, params)
+	if err != nil || len(params.Filters) > 0 {
+		return suppressions, info, err
+	}
+
+	total, err := countEstimate(ctx, r.db, "email_suppressions")
+	if err != nil {
+		return nil, model.CursorInfo{}, err
+	}
+	info.EstimatedTotal = &total
+
+	return suppressions, info, nil
+}
+
+// forgetChecks drops the cached IsSuppressed answers of emails once the transaction ctx
+// carries commits, right away without one. Dropped before the commit, a check in
+// between would cache the old answer again. Failures are logged, the answers expire
+// after suppressionCheckTTL anyway.
+func (r *EmailSuppressionRepository) forgetChecks(ctx context.Context, emails ...string) {
+	conds := make([]Cond, len(emails))
+	for i, email := range emails {
+		conds[i] = suppressionCond(email)
+	}
+
+	forget := func(ctx context.Context) {
+		if err := forgetExists(ctx, r.db, "email_suppressions", conds...); err != nil {
+			r.db.server.Logger.Error().Err(err).Int("emails", len(emails)).Msg("failed to invalidate email suppression checks")
+		}
+	}
+	if !database.AfterCommit(ctx, forget) {
+		forget(ctx)
+	}
+}
+
+func suppressionCond(email string) Cond {
+	return Cond{"email": normalizeEmail(email)}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
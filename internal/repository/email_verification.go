@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+const emailVerificationColumns = `user_id, email, email_verified_at IS NOT NULL AS verified, token_hash, token_expires_at,
+	email_verified_at, last_sent_at, created_at, updated_at`
+
+type EmailVerificationRepository struct {
+	db *instrumentedDB
+}
+
+func NewEmailVerificationRepository(db *instrumentedDB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{
+		db: db,
+	}
+}
+
+// GetVerification returns the verification state of a user, nil if they never asked for one.
+func (r *EmailVerificationRepository) GetVerification(ctx context.Context, userID string) (*model.EmailVerification, error) {
+	query := `SELECT ` + emailVerificationColumns + ` FROM email_verifications WHERE user_id = @user_id`
+
+	return collectVerification(ctx, r.db, query, pgx.NamedArgs{"user_id": userID}, "failed to query email verification of user "+userID)
+}
+
+// GetVerificationByToken returns the verification a token was issued for, nil if no
+// pending verification has it.
+func (r *EmailVerificationRepository) GetVerificationByToken(ctx context.Context, tokenHash string) (*model.EmailVerification, error) {
+	query := `SELECT ` + emailVerificationColumns + ` FROM email_verifications WHERE token_hash = @token_hash`
+
+	return collectVerification(ctx, r.db, query, pgx.NamedArgs{"token_hash": tokenHash}, "failed to query email verification by token")
+}
+
+// IssueToken stores a new token for the verification of email, replacing the previous
+// one. Changing the address drops its verification, the same address stays verified.
+func (r *EmailVerificationRepository) IssueToken(ctx context.Context, userID, email, tokenHash string, expiresAt time.Time) (*model.EmailVerification, error) {
+	query := `
+		INSERT INTO email_verifications (user_id, email, token_hash, token_expires_at, last_sent_at)
+		VALUES (@user_id, @email, @token_hash, @token_expires_at, now())
+		ON CONFLICT (user_id) DO UPDATE SET
+			email = EXCLUDED.email,
+			token_hash = EXCLUDED.token_hash,
+			token_expires_at = EXCLUDED.token_expires_at,
+			last_sent_at = EXCLUDED.last_sent_at,
+			email_verified_at = CASE WHEN email_verifications.email = EXCLUDED.email THEN email_verifications.email_verified_at END,
+			updated_at = now()
+		RETURNING ` + emailVerificationColumns
+
+	return collectVerification(ctx, r.db, query, pgx.NamedArgs{
+		"user_id":          userID,
+		"email":            email,
+		"token_hash":       tokenHash,
+		"token_expires_at": expiresAt,
+	}, "failed to issue email verification token for user "+userID)
+}
+
+// MarkVerified verifies the address of userID if tokenHash is still its pending token,
+// which it clears. It returns nil if the token was replaced or used in the meantime.
+func (r *EmailVerificationRepository) MarkVerified(ctx context.Context, userID, tokenHash string) (*model.EmailVerification, error) {
+	query := `
+		UPDATE email_verifications
+		SET email_verified_at = now(), token_hash = NULL, token_expires_at = NULL, updated_at = now()
+		WHERE user_id = @user_id AND token_hash = @token_hash
+		RETURNING ` + emailVerificationColumns
+
+	return collectVerification(ctx, r.db, query, pgx.NamedArgs{
+		"user_id":    userID,
+		"token_hash": tokenHash,
+	}, "failed to verify email of user "+userID)
+}
+
+// IsVerified reports whether the email of userID is verified.
+func (r *EmailVerificationRepository) IsVerified(ctx context.Context, userID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM email_verifications WHERE user_id = @user_id AND email_verified_at IS NOT NULL)`
+
+	var verified bool
+	if err := r.db.QueryRow(ctx, query, pgx.NamedArgs{"user_id": userID}).Scan(&verified); err != nil {
+		return false, fmt.Errorf("failed to check email verification of user %s: %w", userID, err)
+	}
+
+	return verified, nil
+}
+
+// collectVerification runs a query returning at most one verification. It isn't a method,
+// so database calls are labelled with the repository method calling it.
+func collectVerification(ctx context.Context, db *instrumentedDB, query string, args pgx.NamedArgs, failure string) (*model.EmailVerification, error) {
+	rows, err := db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", failure, err)
+	}
+
+	verification, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.EmailVerification])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:email_verifications: %w", err)
+	}
+
+	return &verification, nil
+}
+
+func (r *EmailVerificationRepository) UserDataSection() string {
+	return "email_verification"
+}
+
+func (r *EmailVerificationRepository) ExportUserData(ctx context.Context, userID string) (any, error) {
+	return r.GetVerification(ctx, userID)
+}
+
+// AnonymizeUserData deletes the user's verification, its address identifies them.
+func (r *EmailVerificationRepository) AnonymizeUserData(ctx context.Context, tx pgx.Tx, userID string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM email_verifications WHERE user_id = @user_id`, pgx.NamedArgs{"user_id": userID}); err != nil {
+		return fmt.Errorf("failed to delete email verification: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/cache"
+	"github.com/jackc/pgx/v5"
+)
+
+// rowEstimateTTL is how long countEstimate caches an estimate, pg_class is only updated
+// by vacuum and analyze anyway.
+const rowEstimateTTL = time.Minute
+
+// Cond matches the rows whose columns equal the values, all of them.
+type Cond map[string]any
+
+// where returns the WHERE clause of c and its arguments. Columns are sorted so a
+// condition always renders the same SQL, which pgx prepares once per connection.
+func (c Cond) where() (string, pgx.NamedArgs) {
+	if len(c) == 0 {
+		return "", nil
+	}
+
+	columns := slices.Sorted(maps.Keys(c))
+	clauses := make([]string, len(columns))
+	args := make(pgx.NamedArgs, len(columns))
+	for i, column := range columns {
+		arg := fmt.Sprintf("c%d", i)
+		clauses[i] = pgx.Identifier{column}.Sanitize() + " = @" + arg
+		args[arg] = c[column]
+	}
+
+	return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// key returns the cache key of c's check on table. Values are hashed, they may be
+// emails or other personal data.
+func (c Cond) key(table string) (string, error) {
+	// Maps are encoded with sorted keys
+	encoded, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode condition on %s: %w", table, err)
+	}
+
+	sum := sha256.Sum256(encoded)
+	return "exists:" + table + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// existsBy reports whether a row of table matches cond, without loading it. Like
+// listPage it isn't a method, so database calls are labelled with the repository
+// method calling it.
+func existsBy(ctx context.Context, db *instrumentedDB, table string, cond Cond) (bool, error) {
+	where, args := cond.where()
+	query := "SELECT EXISTS (SELECT 1 FROM " + sanitizeTable(table) + where + ")"
+
+	var exists bool
+	if err := db.QueryRow(ctx, query, args).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check rows of %s: %w", table, err)
+	}
+
+	return exists, nil
+}
+
+// cachedExistsBy is existsBy for hot checks, its answer is cached for ttl on every
+// instance. Writes that change the answer must call forgetExists.
+func cachedExistsBy(ctx context.Context, db *instrumentedDB, table string, cond Cond, ttl time.Duration) (bool, error) {
+	key, err := cond.key(table)
+	if err != nil {
+		return false, err
+	}
+
+	return fetchOrLoad(ctx, db, key, ttl, func(ctx context.Context) (bool, error) {
+		return existsBy(ctx, db, table, cond)
+	})
+}
+
+// forgetExists drops the cached answers of cachedExistsBy for conds on table.
+func forgetExists(ctx context.Context, db *instrumentedDB, table string, conds ...Cond) error {
+	cacheKeys := make([]string, len(conds))
+	for i, cond := range conds {
+		key, err := cond.key(table)
+		if err != nil {
+			return err
+		}
+		cacheKeys[i] = key
+	}
+
+	if err := db.server.Cache.Delete(ctx, cacheKeys...); err != nil {
+		return fmt.Errorf("failed to invalidate checks of %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// countEstimate returns about how many rows table has, from the statistics Postgres
+// keeps rather than a scan, cached for rowEstimateTTL. Use it for sizes shown to
+// people and thresholds, never where exactness matters.
+func countEstimate(ctx context.Context, db *instrumentedDB, table string) (int64, error) {
+	return fetchOrLoad(ctx, db, "rows:"+table, rowEstimateTTL, func(ctx context.Context) (int64, error) {
+		return estimateTableRows(ctx, db, ListQuery{Table: table})
+	})
+}
+
+// fetchOrLoad is cache.Fetch treating cache errors as misses. The server runs without
+// Redis, so while it is down the answers come from Postgres, as they did before they
+// were cached, and the failures are only logged.
+func fetchOrLoad[T any](ctx context.Context, db *instrumentedDB, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+
+	cached, err := db.server.Cache.Get(ctx, key)
+	if err == nil && json.Unmarshal(cached, &value) == nil {
+		return value, nil
+	}
+	if err != nil && !errors.Is(err, cache.ErrMiss) {
+		db.server.Logger.Warn().Err(err).Str("key", key).Msg("cache unavailable, loading from the database")
+	}
+
+	value, err = load(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	if encoded, err := json.Marshal(value); err == nil {
+		if err := db.server.Cache.Set(ctx, key, encoded, ttl); err != nil {
+			db.server.Logger.Warn().Err(err).Str("key", key).Msg("failed to cache database answer")
+		}
+	}
+
+	return value, nil
+}
+
+// sanitizeTable quotes table, which may be qualified by its schema.
+func sanitizeTable(table string) string {
+	return pgx.Identifier(strings.Split(table, ".")).Sanitize()
+}
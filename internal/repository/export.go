@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const exportColumns = `id, requested_by, dataset, format, columns, language, range_from, range_until, status, content,
+	row_count, size_bytes, error, completed_at, expires_at, created_at, updated_at`
+
+type ExportRepository struct {
+	db *instrumentedDB
+}
+
+func NewExportRepository(db *instrumentedDB) *ExportRepository {
+	return &ExportRepository{
+		db: db,
+	}
+}
+
+func (r *ExportRepository) Create(ctx context.Context, export *model.Export) (*model.Export, error) {
+	query := `
+		INSERT INTO exports (requested_by, dataset, format, columns, language, range_from, range_until)
+		VALUES (@requested_by, @dataset, @format, @columns, @language, @range_from, @range_until)
+		RETURNING ` + exportColumns
+
+	return r.queryExport(ctx, query, pgx.NamedArgs{
+		"requested_by": export.RequestedBy,
+		"dataset":      export.Dataset,
+		"format":       export.Format,
+		"columns":      export.Columns,
+		"language":     export.Language,
+		"range_from":   export.RangeFrom,
+		"range_until":  export.RangeUntil,
+	})
+}
+
+func (r *ExportRepository) Get(ctx context.Context, exportID uuid.UUID) (*model.Export, error) {
+	query := `SELECT ` + exportColumns + ` FROM exports WHERE id = @id`
+
+	return r.queryExport(ctx, query, pgx.NamedArgs{"id": exportID})
+}
+
+// GetRequested returns the export only if requestedBy requested it.
+func (r *ExportRepository) GetRequested(ctx context.Context, requestedBy string, exportID uuid.UUID) (*model.Export, error) {
+	query := `SELECT ` + exportColumns + ` FROM exports WHERE id = @id AND requested_by = @requested_by`
+
+	return r.queryExport(ctx, query, pgx.NamedArgs{"id": exportID, "requested_by": requestedBy})
+}
+
+func (r *ExportRepository) UpdateStatus(ctx context.Context, exportID uuid.UUID, status model.DataExportStatus) error {
+	query := `UPDATE exports SET status = @status, updated_at = now() WHERE id = @id`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{"id": exportID, "status": status})
+	if err != nil {
+		return fmt.Errorf("failed to update status of export %s: %w", exportID, err)
+	}
+
+	return nil
+}
+
+// Complete stores the generated file and marks the export as completed.
+func (r *ExportRepository) Complete(ctx context.Context, exportID uuid.UUID, content []byte, rowCount int64, expiresAt time.Time) error {
+	query := `
+		UPDATE exports
+		SET status = @status, content = @content, row_count = @row_count, size_bytes = @size_bytes, error = NULL,
+			completed_at = now(), expires_at = @expires_at, updated_at = now()
+		WHERE id = @id
+	`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{
+		"id":         exportID,
+		"status":     model.DataExportStatusCompleted,
+		"content":    content,
+		"row_count":  rowCount,
+		"size_bytes": len(content),
+		"expires_at": expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete export %s: %w", exportID, err)
+	}
+
+	return nil
+}
+
+func (r *ExportRepository) Fail(ctx context.Context, exportID uuid.UUID, reason string) error {
+	query := `UPDATE exports SET status = @status, error = @error, updated_at = now() WHERE id = @id`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{
+		"id":     exportID,
+		"status": model.DataExportStatusFailed,
+		"error":  reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark export %s as failed: %w", exportID, err)
+	}
+
+	return nil
+}
+
+func (r *ExportRepository) queryExport(ctx context.Context, query string, args pgx.NamedArgs) (*model.Export, error) {
+	rows, err := r.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query export: %w", err)
+	}
+
+	export, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Export])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:exports: %w", err)
+	}
+
+	return &export, nil
+}
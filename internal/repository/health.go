@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// HealthProbeRepository stores the probe rows of the deep health check.
+type HealthProbeRepository struct {
+	db *instrumentedDB
+}
+
+func NewHealthProbeRepository(db *instrumentedDB) *HealthProbeRepository {
+	return &HealthProbeRepository{
+		db: db,
+	}
+}
+
+func (r *HealthProbeRepository) Create(ctx context.Context) (*model.HealthProbe, error) {
+	rows, err := r.db.Query(ctx, `INSERT INTO health_probes DEFAULT VALUES RETURNING id, created_at, processed_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert health probe: %w", err)
+	}
+
+	probe, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.HealthProbe])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:health_probes: %w", err)
+	}
+
+	return &probe, nil
+}
+
+func (r *HealthProbeRepository) Get(ctx context.Context, id uuid.UUID) (*model.HealthProbe, error) {
+	query := `SELECT id, created_at, processed_at FROM health_probes WHERE id = @id`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query health probe: %w", err)
+	}
+
+	probe, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.HealthProbe])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:health_probes: %w", err)
+	}
+
+	return &probe, nil
+}
+
+// MarkProcessed records that the probe job of a probe ran.
+func (r *HealthProbeRepository) MarkProcessed(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE health_probes SET processed_at = now() WHERE id = @id`
+
+	if _, err := r.db.Exec(ctx, query, pgx.NamedArgs{"id": id}); err != nil {
+		return fmt.Errorf("failed to mark health probe processed: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a probe, along with the probes of checks that were interrupted
+// before cleaning up after themselves.
+func (r *HealthProbeRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM health_probes WHERE id = @id OR created_at < now() - interval '1 hour'`
+
+	if _, err := r.db.Exec(ctx, query, pgx.NamedArgs{"id": id}); err != nil {
+		return fmt.Errorf("failed to delete health probe: %w", err)
+	}
+
+	return nil
+}
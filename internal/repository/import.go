@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const importColumns = `id, requested_by, dataset, filename, status, total_rows, valid_rows, processed_rows,
+	error_count, errors, error, completed_at, created_at, updated_at`
+
+type ImportRepository struct {
+	db *instrumentedDB
+}
+
+func NewImportRepository(db *instrumentedDB) *ImportRepository {
+	return &ImportRepository{
+		db: db,
+	}
+}
+
+// Create records an upload whose rows are about to be validated and staged.
+func (r *ImportRepository) Create(ctx context.Context, requestedBy, dataset, filename string) (*model.Import, error) {
+	query := `
+		INSERT INTO imports (requested_by, dataset, filename)
+		VALUES (@requested_by, @dataset, @filename)
+		RETURNING ` + importColumns
+
+	return r.queryImport(ctx, query, pgx.NamedArgs{
+		"requested_by": requestedBy,
+		"dataset":      dataset,
+		"filename":     filename,
+	})
+}
+
+func (r *ImportRepository) Get(ctx context.Context, importID uuid.UUID) (*model.Import, error) {
+	query := `SELECT ` + importColumns + ` FROM imports WHERE id = @id`
+
+	return r.queryImport(ctx, query, pgx.NamedArgs{"id": importID})
+}
+
+// GetRequested returns the import only if requestedBy uploaded it.
+func (r *ImportRepository) GetRequested(ctx context.Context, requestedBy string, importID uuid.UUID) (*model.Import, error) {
+	query := `SELECT ` + importColumns + ` FROM imports WHERE id = @id AND requested_by = @requested_by`
+
+	return r.queryImport(ctx, query, pgx.NamedArgs{"id": importID, "requested_by": requestedBy})
+}
+
+// StageRows stores validated rows of an import until the import job commits them.
+func (r *ImportRepository) StageRows(ctx context.Context, importID uuid.UUID, rows []model.StagedImportRow) error {
+	query := `
+		INSERT INTO import_rows (import_id, line, data)
+		SELECT @import_id, line, data FROM jsonb_to_recordset(@rows) AS staged (line INTEGER, data JSONB)
+	`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{"import_id": importID, "rows": rows})
+	if err != nil {
+		return fmt.Errorf("failed to stage %d rows of import %s: %w", len(rows), importID, err)
+	}
+
+	return nil
+}
+
+// FinishValidation stores the validation report of an import and moves it to status,
+// pending to be committed or invalid.
+func (r *ImportRepository) FinishValidation(ctx context.Context, imp *model.Import, status model.ImportStatus) (*model.Import, error) {
+	query := `
+		UPDATE imports
+		SET status = @status, total_rows = @total_rows, valid_rows = @valid_rows,
+			error_count = @error_count, errors = @errors, updated_at = now()
+		WHERE id = @id
+		RETURNING ` + importColumns
+
+	return r.queryImport(ctx, query, pgx.NamedArgs{
+		"id":          imp.ID,
+		"status":      status,
+		"total_rows":  imp.TotalRows,
+		"valid_rows":  imp.ValidRows,
+		"error_count": imp.ErrorCount,
+		"errors":      imp.Errors,
+	})
+}
+
+func (r *ImportRepository) UpdateStatus(ctx context.Context, importID uuid.UUID, status model.ImportStatus) error {
+	query := `UPDATE imports SET status = @status, updated_at = now() WHERE id = @id`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{"id": importID, "status": status})
+	if err != nil {
+		return fmt.Errorf("failed to update status of import %s: %w", importID, err)
+	}
+
+	return nil
+}
+
+// NextStagedRows returns the next rows of an import to commit, in file order.
+func (r *ImportRepository) NextStagedRows(ctx context.Context, importID uuid.UUID, limit int) ([]model.StagedImportRow, error) {
+	query := `
+		SELECT line, data FROM import_rows
+		WHERE import_id = @import_id
+		ORDER BY line
+		LIMIT @limit
+	`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"import_id": importID, "limit": limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staged rows of import %s: %w", importID, err)
+	}
+
+	staged, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.StagedImportRow])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:import_rows: %w", err)
+	}
+
+	return staged, nil
+}
+
+// MarkBatchCommitted removes the committed rows up to throughLine from the staging table
+// and counts them as processed, within the transaction that committed them, so a retried
+// job resumes after the last committed batch.
+func (r *ImportRepository) MarkBatchCommitted(ctx context.Context, tx pgx.Tx, importID uuid.UUID, throughLine, count int) error {
+	_, err := tx.Exec(ctx, `DELETE FROM import_rows WHERE import_id = @import_id AND line <= @line`, pgx.NamedArgs{
+		"import_id": importID,
+		"line":      throughLine,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete committed rows of import %s: %w", importID, err)
+	}
+
+	query := `UPDATE imports SET processed_rows = processed_rows + @count, updated_at = now() WHERE id = @id`
+
+	_, err = tx.Exec(ctx, query, pgx.NamedArgs{"id": importID, "count": count})
+	if err != nil {
+		return fmt.Errorf("failed to update progress of import %s: %w", importID, err)
+	}
+
+	return nil
+}
+
+func (r *ImportRepository) Complete(ctx context.Context, importID uuid.UUID) error {
+	query := `UPDATE imports SET status = @status, error = NULL, completed_at = now(), updated_at = now() WHERE id = @id`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{"id": importID, "status": model.ImportStatusCompleted})
+	if err != nil {
+		return fmt.Errorf("failed to complete import %s: %w", importID, err)
+	}
+
+	return nil
+}
+
+// Fail marks an import as failed and drops its remaining staged rows.
+func (r *ImportRepository) Fail(ctx context.Context, importID uuid.UUID, reason string) error {
+	query := `UPDATE imports SET status = @status, error = @error, updated_at = now() WHERE id = @id`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{
+		"id":     importID,
+		"status": model.ImportStatusFailed,
+		"error":  reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark import %s as failed: %w", importID, err)
+	}
+
+	return r.DeleteStagedRows(ctx, importID)
+}
+
+// DeleteStagedRows drops every staged row of an import, e.g. once it turned out invalid.
+func (r *ImportRepository) DeleteStagedRows(ctx context.Context, importID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM import_rows WHERE import_id = @import_id`, pgx.NamedArgs{"import_id": importID})
+	if err != nil {
+		return fmt.Errorf("failed to delete staged rows of import %s: %w", importID, err)
+	}
+
+	return nil
+}
+
+func (r *ImportRepository) queryImport(ctx context.Context, query string, args pgx.NamedArgs) (*model.Import, error) {
+	rows, err := r.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query import: %w", err)
+	}
+
+	imp, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Import])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:imports: %w", err)
+	}
+
+	return &imp, nil
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// instrumentedDB wraps the connection pool used by every repository. Each call is timed,
+// counted as an error if it fails and traced as a segment, labeled with the repository
+// and method it was made from (e.g. Repository/Audit/ListPage), which is found on the
+// call stack. NewRepositories hands it to every repository, so new methods are covered
+// without instrumenting them by hand. Calls made directly on transactions are not covered.
+//
+// Calls whose context carries a transaction (TxManager.WithinTx, database.ContextWithTx)
+// run on it instead of the pool, so repository methods join the caller's transaction.
+//
+// Every call takes the caller's context, so request deadlines and cancellation reach
+// pgx. Calls made with a context that can never be cancelled (context.Background) are
+// logged once per method, such a query only ends at the pool's statement_timeout.
+type instrumentedDB struct {
+	server  *server.Server
+	labels  sync.Map // program counter of the calling method -> label
+	unbound sync.Map // labels of methods already reported for an uncancellable context
+}
+
+func newInstrumentedDB(s *server.Server) *instrumentedDB {
+	return &instrumentedDB{
+		server: s,
+	}
+}
+
+// dbConn is what calls run on: the pool, or the transaction carried by ctx.
+type dbConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, batch *pgx.Batch) pgx.BatchResults
+}
+
+func (db *instrumentedDB) conn(ctx context.Context) dbConn {
+	if tx, ok := database.TxFromContext(ctx); ok {
+		return tx
+	}
+	return db.server.DB.Pool
+}
+
+func (db *instrumentedDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	call := db.start(ctx)
+	tag, err := db.conn(ctx).Exec(ctx, sql, args...)
+	call.end(err)
+	return tag, err
+}
+
+// Query returns rows that finish the measurement once closed, so the time spent
+// reading them counts too.
+func (db *instrumentedDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	call := db.start(ctx)
+	rows, err := db.conn(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		call.end(err)
+		return nil, err
+	}
+	return &instrumentedRows{Rows: rows, call: call}, nil
+}
+
+// QueryRow returns a row that finishes the measurement once scanned.
+func (db *instrumentedDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	call := db.start(ctx)
+	return &instrumentedRow{Row: db.conn(ctx).QueryRow(ctx, sql, args...), call: call}
+}
+
+func (db *instrumentedDB) SendBatch(ctx context.Context, batch *pgx.Batch) pgx.BatchResults {
+	call := db.start(ctx)
+	return &instrumentedBatch{BatchResults: db.conn(ctx).SendBatch(ctx, batch), call: call}
+}
+
+// call is one measured database call.
+type call struct {
+	db      *instrumentedDB
+	label   string
+	start   time.Time
+	segment *newrelic.Segment
+	once    sync.Once
+}
+
+func (db *instrumentedDB) start(ctx context.Context) *call {
+	label := db.callerLabel()
+
+	if ctx.Done() == nil {
+		if _, reported := db.unbound.LoadOrStore(label, true); !reported {
+			db.server.Logger.Warn().Str("method", label).Msg("database call with a context that can't be cancelled, pass the caller's context on")
+		}
+	}
+
+	return &call{
+		db:      db,
+		label:   label,
+		start:   time.Now(),
+		segment: newrelic.FromContext(ctx).StartSegment(label),
+	}
+}
+
+// end records the call once, no matter how often rows are closed or rows scanned.
+func (c *call) end(err error) {
+	c.once.Do(func() {
+		c.segment.End()
+
+		if c.db.server.LoggerService == nil || c.db.server.LoggerService.GetNewRelicApp() == nil {
+			return
+		}
+
+		app := c.db.server.LoggerService.GetNewRelicApp()
+		app.RecordCustomMetric("Custom/"+c.label, time.Since(c.start).Seconds())
+
+		// No rows is an expected outcome, not a failure of the database.
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			app.RecordCustomMetric("Custom/"+c.label+"/Errors", 1)
+		}
+	})
+}
+
+// callerLabel walks up the stack to the first repository method, skipping shared
+// helpers such as listPage, and returns its label.
+func (db *instrumentedDB) callerLabel() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+
+		if label, ok := db.labels.Load(frame.PC); ok {
+			return label.(string)
+		}
+
+		if label, ok := repositoryLabel(frame.Function); ok {
+			db.labels.Store(frame.PC, label)
+			return label
+		}
+
+		if !more {
+			return "Repository/Unknown"
+		}
+	}
+}
+
+// repositoryLabel turns ".../repository.(*AuditRepository).ListPage.func1" into
+// "Repository/Audit/ListPage".
+func repositoryLabel(function string) (string, bool) {
+	_, method, ok := strings.Cut(function, "/repository.(*")
+	if !ok {
+		return "", false
+	}
+
+	repository, method, ok := strings.Cut(method, ").")
+	if !ok || repository == "instrumentedDB" {
+		// The wrapper's own methods are always on the stack, not the caller's
+		return "", false
+	}
+
+	method, _, _ = strings.Cut(method, ".")
+	return "Repository/" + strings.TrimSuffix(repository, "Repository") + "/" + method, true
+}
+
+type instrumentedRows struct {
+	pgx.Rows
+	call *call
+}
+
+func (r *instrumentedRows) Close() {
+	r.Rows.Close()
+	r.call.end(r.Rows.Err())
+}
+
+type instrumentedRow struct {
+	pgx.Row
+	call *call
+}
+
+func (r *instrumentedRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	r.call.end(err)
+	return err
+}
+
+type instrumentedBatch struct {
+	pgx.BatchResults
+	call *call
+}
+
+func (b *instrumentedBatch) Close() error {
+	err := b.BatchResults.Close()
+	b.call.end(err)
+	return err
+}
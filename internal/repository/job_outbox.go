@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// JobOutboxRepository stores tasks the job service couldn't enqueue because their
+// queue was full. It implements job.Outbox.
+type JobOutboxRepository struct {
+	db *instrumentedDB
+}
+
+func NewJobOutboxRepository(db *instrumentedDB) *JobOutboxRepository {
+	return &JobOutboxRepository{
+		db: db,
+	}
+}
+
+func (r *JobOutboxRepository) SaveTask(ctx context.Context, taskType string, payload []byte, queue string, options []byte) error {
+	query := `
+		INSERT INTO job_outbox (task_type, payload, queue, options)
+		VALUES (@task_type, @payload, @queue, @options)
+	`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{
+		"task_type": taskType,
+		"payload":   payload,
+		"queue":     queue,
+		"options":   json.RawMessage(options),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save outbox task: %w", err)
+	}
+
+	return nil
+}
+
+// LockOldest returns up to limit outboxed tasks, oldest first, locked within tx.
+// Rows locked by another relay are skipped, so instances never relay a task twice.
+func (r *JobOutboxRepository) LockOldest(ctx context.Context, tx pgx.Tx, limit int) ([]model.OutboxTask, error) {
+	query := `
+		SELECT id, task_type, payload, queue, options, created_at
+		FROM job_outbox
+		ORDER BY created_at
+		LIMIT @limit
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.Query(ctx, query, pgx.NamedArgs{"limit": limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox tasks: %w", err)
+	}
+
+	tasks, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.OutboxTask])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:job_outbox: %w", err)
+	}
+
+	return tasks, nil
+}
+
+func (r *JobOutboxRepository) Delete(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	query := `DELETE FROM job_outbox WHERE id = @id`
+
+	if _, err := tx.Exec(ctx, query, pgx.NamedArgs{"id": id}); err != nil {
+		return fmt.Errorf("failed to delete outbox task %s: %w", id, err)
+	}
+
+	return nil
+}
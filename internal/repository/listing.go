@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type FieldType int
+
+const (
+	FieldString FieldType = iota
+	FieldInt
+	FieldTime
+	FieldUUID
+	FieldBool
+)
+
+// ListField is a field the listing contract may sort or filter on. The field's name
+// (the key in ListSpec.Fields) must match the JSON name of the item, cursors are
+// built from the JSON representation of the last item of a page.
+type ListField struct {
+	Column string
+	Type   FieldType
+	// Sortable fields must be NOT NULL, keyset pagination can't order past NULLs.
+	Sortable bool
+	Ops      []model.FilterOp
+}
+
+// ListSpec declares what a listing endpoint allows on top of its base query.
+type ListSpec struct {
+	Query  ListQuery
+	Fields map[string]ListField
+	// DefaultSort is used without a sort parameter, e.g. "-created_at".
+	DefaultSort string
+	// IDField names a unique field, used as tie-breaker so cursors never skip or repeat rows.
+	IDField string
+}
+
+// listCursor is what a cursor encodes: the sort it was made for and the position of the last item.
+type listCursor struct {
+	Sort  string `json:"s"`
+	Value any    `json:"v"`
+	ID    any    `json:"id"`
+}
+
+// listByCursor runs spec's query with the sort, filters and cursor of params.
+// Invalid parameters are rejected with a 400 listing every offending field.
+func listByCursor[T any](ctx context.Context, db querier, spec ListSpec, params model.ListParams) ([]T, model.CursorInfo, error) {
+	params = params.Normalize()
+	if params.Sort == "" {
+		params.Sort = spec.DefaultSort
+	}
+
+	sortName, descending := strings.CutPrefix(params.Sort, "-")
+	var fieldErrors []errs.FieldError
+
+	sortField, ok := spec.Fields[sortName]
+	if !ok || !sortField.Sortable {
+		fieldErrors = append(fieldErrors, errs.FieldError{Field: "sort", Error: "must be one of: " + strings.Join(spec.sortable(), ", ")})
+	}
+
+	args := pgx.NamedArgs{}
+	for name, value := range spec.Query.Args {
+		args[name] = value
+	}
+
+	var conditions []string
+	if spec.Query.Where != "" {
+		conditions = append(conditions, "("+spec.Query.Where+")")
+	}
+
+	for i, filter := range params.Filters {
+		condition, err := spec.filterCondition(filter, fmt.Sprintf("filter_%d", i), args)
+		if err != nil {
+			fieldErrors = append(fieldErrors, errs.FieldError{Field: fmt.Sprintf("filter[%s][%s]", filter.Field, filter.Op), Error: err.Error()})
+			continue
+		}
+		conditions = append(conditions, condition)
+	}
+
+	idField := spec.Fields[spec.IDField]
+	direction, comparison := "ASC", ">"
+	if descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if params.Cursor != "" && len(fieldErrors) == 0 {
+		cursor, err := decodeCursor(params.Cursor, params.Sort, sortField, idField)
+		if err != nil {
+			fieldErrors = append(fieldErrors, errs.FieldError{Field: "cursor", Error: err.Error()})
+		} else {
+			args["cursor_value"] = cursor.Value
+			args["cursor_id"] = cursor.ID
+			conditions = append(conditions, fmt.Sprintf("(%s, %s) %s (@cursor_value, @cursor_id)", sortField.Column, idField.Column, comparison))
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, model.CursorInfo{}, errs.BadRequestError("Invalid list parameters", false, nil, fieldErrors, nil)
+	}
+
+	query := "SELECT " + spec.Query.Columns + " FROM " + spec.Query.Table
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT @page_limit", sortField.Column, direction, idField.Column, direction)
+	args["page_limit"] = params.Limit + 1
+
+	rows, err := db.Query(ctx, query, args)
+	if err != nil {
+		return nil, model.CursorInfo{}, fmt.Errorf("failed to query list of %s: %w", spec.Query.Table, err)
+	}
+
+	items, err := pgx.CollectRows(rows, pgx.RowToStructByName[T])
+	if err != nil {
+		return nil, model.CursorInfo{}, fmt.Errorf("failed to collect rows from table:%s: %w", spec.Query.Table, err)
+	}
+
+	info := model.CursorInfo{
+		Limit:   params.Limit,
+		Sort:    params.Sort,
+		HasNext: len(items) > params.Limit,
+	}
+
+	if info.HasNext {
+		items = items[:params.Limit]
+
+		next, err := encodeCursor(items[len(items)-1], params.Sort, sortName, spec.IDField)
+		if err != nil {
+			return nil, model.CursorInfo{}, err
+		}
+		info.NextCursor = &next
+	}
+
+	return items, info, nil
+}
+
+func (spec ListSpec) sortable() []string {
+	var names []string
+	for name, field := range spec.Fields {
+		if field.Sortable {
+			names = append(names, name, "-"+name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// filterCondition validates filter against the spec and returns its SQL condition,
+// with the converted value added to args under param.
+func (spec ListSpec) filterCondition(filter model.Filter, param string, args pgx.NamedArgs) (string, error) {
+	field, ok := spec.Fields[filter.Field]
+	if !ok || len(field.Ops) == 0 {
+		return "", fmt.Errorf("filtering on %s is not supported", filter.Field)
+	}
+	if !slices.Contains(field.Ops, filter.Op) {
+		ops := make([]string, len(field.Ops))
+		for i, op := range field.Ops {
+			ops[i] = string(op)
+		}
+		return "", fmt.Errorf("operator must be one of: %s", strings.Join(ops, ", "))
+	}
+
+	switch filter.Op {
+	case model.FilterIn:
+		values, err := convertFieldValues(field.Type, strings.Split(filter.Value, ","))
+		if err != nil {
+			return "", err
+		}
+		args[param] = values
+		return fmt.Sprintf("%s = ANY(@%s)", field.Column, param), nil
+
+	case model.FilterPrefix:
+		if field.Type != FieldString {
+			return "", fmt.Errorf("prefix only applies to text fields")
+		}
+		args[param] = escapeLike(filter.Value) + "%"
+		return fmt.Sprintf("%s LIKE @%s", field.Column, param), nil
+	}
+
+	value, err := convertFieldValue(field.Type, filter.Value)
+	if err != nil {
+		return "", err
+	}
+	args[param] = value
+
+	operators := map[model.FilterOp]string{
+		model.FilterEq:  "=",
+		model.FilterNe:  "<>",
+		model.FilterLt:  "<",
+		model.FilterLte: "<=",
+		model.FilterGt:  ">",
+		model.FilterGte: ">=",
+	}
+
+	return fmt.Sprintf("%s %s @%s", field.Column, operators[filter.Op], param), nil
+}
+
+// convertFieldValue parses a query string value into the Go type of the field.
+func convertFieldValue(fieldType FieldType, raw string) (any, error) {
+	switch fieldType {
+	case FieldInt:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return value, nil
+	case FieldTime:
+		value, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be an RFC 3339 timestamp")
+		}
+		return value, nil
+	case FieldUUID:
+		value, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a valid UUID")
+		}
+		return value, nil
+	case FieldBool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return value, nil
+	default:
+		return raw, nil
+	}
+}
+
+// convertFieldValues converts every value, returning a typed slice pgx can encode as an array.
+func convertFieldValues(fieldType FieldType, raw []string) (any, error) {
+	switch fieldType {
+	case FieldInt:
+		return convertEach[int64](fieldType, raw)
+	case FieldTime:
+		return convertEach[time.Time](fieldType, raw)
+	case FieldUUID:
+		return convertEach[uuid.UUID](fieldType, raw)
+	case FieldBool:
+		return convertEach[bool](fieldType, raw)
+	default:
+		return raw, nil
+	}
+}
+
+func convertEach[V any](fieldType FieldType, raw []string) ([]V, error) {
+	values := make([]V, len(raw))
+	for i, r := range raw {
+		value, err := convertFieldValue(fieldType, strings.TrimSpace(r))
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value.(V)
+	}
+	return values, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// encodeCursor builds the cursor pointing after item from its JSON representation.
+func encodeCursor(item any, sort, sortName, idName string) (string, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	// Numbers are kept as written, float64 would lose precision of large integers.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	data, err = json.Marshal(listCursor{Sort: sort, Value: fields[sortName], ID: fields[idName]})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor parses a cursor made for sort and converts its values to the field types.
+func decodeCursor(raw, sort string, sortField, idField ListField) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("is malformed")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var cursor listCursor
+	if err := decoder.Decode(&cursor); err != nil {
+		return nil, fmt.Errorf("is malformed")
+	}
+
+	if cursor.Sort != sort {
+		return nil, fmt.Errorf("was issued for sort %q, not %q", cursor.Sort, sort)
+	}
+
+	cursor.Value, err = convertFieldValue(sortField.Type, fmt.Sprint(cursor.Value))
+	if err != nil {
+		return nil, fmt.Errorf("is malformed")
+	}
+
+	cursor.ID, err = convertFieldValue(idField.Type, fmt.Sprint(cursor.ID))
+	if err != nil {
+		return nil, fmt.Errorf("is malformed")
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	deviceColumns   = `id, user_id, provider, platform, token, last_seen_at, created_at, updated_at`
+	deliveryColumns = `id, user_id, channel, provider, recipient, provider_message_id, status, error, created_at, updated_at`
+)
+
+type NotificationRepository struct {
+	db *instrumentedDB
+}
+
+func NewNotificationRepository(db *instrumentedDB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+// UpsertDevice registers a device token for a user. A token that was registered before,
+// e.g. by another account signed in on the same install, moves to this user.
+func (r *NotificationRepository) UpsertDevice(ctx context.Context, userID string, payload *model.RegisterDevicePayload) (*model.Device, error) {
+	query := `
+		INSERT INTO devices (user_id, provider, platform, token)
+		VALUES (@user_id, @provider, @platform, @token)
+		ON CONFLICT (token) DO UPDATE
+		SET user_id = EXCLUDED.user_id, provider = EXCLUDED.provider, platform = EXCLUDED.platform,
+			last_seen_at = now(), updated_at = now()
+		RETURNING ` + deviceColumns
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{
+		"user_id":  userID,
+		"provider": payload.Provider,
+		"platform": payload.Platform,
+		"token":    payload.Token,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert device for user %s: %w", userID, err)
+	}
+
+	device, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Device])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:devices: %w", err)
+	}
+
+	return &device, nil
+}
+
+// ListDevices returns every device of a user, most recently seen first.
+func (r *NotificationRepository) ListDevices(ctx context.Context, userID string) ([]model.Device, error) {
+	query := `SELECT ` + deviceColumns + ` FROM devices WHERE user_id = @user_id ORDER BY last_seen_at DESC`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"user_id": userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query devices of user %s: %w", userID, err)
+	}
+
+	devices, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Device])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// DeleteDevice removes a device of a user and reports whether it existed.
+func (r *NotificationRepository) DeleteDevice(ctx context.Context, userID string, deviceID uuid.UUID) (bool, error) {
+	query := `DELETE FROM devices WHERE id = @id AND user_id = @user_id`
+
+	tag, err := r.db.Exec(ctx, query, pgx.NamedArgs{"id": deviceID, "user_id": userID})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete device %s: %w", deviceID, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// DeleteDeviceByToken forgets a token the provider no longer accepts.
+func (r *NotificationRepository) DeleteDeviceByToken(ctx context.Context, token string) error {
+	query := `DELETE FROM devices WHERE token = @token`
+
+	if _, err := r.db.Exec(ctx, query, pgx.NamedArgs{"token": token}); err != nil {
+		return fmt.Errorf("failed to delete device by token: %w", err)
+	}
+
+	return nil
+}
+
+// CreateDelivery records a notification sent, or attempted, to a user.
+func (r *NotificationRepository) CreateDelivery(ctx context.Context, delivery *model.NotificationDelivery) error {
+	query := `
+		INSERT INTO notification_deliveries (user_id, channel, provider, recipient, provider_message_id, status, error)
+		VALUES (@user_id, @channel, @provider, @recipient, @provider_message_id, @status, @error)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query, pgx.NamedArgs{
+		"user_id":             delivery.UserID,
+		"channel":             delivery.Channel,
+		"provider":            delivery.Provider,
+		"recipient":           delivery.Recipient,
+		"provider_message_id": delivery.ProviderMessageID,
+		"status":              delivery.Status,
+		"error":               delivery.Error,
+	}).Scan(&delivery.ID, &delivery.CreatedAt, &delivery.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification delivery: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDeliveryStatus applies a status reported by the provider and reports whether a
+// delivery was updated. Callbacks can arrive out of order, so final statuses (delivered,
+// undelivered, failed) are never replaced.
+func (r *NotificationRepository) UpdateDeliveryStatus(ctx context.Context, provider, providerMessageID string, status model.NotificationStatus, deliveryError *string) (bool, error) {
+	query := `
+		UPDATE notification_deliveries
+		SET status = @status, error = COALESCE(@error, error), updated_at = now()
+		WHERE provider = @provider AND provider_message_id = @provider_message_id
+			AND status NOT IN ('delivered', 'undelivered', 'failed')
+	`
+
+	tag, err := r.db.Exec(ctx, query, pgx.NamedArgs{
+		"provider":            provider,
+		"provider_message_id": providerMessageID,
+		"status":              status,
+		"error":               deliveryError,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update notification delivery %s: %w", providerMessageID, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *NotificationRepository) UserDataSection() string {
+	return "notifications"
+}
+
+func (r *NotificationRepository) ExportUserData(ctx context.Context, userID string) (any, error) {
+	devices, err := r.ListDevices(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + deliveryColumns + ` FROM notification_deliveries WHERE user_id = @user_id ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"user_id": userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification deliveries of user %s: %w", userID, err)
+	}
+
+	deliveries, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.NotificationDelivery])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:notification_deliveries: %w", err)
+	}
+
+	return &model.NotificationData{Devices: devices, Deliveries: deliveries}, nil
+}
+
+// AnonymizeUserData deletes the user's devices and delivery history, both identify them
+// through phone numbers and device tokens.
+func (r *NotificationRepository) AnonymizeUserData(ctx context.Context, tx pgx.Tx, userID string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM devices WHERE user_id = @user_id`, pgx.NamedArgs{"user_id": userID}); err != nil {
+		return fmt.Errorf("failed to delete devices: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM notification_deliveries WHERE user_id = @user_id`, pgx.NamedArgs{"user_id": userID}); err != nil {
+		return fmt.Errorf("failed to delete notification deliveries: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListQuery is a SELECT split into parts, so the pagination helpers can add
+// ORDER BY/LIMIT/OFFSET and build the matching count query.
+type ListQuery struct {
+	// Columns and Table make up "SELECT <Columns> FROM <Table>".
+	Columns string
+	Table   string
+	// Where is the filter without the WHERE keyword, empty to list the whole table.
+	Where   string
+	OrderBy string
+	Args    pgx.NamedArgs
+}
+
+func (q ListQuery) from() string {
+	if q.Where == "" {
+		return "FROM " + q.Table
+	}
+	return "FROM " + q.Table + " WHERE " + q.Where
+}
+
+// listPage runs q for one page and counts the total with the given strategy.
+// One extra row is fetched to tell whether a next page exists, without counting.
+func listPage[T any](ctx context.Context, db querier, q ListQuery, params model.PageParams, strategy model.CountStrategy) ([]T, model.PageInfo, error) {
+	params = params.Normalize()
+
+	args := pgx.NamedArgs{}
+	for name, value := range q.Args {
+		args[name] = value
+	}
+	args["page_limit"] = params.Limit + 1
+	args["page_offset"] = params.Offset()
+
+	query := fmt.Sprintf("SELECT %s %s ORDER BY %s LIMIT @page_limit OFFSET @page_offset", q.Columns, q.from(), q.OrderBy)
+
+	rows, err := db.Query(ctx, query, args)
+	if err != nil {
+		return nil, model.PageInfo{}, fmt.Errorf("failed to query page of %s: %w", q.Table, err)
+	}
+
+	items, err := pgx.CollectRows(rows, pgx.RowToStructByName[T])
+	if err != nil {
+		return nil, model.PageInfo{}, fmt.Errorf("failed to collect rows from table:%s: %w", q.Table, err)
+	}
+
+	info := model.PageInfo{
+		Page:          params.Page,
+		Limit:         params.Limit,
+		HasNext:       len(items) > params.Limit,
+		CountStrategy: strategy,
+	}
+	if info.HasNext {
+		items = items[:params.Limit]
+	}
+
+	if strategy == model.CountExact || strategy == model.CountEstimated {
+		total, err := countRows(ctx, db, q, strategy)
+		if err != nil {
+			return nil, model.PageInfo{}, err
+		}
+
+		// Estimates can be behind, never report fewer rows than were just seen.
+		total = max(total, int64(params.Offset()+len(items)))
+		totalPages := (total + int64(params.Limit) - 1) / int64(params.Limit)
+		info.Total = &total
+		info.TotalPages = &totalPages
+	}
+
+	return items, info, nil
+}
+
+func countRows(ctx context.Context, db querier, q ListQuery, strategy model.CountStrategy) (int64, error) {
+	if strategy == model.CountEstimated {
+		if q.Where == "" {
+			return estimateTableRows(ctx, db, q)
+		}
+		return estimateQueryRows(ctx, db, q)
+	}
+
+	rows, err := db.Query(ctx, "SELECT COUNT(*) "+q.from(), q.Args)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count %s: %w", q.Table, err)
+	}
+
+	total, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[int64])
+	if err != nil {
+		return 0, fmt.Errorf("failed to collect row from table:%s: %w", q.Table, err)
+	}
+
+	return total, nil
+}
+
+// estimateTableRows reads the row count Postgres keeps in pg_class. It is -1 until
+// the table was first vacuumed or analyzed, then the planner estimate is used instead.
+func estimateTableRows(ctx context.Context, db querier, q ListQuery) (int64, error) {
+	rows, err := db.Query(ctx, "SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass(@table)", pgx.NamedArgs{"table": q.Table})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate rows of %s: %w", q.Table, err)
+	}
+
+	total, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[int64])
+	if err != nil {
+		return 0, fmt.Errorf("failed to collect row from table:pg_class: %w", err)
+	}
+
+	if total < 0 {
+		return estimateQueryRows(ctx, db, q)
+	}
+
+	return total, nil
+}
+
+// estimateQueryRows returns the planner's estimate of the rows matched by q.
+func estimateQueryRows(ctx context.Context, db querier, q ListQuery) (int64, error) {
+	rows, err := db.Query(ctx, "EXPLAIN (FORMAT JSON) SELECT 1 "+q.from(), q.Args)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate rows of %s: %w", q.Table, err)
+	}
+
+	plan, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[[]byte])
+	if err != nil {
+		return 0, fmt.Errorf("failed to collect query plan of %s: %w", q.Table, err)
+	}
+
+	var explained []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explained); err != nil {
+		return 0, fmt.Errorf("failed to parse query plan of %s: %w", q.Table, err)
+	}
+	if len(explained) == 0 {
+		return 0, fmt.Errorf("empty query plan for %s", q.Table)
+	}
+
+	return int64(explained[0].Plan.PlanRows), nil
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// timeRangeClause returns a WHERE condition limiting column to r and adds its bounds to args.
+// Comparing the partition key directly to parameters is what lets the planner prune
+// partitions of time-partitioned tables, wrapping column in a function would defeat it.
+// Without any bound it returns "TRUE".
+func timeRangeClause(column string, r model.TimeRange, args pgx.NamedArgs) string {
+	clause := "TRUE"
+
+	if r.From != nil {
+		clause += " AND " + column + " >= @range_from"
+		args["range_from"] = *r.From
+	}
+
+	if r.Until != nil {
+		clause += " AND " + column + " < @range_until"
+		args["range_until"] = *r.Until
+	}
+
+	return clause
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+const subscriptionColumns = `id, provider, provider_subscription_id, customer_id, owner_id, status, price_id,
+	current_period_end, cancel_at_period_end, canceled_at, synced_at, created_at, updated_at`
+
+type PaymentRepository struct {
+	db *instrumentedDB
+}
+
+func NewPaymentRepository(db *instrumentedDB) *PaymentRepository {
+	return &PaymentRepository{
+		db: db,
+	}
+}
+
+// GetCustomer returns the provider customer of an owner, pgx.ErrNoRows if it has none yet.
+func (r *PaymentRepository) GetCustomer(ctx context.Context, ownerID string) (*model.PaymentCustomer, error) {
+	query := `SELECT owner_id, provider, customer_id, created_at, updated_at FROM payment_customers WHERE owner_id = @owner_id`
+
+	return r.queryCustomer(ctx, query, pgx.NamedArgs{"owner_id": ownerID})
+}
+
+// GetCustomerByProviderID returns the owner of a provider customer.
+func (r *PaymentRepository) GetCustomerByProviderID(ctx context.Context, provider, customerID string) (*model.PaymentCustomer, error) {
+	query := `
+		SELECT owner_id, provider, customer_id, created_at, updated_at
+		FROM payment_customers
+		WHERE provider = @provider AND customer_id = @customer_id
+	`
+
+	return r.queryCustomer(ctx, query, pgx.NamedArgs{"provider": provider, "customer_id": customerID})
+}
+
+// CreateCustomer stores the provider customer of an owner. If a concurrent request
+// stored one first, that one is returned instead, so an owner never has two.
+func (r *PaymentRepository) CreateCustomer(ctx context.Context, ownerID, provider, customerID string) (*model.PaymentCustomer, error) {
+	query := `
+		INSERT INTO payment_customers (owner_id, provider, customer_id)
+		VALUES (@owner_id, @provider, @customer_id)
+		ON CONFLICT (owner_id) DO UPDATE SET owner_id = payment_customers.owner_id
+		RETURNING owner_id, provider, customer_id, created_at, updated_at
+	`
+
+	return r.queryCustomer(ctx, query, pgx.NamedArgs{
+		"owner_id":    ownerID,
+		"provider":    provider,
+		"customer_id": customerID,
+	})
+}
+
+// RecordEvent marks a webhook event as processed within tx and reports whether it is
+// new. Events seen before return false, the caller skips them.
+func (r *PaymentRepository) RecordEvent(ctx context.Context, tx pgx.Tx, provider, eventID, eventType string) (bool, error) {
+	query := `
+		INSERT INTO payment_events (provider, event_id, type)
+		VALUES (@provider, @event_id, @type)
+		ON CONFLICT (provider, event_id) DO NOTHING
+	`
+
+	tag, err := tx.Exec(ctx, query, pgx.NamedArgs{
+		"provider": provider,
+		"event_id": eventID,
+		"type":     eventType,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to record payment event %s: %w", eventID, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// SyncSubscription stores the state of a subscription within tx, unless a newer event
+// (by SyncedAt) was applied already, and reports whether it was stored.
+func (r *PaymentRepository) SyncSubscription(ctx context.Context, tx pgx.Tx, sub *model.Subscription) (bool, error) {
+	query := `
+		INSERT INTO subscriptions (provider, provider_subscription_id, customer_id, owner_id, status, price_id,
+			current_period_end, cancel_at_period_end, canceled_at, synced_at)
+		VALUES (@provider, @provider_subscription_id, @customer_id, @owner_id, @status, @price_id,
+			@current_period_end, @cancel_at_period_end, @canceled_at, @synced_at)
+		ON CONFLICT (provider, provider_subscription_id) DO UPDATE
+		SET customer_id = EXCLUDED.customer_id, owner_id = EXCLUDED.owner_id, status = EXCLUDED.status,
+			price_id = EXCLUDED.price_id, current_period_end = EXCLUDED.current_period_end,
+			cancel_at_period_end = EXCLUDED.cancel_at_period_end, canceled_at = EXCLUDED.canceled_at,
+			synced_at = EXCLUDED.synced_at, updated_at = now()
+		WHERE subscriptions.synced_at <= EXCLUDED.synced_at
+	`
+
+	tag, err := tx.Exec(ctx, query, pgx.NamedArgs{
+		"provider":                 sub.Provider,
+		"provider_subscription_id": sub.ProviderSubscriptionID,
+		"customer_id":              sub.CustomerID,
+		"owner_id":                 sub.OwnerID,
+		"status":                   sub.Status,
+		"price_id":                 sub.PriceID,
+		"current_period_end":       sub.CurrentPeriodEnd,
+		"cancel_at_period_end":     sub.CancelAtPeriodEnd,
+		"canceled_at":              sub.CanceledAt,
+		"synced_at":                sub.SyncedAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to sync subscription %s: %w", sub.ProviderSubscriptionID, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListSubscriptions returns every subscription of an owner, newest first.
+func (r *PaymentRepository) ListSubscriptions(ctx context.Context, ownerID string) ([]model.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE owner_id = @owner_id ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"owner_id": ownerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions of %s: %w", ownerID, err)
+	}
+
+	subscriptions, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Subscription])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// ListSubscriptionsByOwners returns every subscription of the owners of ownerIDs in one
+// query, newest first.
+func (r *PaymentRepository) ListSubscriptionsByOwners(ctx context.Context, ownerIDs []string) ([]model.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE owner_id = ANY(@owner_ids) ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"owner_ids": ownerIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+
+	subscriptions, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Subscription])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *PaymentRepository) queryCustomer(ctx context.Context, query string, args pgx.NamedArgs) (*model.PaymentCustomer, error) {
+	rows, err := r.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payment customer: %w", err)
+	}
+
+	customer, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.PaymentCustomer])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:payment_customers: %w", err)
+	}
+
+	return &customer, nil
+}
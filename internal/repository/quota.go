@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/quota"
+	"github.com/jackc/pgx/v5"
+)
+
+type QuotaRepository struct {
+	db *instrumentedDB
+}
+
+func NewQuotaRepository(db *instrumentedDB) *QuotaRepository {
+	return &QuotaRepository{
+		db: db,
+	}
+}
+
+// SaveSnapshot upserts the given counters. Stored values are never lowered,
+// so a snapshot taken from a freshly restarted Redis can't erase usage.
+func (r *QuotaRepository) SaveSnapshot(ctx context.Context, counters []quota.Counter) error {
+	if len(counters) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO quota_usage (consumer_id, period, period_start, used, updated_at)
+		VALUES (@consumer_id, @period, @period_start, @used, now())
+		ON CONFLICT (consumer_id, period, period_start)
+		DO UPDATE SET used = GREATEST(quota_usage.used, EXCLUDED.used), updated_at = now()
+	`
+
+	batch := &pgx.Batch{}
+	for _, counter := range counters {
+		batch.Queue(query, pgx.NamedArgs{
+			"consumer_id":  counter.Consumer,
+			"period":       counter.Period,
+			"period_start": counter.PeriodStart,
+			"used":         counter.Used,
+		})
+	}
+
+	if err := r.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save quota usage snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// ListCurrentPeriods returns the snapshots of the daily and monthly periods containing now.
+func (r *QuotaRepository) ListCurrentPeriods(ctx context.Context, now time.Time) ([]quota.Counter, error) {
+	query := `
+		SELECT consumer_id, period, period_start, used
+		FROM quota_usage
+		WHERE (period = 'daily' AND period_start = @day) OR (period = 'monthly' AND period_start = @month)
+	`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{
+		"day":   quota.PeriodStart(quota.PeriodDaily, now),
+		"month": quota.PeriodStart(quota.PeriodMonthly, now),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quota usage: %w", err)
+	}
+
+	counters, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (quota.Counter, error) {
+		var counter quota.Counter
+		err := row.Scan(&counter.Consumer, &counter.Period, &counter.PeriodStart, &counter.Used)
+		return counter, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:quota_usage: %w", err)
+	}
+
+	return counters, nil
+}
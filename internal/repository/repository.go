@@ -1,9 +1,74 @@
 package repository
 
-import "github.com/Barry-dE/go-backend-boilerplate/internal/server"
+import (
+	"context"
 
-type Repositories struct{}
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/jackc/pgx/v5"
+)
 
-func NewRepositories(s *server.Server) *Repositories{
-return &Repositories{}
-}
\ No newline at end of file
+type Repositories struct {
+	Audit            *AuditRepository
+	Compliance       *ComplianceRepository
+	Quota            *QuotaRepository
+	EmailSuppression *EmailSuppressionRepository
+	JobOutbox        *JobOutboxRepository
+	Tenant           *TenantRepository
+	HealthProbe      *HealthProbeRepository
+	Export           *ExportRepository
+	Import           *ImportRepository
+	Payment          *PaymentRepository
+	Notification     *NotificationRepository
+	Authz            *AuthzRepository
+	Usage            *UsageRepository
+	Setting          *SettingRepository
+	Verification     *EmailVerificationRepository
+}
+
+// NewRepositories builds every repository on top of the instrumented pool, so each
+// database call is measured and traced per repository method.
+func NewRepositories(s *server.Server) *Repositories {
+	db := newInstrumentedDB(s)
+
+	return &Repositories{
+		Audit:            NewAuditRepository(db),
+		Compliance:       NewComplianceRepository(db),
+		Quota:            NewQuotaRepository(db),
+		EmailSuppression: NewEmailSuppressionRepository(db),
+		JobOutbox:        NewJobOutboxRepository(db),
+		Tenant:           NewTenantRepository(db),
+		HealthProbe:      NewHealthProbeRepository(db),
+		Export:           NewExportRepository(db),
+		Import:           NewImportRepository(db),
+		Payment:          NewPaymentRepository(db),
+		Notification:     NewNotificationRepository(db),
+		Authz:            NewAuthzRepository(db),
+		Usage:            NewUsageRepository(db),
+		Setting:          NewSettingRepository(db),
+		Verification:     NewEmailVerificationRepository(db),
+	}
+}
+
+// UserDataProvider is implemented by every repository that stores data belonging
+// to a user. The compliance service uses it to build data exports and to
+// anonymize a user's data when their account is deleted.
+type UserDataProvider interface {
+	// UserDataSection names the section (file) in the export archive.
+	UserDataSection() string
+	// ExportUserData returns all data the repository holds about the user.
+	ExportUserData(ctx context.Context, userID string) (any, error)
+	// AnonymizeUserData removes or anonymizes the user's data within tx.
+	AnonymizeUserData(ctx context.Context, tx pgx.Tx, userID string) error
+}
+
+// UserDataProviders returns every repository holding user data.
+// Register new repositories here so exports and deletions cover them.
+func (r *Repositories) UserDataProviders() []UserDataProvider {
+	return []UserDataProvider{
+		r.Audit,
+		r.Compliance,
+		r.Notification,
+		r.Usage,
+		r.Verification,
+	}
+}
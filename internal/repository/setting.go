@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+const appSettingColumns = "key, value, updated_by, created_at, updated_at"
+
+type SettingRepository struct {
+	db *instrumentedDB
+}
+
+func NewSettingRepository(db *instrumentedDB) *SettingRepository {
+	return &SettingRepository{
+		db: db,
+	}
+}
+
+// ListSettings returns every stored setting, ordered by key.
+func (r *SettingRepository) ListSettings(ctx context.Context) ([]model.AppSetting, error) {
+	query := `SELECT ` + appSettingColumns + ` FROM app_settings ORDER BY key`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query app settings: %w", err)
+	}
+
+	settings, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.AppSetting])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:app_settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// GetSetting returns the stored setting key, nil if it has its default.
+func (r *SettingRepository) GetSetting(ctx context.Context, key string) (*model.AppSetting, error) {
+	query := `SELECT ` + appSettingColumns + ` FROM app_settings WHERE key = @key`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"key": key})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query app setting %s: %w", key, err)
+	}
+
+	setting, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.AppSetting])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:app_settings: %w", err)
+	}
+
+	return &setting, nil
+}
+
+// UpsertSetting stores the value of key.
+func (r *SettingRepository) UpsertSetting(ctx context.Context, key string, value json.RawMessage, updatedBy string) (*model.AppSetting, error) {
+	query := `
+		INSERT INTO app_settings (key, value, updated_by)
+		VALUES (@key, @value, NULLIF(@updated_by, ''))
+		ON CONFLICT (key) DO UPDATE SET
+			value = EXCLUDED.value,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = now()
+		RETURNING ` + appSettingColumns
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{
+		"key":        key,
+		"value":      value,
+		"updated_by": updatedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert app setting %s: %w", key, err)
+	}
+
+	setting, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.AppSetting])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:app_settings: %w", err)
+	}
+
+	return &setting, nil
+}
+
+// DeleteSetting removes the stored value of key and reports whether it had one.
+func (r *SettingRepository) DeleteSetting(ctx context.Context, key string) (bool, error) {
+	query := `DELETE FROM app_settings WHERE key = @key`
+
+	tag, err := r.db.Exec(ctx, query, pgx.NamedArgs{"key": key})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete app setting %s: %w", key, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
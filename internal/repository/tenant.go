@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+type TenantRepository struct {
+	db *instrumentedDB
+}
+
+func NewTenantRepository(db *instrumentedDB) *TenantRepository {
+	return &TenantRepository{
+		db: db,
+	}
+}
+
+const tenantLimitsColumns = "organization_id, plan, requests_per_minute, daily_quota, monthly_quota, created_at, updated_at"
+
+// GetLimits returns the overrides of an organization, nil if it has none.
+func (r *TenantRepository) GetLimits(ctx context.Context, organizationID string) (*model.TenantLimits, error) {
+	query := `SELECT ` + tenantLimitsColumns + ` FROM tenant_limits WHERE organization_id = @organization_id`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"organization_id": organizationID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenant limits: %w", err)
+	}
+
+	limits, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.TenantLimits])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:tenant_limits: %w", err)
+	}
+
+	return &limits, nil
+}
+
+// GetLimitsByOrganizations returns the overrides of every organization of organizationIDs
+// that has some, in one query.
+func (r *TenantRepository) GetLimitsByOrganizations(ctx context.Context, organizationIDs []string) ([]model.TenantLimits, error) {
+	query := `SELECT ` + tenantLimitsColumns + ` FROM tenant_limits WHERE organization_id = ANY(@organization_ids)`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"organization_ids": organizationIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenant limits: %w", err)
+	}
+
+	limits, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.TenantLimits])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:tenant_limits: %w", err)
+	}
+
+	return limits, nil
+}
+
+// UpsertLimits replaces the overrides of an organization.
+func (r *TenantRepository) UpsertLimits(ctx context.Context, organizationID string, payload *model.UpdateTenantLimitsPayload) (*model.TenantLimits, error) {
+	query := `
+		INSERT INTO tenant_limits (organization_id, plan, requests_per_minute, daily_quota, monthly_quota)
+		VALUES (@organization_id, @plan, @requests_per_minute, @daily_quota, @monthly_quota)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			plan = EXCLUDED.plan,
+			requests_per_minute = EXCLUDED.requests_per_minute,
+			daily_quota = EXCLUDED.daily_quota,
+			monthly_quota = EXCLUDED.monthly_quota,
+			updated_at = now()
+		RETURNING ` + tenantLimitsColumns
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{
+		"organization_id":     organizationID,
+		"plan":                payload.Plan,
+		"requests_per_minute": payload.RequestsPerMinute,
+		"daily_quota":         payload.DailyQuota,
+		"monthly_quota":       payload.MonthlyQuota,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert tenant limits: %w", err)
+	}
+
+	limits, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.TenantLimits])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect row from table:tenant_limits: %w", err)
+	}
+
+	return &limits, nil
+}
+
+// DeleteLimits removes the overrides of an organization and reports whether it had any.
+func (r *TenantRepository) DeleteLimits(ctx context.Context, organizationID string) (bool, error) {
+	query := `DELETE FROM tenant_limits WHERE organization_id = @organization_id`
+
+	tag, err := r.db.Exec(ctx, query, pgx.NamedArgs{"organization_id": organizationID})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete tenant limits: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListOrganizationIDs returns the organizations with overrides, most recently updated first.
+func (r *TenantRepository) ListOrganizationIDs(ctx context.Context, limit int) ([]string, error) {
+	query := `SELECT organization_id FROM tenant_limits ORDER BY updated_at DESC LIMIT @limit`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"limit": limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenant limits: %w", err)
+	}
+
+	organizationIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:tenant_limits: %w", err)
+	}
+
+	return organizationIDs, nil
+}
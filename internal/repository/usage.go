@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// usageUserPrefix is the prefix of the clients identifying a user.
+const usageUserPrefix = "user:"
+
+type UsageRepository struct {
+	db *instrumentedDB
+}
+
+func NewUsageRepository(db *instrumentedDB) *UsageRepository {
+	return &UsageRepository{
+		db: db,
+	}
+}
+
+// AddCounts adds counts to the daily rollup in one statement. Counts flushed by several
+// instances for the same day, client and route add up, a route seen deprecated once
+// stays deprecated for the day.
+func (r *UsageRepository) AddCounts(ctx context.Context, counts []model.UsageCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	days := make([]time.Time, len(counts))
+	clients := make([]string, len(counts))
+	methods := make([]string, len(counts))
+	routes := make([]string, len(counts))
+	versions := make([]string, len(counts))
+	deprecated := make([]bool, len(counts))
+	requests := make([]int64, len(counts))
+	errors := make([]int64, len(counts))
+	lastSeen := make([]time.Time, len(counts))
+	for i, count := range counts {
+		days[i] = count.Day
+		clients[i] = count.Client
+		methods[i] = count.Method
+		routes[i] = count.Route
+		versions[i] = count.Version
+		deprecated[i] = count.Deprecated
+		requests[i] = count.Requests
+		errors[i] = count.Errors
+		lastSeen[i] = count.LastSeenAt
+	}
+
+	query := `
+		INSERT INTO api_usage (day, client, method, route, version, deprecated, requests, errors, last_seen_at)
+		SELECT * FROM unnest(
+			@days::date[], @clients::text[], @methods::text[], @routes::text[], @versions::text[],
+			@deprecated::boolean[], @requests::bigint[], @errors::bigint[], @last_seen::timestamptz[]
+		)
+		ON CONFLICT (day, client, method, route) DO UPDATE SET
+			version = EXCLUDED.version,
+			deprecated = api_usage.deprecated OR EXCLUDED.deprecated,
+			requests = api_usage.requests + EXCLUDED.requests,
+			errors = api_usage.errors + EXCLUDED.errors,
+			last_seen_at = GREATEST(api_usage.last_seen_at, EXCLUDED.last_seen_at),
+			updated_at = now()
+	`
+
+	_, err := r.db.Exec(ctx, query, pgx.NamedArgs{
+		"days":       days,
+		"clients":    clients,
+		"methods":    methods,
+		"routes":     routes,
+		"versions":   versions,
+		"deprecated": deprecated,
+		"requests":   requests,
+		"errors":     errors,
+		"last_seen":  lastSeen,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %d usage counts: %w", len(counts), err)
+	}
+
+	return nil
+}
+
+// usageListSpec is the listing contract of the internal usage endpoint.
+var usageListSpec = ListSpec{
+	Query: ListQuery{
+		Columns: "id, day, client, method, route, version, deprecated, requests, errors, last_seen_at, created_at, updated_at",
+		Table:   "api_usage",
+	},
+	Fields: map[string]ListField{
+		"id":         {Column: "id", Type: FieldUUID, Ops: []model.FilterOp{model.FilterEq}},
+		"day":        {Column: "day", Type: FieldTime, Sortable: true, Ops: []model.FilterOp{model.FilterEq, model.FilterLt, model.FilterLte, model.FilterGt, model.FilterGte}},
+		"client":     {Column: "client", Type: FieldString, Ops: []model.FilterOp{model.FilterEq, model.FilterIn, model.FilterPrefix}},
+		"method":     {Column: "method", Type: FieldString, Ops: []model.FilterOp{model.FilterEq, model.FilterIn}},
+		"route":      {Column: "route", Type: FieldString, Ops: []model.FilterOp{model.FilterEq, model.FilterIn, model.FilterPrefix}},
+		"version":    {Column: "version", Type: FieldString, Ops: []model.FilterOp{model.FilterEq, model.FilterIn}},
+		"deprecated": {Column: "deprecated", Type: FieldBool, Ops: []model.FilterOp{model.FilterEq}},
+		"requests":   {Column: "requests", Type: FieldInt, Sortable: true, Ops: []model.FilterOp{model.FilterGt, model.FilterGte}},
+	},
+	DefaultSort: "-day",
+	IDField:     "id",
+}
+
+// List returns one page of the usage rollup, using the shared listing contract.
+func (r *UsageRepository) List(ctx context.Context, params model.ListParams) ([]model.APIUsage, model.CursorInfo, error) {
+	return listByCursor[model.APIUsage](ctx, r.db, usageListSpec, params)
+}
+
+// ListDeprecatedUsers returns the IDs of the users who called a deprecated route on or after since.
+func (r *UsageRepository) ListDeprecatedUsers(ctx context.Context, since time.Time) ([]string, error) {
+	query := `
+		SELECT DISTINCT substring(client FROM @prefix_length) AS user_id
+		FROM api_usage
+		WHERE deprecated AND day >= @since AND client LIKE @prefix || '%'
+		ORDER BY user_id
+	`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{
+		"since":         since,
+		"prefix":        usageUserPrefix,
+		"prefix_length": len(usageUserPrefix) + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users calling deprecated routes: %w", err)
+	}
+
+	userIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:api_usage: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// ListDeprecatedRoutes returns the deprecated routes userID called on or after since, most called first.
+func (r *UsageRepository) ListDeprecatedRoutes(ctx context.Context, userID string, since time.Time) ([]model.DeprecatedRouteUsage, error) {
+	query := `
+		SELECT method, route, sum(requests)::bigint AS requests, max(last_seen_at) AS last_seen_at
+		FROM api_usage
+		WHERE deprecated AND day >= @since AND client = @client
+		GROUP BY method, route
+		ORDER BY requests DESC, route, method
+	`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{
+		"since":  since,
+		"client": usageUserPrefix + userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deprecated routes of user %s: %w", userID, err)
+	}
+
+	routes, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.DeprecatedRouteUsage])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:api_usage: %w", err)
+	}
+
+	return routes, nil
+}
+
+func (r *UsageRepository) UserDataSection() string {
+	return "api_usage"
+}
+
+func (r *UsageRepository) ExportUserData(ctx context.Context, userID string) (any, error) {
+	query := `
+		SELECT id, day, client, method, route, version, deprecated, requests, errors, last_seen_at, created_at, updated_at
+		FROM api_usage
+		WHERE client = @client
+		ORDER BY day DESC, route, method
+	`
+
+	rows, err := r.db.Query(ctx, query, pgx.NamedArgs{"client": usageUserPrefix + userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api usage of user %s: %w", userID, err)
+	}
+
+	usage, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.APIUsage])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:api_usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// AnonymizeUserData folds the user's usage into the anonymous client, so totals per
+// route are kept without the rows identifying them.
+func (r *UsageRepository) AnonymizeUserData(ctx context.Context, tx pgx.Tx, userID string) error {
+	query := `
+		WITH removed AS (
+			DELETE FROM api_usage WHERE client = @client
+			RETURNING day, method, route, version, deprecated, requests, errors, last_seen_at
+		)
+		INSERT INTO api_usage (day, client, method, route, version, deprecated, requests, errors, last_seen_at)
+		SELECT day, @anonymous, method, route, version, deprecated, requests, errors, last_seen_at FROM removed
+		ON CONFLICT (day, client, method, route) DO UPDATE SET
+			deprecated = api_usage.deprecated OR EXCLUDED.deprecated,
+			requests = api_usage.requests + EXCLUDED.requests,
+			errors = api_usage.errors + EXCLUDED.errors,
+			last_seen_at = GREATEST(api_usage.last_seen_at, EXCLUDED.last_seen_at),
+			updated_at = now()
+	`
+
+	if _, err := tx.Exec(ctx, query, pgx.NamedArgs{
+		"client":    usageUserPrefix + userID,
+		"anonymous": model.AnonymousUsageClient,
+	}); err != nil {
+		return fmt.Errorf("failed to anonymize api usage: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/routeaccess"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// securedGroup is a group whose routes all require access, it declares every route it
+// registers so the OpenAPI document shows what they need.
+type securedGroup struct {
+	*echo.Group
+	access routeaccess.Access
+	auth   *middleware.AuthMiddleware
+}
+
+// secureGroup creates a group under r enforcing access before middlewares.
+func secureGroup(r *echo.Group, prefix string, m *middleware.Middlewares, access routeaccess.Access, middlewares ...echo.MiddlewareFunc) *securedGroup {
+	group := r.Group(prefix, append(m.AuthMiddleware.Require(access), middlewares...)...)
+	return &securedGroup{Group: group, access: access, auth: m.AuthMiddleware}
+}
+
+func (g *securedGroup) declare(route *echo.Route) *echo.Route {
+	g.auth.Declare(g.access, route)
+	return route
+}
+
+func (g *securedGroup) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.declare(g.Group.GET(path, h, m...))
+}
+
+func (g *securedGroup) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.declare(g.Group.POST(path, h, m...))
+}
+
+func (g *securedGroup) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.declare(g.Group.PUT(path, h, m...))
+}
+
+func (g *securedGroup) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.declare(g.Group.PATCH(path, h, m...))
+}
+
+func (g *securedGroup) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.declare(g.Group.DELETE(path, h, m...))
+}
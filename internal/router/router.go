@@ -0,0 +1,168 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/handler"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/service"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+	"github.com/Barry-dE/go-backend-boilerplate/static"
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+// globalRateLimit is the number of requests per second allowed per client IP.
+const globalRateLimit = 20
+
+// NewRouter builds the echo instance with the global middleware chain and every route registered.
+// It fails if the global middleware chain is misordered.
+func NewRouter(s *server.Server, h *handler.Handlers, services *service.Services) (*echo.Echo, error) {
+	middlewares := middleware.NewMiddlewares(s)
+
+	router := echo.New()
+	router.Debug = s.Config.Server.Echo.Debug
+	router.HideBanner = !s.Config.Server.Echo.ShowBanner
+	router.HidePort = !s.Config.Server.Echo.ShowBanner
+
+	router.HTTPErrorHandler = middlewares.GlobalMiddleware.GlobalErrorHandler
+	router.Validator = validation.NewEchoValidator()
+	router.JSONSerializer = middlewares.GlobalMiddleware.JSONSerializer()
+
+	binder := validation.NewSchemaBinder()
+	router.Binder = binder
+
+	// Global middlewares in the order they run. The chain checks ordering rules on Build,
+	// e.g. the request ID must exist before tracing, context enhancement and logging.
+	global, err := middlewares.Chain().
+		// First, so the responses of every later stage are rewritten too, rate limited ones included.
+		Use(middleware.StageServerHeaders, middlewares.GlobalMiddleware.ServerHeaders()).
+		Use(middleware.StageRateLimit, echoMiddleware.RateLimiterWithConfig(echoMiddleware.RateLimiterConfig{
+			Store: echoMiddleware.NewRateLimiterMemoryStore(rate.Limit(globalRateLimit)),
+			DenyHandler: func(c echo.Context, identifier string, err error) error {
+				middlewares.RateLimiterMiddleware.RecordHit(c.Path())
+				return echo.NewHTTPError(http.StatusTooManyRequests, "Rate limit exceeded")
+			},
+		})).
+		Use(middleware.StageCORS, middlewares.GlobalMiddleware.CORS()).
+		Use(middleware.StageSecure, middlewares.GlobalMiddleware.Secure()).
+		Use(middleware.StageBodyLimit, middlewares.GlobalMiddleware.BodyLimit()).
+		// After BodyLimit, which caps the compressed body. Not a chain requirement, import
+		// uploads skip BodyLimit but are decompressed too.
+		Use(middleware.StageDecompress, middlewares.GlobalMiddleware.Decompress()).
+		Use(middleware.StageResponse, middlewares.GlobalMiddleware.CaptureResponseController()).
+		Use(middleware.StageResponseWriter, middlewares.GlobalMiddleware.ResponseWriter()).
+		Use(middleware.StageRequestID, middlewares.RequestID.RequestID()).
+		Use(middleware.StageTracing, middlewares.TracingMiddleware.NewRelicMiddleware()).
+		Use(middleware.StageEnhanceTracing, middlewares.TracingMiddleware.EnchanceTracing()).
+		Use(middleware.StageContext, middlewares.ContextEnhancer.EnhanceContext()).
+		Use(middleware.StageLogger, middlewares.GlobalMiddleware.RequestLogger()).
+		Use(middleware.StageSlowRequest, middlewares.SlowRequestMiddleware.DetectSlowRequests()).
+		Use(middleware.StageLatencyBudget, middlewares.LatencyBudget.Track()).
+		Use(middleware.StageNPlusOne, middlewares.NPlusOne.Detect()).
+		Use(middleware.StageUsage, middlewares.Usage.Track()).
+		Use(middleware.StageRecover, middlewares.GlobalMiddleware.Recover()).
+		Use(middleware.StageListener, middlewares.GlobalMiddleware.PerListener(listenerChains(s))).
+		Use(middleware.StageInFlight, middlewares.DrainMiddleware.TrackInFlight()).
+		Use(middleware.StageRouteToggle, middlewares.RouteToggle.Check()).
+		Use(middleware.StageExamples, middlewares.ExampleCapture.Capture()).
+		// Health checks, orchestrator probes and provider webhooks must never be rate limited.
+		SkipFor(middleware.StageRateLimit, "/status", "/internal/", "/api/v1/webhooks/").
+		// Import uploads are held to the larger import file size on their route instead.
+		SkipFor(middleware.StageBodyLimit, "/api/v1/admin/imports/").
+		// Probes and docs aren't part of the latency SLO.
+		SkipFor(middleware.StageLatencyBudget, "/status", "/health/", "/internal/", "/docs", "/static/", "/.well-known/").
+		// Only API routes are documented, webhooks carry provider payloads.
+		// Usage analytics cover the API, not probes and docs.
+		SkipFor(middleware.StageUsage, "/status", "/health/", "/internal/", "/docs", "/static/", "/.well-known/", "/openapi.json", "/validation-schema").
+		// Probes can't be disabled, nor the toggles themselves, or re-enabling would be locked out.
+		SkipFor(middleware.StageRouteToggle, "/status", "/health/", "/internal/", "/api/v1/admin/routes").
+		SkipFor(middleware.StageExamples, "/status", "/health/", "/internal/", "/docs", "/static/", "/.well-known/", "/openapi.json", "/api/v1/webhooks/").
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
+	router.Use(global...)
+
+	// Register system routes such as health checks and API docs
+	registerSystemRoutes(router, h, middlewares)
+
+	// Register versioned API routes
+	v1 := router.Group("/api/v1")
+	registerV1Routes(v1, h, middlewares)
+
+	if s.Config.GraphQL.Enabled {
+		registerGraphQLRoutes(router, h, middlewares)
+	}
+
+	if err := checkRequestBodies(router); err != nil {
+		return nil, err
+	}
+
+	if err := registerSchemas(binder); err != nil {
+		return nil, err
+	}
+
+	return router, nil
+}
+
+// listenerChains returns the middlewares run for the requests of each listener, after
+// the global ones. Path restrictions come from the listener's config; add what else a
+// listener needs here, e.g. m.AuthMiddleware.RequireInternalCaller() on an internal one.
+func listenerChains(s *server.Server) map[string][]echo.MiddlewareFunc {
+	chains := make(map[string][]echo.MiddlewareFunc)
+	for name, listener := range s.Config.Server.GetListeners() {
+		if len(listener.Paths) > 0 || len(listener.DenyPaths) > 0 {
+			chains[name] = append(chains[name], middleware.RestrictPaths(listener.Paths, listener.DenyPaths))
+		}
+	}
+	return chains
+}
+
+// checkRequestBodies makes sure every endpoint in the validation schema is a registered
+// route, so the schema can't silently drift from the API.
+func checkRequestBodies(router *echo.Echo) error {
+	routes := make(map[string]bool)
+	for _, route := range router.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+
+	var missing []error
+	for _, body := range handler.RequestBodies {
+		if !routes[body.Method+" "+body.Path] {
+			missing = append(missing, fmt.Errorf("validation schema lists %s %s, which is not a registered route", body.Method, body.Path))
+		}
+	}
+
+	return errors.Join(missing...)
+}
+
+// registerSchemas has the binder validate the bodies of endpoints declaring a JSON Schema
+// against it. Schemas are read from static/schemas/, where clients can fetch them too.
+func registerSchemas(binder *validation.SchemaBinder) error {
+	var invalid []error
+	for _, body := range handler.RequestBodies {
+		if body.Schema == "" {
+			continue
+		}
+
+		raw, err := fs.ReadFile(static.FS, path.Join("schemas", body.Schema))
+		if err != nil {
+			invalid = append(invalid, fmt.Errorf("schema of %s %s: %w", body.Method, body.Path, err))
+			continue
+		}
+
+		if err := binder.Register(body.Method, body.Path, raw); err != nil {
+			invalid = append(invalid, err)
+		}
+	}
+
+	return errors.Join(invalid...)
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"github.com/Barry-dE/go-backend-boilerplate/internal/handler"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/routeaccess"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// registerSystemRoutes registers routes that are not part of the versioned API.
+func registerSystemRoutes(r *echo.Echo, h *handler.Handlers, m *middleware.Middlewares) {
+	r.GET("/status", h.Health.HealthCheck)
+
+	// End-to-end checks for synthetic monitors, they write rows and send an email
+	m.AuthMiddleware.Declare(routeaccess.Operator, r.GET("/health/deep", h.Health.DeepHealthCheck, m.AuthMiddleware.Require(routeaccess.Operator)...))
+
+	// Orchestrator endpoints, not counted as in-flight requests.
+	r.GET("/internal/ready", h.Health.Readiness)
+	r.GET("/internal/prestop", h.Health.PreStop)
+
+	// Connection pool stats, for internal scrapers only
+	m.AuthMiddleware.Declare(routeaccess.Internal, r.GET("/internal/pool-stats", h.Health.PoolStats, m.AuthMiddleware.Require(routeaccess.Internal)...))
+
+	// API usage analytics, for internal services only
+	m.AuthMiddleware.Declare(routeaccess.Internal, r.GET("/internal/api-usage", h.Usage.ListUsage, m.AuthMiddleware.Require(routeaccess.Internal)...))
+
+	r.GET("/docs", h.OpenAPI.OpenAPIUI)
+	r.GET("/openapi.json", h.OpenAPI.Spec)
+	r.GET("/validation-schema", h.OpenAPI.ValidationSchema)
+
+	r.GET("/static/*", h.Static.Serve)
+
+	// Standard documents, from the well_known config or embedded under static/well-known/
+	wellKnown := r.Group("/.well-known")
+	wellKnown.GET("/security.txt", h.WellKnown.SecurityTxt)
+	wellKnown.GET("/change-password", h.WellKnown.ChangePassword)
+	wellKnown.GET("/jwks.json", h.WellKnown.JWKS)
+	wellKnown.GET("/*", h.WellKnown.Serve)
+}
+
+// registerGraphQLRoutes registers the optional GraphQL endpoint. It is authenticated,
+// rate limited and metered like the /me routes, the global tracing applies as on every route.
+func registerGraphQLRoutes(r *echo.Echo, h *handler.Handlers, m *middleware.Middlewares) {
+	graphql := secureGroup(r.Group(""), "/graphql", m, routeaccess.User, m.RateLimiterMiddleware.TenantRateLimit(), m.QuotaMiddleware.EnforceQuota())
+	graphql.POST("", h.GraphQL.Query)
+}
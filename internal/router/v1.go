@@ -0,0 +1,134 @@
+package router
+
+import (
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/handler"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/routeaccess"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// registerV1Routes registers every route of the /api/v1 group.
+func registerV1Routes(r *echo.Group, h *handler.Handlers, m *middleware.Middlewares) {
+	registerComplianceRoutes(r, h, m)
+	registerQuotaRoutes(r, h, m)
+	registerAdminRoutes(r, h, m)
+	registerEmailRoutes(r, h)
+	registerEmailVerificationRoutes(r, h, m)
+	registerExportRoutes(r, h)
+	registerPaymentRoutes(r, h, m)
+	registerNotificationRoutes(r, h, m)
+}
+
+func registerComplianceRoutes(r *echo.Group, h *handler.Handlers, m *middleware.Middlewares) {
+	// Routes acting on the authenticated user's own data
+	me := secureGroup(r, "/me", m, routeaccess.User, m.RateLimiterMiddleware.TenantRateLimit(), m.QuotaMiddleware.EnforceQuota())
+	me.POST("/data-exports", h.Compliance.RequestDataExport)
+	me.GET("/data-exports/:id", h.Compliance.GetDataExport)
+	me.POST("/account-deletion", h.Compliance.RequestAccountDeletion)
+	me.DELETE("/account-deletion", h.Compliance.CancelAccountDeletion)
+	me.GET("/audit-logs", h.Compliance.ListAuditLogs)
+
+	// Download links are signed, so they work without a session (e.g. opened from an email)
+	r.GET("/compliance/exports/:id/download", h.Compliance.DownloadDataExport)
+}
+
+func registerQuotaRoutes(r *echo.Group, h *handler.Handlers, m *middleware.Middlewares) {
+	// Checking the remaining quota doesn't count against it, clients poll it so it must stay fast
+	quota := r.GET("/me/quota", h.Quota.GetQuota, m.LatencyBudget.Budget(200*time.Millisecond), m.AuthMiddleware.Authenticate, m.RateLimiterMiddleware.TenantRateLimit())
+	m.AuthMiddleware.Declare(routeaccess.User, quota)
+}
+
+func registerAdminRoutes(r *echo.Group, h *handler.Handlers, m *middleware.Middlewares) {
+	// Platform operator endpoints, they reach across organizations. Every listing follows
+	// the shared cursor/sort/filter contract
+	admin := secureGroup(r, "/admin", m, routeaccess.Operator)
+	admin.GET("/audit-logs", h.Admin.ListAuditLogs)
+	admin.GET("/config-changes", h.Admin.ListConfigChanges)
+	admin.GET("/email-suppressions", h.Admin.ListEmailSuppressions)
+
+	// Per-organization rate limit and quota overrides, e.g. for paid plans
+	admin.GET("/tenants/:id/limits", h.Admin.GetTenantLimits)
+	admin.PUT("/tenants/:id/limits", h.Admin.UpdateTenantLimits)
+	admin.DELETE("/tenants/:id/limits", h.Admin.DeleteTenantLimits)
+
+	// Runtime settings, e.g. the support email or banner text, changed without a redeploy
+	admin.GET("/settings", h.Settings.ListSettings)
+	admin.GET("/settings/:key", h.Settings.GetSetting)
+	admin.PUT("/settings/:key", h.Settings.UpdateSetting)
+	admin.DELETE("/settings/:key", h.Settings.DeleteSetting)
+
+	// Route kill-switch, e.g. to quarantine a misbehaving endpoint during an incident. These
+	// routes skip the check themselves, so a disabled route can always be enabled again
+	admin.GET("/routes/disabled", h.RouteToggle.ListDisabledRoutes)
+	admin.PUT("/routes/disabled", h.RouteToggle.DisableRoute)
+	admin.DELETE("/routes/disabled", h.RouteToggle.EnableRoute)
+
+	// Authorization policies, applied on every instance within authz.reload_interval
+	admin.GET("/authz/policies", h.Authz.ListPolicies)
+	admin.POST("/authz/policies", h.Authz.CreatePolicy)
+	admin.DELETE("/authz/policies/:id", h.Authz.DeletePolicy)
+
+	// Dataset exports: streamed ones can take minutes, so they are held to neither the write
+	// timeout nor the latency budget. Larger exports are built by a job instead.
+	admin.GET("/datasets/:dataset/export", h.Export.StreamExport, m.GlobalMiddleware.StreamingWriteTimeout(), m.LatencyBudget.Budget(0))
+	admin.POST("/datasets/:dataset/exports", h.Export.RequestExport)
+	admin.GET("/exports/:id", h.Export.GetExport)
+
+	// CSV imports: uploads are validated and staged while they are read, which takes a
+	// while for large files, then a job commits them in batches
+	admin.POST("/imports/:dataset", h.Import.UploadImport, m.GlobalMiddleware.ImportBodyLimit(),
+		m.GlobalMiddleware.ReadTimeout(10*time.Minute), m.GlobalMiddleware.WriteTimeout(10*time.Minute), m.LatencyBudget.Budget(0))
+	admin.GET("/imports/:id", h.Import.GetImport)
+
+	// Live progress of async exports and imports, streams stay open until the operation is done
+	admin.GET("/operations/:id/progress", h.Operation.GetProgress)
+	admin.GET("/operations/:id/events", h.Operation.StreamProgress, m.GlobalMiddleware.StreamingWriteTimeout(), m.LatencyBudget.Budget(0))
+}
+
+func registerEmailRoutes(r *echo.Group, h *handler.Handlers) {
+	// Unsubscribe links are signed per recipient, so they work without a session. The
+	// path must match email.UnsubscribePath, which the links in bulk emails point to.
+	r.GET("/email/unsubscribe", h.Email.GetUnsubscribe)
+	r.POST("/email/unsubscribe", h.Email.Unsubscribe)
+}
+
+func registerEmailVerificationRoutes(r *echo.Group, h *handler.Handlers, m *middleware.Middlewares) {
+	// Verification of the caller's email address, sending is rate limited per user as well
+	verification := secureGroup(r, "/me/email-verification", m, routeaccess.User, m.RateLimiterMiddleware.TenantRateLimit())
+	verification.GET("", h.Verification.GetEmailVerification)
+	verification.POST("", h.Verification.RequestEmailVerification)
+	verification.POST("/resend", h.Verification.ResendEmailVerification)
+
+	// Verification links carry a single-use token, so they work without a session. The
+	// path must match email.VerifyPath, which the links in verification emails point to.
+	r.GET("/email/verify", h.Verification.VerifyEmail)
+}
+
+func registerExportRoutes(r *echo.Group, h *handler.Handlers) {
+	// Download links of async exports are signed, so they work without a session
+	r.GET("/exports/:id/download", h.Export.DownloadExport)
+}
+
+func registerPaymentRoutes(r *echo.Group, h *handler.Handlers, m *middleware.Middlewares) {
+	// Billing of the caller's organization, or the caller outside of one. Checking out
+	// requires a verified email address, receipts and billing notices go to it
+	billing := secureGroup(r, "/me/billing", m, routeaccess.User, m.RateLimiterMiddleware.TenantRateLimit())
+	billing.POST("/checkout", h.Payment.CreateCheckout, m.EmailVerification.RequireVerifiedEmail())
+	billing.GET("/subscriptions", h.Payment.ListSubscriptions)
+
+	// Provider webhooks are authenticated by their signature, the path names the webhook source
+	r.POST("/webhooks/stripe", h.Payment.Webhook, m.WebhookMiddleware.Verify("stripe"))
+}
+
+func registerNotificationRoutes(r *echo.Group, h *handler.Handlers, m *middleware.Middlewares) {
+	// Push tokens of the caller's app installs
+	devices := secureGroup(r, "/me/devices", m, routeaccess.User, m.RateLimiterMiddleware.TenantRateLimit())
+	devices.POST("", h.Notification.RegisterDevice)
+	devices.GET("", h.Notification.ListDevices)
+	devices.DELETE("/:id", h.Notification.DeleteDevice)
+
+	// The path must match service.TwilioStatusCallbackPath, which sent SMS report to
+	r.POST("/webhooks/twilio/status", h.Notification.SMSStatus, m.WebhookMiddleware.Verify("twilio"))
+}
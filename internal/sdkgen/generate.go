@@ -0,0 +1,453 @@
+package sdkgen
+
+import (
+	"fmt"
+	"go/format"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// methodOrder keeps generated operations in a stable order within a path.
+var methodOrder = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// initialisms are written in upper case in Go identifiers, as golint expects.
+var initialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "ip": true, "json": true, "url": true, "uuid": true,
+}
+
+// Generate renders the types and operations of spec as Go source for package pkg.
+// The output only depends on the spec, so regenerating an unchanged spec is a no-op.
+func Generate(spec *Spec, pkg string) ([]byte, error) {
+	g := &generator{spec: spec}
+
+	if err := g.schemas(); err != nil {
+		return nil, err
+	}
+
+	if err := g.operations(); err != nil {
+		return nil, err
+	}
+
+	body := g.buf.String()
+
+	// Only import what the generated code uses, depending on the spec.
+	var header strings.Builder
+	header.WriteString("// Code generated by go-boilerplate gen sdk. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&header, "package %s\n\nimport (\n", pkg)
+	for _, path := range []string{"context", "fmt", "io", "net/url", "time"} {
+		if packageUse(path).MatchString(body) {
+			fmt.Fprintf(&header, "%q\n", path)
+		}
+	}
+	header.WriteString(")\n\n")
+
+	source, err := format.Source([]byte(header.String() + body))
+	if err != nil {
+		return nil, fmt.Errorf("generated code does not compile: %w", err)
+	}
+
+	return source, nil
+}
+
+// packageUse matches a qualified identifier of the package imported from path, e.g. "io.Reader" for "io".
+func packageUse(path string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[^\w.])` + regexp.QuoteMeta(path[strings.LastIndex(path, "/")+1:]) + `\.`)
+}
+
+type generator struct {
+	spec *Spec
+	buf  strings.Builder
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) schemas() error {
+	for _, name := range sortedKeys(g.spec.Components.Schemas) {
+		schema := g.spec.Components.Schemas[name]
+		typeName := exportedName(name)
+
+		if schema.Description != "" {
+			g.printf("// %s %s\n", typeName, schema.Description)
+		}
+
+		switch {
+		case len(schema.Enum) > 0:
+			g.printf("type %s string\n\nconst (\n", typeName)
+			for _, value := range schema.Enum {
+				g.printf("%s%s %s = %q\n", typeName, exportedName(value), typeName, value)
+			}
+			g.printf(")\n\n")
+		case schema.Type == "object" && len(schema.Properties) > 0:
+			g.printf("type %s struct {\n", typeName)
+			required := toSet(schema.Required)
+			for _, property := range sortedKeys(schema.Properties) {
+				goType, err := g.goType(schema.Properties[property], required[property])
+				if err != nil {
+					return fmt.Errorf("schema %s, property %s: %w", name, property, err)
+				}
+				tag := property
+				if !required[property] {
+					tag += ",omitempty"
+				}
+				g.printf("%s %s `json:%q`\n", exportedName(property), goType, tag)
+			}
+			g.printf("}\n\n")
+		default:
+			goType, err := g.goType(schema, true)
+			if err != nil {
+				return fmt.Errorf("schema %s: %w", name, err)
+			}
+			g.printf("type %s %s\n\n", typeName, goType)
+		}
+	}
+
+	return nil
+}
+
+// route is an operation together with where it is mounted.
+type route struct {
+	method    string
+	path      string
+	operation *Operation
+}
+
+func (g *generator) routes() []route {
+	var routes []route
+	for _, path := range sortedKeys(g.spec.Paths) {
+		for _, method := range methodOrder {
+			if operation, ok := g.spec.Paths[path][strings.ToLower(method)]; ok {
+				routes = append(routes, route{method: method, path: path, operation: operation})
+			}
+		}
+	}
+	return routes
+}
+
+func (g *generator) operations() error {
+	routes := g.routes()
+
+	// The route table lets consumers (and tests) check the client covers every server route.
+	g.printf("// Route is an API route the client has a method for.\n")
+	g.printf("type Route struct {\nMethod string\nPath string\nOperationID string\n}\n\n")
+	g.printf("// Routes lists every route of the OpenAPI spec the client was generated from.\n")
+	g.printf("var Routes = []Route{\n")
+	for _, r := range routes {
+		g.printf("{Method: %q, Path: %q, OperationID: %q},\n", r.method, r.path, r.operation.OperationID)
+	}
+	g.printf("}\n\n")
+
+	for _, r := range routes {
+		if err := g.operation(r); err != nil {
+			return fmt.Errorf("%s %s: %w", r.method, r.path, err)
+		}
+	}
+
+	return nil
+}
+
+func (g *generator) operation(r route) error {
+	if r.operation.OperationID == "" {
+		return fmt.Errorf("operation has no operationId")
+	}
+	name := exportedName(r.operation.OperationID)
+
+	var (
+		args        = []string{"ctx context.Context"}
+		pathParams  = map[string]string{}
+		queryParams []Parameter
+	)
+
+	for _, param := range r.operation.Parameters {
+		switch param.In {
+		case "path":
+			arg := unexportedName(param.Name)
+			args = append(args, arg+" string")
+			pathParams[param.Name] = arg
+		case "query":
+			queryParams = append(queryParams, param)
+		}
+	}
+
+	if len(queryParams) > 0 {
+		g.printf("// %sParams are the query parameters of %s.\n", name, name)
+		g.printf("type %sParams struct {\n", name)
+		for _, param := range queryParams {
+			goType, err := g.goType(param.Schema, param.Required)
+			if err != nil {
+				return fmt.Errorf("query parameter %s: %w", param.Name, err)
+			}
+			g.printf("%s %s\n", exportedName(param.Name), goType)
+		}
+		g.printf("}\n\n")
+		args = append(args, "params *"+name+"Params")
+	}
+
+	bodyArg := "nil"
+	if body := r.operation.RequestBody; body != nil {
+		media, ok := body.Content["application/json"]
+		upload, isUpload := fileUpload(body)
+		switch {
+		case ok && media.Schema != nil:
+			goType, err := g.goType(media.Schema, true)
+			if err != nil {
+				return fmt.Errorf("request body: %w", err)
+			}
+			args = append(args, "body "+goType)
+			bodyArg = "body"
+		case isUpload:
+			args = append(args, "filename string", "file io.Reader")
+			bodyArg = fmt.Sprintf("&upload{field: %q, filename: filename, file: file}", upload)
+		case body.Required:
+			return fmt.Errorf("only JSON and single file multipart request bodies are supported")
+		}
+		// Optional bodies in other formats (e.g. one-click unsubscribe forms) are meant for
+		// other kinds of clients, the operation works without them.
+	}
+
+	resultType, binary, err := g.resultType(r.operation)
+	if err != nil {
+		return err
+	}
+
+	if r.operation.Summary != "" {
+		g.printf("// %s: %s.\n", name, strings.TrimSuffix(r.operation.Summary, "."))
+	}
+	g.printf("//\n// %s %s\n", r.method, r.path)
+
+	switch {
+	case binary:
+		g.printf("func (c *Client) %s(%s) ([]byte, error) {\n", name, strings.Join(args, ", "))
+	case resultType != "":
+		g.printf("func (c *Client) %s(%s) (*%s, error) {\n", name, strings.Join(args, ", "), resultType)
+	default:
+		g.printf("func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+	}
+
+	g.printf("path := %s\n", pathExpression(r.path, pathParams))
+
+	queryArg := "nil"
+	if len(queryParams) > 0 {
+		queryArg = "query"
+		g.printf("query := url.Values{}\nif params != nil {\n")
+		for _, param := range queryParams {
+			field := "params." + exportedName(param.Name)
+			// Timestamps are sent as RFC 3339, which is what the API binds.
+			value, optionalValue := "fmt.Sprint("+field+")", "fmt.Sprint(*"+field+")"
+			if param.Schema != nil && param.Schema.Format == "date-time" {
+				value = field + ".Format(time.RFC3339)"
+				optionalValue = value
+			}
+			if param.Required {
+				g.printf("query.Set(%q, %s)\n", param.Name, value)
+			} else {
+				g.printf("if %s != nil {\nquery.Set(%q, %s)\n}\n", field, param.Name, optionalValue)
+			}
+		}
+		g.printf("}\n")
+	}
+
+	switch {
+	case binary:
+		g.printf("return c.doRaw(ctx, %q, path, %s, %s)\n", r.method, queryArg, bodyArg)
+	case resultType != "":
+		g.printf("var out %s\n", resultType)
+		g.printf("if err := c.do(ctx, %q, path, %s, %s, &out); err != nil {\nreturn nil, err\n}\n", r.method, queryArg, bodyArg)
+		g.printf("return &out, nil\n")
+	default:
+		g.printf("return c.do(ctx, %q, path, %s, %s, nil)\n", r.method, queryArg, bodyArg)
+	}
+
+	g.printf("}\n\n")
+
+	return nil
+}
+
+// fileUpload returns the form field of a multipart body uploading a single file, which
+// the client sends from a file name and a reader.
+func fileUpload(body *RequestBody) (string, bool) {
+	media, ok := body.Content["multipart/form-data"]
+	if !ok || media.Schema == nil || len(media.Schema.Properties) != 1 {
+		return "", false
+	}
+
+	for field, schema := range media.Schema.Properties {
+		if schema.Type == "string" && schema.Format == "binary" {
+			return field, true
+		}
+	}
+
+	return "", false
+}
+
+// resultType returns the Go type of the first successful response, or binary for non-JSON bodies.
+func (g *generator) resultType(operation *Operation) (string, bool, error) {
+	for _, status := range sortedKeys(operation.Responses) {
+		if !strings.HasPrefix(status, "2") {
+			continue
+		}
+
+		response := operation.Responses[status]
+		if len(response.Content) == 0 {
+			return "", false, nil
+		}
+
+		if media, ok := response.Content["application/json"]; ok && media.Schema != nil {
+			goType, err := g.goType(media.Schema, true)
+			if err != nil {
+				return "", false, fmt.Errorf("response %s: %w", status, err)
+			}
+			return goType, false, nil
+		}
+
+		return "", true, nil
+	}
+
+	return "", false, nil
+}
+
+// goType maps a schema to a Go type. Optional scalars become pointers so a missing
+// value can be told apart from the zero value.
+func (g *generator) goType(schema *Schema, required bool) (string, error) {
+	if schema == nil {
+		return "any", nil
+	}
+
+	var goType string
+	switch {
+	case schema.Ref != "":
+		name := schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+		if _, ok := g.spec.Components.Schemas[name]; !ok {
+			return "", fmt.Errorf("unknown schema reference %s", schema.Ref)
+		}
+		goType = exportedName(name)
+	case schema.Type == "string" && schema.Format == "date-time":
+		goType = "time.Time"
+	case schema.Type == "string" && schema.Format == "binary":
+		return "[]byte", nil
+	case schema.Type == "string":
+		goType = "string"
+	case schema.Type == "integer" && schema.Format == "int64":
+		goType = "int64"
+	case schema.Type == "integer" && schema.Format == "int32":
+		goType = "int32"
+	case schema.Type == "integer":
+		goType = "int"
+	case schema.Type == "number":
+		goType = "float64"
+	case schema.Type == "boolean":
+		goType = "bool"
+	case schema.Type == "array":
+		items, err := g.goType(schema.Items, true)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + items, nil
+	case schema.Type == "object":
+		values := "any"
+		if additional, ok := schema.AdditionalProperties.(map[string]any); ok && additional["$ref"] != nil {
+			ref, _ := additional["$ref"].(string)
+			valueType, err := g.goType(&Schema{Ref: ref}, true)
+			if err != nil {
+				return "", err
+			}
+			values = valueType
+		}
+		return "map[string]" + values, nil
+	case schema.Type == "":
+		// A schema without a type accepts any JSON value, e.g. the value of a setting
+		return "any", nil
+	default:
+		return "", fmt.Errorf("unsupported schema type %q", schema.Type)
+	}
+
+	if !required {
+		return "*" + goType, nil
+	}
+	return goType, nil
+}
+
+// pathExpression turns "/exports/{id}/download" into a Go string expression with escaped parameters.
+func pathExpression(path string, params map[string]string) string {
+	var parts []string
+	for path != "" {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			parts = append(parts, fmt.Sprintf("%q", path))
+			break
+		}
+		end := strings.Index(path[start:], "}") + start
+
+		if start > 0 {
+			parts = append(parts, fmt.Sprintf("%q", path[:start]))
+		}
+		parts = append(parts, fmt.Sprintf("url.PathEscape(%s)", params[path[start+1:end]]))
+		path = path[end+1:]
+	}
+	return strings.Join(parts, " + ")
+}
+
+// exportedName converts snake_case, kebab-case and camelCase names to an exported Go identifier.
+func exportedName(name string) string {
+	var words []string
+	word := []rune{}
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '-' || r == ' ' || r == '.' || r == '[' || r == ']':
+			flush()
+		case unicode.IsUpper(r) && i > 0:
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		lower := strings.ToLower(w)
+		if initialisms[lower] {
+			b.WriteString(strings.ToUpper(lower))
+			continue
+		}
+		b.WriteString(strings.ToUpper(lower[:1]) + lower[1:])
+	}
+	return b.String()
+}
+
+func unexportedName(name string) string {
+	exported := exportedName(name)
+	if initialisms[strings.ToLower(exported)] {
+		return strings.ToLower(exported)
+	}
+	return strings.ToLower(exported[:1]) + exported[1:]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
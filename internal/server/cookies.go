@@ -0,0 +1,50 @@
+package server
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/securecookie"
+)
+
+// newCookieCodec builds the encrypted cookie codec shared by the session and CSRF
+// cookies from the cookies config, on top of the defaults for the environment.
+func newCookieCodec(cfg *config.Config) (*securecookie.Codec, error) {
+	keys, err := securecookie.ParseKeys(cfg.Cookies.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		// Domain-separated, so the cookie key never equals the auth secret itself.
+		key := sha256.Sum256([]byte("securecookie:" + cfg.Auth.SecretKey))
+		keys = [][]byte{key[:]}
+	}
+
+	options := securecookie.DefaultOptions(cfg.Primary.Env)
+	options.Domain = cfg.Cookies.Domain
+
+	if cfg.Cookies.Secure != nil {
+		options.Secure = *cfg.Cookies.Secure
+	}
+
+	switch cfg.Cookies.SameSite {
+	case "strict":
+		options.SameSite = http.SameSiteStrictMode
+	case "none":
+		options.SameSite = http.SameSiteNoneMode
+	}
+
+	if cfg.Cookies.MaxAge > 0 {
+		options.MaxAge = cfg.Cookies.MaxAge
+	}
+
+	codec, err := securecookie.NewCodec(keys, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cookie codec: %w", err)
+	}
+
+	return codec, nil
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/metrics"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// startHealthProber starts probing the dependencies in the background, publishing the
+// same checks /health runs as metrics and events (see metrics.HealthProber).
+func (s *Server) startHealthProber(newRelicApp *newrelic.Application) {
+	s.HealthProber = metrics.NewHealthProber(s.Config.Observability.HealthCheck, newRelicApp, s.Logger)
+
+	s.HealthProber.Register(metrics.HealthCheckDatabase, s.DB.Pool.Ping)
+
+	if s.Redis != nil {
+		s.HealthProber.Register(metrics.HealthCheckRedis, func(ctx context.Context) error {
+			return s.Redis.Ping(ctx).Err()
+		})
+	}
+
+	s.HealthProber.Register(metrics.HealthCheckServer, s.startupReady)
+
+	s.HealthProber.Start()
+}
+
+// startupReady reports whether the instance finished starting up, as /internal/ready
+// does, minus draining: an instance shutting down is no outage.
+func (s *Server) startupReady(context.Context) error {
+	if s.Migrations != nil && !s.Migrations.Done() {
+		return errors.New("startup migration is running")
+	}
+
+	if s.Config.Cache.Warmup.GateReadiness && s.Warmer != nil && !s.Warmer.Ready() {
+		return errors.New("critical caches are warming up")
+	}
+
+	return nil
+}
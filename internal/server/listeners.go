@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+)
+
+// listenerKey holds the name of the listener a connection was accepted on.
+type listenerKey struct{}
+
+// ListenerFromContext returns the name of the listener the request came in on, e.g.
+// config.DefaultListener for the one on the server port.
+func ListenerFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(listenerKey{}).(string)
+	return name
+}
+
+// withListener stores the name of the listener a connection was accepted on, for
+// http.Server.ConnContext.
+func withListener(ctx context.Context, conn net.Conn) context.Context {
+	// TLS connections wrap the accepted one
+	if wrapper, ok := conn.(interface{ NetConn() net.Conn }); ok {
+		conn = wrapper.NetConn()
+	}
+	if named, ok := conn.(*namedConn); ok {
+		return context.WithValue(ctx, listenerKey{}, named.listener)
+	}
+	return ctx
+}
+
+// namedListener tags its connections with its name.
+type namedListener struct {
+	net.Listener
+	name string
+	cfg  config.ListenerConfig
+}
+
+func (l *namedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &namedConn{Conn: conn, listener: l.name}, nil
+}
+
+type namedConn struct {
+	net.Conn
+	listener string
+}
+
+// openListeners binds every configured listener, in name order. On failure the ones
+// already bound are closed.
+func openListeners(cfg *config.ServerConfig) ([]*namedListener, error) {
+	configured := cfg.GetListeners()
+
+	names := make([]string, 0, len(configured))
+	for name := range configured {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	listeners := make([]*namedListener, 0, len(names))
+	for _, name := range names {
+		listener, err := listen(configured[name])
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("failed to listen on %s (%s): %w", name, configured[name].Address, err)
+		}
+		listeners = append(listeners, &namedListener{Listener: listener, name: name, cfg: configured[name]})
+	}
+
+	return listeners, nil
+}
+
+func listen(cfg config.ListenerConfig) (net.Listener, error) {
+	if !cfg.IsUnix() {
+		return net.Listen("tcp", cfg.Address)
+	}
+
+	// A socket left behind by a crashed process would fail the bind
+	if err := os.Remove(cfg.Address); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validated with the config
+	mode, _ := strconv.ParseUint(cfg.SocketMode, 8, 32)
+	if err := os.Chmod(cfg.Address, fs.FileMode(mode)); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+
+	return listener, nil
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/notify"
+)
+
+// newNotificationChannels builds the configured SMS and push channels, either is nil
+// when none of its providers is configured.
+func newNotificationChannels(cfg config.NotificationsConfig) (notify.Channel, *notify.Push, error) {
+	var sms notify.Channel
+	if cfg.SMS.Enabled() {
+		twilio, err := notify.NewTwilio(notify.TwilioOptions{
+			AccountSID:          cfg.SMS.AccountSID,
+			AuthToken:           cfg.SMS.AuthToken,
+			From:                cfg.SMS.From,
+			MessagingServiceSID: cfg.SMS.MessagingServiceSID,
+			BaseURL:             cfg.SMS.APIBaseURL,
+			Timeout:             cfg.Timeout,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize sms channel: %w", err)
+		}
+		sms = twilio
+	}
+
+	if !cfg.Push.Enabled() {
+		return sms, nil, nil
+	}
+
+	var fcm *notify.FCM
+	if cfg.Push.FCM.Enabled() {
+		credentials, err := secretOrFile(cfg.Push.FCM.CredentialsJSON, cfg.Push.FCM.CredentialsFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read fcm credentials: %w", err)
+		}
+		fcm, err = notify.NewFCM(credentials, cfg.Push.FCM.APIBaseURL, cfg.Timeout)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize fcm: %w", err)
+		}
+	}
+
+	var apns *notify.APNs
+	if cfg.Push.APNs.Enabled() {
+		key, err := secretOrFile(cfg.Push.APNs.PrivateKey, cfg.Push.APNs.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read apns private key: %w", err)
+		}
+		apns, err = notify.NewAPNs(notify.APNsOptions{
+			TeamID:     cfg.Push.APNs.TeamID,
+			KeyID:      cfg.Push.APNs.KeyID,
+			PrivateKey: key,
+			Topic:      cfg.Push.APNs.Topic,
+			Sandbox:    cfg.Push.APNs.Sandbox,
+			BaseURL:    cfg.Push.APNs.APIBaseURL,
+			Timeout:    cfg.Timeout,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize apns: %w", err)
+		}
+	}
+
+	return sms, notify.NewPush(fcm, apns), nil
+}
+
+// secretOrFile returns the inline value, or the contents of path without one.
+func secretOrFile(value, path string) ([]byte, error) {
+	if value != "" {
+		return []byte(value), nil
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/rs/zerolog"
+)
+
+// newEventEmitter builds the emitter of observe.Event for the configured exporter. Without
+// a New Relic application the "newrelic" exporter discards events, as before the facade.
+func newEventEmitter(cfg *config.MonitoringConfig, newRelicApp *newrelic.Application, logger *zerolog.Logger) *observe.Emitter {
+	var exporter observe.Exporter
+	switch cfg.Events.Exporter {
+	case "otlp":
+		exporter = observe.NewOTLPExporter(cfg.Events.OTLPEndpoint, cfg.Events.OTLPHeaders, cfg.ServiceName, cfg.Environment, cfg.Events.FlushInterval)
+	case "newrelic":
+		if newRelicApp != nil {
+			exporter = observe.NewNewRelicExporter(newRelicApp)
+		}
+	}
+	if exporter == nil {
+		exporter = observe.NopExporter{}
+	}
+
+	return observe.NewEmitter(exporter, observe.Options{
+		BufferSize:    cfg.Events.BufferSize,
+		BatchSize:     cfg.Events.BatchSize,
+		FlushInterval: cfg.Events.FlushInterval,
+		MaxPerSecond:  cfg.Events.MaxPerSecond,
+		SampleRate:    cfg.Events.SampleRate,
+	}, logger)
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/oidc"
+	"github.com/rs/zerolog"
+)
+
+// newOIDCVerifier builds the verifier of user tokens for the oidc auth provider, nil
+// with any other provider. The provider's keys are fetched right away, but an
+// unreachable provider doesn't block startup, keys are fetched again on the first request.
+func newOIDCVerifier(cfg config.AuthConfig, logger *zerolog.Logger) (*oidc.Verifier, error) {
+	if cfg.Provider != config.AuthProviderOIDC {
+		return nil, nil
+	}
+
+	verifier, err := oidc.NewVerifier(oidc.Options{
+		Issuer:          cfg.OIDC.Issuer,
+		JWKSURL:         cfg.OIDC.JWKSURL,
+		Audiences:       cfg.OIDC.Audiences,
+		Algorithms:      cfg.OIDC.Algorithms,
+		RefreshInterval: cfg.OIDC.RefreshInterval,
+		RefreshCooldown: cfg.OIDC.RefreshCooldown,
+		Leeway:          cfg.OIDC.Leeway,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid oidc auth config: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := verifier.Refresh(ctx); err != nil {
+		logger.Error().Err(err).Str("issuer", cfg.OIDC.Issuer).Msg("Failed to fetch OIDC provider keys, continuing without them")
+	}
+
+	return verifier, nil
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/email"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/selfcheck"
+)
+
+// redisLatencyWarnThreshold is the ping round trip above which Redis is reported as slow.
+const redisLatencyWarnThreshold = 50 * time.Millisecond
+
+// SelfCheck verifies the server's configuration and dependencies and returns a report.
+// It never fails by itself; the caller decides what to do with critical failures.
+func (s *Server) SelfCheck(ctx context.Context) *selfcheck.Report {
+	checks := []selfcheck.Check{
+		{Name: "config", Critical: true, Run: s.checkConfig},
+		{Name: "database", Critical: true, Run: s.checkDatabase},
+		{Name: "migrations", Critical: true, Run: s.checkMigrations},
+		{Name: "redis", Run: s.checkRedis},
+		{Name: "new_relic", Run: s.checkNewRelic},
+		{Name: "email_provider", Run: s.checkEmailProvider},
+		{Name: "email_templates", Critical: true, Run: s.checkEmailTemplates},
+	}
+
+	return selfcheck.Run(ctx, s.Config.SelfCheck.Timeout, checks)
+}
+
+func (s *Server) isDevelopment() bool {
+	return s.Config.Primary.Env == "local" || s.Config.Primary.Env == "development"
+}
+
+func (s *Server) checkConfig(ctx context.Context) selfcheck.Result {
+	if _, err := strconv.Atoi(s.Config.Server.Port); s.Config.Server.Port != "" && err != nil {
+		return selfcheck.Fail(fmt.Errorf("server port %q is not a number", s.Config.Server.Port))
+	}
+
+	var warnings []string
+
+	if s.Config.Server.BaseURL == "" {
+		warnings = append(warnings, "server base_url is not set, absolute links can't be built")
+	}
+
+	if !s.isDevelopment() {
+		for _, origin := range s.Config.Server.CORSAllowedOrigins {
+			if origin == "*" {
+				warnings = append(warnings, "CORS allows every origin")
+			}
+		}
+
+		if s.Config.Auth.URLSigningKey == "" {
+			warnings = append(warnings, "auth url_signing_key is not set, URLs are signed with the secret key")
+		}
+
+		if s.Config.Observability.NewRelic.LicenseKey == "" {
+			warnings = append(warnings, "new relic license key is not set, monitoring is disabled")
+		}
+	}
+
+	if s.Config.Quota.Enabled && s.Config.Quota.DailyLimit == 0 && s.Config.Quota.MonthlyLimit == 0 {
+		warnings = append(warnings, "quotas are enabled without any limit")
+	}
+
+	details := map[string]any{"env": s.Config.Primary.Env}
+	if len(warnings) > 0 {
+		details["warnings"] = warnings
+		return selfcheck.Warn("configuration has warnings", details)
+	}
+
+	return selfcheck.OK("configuration is sane", details)
+}
+
+func (s *Server) checkDatabase(ctx context.Context) selfcheck.Result {
+	start := time.Now()
+
+	var version string
+	if err := s.DB.Pool.QueryRow(ctx, "SHOW server_version").Scan(&version); err != nil {
+		return selfcheck.Fail(fmt.Errorf("failed to query database version: %w", err))
+	}
+
+	return selfcheck.OK("database is reachable", map[string]any{
+		"version": version,
+		"latency": time.Since(start).String(),
+	})
+}
+
+func (s *Server) checkMigrations(ctx context.Context) selfcheck.Result {
+	conn, err := s.DB.Pool.Acquire(ctx)
+	if err != nil {
+		return selfcheck.Fail(fmt.Errorf("failed to acquire connection: %w", err))
+	}
+	defer conn.Release()
+
+	current, latest, err := database.MigrationStatus(ctx, conn.Conn())
+	if err != nil {
+		return selfcheck.Fail(err)
+	}
+
+	details := map[string]any{"current": current, "latest": latest}
+
+	switch {
+	case current < latest && s.isDevelopment():
+		return selfcheck.Warn(fmt.Sprintf("%d migrations pending", latest-current), details)
+	case current < latest:
+		return selfcheck.Fail(fmt.Errorf("%d migrations pending, schema is at version %d of %d", latest-current, current, latest))
+	case current > latest:
+		return selfcheck.Warn("database schema is newer than this build", details)
+	}
+
+	return selfcheck.OK("database schema is up to date", details)
+}
+
+func (s *Server) checkRedis(ctx context.Context) selfcheck.Result {
+	start := time.Now()
+	if err := s.Redis.Ping(ctx).Err(); err != nil {
+		return selfcheck.Fail(fmt.Errorf("failed to ping redis: %w", err))
+	}
+	latency := time.Since(start)
+
+	details := map[string]any{"latency": latency.String()}
+	if latency > redisLatencyWarnThreshold {
+		return selfcheck.Warn("redis is slow to respond", details)
+	}
+
+	return selfcheck.OK("redis is reachable", details)
+}
+
+func (s *Server) checkNewRelic(ctx context.Context) selfcheck.Result {
+	if s.LoggerService == nil || s.LoggerService.GetNewRelicApp() == nil {
+		return selfcheck.Warn("new relic is disabled", nil)
+	}
+
+	timeout := s.Config.SelfCheck.Timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	if err := s.LoggerService.GetNewRelicApp().WaitForConnection(timeout); err != nil {
+		return selfcheck.Fail(fmt.Errorf("new relic did not connect: %w", err))
+	}
+
+	return selfcheck.OK("new relic is connected", nil)
+}
+
+func (s *Server) checkEmailProvider(ctx context.Context) selfcheck.Result {
+	if s.Config.Integration.ResendAPIKey == "" {
+		return selfcheck.Fail(errors.New("resend api key is not set"))
+	}
+
+	if err := email.NewClient(s.Config, s.Logger).VerifyAPIKey(ctx); err != nil {
+		return selfcheck.Fail(err)
+	}
+
+	return selfcheck.OK("email provider accepted the api key", nil)
+}
+
+func (s *Server) checkEmailTemplates(ctx context.Context) selfcheck.Result {
+	if err := email.NewClient(s.Config, s.Logger).CheckTemplates(); err != nil {
+		return selfcheck.Fail(err)
+	}
+
+	return selfcheck.OK("email templates parsed", map[string]any{"templates": len(email.Templates)})
+}
@@ -9,9 +9,39 @@ import (
 
 	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/assets"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/authz"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/bruteforce"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/cache"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/captcha"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/certreload"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/clock"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/drain"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/emailverify"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/events"
 	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/metrics"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/notify"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/oidc"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/payments"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/progress"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/quota"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/ratelimit"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/routeaccess"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/routetoggle"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/securecookie"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/sendrate"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/serializer"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/settings"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/svcauth"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/tenantlimits"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/warmup"
 	loggerPackage "github.com/Barry-dE/go-backend-boilerplate/internal/logger"
+	"github.com/Barry-dE/go-backend-boilerplate/static"
 	newRelicRedis "github.com/newrelic/go-agent/v3/integrations/nrredis-v9"
+	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 )
@@ -19,17 +49,113 @@ import (
 // Server holds all dependencies and services used by the application.
 type Server struct {
 	Config        *config.Config
+	Clock         clock.Clock
 	DB            *database.Database
+	TxManager     *database.TxManager
+	Events        *events.Bus
 	Logger        *zerolog.Logger
 	LoggerService *loggerPackage.LoggerService
 	Redis         *redis.Client
+	// Keys namespaces the Redis keys of every component, stores take their space from it.
+	Keys          keys.Namespace
 	httpServer    *http.Server
 	Job           *job.JobService
+	PoolCollector *metrics.PoolCollector
+	HealthProber  *metrics.HealthProber
+	Quota         *quota.Tracker
+	RateLimiter   *ratelimit.Limiter
+	// Observer exports the events of observe.Event, it is the default emitter.
+	Observer *observe.Emitter
+	// Cache keeps hot lookups in memory in front of Redis, invalidated across instances.
+	Cache        *cache.Cache
+	TenantLimits *tenantlimits.Resolver
+	// Settings are the runtime settings operators change through the admin API.
+	Settings *settings.Store
+	// Verification tells whether users verified their email, see RequireVerifiedEmail.
+	Verification *emailverify.Checker
+	// RouteToggles are the routes operators disabled through the admin API.
+	RouteToggles *routetoggle.Store
+	// RouteAccess is who may call each route, as the router declared it.
+	RouteAccess *routeaccess.Registry
+	Drain       *drain.Tracker
+	// Migrations is the progress of the startup migration, nil if it wasn't tracked.
+	Migrations *database.MigrationProgress
+	Cookies    *securecookie.Codec
+	// Serializers encode response bodies in the format negotiated from the Accept header.
+	Serializers *serializer.Registry
+	// Assets are the static files embedded into the binary, served under /static.
+	Assets *assets.Set
+	// Captcha is nil unless a CAPTCHA provider is configured.
+	Captcha *captcha.Verifier
+	// ServiceTokens mints tokens for calls to other services, ServiceAuth verifies
+	// the tokens of incoming calls. Both are nil unless service auth is configured.
+	ServiceTokens *svcauth.Signer
+	ServiceAuth   *svcauth.Verifier
+	// OIDC verifies user tokens with the oidc auth provider, nil with Clerk.
+	OIDC *oidc.Verifier
+	// BruteForce counts failed authentication attempts, nil if the protection is disabled.
+	BruteForce *bruteforce.Guard
+	// Authz evaluates authorization policies, which the authz service loads and reloads.
+	Authz *authz.Engine
+	// Progress tracks long running operations, e.g. async exports, for clients to follow live.
+	Progress *progress.Tracker
+	// Warmer primes caches at startup, components register their primers while being wired up.
+	Warmer *warmup.Warmer
+	// EmailThrottle keeps email jobs within the sending limits of the email provider.
+	EmailThrottle *sendrate.Throttle
+	// Payments is nil unless a payments provider is configured.
+	Payments payments.Provider
+	// SMS and Push are nil unless one of their providers is configured.
+	SMS           notify.Channel
+	Push          *notify.Push
+	shutdownHooks []func(ctx context.Context)
+	certificates  *certreload.Reloader
 }
 
 // New creates and initializes a new Server instance.
 func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerPackage.LoggerService) (*Server, error) {
 
+	// Encrypted cookies, fail early on malformed keys rather than on the first request.
+	cookies, err := newCookieCodec(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var captchaVerifier *captcha.Verifier
+	if cfg.Captcha.Enabled() {
+		captchaVerifier, err = captcha.NewVerifier(captcha.Provider(cfg.Captcha.Provider), cfg.Captcha.Secret, cfg.Captcha.MinScore, cfg.Captcha.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize captcha verifier: %w", err)
+		}
+	}
+
+	var paymentsProvider payments.Provider
+	if cfg.Payments.Enabled() {
+		paymentsProvider, err = payments.New(cfg.Payments.Provider, payments.Options{
+			SecretKey: cfg.Payments.SecretKey,
+			BaseURL:   cfg.Payments.APIBaseURL,
+			Timeout:   cfg.Payments.Timeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize payments provider: %w", err)
+		}
+	}
+
+	smsChannel, pushChannel, err := newNotificationChannels(cfg.Notifications)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceTokens, serviceAuth, err := newServiceAuth(cfg.ServiceAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	oidcVerifier, err := newOIDCVerifier(cfg.Auth, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize the database connection pool.
 	db, err := database.NewDatabaseConnectionPool(cfg, logger, loggerService)
 	if err != nil {
@@ -54,37 +180,154 @@ func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerPackag
 		logger.Error().Err(err).Msg("Failed to connect to Redis, continuing without Redis")
 	}
 
+	var newRelicApp *newrelic.Application
+	if loggerService != nil {
+		newRelicApp = loggerService.GetNewRelicApp()
+	}
+
+	// Structured events go out through the configured exporter from here on, job
+	// handlers started below already emit them.
+	observer := newEventEmitter(cfg.Observability, newRelicApp, logger)
+	observer.Start()
+	observe.SetDefault(observer)
+
+	// Time-based decisions go through one clock, so tests can control it.
+	clk := clock.Real
+
 	// Initialize the background job service.
-	jobService := job.NewJobService(logger, cfg)
-	jobService.InitHandlers(cfg, logger)
+	jobService, err := job.NewJobService(logger, cfg, newRelicApp, db.Pool, clk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize job service: %w", err)
+	}
+
+	// Every Redis key is namespaced by app and environment.
+	redisKeys := keys.New(cfg.Redis.App, cfg.Primary.Env)
+
+	// Email jobs on every instance share one token bucket per provider.
+	sendRates := make(map[string]sendrate.Limit, len(cfg.Email.SendRates))
+	for provider, rate := range cfg.Email.SendRates {
+		sendRates[provider] = sendrate.Limit{PerSecond: rate.PerSecond, Burst: rate.Burst}
+	}
+	emailThrottle := sendrate.NewThrottle(redisClient, redisKeys.Space(keys.SendRate), sendRates, cfg.Email.ThrottleMaxWait, newRelicApp)
+
+	jobService.InitHandlers(cfg, logger, emailThrottle)
 
 	// Start the job service and return an error if it fails.
 	if err := jobService.Start(); err != nil {
 		return nil, err
 	}
 
+	// Two-tier cache for hot lookups, the local tier is used once invalidations are subscribed.
+	hotCache := cache.New(redisClient, redisKeys.Space(keys.Cache), cache.Options{
+		LocalSize: cfg.Cache.LocalSize,
+		LocalTTL:  cfg.Cache.LocalTTL,
+		Clock:     clk,
+	}, logger)
+	hotCache.Start()
+
+	// Periodically collect database and Redis pool stats.
+	poolCollector := metrics.NewPoolCollector(cfg.Observability.Metrics, db.Pool, redisClient, newRelicApp, logger)
+	poolCollector.Start()
+
+	defaultQuota := quota.Limits{
+		Daily:   cfg.Quota.DailyLimit,
+		Monthly: cfg.Quota.MonthlyLimit,
+	}
+
+	var bruteForce *bruteforce.Guard
+	if !cfg.BruteForce.Disabled {
+		bruteForce = bruteforce.NewGuard(redisClient, redisKeys.Space(keys.BruteForce), bruteforce.Policy{
+			Window:           cfg.BruteForce.Window,
+			DelayAfter:       cfg.BruteForce.DelayAfter,
+			BaseDelay:        cfg.BruteForce.BaseDelay,
+			MaxDelay:         cfg.BruteForce.MaxDelay,
+			LockoutThreshold: cfg.BruteForce.LockoutThreshold,
+			LockoutDuration:  cfg.BruteForce.LockoutDuration,
+		})
+	}
+
+	staticAssets, err := assets.Load(static.FS, "/static")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load static assets: %w", err)
+	}
+
 	// Assemble the server with all initialized components.
 	server := &Server{
 		Config:        cfg,
+		Clock:         clk,
 		DB:            db,
+		TxManager:     database.NewTxManager(db),
+		Events:        events.NewBus(logger),
 		Logger:        logger,
 		LoggerService: loggerService,
 		Redis:         redisClient,
+		Keys:          redisKeys,
 		Job:           jobService,
+		PoolCollector: poolCollector,
+		Observer:      observer,
+		Quota:         quota.NewTracker(redisClient, redisKeys.Space(keys.Quota), defaultQuota),
+		RateLimiter:   ratelimit.NewLimiter(redisClient, redisKeys.Space(keys.RateLimit), time.Minute, clk),
+		Cache:         hotCache,
+		TenantLimits: tenantlimits.NewResolver(hotCache, tenantlimits.Limits{
+			RequestsPerMinute: cfg.RateLimit.TenantRequestsPerMinute,
+			Quota:             defaultQuota,
+		}, cfg.RateLimit.OverrideCacheTTL),
+		Settings:      settings.NewStore(hotCache),
+		Verification:  emailverify.NewChecker(hotCache, 0),
+		RouteToggles:  routetoggle.NewStore(redisClient, redisKeys.Space(keys.RouteToggles), clk, logger),
+		RouteAccess:   routeaccess.NewRegistry(),
+		Drain:         drain.NewTracker(),
+		Cookies:       cookies,
+		Serializers:   serializer.NewDefaultRegistry(),
+		Captcha:       captchaVerifier,
+		ServiceTokens: serviceTokens,
+		ServiceAuth:   serviceAuth,
+		OIDC:          oidcVerifier,
+		BruteForce:    bruteForce,
+		Authz:         authz.NewEngine(logger),
+		Payments:      paymentsProvider,
+		SMS:           smsChannel,
+		Push:          pushChannel,
+		Assets:        staticAssets,
+		Progress:      progress.NewTracker(redisClient, redisKeys.Space(keys.Progress)),
+		EmailThrottle: emailThrottle,
+		Warmer:        warmup.New(logger, cfg.Cache.Warmup.Timeout),
 	}
 
+	// The default rate limit and quotas follow config reloads, stored overrides still apply on top
+	config.OnChange(func(next *config.Config) {
+		server.TenantLimits.SetDefaults(tenantlimits.Limits{
+			RequestsPerMinute: next.RateLimit.TenantRequestsPerMinute,
+			Quota:             quota.Limits{Daily: next.Quota.DailyLimit, Monthly: next.Quota.MonthlyLimit},
+		})
+	})
+
+	if cfg.Server.TLS.Enabled {
+		if err := server.setupTLS(); err != nil {
+			return nil, err
+		}
+	}
+
+	server.RouteToggles.Start(routetoggle.RefreshInterval)
+	server.startHealthProber(newRelicApp)
+
 	return server, nil
 }
 
 // ConfigureHTTPServer sets up the HTTP server with the provided handler and configuration values.
-// It applies timeouts and port settings from the server configuration.
+// It applies timeouts from the server configuration, requests carry the name of the
+// listener they came in on (see ListenerFromContext).
 func (s *Server) ConfigureHTTPServer(handler http.Handler) {
 	s.httpServer = &http.Server{
-		Addr:         ":" + s.Config.Server.Port,
 		Handler:      handler,
 		ReadTimeout:  time.Duration(s.Config.Server.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(s.Config.Server.WriteTimeout) * time.Second,
 		IdleTimeout:  time.Duration(s.Config.Server.IdleTimeout) * time.Second,
+		ConnContext:  withListener,
+	}
+
+	if s.certificates != nil {
+		s.httpServer.TLSConfig = s.tlsConfig()
 	}
 }
 
@@ -94,26 +337,91 @@ func (s *Server) Start() error {
 		return errors.New(("http server not configured, call ConfigureHTTPServer first"))
 	}
 
-	// Log that the server is starting, including environment and port info.
-	s.Logger.Info().Str("port", s.Config.Server.Port).Str("env", s.Config.Primary.Env).Msg("Starting HTTP server")
+	listeners, err := openListeners(&s.Config.Server)
+	if err != nil {
+		return err
+	}
+
+	// Every listener is served until Shutdown, the first to fail stops the server.
+	errs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		// TLS is terminated by the proxy on the other end of a Unix socket.
+		useTLS := s.certificates != nil && !listener.cfg.IsUnix()
 
-	return s.httpServer.ListenAndServe()
+		// Log that the server is starting, including environment and address info.
+		s.Logger.Info().Str("listener", listener.name).Str("address", listener.cfg.Address).Str("env", s.Config.Primary.Env).Bool("tls", useTLS).Msg("Starting HTTP server")
+
+		go func() {
+			// Certificates come from TLSConfig.GetCertificate, so they can be rotated without a restart.
+			if useTLS {
+				errs <- s.httpServer.ServeTLS(listener, "", "")
+				return
+			}
+			errs <- s.httpServer.Serve(listener)
+		}()
+	}
+
+	return <-errs
+}
+
+// OnShutdown registers a function run during Shutdown, after the HTTP server stopped
+// but before the database and job server are closed. Components with background
+// work (tickers, flushers) use it to stop cleanly.
+func (s *Server) OnShutdown(hook func(ctx context.Context)) {
+	s.shutdownHooks = append(s.shutdownHooks, hook)
 }
 
 // Shutdown gracefully stops the server and cleans up resources.
 func (s *Server) Shutdown(ctx context.Context) error {
+	// Report not ready right away in case no preStop hook ran.
+	s.Drain.StartDraining()
+
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown http server: %w", err)
 	}
 
-	if err := s.DB.Close(); err != nil {
-		return fmt.Errorf("failed to close database connection: %w", err)
+	// Run hooks in reverse registration order, like deferred calls.
+	for i := len(s.shutdownHooks) - 1; i >= 0; i-- {
+		s.shutdownHooks[i](ctx)
 	}
 
-	// Stop any running background jobs if present.
+	// Primers still running would only fail once the pools are closed.
+	if s.Warmer != nil {
+		s.Warmer.Stop()
+	}
+
+	// Stop any running background jobs if present, before the pools and caches their
+	// handlers use. The postgres backend shares the database pool: in-flight tasks
+	// complete, fail or are put back through it.
 	if s.Job != nil {
 		s.Job.Stop()
 	}
 
+	// Stop collecting pool stats and probing before the pools go away.
+	if s.PoolCollector != nil {
+		s.PoolCollector.Stop()
+	}
+
+	if s.HealthProber != nil {
+		s.HealthProber.Stop()
+	}
+
+	if s.RouteToggles != nil {
+		s.RouteToggles.Stop()
+	}
+
+	if s.Cache != nil {
+		s.Cache.Stop()
+	}
+
+	if err := s.DB.Close(); err != nil {
+		return fmt.Errorf("failed to close database connection: %w", err)
+	}
+
+	// Export the events recorded until now, jobs included.
+	if s.Observer != nil {
+		s.Observer.Shutdown(ctx)
+	}
+
 	return nil
 }
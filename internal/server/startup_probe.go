@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/rs/zerolog"
+)
+
+// StartupProbe answers on the server's listeners while the database is migrated at
+// startup, before the application is built. Every request, the readiness probe included,
+// gets a 503 "starting" with the migration progress, so orchestrators don't route traffic
+// to a half-migrated instance and operators can see which migration is being applied.
+type StartupProbe struct {
+	httpServer *http.Server
+	logger     *zerolog.Logger
+}
+
+// StartStartupProbe starts listening on the configured listeners. Stop it before the
+// application server starts, it frees them.
+func StartStartupProbe(cfg *config.Config, logger *zerolog.Logger, progress *database.MigrationProgress) *StartupProbe {
+	probe := &StartupProbe{
+		httpServer: &http.Server{
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", "5")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"status":    "starting",
+					"migration": progress.State(),
+				})
+			}),
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 5 * time.Second,
+		},
+		logger: logger,
+	}
+
+	// Probes failing to connect see the instance as not ready too, so startup carries on.
+	listeners, err := openListeners(&cfg.Server)
+	if err != nil {
+		logger.Warn().Err(err).Msg("startup probe server failed")
+		return probe
+	}
+
+	for _, listener := range listeners {
+		go func() {
+			var err error
+			if cfg.Server.TLS.Enabled && !listener.cfg.IsUnix() {
+				err = probe.httpServer.ServeTLS(listener, cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+			} else {
+				err = probe.httpServer.Serve(listener)
+			}
+
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Warn().Err(err).Str("listener", listener.name).Msg("startup probe server failed")
+			}
+		}()
+	}
+
+	return probe
+}
+
+// Stop closes the listener and waits for in-flight probe requests.
+func (p *StartupProbe) Stop(ctx context.Context) {
+	if err := p.httpServer.Shutdown(ctx); err != nil {
+		p.logger.Warn().Err(err).Msg("failed to stop startup probe server")
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/svcauth"
+)
+
+// newServiceAuth builds the signer of outgoing service tokens and the verifier of incoming
+// ones from the service auth config. Either is nil when it isn't configured.
+func newServiceAuth(cfg config.ServiceAuthConfig) (*svcauth.Signer, *svcauth.Verifier, error) {
+	if cfg.Name == "" {
+		return nil, nil, nil
+	}
+
+	var signer *svcauth.Signer
+	if cfg.Secret != "" || cfg.SigningKey != "" {
+		key, err := serviceKey(cfg.Secret, cfg.SigningKey, svcauth.Ed25519SigningKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid service auth signing key: %w", err)
+		}
+
+		signer, err = svcauth.NewSigner(cfg.Name, key, cfg.TokenTTL)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	callers := make(map[string]svcauth.Key, len(cfg.Callers))
+	for name, caller := range cfg.Callers {
+		key, err := serviceKey(caller.Secret, caller.PublicKey, svcauth.Ed25519VerifyingKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid service auth key of caller %s: %w", name, err)
+		}
+		callers[name] = key
+	}
+
+	return signer, svcauth.NewVerifier(cfg.Name, callers, cfg.Leeway), nil
+}
+
+// serviceKey decodes an HMAC secret or, without one, an Ed25519 key built with ed25519Key.
+func serviceKey(secret, ed25519Encoded string, ed25519Key func([]byte) (svcauth.Key, error)) (svcauth.Key, error) {
+	if secret != "" {
+		decoded, err := svcauth.DecodeKey(secret)
+		if err != nil {
+			return svcauth.Key{}, err
+		}
+		return svcauth.HMACKey(decoded)
+	}
+
+	decoded, err := svcauth.DecodeKey(ed25519Encoded)
+	if err != nil {
+		return svcauth.Key{}, err
+	}
+	return ed25519Key(decoded)
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/certreload"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+)
+
+// setupTLS loads the configured certificate and keeps watching its files, renewed
+// certificates are picked up by new connections without restarting the server.
+func (s *Server) setupTLS() error {
+	cfg := s.Config.Server.TLS
+
+	reloader, loaded, err := certreload.NewReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls certificate: %w", err)
+	}
+
+	s.Logger.Info().
+		Str("subject", loaded.Subject).
+		Str("serial", loaded.SerialNumber).
+		Time("not_after", loaded.NotAfter).
+		Msg("loaded tls certificate")
+
+	go reloader.Watch(cfg.ReloadInterval, s.logCertificateReload)
+	s.OnShutdown(func(ctx context.Context) {
+		reloader.Stop()
+	})
+
+	s.certificates = reloader
+	return nil
+}
+
+func (s *Server) logCertificateReload(loaded *certreload.Certificate, err error) {
+	if err != nil {
+		s.Logger.Warn().Err(err).Msg("failed to reload tls certificate, keeping the current one")
+		return
+	}
+
+	s.Logger.Info().
+		Str("subject", loaded.Subject).
+		Str("serial", loaded.SerialNumber).
+		Time("not_after", loaded.NotAfter).
+		Msg("tls certificate rotated")
+
+	observe.Event("TLSCertificateRotated", map[string]any{
+		"subject":   loaded.Subject,
+		"serial":    loaded.SerialNumber,
+		"not_after": loaded.NotAfter.Unix(),
+	})
+}
+
+// tlsConfig serves the certificate currently held by the reloader.
+func (s *Server) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: s.certificates.GetCertificate,
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+)
+
+// AdminService backs the operator endpoints. Every listing uses the shared
+// listing contract (cursor pagination, sort allowlist, filter operators).
+type AdminService struct {
+	server *server.Server
+	repos  *repository.Repositories
+}
+
+func NewAdminService(s *server.Server, repos *repository.Repositories) *AdminService {
+	return &AdminService{
+		server: s,
+		repos:  repos,
+	}
+}
+
+// ListAuditLogs returns a page of the audit trail of every user.
+func (as *AdminService) ListAuditLogs(ctx context.Context, params model.ListParams) (*model.CursorPage[model.AuditLog], error) {
+	entries, info, err := as.repos.Audit.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CursorPage[model.AuditLog]{Items: entries, CursorInfo: info}, nil
+}
+
+// ListConfigChanges returns a page of the change history of runtime settings.
+func (as *AdminService) ListConfigChanges(ctx context.Context, params model.ListParams) (*model.CursorPage[model.AuditLog], error) {
+	entries, info, err := as.repos.Audit.ListConfigChanges(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CursorPage[model.AuditLog]{Items: entries, CursorInfo: info}, nil
+}
+
+// ListEmailSuppressions returns a page of the email suppression list.
+func (as *AdminService) ListEmailSuppressions(ctx context.Context, params model.ListParams) (*model.CursorPage[model.EmailSuppression], error) {
+	suppressions, info, err := as.repos.EmailSuppression.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CursorPage[model.EmailSuppression]{Items: suppressions, CursorInfo: info}, nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/objectstore"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/hibiken/asynq"
+)
+
+const AuditActionAuditLogsArchived = "archive.audit_logs.archived"
+
+// ArchiveService moves audit logs older than the retention period to object storage,
+// one gzip-compressed NDJSON object per UTC day. A day is only deleted from the
+// database once its object was uploaded and read back with the expected size,
+// checksum and row count, so a failed run never loses entries.
+type ArchiveService struct {
+	server *server.Server
+	repos  *repository.Repositories
+	store  *objectstore.Client
+}
+
+// archivedDay is the outcome of archiving one day of audit logs.
+type archivedDay struct {
+	key  string
+	rows int
+	size int
+}
+
+func NewArchiveService(s *server.Server, repos *repository.Repositories) (*ArchiveService, error) {
+	as := &ArchiveService{
+		server: s,
+		repos:  repos,
+	}
+
+	cfg := s.Config.Archive
+	if !cfg.Enabled {
+		return as, nil
+	}
+
+	store, err := objectstore.NewClient(cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive storage client: %w", err)
+	}
+	as.store = store
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskAuditArchive, as.handleAuditArchiveTask)
+
+		if _, err := s.Job.RegisterPeriodic(cfg.Schedule, job.NewAuditArchiveTask()); err != nil {
+			s.Logger.Error().Err(err).Str("schedule", cfg.Schedule).Msg("failed to schedule audit log archival")
+		}
+	}
+
+	return as, nil
+}
+
+// handleAuditArchiveTask archives every day older than the retention period, oldest first,
+// up to MaxDaysPerRun days. It stops at the first failing day so days are archived in order
+// and the task is retried from there.
+func (as *ArchiveService) handleAuditArchiveTask(ctx context.Context, t *asynq.Task) error {
+	cfg := as.server.Config.Archive
+	cutoff := time.Now().UTC().Truncate(24 * time.Hour).Add(-cfg.Retention())
+
+	logger := as.server.Logger.With().Str("type", "audit_archive").Time("cutoff", cutoff).Logger()
+
+	oldest, err := as.repos.Audit.OldestCreatedAt(ctx)
+	if err != nil {
+		return err
+	}
+	if oldest == nil || !oldest.Before(cutoff) {
+		logger.Info().Msg("no audit logs to archive")
+		return nil
+	}
+
+	start := time.Now()
+	var days, rows int
+	for day := oldest.UTC().Truncate(24 * time.Hour); day.Before(cutoff) && days < cfg.MaxDaysPerRun; day = day.Add(24 * time.Hour) {
+		archived, err := as.archiveDay(ctx, day)
+		if err != nil {
+			logger.Error().Err(err).Time("day", day).Msg("failed to archive audit logs")
+			as.recordArchiveRun(days, rows, time.Since(start), err)
+			return err
+		}
+		if archived == nil {
+			continue
+		}
+
+		days++
+		rows += archived.rows
+		logger.Info().Time("day", day).Str("key", archived.key).Int("rows", archived.rows).Int("size_bytes", archived.size).Msg("archived audit logs")
+	}
+
+	logger.Info().Int("days", days).Int("rows", rows).Dur("duration", time.Since(start)).Msg("audit log archival finished")
+	as.recordArchiveRun(days, rows, time.Since(start), nil)
+
+	return nil
+}
+
+// archiveDay exports, uploads, verifies and finally deletes the audit logs of one UTC day.
+// It returns nil without error if the day has no entries.
+func (as *ArchiveService) archiveDay(ctx context.Context, day time.Time) (*archivedDay, error) {
+	until := day.Add(24 * time.Hour)
+
+	entries, err := as.repos.Audit.ListRange(ctx, day, until)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	body, contentHash, err := encodeNDJSON(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit logs of %s: %w", day.Format(time.DateOnly), err)
+	}
+
+	key := as.store.Key("audit_logs", day.Format("2006/01/02")+".ndjson.gz")
+	rowCount := strconv.Itoa(len(entries))
+
+	err = as.store.Put(ctx, key, body, objectstore.PutOptions{
+		ContentType:     "application/x-ndjson",
+		ContentEncoding: "gzip",
+		Metadata: map[string]string{
+			"rows":   rowCount,
+			"sha256": contentHash,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := as.verifyUpload(ctx, key, body, rowCount, contentHash); err != nil {
+		return nil, err
+	}
+
+	tx, err := as.server.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := as.repos.Audit.DeleteRange(ctx, tx, day, until)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries written into the day after the export would be lost, keep everything
+	// and let the next run export the day again.
+	if deleted != int64(len(entries)) {
+		return nil, fmt.Errorf("audit logs of %s changed during archival: exported %d, would delete %d", day.Format(time.DateOnly), len(entries), deleted)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit deletion of archived audit logs: %w", err)
+	}
+
+	resourceID := key
+	entry := &model.AuditLog{
+		ActorID:      model.AuditActorSystem,
+		Action:       AuditActionAuditLogsArchived,
+		ResourceType: "audit_logs",
+		ResourceID:   &resourceID,
+		Metadata: map[string]any{
+			"day":  day.Format(time.DateOnly),
+			"rows": len(entries),
+		},
+	}
+	if err := as.repos.Audit.Create(ctx, entry); err != nil {
+		as.server.Logger.Error().Err(err).Str("action", entry.Action).Msg("failed to record audit log entry")
+	}
+
+	return &archivedDay{key: key, rows: len(entries), size: len(body)}, nil
+}
+
+// verifyUpload reads the object's metadata back and compares it with what was sent.
+func (as *ArchiveService) verifyUpload(ctx context.Context, key string, body []byte, rowCount, contentHash string) error {
+	info, err := as.store.Head(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if info.Size != int64(len(body)) {
+		return fmt.Errorf("archived object %s has size %d, expected %d", key, info.Size, len(body))
+	}
+
+	// Multipart and SSE-KMS ETags aren't an MD5 of the body, the uploaded hash still is checked below.
+	sum := md5.Sum(body)
+	if !strings.Contains(info.ETag, "-") && len(info.ETag) == 32 && info.ETag != hex.EncodeToString(sum[:]) {
+		return fmt.Errorf("archived object %s has etag %s, expected %s", key, info.ETag, hex.EncodeToString(sum[:]))
+	}
+
+	if info.Metadata["rows"] != rowCount || info.Metadata["sha256"] != contentHash {
+		return fmt.Errorf("archived object %s metadata does not match the export", key)
+	}
+
+	return nil
+}
+
+func (as *ArchiveService) recordArchiveRun(days, rows int, duration time.Duration, err error) {
+	attributes := map[string]any{
+		"table":       "audit_logs",
+		"days":        days,
+		"rows":        rows,
+		"duration_ms": duration.Milliseconds(),
+		"success":     err == nil,
+	}
+	if err != nil {
+		attributes["error_message"] = err.Error()
+	}
+
+	observe.Event("AuditArchive", attributes)
+}
+
+// encodeNDJSON writes one JSON document per line and gzips the result.
+// It also returns the hex SHA-256 of the uncompressed content.
+func encodeNDJSON(entries []model.AuditLog) ([]byte, string, error) {
+	var buf bytes.Buffer
+	hash := sha256.New()
+
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(io.MultiWriter(gz, hash))
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), hex.EncodeToString(hash.Sum(nil)), nil
+}
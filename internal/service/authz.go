@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/authz"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/warmup"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/google/uuid"
+)
+
+// Audit actions recorded by the policy engine and when operators change policies.
+const (
+	AuditActionAuthzAllowed       = "authz.allowed"
+	AuditActionAuthzDenied        = "authz.denied"
+	AuditActionAuthzPolicyCreated = "authz.policy.created"
+	AuditActionAuthzPolicyDeleted = "authz.policy.deleted"
+)
+
+// AuthzService manages the authorization policies. It backs the server's policy engine
+// with Postgres, reloading it on every change here and on an interval for changes made
+// through other instances, and writes the engine's decisions to the audit log.
+type AuthzService struct {
+	server      *server.Server
+	repos       *repository.Repositories
+	configAudit *ConfigAuditService
+}
+
+func NewAuthzService(s *server.Server, repos *repository.Repositories, configAudit *ConfigAuditService) *AuthzService {
+	as := &AuthzService{
+		server:      s,
+		repos:       repos,
+		configAudit: configAudit,
+	}
+
+	if s.Authz != nil {
+		s.Authz.SetLoader(as.loadPolicies)
+		s.Authz.OnDecision(as.recordDecision)
+
+		// Everything is denied until the policies are loaded, by this or the next reload
+		s.Warmer.Register(warmup.Primer{
+			Name:     "authz_policies",
+			Critical: true,
+			Prime:    s.Authz.Reload,
+		})
+
+		s.Authz.Start(s.Config.Authz.ReloadInterval)
+		s.OnShutdown(func(ctx context.Context) {
+			s.Authz.Stop()
+		})
+	}
+
+	return as
+}
+
+// ListPolicies returns every authorization policy.
+func (as *AuthzService) ListPolicies(ctx context.Context) ([]model.Policy, error) {
+	return as.repos.Authz.ListPolicies(ctx)
+}
+
+// CreatePolicy adds a policy, it applies right away on this instance and within the
+// reload interval on the others.
+func (as *AuthzService) CreatePolicy(ctx context.Context, payload *model.CreatePolicyPayload) (*model.Policy, error) {
+	actorID := requestctx.UserID(ctx)
+
+	candidate := authz.Policy{
+		Subject:  payload.Subject,
+		Action:   payload.Action,
+		Resource: payload.Resource,
+		Effect:   string(payload.Effect),
+	}
+	if err := candidate.Validate(); err != nil {
+		return nil, errs.BadRequestError("Invalid policy", false, nil, []errs.FieldError{
+			{Field: "subject", Error: "must be *, user:<id>, role:<role> or org:<id>"},
+		}, nil)
+	}
+
+	policy, err := as.repos.Authz.CreatePolicy(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	as.reload(ctx)
+	as.recordChange(ctx, actorID, AuditActionAuthzPolicyCreated, policy.ID, nil, policy)
+
+	return policy, nil
+}
+
+// DeletePolicy removes a policy.
+func (as *AuthzService) DeletePolicy(ctx context.Context, id uuid.UUID) error {
+	actorID := requestctx.UserID(ctx)
+
+	previous, err := as.repos.Authz.GetPolicy(ctx, id)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return errs.NotFoundError("Policy not found", false, nil)
+	}
+
+	deleted, err := as.repos.Authz.DeletePolicy(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errs.NotFoundError("Policy not found", false, nil)
+	}
+
+	as.reload(ctx)
+	as.recordChange(ctx, actorID, AuditActionAuthzPolicyDeleted, id, previous, nil)
+
+	return nil
+}
+
+// loadPolicies is the policy engine's loader.
+func (as *AuthzService) loadPolicies(ctx context.Context) ([]authz.Policy, error) {
+	stored, err := as.repos.Authz.ListPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]authz.Policy, len(stored))
+	for i, policy := range stored {
+		policies[i] = authz.Policy{
+			ID:       policy.ID.String(),
+			Subject:  policy.Subject,
+			Action:   policy.Action,
+			Resource: policy.Resource,
+			Effect:   string(policy.Effect),
+		}
+	}
+
+	return policies, nil
+}
+
+// reload applies a change to the policies on this instance. On failure the change
+// applies with the next periodic reload.
+func (as *AuthzService) reload(ctx context.Context) {
+	if err := as.server.Authz.Reload(ctx); err != nil {
+		as.server.Logger.Error().Err(err).Dur("reload_interval", as.server.Config.Authz.ReloadInterval).Msg("failed to reload authorization policies")
+	}
+}
+
+// recordDecision writes a decision of the policy engine to the audit log, as configured
+// by authz.log_decisions. Failures are logged only, the decision stands.
+func (as *AuthzService) recordDecision(ctx context.Context, decision authz.Decision) {
+	switch as.server.Config.Authz.LogDecisions {
+	case "none":
+		return
+	case "denied":
+		if decision.Allowed {
+			return
+		}
+	}
+
+	action := AuditActionAuthzDenied
+	if decision.Allowed {
+		action = AuditActionAuthzAllowed
+	}
+
+	actorID := decision.Subject.UserID
+	if actorID == "" {
+		actorID = model.AuditActorSystem
+	}
+
+	metadata := map[string]any{
+		"action":          decision.Action,
+		"role":            decision.Subject.Role,
+		"organization_id": decision.Subject.OrganizationID,
+	}
+	if decision.Policy != nil {
+		metadata["policy_id"] = decision.Policy.ID
+	}
+
+	entry := &model.AuditLog{
+		ActorID:      actorID,
+		Action:       action,
+		ResourceType: model.AuditResourceAuthz,
+		ResourceID:   &decision.Resource,
+		Metadata:     metadata,
+	}
+
+	if requestID := database.RequestIDFromContext(ctx); requestID != "" {
+		entry.RequestID = &requestID
+	}
+
+	// The request may be cancelled right after a denial, the entry is written regardless
+	if err := as.repos.Audit.Create(context.WithoutCancel(ctx), entry); err != nil && !errors.Is(err, context.Canceled) {
+		as.server.Logger.Error().Err(err).Str("action", action).Str("resource", decision.Resource).Msg("failed to record authorization decision")
+	}
+}
+
+// recordChange records a change of the policies in the configuration change history.
+func (as *AuthzService) recordChange(ctx context.Context, actorID, action string, id uuid.UUID, previous, current *model.Policy) {
+	as.configAudit.Record(ctx, ConfigChange{
+		ActorID: actorID,
+		Action:  action,
+		Setting: "authz_policy",
+		Scope:   id.String(),
+		Old:     policyValues(previous),
+		New:     policyValues(current),
+	})
+}
+
+func policyValues(policy *model.Policy) map[string]any {
+	if policy == nil {
+		return nil
+	}
+
+	return map[string]any{
+		"subject":     policy.Subject,
+		"action":      policy.Action,
+		"resource":    policy.Resource,
+		"effect":      policy.Effect,
+		"description": policy.Description,
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/hibiken/asynq"
+)
+
+// BackfillService runs the backfills in database.Backfills, the data migration step of
+// an expand/contract schema change. Each run resumes where the previous one stopped and
+// a backfill is skipped for good once completed.
+type BackfillService struct {
+	server *server.Server
+}
+
+func NewBackfillService(s *server.Server) *BackfillService {
+	bs := &BackfillService{
+		server: s,
+	}
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskBackfill, bs.handleBackfillTask)
+
+		cfg := s.Config.Backfill
+		if _, err := s.Job.RegisterPeriodic(cfg.Schedule, job.NewBackfillTask(cfg.Timeout)); err != nil {
+			s.Logger.Error().Err(err).Str("schedule", cfg.Schedule).Msg("failed to schedule backfills")
+		}
+	}
+
+	return bs
+}
+
+// handleBackfillTask runs every registered backfill. A failing backfill doesn't stop
+// the others, the task is retried if any of them failed.
+func (bs *BackfillService) handleBackfillTask(ctx context.Context, t *asynq.Task) error {
+	var errList []error
+	for _, backfill := range database.Backfills {
+		if err := bs.Run(ctx, backfill); err != nil {
+			bs.server.Logger.Error().Err(err).Str("backfill", backfill.Name).Msg("backfill failed")
+			errList = append(errList, err)
+		}
+	}
+
+	return errors.Join(errList...)
+}
+
+// Run fills batches of the backfill until it completes or ctx ends, progress is saved
+// after every batch so an interrupted run loses nothing.
+func (bs *BackfillService) Run(ctx context.Context, backfill database.Backfill) error {
+	cfg := bs.server.Config.Backfill
+	logger := bs.server.Logger.With().Str("type", "backfill").Str("backfill", backfill.Name).Logger()
+
+	start := time.Now()
+	for {
+		progress, err := bs.server.DB.BackfillBatch(ctx, backfill, cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		if progress.Done() {
+			// Completed by an earlier run, nothing to report.
+			if progress.CompletedAt.Before(start) {
+				return nil
+			}
+
+			logger.Info().Int64("rows_done", progress.RowsDone).Int64("batches", progress.Batches).Dur("duration", time.Since(start)).Msg("backfill completed")
+			bs.recordCompletion(backfill, progress)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info().Int64("rows_done", progress.RowsDone).Msg("backfill paused, the next run resumes it")
+			return nil
+		case <-time.After(cfg.Pause):
+		}
+	}
+}
+
+func (bs *BackfillService) recordCompletion(backfill database.Backfill, progress *database.BackfillProgress) {
+	observe.Event("Backfill", map[string]any{
+		"backfill":  backfill.Name,
+		"table":     backfill.Table,
+		"rows_done": progress.RowsDone,
+		"batches":   progress.Batches,
+	})
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/backup"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/objectstore"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+)
+
+const AuditActionDatabaseBackedUp = "backup.database.created"
+
+// BackupService takes scheduled logical backups of the database. Each run dumps the
+// database with pg_dump, encrypts the dump, uploads it and reads its metadata back,
+// optionally restores it into a scratch database to prove it is usable, and finally
+// deletes backups past the retention period. Outcomes are recorded as events and
+// stored for the health check.
+type BackupService struct {
+	server *server.Server
+	repos  *repository.Repositories
+	store  *objectstore.Client
+	key    []byte
+}
+
+// databaseBackup is the outcome of one backup run.
+type databaseBackup struct {
+	key      string
+	size     int
+	verified bool
+}
+
+func NewBackupService(s *server.Server, repos *repository.Repositories) (*BackupService, error) {
+	bs := &BackupService{
+		server: s,
+		repos:  repos,
+	}
+
+	cfg := s.Config.Backup
+	if !cfg.Enabled {
+		return bs, nil
+	}
+
+	key, err := cfg.Key()
+	if err != nil {
+		return nil, err
+	}
+	bs.key = key
+
+	store, err := objectstore.NewClient(cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup storage client: %w", err)
+	}
+	bs.store = store
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskDatabaseBackup, bs.handleDatabaseBackupTask)
+
+		if _, err := s.Job.RegisterPeriodic(cfg.Schedule, job.NewDatabaseBackupTask(cfg.Timeout)); err != nil {
+			s.Logger.Error().Err(err).Str("schedule", cfg.Schedule).Msg("failed to schedule database backups")
+		}
+	}
+
+	return bs, nil
+}
+
+func (bs *BackupService) handleDatabaseBackupTask(ctx context.Context, t *asynq.Task) error {
+	logger := bs.server.Logger.With().Str("type", "database_backup").Logger()
+	start := time.Now()
+
+	result, err := bs.backup(ctx, start.UTC())
+	bs.recordBackupRun(ctx, result, time.Since(start), err)
+	if err != nil {
+		logger.Error().Err(err).Dur("duration", time.Since(start)).Msg("database backup failed")
+		return err
+	}
+
+	logger.Info().
+		Str("key", result.key).
+		Int("size_bytes", result.size).
+		Bool("verified", result.verified).
+		Dur("duration", time.Since(start)).
+		Msg("database backup finished")
+
+	// The backup is safe, a failing cleanup is retried by the next run.
+	if err := bs.enforceRetention(ctx, time.Now()); err != nil {
+		logger.Error().Err(err).Msg("failed to delete expired database backups")
+	}
+
+	return nil
+}
+
+// backup dumps, encrypts, uploads and, if configured, verifies one backup.
+func (bs *BackupService) backup(ctx context.Context, now time.Time) (*databaseBackup, error) {
+	cfg := bs.server.Config
+
+	dsn, err := cfg.Database.DSN()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build database dsn: %w", err)
+	}
+
+	// Taken before the dump, every table listed must be in it.
+	inventory, err := backup.TakeInventory(ctx, bs.server.DB.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	dump, err := backup.Dump(ctx, cfg.Backup.PgDumpPath, dsn)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(dump)
+	contentHash := hex.EncodeToString(sum[:])
+
+	sealed, err := backup.Encrypt(bs.key, dump)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt database backup: %w", err)
+	}
+
+	key := bs.store.Key("database", now.Format("2006/01/02/150405")+".dump.enc")
+	err = bs.store.Put(ctx, key, sealed, objectstore.PutOptions{
+		ContentType: "application/octet-stream",
+		Metadata: map[string]string{
+			"sha256":         contentHash,
+			"schema-version": strconv.Itoa(int(inventory.SchemaVersion)),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := bs.store.Head(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size != int64(len(sealed)) || info.Metadata["sha256"] != contentHash {
+		return nil, fmt.Errorf("uploaded backup %s does not match the dump", key)
+	}
+
+	result := &databaseBackup{key: key, size: len(sealed)}
+
+	if cfg.Backup.VerifyDatabaseURL != "" {
+		if err := bs.verifyRestore(ctx, key, contentHash, inventory); err != nil {
+			return result, err
+		}
+		result.verified = true
+	}
+
+	resourceID := key
+	entry := &model.AuditLog{
+		ActorID:      model.AuditActorSystem,
+		Action:       AuditActionDatabaseBackedUp,
+		ResourceType: "database_backup",
+		ResourceID:   &resourceID,
+		Metadata: map[string]any{
+			"size_bytes":     len(sealed),
+			"sha256":         contentHash,
+			"schema_version": inventory.SchemaVersion,
+			"verified":       result.verified,
+		},
+	}
+	if err := bs.repos.Audit.Create(ctx, entry); err != nil {
+		bs.server.Logger.Error().Err(err).Str("action", entry.Action).Msg("failed to record audit log entry")
+	}
+
+	return result, nil
+}
+
+// verifyRestore downloads the backup as it is stored, decrypts it and restores it into the
+// scratch database, which must then hold every table of the source at the same schema version.
+func (bs *BackupService) verifyRestore(ctx context.Context, key, contentHash string, source *backup.Inventory) error {
+	cfg := bs.server.Config.Backup
+
+	sealed, err := bs.store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	dump, err := backup.Decrypt(bs.key, sealed)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt backup %s: %w", key, err)
+	}
+
+	if sum := sha256.Sum256(dump); hex.EncodeToString(sum[:]) != contentHash {
+		return fmt.Errorf("backup %s does not match the dump", key)
+	}
+
+	// Checked at load too, the restore would drop the application's tables
+	if err := cfg.CheckVerifyDatabase(&bs.server.Config.Database); err != nil {
+		return err
+	}
+
+	if err := backup.Restore(ctx, cfg.PgRestorePath, cfg.VerifyDatabaseURL, dump); err != nil {
+		return fmt.Errorf("failed to restore backup %s: %w", key, err)
+	}
+
+	conn, err := pgx.Connect(ctx, cfg.VerifyDatabaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the backup verification database: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	restored, err := backup.TakeInventory(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("failed to inspect restored backup %s: %w", key, err)
+	}
+
+	if missing := source.Missing(restored); len(missing) > 0 {
+		return fmt.Errorf("restored backup %s is missing tables: %s", key, strings.Join(missing, ", "))
+	}
+
+	if restored.SchemaVersion != source.SchemaVersion {
+		return fmt.Errorf("restored backup %s has schema version %d, expected %d", key, restored.SchemaVersion, source.SchemaVersion)
+	}
+
+	return nil
+}
+
+// enforceRetention deletes backups older than the retention period, always keeping the
+// KeepLast most recent ones so a stretch of failing runs never leaves no backup at all.
+func (bs *BackupService) enforceRetention(ctx context.Context, now time.Time) error {
+	cfg := bs.server.Config.Backup
+
+	objects, err := bs.store.List(ctx, bs.store.Key("database")+"/")
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].LastModified.After(objects[j].LastModified)
+	})
+
+	cutoff := now.Add(-cfg.Retention())
+	deleted := 0
+	for i, object := range objects {
+		if i < cfg.KeepLast || !object.LastModified.Before(cutoff) || !strings.HasSuffix(object.Key, ".dump.enc") {
+			continue
+		}
+
+		if err := bs.store.Delete(ctx, object.Key); err != nil {
+			return err
+		}
+		deleted++
+	}
+
+	if deleted > 0 {
+		bs.server.Logger.Info().Int("deleted", deleted).Time("cutoff", cutoff).Msg("deleted expired database backups")
+	}
+
+	return nil
+}
+
+// recordBackupRun stores the outcome for the health check and records it as an event.
+func (bs *BackupService) recordBackupRun(ctx context.Context, result *databaseBackup, duration time.Duration, err error) {
+	status, loadErr := backup.LoadStatus(ctx, bs.server.Redis, bs.server.Keys.Space(keys.Backup))
+	if loadErr != nil || status == nil {
+		status = &backup.Status{}
+	}
+
+	status.LastRunAt = time.Now().UTC()
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	} else {
+		status.LastSuccessAt = &status.LastRunAt
+		status.LastKey = result.key
+		status.SizeBytes = int64(result.size)
+		status.Verified = result.verified
+	}
+
+	if saveErr := backup.SaveStatus(ctx, bs.server.Redis, bs.server.Keys.Space(keys.Backup), status); saveErr != nil {
+		bs.server.Logger.Error().Err(saveErr).Msg("failed to store backup status")
+	}
+
+	attributes := map[string]any{
+		"duration_ms": duration.Milliseconds(),
+		"success":     err == nil,
+	}
+	if result != nil {
+		attributes["size_bytes"] = result.size
+		attributes["verified"] = result.verified
+	}
+	if err != nil {
+		attributes["error_message"] = err.Error()
+	}
+
+	observe.Event("DatabaseBackup", attributes)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/email"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+)
+
+// maxReportedFailures bounds the failures listed in a batch result, the count is always exact.
+const maxReportedFailures = 100
+
+// errBatchEmailInterrupted stops a batch when the task can't go on, e.g. its deadline
+// would pass before the next send is allowed.
+var errBatchEmailInterrupted = errors.New("batch email interrupted")
+
+// BatchEmailService sends batch email tasks: one template to many recipients, each with
+// their own data. Recipients are handled in chunks, suppressed addresses are skipped and
+// a failing recipient is retried on its own, then reported, without failing the rest.
+type BatchEmailService struct {
+	server      *server.Server
+	repos       *repository.Repositories
+	emailClient *email.Client
+	// limiter keeps this worker's sends below the email provider's rate limit.
+	limiter *rate.Limiter
+}
+
+func NewBatchEmailService(s *server.Server, repos *repository.Repositories) *BatchEmailService {
+	cfg := s.Config.Jobs.BatchEmail
+
+	bs := &BatchEmailService{
+		server:      s,
+		repos:       repos,
+		emailClient: email.NewClient(s.Config, s.Logger),
+		limiter:     rate.NewLimiter(rate.Limit(cfg.RatePerSecond), 1),
+	}
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskBatchEmail, bs.handleBatchEmailTask)
+	}
+
+	return bs
+}
+
+func (bs *BatchEmailService) handleBatchEmailTask(ctx context.Context, t *asynq.Task) error {
+	var p job.BatchEmailTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal batch email payload: %w: %w", err, asynq.SkipRetry)
+	}
+
+	taskID, _ := job.GetTaskID(ctx)
+	logger := bs.server.Logger.With().Str("type", "batch_email").Str("task_id", taskID).Str("template", string(p.Template)).Logger()
+
+	result, offset, err := bs.loadProgress(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	result.Total = len(p.Recipients)
+
+	if offset > 0 {
+		logger.Info().Int("offset", offset).Msg("resuming batch email")
+	} else {
+		logger.Info().Int("recipients", len(p.Recipients)).Msg("sending batch email")
+	}
+
+	chunkSize := bs.server.Config.Jobs.BatchEmail.ChunkSize
+	for start := offset; start < len(p.Recipients); start += chunkSize {
+		chunk := p.Recipients[start:min(start+chunkSize, len(p.Recipients))]
+
+		if err := bs.sendChunk(ctx, &p, chunk, result); err != nil {
+			// Only a failure of the whole chunk (e.g. the task's deadline) gets here, the task
+			// is retried from the start of this chunk.
+			return err
+		}
+
+		bs.saveProgress(ctx, taskID, start+len(chunk), result)
+		bs.writeResult(ctx, t, result)
+	}
+
+	result.Done = true
+	bs.writeResult(ctx, t, result)
+
+	logger.Info().Int("sent", result.Sent).Int("suppressed", result.Suppressed).Int("failed", result.Failed).Msg("batch email completed")
+
+	return nil
+}
+
+// sendChunk sends to every recipient of chunk, counting the outcomes in result.
+func (bs *BatchEmailService) sendChunk(ctx context.Context, p *job.BatchEmailTaskPayload, chunk []job.BatchEmailRecipient, result *job.BatchEmailResult) error {
+	addresses := make([]string, len(chunk))
+	for i, recipient := range chunk {
+		addresses[i] = recipient.To
+	}
+
+	suppressed, err := bs.repos.EmailSuppression.FilterSuppressed(ctx, addresses)
+	if err != nil {
+		return err
+	}
+
+	for _, recipient := range chunk {
+		if suppressed[strings.ToLower(strings.TrimSpace(recipient.To))] {
+			result.Suppressed++
+			continue
+		}
+
+		if err := bs.sendToRecipient(ctx, p, recipient); err != nil {
+			if errors.Is(err, errBatchEmailInterrupted) {
+				return err
+			}
+
+			bs.server.Logger.Warn().Err(err).Str("type", "batch_email").Str("to", recipient.To).Msg("batch email to recipient failed")
+
+			result.Failed++
+			if len(result.Failures) < maxReportedFailures {
+				result.Failures = append(result.Failures, job.BatchEmailFailure{To: recipient.To, Error: err.Error()})
+			}
+			continue
+		}
+
+		result.Sent++
+	}
+
+	return nil
+}
+
+// sendToRecipient renders and sends the email for one recipient, with a few attempts.
+func (bs *BatchEmailService) sendToRecipient(ctx context.Context, p *job.BatchEmailTaskPayload, recipient job.BatchEmailRecipient) error {
+	subject := p.Subject
+	if recipient.Subject != "" {
+		subject = recipient.Subject
+	}
+
+	data := make(map[string]any, len(p.Data)+len(recipient.Data))
+	maps.Copy(data, p.Data)
+	maps.Copy(data, recipient.Data)
+
+	var err error
+	for attempt := 1; attempt <= bs.server.Config.Jobs.BatchEmail.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %w", errBatchEmailInterrupted, ctx.Err())
+			case <-time.After(time.Duration(attempt-1) * time.Second):
+			}
+		}
+
+		if err := bs.limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("%w: %w", errBatchEmailInterrupted, err)
+		}
+
+		// The provider's limit is shared with every other worker, a long wait retries the task later
+		if err := bs.server.EmailThrottle.Wait(ctx, email.ProviderResend); err != nil {
+			return fmt.Errorf("%w: %w", errBatchEmailInterrupted, err)
+		}
+
+		if err = bs.emailClient.SendEmail(recipient.To, subject, p.Template, data); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// loadProgress returns the result and recipient offset saved by a previous attempt of the task.
+func (bs *BatchEmailService) loadProgress(ctx context.Context, taskID string) (*job.BatchEmailResult, int, error) {
+	result := &job.BatchEmailResult{}
+
+	saved, err := bs.server.Redis.HGetAll(ctx, bs.progressKey(taskID)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, fmt.Errorf("failed to load batch email progress: %w", err)
+	}
+
+	if len(saved) == 0 {
+		return result, 0, nil
+	}
+
+	offset, err := strconv.Atoi(saved["offset"])
+	if err != nil {
+		return result, 0, nil
+	}
+
+	if err := json.Unmarshal([]byte(saved["result"]), result); err != nil {
+		return &job.BatchEmailResult{}, 0, nil
+	}
+
+	return result, offset, nil
+}
+
+// saveProgress records that the task got through offset recipients. If that fails, a
+// retry of the task sends to these recipients once more.
+func (bs *BatchEmailService) saveProgress(ctx context.Context, taskID string, offset int, result *job.BatchEmailResult) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	key := bs.progressKey(taskID)
+
+	pipe := bs.server.Redis.TxPipeline()
+	pipe.HSet(ctx, key, "offset", offset, "result", data)
+	pipe.Expire(ctx, key, keys.ProgressTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		bs.server.Logger.Error().Err(err).Str("task_id", taskID).Int("offset", offset).Msg("failed to save batch email progress")
+	}
+}
+
+func (bs *BatchEmailService) writeResult(ctx context.Context, t *asynq.Task, result *job.BatchEmailResult) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	if err := job.WriteResult(ctx, t, data); err != nil {
+		taskID, _ := job.GetTaskID(ctx)
+		bs.server.Logger.Error().Err(err).Str("task_id", taskID).Msg("failed to write batch email result")
+	}
+}
+
+// progressKey keys the number of recipients a batch task got through, so a retried
+// task resumes instead of emailing everybody again.
+func (bs *BatchEmailService) progressKey(taskID string) string {
+	return bs.server.Keys.Space(keys.BatchEmail).Key("progress", taskID)
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/config"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/signedurl"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/user"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+)
+
+var codeAccountDeletionAlreadyPending = errs.Register("ACCOUNT_DELETION_ALREADY_PENDING", http.StatusBadRequest, "The account is already scheduled for deletion.")
+
+// Audit actions recorded by the compliance subsystem.
+const (
+	AuditActionDataExportRequested      = "compliance.data_export.requested"
+	AuditActionDataExportCompleted      = "compliance.data_export.completed"
+	AuditActionDataExportDownloaded     = "compliance.data_export.downloaded"
+	AuditActionAccountDeletionRequested = "compliance.account_deletion.requested"
+	AuditActionAccountDeletionCancelled = "compliance.account_deletion.cancelled"
+	AuditActionAccountDeletionCompleted = "compliance.account_deletion.completed"
+)
+
+// DataExportResponse is a data export as returned to its owner,
+// including a signed download link once the archive is ready.
+type DataExportResponse struct {
+	*model.DataExport
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// ComplianceService implements the GDPR data subject workflows:
+// exporting all of a user's data and deleting their account after a grace period.
+type ComplianceService struct {
+	server *server.Server
+	repos  *repository.Repositories
+	signer *signedurl.Signer
+}
+
+func NewComplianceService(s *server.Server, repos *repository.Repositories) *ComplianceService {
+	cs := &ComplianceService{
+		server: s,
+		repos:  repos,
+		signer: signedurl.NewSigner(s.Config.Auth.GetURLSigningKey()),
+	}
+
+	// The compliance jobs need repositories, so they are registered here instead of in the job package.
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskDataExport, cs.handleDataExportTask)
+		s.Job.RegisterHandler(job.TaskAccountDeletion, cs.handleAccountDeletionTask)
+	}
+
+	return cs
+}
+
+// RequestDataExport creates an export request for the user and enqueues the job building the archive.
+func (cs *ComplianceService) RequestDataExport(ctx context.Context) (*DataExportResponse, error) {
+	userID := requestctx.UserID(ctx)
+
+	export, err := cs.repos.Compliance.CreateDataExport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := job.NewDataExportTask(export.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data export task: %w", err)
+	}
+
+	if _, err := cs.server.Job.Enqueue(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to enqueue data export task: %w", err)
+	}
+
+	cs.recordAudit(ctx, userID, AuditActionDataExportRequested, "data_export", export.ID.String(), nil)
+
+	return &DataExportResponse{DataExport: export}, nil
+}
+
+// GetDataExport returns the user's export, with a signed download link once it is completed.
+func (cs *ComplianceService) GetDataExport(ctx context.Context, exportID uuid.UUID) (*DataExportResponse, error) {
+	userID := requestctx.UserID(ctx)
+
+	export, err := cs.repos.Compliance.GetUserDataExport(ctx, userID, exportID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFoundError("Data export not found", false, nil)
+		}
+		return nil, err
+	}
+
+	response := &DataExportResponse{DataExport: export}
+
+	if export.Status == model.DataExportStatusCompleted && !cs.isExpired(export) {
+		expiresAt := time.Now().Add(cs.server.Config.Compliance.ExportLinkTTL)
+		if export.ExpiresAt != nil && export.ExpiresAt.Before(expiresAt) {
+			expiresAt = *export.ExpiresAt
+		}
+		response.DownloadURL = cs.server.Config.Server.BaseURL + cs.signer.Sign(DataExportDownloadPath(export.ID), expiresAt)
+	}
+
+	return response, nil
+}
+
+// DownloadDataExport verifies a signed download link and returns the export archive.
+func (cs *ComplianceService) DownloadDataExport(ctx context.Context, exportID uuid.UUID, expires, signature string) ([]byte, error) {
+	err := cs.signer.Verify(DataExportDownloadPath(exportID), expires, signature, time.Now())
+	if err != nil {
+		if errors.Is(err, signedurl.ErrExpired) {
+			return nil, errs.ForbididdenError("Download link has expired", true)
+		}
+		return nil, errs.ForbididdenError("Invalid download link", false)
+	}
+
+	export, err := cs.repos.Compliance.GetDataExport(ctx, exportID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFoundError("Data export not found", false, nil)
+		}
+		return nil, err
+	}
+
+	if export.Status != model.DataExportStatusCompleted || cs.isExpired(export) {
+		return nil, errs.NotFoundError("Data export is not available", true, nil)
+	}
+
+	cs.recordAudit(ctx, export.UserID, AuditActionDataExportDownloaded, "data_export", export.ID.String(), nil)
+
+	return export.Archive, nil
+}
+
+// RequestAccountDeletion schedules the user's account for deletion once the grace period ends.
+func (cs *ComplianceService) RequestAccountDeletion(ctx context.Context) (*model.AccountDeletion, error) {
+	userID := requestctx.UserID(ctx)
+
+	if _, err := cs.repos.Compliance.GetPendingAccountDeletion(ctx, userID); err == nil {
+		code := codeAccountDeletionAlreadyPending
+		return nil, errs.BadRequestError("Account deletion is already scheduled", true, &code, nil, nil)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	scheduledFor := time.Now().Add(cs.server.Config.Compliance.DeletionGracePeriod)
+
+	deletion, err := cs.repos.Compliance.CreateAccountDeletion(ctx, userID, scheduledFor)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := job.NewAccountDeletionTask(deletion.ID, scheduledFor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account deletion task: %w", err)
+	}
+
+	if _, err := cs.server.Job.Enqueue(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to enqueue account deletion task: %w", err)
+	}
+
+	cs.recordAudit(ctx, userID, AuditActionAccountDeletionRequested, "account_deletion", deletion.ID.String(), map[string]any{
+		"scheduled_for": scheduledFor,
+	})
+
+	return deletion, nil
+}
+
+// CancelAccountDeletion cancels the user's pending account deletion during the grace period.
+func (cs *ComplianceService) CancelAccountDeletion(ctx context.Context) (*model.AccountDeletion, error) {
+	userID := requestctx.UserID(ctx)
+
+	deletion, err := cs.repos.Compliance.CancelAccountDeletion(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFoundError("No pending account deletion", true, nil)
+		}
+		return nil, err
+	}
+
+	cs.recordAudit(ctx, userID, AuditActionAccountDeletionCancelled, "account_deletion", deletion.ID.String(), nil)
+
+	return deletion, nil
+}
+
+// ListAuditLogs returns a page of the audit trail of the user's own actions.
+func (cs *ComplianceService) ListAuditLogs(ctx context.Context, within model.TimeRange, params model.PageParams, strategy model.CountStrategy) (*model.Page[model.AuditLog], error) {
+	userID := requestctx.UserID(ctx)
+
+	entries, info, err := cs.repos.Audit.ListPageByActor(ctx, userID, within, params, strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Page[model.AuditLog]{Items: entries, PageInfo: info}, nil
+}
+
+// DataExportDownloadPath is the API path serving an export archive, it is what download links sign.
+func DataExportDownloadPath(exportID uuid.UUID) string {
+	return "/api/v1/compliance/exports/" + exportID.String() + "/download"
+}
+
+func (cs *ComplianceService) handleDataExportTask(ctx context.Context, t *asynq.Task) error {
+	var p job.DataExportTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal data export payload: %w", err)
+	}
+
+	logger := cs.server.Logger.With().Str("type", "data_export").Str("export_id", p.ExportID.String()).Logger()
+	logger.Info().Msg("processing data export task")
+
+	export, err := cs.repos.Compliance.GetDataExport(ctx, p.ExportID)
+	if err != nil {
+		return err
+	}
+
+	if err := cs.repos.Compliance.UpdateDataExportStatus(ctx, export.ID, model.DataExportStatusProcessing); err != nil {
+		return err
+	}
+
+	archive, err := cs.buildExportArchive(ctx, export.UserID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to build data export archive")
+
+		// Only give up on the export once the task won't be retried anymore.
+		retried, _ := job.GetRetryCount(ctx)
+		maxRetry, _ := job.GetMaxRetry(ctx)
+		if retried >= maxRetry {
+			if failErr := cs.repos.Compliance.FailDataExport(ctx, export.ID, "failed to build export archive"); failErr != nil {
+				logger.Error().Err(failErr).Msg("failed to mark data export as failed")
+			}
+		}
+
+		return err
+	}
+
+	expiresAt := time.Now().Add(cs.server.Config.Compliance.ExportRetention)
+	if err := cs.repos.Compliance.CompleteDataExport(ctx, export.ID, archive, expiresAt); err != nil {
+		return err
+	}
+
+	cs.recordAudit(ctx, model.AuditActorSystem, AuditActionDataExportCompleted, "data_export", export.ID.String(), map[string]any{
+		"user_id":    export.UserID,
+		"size_bytes": len(archive),
+	})
+
+	logger.Info().Int("size_bytes", len(archive)).Msg("successfully built data export")
+
+	return nil
+}
+
+// buildExportArchive writes one JSON file per user data provider into a zip archive.
+func (cs *ComplianceService) buildExportArchive(ctx context.Context, userID string) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	sections := make([]string, 0)
+	for _, provider := range cs.repos.UserDataProviders() {
+		data, err := provider.ExportUserData(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", provider.UserDataSection(), err)
+		}
+
+		if err := writeJSONFile(archive, provider.UserDataSection()+".json", data); err != nil {
+			return nil, err
+		}
+		sections = append(sections, provider.UserDataSection())
+	}
+
+	manifest := map[string]any{
+		"user_id":      userID,
+		"generated_at": time.Now().UTC(),
+		"sections":     sections,
+	}
+	if err := writeJSONFile(archive, "manifest.json", manifest); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize export archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeJSONFile(archive *zip.Writer, name string, data any) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export archive: %w", name, err)
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to write %s to export archive: %w", name, err)
+	}
+
+	return nil
+}
+
+func (cs *ComplianceService) handleAccountDeletionTask(ctx context.Context, t *asynq.Task) error {
+	var p job.AccountDeletionTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal account deletion payload: %w", err)
+	}
+
+	logger := cs.server.Logger.With().Str("type", "account_deletion").Str("deletion_id", p.DeletionID.String()).Logger()
+	logger.Info().Msg("processing account deletion task")
+
+	deletion, err := cs.repos.Compliance.GetAccountDeletion(ctx, p.DeletionID)
+	if err != nil {
+		return err
+	}
+
+	// The user may have cancelled during the grace period.
+	if deletion.Status != model.AccountDeletionStatusPending {
+		logger.Info().Str("status", string(deletion.Status)).Msg("account deletion no longer pending, skipping")
+		return nil
+	}
+
+	if time.Now().Before(deletion.ScheduledFor) {
+		return fmt.Errorf("account deletion %s is scheduled for %s", deletion.ID, deletion.ScheduledFor)
+	}
+
+	tx, err := cs.server.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin account deletion transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Lock the request first so a concurrent cancellation can't interleave.
+	if _, err := cs.repos.Compliance.CompleteAccountDeletion(ctx, tx, deletion.ID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Info().Msg("account deletion no longer pending, skipping")
+			return nil
+		}
+		return err
+	}
+
+	for _, provider := range cs.repos.UserDataProviders() {
+		if err := provider.AnonymizeUserData(ctx, tx, deletion.UserID); err != nil {
+			return fmt.Errorf("failed to anonymize %s: %w", provider.UserDataSection(), err)
+		}
+	}
+
+	// Remove the identity from Clerk before committing, if this fails the job is retried. A
+	// user already gone was deleted by an earlier attempt whose commit failed. With an OIDC
+	// provider the identity isn't ours to delete.
+	if cs.server.Config.Auth.Provider == config.AuthProviderClerk {
+		if _, err := user.Delete(ctx, deletion.UserID); err != nil {
+			var apiErr *clerk.APIErrorResponse
+			if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusNotFound {
+				return fmt.Errorf("failed to delete user from auth provider: %w", err)
+			}
+			logger.Info().Msg("user already deleted from auth provider")
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit account deletion: %w", err)
+	}
+
+	// The audit entry intentionally references the deletion request, not the deleted user.
+	cs.recordAudit(ctx, model.AuditActorSystem, AuditActionAccountDeletionCompleted, "account_deletion", deletion.ID.String(), nil)
+
+	logger.Info().Msg("successfully deleted account")
+
+	return nil
+}
+
+func (cs *ComplianceService) isExpired(export *model.DataExport) bool {
+	return export.ExpiresAt != nil && time.Now().After(*export.ExpiresAt)
+}
+
+// recordAudit writes an audit entry. Failing to audit never fails the operation itself, it is logged instead.
+func (cs *ComplianceService) recordAudit(ctx context.Context, actorID, action, resourceType, resourceID string, metadata map[string]any) {
+	entry := &model.AuditLog{
+		ActorID:      actorID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   &resourceID,
+		Metadata:     metadata,
+	}
+
+	if err := cs.repos.Audit.Create(ctx, entry); err != nil {
+		cs.server.Logger.Error().Err(err).Str("action", action).Str("resource_id", resourceID).Msg("failed to record audit log entry")
+	}
+}
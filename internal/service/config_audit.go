@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+)
+
+// ConfigChange is a change of a runtime setting made through an admin endpoint.
+type ConfigChange struct {
+	ActorID string
+	// Action is the audit action, e.g. "tenant.limits.updated".
+	Action string
+	// Setting names what changed, e.g. "tenant_limits".
+	Setting string
+	// Scope is what the setting applies to, e.g. an organization, empty for global settings.
+	Scope string
+	// Old and New are the values before and after the change, nil when unset.
+	Old any
+	New any
+}
+
+// ConfigAuditService keeps the change history of runtime settings. Every change is
+// written to the audit log (see model.AuditResourceConfig), logged and recorded as a
+// ConfigurationChanged event, so production toggles can be traced back.
+type ConfigAuditService struct {
+	server *server.Server
+	repos  *repository.Repositories
+}
+
+func NewConfigAuditService(s *server.Server, repos *repository.Repositories) *ConfigAuditService {
+	return &ConfigAuditService{
+		server: s,
+		repos:  repos,
+	}
+}
+
+// Record records change. The change already took effect, so failures are only logged.
+func (ca *ConfigAuditService) Record(ctx context.Context, change ConfigChange) {
+	oldValue, newValue := normalizeConfigValue(change.Old), normalizeConfigValue(change.New)
+	changed := changedFields(oldValue, newValue)
+
+	resourceID := change.Setting
+	if change.Scope != "" {
+		resourceID += ":" + change.Scope
+	}
+
+	entry := &model.AuditLog{
+		ActorID:      change.ActorID,
+		Action:       change.Action,
+		ResourceType: model.AuditResourceConfig,
+		ResourceID:   &resourceID,
+		Metadata: map[string]any{
+			"setting": change.Setting,
+			"scope":   change.Scope,
+			"old":     oldValue,
+			"new":     newValue,
+			"changed": changed,
+		},
+	}
+
+	if requestID := database.RequestIDFromContext(ctx); requestID != "" {
+		entry.RequestID = &requestID
+	}
+
+	if err := ca.repos.Audit.Create(ctx, entry); err != nil {
+		ca.server.Logger.Error().Err(err).Str("action", change.Action).Str("resource_id", resourceID).Msg("failed to record configuration change")
+	}
+
+	ca.server.Logger.Info().
+		Str("actor_id", change.ActorID).
+		Str("action", change.Action).
+		Str("setting", change.Setting).
+		Str("scope", change.Scope).
+		Strs("changed", changed).
+		Interface("old", oldValue).
+		Interface("new", newValue).
+		Msg("configuration changed")
+
+	observe.Event("ConfigurationChanged", map[string]any{
+		"actorId": change.ActorID,
+		"action":  change.Action,
+		"setting": change.Setting,
+		"scope":   change.Scope,
+		"changed": strings.Join(changed, ","),
+	})
+}
+
+// normalizeConfigValue converts value to its JSON form, so it is stored the way the
+// API shows it and compared field by field.
+func normalizeConfigValue(value any) any {
+	if value == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+
+	return normalized
+}
+
+// changedFields lists the fields that differ between two objects, sorted. Values that
+// aren't objects have no fields, they are reported as "value" if they differ.
+func changedFields(oldValue, newValue any) []string {
+	oldFields, oldIsObject := oldValue.(map[string]any)
+	newFields, newIsObject := newValue.(map[string]any)
+
+	if (oldValue != nil && !oldIsObject) || (newValue != nil && !newIsObject) {
+		if reflect.DeepEqual(oldValue, newValue) {
+			return []string{}
+		}
+		return []string{"value"}
+	}
+
+	changed := []string{}
+	for name, value := range newFields {
+		if !reflect.DeepEqual(oldFields[name], value) {
+			changed = append(changed, name)
+		}
+	}
+	for name, value := range oldFields {
+		if _, ok := newFields[name]; !ok && value != nil {
+			changed = append(changed, name)
+		}
+	}
+
+	slices.Sort(changed)
+	return changed
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/email"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/user"
+	"github.com/hibiken/asynq"
+)
+
+// DigestService sends periodic activity digest emails. It is a reference for periodic jobs:
+//   - a cron-scheduled dispatch task pages through all users in batches,
+//   - and enqueues one email task per user, so a failing user is retried on its own
+//     without blocking or repeating everyone else's digest,
+//   - users on the email suppression list or without activity are skipped.
+type DigestService struct {
+	server      *server.Server
+	repos       *repository.Repositories
+	emailClient *email.Client
+}
+
+func NewDigestService(s *server.Server, repos *repository.Repositories) *DigestService {
+	ds := &DigestService{
+		server:      s,
+		repos:       repos,
+		emailClient: email.NewClient(s.Config, s.Logger),
+	}
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskDigestDispatch, ds.handleDigestDispatchTask)
+		s.Job.RegisterHandler(job.TaskDigestEmail, ds.handleDigestEmailTask)
+
+		cfg := s.Config.Jobs.Digest
+		if cfg.Enabled {
+			if _, err := s.Job.RegisterPeriodic(cfg.Schedule, job.NewDigestDispatchTask(cfg.Period)); err != nil {
+				s.Logger.Error().Err(err).Str("schedule", cfg.Schedule).Msg("failed to schedule digest emails")
+			}
+		}
+	}
+
+	return ds
+}
+
+// handleDigestDispatchTask enqueues a digest email task for every user.
+// Enqueue failures are logged per user and don't stop the batch; if listing users fails
+// the task is retried, and users already enqueued for this period are skipped by task ID.
+func (ds *DigestService) handleDigestDispatchTask(ctx context.Context, t *asynq.Task) error {
+	cfg := ds.server.Config.Jobs.Digest
+
+	// The period ends at the tick that enqueued the dispatch, so retries and late runs
+	// summarize the same period.
+	until, err := job.PreviousTick(cfg.Schedule, time.Now())
+	if err != nil {
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+	since := until.Add(-cfg.Period)
+
+	logger := ds.server.Logger.With().Str("type", "digest_dispatch").Time("since", since).Time("until", until).Logger()
+	logger.Info().Msg("dispatching digest emails")
+
+	var enqueued, skipped, failed int
+	for offset := int64(0); ; offset += int64(cfg.BatchSize) {
+		users, err := user.List(ctx, &user.ListParams{
+			ListParams: clerk.ListParams{
+				Limit:  clerk.Int64(int64(cfg.BatchSize)),
+				Offset: clerk.Int64(offset),
+			},
+			OrderBy: clerk.String("+created_at"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list users at offset %d: %w", offset, err)
+		}
+
+		for _, u := range users.Users {
+			task, err := job.NewDigestEmailTask(u.ID, since, until)
+			if err != nil {
+				failed++
+				logger.Error().Err(err).Str("user_id", u.ID).Msg("failed to create digest email task")
+				continue
+			}
+
+			if _, err := ds.server.Job.Enqueue(ctx, task); err != nil {
+				if errors.Is(err, asynq.ErrTaskIDConflict) {
+					skipped++
+					continue
+				}
+				failed++
+				logger.Error().Err(err).Str("user_id", u.ID).Msg("failed to enqueue digest email task")
+				continue
+			}
+			enqueued++
+		}
+
+		if len(users.Users) < cfg.BatchSize {
+			break
+		}
+	}
+
+	logger.Info().Int("enqueued", enqueued).Int("already_enqueued", skipped).Int("failed", failed).Msg("dispatched digest emails")
+
+	return nil
+}
+
+// handleDigestEmailTask sends one user's digest.
+func (ds *DigestService) handleDigestEmailTask(ctx context.Context, t *asynq.Task) error {
+	var p job.DigestEmailTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal digest email payload: %w", err)
+	}
+
+	logger := ds.server.Logger.With().Str("type", "digest").Str("user_id", p.UserID).Logger()
+
+	u, err := user.Get(ctx, p.UserID)
+	if err != nil {
+		// The user was deleted since the dispatch, there is nobody to send to.
+		var apiErr *clerk.APIErrorResponse
+		if errors.As(err, &apiErr) && apiErr.HTTPStatusCode == http.StatusNotFound {
+			logger.Info().Msg("user no longer exists, skipping digest")
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	address := primaryEmailAddress(u)
+	if address == "" {
+		logger.Info().Msg("user has no primary email address, skipping digest")
+		return nil
+	}
+
+	suppressed, err := ds.repos.EmailSuppression.IsSuppressed(ctx, address)
+	if err != nil {
+		return err
+	}
+	if suppressed {
+		logger.Info().Msg("email address is suppressed, skipping digest")
+		return nil
+	}
+
+	counts, err := ds.repos.Audit.CountActionsByActor(ctx, p.UserID, p.Since, p.Until)
+	if err != nil {
+		return err
+	}
+	if len(counts) == 0 {
+		logger.Debug().Msg("no activity in period, skipping digest")
+		return nil
+	}
+
+	activity := make([]email.DigestActivity, 0, len(counts))
+	for _, count := range counts {
+		activity = append(activity, email.DigestActivity{Action: describeAction(count.Action), Count: count.Count})
+	}
+
+	firstName := ""
+	if u.FirstName != nil {
+		firstName = *u.FirstName
+	}
+
+	if err := ds.server.EmailThrottle.Wait(ctx, email.ProviderResend); err != nil {
+		return err
+	}
+
+	if err := ds.emailClient.SendDigestEmail(address, firstName, p.Since, p.Until, activity); err != nil {
+		logger.Error().Err(err).Msg("digest email sending failed")
+		return err
+	}
+
+	logger.Info().Int("actions", len(activity)).Msg("successfully sent digest email")
+
+	return nil
+}
+
+func primaryEmailAddress(u *clerk.User) string {
+	if u.PrimaryEmailAddressID == nil {
+		return ""
+	}
+
+	for _, address := range u.EmailAddresses {
+		if address.ID == *u.PrimaryEmailAddressID {
+			return address.EmailAddress
+		}
+	}
+
+	return ""
+}
+
+// describeAction turns an audit action such as "compliance.data_export.requested"
+// into a readable label like "Data export requested".
+func describeAction(action string) string {
+	parts := strings.Split(action, ".")
+	if len(parts) > 1 {
+		parts = parts[1:]
+	}
+
+	label := strings.ReplaceAll(strings.Join(parts, " "), "_", " ")
+	if label == "" {
+		return action
+	}
+
+	return strings.ToUpper(label[:1]) + label[1:]
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/email"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/unsubscribe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+// Audit actions recorded when recipients manage their email subscriptions.
+const (
+	AuditActionEmailUnsubscribed = "email.unsubscribed"
+)
+
+// Values of the Redis key tracking a scheduled email.
+const (
+	scheduledEmailPending   = "pending"
+	scheduledEmailCancelled = "cancelled"
+)
+
+// cancelScheduledScript marks a scheduled email cancelled if it is still pending, keeping
+// its TTL. Returns 1 if it was.
+var cancelScheduledScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[2], 'KEEPTTL')
+	return 1
+end
+return 0
+`)
+
+// EmailService handles the unsubscribe links of bulk emails and emails scheduled for
+// later. Unsubscribing puts the address on the suppression list, which every bulk
+// sender checks before sending.
+type EmailService struct {
+	server      *server.Server
+	repos       *repository.Repositories
+	signer      *unsubscribe.Signer
+	emailClient *email.Client
+	scheduled   keys.Space
+}
+
+func NewEmailService(s *server.Server, repos *repository.Repositories) *EmailService {
+	es := &EmailService{
+		server:      s,
+		repos:       repos,
+		signer:      unsubscribe.NewSigner(s.Config.Auth.GetURLSigningKey()),
+		emailClient: email.NewClient(s.Config, s.Logger),
+		scheduled:   s.Keys.Space(keys.ScheduledEmail),
+	}
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskScheduledEmail, es.handleScheduledEmailTask)
+	}
+
+	return es
+}
+
+// DescribeUnsubscribe returns what the unsubscribe link with token applies to, and
+// whether the address is unsubscribed already. It doesn't change anything, so link
+// scanners prefetching it can't unsubscribe anyone.
+func (es *EmailService) DescribeUnsubscribe(ctx context.Context, token string) (*model.EmailUnsubscribe, error) {
+	parsed, err := es.parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	suppressed, err := es.repos.EmailSuppression.IsSuppressed(ctx, parsed.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.EmailUnsubscribe{
+		Email:        parsed.Email,
+		List:         parsed.List,
+		Unsubscribed: suppressed,
+	}, nil
+}
+
+// Unsubscribe adds the address of the unsubscribe link to the suppression list.
+// oneClick marks requests sent by the mailbox provider through List-Unsubscribe-Post.
+// Repeated requests succeed without recording another audit entry.
+func (es *EmailService) Unsubscribe(ctx context.Context, token string, oneClick bool) (*model.EmailUnsubscribe, error) {
+	parsed, err := es.parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := es.repos.EmailSuppression.Suppress(ctx, parsed.Email, model.EmailSuppressionUnsubscribe)
+	if err != nil {
+		return nil, err
+	}
+
+	if added {
+		address := strings.ToLower(strings.TrimSpace(parsed.Email))
+		entry := &model.AuditLog{
+			ActorID:      model.AuditActorEmailRecipient,
+			Action:       AuditActionEmailUnsubscribed,
+			ResourceType: model.AuditResourceEmail,
+			ResourceID:   &address,
+			Metadata: map[string]any{
+				"list":          parsed.List,
+				"one_click":     oneClick,
+				"token_version": parsed.Version,
+			},
+		}
+
+		if err := es.repos.Audit.Create(ctx, entry); err != nil {
+			es.server.Logger.Error().Err(err).Str("action", entry.Action).Msg("failed to record audit log entry")
+		}
+	}
+
+	return &model.EmailUnsubscribe{
+		Email:        parsed.Email,
+		List:         parsed.List,
+		Unsubscribed: true,
+	}, nil
+}
+
+func (es *EmailService) parseToken(token string) (*unsubscribe.Token, error) {
+	parsed, err := es.signer.Parse(token, time.Now())
+	if err != nil {
+		if errors.Is(err, unsubscribe.ErrExpired) {
+			return nil, errs.ForbididdenError("Unsubscribe link has expired", true)
+		}
+		return nil, errs.ForbididdenError("Invalid unsubscribe link", false)
+	}
+
+	return parsed, nil
+}
+
+// Schedule sends msg at at, unless it is cancelled first with the returned ID. It makes
+// flows such as "remind the user in 24 hours unless they finish onboarding" a schedule
+// when the flow starts and a CancelScheduled when the user finishes.
+func (es *EmailService) Schedule(ctx context.Context, msg email.Message, at time.Time) (string, error) {
+	id := uuid.NewString()
+
+	task, err := job.NewScheduledEmailTask(id, msg, at)
+	if err != nil {
+		return "", fmt.Errorf("failed to create scheduled email task: %w", err)
+	}
+
+	// Kept past the send time for the task's retries
+	ttl := time.Until(at) + keys.ProgressTTL
+	if err := es.server.Redis.Set(ctx, es.scheduled.Key(id), scheduledEmailPending, ttl).Err(); err != nil {
+		return "", fmt.Errorf("failed to track scheduled email: %w", err)
+	}
+
+	if _, err := es.server.Job.Enqueue(ctx, task); err != nil {
+		es.server.Redis.Del(ctx, es.scheduled.Key(id))
+		return "", fmt.Errorf("failed to enqueue scheduled email task: %w", err)
+	}
+
+	return id, nil
+}
+
+// CancelScheduled cancels the scheduled email id. It reports false if the email isn't
+// pending anymore: it was sent or cancelled already, or the ID is unknown.
+func (es *EmailService) CancelScheduled(ctx context.Context, id string) (bool, error) {
+	cancelled, err := cancelScheduledScript.Run(ctx, es.server.Redis, []string{es.scheduled.Key(id)}, scheduledEmailPending, scheduledEmailCancelled).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel scheduled email: %w", err)
+	}
+
+	return cancelled == 1, nil
+}
+
+func (es *EmailService) handleScheduledEmailTask(ctx context.Context, t *asynq.Task) error {
+	var p job.ScheduledEmailTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal scheduled email payload: %w: %w", err, asynq.SkipRetry)
+	}
+
+	logger := es.server.Logger.With().Str("type", "scheduled_email").Str("schedule_id", p.ID).Str("template", string(p.Message.Template)).Logger()
+
+	// Sending an email its sender cancelled is worse than sending it late, so the task
+	// is retried while the state can't be read
+	state, err := es.server.Redis.Get(ctx, es.scheduled.Key(p.ID)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to read scheduled email state: %w", err)
+	}
+	if state == scheduledEmailCancelled {
+		logger.Info().Msg("scheduled email was cancelled, not sending")
+		return nil
+	}
+
+	if p.Message.Template.Bulk() {
+		suppressed, err := es.repos.EmailSuppression.IsSuppressed(ctx, p.Message.To)
+		if err != nil {
+			return err
+		}
+		if suppressed {
+			logger.Info().Msg("scheduled email recipient is suppressed, not sending")
+			return nil
+		}
+	}
+
+	if err := es.server.EmailThrottle.Wait(ctx, email.ProviderResend); err != nil {
+		return err
+	}
+
+	if err := es.emailClient.Send(p.Message); err != nil {
+		logger.Error().Err(err).Msg("scheduled email sending failed")
+		return err
+	}
+
+	// Sent: cancelling it from now on reports false
+	if err := es.server.Redis.Del(ctx, es.scheduled.Key(p.ID)).Err(); err != nil {
+		logger.Warn().Err(err).Msg("failed to clear scheduled email state")
+	}
+
+	logger.Info().Msg("sent scheduled email")
+
+	return nil
+}
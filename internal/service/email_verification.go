@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/email"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/emailverify"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/ratelimit"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/hibiken/asynq"
+)
+
+var (
+	codeEmailVerificationRateLimited = errs.Register("EMAIL_VERIFICATION_RATE_LIMITED", http.StatusTooManyRequests, "The user was sent too many verification emails in the past hour.")
+	codeEmailAlreadyVerified         = errs.Register("EMAIL_ALREADY_VERIFIED", http.StatusBadRequest, "The user's email address is verified already, there is nothing to resend.")
+)
+
+// Audit actions recorded when users verify their email address.
+const (
+	AuditActionEmailVerified = "email.verified"
+)
+
+// EmailVerificationService verifies users' email addresses: it sends a link carrying a
+// single-use token, stored hashed, and marks the address verified once the link is
+// opened. Sends are rate limited per user, resending replaces the previous link.
+type EmailVerificationService struct {
+	server      *server.Server
+	repos       *repository.Repositories
+	emailClient *email.Client
+	limiter     *ratelimit.Limiter
+}
+
+func NewEmailVerificationService(s *server.Server, repos *repository.Repositories) *EmailVerificationService {
+	vs := &EmailVerificationService{
+		server:      s,
+		repos:       repos,
+		emailClient: email.NewClient(s.Config, s.Logger),
+		limiter:     ratelimit.NewLimiter(s.Redis, s.Keys.Space(keys.Verification), time.Hour, s.Clock),
+	}
+
+	if s.Verification != nil {
+		s.Verification.SetLoader(repos.Verification.IsVerified)
+	}
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskVerificationEmail, vs.handleVerificationEmailTask)
+	}
+
+	return vs
+}
+
+// GetVerification returns the verification state of the caller's email.
+func (vs *EmailVerificationService) GetVerification(ctx context.Context) (*model.EmailVerification, error) {
+	verification, err := vs.repos.Verification.GetVerification(ctx, requestctx.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if verification == nil {
+		return nil, errs.NotFoundError("No email verification was requested", false, nil)
+	}
+
+	return verification, nil
+}
+
+// RequestVerification sends a verification link to the caller's address. An address
+// that is verified already is returned as is, without sending anything.
+func (vs *EmailVerificationService) RequestVerification(ctx context.Context, payload *model.RequestEmailVerificationPayload) (*model.EmailVerification, error) {
+	userID := requestctx.UserID(ctx)
+	address := strings.ToLower(strings.TrimSpace(payload.Email))
+
+	verification, err := vs.repos.Verification.GetVerification(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if verification != nil && verification.Verified && verification.Email == address {
+		return verification, nil
+	}
+
+	return vs.send(ctx, userID, address)
+}
+
+// ResendVerification sends a new link to the address pending verification, the link
+// sent before stops working.
+func (vs *EmailVerificationService) ResendVerification(ctx context.Context) (*model.EmailVerification, error) {
+	verification, err := vs.GetVerification(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if verification.Verified {
+		code := codeEmailAlreadyVerified
+		return nil, errs.BadRequestError("Email address is verified already", true, &code, nil, nil)
+	}
+
+	return vs.send(ctx, verification.UserID, verification.Email)
+}
+
+// send issues a new token for address and hands the email to a job, unless the user was
+// sent SendLimit verification emails in the past hour. Should Redis be down, emails go
+// out unlimited.
+func (vs *EmailVerificationService) send(ctx context.Context, userID, address string) (*model.EmailVerification, error) {
+	cfg := vs.server.Config.Email.Verification
+
+	result, err := vs.limiter.Allow(ctx, userID, cfg.SendLimit)
+	if err != nil {
+		vs.server.Logger.Warn().Err(err).Str("user_id", userID).Msg("email verification rate limit unavailable, sending anyway")
+	} else if !result.Allowed {
+		code := codeEmailVerificationRateLimited
+		return nil, errs.TooManyRequestsError("Too many verification emails, try again later", true, &code)
+	}
+
+	token, tokenHash, err := emailverify.NewToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := vs.server.Clock.Now().Add(cfg.TokenTTL).UTC()
+
+	verification, err := vs.repos.Verification.IssueToken(ctx, userID, address, tokenHash, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// A changed address isn't verified anymore
+	if err := vs.server.Verification.Invalidate(ctx, userID); err != nil {
+		vs.server.Logger.Warn().Err(err).Str("user_id", userID).Msg("failed to invalidate cached email verification")
+	}
+
+	task, err := job.NewVerificationEmailTask(job.VerificationEmailTaskPayload{
+		UserID:    userID,
+		To:        address,
+		URL:       vs.emailClient.VerificationURL(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verification email task: %w", err)
+	}
+
+	if _, err := vs.server.Job.Enqueue(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to enqueue verification email task: %w", err)
+	}
+
+	return verification, nil
+}
+
+// Verify verifies the address a verification link was sent to. Access is granted by
+// the token, not by a session, so the link works wherever it is opened.
+func (vs *EmailVerificationService) Verify(ctx context.Context, token string) (*model.EmailVerification, error) {
+	tokenHash := emailverify.HashToken(token)
+
+	verification, err := vs.repos.Verification.GetVerificationByToken(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if verification == nil {
+		return nil, errs.ForbididdenError("Invalid verification link", false)
+	}
+	if verification.TokenExpiresAt == nil || !vs.server.Clock.Now().Before(*verification.TokenExpiresAt) {
+		return nil, errs.ForbididdenError("Verification link has expired, request a new one", true)
+	}
+
+	verified, err := vs.repos.Verification.MarkVerified(ctx, verification.UserID, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if verified == nil {
+		// Replaced by a new link or used by a concurrent request
+		return nil, errs.ForbididdenError("Invalid verification link", false)
+	}
+
+	if err := vs.server.Verification.Invalidate(ctx, verified.UserID); err != nil {
+		vs.server.Logger.Warn().Err(err).Str("user_id", verified.UserID).Msg("failed to invalidate cached email verification")
+	}
+
+	entry := &model.AuditLog{
+		ActorID:      verified.UserID,
+		Action:       AuditActionEmailVerified,
+		ResourceType: model.AuditResourceEmail,
+		ResourceID:   &verified.Email,
+	}
+	if err := vs.repos.Audit.Create(ctx, entry); err != nil {
+		vs.server.Logger.Error().Err(err).Str("action", entry.Action).Msg("failed to record audit log entry")
+	}
+
+	return verified, nil
+}
+
+func (vs *EmailVerificationService) handleVerificationEmailTask(ctx context.Context, t *asynq.Task) error {
+	var p job.VerificationEmailTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal verification email payload: %w: %w", err, asynq.SkipRetry)
+	}
+
+	logger := vs.server.Logger.With().Str("type", "verification_email").Str("user_id", p.UserID).Logger()
+
+	// The link is worthless once expired, a newer one was sent if the user still wants it
+	if !vs.server.Clock.Now().Before(p.ExpiresAt) {
+		logger.Info().Msg("verification link expired before it was sent, not sending")
+		return nil
+	}
+
+	if err := vs.server.EmailThrottle.Wait(ctx, email.ProviderResend); err != nil {
+		return err
+	}
+
+	if err := vs.emailClient.SendVerificationEmail(p.To, p.URL, p.ExpiresAt); err != nil {
+		logger.Error().Err(err).Msg("verification email sending failed")
+		return err
+	}
+
+	logger.Info().Msg("sent verification email")
+
+	return nil
+}
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/export"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/progress"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/signedurl"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+)
+
+var codeExportTooLarge = errs.Register("EXPORT_TOO_LARGE", http.StatusBadRequest, "The export has more rows than a synchronous export may, request it as an async export.")
+
+// Audit actions recorded by the export subsystem.
+const (
+	AuditActionExportStreamed   = "export.streamed"
+	AuditActionExportRequested  = "export.requested"
+	AuditActionExportDownloaded = "export.downloaded"
+)
+
+// auditLogExport is the audit log as an exportable dataset.
+var auditLogExport = export.Dataset{
+	Name: "audit-logs",
+	Columns: []export.Column{
+		{Key: "id", Header: "ID"},
+		{Key: "created_at", Header: "Time", Headers: map[string]string{"de": "Zeitpunkt", "fr": "Date"}},
+		{Key: "actor_id", Header: "Actor", Headers: map[string]string{"de": "Akteur", "fr": "Acteur"}},
+		{Key: "action", Header: "Action", Headers: map[string]string{"de": "Aktion", "fr": "Action"}},
+		{Key: "resource_type", Header: "Resource type", Headers: map[string]string{"de": "Ressourcentyp", "fr": "Type de ressource"}},
+		{Key: "resource_id", Header: "Resource ID", Headers: map[string]string{"de": "Ressourcen-ID", "fr": "ID de ressource"}},
+		{Key: "request_id", Header: "Request ID", Headers: map[string]string{"de": "Anfrage-ID", "fr": "ID de requête"}},
+		{Key: "metadata", Header: "Metadata", Headers: map[string]string{"de": "Metadaten", "fr": "Métadonnées"}},
+	},
+}
+
+// exportSource is a dataset together with the queries reading it.
+type exportSource struct {
+	dataset export.Dataset
+	// count counts the rows within a range, stopping at limit.
+	count func(ctx context.Context, within model.TimeRange, limit int64) (int64, error)
+	// query returns the rows within a range, the caller closes them.
+	query func(ctx context.Context, within model.TimeRange) (pgx.Rows, error)
+}
+
+// ExportRequest selects what an export contains.
+type ExportRequest struct {
+	Dataset string
+	Format  string
+	Columns []string
+	Within  model.TimeRange
+}
+
+// PreparedExport is a validated export that is ready to be streamed.
+type PreparedExport struct {
+	Filename    string
+	ContentType string
+	write       func(ctx context.Context, w io.Writer) (int64, error)
+}
+
+// Stream streams the export to w and returns how many rows were written.
+func (p *PreparedExport) Stream(ctx context.Context, w io.Writer) (int64, error) {
+	return p.write(ctx, w)
+}
+
+// ExportResponse is an async export as returned to its requester,
+// including a signed download link once the file is ready.
+type ExportResponse struct {
+	*model.Export
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// ExportService exports datasets as CSV or XLSX files. Exports up to Export.MaxSyncRows
+// rows are streamed in the request, larger ones are built by a job and downloaded
+// through a signed link once ready.
+type ExportService struct {
+	server  *server.Server
+	repos   *repository.Repositories
+	signer  *signedurl.Signer
+	sources map[string]exportSource
+}
+
+func NewExportService(s *server.Server, repos *repository.Repositories) *ExportService {
+	es := &ExportService{
+		server: s,
+		repos:  repos,
+		signer: signedurl.NewSigner(s.Config.Auth.GetURLSigningKey()),
+		// Register new exportable datasets here.
+		sources: map[string]exportSource{
+			auditLogExport.Name: {dataset: auditLogExport, count: repos.Audit.CountExport, query: repos.Audit.QueryExport},
+		},
+	}
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskExport, es.handleExportTask)
+	}
+
+	return es
+}
+
+// PrepareExport validates an export streamed in the request. Exports with more than
+// Export.MaxSyncRows rows are rejected, they have to be requested with RequestExport.
+func (es *ExportService) PrepareExport(ctx context.Context, req ExportRequest) (*PreparedExport, error) {
+	actorID := requestctx.UserID(ctx)
+
+	source, format, columns, err := es.resolve(req.Dataset, req.Format, req.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	maxRows := es.server.Config.Export.MaxSyncRows
+	count, err := source.count(ctx, req.Within, maxRows+1)
+	if err != nil {
+		return nil, err
+	}
+	if count > maxRows {
+		code := codeExportTooLarge
+		return nil, errs.BadRequestError(fmt.Sprintf("Export has more than %d rows, request it as an async export", maxRows), true, &code, nil, nil)
+	}
+
+	headers := export.Headers(columns, requestctx.Locale(ctx))
+
+	return &PreparedExport{
+		Filename:    exportFilename(source.dataset.Name, format, time.Now()),
+		ContentType: format.ContentType(),
+		write: func(ctx context.Context, w io.Writer) (int64, error) {
+			rows, err := source.query(ctx, req.Within)
+			if err != nil {
+				return 0, err
+			}
+
+			written, err := export.Stream(ctx, w, format, columns, headers, rows, export.Options{FlushEvery: es.server.Config.Export.FlushRows})
+			if err != nil {
+				return written, err
+			}
+
+			es.recordAudit(ctx, actorID, AuditActionExportStreamed, source.dataset.Name, map[string]any{
+				"format": format,
+				"rows":   written,
+			})
+
+			return written, nil
+		},
+	}, nil
+}
+
+// RequestExport creates an async export and enqueues the job building its file.
+func (es *ExportService) RequestExport(ctx context.Context, dataset string, payload *model.CreateExportPayload) (*ExportResponse, error) {
+	actorID := requestctx.UserID(ctx)
+	acceptLanguage := requestctx.Locale(ctx)
+
+	source, format, columns, err := es.resolve(dataset, payload.Format, payload.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(columns))
+	for i, column := range columns {
+		keys[i] = column.Key
+	}
+
+	created, err := es.repos.Export.Create(ctx, &model.Export{
+		RequestedBy: actorID,
+		Dataset:     source.dataset.Name,
+		Format:      string(format),
+		Columns:     keys,
+		Language:    acceptLanguage,
+		RangeFrom:   payload.From,
+		RangeUntil:  payload.Until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := job.NewExportTask(created.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export task: %w", err)
+	}
+
+	if _, err := es.server.Job.Enqueue(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to enqueue export task: %w", err)
+	}
+
+	es.recordAudit(ctx, actorID, AuditActionExportRequested, created.ID.String(), map[string]any{
+		"dataset": created.Dataset,
+		"format":  created.Format,
+	})
+
+	return &ExportResponse{Export: created}, nil
+}
+
+// GetExport returns an async export of the actor, with a signed download link once it is completed.
+func (es *ExportService) GetExport(ctx context.Context, exportID uuid.UUID) (*ExportResponse, error) {
+	actorID := requestctx.UserID(ctx)
+
+	exp, err := es.repos.Export.GetRequested(ctx, actorID, exportID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFoundError("Export not found", false, nil)
+		}
+		return nil, err
+	}
+
+	response := &ExportResponse{Export: exp}
+
+	if exp.Status == model.DataExportStatusCompleted && !isExportExpired(exp) {
+		expiresAt := time.Now().Add(es.server.Config.Export.LinkTTL)
+		if exp.ExpiresAt != nil && exp.ExpiresAt.Before(expiresAt) {
+			expiresAt = *exp.ExpiresAt
+		}
+		response.DownloadURL = es.server.Config.Server.BaseURL + es.signer.Sign(ExportDownloadPath(exp.ID), expiresAt)
+	}
+
+	return response, nil
+}
+
+// DownloadExport verifies a signed download link and returns the completed export with its file.
+func (es *ExportService) DownloadExport(ctx context.Context, exportID uuid.UUID, expires, signature string) (*model.Export, error) {
+	err := es.signer.Verify(ExportDownloadPath(exportID), expires, signature, time.Now())
+	if err != nil {
+		if errors.Is(err, signedurl.ErrExpired) {
+			return nil, errs.ForbididdenError("Download link has expired", true)
+		}
+		return nil, errs.ForbididdenError("Invalid download link", false)
+	}
+
+	exp, err := es.repos.Export.Get(ctx, exportID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFoundError("Export not found", false, nil)
+		}
+		return nil, err
+	}
+
+	if exp.Status != model.DataExportStatusCompleted || isExportExpired(exp) {
+		return nil, errs.NotFoundError("Export is not available", true, nil)
+	}
+
+	es.recordAudit(ctx, exp.RequestedBy, AuditActionExportDownloaded, exp.ID.String(), nil)
+
+	return exp, nil
+}
+
+// ExportDownloadPath is the API path serving the file of an async export, it is what download links sign.
+func ExportDownloadPath(exportID uuid.UUID) string {
+	return "/api/v1/exports/" + exportID.String() + "/download"
+}
+
+// ExportFilename is the name the file of an export is downloaded as.
+func ExportFilename(exp *model.Export) string {
+	return exportFilename(exp.Dataset, export.Format(exp.Format), exp.CreatedAt)
+}
+
+// ExportContentType is the MIME type the file of an export is served with.
+func ExportContentType(exp *model.Export) string {
+	return export.Format(exp.Format).ContentType()
+}
+
+func exportFilename(dataset string, format export.Format, at time.Time) string {
+	return dataset + "-" + at.UTC().Format("20060102-150405") + "." + format.Extension()
+}
+
+func (es *ExportService) handleExportTask(ctx context.Context, t *asynq.Task) error {
+	var p job.ExportTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal export payload: %w: %w", err, asynq.SkipRetry)
+	}
+
+	logger := es.server.Logger.With().Str("type", "export").Str("export_id", p.ExportID.String()).Logger()
+	logger.Info().Msg("processing export task")
+
+	exp, err := es.repos.Export.Get(ctx, p.ExportID)
+	if err != nil {
+		return err
+	}
+
+	if err := es.repos.Export.UpdateStatus(ctx, exp.ID, model.DataExportStatusProcessing); err != nil {
+		return err
+	}
+
+	// The row count is only known once the rows are read
+	reportProgress(ctx, es.server, exp.ID, progress.StateRunning, -1, "Building export")
+
+	content, rowCount, err := es.buildExport(ctx, exp)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to build export")
+
+		// Only give up on the export once the task won't be retried anymore.
+		retried, _ := job.GetRetryCount(ctx)
+		maxRetry, _ := job.GetMaxRetry(ctx)
+		if retried >= maxRetry || errors.Is(err, asynq.SkipRetry) {
+			if failErr := es.repos.Export.Fail(ctx, exp.ID, "failed to build export file"); failErr != nil {
+				logger.Error().Err(failErr).Msg("failed to mark export as failed")
+			}
+			reportProgress(ctx, es.server, exp.ID, progress.StateFailed, -1, "Failed to build export file")
+		}
+
+		return err
+	}
+
+	expiresAt := time.Now().Add(es.server.Config.Export.Retention)
+	if err := es.repos.Export.Complete(ctx, exp.ID, content, rowCount, expiresAt); err != nil {
+		return err
+	}
+
+	reportProgress(ctx, es.server, exp.ID, progress.StateCompleted, 100, fmt.Sprintf("Exported %d rows", rowCount))
+
+	logger.Info().Int64("rows", rowCount).Int("size_bytes", len(content)).Msg("successfully built export")
+
+	return nil
+}
+
+// buildExport streams the rows of an async export into a file held in memory.
+func (es *ExportService) buildExport(ctx context.Context, exp *model.Export) ([]byte, int64, error) {
+	source, format, columns, err := es.resolve(exp.Dataset, exp.Format, exp.Columns)
+	if err != nil {
+		// The dataset or its columns changed since the export was requested
+		return nil, 0, fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+
+	rows, err := source.query(ctx, exp.TimeRange())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var buf bytes.Buffer
+	rowCount, err := export.Stream(ctx, &buf, format, columns, export.Headers(columns, exp.Language), rows, export.Options{FlushEvery: es.server.Config.Export.FlushRows})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return buf.Bytes(), rowCount, nil
+}
+
+// resolve looks up the dataset, format and columns of an export, failing with a 400 if any is unknown.
+func (es *ExportService) resolve(dataset, format string, columnKeys []string) (exportSource, export.Format, []export.Column, error) {
+	source, ok := es.sources[dataset]
+	if !ok {
+		return exportSource{}, "", nil, errs.NotFoundError("Unknown export dataset "+dataset, true, nil)
+	}
+
+	parsedFormat, err := export.ParseFormat(format)
+	if err != nil {
+		return exportSource{}, "", nil, errs.BadRequestError("Invalid export format", false, nil, []errs.FieldError{
+			{Field: "format", Error: "must be one of csv | xlsx"},
+		}, nil)
+	}
+
+	columns, err := source.dataset.Select(columnKeys)
+	if err != nil {
+		return exportSource{}, "", nil, errs.BadRequestError("Invalid export columns", false, nil, []errs.FieldError{
+			{Field: "columns", Error: err.Error()},
+		}, nil)
+	}
+
+	return source, parsedFormat, columns, nil
+}
+
+func isExportExpired(exp *model.Export) bool {
+	return exp.ExpiresAt != nil && time.Now().After(*exp.ExpiresAt)
+}
+
+// recordAudit writes an audit entry. Failing to audit never fails the export itself, it is logged instead.
+func (es *ExportService) recordAudit(ctx context.Context, actorID, action, resourceID string, metadata map[string]any) {
+	entry := &model.AuditLog{
+		ActorID:      actorID,
+		Action:       action,
+		ResourceType: "export",
+		ResourceID:   &resourceID,
+		Metadata:     metadata,
+	}
+
+	if err := es.repos.Audit.Create(ctx, entry); err != nil {
+		es.server.Logger.Error().Err(err).Str("action", action).Str("resource_id", resourceID).Msg("failed to record audit log entry")
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/email"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	deepHealthHealthy   = "healthy"
+	deepHealthUnhealthy = "unhealthy"
+	deepHealthSkipped   = "skipped"
+)
+
+// probePollInterval is how often the deep health check looks whether its probe job ran.
+const probePollInterval = 100 * time.Millisecond
+
+// HealthService runs the deep health check: unlike /status, which only pings the
+// dependencies, it goes through representative paths end to end so external synthetic
+// monitors catch what a ping can't (read-only replicas, stuck workers, a revoked
+// email key).
+type HealthService struct {
+	server      *server.Server
+	repos       *repository.Repositories
+	emailClient *email.Client
+}
+
+func NewHealthService(s *server.Server, repos *repository.Repositories) *HealthService {
+	hs := &HealthService{
+		server:      s,
+		repos:       repos,
+		emailClient: email.NewClient(s.Config, s.Logger),
+	}
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskHealthProbe, hs.handleHealthProbeTask)
+	}
+
+	return hs
+}
+
+// DeepCheck writes and reads back a probe row, has a probe job process it and sends a
+// probe email, timing every step. Steps depending on a failed one are skipped.
+func (hs *HealthService) DeepCheck(ctx context.Context) *model.DeepHealth {
+	start := time.Now()
+	cfg := hs.server.Config.DeepHealth
+	result := &model.DeepHealth{Status: deepHealthHealthy}
+
+	var probe *model.HealthProbe
+	result.Steps = append(result.Steps, runDeepHealthStep("database", func() error {
+		created, err := hs.repos.HealthProbe.Create(ctx)
+		if err != nil {
+			return err
+		}
+
+		probe, err = hs.repos.HealthProbe.Get(ctx, created.ID)
+		return err
+	}))
+
+	if probe == nil {
+		result.Steps = append(result.Steps, model.DeepHealthCheck{Name: "job", Status: deepHealthSkipped})
+	} else {
+		result.Steps = append(result.Steps, runDeepHealthStep("job", func() error {
+			return hs.probeJob(ctx, probe, cfg.JobTimeout)
+		}))
+
+		if err := hs.repos.HealthProbe.Delete(ctx, probe.ID); err != nil {
+			hs.server.Logger.Warn().Err(err).Str("probe_id", probe.ID.String()).Msg("failed to delete health probe")
+		}
+	}
+
+	if cfg.EmailDisabled {
+		result.Steps = append(result.Steps, model.DeepHealthCheck{Name: "email", Status: deepHealthSkipped})
+	} else {
+		probeID := "-"
+		if probe != nil {
+			probeID = probe.ID.String()
+		}
+
+		result.Steps = append(result.Steps, runDeepHealthStep("email", func() error {
+			return hs.emailClient.SendHealthProbeEmail(cfg.Email, probeID)
+		}))
+	}
+
+	for _, step := range result.Steps {
+		if step.Status == deepHealthUnhealthy {
+			result.Status = deepHealthUnhealthy
+		}
+	}
+	result.DurationMS = time.Since(start).Milliseconds()
+
+	if result.Status != deepHealthHealthy {
+		hs.server.Logger.Warn().Interface("steps", result.Steps).Msg("deep health check failed")
+
+		observe.Event("HealthCheckError", map[string]any{
+			"operation":              "deep_health_check",
+			"check_type":             "deep_health",
+			"error_type":             "deep_unhealthy",
+			"total_response_time_ms": result.DurationMS,
+		})
+	}
+
+	return result
+}
+
+// probeJob enqueues the probe job of probe and waits until a worker processed it.
+func (hs *HealthService) probeJob(ctx context.Context, probe *model.HealthProbe, timeout time.Duration) error {
+	if hs.server.Job == nil {
+		return errors.New("job service is not running")
+	}
+
+	task, err := job.NewHealthProbeTask(probe.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create health probe task: %w", err)
+	}
+
+	if _, err := hs.server.Job.Enqueue(ctx, task); err != nil {
+		return fmt.Errorf("failed to enqueue health probe task: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(probePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("health probe task not processed within %s", timeout)
+		case <-ticker.C:
+		}
+
+		current, err := hs.repos.HealthProbe.Get(ctx, probe.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			return err
+		}
+		if current.ProcessedAt != nil {
+			return nil
+		}
+	}
+}
+
+func (hs *HealthService) handleHealthProbeTask(ctx context.Context, t *asynq.Task) error {
+	var p job.HealthProbeTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal health probe payload: %w: %w", err, asynq.SkipRetry)
+	}
+
+	return hs.repos.HealthProbe.MarkProcessed(ctx, p.ProbeID)
+}
+
+// runDeepHealthStep runs one step of the deep health check and times it.
+func runDeepHealthStep(name string, step func() error) model.DeepHealthCheck {
+	start := time.Now()
+	err := step()
+
+	check := model.DeepHealthCheck{
+		Name:       name,
+		Status:     deepHealthHealthy,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		check.Status = deepHealthUnhealthy
+		check.Error = err.Error()
+	}
+
+	return check
+}
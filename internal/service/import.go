@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/importer"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/progress"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+)
+
+// Audit actions recorded by the import subsystem.
+const (
+	AuditActionImportRequested = "import.requested"
+	AuditActionImportCompleted = "import.completed"
+)
+
+// importTarget is a dataset rows can be imported into.
+type importTarget struct {
+	schema importer.Schema
+	// commit writes a batch of validated rows within tx.
+	commit func(ctx context.Context, tx pgx.Tx, rows []importer.Row) error
+}
+
+// ImportService imports CSV uploads into datasets. Uploads are validated in the request,
+// every row error is reported, and valid rows are staged; a job then commits them in
+// batches, counting its progress on the import.
+type ImportService struct {
+	server  *server.Server
+	repos   *repository.Repositories
+	targets map[string]importTarget
+}
+
+func NewImportService(s *server.Server, repos *repository.Repositories) *ImportService {
+	reasons := make([]string, 0)
+	for _, reason := range model.EmailSuppressionReasonValues() {
+		reasons = append(reasons, string(reason))
+	}
+
+	is := &ImportService{
+		server: s,
+		repos:  repos,
+		// Register new import targets here.
+		targets: map[string]importTarget{
+			"email-suppressions": {
+				schema: importer.Schema{
+					Name: "email-suppressions",
+					Fields: []importer.Field{
+						{Name: "email", Type: importer.TypeEmail, Required: true, MaxLength: 320},
+						{Name: "reason", Type: importer.TypeEnum, Required: true, Values: reasons},
+					},
+				},
+				commit: func(ctx context.Context, tx pgx.Tx, rows []importer.Row) error {
+					emails := make([]string, len(rows))
+					reasons := make([]model.EmailSuppressionReason, len(rows))
+					for i, row := range rows {
+						emails[i] = row.String("email")
+						reasons[i] = model.EmailSuppressionReason(row.String("reason"))
+					}
+
+					_, err := repos.EmailSuppression.SuppressBatch(ctx, tx, emails, reasons)
+					return err
+				},
+			},
+		},
+	}
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskImportCommit, is.handleImportCommitTask)
+	}
+
+	return is
+}
+
+// Upload validates and stages the CSV read from r and, unless rows are invalid, enqueues
+// the job committing it. With skipInvalid the valid rows are committed regardless.
+func (is *ImportService) Upload(ctx context.Context, dataset, filename string, r io.Reader, skipInvalid bool) (*model.Import, error) {
+	actorID := requestctx.UserID(ctx)
+
+	target, ok := is.targets[dataset]
+	if !ok {
+		return nil, errs.NotFoundError("Unknown import dataset "+dataset, true, nil)
+	}
+
+	imp, err := is.repos.Import.Create(ctx, actorID, dataset, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := is.server.Config.Import
+	batch := make([]model.StagedImportRow, 0, cfg.BatchSize)
+	stage := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := is.repos.Import.StageRows(ctx, imp.ID, batch)
+		batch = batch[:0]
+		return err
+	}
+
+	report, err := importer.Read(ctx, r, target.schema, cfg.MaxReportedErrors, func(line int, row importer.Row) error {
+		batch = append(batch, model.StagedImportRow{Line: line, Data: row})
+		if len(batch) < cfg.BatchSize {
+			return nil
+		}
+		return stage()
+	})
+	if err == nil {
+		err = stage()
+	}
+	if err != nil {
+		if failErr := is.repos.Import.Fail(ctx, imp.ID, err.Error()); failErr != nil {
+			is.server.Logger.Error().Err(failErr).Str("import_id", imp.ID.String()).Msg("failed to mark import as failed")
+		}
+
+		if errors.Is(err, importer.ErrInvalidFile) {
+			return nil, errs.BadRequestError(err.Error(), true, nil, nil, nil)
+		}
+		return nil, err
+	}
+
+	imp.TotalRows = report.TotalRows
+	imp.ValidRows = report.ValidRows
+	imp.ErrorCount = report.ErrorCount
+	imp.Errors = make([]model.ImportError, len(report.Errors))
+	for i, rowErr := range report.Errors {
+		imp.Errors[i] = model.ImportError{Line: rowErr.Line, Field: rowErr.Field, Message: rowErr.Message}
+	}
+
+	if report.ErrorCount > 0 && !skipInvalid {
+		if err := is.repos.Import.DeleteStagedRows(ctx, imp.ID); err != nil {
+			return nil, err
+		}
+		return is.repos.Import.FinishValidation(ctx, imp, model.ImportStatusInvalid)
+	}
+
+	imp, err = is.repos.Import.FinishValidation(ctx, imp, model.ImportStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := job.NewImportCommitTask(imp.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import commit task: %w", err)
+	}
+
+	if _, err := is.server.Job.Enqueue(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to enqueue import commit task: %w", err)
+	}
+
+	is.recordAudit(ctx, actorID, AuditActionImportRequested, imp.ID.String(), map[string]any{
+		"dataset":    imp.Dataset,
+		"valid_rows": imp.ValidRows,
+		"skipped":    imp.ErrorCount,
+	})
+
+	return imp, nil
+}
+
+// GetImport returns an import of the actor with its validation report and progress.
+func (is *ImportService) GetImport(ctx context.Context, importID uuid.UUID) (*model.Import, error) {
+	actorID := requestctx.UserID(ctx)
+
+	imp, err := is.repos.Import.GetRequested(ctx, actorID, importID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFoundError("Import not found", false, nil)
+		}
+		return nil, err
+	}
+
+	return imp, nil
+}
+
+func (is *ImportService) handleImportCommitTask(ctx context.Context, t *asynq.Task) error {
+	var p job.ImportCommitTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal import commit payload: %w: %w", err, asynq.SkipRetry)
+	}
+
+	logger := is.server.Logger.With().Str("type", "import").Str("import_id", p.ImportID.String()).Logger()
+	logger.Info().Msg("processing import commit task")
+
+	imp, err := is.repos.Import.Get(ctx, p.ImportID)
+	if err != nil {
+		return err
+	}
+
+	if imp.Status != model.ImportStatusPending && imp.Status != model.ImportStatusProcessing {
+		logger.Info().Str("status", string(imp.Status)).Msg("import no longer pending, skipping")
+		return nil
+	}
+
+	target, ok := is.targets[imp.Dataset]
+	if !ok {
+		if err := is.repos.Import.Fail(ctx, imp.ID, "unknown import dataset"); err != nil {
+			return err
+		}
+		reportProgress(ctx, is.server, imp.ID, progress.StateFailed, -1, "Unknown import dataset")
+		return fmt.Errorf("unknown import dataset %s: %w", imp.Dataset, asynq.SkipRetry)
+	}
+
+	if err := is.repos.Import.UpdateStatus(ctx, imp.ID, model.ImportStatusProcessing); err != nil {
+		return err
+	}
+
+	if err := is.commitStagedRows(ctx, imp, target); err != nil {
+		logger.Error().Err(err).Msg("failed to commit import")
+
+		// Only give up on the import once the task won't be retried anymore.
+		retried, _ := job.GetRetryCount(ctx)
+		maxRetry, _ := job.GetMaxRetry(ctx)
+		if retried >= maxRetry {
+			if failErr := is.repos.Import.Fail(ctx, imp.ID, "failed to commit import rows"); failErr != nil {
+				logger.Error().Err(failErr).Msg("failed to mark import as failed")
+			}
+			reportProgress(ctx, is.server, imp.ID, progress.StateFailed, -1, "Failed to commit import rows")
+		}
+
+		return err
+	}
+
+	imp, err = is.repos.Import.Get(ctx, imp.ID)
+	if err != nil {
+		return err
+	}
+
+	// Postgres empties the unlogged staging table after a crash
+	if imp.ProcessedRows < imp.ValidRows {
+		logger.Error().Int64("processed_rows", imp.ProcessedRows).Int64("valid_rows", imp.ValidRows).Msg("staged import rows were lost")
+		reportProgress(ctx, is.server, imp.ID, progress.StateFailed, -1, "Staged rows were lost, upload the file again")
+		return is.repos.Import.Fail(ctx, imp.ID, "staged rows were lost, upload the file again")
+	}
+
+	if err := is.repos.Import.Complete(ctx, imp.ID); err != nil {
+		return err
+	}
+
+	reportProgress(ctx, is.server, imp.ID, progress.StateCompleted, 100, fmt.Sprintf("Imported %d rows", imp.ProcessedRows))
+
+	is.recordAudit(ctx, model.AuditActorSystem, AuditActionImportCompleted, imp.ID.String(), map[string]any{
+		"dataset":        imp.Dataset,
+		"processed_rows": imp.ProcessedRows,
+	})
+
+	logger.Info().Int64("processed_rows", imp.ProcessedRows).Msg("successfully committed import")
+
+	return nil
+}
+
+// commitStagedRows commits the staged rows of an import batch by batch, each batch in a
+// transaction that also removes it from the staging table and counts it as processed.
+// Progress is reported after every batch.
+func (is *ImportService) commitStagedRows(ctx context.Context, imp *model.Import, target importTarget) error {
+	processed := imp.ProcessedRows
+	for {
+		staged, err := is.repos.Import.NextStagedRows(ctx, imp.ID, is.server.Config.Import.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(staged) == 0 {
+			return nil
+		}
+
+		rows := make([]importer.Row, len(staged))
+		for i, row := range staged {
+			rows[i] = row.Data
+		}
+
+		if err := is.commitBatch(ctx, imp.ID, target, rows, staged[len(staged)-1].Line); err != nil {
+			return err
+		}
+
+		processed += int64(len(rows))
+		percent := 100
+		if imp.ValidRows > 0 {
+			percent = int(processed * 100 / imp.ValidRows)
+		}
+		reportProgress(ctx, is.server, imp.ID, progress.StateRunning, percent, fmt.Sprintf("Committed %d of %d rows", processed, imp.ValidRows))
+	}
+}
+
+// commitBatch writes rows and the import's progress in one transaction. It is begun with
+// TxManager so targets can defer work until it commits, e.g. cache invalidations.
+func (is *ImportService) commitBatch(ctx context.Context, importID uuid.UUID, target importTarget, rows []importer.Row, throughLine int) error {
+	return is.server.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		tx, _ := database.TxFromContext(ctx)
+
+		if err := target.commit(ctx, tx, rows); err != nil {
+			return err
+		}
+
+		return is.repos.Import.MarkBatchCommitted(ctx, tx, importID, throughLine, len(rows))
+	})
+}
+
+// recordAudit writes an audit entry. Failing to audit never fails the import itself, it is logged instead.
+func (is *ImportService) recordAudit(ctx context.Context, actorID, action, resourceID string, metadata map[string]any) {
+	entry := &model.AuditLog{
+		ActorID:      actorID,
+		Action:       action,
+		ResourceType: "import",
+		ResourceID:   &resourceID,
+		Metadata:     metadata,
+	}
+
+	if err := is.repos.Audit.Create(ctx, entry); err != nil {
+		is.server.Logger.Error().Err(err).Str("action", action).Str("resource_id", resourceID).Msg("failed to record audit log entry")
+	}
+}
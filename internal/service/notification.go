@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/keys"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/notify"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/ratelimit"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/validation"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+var codeNotificationRateLimited = errs.Register("NOTIFICATION_RATE_LIMITED", http.StatusTooManyRequests, "The user was sent too many notifications of the channel in the past hour.")
+
+// TwilioStatusCallbackPath receives Twilio's delivery updates of sent SMS.
+const TwilioStatusCallbackPath = "/api/v1/webhooks/twilio/status"
+
+// NotificationService sends SMS and push notifications to users, next to email. Sends
+// are rate limited per user and channel, then handed to a job; every message sent is
+// recorded as a delivery, whose status SMS status callbacks keep up to date.
+type NotificationService struct {
+	server  *server.Server
+	repos   *repository.Repositories
+	limiter *ratelimit.Limiter
+}
+
+func NewNotificationService(s *server.Server, repos *repository.Repositories) *NotificationService {
+	ns := &NotificationService{
+		server:  s,
+		repos:   repos,
+		limiter: ratelimit.NewLimiter(s.Redis, s.Keys.Space(keys.Notify), time.Hour, s.Clock),
+	}
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskNotification, ns.handleNotificationTask)
+	}
+
+	return ns
+}
+
+// RegisterDevice registers the push token of an app install for the calling user.
+func (ns *NotificationService) RegisterDevice(ctx context.Context, payload *model.RegisterDevicePayload) (*model.Device, error) {
+	userID := requestctx.UserID(ctx)
+
+	if ns.server.Push == nil || !ns.server.Push.Supports(payload.Provider) {
+		return nil, errs.BadRequestError("Push provider is not enabled", false, nil, []errs.FieldError{
+			{Field: "provider", Error: "is not enabled"},
+		}, nil)
+	}
+
+	return ns.repos.Notification.UpsertDevice(ctx, userID, payload)
+}
+
+func (ns *NotificationService) ListDevices(ctx context.Context) ([]model.Device, error) {
+	userID := requestctx.UserID(ctx)
+	return ns.repos.Notification.ListDevices(ctx, userID)
+}
+
+func (ns *NotificationService) DeleteDevice(ctx context.Context, deviceID uuid.UUID) error {
+	userID := requestctx.UserID(ctx)
+
+	deleted, err := ns.repos.Notification.DeleteDevice(ctx, userID, deviceID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errs.NotFoundError("Device not found", false, nil)
+	}
+
+	return nil
+}
+
+// SendPush sends a push notification to every device of userID.
+func (ns *NotificationService) SendPush(ctx context.Context, userID, title, body string, data map[string]string) error {
+	if ns.server.Push == nil {
+		return errors.New("push notifications are not enabled")
+	}
+
+	return ns.enqueue(ctx, job.NotificationTaskPayload{
+		Channel: notify.ChannelPush,
+		UserID:  userID,
+		Title:   title,
+		Body:    body,
+		Data:    data,
+	}, ns.server.Config.Notifications.Push.RateLimit)
+}
+
+// SendSMS sends an SMS to phone, an E.164 number of userID.
+func (ns *NotificationService) SendSMS(ctx context.Context, userID, phone, body string) error {
+	if ns.server.SMS == nil {
+		return errors.New("sms notifications are not enabled")
+	}
+
+	if err := validation.NewValidator().Var(phone, "required,e164"); err != nil {
+		return errs.BadRequestError("Invalid phone number", false, nil, []errs.FieldError{
+			{Field: "phone", Error: "must be an E.164 number"},
+		}, nil)
+	}
+
+	return ns.enqueue(ctx, job.NotificationTaskPayload{
+		Channel: notify.ChannelSMS,
+		UserID:  userID,
+		To:      phone,
+		Body:    body,
+	}, ns.server.Config.Notifications.SMS.RateLimit)
+}
+
+// enqueue hands the notification to a job, unless the user was sent limit notifications
+// of the channel in the past hour. Should Redis be down, notifications go out unlimited.
+func (ns *NotificationService) enqueue(ctx context.Context, payload job.NotificationTaskPayload, limit int) error {
+	result, err := ns.limiter.Allow(ctx, payload.Channel+":"+payload.UserID, limit)
+	if err != nil {
+		ns.server.Logger.Warn().Err(err).Str("channel", payload.Channel).Msg("notification rate limit unavailable, sending anyway")
+	} else if !result.Allowed {
+		code := codeNotificationRateLimited
+		return errs.TooManyRequestsError("Too many "+payload.Channel+" notifications, try again later", true, &code)
+	}
+
+	task, err := job.NewNotificationTask(payload)
+	if err != nil {
+		return fmt.Errorf("failed to create notification task: %w", err)
+	}
+
+	if _, err := ns.server.Job.Enqueue(ctx, task); err != nil {
+		return fmt.Errorf("failed to enqueue notification task: %w", err)
+	}
+
+	return nil
+}
+
+// HandleSMSStatus applies a status callback of the SMS provider, whose signature was verified.
+func (ns *NotificationService) HandleSMSStatus(ctx context.Context, form url.Values) error {
+	update, err := notify.ParseTwilioStatusCallback(form)
+	if err != nil {
+		return errs.BadRequestError("Invalid status callback", false, nil, nil, nil)
+	}
+
+	var deliveryError *string
+	if update.Error != "" {
+		deliveryError = &update.Error
+	}
+
+	updated, err := ns.repos.Notification.UpdateDeliveryStatus(ctx, "twilio", update.ProviderMessageID, model.NotificationStatus(update.Status), deliveryError)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		// Unknown or already final, Twilio shouldn't retry either way
+		ns.server.Logger.Debug().Str("message_sid", update.ProviderMessageID).Str("status", update.Status).Msg("sms status callback changed no delivery")
+	}
+
+	return nil
+}
+
+func (ns *NotificationService) handleNotificationTask(ctx context.Context, t *asynq.Task) error {
+	var p job.NotificationTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal notification payload: %w: %w", err, asynq.SkipRetry)
+	}
+
+	switch p.Channel {
+	case notify.ChannelSMS:
+		return ns.sendSMS(ctx, p)
+	case notify.ChannelPush:
+		return ns.sendPush(ctx, p)
+	default:
+		return fmt.Errorf("unknown notification channel %s: %w", p.Channel, asynq.SkipRetry)
+	}
+}
+
+// sendSMS sends the SMS of p, failed sends are retried and only recorded once the task
+// won't be retried anymore.
+func (ns *NotificationService) sendSMS(ctx context.Context, p job.NotificationTaskPayload) error {
+	if ns.server.SMS == nil {
+		return fmt.Errorf("sms notifications are not enabled: %w", asynq.SkipRetry)
+	}
+
+	logger := ns.server.Logger.With().Str("type", "notification").Str("channel", notify.ChannelSMS).Str("user_id", p.UserID).Logger()
+
+	msg := notify.Message{To: p.To, Body: p.Body}
+	if baseURL := ns.server.Config.Server.BaseURL; baseURL != "" {
+		msg.StatusCallbackURL = strings.TrimSuffix(baseURL, "/") + TwilioStatusCallbackPath
+	}
+
+	receipt, err := ns.server.SMS.Send(ctx, msg)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to send sms")
+
+		retried, _ := job.GetRetryCount(ctx)
+		maxRetry, _ := job.GetMaxRetry(ctx)
+		invalid := errors.Is(err, notify.ErrInvalidRecipient)
+		if invalid || retried >= maxRetry {
+			ns.recordDelivery(ctx, p, "twilio", p.To, nil, err)
+		}
+		if invalid {
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
+		return err
+	}
+
+	ns.recordDelivery(ctx, p, receipt.Provider, p.To, receipt, nil)
+
+	return nil
+}
+
+// sendPush sends the notification of p to every device of the user. Sends aren't retried,
+// so devices that got it don't get it twice; tokens the provider rejects are forgotten.
+func (ns *NotificationService) sendPush(ctx context.Context, p job.NotificationTaskPayload) error {
+	if ns.server.Push == nil {
+		return fmt.Errorf("push notifications are not enabled: %w", asynq.SkipRetry)
+	}
+
+	logger := ns.server.Logger.With().Str("type", "notification").Str("channel", notify.ChannelPush).Str("user_id", p.UserID).Logger()
+
+	devices, err := ns.repos.Notification.ListDevices(ctx, p.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, device := range devices {
+		receipt, err := ns.server.Push.Send(ctx, notify.Message{
+			To:       device.Token,
+			Provider: device.Provider,
+			Title:    p.Title,
+			Body:     p.Body,
+			Data:     p.Data,
+		})
+		if err != nil {
+			logger.Warn().Err(err).Str("device_id", device.ID.String()).Msg("failed to send push notification")
+
+			if errors.Is(err, notify.ErrInvalidRecipient) {
+				if err := ns.repos.Notification.DeleteDeviceByToken(ctx, device.Token); err != nil {
+					logger.Error().Err(err).Str("device_id", device.ID.String()).Msg("failed to forget invalid device")
+				}
+			}
+		}
+
+		ns.recordDelivery(ctx, p, device.Provider, device.Token, receipt, err)
+	}
+
+	return nil
+}
+
+// recordDelivery stores the outcome of a send, failing to is logged only: the message
+// went out (or didn't) either way.
+func (ns *NotificationService) recordDelivery(ctx context.Context, p job.NotificationTaskPayload, provider, recipient string, receipt *notify.Receipt, sendErr error) {
+	delivery := &model.NotificationDelivery{
+		UserID:    p.UserID,
+		Channel:   p.Channel,
+		Provider:  provider,
+		Recipient: recipient,
+		Status:    model.NotificationStatusFailed,
+	}
+
+	if sendErr != nil {
+		message := sendErr.Error()
+		delivery.Error = &message
+	} else {
+		delivery.Status = model.NotificationStatus(receipt.Status)
+		if receipt.ProviderMessageID != "" {
+			delivery.ProviderMessageID = &receipt.ProviderMessageID
+		}
+	}
+
+	if err := ns.repos.Notification.CreateDelivery(ctx, delivery); err != nil {
+		ns.server.Logger.Error().Err(err).Str("channel", p.Channel).Str("user_id", p.UserID).Msg("failed to record notification delivery")
+	}
+}
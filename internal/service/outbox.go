@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/hibiken/asynq"
+)
+
+// OutboxService backs the "outbox" overflow policy: it hands the job service the
+// outbox to persist tasks in, and periodically relays them once their queue has room.
+type OutboxService struct {
+	server *server.Server
+	repos  *repository.Repositories
+}
+
+func NewOutboxService(s *server.Server, repos *repository.Repositories) *OutboxService {
+	ob := &OutboxService{
+		server: s,
+		repos:  repos,
+	}
+
+	if s.Job != nil {
+		s.Job.SetOutbox(repos.JobOutbox)
+		s.Job.RegisterHandler(job.TaskOutboxRelay, ob.handleOutboxRelayTask)
+
+		cfg := s.Config.Jobs.Backpressure
+		if _, err := s.Job.RegisterPeriodic(cfg.RelaySchedule, job.NewOutboxRelayTask()); err != nil {
+			s.Logger.Error().Err(err).Str("schedule", cfg.RelaySchedule).Msg("failed to schedule outbox relay")
+		}
+	}
+
+	return ob
+}
+
+func (ob *OutboxService) handleOutboxRelayTask(ctx context.Context, t *asynq.Task) error {
+	_, err := ob.Relay(ctx)
+	return err
+}
+
+// Relay enqueues the oldest outboxed tasks whose queue has room again and returns
+// how many it moved. A task is deleted from the outbox in the same transaction, a
+// failed commit may enqueue it twice but never loses it.
+func (ob *OutboxService) Relay(ctx context.Context) (int, error) {
+	tx, err := ob.server.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tasks, err := ob.repos.JobOutbox.LockOldest(ctx, tx, ob.server.Config.Jobs.Backpressure.RelayBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	relayed := 0
+	full := make(map[string]bool)
+	for _, task := range tasks {
+		if full[task.Queue] || !ob.server.Job.HasCapacity(task.Queue) {
+			full[task.Queue] = true
+			continue
+		}
+
+		opts, err := job.RestoreOptions(task.Options)
+		if err != nil {
+			return relayed, fmt.Errorf("failed to relay outbox task %s: %w", task.ID, err)
+		}
+
+		// Enqueued without the backpressure check, going through Enqueue would apply
+		// the overflow policy again and outbox the task a second time. A task whose ID or
+		// unique key is taken was enqueued since, it is only removed from the outbox.
+		_, err = ob.server.Job.EnqueueUnchecked(ctx, job.NewTask(task.TaskType, task.Payload, opts...), asynq.Queue(task.Queue))
+		if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) && !errors.Is(err, asynq.ErrDuplicateTask) {
+			return relayed, fmt.Errorf("failed to relay outbox task %s: %w", task.ID, err)
+		}
+
+		if err := ob.repos.JobOutbox.Delete(ctx, tx, task.ID); err != nil {
+			return relayed, err
+		}
+		relayed++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return relayed, fmt.Errorf("failed to commit outbox relay: %w", err)
+	}
+
+	if len(tasks) > 0 {
+		ob.server.Logger.Info().Int("relayed", relayed).Int("pending", len(tasks)-relayed).Msg("relayed outbox tasks")
+	}
+
+	return relayed, nil
+}
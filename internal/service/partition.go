@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/observe"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/hibiken/asynq"
+)
+
+// PartitionService maintains the time-partitioned tables in database.PartitionedTables:
+// it keeps Premake partitions ready ahead of time and drops the ones past retention.
+type PartitionService struct {
+	server *server.Server
+}
+
+func NewPartitionService(s *server.Server) *PartitionService {
+	ps := &PartitionService{
+		server: s,
+	}
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskPartitionMaintenance, ps.handlePartitionMaintenanceTask)
+
+		cfg := s.Config.Partitions
+		if _, err := s.Job.RegisterPeriodic(cfg.Schedule, job.NewPartitionMaintenanceTask()); err != nil {
+			s.Logger.Error().Err(err).Str("schedule", cfg.Schedule).Msg("failed to schedule partition maintenance")
+		}
+	}
+
+	return ps
+}
+
+// handlePartitionMaintenanceTask maintains every partitioned table. A failing table
+// doesn't stop the others, the task is retried if any of them failed.
+func (ps *PartitionService) handlePartitionMaintenanceTask(ctx context.Context, t *asynq.Task) error {
+	var errList []error
+	for _, table := range database.PartitionedTables {
+		if err := ps.Maintain(ctx, table, time.Now()); err != nil {
+			ps.server.Logger.Error().Err(err).Str("table", table.Name).Msg("partition maintenance failed")
+			errList = append(errList, err)
+		}
+	}
+
+	return errors.Join(errList...)
+}
+
+// Maintain creates the upcoming partitions of table and, with a retention configured,
+// drops the expired ones.
+func (ps *PartitionService) Maintain(ctx context.Context, table database.PartitionedTable, now time.Time) error {
+	cfg := ps.server.Config.Partitions
+	logger := ps.server.Logger.With().Str("type", "partition_maintenance").Str("table", table.Name).Logger()
+
+	created, err := ps.server.DB.EnsurePartitions(ctx, table, now, cfg.Premake)
+	for _, p := range created {
+		logger.Info().Str("partition", p.Name).Time("from", p.From).Time("until", p.Until).Msg("created partition")
+	}
+	if err != nil {
+		return err
+	}
+
+	var dropped, skipped []database.Partition
+	if cfg.Retention > 0 {
+		// The archive job empties old audit log partitions, never drop rows it hasn't exported yet.
+		requireEmpty := table.Name == "audit_logs" && ps.server.Config.Archive.Enabled
+
+		dropped, skipped, err = ps.server.DB.DropPartitionsBefore(ctx, table, now.Add(-cfg.Retention), requireEmpty)
+		for _, p := range dropped {
+			logger.Info().Str("partition", p.Name).Time("from", p.From).Time("until", p.Until).Msg("dropped expired partition")
+		}
+		for _, p := range skipped {
+			logger.Warn().Str("partition", p.Name).Time("from", p.From).Time("until", p.Until).Msg("expired partition still holds unarchived rows, keeping it")
+		}
+	}
+
+	observe.Event("PartitionMaintenance", map[string]any{
+		"table":   table.Name,
+		"created": len(created),
+		"dropped": len(dropped),
+		"skipped": len(skipped),
+	})
+
+	return err
+}
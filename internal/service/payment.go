@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/payments"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/jackc/pgx/v5"
+)
+
+var codePaymentsDisabled = errs.Register("PAYMENTS_DISABLED", http.StatusNotFound, "No payments provider is configured on this deployment.")
+
+// SubscriptionChangedEvent is published on the event bus, after the commit, whenever a
+// webhook changed a subscription, with the *model.Subscription as payload. Subscribe to
+// it to react to plan changes, e.g. to update the owner's tenant limits.
+const SubscriptionChangedEvent = "payments.subscription_changed"
+
+// PaymentService bills owners, organizations or users (see middleware.GetQuotaConsumer),
+// through the configured payments provider and keeps their subscriptions in sync with it.
+type PaymentService struct {
+	server *server.Server
+	repos  *repository.Repositories
+}
+
+func NewPaymentService(s *server.Server, repos *repository.Repositories) *PaymentService {
+	return &PaymentService{
+		server: s,
+		repos:  repos,
+	}
+}
+
+// CreateCheckout starts a hosted checkout subscribing the calling tenant to the payload's price,
+// creating the owner's customer at the provider on its first checkout.
+func (ps *PaymentService) CreateCheckout(ctx context.Context, payload *model.CreateCheckoutPayload) (*model.CheckoutSession, error) {
+	ownerID := requestctx.Tenant(ctx)
+
+	provider, err := ps.provider()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := ps.server.Config.Payments
+	if len(cfg.Prices) > 0 && !slices.Contains(cfg.Prices, payload.PriceID) {
+		return nil, errs.BadRequestError("Unknown price", false, nil, []errs.FieldError{
+			{Field: "price_id", Error: "is not offered"},
+		}, nil)
+	}
+
+	customer, err := ps.customer(ctx, provider, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := provider.CreateCheckoutSession(ctx, payments.CheckoutParams{
+		CustomerID: customer.CustomerID,
+		OwnerID:    ownerID,
+		PriceID:    payload.PriceID,
+		SuccessURL: cfg.SuccessURL,
+		CancelURL:  cfg.CancelURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CheckoutSession{
+		ID:        session.ID,
+		URL:       session.URL,
+		ExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// ListSubscriptions returns the subscriptions of the calling tenant as last reported by the provider.
+func (ps *PaymentService) ListSubscriptions(ctx context.Context) ([]model.Subscription, error) {
+	ownerID := requestctx.Tenant(ctx)
+	if _, err := ps.provider(); err != nil {
+		return nil, err
+	}
+
+	return ps.repos.Payment.ListSubscriptions(ctx, ownerID)
+}
+
+// ListSubscriptionsByOwners returns the subscriptions of every owner of ownerIDs, keyed by
+// owner, in one query.
+func (ps *PaymentService) ListSubscriptionsByOwners(ctx context.Context, ownerIDs []string) (map[string][]model.Subscription, error) {
+	if _, err := ps.provider(); err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := ps.repos.Payment.ListSubscriptionsByOwners(ctx, ownerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byOwner := make(map[string][]model.Subscription, len(ownerIDs))
+	for _, subscription := range subscriptions {
+		byOwner[subscription.OwnerID] = append(byOwner[subscription.OwnerID], subscription)
+	}
+
+	return byOwner, nil
+}
+
+// HandleWebhook processes a webhook of the provider, whose signature was verified. Every
+// event is processed once: it is recorded in the transaction applying it, so redelivered
+// events are skipped, and events older than the stored state of a subscription are ignored.
+func (ps *PaymentService) HandleWebhook(ctx context.Context, payload []byte) error {
+	provider, err := ps.provider()
+	if err != nil {
+		return err
+	}
+
+	event, err := provider.ParseEvent(payload)
+	if err != nil {
+		if errors.Is(err, payments.ErrInvalidEvent) {
+			return errs.BadRequestError("Invalid payments event", false, nil, nil, nil)
+		}
+		return err
+	}
+
+	logger := ps.server.Logger.With().Str("provider", provider.Name()).Str("event_id", event.ID).Str("event_type", event.Type).Logger()
+
+	return ps.server.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		tx, _ := database.TxFromContext(ctx)
+
+		isNew, err := ps.repos.Payment.RecordEvent(ctx, tx, provider.Name(), event.ID, event.Type)
+		if err != nil {
+			return err
+		}
+		if !isNew {
+			logger.Debug().Msg("payments event already processed, skipping")
+			return nil
+		}
+
+		if event.Subscription == nil {
+			return nil
+		}
+
+		sub, err := ps.subscription(ctx, provider, event)
+		if err != nil {
+			return err
+		}
+		if sub == nil {
+			// Recorded all the same: redelivering it wouldn't make it any more usable
+			logger.Warn().Str("subscription_id", event.Subscription.ID).Str("status", event.Subscription.Status).
+				Msg("payments event of an unknown owner or status, skipping")
+			return nil
+		}
+
+		synced, err := ps.repos.Payment.SyncSubscription(ctx, tx, sub)
+		if err != nil {
+			return err
+		}
+		if !synced {
+			logger.Info().Str("subscription_id", sub.ProviderSubscriptionID).Msg("payments event older than the stored subscription, skipping")
+			return nil
+		}
+
+		logger.Info().Str("subscription_id", sub.ProviderSubscriptionID).Str("owner_id", sub.OwnerID).
+			Str("status", string(sub.Status)).Msg("synced subscription")
+
+		ps.server.Events.Publish(ctx, SubscriptionChangedEvent, sub)
+
+		return nil
+	})
+}
+
+// subscription maps the subscription of event to the stored model. It returns nil if
+// its owner can't be found or its status is unknown.
+func (ps *PaymentService) subscription(ctx context.Context, provider payments.Provider, event *payments.Event) (*model.Subscription, error) {
+	status, err := model.ParseSubscriptionStatus(event.Subscription.Status)
+	if err != nil {
+		return nil, nil
+	}
+
+	ownerID := event.Subscription.OwnerID
+	if ownerID == "" {
+		customer, err := ps.repos.Payment.GetCustomerByProviderID(ctx, provider.Name(), event.Subscription.CustomerID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		ownerID = customer.OwnerID
+	}
+
+	return &model.Subscription{
+		Provider:               provider.Name(),
+		ProviderSubscriptionID: event.Subscription.ID,
+		CustomerID:             event.Subscription.CustomerID,
+		OwnerID:                ownerID,
+		Status:                 status,
+		PriceID:                event.Subscription.PriceID,
+		CurrentPeriodEnd:       event.Subscription.CurrentPeriodEnd,
+		CancelAtPeriodEnd:      event.Subscription.CancelAtPeriodEnd,
+		CanceledAt:             event.Subscription.CanceledAt,
+		SyncedAt:               event.CreatedAt,
+	}, nil
+}
+
+// customer returns the provider customer of ownerID, creating it if it has none. The
+// owner is the idempotency key, so retried or concurrent first checkouts create one customer.
+func (ps *PaymentService) customer(ctx context.Context, provider payments.Provider, ownerID string) (*model.PaymentCustomer, error) {
+	customer, err := ps.repos.Payment.GetCustomer(ctx, ownerID)
+	if err == nil {
+		return customer, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	created, err := provider.CreateCustomer(ctx, payments.CustomerParams{
+		OwnerID:        ownerID,
+		IdempotencyKey: "customer:" + ownerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ps.repos.Payment.CreateCustomer(ctx, ownerID, provider.Name(), created.ID)
+}
+
+func (ps *PaymentService) provider() (payments.Provider, error) {
+	if ps.server.Payments == nil {
+		code := codePaymentsDisabled
+		return nil, errs.NotFoundError("Payments are not enabled", true, &code)
+	}
+	return ps.server.Payments, nil
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/google/uuid"
+)
+
+// reportProgress reports the progress of an operation for clients following it live.
+// Failing to report never fails the operation itself, it is logged instead.
+func reportProgress(ctx context.Context, s *server.Server, operationID uuid.UUID, state string, percent int, message string) {
+	if s.Progress == nil {
+		return
+	}
+
+	if err := s.Progress.Report(ctx, operationID.String(), state, percent, message); err != nil {
+		s.Logger.Warn().Err(err).Str("operation_id", operationID.String()).Str("state", state).Msg("failed to report progress")
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/quota"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+)
+
+// QuotaService exposes quota usage and keeps the Redis counters backed by Postgres snapshots,
+// so usage survives a Redis restart.
+type QuotaService struct {
+	server *server.Server
+	repos  *repository.Repositories
+	stop   chan struct{}
+}
+
+func NewQuotaService(s *server.Server, repos *repository.Repositories) *QuotaService {
+	qs := &QuotaService{
+		server: s,
+		repos:  repos,
+		stop:   make(chan struct{}),
+	}
+
+	if s.Config.Quota.Enabled && s.Quota != nil {
+		qs.restore()
+		go qs.snapshotLoop()
+		s.OnShutdown(qs.shutdown)
+	}
+
+	return qs
+}
+
+// GetUsage returns the calling tenant's current usage against limits without counting a request.
+func (s *QuotaService) GetUsage(ctx context.Context, limits quota.Limits) (*quota.Usage, error) {
+	consumer := requestctx.Tenant(ctx)
+
+	usage, err := s.server.Quota.Get(ctx, consumer, limits, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// Snapshot persists the current counters to Postgres.
+func (s *QuotaService) Snapshot(ctx context.Context) error {
+	counters, err := s.server.Quota.Counters(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return s.repos.Quota.SaveSnapshot(ctx, counters)
+}
+
+// restore seeds Redis with the last snapshot of the current periods. Counters still in Redis are kept.
+func (s *QuotaService) restore() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) //nolint:forbidigo // runs at startup, there is no caller context
+	defer cancel()
+
+	now := time.Now()
+	counters, err := s.repos.Quota.ListCurrentPeriods(ctx, now)
+	if err != nil {
+		s.server.Logger.Error().Err(err).Msg("failed to load quota usage snapshot")
+		return
+	}
+
+	for _, counter := range counters {
+		if err := s.server.Quota.Seed(ctx, counter, now); err != nil {
+			s.server.Logger.Error().Err(err).Msg("failed to restore quota usage")
+			return
+		}
+	}
+
+	s.server.Logger.Info().Int("counters", len(counters)).Msg("restored quota usage from snapshot")
+}
+
+func (s *QuotaService) snapshotLoop() {
+	ticker := time.NewTicker(s.server.Config.Quota.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second) //nolint:forbidigo // background loop, there is no caller context
+			if err := s.Snapshot(ctx); err != nil {
+				s.server.Logger.Error().Err(err).Msg("failed to snapshot quota usage")
+			}
+			cancel()
+		}
+	}
+}
+
+// shutdown stops the snapshot loop and takes a final snapshot.
+func (s *QuotaService) shutdown(ctx context.Context) {
+	close(s.stop)
+
+	if err := s.Snapshot(ctx); err != nil {
+		s.server.Logger.Error().Err(err).Msg("failed to snapshot quota usage on shutdown")
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/routetoggle"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+)
+
+// Audit actions recorded when operators disable and enable routes.
+const (
+	AuditActionRouteDisabled = "routes.disabled"
+	AuditActionRouteEnabled  = "routes.enabled"
+)
+
+// RouteToggleService disables and enables routes at runtime, e.g. to quarantine a
+// misbehaving endpoint during an incident. Changes apply on every instance within
+// routetoggle.RefreshInterval.
+type RouteToggleService struct {
+	server      *server.Server
+	configAudit *ConfigAuditService
+}
+
+func NewRouteToggleService(s *server.Server, configAudit *ConfigAuditService) *RouteToggleService {
+	return &RouteToggleService{
+		server:      s,
+		configAudit: configAudit,
+	}
+}
+
+// ListDisabledRoutes returns the disabled routes.
+func (rs *RouteToggleService) ListDisabledRoutes() []routetoggle.Toggle {
+	return rs.server.RouteToggles.List()
+}
+
+// DisableRoute disables a route, the caller checks it is a registered one.
+func (rs *RouteToggleService) DisableRoute(ctx context.Context, payload *model.DisableRoutePayload) (*routetoggle.Toggle, error) {
+	actorID := requestctx.UserID(ctx)
+
+	toggle := routetoggle.Toggle{
+		Method:     payload.Method,
+		Path:       payload.Path,
+		Reason:     payload.Reason,
+		DisabledBy: actorID,
+		DisabledAt: rs.server.Clock.Now().UTC(),
+	}
+
+	previous, err := rs.server.RouteToggles.Disable(ctx, toggle)
+	if err != nil {
+		return nil, err
+	}
+
+	rs.server.Logger.Warn().
+		Str("route", routetoggle.Name(toggle.Method, toggle.Path)).
+		Str("reason", toggle.Reason).
+		Str("actor_id", actorID).
+		Msg("route disabled")
+
+	rs.recordChange(ctx, actorID, AuditActionRouteDisabled, toggle.Method, toggle.Path, toggleValue(previous), toggle)
+
+	return &toggle, nil
+}
+
+// EnableRoute enables a disabled route again.
+func (rs *RouteToggleService) EnableRoute(ctx context.Context, method, path string) error {
+	actorID := requestctx.UserID(ctx)
+
+	previous, err := rs.server.RouteToggles.Enable(ctx, method, path)
+	if err != nil {
+		return err
+	}
+
+	if previous == nil {
+		return errs.NotFoundError("Route is not disabled", false, nil)
+	}
+
+	rs.server.Logger.Info().
+		Str("route", routetoggle.Name(method, path)).
+		Str("actor_id", actorID).
+		Msg("route enabled")
+
+	rs.recordChange(ctx, actorID, AuditActionRouteEnabled, method, path, toggleValue(previous), nil)
+
+	return nil
+}
+
+// recordChange records a change of a route's toggle in the configuration change
+// history, scoped to the route.
+func (rs *RouteToggleService) recordChange(ctx context.Context, actorID, action, method, path string, previous, current any) {
+	rs.configAudit.Record(ctx, ConfigChange{
+		ActorID: actorID,
+		Action:  action,
+		Setting: "route_toggles",
+		Scope:   routetoggle.Name(method, path),
+		Old:     previous,
+		New:     current,
+	})
+}
+
+func toggleValue(toggle *routetoggle.Toggle) any {
+	if toggle == nil {
+		return nil
+	}
+
+	return *toggle
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/bruteforce"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/events"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+)
+
+// SecurityService writes security events raised by the middleware, such as
+// authentication lockouts, to the audit log.
+type SecurityService struct {
+	server *server.Server
+	repos  *repository.Repositories
+}
+
+func NewSecurityService(s *server.Server, repos *repository.Repositories) *SecurityService {
+	ss := &SecurityService{
+		server: s,
+		repos:  repos,
+	}
+
+	s.Events.Subscribe(bruteforce.LockoutEvent, ss.recordLockout)
+
+	return ss
+}
+
+func (ss *SecurityService) recordLockout(ctx context.Context, event events.Event) {
+	lockout, ok := event.Payload.(bruteforce.Lockout)
+	if !ok {
+		return
+	}
+
+	entry := &model.AuditLog{
+		ActorID:      model.AuditActorSystem,
+		Action:       "auth.locked_out",
+		ResourceType: model.AuditResourceAuth,
+		ResourceID:   &lockout.Key,
+		Metadata: map[string]any{
+			"failures":     lockout.Failures,
+			"locked_until": lockout.Until.UTC().Format(time.RFC3339),
+			"ip":           lockout.IP,
+			"path":         lockout.Path,
+		},
+	}
+
+	if requestID := database.RequestIDFromContext(ctx); requestID != "" {
+		entry.RequestID = &requestID
+	}
+
+	if err := ss.repos.Audit.Create(ctx, entry); err != nil {
+		ss.server.Logger.Error().Err(err).Str("key", lockout.Key).Msg("failed to record authentication lockout")
+	}
+}
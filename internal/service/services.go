@@ -7,15 +7,79 @@ import (
 )
 
 type Services struct {
-	AuthService *AuthService
-	Job         *job.JobService
+	AuthService       *AuthService
+	ComplianceService *ComplianceService
+	QuotaService      *QuotaService
+	DigestService     *DigestService
+	BatchEmailService *BatchEmailService
+	EmailService      *EmailService
+	ArchiveService    *ArchiveService
+	BackupService     *BackupService
+	PartitionService  *PartitionService
+	BackfillService   *BackfillService
+	AdminService      *AdminService
+	OutboxService     *OutboxService
+	TenantService     *TenantService
+	ConfigAudit       *ConfigAuditService
+	SecurityService   *SecurityService
+	HealthService     *HealthService
+	ExportService     *ExportService
+	ImportService     *ImportService
+	PaymentService    *PaymentService
+	Notification      *NotificationService
+	AuthzService      *AuthzService
+	UsageService      *UsageService
+	SettingsService   *SettingsService
+	RouteToggles      *RouteToggleService
+	EmailVerification *EmailVerificationService
+	Job               *job.JobService
 }
 
 func NewService(s *server.Server, repos *repository.Repositories) (*Services, error) {
 	authService := NewAuthService(s)
+	configAudit := NewConfigAuditService(s, repos)
+
+	archiveService, err := NewArchiveService(s, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	backupService, err := NewBackupService(s, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	settingsService, err := NewSettingsService(s, repos, configAudit)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Services{
-		AuthService: authService,
-		Job:         s.Job,
+		AuthService:       authService,
+		ComplianceService: NewComplianceService(s, repos),
+		QuotaService:      NewQuotaService(s, repos),
+		DigestService:     NewDigestService(s, repos),
+		BatchEmailService: NewBatchEmailService(s, repos),
+		EmailService:      NewEmailService(s, repos),
+		ArchiveService:    archiveService,
+		BackupService:     backupService,
+		PartitionService:  NewPartitionService(s),
+		BackfillService:   NewBackfillService(s),
+		AdminService:      NewAdminService(s, repos),
+		OutboxService:     NewOutboxService(s, repos),
+		TenantService:     NewTenantService(s, repos, configAudit),
+		ConfigAudit:       configAudit,
+		SecurityService:   NewSecurityService(s, repos),
+		HealthService:     NewHealthService(s, repos),
+		ExportService:     NewExportService(s, repos),
+		ImportService:     NewImportService(s, repos),
+		PaymentService:    NewPaymentService(s, repos),
+		Notification:      NewNotificationService(s, repos),
+		AuthzService:      NewAuthzService(s, repos, configAudit),
+		UsageService:      NewUsageService(s, repos),
+		SettingsService:   settingsService,
+		RouteToggles:      NewRouteToggleService(s, configAudit),
+		EmailVerification: NewEmailVerificationService(s, repos),
+		Job:               s.Job,
 	}, nil
 }
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/settings"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/warmup"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+)
+
+// Audit actions recorded when operators change runtime settings.
+const (
+	AuditActionSettingUpdated = "settings.updated"
+	AuditActionSettingReset   = "settings.reset"
+)
+
+// Runtime settings of the application, read with server.Settings, e.g.
+// s.Settings.String(ctx, SettingSupportEmail).
+const (
+	SettingSupportEmail  = "support_email"
+	SettingBannerEnabled = "banner_enabled"
+	SettingBannerText    = "banner_text"
+)
+
+var appSettings = []settings.Definition{
+	{Key: SettingSupportEmail, Type: settings.TypeString, Default: "", Description: "Address users are told to contact for support"},
+	{Key: SettingBannerEnabled, Type: settings.TypeBool, Default: false, Description: "Whether the banner is shown to users"},
+	{Key: SettingBannerText, Type: settings.TypeString, Default: "", Description: "Text of the banner, e.g. announcing maintenance"},
+}
+
+// SettingsService manages the runtime settings stored in Postgres, which the settings
+// store caches in memory and Redis.
+type SettingsService struct {
+	server      *server.Server
+	repos       *repository.Repositories
+	configAudit *ConfigAuditService
+}
+
+func NewSettingsService(s *server.Server, repos *repository.Repositories, configAudit *ConfigAuditService) (*SettingsService, error) {
+	ss := &SettingsService{
+		server:      s,
+		repos:       repos,
+		configAudit: configAudit,
+	}
+
+	if s.Settings != nil {
+		if err := s.Settings.Register(appSettings...); err != nil {
+			return nil, fmt.Errorf("failed to register settings: %w", err)
+		}
+		s.Settings.SetLoader(ss.loadSettings)
+
+		s.Warmer.Register(warmup.Primer{
+			Name: "app_settings",
+			Prime: func(ctx context.Context) error {
+				_, err := s.Settings.Stored(ctx)
+				return err
+			},
+		})
+	}
+
+	return ss, nil
+}
+
+// ListSettings returns every declared setting with its current value.
+func (ss *SettingsService) ListSettings(ctx context.Context) ([]model.Setting, error) {
+	stored, err := ss.repos.Setting.ListSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*model.AppSetting, len(stored))
+	for i := range stored {
+		byKey[stored[i].Key] = &stored[i]
+	}
+
+	definitions := ss.server.Settings.Definitions()
+	result := make([]model.Setting, 0, len(definitions))
+	for _, def := range definitions {
+		result = append(result, ss.setting(def, byKey[def.Key]))
+	}
+
+	return result, nil
+}
+
+// GetSetting returns a setting with its current value.
+func (ss *SettingsService) GetSetting(ctx context.Context, key string) (*model.Setting, error) {
+	def, err := ss.definition(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := ss.repos.Setting.GetSetting(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	setting := ss.setting(def, stored)
+	return &setting, nil
+}
+
+// SetSetting changes the value of a setting, it applies once the cached values are
+// invalidated, which happens right away unless Redis is unavailable.
+func (ss *SettingsService) SetSetting(ctx context.Context, key string, payload *model.UpdateSettingPayload) (*model.Setting, error) {
+	actorID := requestctx.UserID(ctx)
+
+	def, err := ss.definition(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := def.Check(payload.Value); err != nil {
+		return nil, errs.BadRequestError("Invalid setting value", false, nil, []errs.FieldError{
+			{Field: "value", Error: err.Error()},
+		}, nil)
+	}
+
+	previous, err := ss.repos.Setting.GetSetting(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := ss.repos.Setting.UpsertSetting(ctx, key, payload.Value, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	ss.invalidate(ctx)
+	ss.recordChange(ctx, actorID, AuditActionSettingUpdated, key, previous, stored)
+
+	setting := ss.setting(def, stored)
+	return &setting, nil
+}
+
+// ResetSetting removes the stored value of a setting, returning it to its default.
+func (ss *SettingsService) ResetSetting(ctx context.Context, key string) error {
+	actorID := requestctx.UserID(ctx)
+
+	if _, err := ss.definition(key); err != nil {
+		return err
+	}
+
+	previous, err := ss.repos.Setting.GetSetting(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := ss.repos.Setting.DeleteSetting(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return errs.NotFoundError("Setting already has its default value", false, nil)
+	}
+
+	ss.invalidate(ctx)
+	ss.recordChange(ctx, actorID, AuditActionSettingReset, key, previous, nil)
+
+	return nil
+}
+
+func (ss *SettingsService) definition(key string) (settings.Definition, error) {
+	def, ok := ss.server.Settings.Definition(key)
+	if !ok {
+		return settings.Definition{}, errs.NotFoundError("Setting not found", false, nil)
+	}
+	return def, nil
+}
+
+// setting merges the declaration of a setting with its stored value, nil if it has its default.
+func (ss *SettingsService) setting(def settings.Definition, stored *model.AppSetting) model.Setting {
+	defaultValue, _ := ss.server.Settings.Default(def.Key)
+
+	setting := model.Setting{
+		Key:         def.Key,
+		Type:        string(def.Type),
+		Description: def.Description,
+		Value:       defaultValue,
+		Default:     defaultValue,
+	}
+
+	if stored != nil {
+		setting.Value = stored.Value
+		setting.Overridden = true
+		setting.UpdatedBy = stored.UpdatedBy
+		setting.UpdatedAt = &stored.UpdatedAt
+	}
+
+	return setting
+}
+
+// loadSettings is the settings store's loader.
+func (ss *SettingsService) loadSettings(ctx context.Context) (map[string]json.RawMessage, error) {
+	stored, err := ss.repos.Setting.ListSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]json.RawMessage, len(stored))
+	for _, setting := range stored {
+		values[setting.Key] = setting.Value
+	}
+
+	return values, nil
+}
+
+// invalidate drops the cached values. On failure the old ones stay in effect until the cache expires.
+func (ss *SettingsService) invalidate(ctx context.Context) {
+	if err := ss.server.Settings.Invalidate(ctx); err != nil {
+		ss.server.Logger.Error().Err(err).Msg("failed to invalidate cached settings")
+	}
+}
+
+// recordChange records a change of a setting in the configuration change history,
+// settings are global so the change has no scope.
+func (ss *SettingsService) recordChange(ctx context.Context, actorID, action, key string, previous, current *model.AppSetting) {
+	ss.configAudit.Record(ctx, ConfigChange{
+		ActorID: actorID,
+		Action:  action,
+		Setting: key,
+		Old:     settingValue(previous),
+		New:     settingValue(current),
+	})
+}
+
+func settingValue(setting *model.AppSetting) any {
+	if setting == nil {
+		return nil
+	}
+
+	return setting.Value
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/requestctx"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/tenantlimits"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/warmup"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+)
+
+// Audit actions recorded when operators change tenant limits.
+const (
+	AuditActionTenantLimitsUpdated = "tenant.limits.updated"
+	AuditActionTenantLimitsDeleted = "tenant.limits.deleted"
+)
+
+// maxPrimedOverrides bounds how many tenant overrides are primed at startup.
+const maxPrimedOverrides = 1000
+
+// TenantService manages the per-organization overrides of the rate limit and quotas.
+// It backs the tenant limit resolver with Postgres, which caches overrides in Redis.
+type TenantService struct {
+	server      *server.Server
+	repos       *repository.Repositories
+	configAudit *ConfigAuditService
+}
+
+func NewTenantService(s *server.Server, repos *repository.Repositories, configAudit *ConfigAuditService) *TenantService {
+	ts := &TenantService{
+		server:      s,
+		repos:       repos,
+		configAudit: configAudit,
+	}
+
+	if s.TenantLimits != nil {
+		s.TenantLimits.SetLoader(ts.loadOverride)
+
+		s.Warmer.Register(warmup.Primer{
+			Name:  "tenant_limits",
+			Prime: ts.primeOverrides,
+		})
+	}
+
+	return ts
+}
+
+// GetLimits returns the overrides of an organization.
+func (ts *TenantService) GetLimits(ctx context.Context, organizationID string) (*model.TenantLimits, error) {
+	limits, err := ts.repos.Tenant.GetLimits(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limits == nil {
+		return nil, errs.NotFoundError("Organization has no limit overrides", false, nil)
+	}
+
+	return limits, nil
+}
+
+// GetLimitsByOrganizations returns the overrides of every organization of organizationIDs
+// that has some, keyed by organization, in one query.
+func (ts *TenantService) GetLimitsByOrganizations(ctx context.Context, organizationIDs []string) (map[string]*model.TenantLimits, error) {
+	limits, err := ts.repos.Tenant.GetLimitsByOrganizations(ctx, organizationIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byOrganization := make(map[string]*model.TenantLimits, len(limits))
+	for i := range limits {
+		byOrganization[limits[i].OrganizationID] = &limits[i]
+	}
+
+	return byOrganization, nil
+}
+
+// ListOrganizationIDs returns the organizations with overrides, most recently updated first.
+func (ts *TenantService) ListOrganizationIDs(ctx context.Context, limit int) ([]string, error) {
+	return ts.repos.Tenant.ListOrganizationIDs(ctx, limit)
+}
+
+// SetLimits replaces the overrides of an organization, they apply once the cached
+// overrides are invalidated, which happens right away unless Redis is unavailable.
+func (ts *TenantService) SetLimits(ctx context.Context, organizationID string, payload *model.UpdateTenantLimitsPayload) (*model.TenantLimits, error) {
+	actorID := requestctx.UserID(ctx)
+
+	previous, err := ts.repos.Tenant.GetLimits(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := ts.repos.Tenant.UpsertLimits(ctx, organizationID, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.invalidate(ctx, organizationID)
+	ts.recordChange(ctx, actorID, AuditActionTenantLimitsUpdated, organizationID, previous, limits)
+
+	return limits, nil
+}
+
+// DeleteLimits removes the overrides of an organization, returning it to the defaults.
+func (ts *TenantService) DeleteLimits(ctx context.Context, organizationID string) error {
+	actorID := requestctx.UserID(ctx)
+
+	previous, err := ts.repos.Tenant.GetLimits(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := ts.repos.Tenant.DeleteLimits(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return errs.NotFoundError("Organization has no limit overrides", false, nil)
+	}
+
+	ts.invalidate(ctx, organizationID)
+	ts.recordChange(ctx, actorID, AuditActionTenantLimitsDeleted, organizationID, previous, nil)
+
+	return nil
+}
+
+// primeOverrides resolves the overrides of the most recently updated organizations, so
+// they are in the local cache tier, and in Redis if it was empty, before their requests
+// arrive. Tenants without an override get the defaults and need no priming.
+func (ts *TenantService) primeOverrides(ctx context.Context) error {
+	// Priming more than the local tier holds would only evict what was primed first
+	limit := maxPrimedOverrides
+	if localSize := ts.server.Config.Cache.LocalSize; localSize > 0 && localSize < limit {
+		limit = localSize
+	}
+
+	organizationIDs, err := ts.repos.Tenant.ListOrganizationIDs(ctx, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, organizationID := range organizationIDs {
+		if _, err := ts.server.TenantLimits.Resolve(ctx, organizationID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadOverride is the tenant limit resolver's loader.
+func (ts *TenantService) loadOverride(ctx context.Context, organizationID string) (*tenantlimits.Override, error) {
+	limits, err := ts.repos.Tenant.GetLimits(ctx, organizationID)
+	if err != nil || limits == nil {
+		return nil, err
+	}
+
+	return &tenantlimits.Override{
+		Plan:              limits.Plan,
+		RequestsPerMinute: limits.RequestsPerMinute,
+		DailyQuota:        limits.DailyQuota,
+		MonthlyQuota:      limits.MonthlyQuota,
+	}, nil
+}
+
+// invalidate drops the cached overrides. On failure the old ones stay in effect until the cache expires.
+func (ts *TenantService) invalidate(ctx context.Context, organizationID string) {
+	if err := ts.server.TenantLimits.Invalidate(ctx, organizationID); err != nil {
+		ts.server.Logger.Error().Err(err).Str("organization_id", organizationID).Dur("cache_ttl", ts.server.Config.RateLimit.OverrideCacheTTL).Msg("failed to invalidate cached tenant limits")
+	}
+}
+
+// recordChange records a change of an organization's overrides in the configuration
+// change history. Only the limits are compared, not when the row was written.
+func (ts *TenantService) recordChange(ctx context.Context, actorID, action, organizationID string, previous, current *model.TenantLimits) {
+	ts.configAudit.Record(ctx, ConfigChange{
+		ActorID: actorID,
+		Action:  action,
+		Setting: "tenant_limits",
+		Scope:   organizationID,
+		Old:     limitValues(previous),
+		New:     limitValues(current),
+	})
+}
+
+func limitValues(limits *model.TenantLimits) map[string]any {
+	if limits == nil {
+		return nil
+	}
+
+	return map[string]any{
+		"plan":                limits.Plan,
+		"requests_per_minute": limits.RequestsPerMinute,
+		"daily_quota":         limits.DailyQuota,
+		"monthly_quota":       limits.MonthlyQuota,
+	}
+}
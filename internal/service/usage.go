@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/email"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/lib/job"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/model"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/repository"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/server"
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/user"
+	"github.com/hibiken/asynq"
+)
+
+// UsageService rolls up the API usage counted by the usage middleware and notifies the
+// users still calling deprecated routes, weekly by default.
+type UsageService struct {
+	server      *server.Server
+	repos       *repository.Repositories
+	emailClient *email.Client
+}
+
+func NewUsageService(s *server.Server, repos *repository.Repositories) *UsageService {
+	us := &UsageService{
+		server:      s,
+		repos:       repos,
+		emailClient: email.NewClient(s.Config, s.Logger),
+	}
+
+	if s.Job != nil {
+		s.Job.RegisterHandler(job.TaskUsageRollup, us.handleUsageRollupTask)
+		s.Job.RegisterHandler(job.TaskDeprecationDispatch, us.handleDeprecationDispatchTask)
+		s.Job.RegisterHandler(job.TaskDeprecationNotice, us.handleDeprecationNoticeTask)
+
+		cfg := s.Config.Usage.DeprecationNotices
+		if cfg.Enabled && !s.Config.Usage.Disabled {
+			if _, err := s.Job.RegisterPeriodic(cfg.Schedule, job.NewDeprecationDispatchTask(cfg.Lookback)); err != nil {
+				s.Logger.Error().Err(err).Str("schedule", cfg.Schedule).Msg("failed to schedule deprecation notices")
+			}
+		}
+	}
+
+	return us
+}
+
+// ListUsage returns a page of the usage rollup.
+func (us *UsageService) ListUsage(ctx context.Context, params model.ListParams) (*model.CursorPage[model.APIUsage], error) {
+	usage, info, err := us.repos.Usage.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CursorPage[model.APIUsage]{Items: usage, CursorInfo: info}, nil
+}
+
+// handleUsageRollupTask adds the counts flushed by an instance to the rollup.
+func (us *UsageService) handleUsageRollupTask(ctx context.Context, t *asynq.Task) error {
+	var p job.UsageRollupTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal usage rollup payload: %w", err)
+	}
+
+	return us.repos.Usage.AddCounts(ctx, p.Counts)
+}
+
+// handleDeprecationDispatchTask enqueues a deprecation notice for every user who called a
+// deprecated route within the lookback. Like the digest dispatch, enqueue failures are
+// logged per user and users already enqueued for this period are skipped by task ID.
+func (us *UsageService) handleDeprecationDispatchTask(ctx context.Context, t *asynq.Task) error {
+	// Truncate so retries of this dispatch cover the same period.
+	since := time.Now().UTC().Truncate(24 * time.Hour).Add(-us.server.Config.Usage.DeprecationNotices.Lookback)
+
+	logger := us.server.Logger.With().Str("type", "deprecation_dispatch").Time("since", since).Logger()
+
+	userIDs, err := us.repos.Usage.ListDeprecatedUsers(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	var enqueued, skipped, failed int
+	for _, userID := range userIDs {
+		task, err := job.NewDeprecationNoticeTask(userID, since)
+		if err != nil {
+			failed++
+			logger.Error().Err(err).Str("user_id", userID).Msg("failed to create deprecation notice task")
+			continue
+		}
+
+		if _, err := us.server.Job.Enqueue(ctx, task); err != nil {
+			if errors.Is(err, asynq.ErrTaskIDConflict) {
+				skipped++
+				continue
+			}
+			failed++
+			logger.Error().Err(err).Str("user_id", userID).Msg("failed to enqueue deprecation notice task")
+			continue
+		}
+		enqueued++
+	}
+
+	logger.Info().Int("enqueued", enqueued).Int("already_enqueued", skipped).Int("failed", failed).Msg("dispatched deprecation notices")
+
+	return nil
+}
+
+// handleDeprecationNoticeTask emails one user the deprecated routes they called.
+func (us *UsageService) handleDeprecationNoticeTask(ctx context.Context, t *asynq.Task) error {
+	var p job.DeprecationNoticeTaskPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal deprecation notice payload: %w", err)
+	}
+
+	logger := us.server.Logger.With().Str("type", "deprecation_notice").Str("user_id", p.UserID).Logger()
+
+	routes, err := us.repos.Usage.ListDeprecatedRoutes(ctx, p.UserID, p.Since)
+	if err != nil {
+		return err
+	}
+	if len(routes) == 0 {
+		logger.Debug().Msg("no deprecated routes called in period, skipping notice")
+		return nil
+	}
+
+	u, err := user.Get(ctx, p.UserID)
+	if err != nil {
+		// The user was deleted since the dispatch, there is nobody to send to.
+		var apiErr *clerk.APIErrorResponse
+		if errors.As(err, &apiErr) && apiErr.HTTPStatusCode == http.StatusNotFound {
+			logger.Info().Msg("user no longer exists, skipping deprecation notice")
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	address := primaryEmailAddress(u)
+	if address == "" {
+		logger.Info().Msg("user has no primary email address, skipping deprecation notice")
+		return nil
+	}
+
+	suppressed, err := us.repos.EmailSuppression.IsSuppressed(ctx, address)
+	if err != nil {
+		return err
+	}
+	if suppressed {
+		logger.Info().Msg("email address is suppressed, skipping deprecation notice")
+		return nil
+	}
+
+	lines := make([]email.DeprecatedRoute, 0, len(routes))
+	for _, route := range routes {
+		lines = append(lines, email.DeprecatedRoute{
+			Route:      route.Method + " " + route.Route,
+			Requests:   route.Requests,
+			LastSeenAt: route.LastSeenAt,
+		})
+	}
+
+	firstName := ""
+	if u.FirstName != nil {
+		firstName = *u.FirstName
+	}
+
+	if err := us.server.EmailThrottle.Wait(ctx, email.ProviderResend); err != nil {
+		return err
+	}
+
+	if err := us.emailClient.SendDeprecationNoticeEmail(address, firstName, p.Since, lines); err != nil {
+		logger.Error().Err(err).Msg("deprecation notice sending failed")
+		return err
+	}
+
+	logger.Info().Int("routes", len(lines)).Msg("successfully sent deprecation notice")
+
+	return nil
+}
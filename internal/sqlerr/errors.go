@@ -20,6 +20,8 @@ const (
 	ForeignKeyViolation Code = "foreign_key_violation "
 
 	TooManyConnections Code = "too_many_connections"
+
+	QueryCanceled Code = "query_canceled"
 )
 
 func MapDatabaseErrorCode(code string) Code {
@@ -40,6 +42,8 @@ func MapDatabaseErrorCode(code string) Code {
 		return ForeignKeyViolation
 	case "53300":
 		return TooManyConnections
+	case "57014":
+		return QueryCanceled
 	default:
 		return Other
 
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 
@@ -15,6 +16,16 @@ import (
 	"golang.org/x/text/language"
 )
 
+var codeDatabaseTimeout = errs.Register("DATABASE_TIMEOUT", http.StatusGatewayTimeout, "A database statement of the request ran over its timeout.")
+
+// Codes generated from the table a constraint violation happened on, see generateErrorCode.
+var (
+	_ = errs.RegisterPattern("{ENTITY}_NOT_FOUND", http.StatusBadRequest, "A referenced record doesn't exist (foreign key violation).")
+	_ = errs.RegisterPattern("{ENTITY}_ALREADY_EXISTS", http.StatusBadRequest, "A record with the same unique value already exists.")
+	_ = errs.RegisterPattern("{ENTITY}_REQUIRED", http.StatusBadRequest, "A required value is missing, fields names it.")
+	_ = errs.RegisterPattern("{ENTITY}_INVALID", http.StatusBadRequest, "A value breaks a check constraint.")
+)
+
 // ErrCode extracts the mapped error code from a database error.
 // If the provided error is nil or not a database-related error, it returns Other.
 func ErrCode(err error) Code {
@@ -197,6 +208,11 @@ func HandleError(err error) error {
 		case CheckViolation:
 			return errs.BadRequestError(userMessage, true, &errorCode, nil, nil)
 
+		case QueryCanceled:
+			// Raised when the statement_timeout of the request ran out, see database.WithStatementTimeout
+			timeoutCode := codeDatabaseTimeout
+			return errs.GatewayTimeoutError("The request took too long to complete, please try again later", true, &timeoutCode)
+
 		default:
 			return errs.InternalServerError()
 		}
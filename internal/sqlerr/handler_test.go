@@ -0,0 +1,116 @@
+package sqlerr_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/Barry-dE/go-backend-boilerplate/internal/sqlerr"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		msg    string
+		fields []errs.FieldError
+	}{
+		{
+			name: "unique violation",
+			err: &pgconn.PgError{
+				Code:           "23505",
+				Severity:       "ERROR",
+				TableName:      "users",
+				ConstraintName: "users_email_key",
+			},
+			status: http.StatusBadRequest,
+			code:   "USER_ALREADY_EXISTS",
+			msg:    "A User with this Email already exists",
+		},
+		{
+			name: "foreign key violation",
+			err: &pgconn.PgError{
+				Code:           "23503",
+				Severity:       "ERROR",
+				TableName:      "todos",
+				ConstraintName: "todos_category_id_fkey",
+			},
+			status: http.StatusBadRequest,
+			code:   "TODO_NOT_FOUND",
+			msg:    "The referenced Todo does not exist",
+		},
+		{
+			name: "not null violation",
+			err: &pgconn.PgError{
+				Code:       "23502",
+				Severity:   "ERROR",
+				TableName:  "todos",
+				ColumnName: "title",
+			},
+			status: http.StatusBadRequest,
+			code:   "TODO_REQUIRED",
+			msg:    "The Title is required",
+			fields: []errs.FieldError{{Field: "title", Error: "is required"}},
+		},
+		{
+			name:   "wrapped violation",
+			err:    fmt.Errorf("failed to create todo: %w", &pgconn.PgError{Code: "23505", Severity: "ERROR", TableName: "todos"}),
+			status: http.StatusBadRequest,
+			code:   "TODO_ALREADY_EXISTS",
+			msg:    "A Todo with this identifier already exists",
+		},
+		{
+			name:   "no rows",
+			err:    pgx.ErrNoRows,
+			status: http.StatusNotFound,
+			code:   "NOT_FOUND",
+			msg:    "Resource not found",
+		},
+		{
+			name:   "no rows of a table",
+			err:    fmt.Errorf("table:todos: %w", pgx.ErrNoRows),
+			status: http.StatusNotFound,
+			code:   "NOT_FOUND",
+			msg:    "Todo not found",
+		},
+		{
+			name:   "unknown error",
+			err:    errors.New("connection reset"),
+			status: http.StatusInternalServerError,
+			code:   "INTERNAL_SERVER_ERROR",
+			msg:    "Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var httpErr *errs.HttpError
+			require.ErrorAs(t, sqlerr.HandleError(tt.err), &httpErr)
+
+			assert.Equal(t, tt.status, httpErr.Status)
+			assert.Equal(t, tt.code, httpErr.Code)
+			assert.Equal(t, tt.msg, httpErr.Message)
+			assert.Equal(t, tt.fields, httpErr.Errors)
+		})
+	}
+}
+
+func TestHandleErrorKeepsHTTPErrors(t *testing.T) {
+	t.Parallel()
+
+	err := errs.ForbididdenError("Not yours", true)
+
+	assert.Same(t, err, sqlerr.HandleError(err))
+}
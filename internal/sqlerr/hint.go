@@ -0,0 +1,178 @@
+package sqlerr
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// invalidTextRepresentation is raised for values not of a column's type, enum values included.
+const invalidTextRepresentation = "22P02"
+
+// diagnoseTimeout bounds the catalog lookups of Diagnose, it runs on the error path.
+const diagnoseTimeout = 2 * time.Second
+
+var (
+	// keyDetailPattern matches the detail of unique and foreign key violations, e.g.
+	// `Key (email)=(a@example.com) already exists.`
+	keyDetailPattern = regexp.MustCompile(`^Key \((.+?)\)=\((.*)\)`)
+	// invalidEnumPattern matches `invalid input value for enum order_status: "shipped"`.
+	invalidEnumPattern = regexp.MustCompile(`^invalid input value for enum ([^:]+): "(.*)"$`)
+)
+
+// Querier runs the catalog lookups of Diagnose, e.g. a *pgxpool.Pool.
+type Querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Diagnosis explains a database error to the developer who caused it. It carries the
+// offending values, so it is for development logs only and never sent to clients.
+type Diagnosis struct {
+	SQLState   string `json:"sqlstate"`
+	Table      string `json:"table,omitempty"`
+	Column     string `json:"column,omitempty"`
+	Constraint string `json:"constraint,omitempty"`
+	// Definition is the constraint's, or the unique index's, as Postgres prints it.
+	Definition string `json:"definition,omitempty"`
+	// Values are the offending values by column, as far as Postgres reported them.
+	Values map[string]string `json:"values,omitempty"`
+	// EnumValues are the values allowed for an invalid enum value.
+	EnumValues []string `json:"enum_values,omitempty"`
+	Detail     string   `json:"detail,omitempty"`
+	Hint       string   `json:"hint,omitempty"`
+	// Remediation suggests how to fix the error.
+	Remediation string `json:"remediation"`
+}
+
+// Diagnose builds a Diagnosis of err, nil if it isn't a constraint violation or an
+// invalid enum value. Definitions and enum values are looked up through db, lookups
+// failing leave them out.
+func Diagnose(ctx context.Context, db Querier, err error) *Diagnosis {
+	var pgerr *pgconn.PgError
+	if !errors.As(err, &pgerr) {
+		return nil
+	}
+
+	d := &Diagnosis{
+		SQLState:   pgerr.Code,
+		Table:      pgerr.TableName,
+		Column:     pgerr.ColumnName,
+		Constraint: pgerr.ConstraintName,
+		Detail:     pgerr.Detail,
+		Hint:       pgerr.Hint,
+		Values:     detailValues(pgerr.Detail),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, diagnoseTimeout)
+	defer cancel()
+
+	switch MapDatabaseErrorCode(pgerr.Code) {
+	case UniqueViolation:
+		d.Remediation = "A row with these values already exists: look it up first, or upsert with ON CONFLICT if replacing it is intended."
+	case ForeignKeyViolation:
+		d.Remediation = "The referenced row doesn't exist, or is still referenced when deleting: create the referenced row first, check the ID, or delete the referencing rows."
+	case NotNullViolation:
+		d.Remediation = "Set " + pgerr.ColumnName + " in the statement, or give the column a default in a migration."
+	case CheckViolation:
+		d.Remediation = "A value breaks the check constraint, compare the failing row against its definition."
+	case ExcludeViolation:
+		d.Remediation = "The row overlaps an existing one under the exclusion constraint, compare it against its definition."
+	default:
+		if pgerr.Code != invalidTextRepresentation {
+			return nil
+		}
+
+		matches := invalidEnumPattern.FindStringSubmatch(pgerr.Message)
+		if matches == nil {
+			return nil
+		}
+
+		d.Values = map[string]string{matches[1]: matches[2]}
+		d.EnumValues = enumValues(ctx, db, matches[1])
+		d.Remediation = "Use one of the values of enum " + matches[1] + ". A new value needs a migration (ALTER TYPE ... ADD VALUE) and an entry in internal/model/enums.json."
+		return d
+	}
+
+	if pgerr.ConstraintName != "" {
+		d.Definition = constraintDefinition(ctx, db, pgerr.SchemaName, pgerr.ConstraintName)
+	}
+
+	return d
+}
+
+// detailValues parses the columns and values out of a violation's detail. Multi-column
+// keys are split on ", ", which misreads values containing it, good enough for a hint.
+func detailValues(detail string) map[string]string {
+	matches := keyDetailPattern.FindStringSubmatch(detail)
+	if matches == nil {
+		return nil
+	}
+
+	columns := strings.Split(matches[1], ", ")
+	values := strings.Split(matches[2], ", ")
+	if len(columns) != len(values) {
+		return map[string]string{matches[1]: matches[2]}
+	}
+
+	result := make(map[string]string, len(columns))
+	for i, column := range columns {
+		result[column] = values[i]
+	}
+	return result
+}
+
+// constraintDefinition returns the definition of a constraint, or of the unique index
+// raising a unique violation without a constraint behind it.
+func constraintDefinition(ctx context.Context, db Querier, schema, name string) string {
+	if db == nil {
+		return ""
+	}
+	if schema == "" {
+		schema = "public"
+	}
+
+	var definition string
+	err := db.QueryRow(ctx, `
+		SELECT pg_get_constraintdef(c.oid)
+		FROM pg_constraint c
+		JOIN pg_namespace n ON n.oid = c.connamespace
+		WHERE n.nspname = $1 AND c.conname = $2`, schema, name).Scan(&definition)
+	if err == nil {
+		return definition
+	}
+
+	err = db.QueryRow(ctx, `SELECT indexdef FROM pg_indexes WHERE schemaname = $1 AND indexname = $2`, schema, name).Scan(&definition)
+	if err != nil {
+		return ""
+	}
+	return definition
+}
+
+// enumValues returns the values of an enum type in their declared order.
+func enumValues(ctx context.Context, db Querier, enum string) []string {
+	if db == nil {
+		return nil
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT e.enumlabel
+		FROM pg_enum e
+		JOIN pg_type t ON t.oid = e.enumtypid
+		WHERE t.typname = $1
+		ORDER BY e.enumsortorder`, enum)
+	if err != nil {
+		return nil
+	}
+
+	values, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil
+	}
+	return values
+}
@@ -0,0 +1,99 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// PostgresContainer is a Postgres server running in a container.
+type PostgresContainer struct {
+	Container testcontainers.Container
+	Host      string
+	Port      int
+	Database  string
+	User      string
+	Password  string
+}
+
+// RedisContainer is a Redis server running in a container.
+type RedisContainer struct {
+	Container testcontainers.Container
+	// Address is host:port of the mapped port.
+	Address string
+}
+
+// StartPostgres starts a Postgres container with an empty database owned by user. It is
+// used by SetupTestDB and by "dev up", callers terminate the container themselves.
+func StartPostgres(ctx context.Context, database, user, password string) (*PostgresContainer, error) {
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image: "postgres:15-alpine",
+			Env: map[string]string{
+				"POSTGRES_DB":       database,
+				"POSTGRES_USER":     user,
+				"POSTGRES_PASSWORD": password,
+			},
+			ExposedPorts: []string{"5432/tcp"},
+			// Logged once by the temporary server initializing the database, then by the real one.
+			WaitingFor: wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+		},
+		Started: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start postgres container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "5432/tcp", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get postgres container endpoint: %w", err)
+	}
+
+	host, portValue, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid postgres container endpoint %q: %w", endpoint, err)
+	}
+
+	port, err := strconv.Atoi(portValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid postgres container endpoint %q: %w", endpoint, err)
+	}
+
+	return &PostgresContainer{
+		Container: container,
+		Host:      host,
+		Port:      port,
+		Database:  database,
+		User:      user,
+		Password:  password,
+	}, nil
+}
+
+// StartRedis starts a Redis container, callers terminate it themselves.
+func StartRedis(ctx context.Context) (*RedisContainer, error) {
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis:7-alpine",
+			ExposedPorts: []string{"6379/tcp"},
+			WaitingFor:   wait.ForLog("Ready to accept connections"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start redis container: %w", err)
+	}
+
+	address, err := container.PortEndpoint(ctx, "6379/tcp", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redis container endpoint: %w", err)
+	}
+
+	return &RedisContainer{
+		Container: container,
+		Address:   address,
+	}, nil
+}
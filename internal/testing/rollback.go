@@ -0,0 +1,40 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+// WithRollback runs fn inside a transaction that is rolled back once fn returns, so a
+// test leaves no data behind and needs no truncation. The context handed to fn carries
+// the transaction: repository calls made with it run on it, and code starting its own
+// transaction with TxManager.WithinTx (or tx.Begin) gets a savepoint instead, which it
+// can commit or roll back as usual. AfterCommit hooks never run.
+//
+//	testing.WithRollback(t, db.Pool, func(ctx context.Context, tx pgx.Tx) {
+//		device, err := repos.Notification.UpsertDevice(ctx, userID, payload)
+//		require.NoError(t, err)
+//	})
+func WithRollback(t *testing.T, pool *pgxpool.Pool, fn func(ctx context.Context, tx pgx.Tx)) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err, "failed to begin test transaction")
+
+	// Deferred, so a failed require (which exits the test goroutine) still rolls back
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			t.Errorf("failed to roll back test transaction: %v", err)
+		}
+	}()
+
+	fn(database.ContextWithTx(ctx, tx), tx)
+}
@@ -0,0 +1,118 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// maintenanceDatabase is connected to while the test database is copied or replaced,
+// a database can't be dropped or used as a template while anyone is connected to it.
+const maintenanceDatabase = "postgres"
+
+// Snapshot saves the current state of the test database (schema and data) so it can be
+// brought back with Restore. Run expensive fixture setup once, snapshot, then restore
+// before each test instead of truncating tables.
+//
+// The snapshot is a copy made with CREATE DATABASE ... TEMPLATE, which copies files
+// instead of replaying inserts. Taking a new snapshot replaces the previous one.
+// Pool is closed and reopened, so don't hold on to the old value.
+func (db *TestDBSetup) Snapshot(ctx context.Context) error {
+	snapshotName := db.Config.Database.Name + "_snapshot"
+
+	err := db.withoutConnections(ctx, func(conn *pgx.Conn) error {
+		if _, err := conn.Exec(ctx, "DROP DATABASE IF EXISTS "+pgx.Identifier{snapshotName}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to drop previous snapshot: %w", err)
+		}
+
+		query := fmt.Sprintf("CREATE DATABASE %s TEMPLATE %s",
+			pgx.Identifier{snapshotName}.Sanitize(), pgx.Identifier{db.Config.Database.Name}.Sanitize())
+		if _, err := conn.Exec(ctx, query); err != nil {
+			return fmt.Errorf("failed to create snapshot: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	db.snapshotName = snapshotName
+	db.logger.Info().Str("snapshot", snapshotName).Msg("created test database snapshot")
+
+	return nil
+}
+
+// Restore replaces the test database with the last snapshot. Pool is closed and
+// reopened on the restored database, so read it again after restoring.
+func (db *TestDBSetup) Restore(ctx context.Context) error {
+	if db.snapshotName == "" {
+		return errors.New("no snapshot to restore, call Snapshot first")
+	}
+
+	return db.withoutConnections(ctx, func(conn *pgx.Conn) error {
+		if _, err := conn.Exec(ctx, "DROP DATABASE "+pgx.Identifier{db.Config.Database.Name}.Sanitize()+" WITH (FORCE)"); err != nil {
+			return fmt.Errorf("failed to drop test database: %w", err)
+		}
+
+		query := fmt.Sprintf("CREATE DATABASE %s TEMPLATE %s",
+			pgx.Identifier{db.Config.Database.Name}.Sanitize(), pgx.Identifier{db.snapshotName}.Sanitize())
+		if _, err := conn.Exec(ctx, query); err != nil {
+			return fmt.Errorf("failed to restore snapshot: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// RestoreAfter restores the snapshot once t and its subtests finished,
+// so every test starts from the snapshotted state.
+func (db *TestDBSetup) RestoreAfter(t *testing.T) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		if err := db.Restore(context.Background()); err != nil {
+			t.Errorf("failed to restore test database snapshot: %v", err)
+		}
+	})
+}
+
+// withoutConnections closes Pool, runs fn on a connection to the maintenance database
+// and opens a new Pool afterwards, even if fn failed.
+func (db *TestDBSetup) withoutConnections(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	if db.Pool != nil {
+		db.Pool.Close()
+		db.Pool = nil
+	}
+
+	maintenanceConfig := db.Config.Database
+	maintenanceConfig.Name = maintenanceDatabase
+
+	dsn, err := maintenanceConfig.DSN()
+	if err != nil {
+		return fmt.Errorf("failed to build maintenance dsn: %w", err)
+	}
+
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to maintenance database: %w", err)
+	}
+
+	fnErr := fn(conn)
+
+	if err := conn.Close(ctx); err != nil {
+		db.logger.Warn().Err(err).Msg("failed to close maintenance connection")
+	}
+
+	reopened, err := database.NewDatabaseConnectionPool(db.Config, &db.logger, nil)
+	if err != nil {
+		return errors.Join(fnErr, fmt.Errorf("failed to reopen test database pool: %w", err))
+	}
+	db.Pool = reopened.Pool
+
+	return fnErr
+}
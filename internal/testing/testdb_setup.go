@@ -13,7 +13,6 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
-	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 // TestDBSetup represents a temporary PostgreSQL database and its associated resources.
@@ -21,6 +20,9 @@ type TestDBSetup struct {
 	Pool            *pgxpool.Pool
 	TestDBContainer testcontainers.Container
 	Config          *config.Config
+
+	logger       zerolog.Logger
+	snapshotName string
 }
 
 // SetupTestDB creates and configures a PostgreSQL container for integration testing.
@@ -35,32 +37,13 @@ func SetupTestDB(t *testing.T) (*TestDBSetup, func()) {
 	databaseUser := "test_user"
 	databasePassword := "test_password"
 
-	// Define a container request for Postgres DB
-	req := testcontainers.ContainerRequest{
-		Image: "postgres:15-alpine",
-		Env: map[string]string{
-			"POSTGRES_DB":       databaseName,
-			"POSTGRES_USER":     databaseUser,
-			"POSTGRES_PASSWORD": databasePassword,
-		},
-		ExposedPorts: []string{"5432/tcp"},
-		WaitingFor:   wait.ForLog("database system is ready to accept connections"),
-	}
-
 	// Start the container
-	postgresContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          true,
-	})
+	postgres, err := StartPostgres(ctx, databaseName, databaseUser, databasePassword)
 	require.NoError(t, err, "failed to start Postgres container")
 
-	// Get container host and mapped port to connect from the test environment
-	host, err := postgresContainer.Host(ctx)
-	require.NoError(t, err, "failed to get postgres container host")
-
-	mappedPort, err := postgresContainer.MappedPort(ctx, "5432")
-	require.NoError(t, err, "failed to get postgres container mapped port")
-	port := mappedPort.Int()
+	postgresContainer := postgres.Container
+	host := postgres.Host
+	port := postgres.Port
 
 	// Automatically terminate container after the test finishes
 	t.Cleanup(func() {
@@ -130,18 +113,20 @@ func SetupTestDB(t *testing.T) (*TestDBSetup, func()) {
 	require.NoError(t, lastErr, "database connection failed after multiple attempts")
 
 	// Migrations
-	err = database.Migrate(ctx, &logger, cfg)
+	err = database.Migrate(ctx, &logger, cfg, nil)
 	require.NoError(t, err, "database migration failed")
 
 	testDBSetup := &TestDBSetup{
 		Pool:            db.Pool,
 		TestDBContainer: postgresContainer,
 		Config:          cfg,
+		logger:          logger,
 	}
 
+	// Close whichever pool is current, Restore replaces it.
 	cleanUp := func() {
-		if db.Pool != nil {
-			db.Pool.Close()
+		if testDBSetup.Pool != nil {
+			testDBSetup.Pool.Close()
 		}
 	}
 
@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/go-playground/validator/v10"
+)
+
+// EchoValidator is the echo.Validator of the API, so handlers can call c.Validate on
+// payloads that don't implement Validatable. Failures become the same 400 response
+// with field errors BindAndValidate returns.
+type EchoValidator struct {
+	validator *validator.Validate
+}
+
+// NewEchoValidator returns an EchoValidator using the shared validator and its custom tags.
+func NewEchoValidator() *EchoValidator {
+	return &EchoValidator{
+		validator: NewValidator(),
+	}
+}
+
+// Validate checks i with its own Validate method if it is Validatable, with its
+// validate tags otherwise.
+func (v *EchoValidator) Validate(i any) error {
+	var err error
+	if validatable, ok := i.(Validatable); ok {
+		err = validatable.Validate()
+	} else {
+		err = v.validator.Struct(i)
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	msg, fieldErrors := extractValidationErrors(err)
+	if fieldErrors == nil {
+		// Not a validation failure, e.g. a non-struct was passed: a bug, not a bad request.
+		return err
+	}
+
+	return errs.BadRequestError(msg, true, nil, fieldErrors, nil)
+}
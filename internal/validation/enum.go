@@ -0,0 +1,29 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// Enum is implemented by the types generated with "go-boilerplate gen enums".
+type Enum interface {
+	IsValid() bool
+	EnumValues() []string
+}
+
+// NewValidator returns a validator with the custom tags of the API registered.
+// Payloads should use it in their Validate method instead of validator.New().
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	RegisterEnumValidation(v)
+	return v
+}
+
+// RegisterEnumValidation registers the "enum" tag, which rejects enum fields holding
+// an undeclared value, e.g. `validate:"required,enum"`.
+// Fields that aren't an Enum fail the tag, so a misplaced tag is caught on first use.
+func RegisterEnumValidation(v *validator.Validate) {
+	_ = v.RegisterValidation("enum", func(fl validator.FieldLevel) bool {
+		enum, ok := fl.Field().Interface().(Enum)
+		return ok && enum.IsValid()
+	})
+}
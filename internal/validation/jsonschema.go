@@ -0,0 +1,478 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONSchema is a compiled JSON Schema. It supports the subset of keywords request
+// bodies need: type, enum, const, properties, required, additionalProperties, items,
+// minItems, maxItems, uniqueItems, minLength, maxLength, pattern, format (email, uuid,
+// date, date-time, uri), minimum, maximum, exclusiveMinimum and exclusiveMaximum.
+// Annotations ($schema, $id, $comment, title, description, default, examples) are
+// ignored. Any other keyword fails compilation, so a schema never silently checks less
+// than it says.
+type JSONSchema struct {
+	Schema      string          `json:"$schema"`
+	ID          string          `json:"$id"`
+	Comment     string          `json:"$comment"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Default     json.RawMessage `json:"default"`
+	Examples    json.RawMessage `json:"examples"`
+
+	Type                 schemaTypes            `json:"type"`
+	Enum                 []any                  `json:"enum"`
+	Const                *json.RawMessage       `json:"const"`
+	Properties           map[string]*JSONSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *additionalProperties  `json:"additionalProperties"`
+	Items                *JSONSchema            `json:"items"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	UniqueItems          bool                   `json:"uniqueItems"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Format               string                 `json:"format"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum"`
+
+	pattern *regexp.Regexp
+	constV  any
+}
+
+// SchemaViolation is a keyword a value of the document failed.
+type SchemaViolation struct {
+	// Field is the path of the value, e.g. "recipients[2].email", empty for the document itself.
+	Field   string
+	Keyword string
+	Message string
+}
+
+// schemaTypes is the type keyword, a single type or a list of them.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+	*t = list
+	return nil
+}
+
+// additionalProperties is either a boolean or the schema of properties not listed.
+type additionalProperties struct {
+	allowed bool
+	schema  *JSONSchema
+}
+
+func (a *additionalProperties) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.allowed); err == nil {
+		return nil
+	}
+
+	a.allowed = true
+	a.schema = &JSONSchema{}
+	return strictUnmarshal(data, a.schema)
+}
+
+var schemaTypeNames = []string{"string", "number", "integer", "boolean", "object", "array", "null"}
+
+var schemaFormats = map[string]func(string) bool{
+	"email": func(s string) bool {
+		address, err := mail.ParseAddress(s)
+		return err == nil && address.Address == s
+	},
+	"uuid": IsValidUUID,
+	"date": func(s string) bool {
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	},
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	},
+	"uri": func(s string) bool {
+		u, err := url.ParseRequestURI(s)
+		return err == nil && u.Scheme != ""
+	},
+}
+
+// CompileSchema parses and checks a JSON Schema.
+func CompileSchema(raw []byte) (*JSONSchema, error) {
+	schema := &JSONSchema{}
+	if err := strictUnmarshal(raw, schema); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	if err := schema.compile(""); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	return schema, nil
+}
+
+// strictUnmarshal decodes into v, failing on keywords JSONSchema doesn't support.
+func strictUnmarshal(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if keyword, ok := strings.CutPrefix(fmt.Sprint(err), "json: unknown field "); ok {
+		return fmt.Errorf("unsupported keyword %s", keyword)
+	}
+	return err
+}
+
+func (s *JSONSchema) compile(path string) error {
+	for _, t := range s.Type {
+		if !slices.Contains(schemaTypeNames, t) {
+			return fmt.Errorf("%sunknown type %q", pathPrefix(path), t)
+		}
+	}
+
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%sinvalid pattern: %w", pathPrefix(path), err)
+		}
+		s.pattern = pattern
+	}
+
+	if s.Format != "" {
+		if _, ok := schemaFormats[s.Format]; !ok {
+			return fmt.Errorf("%sunsupported format %q", pathPrefix(path), s.Format)
+		}
+	}
+
+	if s.Const != nil {
+		value, err := decodeDocument(*s.Const)
+		if err != nil {
+			return fmt.Errorf("%sinvalid const: %w", pathPrefix(path), err)
+		}
+		s.constV = value
+	}
+
+	for i, value := range s.Enum {
+		s.Enum[i] = normalizeNumbers(value)
+	}
+
+	for name, property := range s.Properties {
+		if err := property.compile(joinPath(path, name)); err != nil {
+			return err
+		}
+	}
+
+	if s.AdditionalProperties != nil && s.AdditionalProperties.schema != nil {
+		if err := s.AdditionalProperties.schema.compile(joinPath(path, "*")); err != nil {
+			return err
+		}
+	}
+
+	if s.Items != nil {
+		if err := s.Items.compile(path + "[]"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateDocument validates a JSON document, returning every violation in the same
+// order on every call. Malformed JSON fails with an error instead.
+func (s *JSONSchema) ValidateDocument(data []byte) ([]SchemaViolation, error) {
+	document, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []SchemaViolation
+	s.validate(document, "", &violations)
+	return violations, nil
+}
+
+// decodeDocument decodes data with numbers as float64, an empty document is null.
+func decodeDocument(data []byte) (any, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after the JSON document")
+	}
+
+	return normalizeNumbers(document), nil
+}
+
+// normalizeNumbers turns json.Number and float64 values into float64, so values
+// decoded either way compare equal.
+func normalizeNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		f, _ := strconv.ParseFloat(v.String(), 64)
+		return f
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	}
+	return value
+}
+
+func (s *JSONSchema) validate(value any, path string, violations *[]SchemaViolation) {
+	fail := func(keyword, message string) {
+		*violations = append(*violations, SchemaViolation{Field: path, Keyword: keyword, Message: message})
+	}
+
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return hasType(value, t) }) {
+		fail("type", "must be "+describeTypes(s.Type))
+		// Other keywords would only repeat that the value has the wrong type.
+		return
+	}
+
+	if s.Const != nil && !equalJSON(value, s.constV) {
+		fail("const", fmt.Sprintf("must be %s", formatJSON(s.constV)))
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(allowed any) bool { return equalJSON(value, allowed) }) {
+		values := make([]string, len(s.Enum))
+		for i, allowed := range s.Enum {
+			values[i] = formatEnumValue(allowed)
+		}
+		fail("enum", "must be one of: "+strings.Join(values, ", "))
+	}
+
+	switch v := value.(type) {
+	case string:
+		s.validateString(v, fail)
+	case float64:
+		s.validateNumber(v, fail)
+	case []any:
+		s.validateArray(v, path, violations, fail)
+	case map[string]any:
+		s.validateObject(v, path, violations, fail)
+	}
+}
+
+func (s *JSONSchema) validateString(v string, fail func(keyword, message string)) {
+	length := utf8.RuneCountInString(v)
+	if s.MinLength != nil && length < *s.MinLength {
+		fail("minLength", fmt.Sprintf("must be at least %d characters", *s.MinLength))
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		fail("maxLength", fmt.Sprintf("must not exceed %d characters", *s.MaxLength))
+	}
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		fail("pattern", "has an invalid format")
+	}
+	if s.Format != "" && !schemaFormats[s.Format](v) {
+		fail("format", formatMessage(s.Format))
+	}
+}
+
+func (s *JSONSchema) validateNumber(v float64, fail func(keyword, message string)) {
+	if s.Minimum != nil && v < *s.Minimum {
+		fail("minimum", "must be at least "+formatNumber(*s.Minimum))
+	}
+	if s.Maximum != nil && v > *s.Maximum {
+		fail("maximum", "must not exceed "+formatNumber(*s.Maximum))
+	}
+	if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+		fail("exclusiveMinimum", "must be greater than "+formatNumber(*s.ExclusiveMinimum))
+	}
+	if s.ExclusiveMaximum != nil && v >= *s.ExclusiveMaximum {
+		fail("exclusiveMaximum", "must be less than "+formatNumber(*s.ExclusiveMaximum))
+	}
+}
+
+func (s *JSONSchema) validateArray(v []any, path string, violations *[]SchemaViolation, fail func(keyword, message string)) {
+	if s.MinItems != nil && len(v) < *s.MinItems {
+		fail("minItems", fmt.Sprintf("must have at least %d items", *s.MinItems))
+	}
+	if s.MaxItems != nil && len(v) > *s.MaxItems {
+		fail("maxItems", fmt.Sprintf("must not have more than %d items", *s.MaxItems))
+	}
+	if s.UniqueItems {
+		for i := range v {
+			if slices.ContainsFunc(v[:i], func(earlier any) bool { return equalJSON(earlier, v[i]) }) {
+				fail("uniqueItems", "must not contain duplicates")
+				break
+			}
+		}
+	}
+
+	if s.Items != nil {
+		for i, item := range v {
+			s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), violations)
+		}
+	}
+}
+
+func (s *JSONSchema) validateObject(v map[string]any, path string, violations *[]SchemaViolation, fail func(keyword, message string)) {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			*violations = append(*violations, SchemaViolation{Field: joinPath(path, name), Keyword: "required", Message: "is required"})
+		}
+	}
+
+	// Sorted, so violations come in the same order on every request.
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if property, ok := s.Properties[name]; ok {
+			property.validate(v[name], joinPath(path, name), violations)
+			continue
+		}
+
+		if s.AdditionalProperties == nil {
+			continue
+		}
+		if !s.AdditionalProperties.allowed {
+			*violations = append(*violations, SchemaViolation{Field: joinPath(path, name), Keyword: "additionalProperties", Message: "is not allowed"})
+		} else if s.AdditionalProperties.schema != nil {
+			s.AdditionalProperties.schema.validate(v[name], joinPath(path, name), violations)
+		}
+	}
+}
+
+func hasType(value any, t string) bool {
+	switch v := value.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case float64:
+		return t == "number" || (t == "integer" && v == math.Trunc(v) && !math.IsInf(v, 0))
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+	return false
+}
+
+func describeTypes(types []string) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		switch t {
+		case "null":
+			names[i] = "null"
+		case "array", "integer", "object":
+			names[i] = "an " + t
+		default:
+			names[i] = "a " + t
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+func formatMessage(format string) string {
+	switch format {
+	case "email":
+		return "must be a valid email address"
+	case "uuid":
+		return "must be a valid UUID"
+	case "date":
+		return "must be a date (YYYY-MM-DD)"
+	case "date-time":
+		return "must be an RFC 3339 timestamp"
+	case "uri":
+		return "must be a valid URI"
+	}
+	return "must be a valid " + format
+}
+
+func equalJSON(a, b any) bool {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, item := range av {
+			other, ok := bv[key]
+			if !ok || !equalJSON(item, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		return ok && slices.EqualFunc(av, bv, equalJSON)
+	default:
+		return a == b
+	}
+}
+
+func formatJSON(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// formatEnumValue shows strings as they are, like the oneof messages of struct validation.
+func formatEnumValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return formatJSON(value)
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func pathPrefix(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + ": "
+}
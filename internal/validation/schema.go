@@ -0,0 +1,175 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FieldSchema describes the constraints of one request field, so clients can run the
+// same checks before sending a request.
+type FieldSchema struct {
+	// Name is the JSON name, nested fields are joined with dots and items of lists are
+	// marked with [], e.g. "recipients[].email".
+	Name string `json:"name"`
+	// Type is the JSON type: string, integer, number, boolean, array, object, any or a
+	// string format such as date-time.
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	Nullable bool   `json:"nullable"`
+	Rules    []Rule `json:"rules"`
+}
+
+// Rule is one validator tag of a field.
+type Rule struct {
+	Tag   string `json:"tag"`
+	Param string `json:"param,omitempty"`
+	// Values lists the allowed values of oneof and enum rules.
+	Values []string `json:"values,omitempty"`
+	// Message is the error message the API responds with when the rule fails.
+	Message string `json:"message"`
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Describe returns the constraints of every field of payload, a struct or pointer to one,
+// read from its json and validate tags.
+func Describe(payload any) []FieldSchema {
+	t := reflect.TypeOf(payload)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return describeStruct(t, "")
+}
+
+func describeStruct(t reflect.Type, prefix string) []FieldSchema {
+	fields := []FieldSchema{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without a name of their own are flattened, like encoding/json does.
+		if field.Anonymous && name == "" && indirect(field.Type).Kind() == reflect.Struct {
+			fields = append(fields, describeStruct(indirect(field.Type), prefix)...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, describeField(field.Type, prefix+name, field.Tag.Get("validate"))...)
+	}
+
+	return fields
+}
+
+// describeField describes a field and, for structs and lists of structs, its nested fields.
+func describeField(t reflect.Type, name, tag string) []FieldSchema {
+	own, itemTag, _ := strings.Cut(tag, "dive")
+	own = strings.Trim(own, ",")
+	itemTag = strings.Trim(itemTag, ",")
+
+	schema := FieldSchema{
+		Name:     name,
+		Type:     jsonType(t),
+		Nullable: t.Kind() == reflect.Pointer,
+		Rules:    []Rule{},
+	}
+
+	elem := indirect(t)
+	for _, rule := range strings.Split(own, ",") {
+		tagName, param, _ := strings.Cut(rule, "=")
+
+		switch tagName {
+		case "", "omitempty":
+			continue
+		case "required":
+			schema.Required = true
+		}
+
+		schema.Rules = append(schema.Rules, describeRule(elem, name, tagName, param))
+	}
+
+	fields := []FieldSchema{schema}
+
+	switch {
+	case elem.Kind() == reflect.Struct && elem != timeType:
+		fields = append(fields, describeStruct(elem, name+".")...)
+
+	case elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array:
+		item := elem.Elem()
+		if indirect(item).Kind() == reflect.Struct && indirect(item) != timeType {
+			fields = append(fields, describeStruct(indirect(item), name+"[].")...)
+		} else if itemTag != "" {
+			fields = append(fields, describeField(item, name+"[]", itemTag)...)
+		}
+	}
+
+	return fields
+}
+
+func describeRule(t reflect.Type, field, tag, param string) Rule {
+	rule := Rule{Tag: tag, Param: param}
+
+	switch tag {
+	case "oneof":
+		rule.Values = strings.Fields(param)
+	case "enum":
+		if enum, ok := reflect.Zero(t).Interface().(Enum); ok {
+			rule.Values = enum.EnumValues()
+		}
+	}
+
+	rule.Message = ruleMessage(strings.ToLower(field), tag, param, t.Kind() == reflect.String, rule.Values)
+	return rule
+}
+
+func jsonType(t reflect.Type) string {
+	t = indirect(t)
+
+	if t == timeType {
+		return "date-time"
+	}
+
+	// Raw JSON takes any JSON value
+	if t == rawJSONType {
+		return "any"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
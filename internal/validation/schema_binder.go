@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Barry-dE/go-backend-boilerplate/internal/errs"
+	"github.com/labstack/echo/v4"
+)
+
+// FailureStageSchema is the stage of requests whose body broke the JSON Schema of their route.
+const FailureStageSchema = "schema"
+
+// indexPattern matches list indices in violation paths, failures are counted per field
+// whatever the item.
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// SchemaBinder is the echo.Binder of the API. For routes with a registered JSON Schema
+// it validates the body against the schema before unmarshalling it, so teams writing
+// schemas first get the same 400 response with field errors as struct validation.
+// Other routes are bound by echo's DefaultBinder as they are.
+type SchemaBinder struct {
+	echo.DefaultBinder
+	schemas map[string]*JSONSchema
+}
+
+// NewSchemaBinder returns a SchemaBinder without any schema.
+func NewSchemaBinder() *SchemaBinder {
+	return &SchemaBinder{
+		schemas: make(map[string]*JSONSchema),
+	}
+}
+
+// Register compiles raw and validates the bodies of the route with it. path is the route
+// as registered with echo, e.g. "/api/v1/admin/tenants/:id/limits". Registration must
+// happen before the server starts.
+func (b *SchemaBinder) Register(method, path string, raw []byte) error {
+	schema, err := CompileSchema(raw)
+	if err != nil {
+		return fmt.Errorf("schema of %s %s: %w", method, path, err)
+	}
+
+	b.schemas[method+" "+path] = schema
+	return nil
+}
+
+// Bind validates the body of the request against its route's schema, if it has one,
+// then binds it into i.
+func (b *SchemaBinder) Bind(i any, c echo.Context) error {
+	schema, ok := b.schemas[c.Request().Method+" "+c.Path()]
+	if !ok {
+		return b.DefaultBinder.Bind(i, c)
+	}
+
+	req := c.Request()
+	if contentType := req.Header.Get(echo.HeaderContentType); contentType != "" && !strings.HasPrefix(contentType, echo.MIMEApplicationJSON) {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "Request body must be JSON")
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Failed to read request body").SetInternal(err)
+	}
+	// Put the body back for the DefaultBinder.
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	violations, err := schema.ValidateDocument(body)
+	if err != nil {
+		c.Set(FailureKey, &Failure{Stage: FailureStageBind})
+		return errs.BadRequestError("Request body is not valid JSON", false, nil, nil, nil)
+	}
+
+	if len(violations) > 0 {
+		fieldErrors := make([]errs.FieldError, len(violations))
+		failures := make([]FieldFailure, len(violations))
+		for i, violation := range violations {
+			if violation.Field == "" {
+				violation.Field = "body"
+			}
+			fieldErrors[i] = errs.FieldError{Field: violation.Field, Error: violation.Message}
+			failures[i] = FieldFailure{Field: indexPattern.ReplaceAllString(violation.Field, "[]"), Tag: violation.Keyword}
+		}
+
+		c.Set(FailureKey, &Failure{Stage: FailureStageSchema, Fields: failures})
+		return errs.BadRequestError("Validation failed", true, nil, fieldErrors, nil)
+	}
+
+	return b.DefaultBinder.Bind(i, c)
+}
@@ -1,6 +1,8 @@
 package validation
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -26,24 +28,87 @@ func (c CustomValidationErrors) Error() string {
 	return "Validation failed"
 }
 
+// FailureKey is the echo context key BindAndValidate stores a *Failure under when it
+// rejects a request, so the error handler can count rejections per endpoint and field.
+const FailureKey = "validation_failure"
+
+// Failure describes why BindAndValidate rejected a request.
+type Failure struct {
+	// Stage is FailureStageBind when the body couldn't be decoded,
+	// FailureStageSchema when it broke the JSON Schema of its route and
+	// FailureStageValidate when it broke validation rules.
+	Stage  string
+	Fields []FieldFailure
+}
+
+const (
+	FailureStageBind     = "bind"
+	FailureStageValidate = "validate"
+)
+
+// FieldFailure is a rule a field failed, e.g. email/required. Fields of bodies that
+// couldn't be decoded fail the "type" rule, custom Validate errors the "custom" one.
+type FieldFailure struct {
+	Field string
+	Tag   string
+}
+
+// GetFailure returns why BindAndValidate rejected the request, if it did.
+func GetFailure(c echo.Context) (*Failure, bool) {
+	failure, ok := c.Get(FailureKey).(*Failure)
+	return failure, ok
+}
+
 func BindAndValidate(c echo.Context, payload Validatable) error {
 	if err := c.Bind(payload); err != nil {
+		// The SchemaBinder rejected the body with its own response and failure.
+		var httpErr *errs.HttpError
+		if errors.As(err, &httpErr) {
+			return err
+		}
+
+		c.Set(FailureKey, &Failure{Stage: FailureStageBind, Fields: bindFailures(err)})
 		message := strings.Split(strings.Split(err.Error(), ",")[1], "message=")[1]
 		return errs.BadRequestError(message, false, nil, nil, nil)
 	}
 
-	if msg, fieldErrors := validateStruct(payload); fieldErrors != nil {
-		return errs.BadRequestError(msg, true, nil, fieldErrors, nil)
+	if err := payload.Validate(); err != nil {
+		msg, fieldErrors := extractValidationErrors(err)
+		if fieldErrors != nil {
+			c.Set(FailureKey, &Failure{Stage: FailureStageValidate, Fields: ruleFailures(err)})
+			return errs.BadRequestError(msg, true, nil, fieldErrors, nil)
+		}
 	}
 
 	return nil
 }
 
-func validateStruct(v Validatable) (string, []errs.FieldError) {
-	if err := v.Validate(); err != nil {
-		return extractValidationErrors(err)
+// bindFailures returns the field whose value has the wrong type, if the bind error names one.
+func bindFailures(err error) []FieldFailure {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldFailure{{Field: typeErr.Field, Tag: "type"}}
 	}
-	return "", nil
+	return nil
+}
+
+// ruleFailures returns the rule every field of a validation error failed.
+func ruleFailures(err error) []FieldFailure {
+	var failures []FieldFailure
+
+	if customValidationError, ok := err.(CustomValidationErrors); ok {
+		for _, err := range customValidationError {
+			failures = append(failures, FieldFailure{Field: err.Field, Tag: "custom"})
+		}
+	}
+
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		for _, err := range validationErrors {
+			failures = append(failures, FieldFailure{Field: strings.ToLower(err.Field()), Tag: err.Tag()})
+		}
+	}
+
+	return failures
 }
 
 func extractValidationErrors(err error) (string, []errs.FieldError) {
@@ -80,23 +145,37 @@ func IsValidUUID(uuid string) bool {
 
 // getValidationMessage generates user-friendly error messages based on validation tag
 func getValidationMessage(err validator.FieldError) string {
-	isString := err.Type().Kind() == reflect.String
+	var enumValues []string
+	if enum, ok := err.Value().(Enum); ok {
+		enumValues = enum.EnumValues()
+	}
 
-	switch err.Tag() {
+	return ruleMessage(strings.ToLower(err.Field()), err.Tag(), err.Param(), err.Type().Kind() == reflect.String, enumValues)
+}
+
+// ruleMessage is the message of a failed validation tag. The validation schema shows
+// the same messages, so clients can reproduce the server's errors.
+func ruleMessage(field, tag, param string, isString bool, enumValues []string) string {
+	switch tag {
 	case "required":
 		return "is required"
 	case "min":
 		if isString {
-			return fmt.Sprintf("must be at least %s characters", err.Param())
+			return fmt.Sprintf("must be at least %s characters", param)
 		}
-		return fmt.Sprintf("must be at least %s", err.Param())
+		return fmt.Sprintf("must be at least %s", param)
 	case "max":
 		if isString {
-			return fmt.Sprintf("must not exceed %s characters", err.Param())
+			return fmt.Sprintf("must not exceed %s characters", param)
 		}
-		return fmt.Sprintf("must not exceed %s", err.Param())
+		return fmt.Sprintf("must not exceed %s", param)
 	case "oneof":
-		return fmt.Sprintf("must be one of: %s", err.Param())
+		return fmt.Sprintf("must be one of: %s", param)
+	case "enum":
+		if enumValues != nil {
+			return fmt.Sprintf("must be one of: %s", strings.Join(enumValues, ", "))
+		}
+		return "is not a valid value"
 	case "email":
 		return "must be a valid email address"
 	case "e164":
@@ -108,10 +187,9 @@ func getValidationMessage(err validator.FieldError) string {
 	case "dive":
 		return "some items are invalid"
 	default:
-		field := strings.ToLower(err.Field())
-		if err.Param() != "" {
-			return fmt.Sprintf("%s: %s:%s", field, err.Tag(), err.Param())
+		if param != "" {
+			return fmt.Sprintf("%s: %s:%s", field, tag, param)
 		}
-		return fmt.Sprintf("%s: %s", field, err.Tag())
+		return fmt.Sprintf("%s: %s", field, tag)
 	}
 }
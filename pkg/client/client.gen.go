@@ -0,0 +1,1263 @@
+// Code generated by go-boilerplate gen sdk. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+)
+
+type AccountDeletion struct {
+	CancelledAt  *time.Time            `json:"cancelled_at,omitempty"`
+	CompletedAt  *time.Time            `json:"completed_at,omitempty"`
+	CreatedAt    time.Time             `json:"created_at"`
+	ID           string                `json:"id"`
+	ScheduledFor time.Time             `json:"scheduled_for"`
+	Status       AccountDeletionStatus `json:"status"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+	UserID       string                `json:"user_id"`
+}
+
+type AccountDeletionStatus string
+
+const (
+	AccountDeletionStatusPending   AccountDeletionStatus = "pending"
+	AccountDeletionStatusCancelled AccountDeletionStatus = "cancelled"
+	AccountDeletionStatusCompleted AccountDeletionStatus = "completed"
+)
+
+type AuditLog struct {
+	Action       string         `json:"action"`
+	ActorID      string         `json:"actor_id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	ID           string         `json:"id"`
+	Metadata     map[string]any `json:"metadata"`
+	RequestID    *string        `json:"request_id,omitempty"`
+	ResourceID   *string        `json:"resource_id,omitempty"`
+	ResourceType string         `json:"resource_type"`
+}
+
+type AuditLogCursorPage struct {
+	CursorInfo CursorInfo `json:"cursor_info"`
+	Items      []AuditLog `json:"items"`
+}
+
+type AuditLogPage struct {
+	Items    []AuditLog `json:"items"`
+	PageInfo PageInfo   `json:"page_info"`
+}
+
+type CheckoutSession struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+}
+
+type CreateCheckoutPayload struct {
+	PriceID string `json:"price_id"`
+}
+
+type CreateExportPayload struct {
+	Columns []string   `json:"columns,omitempty"`
+	Format  *string    `json:"format,omitempty"`
+	From    *time.Time `json:"from,omitempty"`
+	Until   *time.Time `json:"until,omitempty"`
+}
+
+type CreatePolicyPayload struct {
+	Action      string  `json:"action"`
+	Description *string `json:"description,omitempty"`
+	Effect      string  `json:"effect"`
+	Resource    string  `json:"resource"`
+	Subject     string  `json:"subject"`
+}
+
+type CursorInfo struct {
+	EstimatedTotal *int64  `json:"estimated_total,omitempty"`
+	HasNext        bool    `json:"has_next"`
+	Limit          int     `json:"limit"`
+	NextCursor     *string `json:"next_cursor,omitempty"`
+	Sort           string  `json:"sort"`
+}
+
+type DataExport struct {
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	DownloadURL *string          `json:"download_url,omitempty"`
+	Error       *string          `json:"error,omitempty"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"`
+	ID          string           `json:"id"`
+	SizeBytes   *int64           `json:"size_bytes,omitempty"`
+	Status      DataExportStatus `json:"status"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	UserID      string           `json:"user_id"`
+}
+
+type DataExportStatus string
+
+const (
+	DataExportStatusPending    DataExportStatus = "pending"
+	DataExportStatusProcessing DataExportStatus = "processing"
+	DataExportStatusCompleted  DataExportStatus = "completed"
+	DataExportStatusFailed     DataExportStatus = "failed"
+)
+
+type DeepHealth struct {
+	DurationMs int               `json:"duration_ms"`
+	Status     string            `json:"status"`
+	Steps      []DeepHealthCheck `json:"steps"`
+}
+
+type DeepHealthCheck struct {
+	DurationMs int     `json:"duration_ms"`
+	Error      *string `json:"error,omitempty"`
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+}
+
+type Device struct {
+	CreatedAt  time.Time `json:"created_at"`
+	ID         string    `json:"id"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Platform   string    `json:"platform"`
+	Provider   string    `json:"provider"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	UserID     string    `json:"user_id"`
+}
+
+type DisableRoutePayload struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type EmailSuppression struct {
+	CreatedAt time.Time `json:"created_at"`
+	Email     string    `json:"email"`
+	Reason    string    `json:"reason"`
+}
+
+type EmailSuppressionCursorPage struct {
+	CursorInfo CursorInfo         `json:"cursor_info"`
+	Items      []EmailSuppression `json:"items"`
+}
+
+type EmailUnsubscribe struct {
+	Email        string `json:"email"`
+	List         string `json:"list"`
+	Unsubscribed bool   `json:"unsubscribed"`
+}
+
+type EmailVerification struct {
+	CreatedAt       time.Time  `json:"created_at"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	LastSentAt      *time.Time `json:"last_sent_at,omitempty"`
+	TokenExpiresAt  *time.Time `json:"token_expires_at,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	UserID          string     `json:"user_id"`
+	Verified        bool       `json:"verified"`
+}
+
+type EndpointValidation struct {
+	Fields      []FieldValidation `json:"fields"`
+	Method      string            `json:"method"`
+	OperationID string            `json:"operation_id"`
+	Path        string            `json:"path"`
+}
+
+type ErrorResponse struct {
+	Code     string       `json:"code"`
+	Fields   []FieldError `json:"fields,omitempty"`
+	Message  string       `json:"message"`
+	Override bool         `json:"override"`
+	Status   int          `json:"status"`
+}
+
+type Export struct {
+	Columns     []string         `json:"columns"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Dataset     string           `json:"dataset"`
+	DownloadURL *string          `json:"download_url,omitempty"`
+	Error       *string          `json:"error,omitempty"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"`
+	Format      string           `json:"format"`
+	From        *time.Time       `json:"from,omitempty"`
+	ID          string           `json:"id"`
+	Language    *string          `json:"language,omitempty"`
+	RequestedBy string           `json:"requested_by"`
+	RowCount    *int64           `json:"row_count,omitempty"`
+	SizeBytes   *int64           `json:"size_bytes,omitempty"`
+	Status      DataExportStatus `json:"status"`
+	Until       *time.Time       `json:"until,omitempty"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+type FieldError struct {
+	Error string `json:"error"`
+	Field string `json:"field"`
+}
+
+type FieldValidation struct {
+	Name     string           `json:"name"`
+	Nullable bool             `json:"nullable"`
+	Required bool             `json:"required"`
+	Rules    []ValidationRule `json:"rules"`
+	Type     string           `json:"type"`
+}
+
+type HealthResponse struct {
+	Checks      map[string]any `json:"checks,omitempty"`
+	Environment string         `json:"environment"`
+	Status      string         `json:"status"`
+	Timestamp   time.Time      `json:"timestamp"`
+}
+
+type Import struct {
+	CompletedAt   *time.Time    `json:"completed_at,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Dataset       string        `json:"dataset"`
+	Error         *string       `json:"error,omitempty"`
+	ErrorCount    int64         `json:"error_count"`
+	Errors        []ImportError `json:"errors"`
+	Filename      string        `json:"filename"`
+	ID            string        `json:"id"`
+	ProcessedRows int64         `json:"processed_rows"`
+	RequestedBy   string        `json:"requested_by"`
+	Status        ImportStatus  `json:"status"`
+	TotalRows     int64         `json:"total_rows"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	ValidRows     int64         `json:"valid_rows"`
+}
+
+type ImportError struct {
+	Field   *string `json:"field,omitempty"`
+	Line    int     `json:"line"`
+	Message string  `json:"message"`
+}
+
+type ImportStatus string
+
+const (
+	ImportStatusValidating ImportStatus = "validating"
+	ImportStatusInvalid    ImportStatus = "invalid"
+	ImportStatusPending    ImportStatus = "pending"
+	ImportStatusProcessing ImportStatus = "processing"
+	ImportStatusCompleted  ImportStatus = "completed"
+	ImportStatusFailed     ImportStatus = "failed"
+)
+
+type PageInfo struct {
+	CountStrategy string `json:"count_strategy"`
+	HasNext       bool   `json:"has_next"`
+	Limit         int    `json:"limit"`
+	Page          int    `json:"page"`
+	Total         *int64 `json:"total,omitempty"`
+	TotalPages    *int64 `json:"total_pages,omitempty"`
+}
+
+type PeriodUsage struct {
+	Limit     int64     `json:"limit"`
+	Period    string    `json:"period"`
+	Remaining int64     `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+	Used      int64     `json:"used"`
+}
+
+type Policy struct {
+	Action      string    `json:"action"`
+	CreatedAt   time.Time `json:"created_at"`
+	Description *string   `json:"description,omitempty"`
+	Effect      string    `json:"effect"`
+	ID          string    `json:"id"`
+	Resource    string    `json:"resource"`
+	Subject     string    `json:"subject"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ProgressUpdate struct {
+	Message     *string   `json:"message,omitempty"`
+	OperationID string    `json:"operation_id"`
+	Percent     int       `json:"percent"`
+	State       string    `json:"state"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type QuotaUsage struct {
+	Consumer string      `json:"consumer"`
+	Daily    PeriodUsage `json:"daily"`
+	Exceeded *string     `json:"exceeded,omitempty"`
+	Monthly  PeriodUsage `json:"monthly"`
+}
+
+type RegisterDevicePayload struct {
+	Platform string `json:"platform"`
+	Provider string `json:"provider"`
+	Token    string `json:"token"`
+}
+
+type RequestEmailVerificationPayload struct {
+	Email string `json:"email"`
+}
+
+type RouteToggle struct {
+	DisabledAt time.Time `json:"disabled_at"`
+	DisabledBy *string   `json:"disabled_by,omitempty"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Reason     string    `json:"reason"`
+}
+
+type Setting struct {
+	Default     any        `json:"default"`
+	Description string     `json:"description"`
+	Key         string     `json:"key"`
+	Overridden  bool       `json:"overridden"`
+	Type        string     `json:"type"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy   *string    `json:"updated_by,omitempty"`
+	Value       any        `json:"value"`
+}
+
+type Subscription struct {
+	CancelAtPeriodEnd      bool               `json:"cancel_at_period_end"`
+	CanceledAt             *time.Time         `json:"canceled_at,omitempty"`
+	CreatedAt              time.Time          `json:"created_at"`
+	CurrentPeriodEnd       *time.Time         `json:"current_period_end,omitempty"`
+	CustomerID             string             `json:"customer_id"`
+	ID                     string             `json:"id"`
+	OwnerID                string             `json:"owner_id"`
+	PriceID                string             `json:"price_id"`
+	Provider               string             `json:"provider"`
+	ProviderSubscriptionID string             `json:"provider_subscription_id"`
+	Status                 SubscriptionStatus `json:"status"`
+	SyncedAt               time.Time          `json:"synced_at"`
+	UpdatedAt              time.Time          `json:"updated_at"`
+}
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusTrialing          SubscriptionStatus = "trialing"
+	SubscriptionStatusActive            SubscriptionStatus = "active"
+	SubscriptionStatusPastDue           SubscriptionStatus = "past_due"
+	SubscriptionStatusUnpaid            SubscriptionStatus = "unpaid"
+	SubscriptionStatusPaused            SubscriptionStatus = "paused"
+	SubscriptionStatusIncomplete        SubscriptionStatus = "incomplete"
+	SubscriptionStatusIncompleteExpired SubscriptionStatus = "incomplete_expired"
+	SubscriptionStatusCanceled          SubscriptionStatus = "canceled"
+)
+
+type TenantLimits struct {
+	CreatedAt         time.Time `json:"created_at"`
+	DailyQuota        *int64    `json:"daily_quota,omitempty"`
+	MonthlyQuota      *int64    `json:"monthly_quota,omitempty"`
+	OrganizationID    string    `json:"organization_id"`
+	Plan              *string   `json:"plan,omitempty"`
+	RequestsPerMinute *int      `json:"requests_per_minute,omitempty"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type UpdateSettingPayload struct {
+	Value any `json:"value"`
+}
+
+type UpdateTenantLimitsPayload struct {
+	DailyQuota        *int64  `json:"daily_quota,omitempty"`
+	MonthlyQuota      *int64  `json:"monthly_quota,omitempty"`
+	Plan              *string `json:"plan,omitempty"`
+	RequestsPerMinute *int    `json:"requests_per_minute,omitempty"`
+}
+
+type ValidationRule struct {
+	Message string   `json:"message"`
+	Param   *string  `json:"param,omitempty"`
+	Tag     string   `json:"tag"`
+	Values  []string `json:"values,omitempty"`
+}
+
+type ValidationSchema struct {
+	Endpoints []EndpointValidation `json:"endpoints"`
+}
+
+// Route is an API route the client has a method for.
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+}
+
+// Routes lists every route of the OpenAPI spec the client was generated from.
+var Routes = []Route{
+	{Method: "GET", Path: "/api/v1/admin/audit-logs", OperationID: "adminListAuditLogs"},
+	{Method: "GET", Path: "/api/v1/admin/authz/policies", OperationID: "adminListPolicies"},
+	{Method: "POST", Path: "/api/v1/admin/authz/policies", OperationID: "adminCreatePolicy"},
+	{Method: "DELETE", Path: "/api/v1/admin/authz/policies/{id}", OperationID: "adminDeletePolicy"},
+	{Method: "GET", Path: "/api/v1/admin/config-changes", OperationID: "adminListConfigChanges"},
+	{Method: "GET", Path: "/api/v1/admin/datasets/{dataset}/export", OperationID: "adminStreamExport"},
+	{Method: "POST", Path: "/api/v1/admin/datasets/{dataset}/exports", OperationID: "adminRequestExport"},
+	{Method: "GET", Path: "/api/v1/admin/email-suppressions", OperationID: "adminListEmailSuppressions"},
+	{Method: "GET", Path: "/api/v1/admin/exports/{id}", OperationID: "adminGetExport"},
+	{Method: "POST", Path: "/api/v1/admin/imports/{dataset}", OperationID: "adminUploadImport"},
+	{Method: "GET", Path: "/api/v1/admin/imports/{id}", OperationID: "adminGetImport"},
+	{Method: "GET", Path: "/api/v1/admin/operations/{id}/events", OperationID: "adminStreamOperationProgress"},
+	{Method: "GET", Path: "/api/v1/admin/operations/{id}/progress", OperationID: "adminGetOperationProgress"},
+	{Method: "GET", Path: "/api/v1/admin/routes/disabled", OperationID: "adminListDisabledRoutes"},
+	{Method: "PUT", Path: "/api/v1/admin/routes/disabled", OperationID: "adminDisableRoute"},
+	{Method: "DELETE", Path: "/api/v1/admin/routes/disabled", OperationID: "adminEnableRoute"},
+	{Method: "GET", Path: "/api/v1/admin/settings", OperationID: "adminListSettings"},
+	{Method: "GET", Path: "/api/v1/admin/settings/{key}", OperationID: "adminGetSetting"},
+	{Method: "PUT", Path: "/api/v1/admin/settings/{key}", OperationID: "adminUpdateSetting"},
+	{Method: "DELETE", Path: "/api/v1/admin/settings/{key}", OperationID: "adminDeleteSetting"},
+	{Method: "GET", Path: "/api/v1/admin/tenants/{id}/limits", OperationID: "adminGetTenantLimits"},
+	{Method: "PUT", Path: "/api/v1/admin/tenants/{id}/limits", OperationID: "adminUpdateTenantLimits"},
+	{Method: "DELETE", Path: "/api/v1/admin/tenants/{id}/limits", OperationID: "adminDeleteTenantLimits"},
+	{Method: "GET", Path: "/api/v1/compliance/exports/{id}/download", OperationID: "downloadDataExport"},
+	{Method: "GET", Path: "/api/v1/email/unsubscribe", OperationID: "getEmailUnsubscribe"},
+	{Method: "POST", Path: "/api/v1/email/unsubscribe", OperationID: "unsubscribeEmail"},
+	{Method: "GET", Path: "/api/v1/email/verify", OperationID: "verifyEmail"},
+	{Method: "GET", Path: "/api/v1/exports/{id}/download", OperationID: "downloadExport"},
+	{Method: "POST", Path: "/api/v1/me/account-deletion", OperationID: "requestAccountDeletion"},
+	{Method: "DELETE", Path: "/api/v1/me/account-deletion", OperationID: "cancelAccountDeletion"},
+	{Method: "GET", Path: "/api/v1/me/audit-logs", OperationID: "listAuditLogs"},
+	{Method: "POST", Path: "/api/v1/me/billing/checkout", OperationID: "createCheckout"},
+	{Method: "GET", Path: "/api/v1/me/billing/subscriptions", OperationID: "listSubscriptions"},
+	{Method: "POST", Path: "/api/v1/me/data-exports", OperationID: "requestDataExport"},
+	{Method: "GET", Path: "/api/v1/me/data-exports/{id}", OperationID: "getDataExport"},
+	{Method: "GET", Path: "/api/v1/me/devices", OperationID: "listDevices"},
+	{Method: "POST", Path: "/api/v1/me/devices", OperationID: "registerDevice"},
+	{Method: "DELETE", Path: "/api/v1/me/devices/{id}", OperationID: "deleteDevice"},
+	{Method: "GET", Path: "/api/v1/me/email-verification", OperationID: "getEmailVerification"},
+	{Method: "POST", Path: "/api/v1/me/email-verification", OperationID: "requestEmailVerification"},
+	{Method: "POST", Path: "/api/v1/me/email-verification/resend", OperationID: "resendEmailVerification"},
+	{Method: "GET", Path: "/api/v1/me/quota", OperationID: "getQuota"},
+	{Method: "GET", Path: "/health/deep", OperationID: "getDeepHealth"},
+	{Method: "GET", Path: "/status", OperationID: "getStatus"},
+	{Method: "GET", Path: "/validation-schema", OperationID: "getValidationSchema"},
+}
+
+// AdminListAuditLogsParams are the query parameters of AdminListAuditLogs.
+type AdminListAuditLogsParams struct {
+	Limit                *int
+	Cursor               *string
+	Sort                 *string
+	FilterIDEq           *string
+	FilterActorIDEq      *string
+	FilterActorIDIn      *string
+	FilterActionEq       *string
+	FilterActionNe       *string
+	FilterActionIn       *string
+	FilterActionPrefix   *string
+	FilterResourceTypeEq *string
+	FilterResourceTypeIn *string
+	FilterResourceIDEq   *string
+	FilterRequestIDEq    *string
+	FilterCreatedAtLt    *time.Time
+	FilterCreatedAtLte   *time.Time
+	FilterCreatedAtGt    *time.Time
+	FilterCreatedAtGte   *time.Time
+}
+
+// AdminListAuditLogs: List the audit trail of every user (admin only).
+//
+// GET /api/v1/admin/audit-logs
+func (c *Client) AdminListAuditLogs(ctx context.Context, params *AdminListAuditLogsParams) (*AuditLogCursorPage, error) {
+	path := "/api/v1/admin/audit-logs"
+	query := url.Values{}
+	if params != nil {
+		if params.Limit != nil {
+			query.Set("limit", fmt.Sprint(*params.Limit))
+		}
+		if params.Cursor != nil {
+			query.Set("cursor", fmt.Sprint(*params.Cursor))
+		}
+		if params.Sort != nil {
+			query.Set("sort", fmt.Sprint(*params.Sort))
+		}
+		if params.FilterIDEq != nil {
+			query.Set("filter[id][eq]", fmt.Sprint(*params.FilterIDEq))
+		}
+		if params.FilterActorIDEq != nil {
+			query.Set("filter[actor_id][eq]", fmt.Sprint(*params.FilterActorIDEq))
+		}
+		if params.FilterActorIDIn != nil {
+			query.Set("filter[actor_id][in]", fmt.Sprint(*params.FilterActorIDIn))
+		}
+		if params.FilterActionEq != nil {
+			query.Set("filter[action][eq]", fmt.Sprint(*params.FilterActionEq))
+		}
+		if params.FilterActionNe != nil {
+			query.Set("filter[action][ne]", fmt.Sprint(*params.FilterActionNe))
+		}
+		if params.FilterActionIn != nil {
+			query.Set("filter[action][in]", fmt.Sprint(*params.FilterActionIn))
+		}
+		if params.FilterActionPrefix != nil {
+			query.Set("filter[action][prefix]", fmt.Sprint(*params.FilterActionPrefix))
+		}
+		if params.FilterResourceTypeEq != nil {
+			query.Set("filter[resource_type][eq]", fmt.Sprint(*params.FilterResourceTypeEq))
+		}
+		if params.FilterResourceTypeIn != nil {
+			query.Set("filter[resource_type][in]", fmt.Sprint(*params.FilterResourceTypeIn))
+		}
+		if params.FilterResourceIDEq != nil {
+			query.Set("filter[resource_id][eq]", fmt.Sprint(*params.FilterResourceIDEq))
+		}
+		if params.FilterRequestIDEq != nil {
+			query.Set("filter[request_id][eq]", fmt.Sprint(*params.FilterRequestIDEq))
+		}
+		if params.FilterCreatedAtLt != nil {
+			query.Set("filter[created_at][lt]", params.FilterCreatedAtLt.Format(time.RFC3339))
+		}
+		if params.FilterCreatedAtLte != nil {
+			query.Set("filter[created_at][lte]", params.FilterCreatedAtLte.Format(time.RFC3339))
+		}
+		if params.FilterCreatedAtGt != nil {
+			query.Set("filter[created_at][gt]", params.FilterCreatedAtGt.Format(time.RFC3339))
+		}
+		if params.FilterCreatedAtGte != nil {
+			query.Set("filter[created_at][gte]", params.FilterCreatedAtGte.Format(time.RFC3339))
+		}
+	}
+	var out AuditLogCursorPage
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminListPolicies: Authorization policies (admin only).
+//
+// GET /api/v1/admin/authz/policies
+func (c *Client) AdminListPolicies(ctx context.Context) (*[]Policy, error) {
+	path := "/api/v1/admin/authz/policies"
+	var out []Policy
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminCreatePolicy: Add an authorization policy, applied without a restart (admin only).
+//
+// POST /api/v1/admin/authz/policies
+func (c *Client) AdminCreatePolicy(ctx context.Context, body CreatePolicyPayload) (*Policy, error) {
+	path := "/api/v1/admin/authz/policies"
+	var out Policy
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminDeletePolicy: Remove an authorization policy (admin only).
+//
+// DELETE /api/v1/admin/authz/policies/{id}
+func (c *Client) AdminDeletePolicy(ctx context.Context, id string) error {
+	path := "/api/v1/admin/authz/policies/" + url.PathEscape(id)
+	return c.do(ctx, "DELETE", path, nil, nil, nil)
+}
+
+// AdminListConfigChangesParams are the query parameters of AdminListConfigChanges.
+type AdminListConfigChangesParams struct {
+	Limit                  *int
+	Cursor                 *string
+	Sort                   *string
+	FilterIDEq             *string
+	FilterActorIDEq        *string
+	FilterActorIDIn        *string
+	FilterActionEq         *string
+	FilterActionIn         *string
+	FilterActionPrefix     *string
+	FilterResourceIDEq     *string
+	FilterResourceIDPrefix *string
+	FilterRequestIDEq      *string
+	FilterCreatedAtLt      *time.Time
+	FilterCreatedAtLte     *time.Time
+	FilterCreatedAtGt      *time.Time
+	FilterCreatedAtGte     *time.Time
+}
+
+// AdminListConfigChanges: List the change history of runtime settings, with old and new values (admin only).
+//
+// GET /api/v1/admin/config-changes
+func (c *Client) AdminListConfigChanges(ctx context.Context, params *AdminListConfigChangesParams) (*AuditLogCursorPage, error) {
+	path := "/api/v1/admin/config-changes"
+	query := url.Values{}
+	if params != nil {
+		if params.Limit != nil {
+			query.Set("limit", fmt.Sprint(*params.Limit))
+		}
+		if params.Cursor != nil {
+			query.Set("cursor", fmt.Sprint(*params.Cursor))
+		}
+		if params.Sort != nil {
+			query.Set("sort", fmt.Sprint(*params.Sort))
+		}
+		if params.FilterIDEq != nil {
+			query.Set("filter[id][eq]", fmt.Sprint(*params.FilterIDEq))
+		}
+		if params.FilterActorIDEq != nil {
+			query.Set("filter[actor_id][eq]", fmt.Sprint(*params.FilterActorIDEq))
+		}
+		if params.FilterActorIDIn != nil {
+			query.Set("filter[actor_id][in]", fmt.Sprint(*params.FilterActorIDIn))
+		}
+		if params.FilterActionEq != nil {
+			query.Set("filter[action][eq]", fmt.Sprint(*params.FilterActionEq))
+		}
+		if params.FilterActionIn != nil {
+			query.Set("filter[action][in]", fmt.Sprint(*params.FilterActionIn))
+		}
+		if params.FilterActionPrefix != nil {
+			query.Set("filter[action][prefix]", fmt.Sprint(*params.FilterActionPrefix))
+		}
+		if params.FilterResourceIDEq != nil {
+			query.Set("filter[resource_id][eq]", fmt.Sprint(*params.FilterResourceIDEq))
+		}
+		if params.FilterResourceIDPrefix != nil {
+			query.Set("filter[resource_id][prefix]", fmt.Sprint(*params.FilterResourceIDPrefix))
+		}
+		if params.FilterRequestIDEq != nil {
+			query.Set("filter[request_id][eq]", fmt.Sprint(*params.FilterRequestIDEq))
+		}
+		if params.FilterCreatedAtLt != nil {
+			query.Set("filter[created_at][lt]", params.FilterCreatedAtLt.Format(time.RFC3339))
+		}
+		if params.FilterCreatedAtLte != nil {
+			query.Set("filter[created_at][lte]", params.FilterCreatedAtLte.Format(time.RFC3339))
+		}
+		if params.FilterCreatedAtGt != nil {
+			query.Set("filter[created_at][gt]", params.FilterCreatedAtGt.Format(time.RFC3339))
+		}
+		if params.FilterCreatedAtGte != nil {
+			query.Set("filter[created_at][gte]", params.FilterCreatedAtGte.Format(time.RFC3339))
+		}
+	}
+	var out AuditLogCursorPage
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminStreamExportParams are the query parameters of AdminStreamExport.
+type AdminStreamExportParams struct {
+	Format  *string
+	Columns *string
+	From    *time.Time
+	Until   *time.Time
+}
+
+// AdminStreamExport: Stream a dataset as CSV or XLSX, headers localized from Accept-Language (admin only).
+//
+// GET /api/v1/admin/datasets/{dataset}/export
+func (c *Client) AdminStreamExport(ctx context.Context, dataset string, params *AdminStreamExportParams) ([]byte, error) {
+	path := "/api/v1/admin/datasets/" + url.PathEscape(dataset) + "/export"
+	query := url.Values{}
+	if params != nil {
+		if params.Format != nil {
+			query.Set("format", fmt.Sprint(*params.Format))
+		}
+		if params.Columns != nil {
+			query.Set("columns", fmt.Sprint(*params.Columns))
+		}
+		if params.From != nil {
+			query.Set("from", params.From.Format(time.RFC3339))
+		}
+		if params.Until != nil {
+			query.Set("until", params.Until.Format(time.RFC3339))
+		}
+	}
+	return c.doRaw(ctx, "GET", path, query, nil)
+}
+
+// AdminRequestExport: Start an async export of a dataset, for exports too large to stream (admin only).
+//
+// POST /api/v1/admin/datasets/{dataset}/exports
+func (c *Client) AdminRequestExport(ctx context.Context, dataset string, body CreateExportPayload) (*Export, error) {
+	path := "/api/v1/admin/datasets/" + url.PathEscape(dataset) + "/exports"
+	var out Export
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminListEmailSuppressionsParams are the query parameters of AdminListEmailSuppressions.
+type AdminListEmailSuppressionsParams struct {
+	Limit              *int
+	Cursor             *string
+	Sort               *string
+	FilterEmailEq      *string
+	FilterEmailPrefix  *string
+	FilterReasonEq     *string
+	FilterReasonIn     *string
+	FilterCreatedAtLt  *time.Time
+	FilterCreatedAtLte *time.Time
+	FilterCreatedAtGt  *time.Time
+	FilterCreatedAtGte *time.Time
+}
+
+// AdminListEmailSuppressions: List the email suppression list (admin only).
+//
+// GET /api/v1/admin/email-suppressions
+func (c *Client) AdminListEmailSuppressions(ctx context.Context, params *AdminListEmailSuppressionsParams) (*EmailSuppressionCursorPage, error) {
+	path := "/api/v1/admin/email-suppressions"
+	query := url.Values{}
+	if params != nil {
+		if params.Limit != nil {
+			query.Set("limit", fmt.Sprint(*params.Limit))
+		}
+		if params.Cursor != nil {
+			query.Set("cursor", fmt.Sprint(*params.Cursor))
+		}
+		if params.Sort != nil {
+			query.Set("sort", fmt.Sprint(*params.Sort))
+		}
+		if params.FilterEmailEq != nil {
+			query.Set("filter[email][eq]", fmt.Sprint(*params.FilterEmailEq))
+		}
+		if params.FilterEmailPrefix != nil {
+			query.Set("filter[email][prefix]", fmt.Sprint(*params.FilterEmailPrefix))
+		}
+		if params.FilterReasonEq != nil {
+			query.Set("filter[reason][eq]", fmt.Sprint(*params.FilterReasonEq))
+		}
+		if params.FilterReasonIn != nil {
+			query.Set("filter[reason][in]", fmt.Sprint(*params.FilterReasonIn))
+		}
+		if params.FilterCreatedAtLt != nil {
+			query.Set("filter[created_at][lt]", params.FilterCreatedAtLt.Format(time.RFC3339))
+		}
+		if params.FilterCreatedAtLte != nil {
+			query.Set("filter[created_at][lte]", params.FilterCreatedAtLte.Format(time.RFC3339))
+		}
+		if params.FilterCreatedAtGt != nil {
+			query.Set("filter[created_at][gt]", params.FilterCreatedAtGt.Format(time.RFC3339))
+		}
+		if params.FilterCreatedAtGte != nil {
+			query.Set("filter[created_at][gte]", params.FilterCreatedAtGte.Format(time.RFC3339))
+		}
+	}
+	var out EmailSuppressionCursorPage
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminGetExport: Status of an async export, with a signed download link once ready (admin only).
+//
+// GET /api/v1/admin/exports/{id}
+func (c *Client) AdminGetExport(ctx context.Context, id string) (*Export, error) {
+	path := "/api/v1/admin/exports/" + url.PathEscape(id)
+	var out Export
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminUploadImportParams are the query parameters of AdminUploadImport.
+type AdminUploadImportParams struct {
+	SkipInvalid *bool
+}
+
+// AdminUploadImport: Upload a CSV file to import into a dataset, rows are validated before they are committed (admin only).
+//
+// POST /api/v1/admin/imports/{dataset}
+func (c *Client) AdminUploadImport(ctx context.Context, dataset string, params *AdminUploadImportParams, filename string, file io.Reader) (*Import, error) {
+	path := "/api/v1/admin/imports/" + url.PathEscape(dataset)
+	query := url.Values{}
+	if params != nil {
+		if params.SkipInvalid != nil {
+			query.Set("skip_invalid", fmt.Sprint(*params.SkipInvalid))
+		}
+	}
+	var out Import
+	if err := c.do(ctx, "POST", path, query, &upload{field: "file", filename: filename, file: file}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminGetImport: Status of an import, with its validation report and progress (admin only).
+//
+// GET /api/v1/admin/imports/{id}
+func (c *Client) AdminGetImport(ctx context.Context, id string) (*Import, error) {
+	path := "/api/v1/admin/imports/" + url.PathEscape(id)
+	var out Import
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminStreamOperationProgress: Stream the progress of an async export or import as server-sent events until it is done (admin only).
+//
+// GET /api/v1/admin/operations/{id}/events
+func (c *Client) AdminStreamOperationProgress(ctx context.Context, id string) ([]byte, error) {
+	path := "/api/v1/admin/operations/" + url.PathEscape(id) + "/events"
+	return c.doRaw(ctx, "GET", path, nil, nil)
+}
+
+// AdminGetOperationProgress: Last progress update of an async export or import (admin only).
+//
+// GET /api/v1/admin/operations/{id}/progress
+func (c *Client) AdminGetOperationProgress(ctx context.Context, id string) (*ProgressUpdate, error) {
+	path := "/api/v1/admin/operations/" + url.PathEscape(id) + "/progress"
+	var out ProgressUpdate
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminListDisabledRoutes: Routes disabled at runtime (admin only).
+//
+// GET /api/v1/admin/routes/disabled
+func (c *Client) AdminListDisabledRoutes(ctx context.Context) (*[]RouteToggle, error) {
+	path := "/api/v1/admin/routes/disabled"
+	var out []RouteToggle
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminDisableRoute: Disable a route on every instance, its requests get 503 ROUTE_DISABLED (admin only).
+//
+// PUT /api/v1/admin/routes/disabled
+func (c *Client) AdminDisableRoute(ctx context.Context, body DisableRoutePayload) (*RouteToggle, error) {
+	path := "/api/v1/admin/routes/disabled"
+	var out RouteToggle
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminEnableRouteParams are the query parameters of AdminEnableRoute.
+type AdminEnableRouteParams struct {
+	Method string
+	Path   string
+}
+
+// AdminEnableRoute: Enable a disabled route again (admin only).
+//
+// DELETE /api/v1/admin/routes/disabled
+func (c *Client) AdminEnableRoute(ctx context.Context, params *AdminEnableRouteParams) error {
+	path := "/api/v1/admin/routes/disabled"
+	query := url.Values{}
+	if params != nil {
+		query.Set("method", fmt.Sprint(params.Method))
+		query.Set("path", fmt.Sprint(params.Path))
+	}
+	return c.do(ctx, "DELETE", path, query, nil, nil)
+}
+
+// AdminListSettings: Runtime settings with their current values (admin only).
+//
+// GET /api/v1/admin/settings
+func (c *Client) AdminListSettings(ctx context.Context) (*[]Setting, error) {
+	path := "/api/v1/admin/settings"
+	var out []Setting
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminGetSetting: A runtime setting with its current value (admin only).
+//
+// GET /api/v1/admin/settings/{key}
+func (c *Client) AdminGetSetting(ctx context.Context, key string) (*Setting, error) {
+	path := "/api/v1/admin/settings/" + url.PathEscape(key)
+	var out Setting
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminUpdateSetting: Change the value of a runtime setting, applied without a redeploy (admin only).
+//
+// PUT /api/v1/admin/settings/{key}
+func (c *Client) AdminUpdateSetting(ctx context.Context, key string, body UpdateSettingPayload) (*Setting, error) {
+	path := "/api/v1/admin/settings/" + url.PathEscape(key)
+	var out Setting
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminDeleteSetting: Return a runtime setting to its default (admin only).
+//
+// DELETE /api/v1/admin/settings/{key}
+func (c *Client) AdminDeleteSetting(ctx context.Context, key string) error {
+	path := "/api/v1/admin/settings/" + url.PathEscape(key)
+	return c.do(ctx, "DELETE", path, nil, nil, nil)
+}
+
+// AdminGetTenantLimits: Rate limit and quota overrides of an organization (admin only).
+//
+// GET /api/v1/admin/tenants/{id}/limits
+func (c *Client) AdminGetTenantLimits(ctx context.Context, id string) (*TenantLimits, error) {
+	path := "/api/v1/admin/tenants/" + url.PathEscape(id) + "/limits"
+	var out TenantLimits
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminUpdateTenantLimits: Replace the rate limit and quota overrides of an organization (admin only).
+//
+// PUT /api/v1/admin/tenants/{id}/limits
+func (c *Client) AdminUpdateTenantLimits(ctx context.Context, id string, body UpdateTenantLimitsPayload) (*TenantLimits, error) {
+	path := "/api/v1/admin/tenants/" + url.PathEscape(id) + "/limits"
+	var out TenantLimits
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdminDeleteTenantLimits: Return an organization to the default rate limit and quotas (admin only).
+//
+// DELETE /api/v1/admin/tenants/{id}/limits
+func (c *Client) AdminDeleteTenantLimits(ctx context.Context, id string) error {
+	path := "/api/v1/admin/tenants/" + url.PathEscape(id) + "/limits"
+	return c.do(ctx, "DELETE", path, nil, nil, nil)
+}
+
+// DownloadDataExportParams are the query parameters of DownloadDataExport.
+type DownloadDataExportParams struct {
+	Expires   string
+	Signature string
+}
+
+// DownloadDataExport: Download an export archive through a signed link.
+//
+// GET /api/v1/compliance/exports/{id}/download
+func (c *Client) DownloadDataExport(ctx context.Context, id string, params *DownloadDataExportParams) ([]byte, error) {
+	path := "/api/v1/compliance/exports/" + url.PathEscape(id) + "/download"
+	query := url.Values{}
+	if params != nil {
+		query.Set("expires", fmt.Sprint(params.Expires))
+		query.Set("signature", fmt.Sprint(params.Signature))
+	}
+	return c.doRaw(ctx, "GET", path, query, nil)
+}
+
+// GetEmailUnsubscribeParams are the query parameters of GetEmailUnsubscribe.
+type GetEmailUnsubscribeParams struct {
+	Token string
+}
+
+// GetEmailUnsubscribe: Describe a signed unsubscribe link, without unsubscribing.
+//
+// GET /api/v1/email/unsubscribe
+func (c *Client) GetEmailUnsubscribe(ctx context.Context, params *GetEmailUnsubscribeParams) (*EmailUnsubscribe, error) {
+	path := "/api/v1/email/unsubscribe"
+	query := url.Values{}
+	if params != nil {
+		query.Set("token", fmt.Sprint(params.Token))
+	}
+	var out EmailUnsubscribe
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UnsubscribeEmailParams are the query parameters of UnsubscribeEmail.
+type UnsubscribeEmailParams struct {
+	Token string
+}
+
+// UnsubscribeEmail: Unsubscribe from bulk emails through a signed link, also used for one-click unsubscribes (RFC 8058).
+//
+// POST /api/v1/email/unsubscribe
+func (c *Client) UnsubscribeEmail(ctx context.Context, params *UnsubscribeEmailParams) (*EmailUnsubscribe, error) {
+	path := "/api/v1/email/unsubscribe"
+	query := url.Values{}
+	if params != nil {
+		query.Set("token", fmt.Sprint(params.Token))
+	}
+	var out EmailUnsubscribe
+	if err := c.do(ctx, "POST", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// VerifyEmailParams are the query parameters of VerifyEmail.
+type VerifyEmailParams struct {
+	Token string
+}
+
+// VerifyEmail: Verify an email address through the link of a verification email.
+//
+// GET /api/v1/email/verify
+func (c *Client) VerifyEmail(ctx context.Context, params *VerifyEmailParams) (*EmailVerification, error) {
+	path := "/api/v1/email/verify"
+	query := url.Values{}
+	if params != nil {
+		query.Set("token", fmt.Sprint(params.Token))
+	}
+	var out EmailVerification
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DownloadExportParams are the query parameters of DownloadExport.
+type DownloadExportParams struct {
+	Expires   string
+	Signature string
+}
+
+// DownloadExport: Download the file of an async export through a signed link.
+//
+// GET /api/v1/exports/{id}/download
+func (c *Client) DownloadExport(ctx context.Context, id string, params *DownloadExportParams) ([]byte, error) {
+	path := "/api/v1/exports/" + url.PathEscape(id) + "/download"
+	query := url.Values{}
+	if params != nil {
+		query.Set("expires", fmt.Sprint(params.Expires))
+		query.Set("signature", fmt.Sprint(params.Signature))
+	}
+	return c.doRaw(ctx, "GET", path, query, nil)
+}
+
+// RequestAccountDeletion: Schedule deletion of the user's account.
+//
+// POST /api/v1/me/account-deletion
+func (c *Client) RequestAccountDeletion(ctx context.Context) (*AccountDeletion, error) {
+	path := "/api/v1/me/account-deletion"
+	var out AccountDeletion
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelAccountDeletion: Cancel a pending account deletion.
+//
+// DELETE /api/v1/me/account-deletion
+func (c *Client) CancelAccountDeletion(ctx context.Context) (*AccountDeletion, error) {
+	path := "/api/v1/me/account-deletion"
+	var out AccountDeletion
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAuditLogsParams are the query parameters of ListAuditLogs.
+type ListAuditLogsParams struct {
+	Page  *int
+	Limit *int
+	From  *time.Time
+	Until *time.Time
+}
+
+// ListAuditLogs: Page through the audit trail of the user's own actions.
+//
+// GET /api/v1/me/audit-logs
+func (c *Client) ListAuditLogs(ctx context.Context, params *ListAuditLogsParams) (*AuditLogPage, error) {
+	path := "/api/v1/me/audit-logs"
+	query := url.Values{}
+	if params != nil {
+		if params.Page != nil {
+			query.Set("page", fmt.Sprint(*params.Page))
+		}
+		if params.Limit != nil {
+			query.Set("limit", fmt.Sprint(*params.Limit))
+		}
+		if params.From != nil {
+			query.Set("from", params.From.Format(time.RFC3339))
+		}
+		if params.Until != nil {
+			query.Set("until", params.Until.Format(time.RFC3339))
+		}
+	}
+	var out AuditLogPage
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateCheckout: Start a hosted checkout subscribing the caller's organization, or the caller outside of one, to a price.
+//
+// POST /api/v1/me/billing/checkout
+func (c *Client) CreateCheckout(ctx context.Context, body CreateCheckoutPayload) (*CheckoutSession, error) {
+	path := "/api/v1/me/billing/checkout"
+	var out CheckoutSession
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSubscriptions: Subscriptions of the caller's organization, or the caller outside of one.
+//
+// GET /api/v1/me/billing/subscriptions
+func (c *Client) ListSubscriptions(ctx context.Context) (*[]Subscription, error) {
+	path := "/api/v1/me/billing/subscriptions"
+	var out []Subscription
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RequestDataExport: Start an export of all of the user's data.
+//
+// POST /api/v1/me/data-exports
+func (c *Client) RequestDataExport(ctx context.Context) (*DataExport, error) {
+	path := "/api/v1/me/data-exports"
+	var out DataExport
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetDataExport: Status of a data export, with a signed download link once ready.
+//
+// GET /api/v1/me/data-exports/{id}
+func (c *Client) GetDataExport(ctx context.Context, id string) (*DataExport, error) {
+	path := "/api/v1/me/data-exports/" + url.PathEscape(id)
+	var out DataExport
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListDevices: Devices of the caller registered for push notifications.
+//
+// GET /api/v1/me/devices
+func (c *Client) ListDevices(ctx context.Context) (*[]Device, error) {
+	path := "/api/v1/me/devices"
+	var out []Device
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RegisterDevice: Register the push token of one of the caller's app installs.
+//
+// POST /api/v1/me/devices
+func (c *Client) RegisterDevice(ctx context.Context, body RegisterDevicePayload) (*Device, error) {
+	path := "/api/v1/me/devices"
+	var out Device
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteDevice: Stop push notifications to one of the caller's devices.
+//
+// DELETE /api/v1/me/devices/{id}
+func (c *Client) DeleteDevice(ctx context.Context, id string) error {
+	path := "/api/v1/me/devices/" + url.PathEscape(id)
+	return c.do(ctx, "DELETE", path, nil, nil, nil)
+}
+
+// GetEmailVerification: Verification state of the caller's email address.
+//
+// GET /api/v1/me/email-verification
+func (c *Client) GetEmailVerification(ctx context.Context) (*EmailVerification, error) {
+	path := "/api/v1/me/email-verification"
+	var out EmailVerification
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RequestEmailVerification: Send a verification link to the caller's email address.
+//
+// POST /api/v1/me/email-verification
+func (c *Client) RequestEmailVerification(ctx context.Context, body RequestEmailVerificationPayload) (*EmailVerification, error) {
+	path := "/api/v1/me/email-verification"
+	var out EmailVerification
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResendEmailVerification: Send a new link to the address pending verification, the previous link stops working.
+//
+// POST /api/v1/me/email-verification/resend
+func (c *Client) ResendEmailVerification(ctx context.Context) (*EmailVerification, error) {
+	path := "/api/v1/me/email-verification/resend"
+	var out EmailVerification
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetQuota: Remaining daily and monthly request quota.
+//
+// GET /api/v1/me/quota
+func (c *Client) GetQuota(ctx context.Context) (*QuotaUsage, error) {
+	path := "/api/v1/me/quota"
+	var out QuotaUsage
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetDeepHealth: Exercise the database, job queue and email delivery end to end, with per-step timings (admin only).
+//
+// GET /health/deep
+func (c *Client) GetDeepHealth(ctx context.Context) (*DeepHealth, error) {
+	path := "/health/deep"
+	var out DeepHealth
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetStatus: Health of the API and its dependencies.
+//
+// GET /status
+func (c *Client) GetStatus(ctx context.Context) (*HealthResponse, error) {
+	path := "/status"
+	var out HealthResponse
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetValidationSchema: Constraints of every request body, for mirroring validation client-side.
+//
+// GET /validation-schema
+func (c *Client) GetValidationSchema(ctx context.Context) (*ValidationSchema, error) {
+	path := "/validation-schema"
+	var out ValidationSchema
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
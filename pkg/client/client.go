@@ -0,0 +1,195 @@
+// Package client is a typed Go client for the API. The types and one method per
+// route are generated from static/openapi.json into client.gen.go by running
+//
+//	go run ./cmd/go-boilerplate gen sdk
+//
+// This file holds the hand-written transport they share.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RequestEditor modifies every outgoing request, e.g. to add headers.
+type RequestEditor func(ctx context.Context, req *http.Request) error
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	editors    []RequestEditor
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the default HTTP client (30s timeout).
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithBearerToken authenticates every request with token.
+func WithBearerToken(token string) Option {
+	return WithRequestEditor(func(_ context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// WithRequestEditor adds a function run on every request before it is sent.
+func WithRequestEditor(editor RequestEditor) Option {
+	return func(c *Client) {
+		c.editors = append(c.editors, editor)
+	}
+}
+
+// New returns a client for the API served at baseURL, e.g. "https://api.example.com".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// APIError is returned for every non-2xx response.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Fields     []FieldError
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("api error: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("api error: status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// do sends a request with an optional JSON or file upload body and decodes a JSON response into out, if not nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	data, err := c.doRaw(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
+// doRaw sends a request and returns the raw response body.
+func (c *Client) doRaw(ctx context.Context, method, path string, query url.Values, body any) ([]byte, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var (
+		reader      io.Reader
+		contentType string
+	)
+	switch body := body.(type) {
+	case nil:
+	case *upload:
+		reader, contentType = body.encode()
+	default:
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reader, contentType = bytes.NewReader(payload), "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	for _, editor := range c.editors {
+		if err := editor(ctx, req); err != nil {
+			// Closing the body stops the goroutine encoding an upload
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of %s %s: %w", method, path, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+
+		var errorResponse ErrorResponse
+		if json.Unmarshal(data, &errorResponse) == nil {
+			apiErr.Code = errorResponse.Code
+			apiErr.Message = errorResponse.Message
+			apiErr.Fields = errorResponse.Fields
+		}
+
+		return nil, apiErr
+	}
+
+	return data, nil
+}
+
+// upload is a multipart/form-data body holding a single file.
+type upload struct {
+	field    string
+	filename string
+	file     io.Reader
+}
+
+// encode streams the multipart body through a pipe, so the file isn't held in memory.
+func (u *upload) encode() (io.Reader, string) {
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+
+	go func() {
+		part, err := form.CreateFormFile(u.field, u.filename)
+		if err == nil {
+			_, err = io.Copy(part, u.file)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr, form.FormDataContentType()
+}
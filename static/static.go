@@ -0,0 +1,9 @@
+// Package static embeds the files under static/ (the API docs page, the OpenAPI spec,
+// ...) into the binary, so the server doesn't depend on its working directory. Brotli
+// variants (name.br) placed next to a file are embedded and served too.
+package static
+
+import "embed"
+
+//go:embed *
+var FS embed.FS